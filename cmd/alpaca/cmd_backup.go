@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/d2verb/alpaca/internal/backup"
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/pathutil"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ExportCmd struct {
+	Output        string `short:"o" required:"" help:"Archive to write (gzip-compressed tar)" placeholder:"FILE"`
+	IncludeModels bool   `help:"Include downloaded models, mmproj files, LoRA adapters and their metadata"`
+}
+
+func (c *ExportCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	output, err := pathutil.ResolvePath(c.Output, "")
+	if err != nil {
+		return err
+	}
+
+	part := output + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	err = backup.Export(context.Background(), f, backupPaths(paths), c.IncludeModels)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(part)
+		return err
+	}
+	if err := os.Rename(part, output); err != nil {
+		os.Remove(part)
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Exported to %s (%s)", output, formatSize(info.Size())))
+	return nil
+}
+
+type ImportCmd struct {
+	Archive string `arg:"" help:"Archive written by alpaca export"`
+}
+
+func (c *ImportCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+	archive, err := pathutil.ResolvePath(c.Archive, "")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer f.Close()
+
+	result, err := backup.Import(context.Background(), f, backupPaths(paths))
+	if err != nil {
+		return fmt.Errorf("import %s: %w", archive, err)
+	}
+	for _, item := range result.Imported {
+		ui.PrintSuccess("Imported " + item)
+	}
+	for _, item := range result.Skipped {
+		ui.PrintWarning("Skipped " + item)
+	}
+	if len(result.Imported) == 0 && len(result.Skipped) == 0 {
+		ui.PrintInfo("Archive is empty.")
+	}
+	return nil
+}
+
+// backupPaths returns the parts of paths that export and import cover.
+func backupPaths(paths *config.Paths) backup.Paths {
+	return backup.Paths{Config: paths.Config, Presets: paths.Presets, Models: paths.Models}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type CancelCmd struct{}
+
+func (c *CancelCmd) Run() error {
+	cl, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cl.Cancel(context.Background())
+	if err != nil {
+		return clientError(err)
+	}
+	if resp.Status == "error" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	var data protocol.CancelData
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
+	if !data.Canceled {
+		ui.PrintInfo("No load in progress.")
+		return nil
+	}
+	ui.PrintSuccess("Load canceled")
+	return nil
+}
@@ -16,7 +16,7 @@ type EditCmd struct {
 }
 
 func (c *EditCmd) Run() error {
-	// Resolve identifier (handles empty input → .alpaca.yaml)
+	// Resolve identifier (handles empty input or "." → nearest .alpaca.yaml)
 	idStr, err := resolveLocalPreset(c.Identifier)
 	if err != nil {
 		return err
@@ -50,6 +50,36 @@ func TestResolveLocalPreset(t *testing.T) {
 		}
 	})
 
+	t.Run("finds local preset in a parent directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(tmpDir, LocalPresetFile), []byte("name: test\nmodel: f:/path.gguf"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		subDir := filepath.Join(tmpDir, "src", "pkg")
+		if err := os.MkdirAll(subDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		origDir, _ := os.Getwd()
+		if err := os.Chdir(subDir); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chdir(origDir) })
+
+		for _, input := range []string{"", "."} {
+			got, err := resolveLocalPreset(input)
+			if err != nil {
+				t.Fatalf("resolveLocalPreset(%q) error = %v", input, err)
+			}
+
+			actualDir, _ := os.Getwd()
+			expected := "f:" + filepath.Join(actualDir, "..", "..", LocalPresetFile)
+			if got != expected {
+				t.Errorf("resolveLocalPreset(%q) = %q, want %q", input, got, expected)
+			}
+		}
+	})
+
 	t.Run("returns error when no local preset exists", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -32,6 +32,18 @@ func (c *ListCmd) Run() error {
 		return fmt.Errorf("list models: %w", err)
 	}
 
+	// Partial downloads are invisible files that still use disk space, so
+	// note them here; failing to list them is not worth failing ls over.
+	partials, _ := modelMgr.Pending(ctx)
+	partialSizes := make(map[string]int64)
+	var partialTotal int64
+	for _, p := range partials {
+		partialTotal += p.Size
+		if p.Model != "" {
+			partialSizes[p.Model] += p.Size
+		}
+	}
+
 	// Convert to UI model format
 	models := make([]ui.ModelInfo, len(entries))
 	for i, entry := range entries {
@@ -43,6 +55,24 @@ func (c *ListCmd) Run() error {
 			Repo:         entry.Repo,
 			Quant:        entry.Quant,
 			SizeString:   sizeStr,
+			Info:         formatModelInfo(&entry),
+			DownloadedAt: entry.DownloadedAt.Format("2006-01-02"),
+		}
+		if size, ok := partialSizes[fmt.Sprintf("h:%s:%s", entry.Repo, entry.Quant)]; ok {
+			models[i].Partial = formatSize(size)
+		}
+	}
+
+	loraEntries, err := modelMgr.ListLoras(ctx)
+	if err != nil {
+		return fmt.Errorf("list lora adapters: %w", err)
+	}
+	loras := make([]ui.LoraInfo, len(loraEntries))
+	for i, entry := range loraEntries {
+		loras[i] = ui.LoraInfo{
+			Repo:         entry.Repo,
+			File:         entry.File,
+			SizeString:   formatSize(entry.Size),
 			DownloadedAt: entry.DownloadedAt.Format("2006-01-02"),
 		}
 	}
@@ -54,6 +84,13 @@ func (c *ListCmd) Run() error {
 	}
 	fmt.Fprintln(ui.Output) // Single blank line between sections
 	ui.PrintModelList(models)
+	if len(partials) > 0 {
+		ui.PrintInfo(fmt.Sprintf("%d partial download(s) use %s. Run: alpaca model pending ls", len(partials), formatSize(partialTotal)))
+	}
+	if len(loras) > 0 {
+		fmt.Fprintln(ui.Output)
+		ui.PrintLoraList(loras)
+	}
 
 	return nil
 }
@@ -5,16 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/d2verb/alpaca/internal/client"
 	"github.com/d2verb/alpaca/internal/config"
 	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/model"
 	"github.com/d2verb/alpaca/internal/pathutil"
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/pull"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
@@ -22,7 +27,13 @@ import (
 const LocalPresetFile = ".alpaca.yaml"
 
 type LoadCmd struct {
-	Identifier string `arg:"" optional:"" help:"Identifier (p:preset, h:org/repo:quant, f:/path/to/file, or f:*.yaml)" predictor:"load-identifier"`
+	Identifier  string `arg:"" optional:"" help:"Identifier (p:preset, h:org/repo:quant, f:/path/to/model.gguf, or a preset file such as ./preset.yaml); defaults to the nearest .alpaca.yaml" predictor:"load-identifier"`
+	Draft       string `help:"Draft model for speculative decoding (h:org/repo:quant, f:/path, or 'auto')" placeholder:"MODEL"`
+	LlamaServer string `help:"llama-server binary to use for this load (overrides the preset and config)" placeholder:"PATH"`
+	DryRun      bool   `help:"Resolve the model and print the llama-server command (and router config.ini) without starting it"`
+	Watch       bool   `help:"Reload the preset whenever its file is saved, until it is unloaded"`
+	Strict      bool   `help:"Refuse to load when ctx-size exceeds the model's trained context length, instead of warning"`
+	Details     bool   `help:"If llama-server fails to start, also show its command and the end of its log"`
 }
 
 func (c *LoadCmd) Run() error {
@@ -36,18 +47,33 @@ func (c *LoadCmd) Run() error {
 		return err
 	}
 
-	// Resolve identifier (handles empty input → .alpaca.yaml)
+	// Resolve identifier (handles empty input or "." → nearest .alpaca.yaml)
 	idStr, err := resolveLocalPreset(c.Identifier)
 	if err != nil {
 		return err
 	}
 
+	// A name without prefix is looked up among presets and downloaded
+	// models, unless it is the path of a preset file
+	if identifier.IsPresetPath(idStr) {
+		idStr = "f:" + idStr
+	} else if !identifier.HasPrefix(idStr) {
+		idStr, err = matchIdentifier(cl, paths, idStr)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Parse and normalize identifier
 	id, err := identifier.Parse(idStr)
 	if err != nil {
 		return fmt.Errorf("invalid identifier: %w", err)
 	}
 
+	if c.Watch && id.Type != identifier.TypePresetName && id.Type != identifier.TypePresetFilePath {
+		return fmt.Errorf("--watch needs a preset (p:name or a .yaml file), not %s", id.Raw)
+	}
+
 	// Prepare load request (normalize paths, get display name)
 	req, err := c.prepare(id)
 	if err != nil {
@@ -55,43 +81,185 @@ func (c *LoadCmd) Run() error {
 	}
 
 	// Ensure HuggingFace model is downloaded (with progress bar)
-	// This handles direct HF identifiers and presets that reference HF models
-	isRouter, err := c.ensureHFModel(paths, id)
+	// This handles direct HF identifiers and presets that reference HF models.
+	// A remote daemon resolves models on its own host, so nothing is pulled locally.
+	// Neither is anything pulled for a dry run.
+	isRouter := false
+	if daemonHost == "" && !c.DryRun {
+		isRouter, err = c.ensureHFModel(paths, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	draft, err := c.resolveDraft(paths, id)
+	if err != nil {
+		return err
+	}
+
+	llamaServer, err := c.resolveLlamaServer()
 	if err != nil {
 		return err
 	}
 
-	// Send to daemon
-	ui.PrintInfo(fmt.Sprintf("Loading %s...", req.displayName))
-	resp, err := cl.Load(req.identifier)
+	// Send to daemon. Ctrl-C cancels the load on the daemon too, rather than
+	// leaving it loading in the background.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	opts := client.LoadOptions{DraftModel: draft, LlamaServer: llamaServer, DryRun: c.DryRun, Watch: c.Watch, Strict: c.Strict}
+	var spinner *ui.Spinner
+	if c.DryRun {
+		ui.PrintInfo(fmt.Sprintf("Resolving %s (dry run)...", req.displayName))
+	} else {
+		spinner = ui.StartSpinner(fmt.Sprintf("Loading %s...", req.displayName), isTerminal())
+		opts.Progress = func(p protocol.LoadProgressData) { spinner.SetPhase(loadPhaseLabel(p)) }
+	}
+	resp, err := cl.Load(ctx, req.identifier, opts)
+	var elapsed time.Duration
+	if spinner != nil {
+		elapsed = spinner.Stop()
+	}
 	if err != nil {
-		if os.IsNotExist(err) || errors.Is(err, syscall.ECONNREFUSED) {
+		var mismatch *client.VersionMismatchError
+		switch {
+		case os.IsNotExist(err) || errors.Is(err, syscall.ECONNREFUSED):
 			return errDaemonNotRunning()
+		case errors.As(err, &mismatch):
+			return errVersionMismatch(mismatch)
+		case errors.Is(err, context.Canceled):
+			return errors.New("load canceled")
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("load did not finish within %s and was canceled", daemonTimeouts.Request)
 		}
 		return fmt.Errorf("load model: %w", err)
 	}
 
 	if resp.Status == "error" {
-		return handleLoadError(resp.ErrorCode, resp.Error, id)
+		return handleLoadError(resp, id, c.Details)
 	}
 
-	endpoint, _ := resp.Data["endpoint"].(string)
+	var data protocol.LoadData
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
+	for _, msg := range data.Warnings {
+		ui.PrintWarning(msg)
+	}
+
+	if c.DryRun {
+		printInvocation(data.Command, data.ConfigINI)
+		return nil
+	}
+
+	// Only the daemon knows a remote preset is a router; it reports its models
+	router := isRouter || data.Models != nil || data.ModelsError != ""
 	readyMsg := "Model ready"
-	if isRouter {
+	if router {
 		readyMsg = "Router ready"
 	}
-	ui.PrintSuccess(fmt.Sprintf("%s at %s", readyMsg, ui.FormatEndpoint(endpoint)))
+	ui.PrintSuccess(fmt.Sprintf("%s in %s at %s", readyMsg, ui.FormatElapsed(elapsed), ui.FormatEndpoint(data.Endpoint)))
+	if router {
+		ui.PrintRouterModels(routerModelInfos(data.Models), data.ModelsError)
+	}
+	for _, name := range data.MissingModels {
+		ui.PrintWarning(fmt.Sprintf("llama-server did not register model '%s'; check its options. Run: alpaca logs --server", name))
+	}
+	if c.Watch {
+		ui.PrintInfo(fmt.Sprintf("Watching %s: saved changes are reloaded; rejected edits are logged (Run: alpaca logs)", req.displayName))
+	}
 	return nil
 }
 
+// loadPhaseLabel describes a load phase for the spinner, preferring what
+// llama-server reports during the health check, e.g. "loading model 43%".
+func loadPhaseLabel(p protocol.LoadProgressData) string {
+	switch {
+	case p.Detail != "":
+		return p.Detail
+	case p.Phase == protocol.LoadPhaseResolving:
+		return "resolving model"
+	case p.Phase == protocol.LoadPhaseStarting:
+		return "starting llama-server"
+	case p.Phase == protocol.LoadPhaseHealthCheck:
+		return "waiting for llama-server"
+	case p.Phase == protocol.LoadPhaseWarmup:
+		return "warming up"
+	}
+	return p.Phase
+}
+
+// matchIdentifier resolves a name without prefix (alpaca load qwen3) to a
+// preset or downloaded model, asking which one when several match.
+func matchIdentifier(cl *client.Client, paths *config.Paths, name string) (string, error) {
+	matches := identifier.Match(name, loadCandidates(cl, paths))
+	switch {
+	case len(matches) == 0:
+		return "", fmt.Errorf("no preset or downloaded model matches '%s'\nRun: alpaca ls", name)
+	case len(matches) == 1:
+		ui.PrintInfo(fmt.Sprintf("Using %s", matches[0]))
+		return matches[0], nil
+	case !stdinIsTerminal():
+		return "", fmt.Errorf("'%s' matches several presets and models:\n  %s\nRun: alpaca load <one of these>", name, strings.Join(matches, "\n  "))
+	}
+	return promptChoice(fmt.Sprintf("'%s' matches several presets and models:", name), matches)
+}
+
+// loadCandidates returns the identifiers of the presets and downloaded
+// models a name can match: the daemon's for a remote daemon, since those
+// are the ones it can load, and the local ones otherwise.
+func loadCandidates(cl *client.Client, paths *config.Paths) []string {
+	ctx := context.Background()
+	if daemonHost == "" {
+		return append(completePresets(ctx, paths.Presets, "p:"), completeModels(ctx, paths.Models, "h:")...)
+	}
+
+	var candidates []string
+	if resp, err := cl.ListPresets(ctx); err == nil {
+		var data protocol.ListPresetsData
+		if resp.Decode(&data) == nil {
+			for _, name := range data.Presets {
+				candidates = append(candidates, "p:"+name)
+			}
+		}
+	}
+	if resp, err := cl.ListModels(ctx); err == nil {
+		var data protocol.ListModelsData
+		if resp.Decode(&data) == nil {
+			for _, m := range data.Models {
+				candidates = append(candidates, "h:"+m.Repo+":"+m.Quant)
+			}
+		}
+	}
+	return candidates
+}
+
+// resolveLlamaServer makes a --llama-server path absolute so the daemon,
+// which runs in a different directory, finds it. Paths for a remote daemon
+// refer to its host and are sent as given.
+func (c *LoadCmd) resolveLlamaServer() (string, error) {
+	if c.LlamaServer == "" || daemonHost != "" {
+		return c.LlamaServer, nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("get working directory: %w", err)
+	}
+	return pathutil.ResolveCommand(c.LlamaServer, cwd)
+}
+
 // ensureHFModel ensures HuggingFace models are downloaded before loading.
 // Handles direct HF identifiers and presets that reference HF models.
 func (c *LoadCmd) ensureHFModel(paths *config.Paths, id *identifier.Identifier) (bool, error) {
-	var repo, quant string
+	var repo, quant, mmproj string
+	registry := pull.HuggingFace
 
 	switch id.Type {
 	case identifier.TypeHuggingFace:
 		repo, quant = id.Repo, id.Quant
+		var err error
+		if registry, err = pull.RegistryFor(id.Registry); err != nil {
+			return false, err
+		}
 
 	case identifier.TypePresetName, identifier.TypePresetFilePath:
 		p, err := c.loadPreset(paths, id)
@@ -105,12 +273,16 @@ func (c *LoadCmd) ensureHFModel(paths *config.Paths, id *identifier.Identifier)
 			return true, c.ensureRouterModels(paths, p)
 		}
 		repo, quant = extractHFModel(p.Model)
+		mmproj = p.Mmproj
 		if err := c.ensureDraftModel(paths, p.DraftModel); err != nil {
 			return false, err
 		}
 		if err := c.ensureMmprojFile(p.Mmproj); err != nil {
 			return false, err
 		}
+		if err := c.ensureLoras(paths, p.Lora); err != nil {
+			return false, err
+		}
 
 	default:
 		return false, nil
@@ -120,10 +292,10 @@ func (c *LoadCmd) ensureHFModel(paths *config.Paths, id *identifier.Identifier)
 		return false, nil
 	}
 
-	if err := pullIfNeeded(context.Background(), paths.Models, repo, quant); err != nil {
+	if err := pullIfNeeded(context.Background(), registry, paths.Models, repo, quant); err != nil {
 		return false, fmt.Errorf("download model: %w", err)
 	}
-	return false, nil
+	return false, ensureMmproj(paths, repo, quant, mmproj)
 }
 
 // loadPreset loads a preset from name or file path.
@@ -163,14 +335,17 @@ func (c *LoadCmd) ensureRouterModels(paths *config.Paths, p *preset.Preset) erro
 	for _, m := range p.Models {
 		repo, quant := extractHFModel(m.Model)
 		if repo != "" {
-			if err := pullIfNeeded(ctx, paths.Models, repo, quant); err != nil {
+			if err := pullIfNeeded(ctx, pull.HuggingFace, paths.Models, repo, quant); err != nil {
 				return fmt.Errorf("download model '%s': %w", m.Name, err)
 			}
+			if err := ensureMmproj(paths, repo, quant, m.Mmproj); err != nil {
+				return fmt.Errorf("model '%s': %w", m.Name, err)
+			}
 		}
 
 		draftRepo, draftQuant := extractHFModel(m.DraftModel)
 		if draftRepo != "" {
-			if err := pullIfNeeded(ctx, paths.Models, draftRepo, draftQuant); err != nil {
+			if err := pullIfNeeded(ctx, pull.HuggingFace, paths.Models, draftRepo, draftQuant); err != nil {
 				return fmt.Errorf("download draft model for '%s': %w", m.Name, err)
 			}
 		}
@@ -178,11 +353,39 @@ func (c *LoadCmd) ensureRouterModels(paths *config.Paths, p *preset.Preset) erro
 		if err := c.ensureMmprojFile(m.Mmproj); err != nil {
 			return fmt.Errorf("model '%s': %w", m.Name, err)
 		}
+
+		if err := c.ensureLoras(paths, m.Lora); err != nil {
+			return fmt.Errorf("model '%s': %w", m.Name, err)
+		}
 	}
 
 	return nil
 }
 
+// ensureMmproj offers to download the mmproj of a downloaded model whose
+// mmproj download failed, rather than pulling the whole model again.
+// mmproj is the preset's mmproj field: an explicit file or "none" needs no
+// download. Without a terminal to ask on (and no --yes), it only warns.
+func ensureMmproj(paths *config.Paths, repo, quant, mmproj string) error {
+	if mmproj != "" {
+		return nil
+	}
+	entry, err := model.NewManager(paths.Models).GetDetails(context.Background(), repo, quant)
+	if err != nil || !entry.MmprojFailed {
+		return nil
+	}
+	ref := fmt.Sprintf("h:%s:%s", repo, quant)
+	if !assumeYes && !stdinIsTerminal() {
+		ui.PrintWarning(fmt.Sprintf("The mmproj for %s is not downloaded, so vision is unavailable. Run: alpaca pull %s", ref, ref))
+		return nil
+	}
+	ok, err := promptConfirm(fmt.Sprintf("The mmproj (vision projector) for %s is not downloaded. Download it now?", ref))
+	if err != nil || !ok {
+		return err
+	}
+	return pullMmproj(paths, repo, quant)
+}
+
 // ensureMmprojFile validates that an explicit mmproj file path exists.
 func (c *LoadCmd) ensureMmprojFile(mmproj string) error {
 	if !preset.IsMmprojActive(mmproj) {
@@ -208,14 +411,117 @@ func (c *LoadCmd) ensureDraftModel(paths *config.Paths, draftModel string) error
 		return nil
 	}
 
-	if err := pullIfNeeded(context.Background(), paths.Models, draftRepo, draftQuant); err != nil {
+	if err := pullIfNeeded(context.Background(), pull.HuggingFace, paths.Models, draftRepo, draftQuant); err != nil {
 		return fmt.Errorf("download draft model: %w", err)
 	}
 	return nil
 }
 
-// pullIfNeeded downloads a model if not already present.
-func pullIfNeeded(ctx context.Context, modelsDir, repo, quant string) error {
+// resolveDraft resolves the --draft flag into a draft model identifier and
+// downloads it if needed. Returns "" when no draft override was requested.
+func (c *LoadCmd) resolveDraft(paths *config.Paths, id *identifier.Identifier) (string, error) {
+	if c.Draft == "" {
+		return "", nil
+	}
+	if id.Type != identifier.TypeHuggingFace && id.Type != identifier.TypeModelFilePath {
+		return "", fmt.Errorf("--draft can only be used with h: or f: model identifiers\nSet draft-model in the preset instead")
+	}
+
+	draft := c.Draft
+	if draft == "auto" {
+		if id.Type != identifier.TypeHuggingFace {
+			return "", fmt.Errorf("--draft auto requires a HuggingFace model (h:org/repo:quant)")
+		}
+		found, err := findDraftModel(paths, id.Repo, id.Quant)
+		if err != nil {
+			return "", err
+		}
+		ui.PrintInfo(fmt.Sprintf("Using draft model %s", found))
+		draft = found
+	}
+
+	draftID, err := identifier.Parse(draft)
+	if err != nil {
+		return "", fmt.Errorf("invalid draft model: %w", err)
+	}
+	switch draftID.Type {
+	case identifier.TypeHuggingFace:
+		if daemonHost == "" && !c.DryRun {
+			if err := c.ensureDraftModel(paths, draft); err != nil {
+				return "", err
+			}
+		}
+		return draft, nil
+	case identifier.TypeModelFilePath:
+		return toAbsFileID(draftID.FilePath)
+	default:
+		return "", fmt.Errorf("invalid draft model: expected h:org/repo:quant, f:/path, or auto")
+	}
+}
+
+// findDraftModel returns the draft model for repo from the draft-models
+// mapping in config.yaml, falling back to HuggingFace naming heuristics.
+func findDraftModel(paths *config.Paths, repo, quant string) (string, error) {
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return "", err
+	}
+	if draft, ok := cfg.DraftModels[repo]; ok {
+		return draft, nil
+	}
+
+	ui.PrintInfo("Looking up draft model...")
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return "", err
+	}
+	draft, err := puller.FindDraftModel(context.Background(), repo, quant)
+	if err != nil {
+		return "", fmt.Errorf("%w\nAdd a mapping under 'draft-models' in %s", err, paths.Config)
+	}
+	return draft, nil
+}
+
+// ensureLoras downloads HuggingFace LoRA adapters and validates that explicit
+// adapter file paths exist.
+func (c *LoadCmd) ensureLoras(paths *config.Paths, loras []string) error {
+	for _, l := range loras {
+		id, err := identifier.Parse(l)
+		if err != nil {
+			return fmt.Errorf("invalid lora: %w", err)
+		}
+		switch id.Type {
+		case identifier.TypeHuggingFace:
+			if err := pullLoraIfNeeded(context.Background(), paths.Models, id.Repo, id.Quant); err != nil {
+				return fmt.Errorf("download lora: %w", err)
+			}
+		case identifier.TypeModelFilePath:
+			if _, err := os.Stat(id.FilePath); err != nil {
+				if os.IsNotExist(err) {
+					return fmt.Errorf("lora file not found: %s", id.FilePath)
+				}
+				return fmt.Errorf("check lora file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// pullLoraIfNeeded downloads a LoRA adapter if not already present.
+func pullLoraIfNeeded(ctx context.Context, modelsDir, repo, file string) error {
+	modelMgr := model.NewManager(modelsDir)
+	exists, err := modelMgr.LoraExists(ctx, repo, file)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return pullLora(pull.HuggingFace, repo, file, modelsDir, "", false)
+}
+
+// pullIfNeeded downloads a model from registry if not already present.
+func pullIfNeeded(ctx context.Context, registry pull.Registry, modelsDir, repo, quant string) error {
 	modelMgr := model.NewManager(modelsDir)
 	exists, err := modelMgr.Exists(ctx, repo, quant)
 	if err != nil {
@@ -224,7 +530,7 @@ func pullIfNeeded(ctx context.Context, modelsDir, repo, quant string) error {
 	if exists {
 		return nil
 	}
-	return pullModel(repo, quant, modelsDir)
+	return pullModel(registry, repo, quant, modelsDir, "", false)
 }
 
 // extractHFModel extracts repo and quant from an HF model reference (h:org/repo:quant).
@@ -282,8 +588,9 @@ func toAbsFileID(path string) (string, error) {
 }
 
 // handleLoadError converts daemon error codes into user-friendly errors.
-func handleLoadError(code, message string, id *identifier.Identifier) error {
-	switch code {
+// details expands the diagnostics of a llama-server failure.
+func handleLoadError(resp *protocol.Response, id *identifier.Identifier, details bool) error {
+	switch resp.ErrorCode {
 	case protocol.ErrCodePresetNotFound:
 		return errPresetNotFound(id.PresetName)
 
@@ -295,18 +602,72 @@ func handleLoadError(code, message string, id *identifier.Identifier) error {
 
 	case protocol.ErrCodeServerFailed:
 		// Strip internal ProcessError prefix (e.g., "wait llama-server: ")
-		msg := message
+		msg := resp.Error
 		msg = strings.TrimPrefix(msg, "wait llama-server: ")
 		msg = strings.TrimPrefix(msg, "start llama-server: ")
 
+		// Older daemons send no diagnostics
+		var diag protocol.LoadFailureData
+		if err := resp.Decode(&diag); err == nil {
+			msg += loadDetails(diag, details)
+		}
+
 		paths, _ := getPaths()
 		hint := ""
 		if paths != nil {
 			hint = fmt.Sprintf("\nSee %s for details", paths.LlamaLog)
+			if strings.Contains(msg, llama.OutputHeader) {
+				hint = fmt.Sprintf("\nFull log: %s", paths.LlamaLog)
+			}
 		}
 		return fmt.Errorf("%s%s", msg, hint)
 
 	default:
-		return fmt.Errorf("%s", message)
+		return fmt.Errorf("%s", resp.Error)
+	}
+}
+
+// loadDetails renders the diagnostics of a failed load as a Details
+// section. Collapsed, it is a one-line summary; expanded, it lists the
+// llama-server command and the end of its log too. Empty without
+// diagnostics.
+func loadDetails(d protocol.LoadFailureData, expanded bool) string {
+	var facts []string
+	if d.ExitCode != nil {
+		facts = append(facts, fmt.Sprintf("exit code %d", *d.ExitCode))
+	}
+	if d.Addr != "" {
+		if d.PortInUse {
+			facts = append(facts, fmt.Sprintf("port %s in use by another process", d.Addr))
+		} else {
+			facts = append(facts, fmt.Sprintf("port %s free", d.Addr))
+		}
+	}
+	if d.FreeMemory > 0 {
+		facts = append(facts, formatSize(d.FreeMemory)+" memory free")
+	}
+	if len(facts) == 0 && len(d.Command) == 0 && len(d.LogTail) == 0 {
+		return ""
+	}
+
+	if !expanded {
+		summary := "\nDetails: " + strings.Join(facts, " · ")
+		if len(d.Command) > 0 || len(d.LogTail) > 0 {
+			summary += " (Run with --details for the command and log)"
+		}
+		return summary
+	}
+
+	var b strings.Builder
+	b.WriteString("\nDetails:")
+	for _, fact := range facts {
+		b.WriteString("\n  " + fact)
+	}
+	if len(d.Command) > 0 {
+		b.WriteString("\n  command: " + shellJoin(d.Command))
+	}
+	if len(d.LogTail) > 0 {
+		b.WriteString("\n  last log lines:\n    " + strings.Join(d.LogTail, "\n    "))
 	}
+	return b.String()
 }
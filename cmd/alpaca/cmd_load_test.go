@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+	"github.com/fatih/color"
+)
+
+func TestLoadCmd_ResolveDraft(t *testing.T) {
+	absDraft, _ := filepath.Abs("draft.gguf")
+
+	tests := []struct {
+		name    string
+		input   string
+		draft   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no draft flag",
+			input: "h:org/model:Q4_K_M",
+			draft: "",
+			want:  "",
+		},
+		{
+			name:  "file draft is made absolute",
+			input: "f:/models/model.gguf",
+			draft: "f:draft.gguf",
+			want:  "f:" + absDraft,
+		},
+		{
+			name:    "preset identifier rejected",
+			input:   "p:my-preset",
+			draft:   "f:/models/draft.gguf",
+			wantErr: "only be used with h: or f: model identifiers",
+		},
+		{
+			name:    "auto requires HuggingFace model",
+			input:   "f:/models/model.gguf",
+			draft:   "auto",
+			wantErr: "--draft auto requires a HuggingFace model",
+		},
+		{
+			name:    "preset draft rejected",
+			input:   "h:org/model:Q4_K_M",
+			draft:   "p:other",
+			wantErr: "invalid draft model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			id, err := identifier.Parse(tt.input)
+			if err != nil {
+				t.Fatalf("parse identifier: %v", err)
+			}
+			cmd := &LoadCmd{Identifier: tt.input, Draft: tt.draft}
+
+			// Act
+			got, err := cmd.resolveDraft(nil, id)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveDraft() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveDraft() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveDraft() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnsureMmproj_WarnsWithoutTerminal(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	modelsDir := t.TempDir()
+	meta := metadata.NewManager(modelsDir)
+	meta.Add(metadata.ModelEntry{Repo: "org/vision", Quant: "Q4_K_M", Filename: "v.gguf", MmprojFailed: true})
+	meta.Add(metadata.ModelEntry{Repo: "org/text", Quant: "Q4_K_M", Filename: "t.gguf"})
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	paths := &config.Paths{Models: modelsDir}
+	origYes, origTerminal := assumeYes, stdinIsTerminal
+	t.Cleanup(func() { assumeYes, stdinIsTerminal = origYes, origTerminal })
+	assumeYes = false
+	stdinIsTerminal = func() bool { return false }
+	var buf bytes.Buffer
+	ui.Output = &buf
+	defer func() { ui.Output = os.Stdout }()
+
+	// Act
+	errVision := ensureMmproj(paths, "org/vision", "Q4_K_M", "")
+	errExplicit := ensureMmproj(paths, "org/vision", "Q4_K_M", "none")
+	errText := ensureMmproj(paths, "org/text", "Q4_K_M", "")
+
+	// Assert
+	if errVision != nil || errExplicit != nil || errText != nil {
+		t.Fatalf("ensureMmproj() errors = %v, %v, %v", errVision, errExplicit, errText)
+	}
+	want := "The mmproj for h:org/vision:Q4_K_M is not downloaded, so vision is unavailable. Run: alpaca pull h:org/vision:Q4_K_M"
+	if got := buf.String(); strings.Count(got, want) != 1 {
+		t.Errorf("output = %q, want one warning %q", got, want)
+	}
+}
+
+func TestMatchIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		terminal bool
+		answer   string
+		want     string
+		wantErr  string
+	}{
+		{name: "unique prefix", input: "qwen", want: "p:qwen3-coder"},
+		{name: "typo", input: "caht", want: "p:chat"},
+		{name: "ambiguous asks", input: "cha", terminal: true, answer: "2\n", want: "p:chat-long"},
+		{name: "ambiguous without terminal", input: "cha", wantErr: "matches several presets and models:\n  p:chat\n  p:chat-long"},
+		{name: "no match", input: "llama", wantErr: "no preset or downloaded model matches 'llama'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("ALPACA_PROFILE", "")
+			presets := filepath.Join(home, ".alpaca", "presets")
+			if err := os.MkdirAll(presets, 0755); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"chat", "chat-long", "qwen3-coder"} {
+				content := "name: " + name + "\nmodel: f:/models/" + name + ".gguf\n"
+				if err := os.WriteFile(filepath.Join(presets, name+".yaml"), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			paths, err := getPaths()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			ui.Output = &buf
+			t.Cleanup(func() { ui.Output = os.Stdout })
+			setStdinInput(t, tt.answer)
+			origTerminal := stdinIsTerminal
+			t.Cleanup(func() { stdinIsTerminal = origTerminal })
+			stdinIsTerminal = func() bool { return tt.terminal }
+
+			// Act
+			got, err := matchIdentifier(nil, paths, tt.input)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("matchIdentifier() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchIdentifier() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("matchIdentifier() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCmd_WatchNeedsPreset(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ALPACA_PROFILE", "")
+	cmd := &LoadCmd{Identifier: "f:/models/qwen3.gguf", Watch: true}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "--watch needs a preset") {
+		t.Fatalf("expected --watch error, got %v", err)
+	}
+}
+
+func TestLoadDetails(t *testing.T) {
+	exitCode := 1
+	diag := protocol.LoadFailureData{
+		Command:    []string{"llama-server", "--port", "8080"},
+		ExitCode:   &exitCode,
+		LogTail:    []string{"main: loading model", "error: couldn't bind HTTP server socket"},
+		Addr:       "127.0.0.1:8080",
+		PortInUse:  true,
+		FreeMemory: 4 * 1024 * 1024 * 1024,
+	}
+	tests := []struct {
+		name     string
+		diag     protocol.LoadFailureData
+		expanded bool
+		want     string
+	}{
+		{
+			name: "collapsed",
+			diag: diag,
+			want: "\nDetails: exit code 1 · port 127.0.0.1:8080 in use by another process · 4.0 GB memory free (Run with --details for the command and log)",
+		},
+		{
+			name:     "expanded",
+			diag:     diag,
+			expanded: true,
+			want: "\nDetails:\n  exit code 1\n  port 127.0.0.1:8080 in use by another process\n  4.0 GB memory free" +
+				"\n  command: llama-server --port 8080\n  last log lines:\n    main: loading model\n    error: couldn't bind HTTP server socket",
+		},
+		{
+			name: "port free, nothing to expand",
+			diag: protocol.LoadFailureData{Addr: "127.0.0.1:8080"},
+			want: "\nDetails: port 127.0.0.1:8080 free",
+		},
+		{
+			name: "no diagnostics",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := loadDetails(tt.diag, tt.expanded)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("loadDetails() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
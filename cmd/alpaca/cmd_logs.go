@@ -5,11 +5,14 @@ import (
 	"os"
 	"os/exec"
 	"syscall"
+
+	"github.com/d2verb/alpaca/internal/daemon"
 )
 
 type LogsCmd struct {
-	Follow bool `short:"f" help:"Follow log output in real-time (tail -f)"`
-	Server bool `short:"s" help:"Show llama-server logs"`
+	Follow bool   `short:"f" help:"Follow log output in real-time (tail -f)"`
+	Server bool   `short:"s" help:"Show llama-server logs"`
+	RunLog string `name:"run" help:"Show one llama-server run's log (run-logs in config.yaml): latest, a preset name for its latest run, or a file name in logs/runs" placeholder:"RUN"`
 }
 
 func (c *LogsCmd) Run() error {
@@ -19,7 +22,12 @@ func (c *LogsCmd) Run() error {
 	}
 
 	logPath := paths.DaemonLog
-	if c.Server {
+	switch {
+	case c.RunLog != "":
+		if logPath, err = daemon.FindRunLog(paths.RunLogs, c.RunLog); err != nil {
+			return err
+		}
+	case c.Server:
 		logPath = paths.LlamaLog
 	}
 
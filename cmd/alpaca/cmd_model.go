@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pathutil"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+// ModelCmd groups model management subcommands.
+type ModelCmd struct {
+	Add             ModelAddCmd             `cmd:"" help:"Add a model from a direct URL, a local GGUF file, or a manifest written by alpaca model cp"`
+	List            ModelListCmd            `cmd:"" name:"ls" help:"List the GGUF files in a directory alpaca does not manage"`
+	Show            ModelShowCmd            `cmd:"" help:"Show a downloaded model's files, hashes, origin and the presets that use it"`
+	Pull            ModelPullCmd            `cmd:"" help:"Download several models concurrently"`
+	Push            ModelPushCmd            `cmd:"" help:"Push a downloaded model to an OCI registry"`
+	Copy            ModelCopyCmd            `cmd:"" name:"cp" help:"Copy a downloaded model's files and a manifest to a directory, for alpaca model add on another machine"`
+	Quants          ModelQuantsCmd          `cmd:"" help:"List the quants available in a HuggingFace repository"`
+	Outdated        ModelOutdatedCmd        `cmd:"" help:"List downloaded models that changed upstream"`
+	Upgrade         ModelUpgradeCmd         `cmd:"" help:"Re-download models that changed upstream"`
+	GC              ModelGCCmd              `cmd:"" name:"gc" help:"Move model files into the blob store and delete unused blobs"`
+	DU              ModelDUCmd              `cmd:"" name:"du" help:"Show the disk space models, partial downloads and logs take"`
+	Pending         ModelPendingCmd         `cmd:"" help:"List or delete partial downloads"`
+	RebuildMetadata ModelRebuildMetadataCmd `cmd:"" name:"rebuild-metadata" help:"Recover model records from the files in the models directory"`
+}
+
+type ModelAddCmd struct {
+	Source string `arg:"" help:"Direct https:// URL, local path to a .gguf file, or a *.alpaca.json manifest written by alpaca model cp"`
+	Name   string `help:"Name to register the model under (format: org/name:QUANT; required unless adding a manifest)" placeholder:"ORG/NAME:QUANT"`
+	SHA256 string `name:"sha256" help:"Expected SHA256 of the file; the model is rejected on mismatch" placeholder:"HASH"`
+	Link   bool   `help:"Symlink a local file instead of copying it"`
+}
+
+func (c *ModelAddCmd) Run() error {
+	if strings.HasSuffix(c.Source, model.ManifestSuffix) {
+		return c.addManifest()
+	}
+	repo, quant, err := parseModelName(c.Name)
+	if err != nil {
+		return err
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+
+	if isURL(c.Source) {
+		if c.Link {
+			return fmt.Errorf("--link can only be used with local files")
+		}
+		if err := addModelFromURL(c.Source, repo, quant, c.SHA256, paths.Models); err != nil {
+			ui.PrintError(err.Error())
+			return errDownloadFailed()
+		}
+	} else {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		src, err := pathutil.ResolvePath(c.Source, cwd)
+		if err != nil {
+			return err
+		}
+		entry, err := model.NewManager(paths.Models).Import(context.Background(), src, repo, quant, c.Link, c.SHA256)
+		if err != nil {
+			return err
+		}
+		verb := "Copied"
+		if c.Link {
+			verb = "Linked"
+		}
+		ui.PrintSuccess(fmt.Sprintf("%s %s", verb, entry.Filename))
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Added h:%s:%s", repo, quant))
+	return nil
+}
+
+// addManifest registers the model of a manifest written by alpaca model cp.
+func (c *ModelAddCmd) addManifest() error {
+	if c.Name != "" || c.Link || c.SHA256 != "" {
+		return fmt.Errorf("--name, --sha256 and --link do not apply to a manifest; the model is added as the manifest records it")
+	}
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	src, err := pathutil.ResolvePath(c.Source, cwd)
+	if err != nil {
+		return err
+	}
+	entry, err := model.NewManager(paths.Models).ImportManifest(context.Background(), src)
+	if err != nil {
+		return err
+	}
+	for _, f := range entry.Files() {
+		ui.PrintSuccess(fmt.Sprintf("Copied %s", f))
+	}
+	if entry.Mmproj != nil {
+		ui.PrintSuccess(fmt.Sprintf("Copied %s", entry.Mmproj.Filename))
+	}
+	ui.PrintSuccess(fmt.Sprintf("Added %s", identifier.Model(entry.Registry, entry.Repo, entry.Quant)))
+	return nil
+}
+
+// parseModelName validates an org/name:QUANT model name.
+func parseModelName(name string) (repo, quant string, err error) {
+	id, err := identifier.Parse("h:" + name)
+	if err != nil || id.Quant == "" || id.IsRepoFile() || !strings.Contains(id.Repo, "/") {
+		return "", "", fmt.Errorf("invalid model name %q\nFormat: org/name:QUANT\nExample: alpaca model add https://example.com/model.gguf --name me/model:Q4_K_M", name)
+	}
+	return id.Repo, id.Quant, nil
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// addModelFromURL downloads a GGUF from a direct URL with progress output.
+func addModelFromURL(rawURL, repo, quant, sha256, modelsDir string) error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	puller, err := newPuller(paths, modelsDir, "")
+	if err != nil {
+		return err
+	}
+
+	puller.SetReportFunc(printProgress)
+	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
+		ui.PrintInfo(fmt.Sprintf("Downloading %s...", filename))
+	})
+	puller.SetFileSavedFunc(func(savedPath string) {
+		endProgressLine()
+		ui.PrintSuccess(fmt.Sprintf("Saved to: %s", savedPath))
+	})
+
+	if _, err := puller.PullURL(context.Background(), rawURL, repo, quant, sha256); err != nil {
+		endProgressLine()
+		return err
+	}
+	return nil
+}
+
+type ModelListCmd struct {
+	LocalDir string `required:"" help:"Directory to list; a directory holding one model loads as f:DIR" placeholder:"DIR"`
+}
+
+func (c *ModelListCmd) Run() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	dir, err := pathutil.ResolvePath(c.LocalDir, cwd)
+	if err != nil {
+		return err
+	}
+	scanned, err := model.ScanDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var infos []ui.LocalModelInfo
+	add := func(m model.LocalModel, kind string) {
+		infos = append(infos, ui.LocalModelInfo{Path: m.Path, SizeString: formatSize(m.Size), Files: m.Shards, Kind: kind})
+	}
+	for _, m := range scanned.Models {
+		add(m, "")
+	}
+	for _, l := range scanned.Loras {
+		add(l, "LoRA adapter")
+	}
+	for _, path := range scanned.Mmprojs {
+		var size int64
+		if info, err := os.Stat(path); err == nil {
+			size = info.Size()
+		}
+		add(model.LocalModel{Path: path, Shards: 1, Size: size}, "mmproj")
+	}
+
+	ui.PrintLocalModelList(infos)
+	for _, s := range scanned.Skipped {
+		ui.PrintWarning("Skipped " + s)
+	}
+	if len(scanned.Models) == 1 {
+		fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca load f:"+dir))
+	}
+	return nil
+}
+
+type ModelShowCmd struct {
+	Model string `arg:"" help:"Model to show (h:org/repo:quant)" predictor:"model-identifier"`
+}
+
+func (c *ModelShowCmd) Run() error {
+	id, err := identifier.Parse(c.Model)
+	if err != nil {
+		return fmt.Errorf("invalid identifier: %w", err)
+	}
+	if id.Type != identifier.TypeHuggingFace || id.Quant == "" {
+		return fmt.Errorf("expected a downloaded model (h:org/repo:quant), got %q", c.Model)
+	}
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	return showModel(id, paths.Models, paths.Presets)
+}
+
+type ModelQuantsCmd struct {
+	Repo string `arg:"" help:"HuggingFace repository (format: org/repo)"`
+}
+
+func (c *ModelQuantsCmd) Run() error {
+	repo, err := parseQuantsRepo(c.Repo)
+	if err != nil {
+		return err
+	}
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	quants, err := puller.ListQuants(ctx, repo)
+	if err != nil {
+		return err
+	}
+	if len(quants) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No GGUF quants found in %s.", repo))
+		return nil
+	}
+
+	// Without a detectable memory budget no quant is marked for :auto
+	auto, _ := puller.SelectAutoQuant(quants)
+	mgr := model.NewManager(paths.Models)
+	items := make([]ui.QuantInfo, len(quants))
+	for i, q := range quants {
+		downloaded, err := mgr.Exists(ctx, repo, q.Quant)
+		if err != nil {
+			return err
+		}
+		items[i] = ui.QuantInfo{
+			Repo:       repo,
+			Quant:      q.Quant,
+			SizeString: formatSize(q.Size),
+			Filename:   q.Filename,
+			Files:      q.Files,
+			Downloaded: downloaded,
+			Auto:       q.Quant == auto.Quant,
+		}
+	}
+	ui.PrintQuantList(items)
+	return nil
+}
+
+// parseQuantsRepo parses the repository argument of model quants,
+// accepting an optional h: prefix.
+func parseQuantsRepo(spec string) (string, error) {
+	repo := strings.TrimPrefix(spec, "h:")
+	if _, quant, ok := strings.Cut(repo, ":"); ok {
+		return "", fmt.Errorf("invalid repository %q: remove the quant %q to list every quant\nFormat: org/repo", spec, quant)
+	}
+	if org, name, ok := strings.Cut(repo, "/"); !ok || org == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid repository %q\nFormat: org/repo", spec)
+	}
+	return repo, nil
+}
+
+type ModelGCCmd struct {
+	DryRun bool `help:"Show what would be migrated and deleted without changing anything"`
+}
+
+func (c *ModelGCCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	result, err := model.NewManager(paths.Models).GC(context.Background(), c.DryRun)
+	if err != nil {
+		return err
+	}
+
+	verb, deleted := "Moved", "Deleted"
+	if c.DryRun {
+		verb, deleted = "Would move", "Would delete"
+	}
+	for _, f := range result.Migrated {
+		ui.PrintInfo(fmt.Sprintf("%s %s into the blob store", verb, f))
+	}
+	for _, b := range result.Removed {
+		ui.PrintInfo(fmt.Sprintf("%s %s (%s)", deleted, b.Name, formatSize(b.Size)))
+	}
+	if len(result.Migrated) == 0 && len(result.Removed) == 0 {
+		ui.PrintSuccess("Nothing to clean up.")
+		return nil
+	}
+	ui.PrintSuccess(fmt.Sprintf("%s %d unused blob(s), freeing %s.", deleted, len(result.Removed), formatSize(result.Freed())))
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pathutil"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelCopyCmd struct {
+	Model string `arg:"" help:"Downloaded model to copy (h:org/repo:quant)" predictor:"model-identifier"`
+	Dest  string `arg:"" help:"Directory to copy the files and manifest to (created if missing)" placeholder:"DIR"`
+	Link  bool   `help:"Hard-link the files instead of copying them (same file system only)"`
+}
+
+func (c *ModelCopyCmd) Run() error {
+	id, err := identifier.Parse(c.Model)
+	if err != nil {
+		return fmt.Errorf("invalid identifier: %w", err)
+	}
+	if id.Type != identifier.TypeHuggingFace || id.Quant == "" || id.IsRepoFile() {
+		return fmt.Errorf("expected a downloaded model (h:org/repo:quant), got %q", c.Model)
+	}
+
+	dest, err := pathutil.ResolvePath(c.Dest, "")
+	if err != nil {
+		return err
+	}
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	manifestPath, manifest, err := model.NewManager(paths.Models).Export(context.Background(), id.Repo, id.Quant, dest, c.Link)
+	if err != nil {
+		var notFound *metadata.NotFoundError
+		if errors.As(err, &notFound) {
+			return errModelNotFound(c.Model)
+		}
+		return err
+	}
+
+	files := manifest.Files
+	if manifest.Mmproj != nil {
+		files = append(files, *manifest.Mmproj)
+	}
+	var size int64
+	for _, f := range files {
+		size += f.Size
+	}
+	verb := "Copied"
+	if c.Link {
+		verb = "Linked"
+	}
+	ui.PrintSuccess(fmt.Sprintf("%s %d file(s) (%s) to %s", verb, len(files), formatSize(size), dest))
+	ui.PrintSuccess(fmt.Sprintf("Wrote %s", manifestPath))
+	ui.PrintInfo(fmt.Sprintf("On the other machine, run: alpaca model add %s", filepath.Join("<dir>", filepath.Base(manifestPath))))
+	return nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelDUCmd struct {
+	JSON bool `name:"json" help:"Print sizes in bytes as JSON"`
+}
+
+// diskUsageJSON is the output of alpaca model du --json. Sizes are in bytes.
+type diskUsageJSON struct {
+	Repos   []repoUsageJSON `json:"repos"`
+	Partial int64           `json:"partial_downloads"`
+	Unused  int64           `json:"unused_blobs"`
+	Other   int64           `json:"other"`
+	Logs    int64           `json:"logs"`
+	Total   int64           `json:"total"`
+}
+
+type repoUsageJSON struct {
+	Repo   string `json:"repo"`
+	Quants int    `json:"quants"`
+	Models int64  `json:"models"`
+	Mmproj int64  `json:"mmproj"`
+	Loras  int64  `json:"loras"`
+	Extra  int64  `json:"extra_files"`
+	Total  int64  `json:"total"`
+}
+
+func (c *ModelDUCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	usage, err := model.NewManager(paths.Models).DiskUsage(context.Background())
+	if err != nil {
+		return err
+	}
+	logs, err := dirSize(paths.Logs)
+	if err != nil {
+		return err
+	}
+	total := usage.Total() + logs
+
+	if c.JSON {
+		out := diskUsageJSON{Repos: []repoUsageJSON{}, Partial: usage.Partial, Unused: usage.Unused, Other: usage.Other, Logs: logs, Total: total}
+		for _, r := range usage.Repos {
+			out.Repos = append(out.Repos, repoUsageJSON{
+				Repo: r.Repo, Quants: r.Quants, Models: r.Models, Mmproj: r.Mmproj, Loras: r.Loras, Extra: r.Extra, Total: r.Total(),
+			})
+		}
+		enc := json.NewEncoder(ui.Output)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	repos := make([]ui.DiskUsageInfo, 0, len(usage.Repos))
+	for _, r := range usage.Repos {
+		repos = append(repos, ui.DiskUsageInfo{Name: r.Repo, Size: formatSize(r.Total()), Detail: repoUsageDetail(r)})
+	}
+	var other []ui.DiskUsageInfo
+	for _, o := range []struct {
+		name string
+		size int64
+	}{
+		{"Partial downloads (alpaca model pending rm)", usage.Partial},
+		{"Unused blobs (alpaca model gc)", usage.Unused},
+		{"Other files in the models directory", usage.Other},
+		{"Logs", logs},
+	} {
+		if o.size > 0 {
+			other = append(other, ui.DiskUsageInfo{Name: o.name, Size: formatSize(o.size)})
+		}
+	}
+	ui.PrintDiskUsage(repos, other, formatSize(total))
+	return nil
+}
+
+// repoUsageDetail describes what a repository's disk usage is made of.
+func repoUsageDetail(r model.RepoUsage) string {
+	var parts []string
+	switch r.Quants {
+	case 0:
+	case 1:
+		parts = append(parts, "1 quant")
+	default:
+		parts = append(parts, fmt.Sprintf("%d quants", r.Quants))
+	}
+	if r.Mmproj > 0 {
+		parts = append(parts, "mmproj "+formatSize(r.Mmproj))
+	}
+	if r.Loras > 0 {
+		parts = append(parts, "LoRA "+formatSize(r.Loras))
+	}
+	if r.Extra > 0 {
+		parts = append(parts, "extra files "+formatSize(r.Extra))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// dirSize returns the total size of the regular files under dir, or 0 if
+// it does not exist.
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("scan %s: %w", dir, err)
+	}
+	return size, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/model"
+)
+
+func TestRepoUsageDetail(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage model.RepoUsage
+		want  string
+	}{
+		{"one quant", model.RepoUsage{Quants: 1, Models: 5 << 30}, "1 quant"},
+		{"quants with mmproj", model.RepoUsage{Quants: 2, Models: 8 << 30, Mmproj: 851 << 20}, "2 quants · mmproj 851.0 MB"},
+		{"LoRA adapters only", model.RepoUsage{Loras: 40 << 20}, "LoRA 40.0 MB"},
+		{"extra files", model.RepoUsage{Quants: 1, Extra: 12 << 10}, "1 quant · extra files 12.0 KB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := repoUsageDetail(tt.usage)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("repoUsageDetail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+// ModelPendingCmd groups the commands for downloads that did not finish.
+type ModelPendingCmd struct {
+	List ModelPendingListCmd `cmd:"" name:"ls" help:"List partial downloads with their sizes and ages"`
+	Rm   ModelPendingRmCmd   `cmd:"" name:"rm" help:"Delete partial downloads"`
+}
+
+type ModelPendingListCmd struct{}
+
+func (c *ModelPendingListCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	partials, err := model.NewManager(paths.Models).Pending(context.Background())
+	if err != nil {
+		return err
+	}
+	ui.PrintPendingList(partialInfos(time.Now(), partials))
+	if len(partials) > 0 {
+		ui.PrintInfo("Pull the same model again to resume, or run: alpaca model pending rm")
+	}
+	return nil
+}
+
+type ModelPendingRmCmd struct {
+	Files []string `arg:"" optional:"" help:"Files to delete the partial downloads of, as shown by alpaca model pending ls (default: all)"`
+}
+
+func (c *ModelPendingRmCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	mgr := model.NewManager(paths.Models)
+	partials, err := mgr.Pending(context.Background())
+	if err != nil {
+		return err
+	}
+
+	targets := partials
+	if len(c.Files) > 0 {
+		targets = nil
+		for _, f := range c.Files {
+			i := slices.IndexFunc(partials, func(p model.Partial) bool { return p.Filename == f })
+			if i < 0 {
+				return fmt.Errorf("no partial download of '%s'\nRun: alpaca model pending ls", f)
+			}
+			targets = append(targets, partials[i])
+		}
+	}
+	if len(targets) == 0 {
+		ui.PrintSuccess("No partial downloads.")
+		return nil
+	}
+
+	var size int64
+	for _, p := range targets {
+		size += p.Size
+	}
+	ok, err := promptConfirm(fmt.Sprintf("Delete %d partial download(s) (%s)?", len(targets), formatSize(size)))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ui.PrintInfo("Cancelled")
+		return nil
+	}
+
+	for _, p := range targets {
+		if err := mgr.RemovePartial(p.Filename); err != nil {
+			return err
+		}
+	}
+	ui.PrintSuccess(fmt.Sprintf("Deleted %d partial download(s), freeing %s.", len(targets), formatSize(size)))
+	return nil
+}
+
+// partialInfos converts partial downloads for display, with ages as of now.
+func partialInfos(now time.Time, partials []model.Partial) []ui.PartialInfo {
+	infos := make([]ui.PartialInfo, len(partials))
+	for i, p := range partials {
+		infos[i] = ui.PartialInfo{
+			Filename: p.Filename,
+			Size:     formatSize(p.Size),
+			Age:      formatAge(now.Sub(p.ModTime)),
+			Model:    p.Model,
+		}
+	}
+	return infos
+}
+
+// formatAge formats d in its largest whole unit, from minutes to days.
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
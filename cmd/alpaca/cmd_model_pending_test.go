@@ -0,0 +1,48 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+func TestPartialInfos(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	partials := []model.Partial{
+		{Filename: "m.gguf", Size: 1536 * 1024 * 1024, ModTime: now.Add(-90 * time.Second), Model: "h:org/m:Q4_K_M"},
+		{Filename: "n.gguf", Size: 0, ModTime: now.Add(-5 * time.Hour)},
+		{Filename: "o.gguf", Size: 0, ModTime: now.Add(-50 * time.Hour)},
+	}
+
+	// Act
+	infos := partialInfos(now, partials)
+
+	// Assert
+	want := []ui.PartialInfo{
+		{Filename: "m.gguf", Size: "1.5 GB", Age: "1m", Model: "h:org/m:Q4_K_M"},
+		{Filename: "n.gguf", Size: "0 B", Age: "5h"},
+		{Filename: "o.gguf", Size: "0 B", Age: "2d"},
+	}
+	if !slices.Equal(infos, want) {
+		t.Errorf("partialInfos() = %+v, want %+v", infos, want)
+	}
+}
+
+func TestModelPendingRmCmd_UnknownFile(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	cmd := &ModelPendingRmCmd{Files: []string{"missing.gguf"}}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "no partial download of 'missing.gguf'") {
+		t.Fatalf("expected unknown partial error, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelPullCmd struct {
+	Specs       []string `arg:"" help:"Models to download (format: [h:]org/repo:quant, [h:]org/repo:auto, or [h:]org/repo:adapter.gguf; ms: for ModelScope, oci:// for OCI registries)"`
+	Concurrency int      `short:"j" default:"2" help:"Number of models to download at the same time"`
+	Endpoint    string   `help:"HuggingFace endpoint or mirror to download from (overrides HF_ENDPOINT and config)" placeholder:"URL"`
+	Verify      bool     `help:"Hash files already downloaded in full instead of trusting their recorded checksums"`
+}
+
+func (c *ModelPullCmd) Run() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	ids, err := parsePullSpecs(c.Specs)
+	if err != nil {
+		return err
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+
+	puller, err := newPuller(paths, paths.Models, c.Endpoint)
+	if err != nil {
+		return err
+	}
+	puller.SetVerify(c.Verify)
+
+	if err := confirmPullTerms(puller, paths.Models, ids); err != nil {
+		if errors.Is(err, errDownloadDeclined) {
+			ui.PrintInfo("Cancelled")
+			return nil
+		}
+		return err
+	}
+
+	outcomes := pullMany(context.Background(), puller, ids, c.Concurrency)
+
+	fmt.Fprintln(ui.Output)
+	failed := 0
+	for _, o := range outcomes {
+		switch {
+		case o.err != nil:
+			failed++
+			printDownloadError(o.id.Raw+": ", o.err)
+		case o.mmprojFailed:
+			failed++
+			ui.PrintWarning(fmt.Sprintf("%s: mmproj download failed - vision unavailable", o.id.Raw))
+		case o.upToDate:
+			ui.PrintSuccess(fmt.Sprintf("%s: already up to date", o.id.Raw))
+		case o.quant != "":
+			ui.PrintSuccess(fmt.Sprintf("%s: downloaded as %s", o.id.Raw, o.quant))
+		default:
+			ui.PrintSuccess(fmt.Sprintf("%s: downloaded", o.id.Raw))
+		}
+	}
+	if failed > 0 {
+		ui.PrintError(fmt.Sprintf("%d of %d downloads failed.", failed, len(outcomes)))
+		return errDownloadFailed()
+	}
+	return nil
+}
+
+// parsePullSpecs parses model specs, accepting an optional h: prefix.
+// Duplicates are dropped; other items of one repository can still share
+// files, which pullMany handles.
+func parsePullSpecs(specs []string) ([]*identifier.Identifier, error) {
+	var ids []*identifier.Identifier
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		raw := spec
+		if !identifier.HasPrefix(raw) {
+			raw = "h:" + raw
+		}
+		id, err := identifier.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid model %q: %w", spec, err)
+		}
+		if id.Type != identifier.TypeHuggingFace {
+			return nil, fmt.Errorf("invalid model %q: only HuggingFace models (h:), ModelScope models (ms:) and OCI artifacts (oci://) can be pulled\nFormat: org/repo:quant", spec)
+		}
+		if id.Quant == "" {
+			return nil, fmt.Errorf("invalid model %q: missing quant specifier\nFormat: org/repo:quant", spec)
+		}
+		if seen[id.Raw] {
+			continue
+		}
+		seen[id.Raw] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// pullOutcome is the result of one download in pullMany.
+type pullOutcome struct {
+	id           *identifier.Identifier
+	quant        string // quant chosen for :auto
+	err          error
+	upToDate     bool
+	mmprojFailed bool
+}
+
+// confirmPullTerms shows the license of each repository ids download
+// from and confirms restrictive terms as pull does, before any download
+// starts. Repositories whose items are all downloaded already are skipped.
+func confirmPullTerms(puller *pull.Puller, modelsDir string, ids []*identifier.Identifier) error {
+	ctx := context.Background()
+	mgr := model.NewManager(modelsDir)
+	confirmed := make(map[string]bool)
+	for _, id := range ids {
+		if confirmed[id.Registry+":"+id.Repo] {
+			continue
+		}
+		registry, err := pull.RegistryFor(id.Registry)
+		if err != nil {
+			continue // reported by its download
+		}
+		p := puller.Clone()
+		p.SetRegistry(registry)
+		if isDownloaded(ctx, p, mgr, id) {
+			continue
+		}
+		confirmed[id.Registry+":"+id.Repo] = true
+		terms, termsErr := p.GetRepoTerms(ctx, id.Repo)
+		if err := confirmRepoTerms(registry, id.Repo, terms, termsErr, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDownloaded reports whether the model or LoRA adapter id names is
+// downloaded. A :auto quant is resolved as pull resolves it, unless the
+// quant chosen by an earlier pull is downloaded.
+func isDownloaded(ctx context.Context, p *pull.Puller, mgr *model.Manager, id *identifier.Identifier) bool {
+	if id.IsRepoFile() {
+		downloaded, _ := mgr.LoraExists(ctx, id.Repo, id.Quant)
+		return downloaded
+	}
+	if downloaded, _ := mgr.Exists(ctx, id.Repo, id.Quant); downloaded || id.Quant != pull.AutoQuant {
+		return downloaded
+	}
+	info, err := p.GetFileInfo(ctx, id.Repo, id.Quant)
+	if err != nil {
+		return false
+	}
+	downloaded, _ := mgr.Exists(ctx, id.Repo, info.Quant)
+	return downloaded
+}
+
+// pullMany downloads models and LoRA adapters with at most concurrency
+// downloads in flight, rendering one progress line per item.
+// Items of one repository are downloaded one after another: they can share
+// files, such as the mmproj every quant of a vision model uses.
+// Outcomes are returned in the order of ids.
+func pullMany(ctx context.Context, puller *pull.Puller, ids []*identifier.Identifier, concurrency int) []pullOutcome {
+	labels := make([]string, len(ids))
+	repoLocks := make(map[string]*sync.Mutex)
+	for i, id := range ids {
+		labels[i] = id.Raw
+		if repoLocks[id.Registry+":"+id.Repo] == nil {
+			repoLocks[id.Registry+":"+id.Repo] = &sync.Mutex{}
+		}
+	}
+	progress := newMultiProgress(ui.Output, isTerminal(), labels)
+
+	outcomes := make([]pullOutcome, len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Taken before a slot, so a waiting item does not hold one
+			repoLock := repoLocks[id.Registry+":"+id.Repo]
+			repoLock.Lock()
+			defer repoLock.Unlock()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			p := puller.Clone()
+			if registry, err := pull.RegistryFor(id.Registry); err == nil {
+				p.SetRegistry(registry)
+			}
+			p.SetReportFunc(func(pr pull.Progress) {
+				progress.update(i, pr)
+			})
+			progress.start(i)
+
+			var result *pull.PullResult
+			var err error
+			if id.IsRepoFile() {
+				result, err = p.PullLora(ctx, id.Repo, id.Quant)
+			} else {
+				result, err = p.Pull(ctx, id.Repo, id.Quant)
+			}
+
+			outcome := pullOutcome{id: id, err: err}
+			status := "failed"
+			if err == nil {
+				outcome.quant = result.Quant
+				outcome.upToDate = result.AlreadyUpToDate
+				outcome.mmprojFailed = result.MmprojFailed
+				status = "done"
+				if result.AlreadyUpToDate {
+					status = "up to date"
+				}
+			}
+			outcomes[i] = outcome
+			progress.finish(i, status)
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
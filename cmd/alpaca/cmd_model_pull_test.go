@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+func TestParsePullSpecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []string
+		wantErr string
+	}{
+		{"with and without prefix", []string{"h:org/a:Q4_K_M", "org/b:Q8_0"}, []string{"h:org/a:Q4_K_M", "h:org/b:Q8_0"}, ""},
+		{"lora adapter", []string{"org/lora:adapter.gguf"}, []string{"h:org/lora:adapter.gguf"}, ""},
+		{"duplicates dropped", []string{"org/a:Q4_K_M", "h:org/a:Q4_K_M"}, []string{"h:org/a:Q4_K_M"}, ""},
+		{"ModelScope", []string{"ms:org/a:Q4_K_M"}, []string{"ms:org/a:Q4_K_M"}, ""},
+		{"preset rejected", []string{"p:coder"}, nil, "only HuggingFace models"},
+		{"missing quant", []string{"org/a"}, nil, "missing quant specifier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			ids, err := parsePullSpecs(tt.specs)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, id := range ids {
+				got = append(got, id.Raw)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullMany(t *testing.T) {
+	// Arrange
+	content := []byte("model-content")
+	hash := sha256.Sum256(content)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/org/missing/"):
+			http.NotFound(w, r)
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			fmt.Fprintf(w, `{"ggufFile":{"rfilename":"model-Q4_K_M.gguf","size":%d,"lfs":{"sha256":"%s"}}}`, len(content), hex.EncodeToString(hash[:]))
+		case strings.Contains(r.URL.Path, "/resolve/main/"):
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	puller := pull.NewPuller(t.TempDir())
+	if err := puller.SetEndpoints([]string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := parsePullSpecs([]string{"org/ok:Q4_K_M", "org/missing:Q4_K_M"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	// Act
+	outcomes := pullMany(context.Background(), puller, ids, 2)
+
+	// Assert
+	if len(outcomes) != 2 {
+		t.Fatalf("expected 2 outcomes, got %d", len(outcomes))
+	}
+	if outcomes[0].err != nil {
+		t.Errorf("org/ok: unexpected error %v", outcomes[0].err)
+	}
+	if outcomes[1].err == nil {
+		t.Error("org/missing: expected error")
+	}
+	if !strings.Contains(buf.String(), "h:org/ok:Q4_K_M: done") {
+		t.Errorf("expected done line in output, got:\n%s", buf.String())
+	}
+}
+
+func TestPullMany_SharedMmproj(t *testing.T) {
+	// Arrange
+	model := []byte("model-content")
+	mmproj := []byte("mmproj-content")
+	sum := func(b []byte) string {
+		h := sha256.Sum256(b)
+		return hex.EncodeToString(h[:])
+	}
+	var mu sync.Mutex
+	var active, maxActive int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			quant := path.Base(r.URL.Path)
+			fmt.Fprintf(w, `{"ggufFile":{"rfilename":"model-%s.gguf","size":%d,"lfs":{"sha256":"%s"}},"mmprojFile":{"rfilename":"mmproj-f16.gguf","size":%d,"lfs":{"sha256":"%s"}}}`,
+				quant, len(model), sum(model), len(mmproj), sum(mmproj))
+		case strings.Contains(r.URL.Path, "/resolve/main/"):
+			mu.Lock()
+			active++
+			maxActive = max(maxActive, active)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				active--
+				mu.Unlock()
+			}()
+			time.Sleep(20 * time.Millisecond)
+			if strings.HasSuffix(r.URL.Path, "mmproj-f16.gguf") {
+				w.Write(mmproj)
+			} else {
+				w.Write(model)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	modelsDir := t.TempDir()
+	puller := pull.NewPuller(modelsDir)
+	if err := puller.SetEndpoints([]string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := parsePullSpecs([]string{"org/vision:Q4_K_M", "org/vision:Q8_0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	origOutput := ui.Output
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = origOutput })
+
+	// Act
+	outcomes := pullMany(context.Background(), puller, ids, 2)
+
+	// Assert
+	for _, o := range outcomes {
+		if o.err != nil || o.mmprojFailed {
+			t.Errorf("%s: error = %v, mmprojFailed = %v", o.id.Raw, o.err, o.mmprojFailed)
+		}
+	}
+	if maxActive > 1 {
+		t.Errorf("%d downloads of one repository ran at once, want them one after another", maxActive)
+	}
+	got, err := os.ReadFile(filepath.Join(modelsDir, "org_vision_mmproj-f16.gguf"))
+	if err != nil || !bytes.Equal(got, mmproj) {
+		t.Errorf("shared mmproj = %q, %v, want %q", got, err, mmproj)
+	}
+}
+
+func TestConfirmPullTerms(t *testing.T) {
+	tests := []struct {
+		name       string
+		specs      []string
+		wantPrompt bool
+	}{
+		{"downloaded quant", []string{"org/gated:Q4_K_M"}, false},
+		{"quant chosen by an earlier auto pull", []string{"org/gated:auto"}, false},
+		{"new quant after a downloaded one", []string{"org/gated:Q4_K_M", "org/gated:Q8_0"}, true},
+		{"new repository", []string{"org/other:Q4_K_M"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"gated":"manual","cardData":{"license":"llama3.1"}}`)
+			}))
+			t.Cleanup(srv.Close)
+			modelsDir := t.TempDir()
+			meta := metadata.NewManager(modelsDir)
+			meta.Add(metadata.ModelEntry{Repo: "org/gated", Quant: "Q4_K_M", Filename: "m.gguf", AutoSelected: true})
+			if err := meta.Save(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			puller := pull.NewPuller(modelsDir)
+			if err := puller.SetEndpoints([]string{srv.URL}); err != nil {
+				t.Fatal(err)
+			}
+			ids, err := parsePullSpecs(tt.specs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			ui.Output = &buf
+			t.Cleanup(func() { ui.Output = os.Stdout })
+			setStdinInput(t, "y\n")
+			origYes, origTerminal := assumeYes, stdinIsTerminal
+			t.Cleanup(func() { assumeYes, stdinIsTerminal = origYes, origTerminal })
+			assumeYes = false
+			stdinIsTerminal = func() bool { return true }
+
+			// Act
+			err = confirmPullTerms(puller, modelsDir, ids)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("confirmPullTerms() error = %v", err)
+			}
+			if prompted := strings.Contains(buf.String(), "(y/N)"); prompted != tt.wantPrompt {
+				t.Errorf("prompt shown = %v, want %v\noutput: %s", prompted, tt.wantPrompt, buf.String())
+			}
+		})
+	}
+}
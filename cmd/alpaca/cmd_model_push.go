@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelPushCmd struct {
+	Model  string `arg:"" help:"Downloaded model to push (h:org/repo:quant)" predictor:"model-identifier"`
+	Target string `arg:"" help:"Where to push it (format: oci://registry/repository:tag; the tag defaults to latest)"`
+	Verify bool   `help:"Hash the model files in full instead of trusting their recorded checksums"`
+}
+
+func (c *ModelPushCmd) Run() error {
+	id, err := identifier.Parse(c.Model)
+	if err != nil {
+		return fmt.Errorf("invalid identifier: %w", err)
+	}
+	if id.Type != identifier.TypeHuggingFace || id.Quant == "" || id.IsRepoFile() {
+		return fmt.Errorf("expected a downloaded model (h:org/repo:quant), got %q", c.Model)
+	}
+	target, err := identifier.Parse(c.Target)
+	if err != nil || target.Registry != identifier.RegistryOCI {
+		return fmt.Errorf("invalid target %q\nFormat: oci://registry/repository:tag", c.Target)
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	entry, err := model.NewManager(paths.Models).GetDetails(ctx, id.Repo, id.Quant)
+	if err != nil {
+		var notFound *metadata.NotFoundError
+		if errors.As(err, &notFound) {
+			return errModelNotFound(c.Model)
+		}
+		return err
+	}
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return err
+	}
+	puller.SetVerify(c.Verify)
+	puller.SetReportFunc(printProgress)
+	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
+		ui.PrintInfo(fmt.Sprintf("[%d/%d] Uploading %s (%s)...", index, total, filename, formatSize(size)))
+	})
+
+	digest, err := puller.PushOCI(ctx, *entry, target.Repo, target.Quant)
+	if err != nil {
+		endProgressLine()
+		return err
+	}
+	fmt.Fprintln(ui.Output)
+	ui.PrintSuccess(fmt.Sprintf("Pushed %s as %s (%s)", c.Model, target.Raw, digest))
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelRebuildMetadataCmd struct {
+	DryRun bool `help:"Show what would be recorded without changing anything"`
+}
+
+func (c *ModelRebuildMetadataCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	if !c.DryRun {
+		ui.PrintInfo("Scanning and hashing model files...")
+	}
+	result, err := model.NewManager(paths.Models).RebuildMetadata(context.Background(), c.DryRun)
+	if err != nil {
+		return err
+	}
+
+	recorded, dropped := "Recorded", "Dropped"
+	if c.DryRun {
+		recorded, dropped = "Would record", "Would drop"
+	}
+	if result.Corrupt {
+		ui.PrintWarning(fmt.Sprintf("Model metadata could not be parsed and was rebuilt from scratch (saved as %s)", model.BackupFileName))
+	}
+	for _, spec := range result.Dropped {
+		ui.PrintWarning(fmt.Sprintf("%s %s: its files are missing", dropped, spec))
+	}
+	for _, e := range result.Models {
+		msg := fmt.Sprintf("%s h:%s:%s (%s)", recorded, e.Repo, e.Quant, formatSize(e.Size))
+		if e.Mmproj != nil {
+			msg += " with mmproj"
+		}
+		if e.Source != "" {
+			msg += " from " + e.Filename + ", repository unknown"
+		}
+		ui.PrintInfo(msg)
+	}
+	for _, l := range result.Loras {
+		ui.PrintInfo(fmt.Sprintf("%s LoRA adapter h:%s:%s (%s)", recorded, l.Repo, l.File, formatSize(l.Size)))
+	}
+	for _, s := range result.Skipped {
+		ui.PrintWarning("Skipped " + s)
+	}
+
+	if c.DryRun {
+		return nil
+	}
+	ui.PrintSuccess(fmt.Sprintf("Rebuilt model metadata: kept %d, recorded %d, dropped %d.",
+		result.Kept, len(result.Models)+len(result.Loras), len(result.Dropped)))
+	if slices.ContainsFunc(result.Models, func(e metadata.ModelEntry) bool { return e.Source != "" }) {
+		ui.PrintInfo(fmt.Sprintf("Models under %s/ are not checked for updates. Pull them again by name to record their repository.", model.LocalOrg))
+	}
+	return nil
+}
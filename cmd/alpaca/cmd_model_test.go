@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/model"
+)
+
+func TestParseModelName(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantRepo  string
+		wantQuant string
+		wantErr   bool
+	}{
+		{"valid", "me/custom:Q4_K_M", "me/custom", "Q4_K_M", false},
+		{"missing quant", "me/custom", "", "", true},
+		{"missing org", "custom:Q4_K_M", "", "", true},
+		{"gguf file quant", "me/custom:adapter.gguf", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			repo, quant, err := parseModelName(tt.input)
+
+			// Assert
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid model name") {
+					t.Fatalf("expected invalid model name error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo != tt.wantRepo || quant != tt.wantQuant {
+				t.Errorf("got %s:%s, want %s:%s", repo, quant, tt.wantRepo, tt.wantQuant)
+			}
+		})
+	}
+}
+
+func TestModelAddCmd_LinkWithURL(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	cmd := &ModelAddCmd{Source: "https://example.com/model.gguf", Name: "me/custom:Q4_K_M", Link: true}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "--link") {
+		t.Fatalf("expected --link error, got %v", err)
+	}
+}
+
+func TestModelAddCmd_ManifestWithName(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	cmd := &ModelAddCmd{Source: "/media/usb/model" + model.ManifestSuffix, Name: "me/custom:Q4_K_M"}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "do not apply to a manifest") {
+		t.Fatalf("expected manifest option error, got %v", err)
+	}
+}
+
+func TestParseQuantsRepo(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{"org/model-GGUF", "org/model-GGUF", ""},
+		{"h:org/model-GGUF", "org/model-GGUF", ""},
+		{"org/model-GGUF:Q4_K_M", "", "remove the quant"},
+		{"model-GGUF", "", "Format: org/repo"},
+		{"org/a/b", "", "Format: org/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			// Act
+			got, err := parseQuantsRepo(tt.spec)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseQuantsRepo(%q) = %q, %v, want %q", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ModelOutdatedCmd struct{}
+
+func (c *ModelOutdatedCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return err
+	}
+
+	outdated, checked, err := findOutdated(context.Background(), puller, paths.Models)
+	if err != nil {
+		return err
+	}
+	if checked == 0 {
+		ui.PrintInfo("No HuggingFace models downloaded.")
+		return nil
+	}
+	if len(outdated) == 0 {
+		ui.PrintSuccess(fmt.Sprintf("All %d model(s) are up to date.", checked))
+		return nil
+	}
+
+	for _, e := range outdated {
+		ui.PrintWarning(fmt.Sprintf("%s has an update available", identifier.Model(e.Registry, e.Repo, e.Quant)))
+	}
+	ui.PrintInfo("Run: alpaca model upgrade --all")
+	return nil
+}
+
+type ModelUpgradeCmd struct {
+	Specs       []string `arg:"" optional:"" help:"Models to upgrade (format: [h:]org/repo:quant)"`
+	All         bool     `help:"Upgrade every model that changed upstream"`
+	Concurrency int      `short:"j" default:"2" help:"Number of models to download at the same time"`
+}
+
+func (c *ModelUpgradeCmd) Run() error {
+	if c.All == (len(c.Specs) > 0) {
+		return fmt.Errorf("specify models to upgrade or --all, not both")
+	}
+	if c.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return err
+	}
+
+	var ids []*identifier.Identifier
+	if c.All {
+		outdated, _, err := findOutdated(context.Background(), puller, paths.Models)
+		if err != nil {
+			return err
+		}
+		if len(outdated) == 0 {
+			ui.PrintSuccess("All models are up to date.")
+			return nil
+		}
+		for _, e := range outdated {
+			ids = append(ids, &identifier.Identifier{
+				Raw:      identifier.Model(e.Registry, e.Repo, e.Quant),
+				Type:     identifier.TypeHuggingFace,
+				Repo:     e.Repo,
+				Quant:    e.Quant,
+				Registry: e.Registry,
+			})
+		}
+	} else {
+		ids, err = parsePullSpecs(c.Specs)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := confirmPullTerms(puller, paths.Models, ids); err != nil {
+		if errors.Is(err, errDownloadDeclined) {
+			ui.PrintInfo("Cancelled")
+			return nil
+		}
+		return err
+	}
+
+	outcomes := pullMany(context.Background(), puller, ids, c.Concurrency)
+
+	fmt.Fprintln(ui.Output)
+	failed := 0
+	for _, o := range outcomes {
+		switch {
+		case o.err != nil:
+			failed++
+			printDownloadError(o.id.Raw+": ", o.err)
+		case o.upToDate:
+			ui.PrintSuccess(fmt.Sprintf("%s: already up to date", o.id.Raw))
+		default:
+			ui.PrintSuccess(fmt.Sprintf("%s: upgraded", o.id.Raw))
+		}
+	}
+	if failed > 0 {
+		ui.PrintError(fmt.Sprintf("%d of %d upgrades failed.", failed, len(outcomes)))
+		return errDownloadFailed()
+	}
+	return nil
+}
+
+// findOutdated checks every HuggingFace model in metadata against upstream.
+// Models added from URLs or local files are skipped. Check failures are
+// printed as warnings and do not abort the scan.
+func findOutdated(ctx context.Context, puller *pull.Puller, modelsDir string) (outdated []metadata.ModelEntry, checked int, err error) {
+	entries, err := model.NewManager(modelsDir).List(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, e := range entries {
+		if e.Source != "" {
+			continue
+		}
+		checked++
+		stale, err := puller.CheckOutdated(ctx, e)
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("%s: check failed: %v", identifier.Model(e.Registry, e.Repo, e.Quant), err))
+			continue
+		}
+		if stale {
+			outdated = append(outdated, e)
+		}
+	}
+	return outdated, checked, nil
+}
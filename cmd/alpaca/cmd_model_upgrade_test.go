@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/pull"
+)
+
+func TestModelUpgradeCmd_RequiresSpecsOrAll(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  ModelUpgradeCmd
+	}{
+		{"neither", ModelUpgradeCmd{Concurrency: 2}},
+		{"both", ModelUpgradeCmd{Specs: []string{"org/a:Q4_K_M"}, All: true, Concurrency: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.cmd.Run()
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), "--all") {
+				t.Fatalf("expected specs/--all error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFindOutdated(t *testing.T) {
+	// Arrange
+	current := sha256.Sum256([]byte("current"))
+	currentHash := hex.EncodeToString(current[:])
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ggufFile":{"rfilename":"m.gguf","size":7,"lfs":{"sha256":"%s"}}}`, currentHash)
+	}))
+	t.Cleanup(srv.Close)
+
+	modelsDir := t.TempDir()
+	meta := metadata.NewManager(modelsDir)
+	meta.Add(metadata.ModelEntry{Repo: "org/fresh", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: currentHash})
+	meta.Add(metadata.ModelEntry{Repo: "org/stale", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: "old"})
+	meta.Add(metadata.ModelEntry{Repo: "me/local", Quant: "Q4_K_M", Filename: "l.gguf", Source: "/tmp/l.gguf"})
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	puller := pull.NewPuller(modelsDir)
+	if err := puller.SetEndpoints([]string{srv.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	outdated, checked, err := findOutdated(context.Background(), puller, modelsDir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("findOutdated() error = %v", err)
+	}
+	if checked != 2 {
+		t.Errorf("checked = %d, want 2 (local import skipped)", checked)
+	}
+	if len(outdated) != 1 || outdated[0].Repo != "org/stale" {
+		t.Errorf("outdated = %+v, want only org/stale", outdated)
+	}
+}
@@ -29,7 +29,7 @@ func (c *NewCmd) runGlobal() error {
 		return err
 	}
 
-	fmt.Fprintf(ui.Output, "📦 %s\n", ui.Heading("Create Preset"))
+	ui.PrintHeading("📦", "Create Preset")
 
 	// Prompt for name
 	name, err := promptLine("Name", "")
@@ -61,7 +61,7 @@ func (c *NewCmd) runGlobal() error {
 	}
 
 	ui.PrintSuccess(fmt.Sprintf("Created '%s'", name))
-	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info("💡"), ui.Info(fmt.Sprintf("alpaca load p:%s", name)))
+	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info(fmt.Sprintf("alpaca load p:%s", name)))
 	return nil
 }
 
@@ -77,7 +77,7 @@ func (c *NewCmd) runLocal() error {
 		return fmt.Errorf("%s already exists", LocalPresetFile)
 	}
 
-	fmt.Fprintf(ui.Output, "📦 %s\n", ui.Heading("Create Local Preset"))
+	ui.PrintHeading("📦", "Create Local Preset")
 
 	// Default name from directory name
 	defaultName := preset.SanitizeName(filepath.Base(cwd))
@@ -105,7 +105,7 @@ func (c *NewCmd) runLocal() error {
 	}
 
 	ui.PrintSuccess(fmt.Sprintf("Created '%s'", LocalPresetFile))
-	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info("💡"), ui.Info("alpaca load"))
+	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca load"))
 	return nil
 }
 
@@ -175,7 +175,8 @@ func (c *NewCmd) collectRouterInputs(name string) (*preset.Preset, error) {
 		return nil, err
 	}
 
-	fmt.Fprintf(ui.Output, "\n🤖 %s\n", ui.Heading("Add Models (enter blank name to finish)"))
+	fmt.Fprintln(ui.Output)
+	ui.PrintHeading("🤖", "Add Models (enter blank name to finish)")
 
 	var models []preset.ModelEntry
 	for i := 1; ; {
@@ -14,9 +14,9 @@ import (
 // Returns a cleanup function that restores the original reader.
 func setStdinInput(t *testing.T, input string) {
 	t.Helper()
-	original := stdin
-	stdin = bufio.NewReader(strings.NewReader(input))
-	t.Cleanup(func() { stdin = original })
+	original := ui.Input
+	ui.Input = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { ui.Input = original })
 }
 
 func TestCollectRouterInputs(t *testing.T) {
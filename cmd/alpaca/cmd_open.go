@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"os/exec"
 
+	"github.com/d2verb/alpaca/internal/protocol"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
@@ -19,18 +21,20 @@ func (c *OpenCmd) Run() error {
 		return err
 	}
 
-	resp, err := cl.Status()
+	resp, err := cl.Status(context.Background())
 	if err != nil {
-		return errDaemonNotRunning()
+		return clientError(err)
 	}
 
-	state, _ := resp.Data["state"].(string)
-	endpoint, _ := resp.Data["endpoint"].(string)
+	var data protocol.StatusData
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
 
-	if state != "running" || endpoint == "" {
+	if data.State != "running" || data.Endpoint == "" {
 		return errServerNotRunning()
 	}
 
-	ui.PrintInfo("Opening " + endpoint + " in browser...")
-	return openBrowser(endpoint)
+	ui.PrintInfo("Opening " + data.Endpoint + " in browser...")
+	return openBrowser(data.Endpoint)
 }
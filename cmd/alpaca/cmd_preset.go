@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+// PresetCmd groups preset management subcommands.
+type PresetCmd struct {
+	Init   PresetInitCmd   `cmd:"" help:"Write a commented starter preset filled in with your downloaded models"`
+	Pull   PresetPullCmd   `cmd:"" help:"Download every model a preset references"`
+	Lint   PresetLintCmd   `cmd:"" help:"Check presets for missing models, unknown llama-server options, and bad ports"`
+	Rename PresetRenameCmd `cmd:"" help:"Rename a preset"`
+
+	ImportLlamaSwap PresetImportLlamaSwapCmd `cmd:"" name:"import-llama-swap" help:"Convert a llama-swap config into a router preset"`
+}
+
+type PresetRenameCmd struct {
+	Identifier string `arg:"" help:"Preset to rename (p:name)" predictor:"preset-identifier"`
+	NewName    string `arg:"" help:"New preset name"`
+}
+
+func (c *PresetRenameCmd) Run() error {
+	id, err := identifier.Parse(c.Identifier)
+	if err != nil {
+		return fmt.Errorf("invalid identifier: %w", err)
+	}
+	if id.Type != identifier.TypePresetName {
+		return fmt.Errorf("only presets can be renamed\nUse: alpaca preset rename p:old-name new-name")
+	}
+	newName := strings.TrimPrefix(c.NewName, "p:")
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if err := preset.NewLoader(paths.Presets).Rename(id.PresetName, newName); err != nil {
+		return mapPresetError(err, id.PresetName)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Preset '%s' renamed to '%s'", id.PresetName, newName))
+	return nil
+}
+
+type PresetPullCmd struct {
+	Identifier  string `arg:"" optional:"" help:"Preset (p:name or f:path/to/preset.yaml); defaults to the nearest .alpaca.yaml" predictor:"edit-identifier"`
+	DryRun      bool   `help:"Show what would be downloaded and the total size without downloading"`
+	Concurrency int    `short:"j" default:"2" help:"Number of models to download at the same time"`
+	Endpoint    string `help:"HuggingFace endpoint or mirror to download from (overrides HF_ENDPOINT and config)" placeholder:"URL"`
+}
+
+func (c *PresetPullCmd) Run() error {
+	if c.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	p, err := loadPresetForCommand(paths.Presets, c.Identifier)
+	if err != nil {
+		return err
+	}
+
+	refs, files, err := presetModelRefs(p)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Local file not found: %s", f))
+		}
+	}
+
+	missing, err := missingRefs(context.Background(), paths.Models, refs)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		ui.PrintSuccess(fmt.Sprintf("All models for '%s' are already downloaded.", p.Name))
+		return nil
+	}
+
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+	puller, err := newPuller(paths, paths.Models, c.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	if c.DryRun {
+		return printPullPlan(context.Background(), puller, missing)
+	}
+
+	ui.PrintInfo(fmt.Sprintf("Downloading %d missing model(s) for '%s'...", len(missing), p.Name))
+	outcomes := pullMany(context.Background(), puller, missing, c.Concurrency)
+
+	failed := 0
+	for _, o := range outcomes {
+		if o.err != nil {
+			failed++
+			ui.PrintError(fmt.Sprintf("%s: %v", o.id.Raw, o.err))
+		} else if o.mmprojFailed {
+			failed++
+			ui.PrintWarning(fmt.Sprintf("%s: mmproj download failed - vision unavailable", o.id.Raw))
+		}
+	}
+	if failed > 0 {
+		ui.PrintError(fmt.Sprintf("%d of %d downloads failed.", failed, len(outcomes)))
+		return errDownloadFailed()
+	}
+	ui.PrintSuccess(fmt.Sprintf("All models for '%s' are downloaded.", p.Name))
+	return nil
+}
+
+// loadPresetForCommand loads a preset from a p:/f: identifier, defaulting
+// to the nearest .alpaca.yaml.
+func loadPresetForCommand(presetsDir, input string) (*preset.Preset, error) {
+	idStr, err := resolveLocalPreset(input)
+	if err != nil {
+		return nil, err
+	}
+	id, err := identifier.Parse(idStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identifier: %w", err)
+	}
+
+	switch id.Type {
+	case identifier.TypePresetName:
+		p, err := preset.NewLoader(presetsDir).Load(id.PresetName)
+		if err != nil {
+			return nil, mapPresetError(err, id.PresetName)
+		}
+		return p, nil
+	case identifier.TypePresetFilePath:
+		return preset.LoadFile(id.FilePath)
+	default:
+		return nil, fmt.Errorf("expected a preset (p:name or f:path/to/preset.yaml), got %q", input)
+	}
+}
+
+// presetModelRefs collects the HuggingFace models, draft models, and LoRA
+// adapters a preset references, plus any local files it points to.
+// Duplicates are dropped. mmproj files for h: models are fetched with the model.
+func presetModelRefs(p *preset.Preset) ([]*identifier.Identifier, []string, error) {
+	var refs []*identifier.Identifier
+	var files []string
+	seen := make(map[string]bool)
+
+	add := func(ref string) error {
+		if ref == "" || ref == "none" || seen[ref] {
+			return nil
+		}
+		seen[ref] = true
+		id, err := identifier.Parse(ref)
+		if err != nil {
+			return fmt.Errorf("invalid model reference %q: %w", ref, err)
+		}
+		switch id.Type {
+		case identifier.TypeHuggingFace:
+			if id.Quant != "" {
+				refs = append(refs, id)
+			}
+		case identifier.TypeModelFilePath:
+			files = append(files, id.FilePath)
+		}
+		return nil
+	}
+
+	addAll := func(modelRef, draft, mmproj string, loras []string) error {
+		for _, ref := range append([]string{modelRef, draft, mmproj}, loras...) {
+			if err := add(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if p.IsRouter() {
+		for _, m := range p.Models {
+			if err := addAll(m.Model, m.DraftModel, m.Mmproj, m.Lora); err != nil {
+				return nil, nil, fmt.Errorf("model '%s': %w", m.Name, err)
+			}
+		}
+	} else if err := addAll(p.Model, p.DraftModel, p.Mmproj, p.Lora); err != nil {
+		return nil, nil, err
+	}
+	return refs, files, nil
+}
+
+// missingRefs returns the references that are not downloaded yet.
+func missingRefs(ctx context.Context, modelsDir string, refs []*identifier.Identifier) ([]*identifier.Identifier, error) {
+	mgr := model.NewManager(modelsDir)
+	var missing []*identifier.Identifier
+	for _, id := range refs {
+		var exists bool
+		var err error
+		if id.IsRepoFile() {
+			exists, err = mgr.LoraExists(ctx, id.Repo, id.Quant)
+		} else {
+			exists, err = mgr.Exists(ctx, id.Repo, id.Quant)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+// printPullPlan prints each missing reference with its download size and the total.
+func printPullPlan(ctx context.Context, puller *pull.Puller, ids []*identifier.Identifier) error {
+	ui.PrintInfo(fmt.Sprintf("Would download %d model(s):", len(ids)))
+	var total int64
+	for _, id := range ids {
+		var info *pull.FileInfo
+		var err error
+		if id.IsRepoFile() {
+			info, err = puller.GetLoraInfo(ctx, id.Repo, id.Quant)
+		} else {
+			info, err = puller.GetFileInfo(ctx, id.Repo, id.Quant)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", id.Raw, err)
+		}
+		size := info.Size + info.MmprojSize
+		total += size
+		line := fmt.Sprintf("  %s  (%s)", id.Raw, formatSize(size))
+		if info.MmprojOriginalFilename != "" {
+			line += " incl. mmproj"
+		}
+		fmt.Fprintln(ui.Output, line)
+	}
+	ui.PrintInfo(fmt.Sprintf("Total: %s", formatSize(total)))
+	return nil
+}
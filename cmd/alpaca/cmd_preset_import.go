@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/ui"
+	"gopkg.in/yaml.v3"
+)
+
+type PresetImportLlamaSwapCmd struct {
+	Path string `arg:"" help:"llama-swap config file" type:"existingfile"`
+	Name string `help:"Preset name" default:"llama-swap"`
+}
+
+func (c *PresetImportLlamaSwapCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(c.Path)
+	if err != nil {
+		return fmt.Errorf("read llama-swap config: %w", err)
+	}
+
+	name := strings.TrimPrefix(c.Name, "p:")
+	if err := preset.ValidateName(name); err != nil {
+		return fmt.Errorf("invalid name: %w", err)
+	}
+	p, notes, err := preset.ImportLlamaSwap(data, name)
+	for _, n := range notes {
+		ui.PrintWarning(n)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal preset: %w", err)
+	}
+	header := fmt.Sprintf("# Imported from %s by alpaca preset import-llama-swap.\n", filepath.Base(c.Path))
+	path, err := preset.NewLoader(paths.Presets).CreateYAML(append([]byte(header), out...))
+	var exists *preset.AlreadyExistsError
+	if errors.As(err, &exists) {
+		return fmt.Errorf("%w\nChoose another name: alpaca preset import-llama-swap %s --name NAME", err, c.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Created '%s' with %d model(s) (%s)", name, len(p.Models), path))
+	refs, _, err := presetModelRefs(p)
+	if err != nil {
+		return err
+	}
+	missing, err := missingRefs(context.Background(), paths.Models, refs)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca preset pull p:"+name))
+		return nil
+	}
+	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca load p:"+name))
+	return nil
+}
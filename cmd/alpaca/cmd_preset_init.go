@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+// Models written into a starter preset when no suitable model is downloaded.
+const (
+	starterChatModel      = "h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M"
+	starterEmbeddingModel = "h:nomic-ai/nomic-embed-text-v2-moe-GGUF:Q4_K_M"
+)
+
+// routerStarterChatModels is how many chat models a router starter preset
+// fills in.
+const routerStarterChatModels = 2
+
+type PresetInitCmd struct {
+	Name      string `arg:"" optional:"" help:"Preset name (default: derived from the model, or the directory name with --local)"`
+	Router    bool   `help:"Router mode: several models served by one llama-server"`
+	Vision    bool   `help:"Use a downloaded model with an mmproj"`
+	Embedding bool   `help:"Serve embeddings"`
+	Local     bool   `help:"Write .alpaca.yaml in the current directory instead"`
+}
+
+func (c *PresetInitCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	entries, err := model.NewManager(paths.Models).List(context.Background())
+	if err != nil {
+		return err
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	opts := c.starterOptions(entries)
+	if c.Name != "" {
+		opts.Name = strings.TrimPrefix(c.Name, "p:")
+	} else if name := preset.SanitizeName(filepath.Base(cwd)); c.Local && name != "" {
+		opts.Name = name
+	}
+	if err := preset.ValidateName(opts.Name); err != nil {
+		return fmt.Errorf("invalid name: %w", err)
+	}
+	data := preset.Template(opts)
+
+	var path, load string
+	if c.Local {
+		path = filepath.Join(cwd, LocalPresetFile)
+		if err := writeNewFile(path, data); err != nil {
+			return err
+		}
+	} else {
+		path, err = preset.NewLoader(paths.Presets).CreateYAML(data)
+		var exists *preset.AlreadyExistsError
+		if errors.As(err, &exists) {
+			return fmt.Errorf("%w\nChoose another name: alpaca preset init NAME", err)
+		}
+		if err != nil {
+			return err
+		}
+		load = " p:" + opts.Name
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Created '%s' (%s)", opts.Name, path))
+	if missing := starterMissing(opts, entries); len(missing) > 0 {
+		ui.PrintInfo(fmt.Sprintf("Not downloaded yet: %s", strings.Join(missing, ", ")))
+		fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca preset pull"+load))
+		return nil
+	}
+	fmt.Fprintf(ui.Output, "%s %s\n", ui.Info(ui.Symbol("💡")), ui.Info("alpaca load"+load))
+	return nil
+}
+
+// writeNewFile writes data to path, failing if it exists.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists", filepath.Base(path))
+	}
+	if err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Base(path), err)
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// starterOptions picks the downloaded models a starter preset is filled
+// in with: a model with an mmproj for --vision, an embedding model for
+// --embedding, chat models otherwise. A placeholder stands in for a kind
+// of model that is not downloaded. The rest are listed in comments.
+func (c *PresetInitCmd) starterOptions(entries []metadata.ModelEntry) preset.TemplateOptions {
+	opts := preset.TemplateOptions{Router: c.Router, Vision: c.Vision, Embedding: c.Embedding}
+
+	// Chat models with an mmproj come first for --vision
+	var vision, chat, embedding []metadata.ModelEntry
+	for _, e := range entries {
+		switch {
+		case isEmbeddingModel(e):
+			embedding = append(embedding, e)
+		case c.Vision && e.Mmproj != nil:
+			vision = append(vision, e)
+		default:
+			chat = append(chat, e)
+		}
+	}
+	chat = append(vision, chat...)
+
+	used := make(map[string]bool)
+	add := func(ref string, options preset.Options) {
+		opts.Models = append(opts.Models, preset.ModelEntry{Name: starterModelName(ref, opts.Models), Model: ref, Options: options})
+		used[ref] = true
+	}
+	switch {
+	case !c.Router && c.Embedding:
+		add(firstRef(embedding, starterEmbeddingModel), nil)
+	case !c.Router && c.Vision:
+		add(firstRef(vision, starterChatModel), nil)
+	case !c.Router:
+		add(firstRef(chat, starterChatModel), nil)
+	default:
+		for _, e := range chat[:min(len(chat), routerStarterChatModels)] {
+			add(modelRef(e), nil)
+		}
+		if len(opts.Models) == 0 {
+			add(starterChatModel, nil)
+		}
+		if c.Embedding {
+			add(firstRef(embedding, starterEmbeddingModel), preset.Options{"embeddings": "true"})
+		}
+	}
+
+	for _, e := range entries {
+		if ref := modelRef(e); !used[ref] {
+			opts.Others = append(opts.Others, ref)
+		}
+	}
+	if c.Router {
+		opts.Name = "workspace"
+	} else {
+		opts.Name = opts.Models[0].Name
+	}
+	return opts
+}
+
+// starterMissing returns the models of opts that are not downloaded.
+func starterMissing(opts preset.TemplateOptions, entries []metadata.ModelEntry) []string {
+	var missing []string
+	for _, m := range opts.Models {
+		if !slices.ContainsFunc(entries, func(e metadata.ModelEntry) bool { return modelRef(e) == m.Model }) {
+			missing = append(missing, m.Model)
+		}
+	}
+	return missing
+}
+
+// modelRef returns the h: identifier of a downloaded model.
+func modelRef(e metadata.ModelEntry) string {
+	return fmt.Sprintf("h:%s:%s", e.Repo, e.Quant)
+}
+
+// firstRef returns the identifier of the first of entries, or fallback.
+func firstRef(entries []metadata.ModelEntry, fallback string) string {
+	if len(entries) == 0 {
+		return fallback
+	}
+	return modelRef(entries[0])
+}
+
+// isEmbeddingModel reports whether e looks like an embedding model, going
+// by its repository name.
+func isEmbeddingModel(e metadata.ModelEntry) bool {
+	return strings.Contains(strings.ToLower(e.Repo), "embed")
+}
+
+// starterModelName derives a model name from an h: identifier, e.g.
+// "gemma-3-4b-it" from h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M, made unique
+// among taken.
+func starterModelName(ref string, taken []preset.ModelEntry) string {
+	repo, _, _ := strings.Cut(strings.TrimPrefix(ref, "h:"), ":")
+	name := repo[strings.LastIndex(repo, "/")+1:]
+	if i := strings.LastIndex(strings.ToLower(name), "-gguf"); i > 0 {
+		name = name[:i]
+	}
+	name = preset.SanitizeName(strings.ToLower(name))
+	if name == "" {
+		name = "model"
+	}
+	unique := name
+	for i := 2; slices.ContainsFunc(taken, func(m preset.ModelEntry) bool { return m.Name == unique }); i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	return unique
+}
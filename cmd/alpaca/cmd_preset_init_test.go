@@ -0,0 +1,84 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func TestPresetInitCmd_StarterOptions(t *testing.T) {
+	downloaded := []metadata.ModelEntry{
+		{Repo: "Qwen/Qwen3-8B-GGUF", Quant: "Q4_K_M"},
+		{Repo: "ggml-org/gemma-3-4b-it-GGUF", Quant: "Q4_K_M", Mmproj: &metadata.MmprojEntry{Filename: "mmproj.gguf"}},
+		{Repo: "nomic-ai/nomic-embed-text-v2-moe-GGUF", Quant: "Q8_0"},
+	}
+
+	tests := []struct {
+		name       string
+		cmd        PresetInitCmd
+		entries    []metadata.ModelEntry
+		wantName   string
+		wantModels []string
+		wantOthers []string
+	}{
+		{
+			name:       "single picks the first chat model",
+			entries:    downloaded,
+			wantName:   "qwen3-8b",
+			wantModels: []string{"h:Qwen/Qwen3-8B-GGUF:Q4_K_M"},
+			wantOthers: []string{"h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M", "h:nomic-ai/nomic-embed-text-v2-moe-GGUF:Q8_0"},
+		},
+		{
+			name:       "vision picks a model with an mmproj",
+			cmd:        PresetInitCmd{Vision: true},
+			entries:    downloaded,
+			wantName:   "gemma-3-4b-it",
+			wantModels: []string{"h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M"},
+			wantOthers: []string{"h:Qwen/Qwen3-8B-GGUF:Q4_K_M", "h:nomic-ai/nomic-embed-text-v2-moe-GGUF:Q8_0"},
+		},
+		{
+			name:       "router with embedding",
+			cmd:        PresetInitCmd{Router: true, Embedding: true},
+			entries:    downloaded,
+			wantName:   "workspace",
+			wantModels: []string{"h:Qwen/Qwen3-8B-GGUF:Q4_K_M", "h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M", "h:nomic-ai/nomic-embed-text-v2-moe-GGUF:Q8_0"},
+		},
+		{
+			name:       "placeholder without downloaded models",
+			cmd:        PresetInitCmd{Embedding: true},
+			wantName:   "nomic-embed-text-v2-moe",
+			wantModels: []string{starterEmbeddingModel},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			opts := tt.cmd.starterOptions(tt.entries)
+
+			// Assert
+			var models []string
+			for _, m := range opts.Models {
+				models = append(models, m.Model)
+			}
+			if opts.Name != tt.wantName || !slices.Equal(models, tt.wantModels) || !slices.Equal(opts.Others, tt.wantOthers) {
+				t.Errorf("name = %s, models = %v, others = %v, want %s, %v, %v", opts.Name, models, opts.Others, tt.wantName, tt.wantModels, tt.wantOthers)
+			}
+		})
+	}
+}
+
+func TestStarterModelName(t *testing.T) {
+	// Arrange
+	taken := []preset.ModelEntry{{Name: "qwen3-8b"}}
+
+	// Act
+	got := starterModelName("h:Qwen/Qwen3-8B-GGUF:Q8_0", taken)
+
+	// Assert
+	if got != "qwen3-8b-2" {
+		t.Errorf("starterModelName() = %q, want %q", got, "qwen3-8b-2")
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/gguf"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pathutil"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type PresetLintCmd struct {
+	Identifiers []string `arg:"" optional:"" help:"Presets to check (p:name, f:path/to/preset.yaml or f:path/to/dir); defaults to every preset in ~/.alpaca/presets" predictor:"edit-identifier"`
+	Offline     bool     `help:"Do not check HuggingFace for models that are not downloaded"`
+	Strict      bool     `help:"Also fail when there are warnings"`
+	LlamaServer string   `help:"llama-server binary whose options are checked (default: the preset's, then config, then PATH)" placeholder:"PATH"`
+}
+
+func (c *PresetLintCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return err
+	}
+
+	targets, err := lintTargets(paths.Presets, c.Identifiers)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		ui.PrintInfo("No presets to check.")
+		return nil
+	}
+
+	ctx := context.Background()
+	mgr := model.NewManager(paths.Models)
+	schemas := llama.NewSchemaCache(paths.Cache)
+	l := &presetLinter{
+		llamaServer:   c.LlamaServer,
+		defaultServer: "llama-server",
+		validateFile:  gguf.Validate,
+		serverOptions: func(command string) (llama.OptionSchema, error) {
+			return schemas.Load(ctx, command)
+		},
+		modelExists: func(id *identifier.Identifier) (bool, error) {
+			if id.IsRepoFile() {
+				return mgr.LoraExists(ctx, id.Repo, id.Quant)
+			}
+			return mgr.Exists(ctx, id.Repo, id.Quant)
+		},
+	}
+	if cfg.LlamaServerPath != "" {
+		if l.defaultServer, err = pathutil.ResolveCommand(cfg.LlamaServerPath, paths.Home); err != nil {
+			return fmt.Errorf("resolve llama-server-path: %w", err)
+		}
+	}
+	if c.LlamaServer != "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+		if l.llamaServer, err = pathutil.ResolveCommand(c.LlamaServer, cwd); err != nil {
+			return err
+		}
+	}
+	if !c.Offline {
+		puller, err := newPuller(paths, paths.Models, "")
+		if err != nil {
+			return err
+		}
+		l.remoteCheck = func(id *identifier.Identifier) error {
+			if id.IsRepoFile() {
+				_, err := puller.GetLoraInfo(ctx, id.Repo, id.Quant)
+				return err
+			}
+			_, err := puller.GetFileInfo(ctx, id.Repo, id.Quant)
+			return err
+		}
+	}
+
+	var withErrors, withWarnings int
+	for _, t := range targets {
+		label, findings := l.lintFile(t)
+		errs, warns := countFindings(findings)
+		switch {
+		case errs > 0:
+			withErrors++
+			ui.PrintError(label)
+		case warns > 0:
+			withWarnings++
+			ui.PrintWarning(label)
+		default:
+			ui.PrintSuccess(label)
+		}
+		for _, f := range findings {
+			fmt.Fprintf(ui.Output, "    %s: %s\n", f.severity(), f.msg)
+		}
+	}
+
+	ui.PrintInfo(fmt.Sprintf("%d preset(s) checked: %d with errors, %d with warnings", len(targets), withErrors, withWarnings))
+	if withErrors > 0 || (c.Strict && withWarnings > 0) {
+		return &ExitError{Code: exitError, Kind: ExitKindError}
+	}
+	return nil
+}
+
+// lintTarget is a preset file to check.
+type lintTarget struct {
+	path  string
+	label string // shown when the file cannot be parsed
+	named bool   // in the presets directory; shown as p:<name> once parsed
+}
+
+// lintTargets resolves lint arguments to preset files. Without arguments,
+// every preset in presetsDir is checked.
+func lintTargets(presetsDir string, args []string) ([]lintTarget, error) {
+	if len(args) == 0 {
+		return presetFilesIn(presetsDir, true)
+	}
+
+	var targets []lintTarget
+	for _, arg := range args {
+		id, err := identifier.Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid identifier: %w", err)
+		}
+		switch id.Type {
+		case identifier.TypePresetName:
+			path, err := preset.NewLoader(presetsDir).FindPath(id.PresetName)
+			if err != nil {
+				return nil, mapPresetError(err, id.PresetName)
+			}
+			targets = append(targets, lintTarget{path: path, label: arg, named: true})
+		case identifier.TypePresetFilePath, identifier.TypeModelFilePath:
+			path, err := pathutil.ResolvePath(id.FilePath, "")
+			if err != nil {
+				return nil, err
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if info.IsDir() {
+				files, err := presetFilesIn(path, false)
+				if err != nil {
+					return nil, err
+				}
+				targets = append(targets, files...)
+				continue
+			}
+			if id.Type == identifier.TypeModelFilePath {
+				return nil, fmt.Errorf("expected a preset (p:name, f:path/to/preset.yaml or f:path/to/dir), got %q", arg)
+			}
+			targets = append(targets, lintTarget{path: path, label: arg})
+		default:
+			return nil, fmt.Errorf("expected a preset (p:name, f:path/to/preset.yaml or f:path/to/dir), got %q", arg)
+		}
+	}
+	return targets, nil
+}
+
+// presetFilesIn returns the .yaml files in dir, sorted by name.
+func presetFilesIn(dir string, named bool) ([]lintTarget, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) && named {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read presets: %w", err)
+	}
+	var targets []lintTarget
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		targets = append(targets, lintTarget{path: path, label: "f:" + path, named: named})
+	}
+	return targets, nil
+}
+
+// lintFinding is a problem found in a preset.
+type lintFinding struct {
+	warning bool
+	msg     string
+}
+
+func (f lintFinding) severity() string {
+	if f.warning {
+		return "warning"
+	}
+	return "error"
+}
+
+func countFindings(findings []lintFinding) (errs, warns int) {
+	for _, f := range findings {
+		if f.warning {
+			warns++
+		} else {
+			errs++
+		}
+	}
+	return errs, warns
+}
+
+// presetLinter checks presets beyond what loading them validates.
+type presetLinter struct {
+	llamaServer   string // --llama-server; overrides preset and config
+	defaultServer string // config llama-server-path, or llama-server from PATH
+
+	validateFile  func(path string) error
+	serverOptions func(command string) (llama.OptionSchema, error)
+	modelExists   func(id *identifier.Identifier) (bool, error)
+	remoteCheck   func(id *identifier.Identifier) error // nil when offline
+}
+
+// lintFile loads and checks the preset at t and returns its display label
+// and the problems found.
+func (l *presetLinter) lintFile(t lintTarget) (string, []lintFinding) {
+	p, err := preset.LoadFile(t.path)
+	if err != nil {
+		return t.label, []lintFinding{{msg: err.Error()}}
+	}
+	label := t.label
+	if t.named {
+		label = "p:" + p.Name
+	}
+	return label, l.lint(p)
+}
+
+// lint checks the model files, options, and port of a loaded preset.
+// Unknown options are errors; values that do not fit an option are
+// warnings, since their kinds are inferred from llama-server --help.
+func (l *presetLinter) lint(p *preset.Preset) []lintFinding {
+	var findings []lintFinding
+	add := func(warning bool, format string, args ...any) {
+		findings = append(findings, lintFinding{warning: warning, msg: fmt.Sprintf(format, args...)})
+	}
+
+	refs, files, err := presetModelRefs(p)
+	if err != nil {
+		add(false, "%v", err)
+	}
+	for _, f := range files {
+		if _, err := os.Stat(f); err != nil {
+			add(false, "model file not found: %s", f)
+		} else if err := l.validateFile(f); err != nil {
+			add(false, "%s: %v", f, err)
+		}
+	}
+	for _, id := range refs {
+		exists, err := l.modelExists(id)
+		switch {
+		case err != nil:
+			add(false, "%s: %v", id.Raw, err)
+		case exists:
+		case l.remoteCheck == nil:
+			add(true, "%s is not downloaded (not checked on HuggingFace with --offline)", id.Raw)
+		default:
+			if err := l.remoteCheck(id); err != nil {
+				add(false, "%s cannot be pulled: %v", id.Raw, err)
+			}
+		}
+	}
+
+	command := l.llamaServer
+	if command == "" {
+		command = p.LlamaServerPath
+	}
+	if command == "" {
+		command = l.defaultServer
+	}
+	if schema, err := l.serverOptions(command); err != nil {
+		add(true, "options not checked: %v", err)
+	} else {
+		for _, err := range p.CheckOptions(schema.Check) {
+			add(!errors.Is(err, llama.ErrUnknownOption), "%v", err)
+		}
+	}
+
+	switch port := p.GetPort(); {
+	case port < 1 || port > 65535:
+		add(false, "port %d is out of range (1-65535)", port)
+	case port < 1024:
+		add(true, "port %d is privileged and may need root", port)
+	}
+	return findings
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// newTestLinter returns a linter that knows ctx-size, threads and
+// flash-attn, finds h:org/have-GGUF:Q4_K_M locally and
+// h:org/remote-GGUF:Q4_K_M remotely.
+func newTestLinter() *presetLinter {
+	return &presetLinter{
+		defaultServer: "llama-server",
+		validateFile:  func(string) error { return nil },
+		serverOptions: func(string) (llama.OptionSchema, error) {
+			return llama.OptionSchema{
+				"ctx-size":   {Kind: llama.OptionNumber},
+				"threads":    {Kind: llama.OptionNumber},
+				"flash-attn": {Kind: llama.OptionEnum, Values: []string{"on", "off", "auto"}},
+			}, nil
+		},
+		modelExists: func(id *identifier.Identifier) (bool, error) {
+			return id.Repo == "org/have-GGUF", nil
+		},
+		remoteCheck: func(id *identifier.Identifier) error {
+			if id.Repo == "org/remote-GGUF" {
+				return nil
+			}
+			return errors.New("repository not found")
+		},
+	}
+}
+
+func TestPresetLinter_Lint(t *testing.T) {
+	modelFile := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(modelFile, []byte("GGUF"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		preset  *preset.Preset
+		offline bool
+		want    []string // "error: ..." or "warning: ..." substrings, in order
+	}{
+		{
+			name:   "clean",
+			preset: &preset.Preset{Name: "ok", Model: "h:org/have-GGUF:Q4_K_M", Options: preset.Options{"ctx-size": "4096"}},
+		},
+		{
+			name:   "pullable model",
+			preset: &preset.Preset{Name: "ok", Model: "h:org/remote-GGUF:Q4_K_M"},
+		},
+		{
+			name:   "missing file and unpullable model",
+			preset: &preset.Preset{Name: "bad", Model: "f:/nonexistent/model.gguf", DraftModel: "h:org/gone-GGUF:Q8_0"},
+			want:   []string{"error: model file not found: /nonexistent/model.gguf", "error: h:org/gone-GGUF:Q8_0 cannot be pulled: repository not found"},
+		},
+		{
+			name:    "offline leaves remote models unchecked",
+			preset:  &preset.Preset{Name: "off", Model: "h:org/remote-GGUF:Q4_K_M"},
+			offline: true,
+			want:    []string{"warning: h:org/remote-GGUF:Q4_K_M is not downloaded"},
+		},
+		{
+			name:   "unknown options",
+			preset: &preset.Preset{Name: "opts", Model: "f:" + modelFile, Options: preset.Options{"ctx_size": "1", "threads": "8"}},
+			want:   []string{"error: unknown llama-server option 'ctx_size' (did you mean 'ctx-size'?)"},
+		},
+		{
+			name:   "malformed option values",
+			preset: &preset.Preset{Name: "opts", Model: "f:" + modelFile, Options: preset.Options{"ctx-size": "8k", "flash-attn": "yes"}},
+			want: []string{
+				"warning: option 'ctx-size' expects a number, got '8k'",
+				"warning: option 'flash-attn' expects one of on, off, auto, got 'yes'",
+			},
+		},
+		{
+			name: "router entry options",
+			preset: &preset.Preset{Name: "router", Mode: "router", Models: []preset.ModelEntry{
+				{Name: "a", Model: "f:" + modelFile, Options: preset.Options{"load-on-startup": "true", "tempurature": "0.2"}},
+			}},
+			want: []string{"error: model 'a': unknown llama-server option 'tempurature'"},
+		},
+		{
+			name:   "privileged port",
+			preset: &preset.Preset{Name: "port", Model: "f:" + modelFile, Port: 80},
+			want:   []string{"warning: port 80 is privileged"},
+		},
+		{
+			name:   "port out of range",
+			preset: &preset.Preset{Name: "port", Model: "f:" + modelFile, Port: 70000},
+			want:   []string{"error: port 70000 is out of range"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			l := newTestLinter()
+			if tt.offline {
+				l.remoteCheck = nil
+			}
+
+			// Act
+			findings := l.lint(tt.preset)
+
+			// Assert
+			var got []string
+			for _, f := range findings {
+				got = append(got, f.severity()+": "+f.msg)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("findings = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("finding[%d] = %q, want containing %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPresetLinter_OptionsUncheckedWithoutLlamaServer(t *testing.T) {
+	// Arrange
+	l := newTestLinter()
+	l.serverOptions = func(command string) (llama.OptionSchema, error) {
+		return nil, errors.New("run " + command + " --help: executable file not found")
+	}
+	p := &preset.Preset{Name: "x", Model: "h:org/have-GGUF:Q4_K_M", LlamaServerPath: "/opt/llama/bin/llama-server"}
+
+	// Act
+	findings := l.lint(p)
+
+	// Assert
+	if len(findings) != 1 || !findings[0].warning || !strings.Contains(findings[0].msg, "/opt/llama/bin/llama-server") {
+		t.Errorf("findings = %+v, want one warning naming the preset's llama-server", findings)
+	}
+}
+
+func TestLintTargets(t *testing.T) {
+	// Arrange
+	presetsDir := t.TempDir()
+	for name, content := range map[string]string{
+		"b.yaml":     "name: beta\nmodel: f:/m.gguf\n",
+		"a.yaml":     "name: alpha\nmodel: f:/m.gguf\n",
+		"notes.txt":  "not a preset",
+		"broken.yml": "ignored",
+	} {
+		if err := os.WriteFile(filepath.Join(presetsDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantPaths []string
+		wantErr   string
+	}{
+		{"all presets", nil, []string{"a.yaml", "b.yaml"}, ""},
+		{"by name", []string{"p:beta"}, []string{"b.yaml"}, ""},
+		{"directory", []string{"f:" + presetsDir}, []string{"a.yaml", "b.yaml"}, ""},
+		{"file", []string{"f:" + filepath.Join(presetsDir, "a.yaml")}, []string{"a.yaml"}, ""},
+		{"unknown name", []string{"p:gamma"}, nil, "Preset 'gamma' not found"},
+		{"model identifier", []string{"h:org/repo:Q4_K_M"}, nil, "expected a preset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			targets, err := lintTargets(presetsDir, tt.args)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("lintTargets() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lintTargets() error = %v", err)
+			}
+			var got []string
+			for _, target := range targets {
+				got = append(got, filepath.Base(target.path))
+			}
+			if !slices.Equal(got, tt.wantPaths) {
+				t.Errorf("targets = %v, want %v", got, tt.wantPaths)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func rawIDs(ids []*identifier.Identifier) []string {
+	var out []string
+	for _, id := range ids {
+		out = append(out, id.Raw)
+	}
+	return out
+}
+
+func TestPresetModelRefs(t *testing.T) {
+	tests := []struct {
+		name      string
+		preset    *preset.Preset
+		wantRefs  []string
+		wantFiles []string
+	}{
+		{
+			name: "single with draft, mmproj, and lora",
+			preset: &preset.Preset{
+				Name:       "coder",
+				Model:      "h:org/model-GGUF:Q4_K_M",
+				DraftModel: "h:org/draft-GGUF:Q8_0",
+				Mmproj:     "f:/models/mmproj.gguf",
+				Lora:       []string{"h:org/lora:adapter.gguf"},
+			},
+			wantRefs:  []string{"h:org/model-GGUF:Q4_K_M", "h:org/draft-GGUF:Q8_0", "h:org/lora:adapter.gguf"},
+			wantFiles: []string{"/models/mmproj.gguf"},
+		},
+		{
+			name: "router deduplicates shared references",
+			preset: &preset.Preset{
+				Name: "workspace",
+				Mode: "router",
+				Models: []preset.ModelEntry{
+					{Name: "big", Model: "h:org/big-GGUF:Q4_K_M", DraftModel: "h:org/small-GGUF:Q8_0"},
+					{Name: "small", Model: "h:org/small-GGUF:Q8_0", Mmproj: "none"},
+					{Name: "local", Model: "f:/models/local.gguf"},
+				},
+			},
+			wantRefs:  []string{"h:org/big-GGUF:Q4_K_M", "h:org/small-GGUF:Q8_0"},
+			wantFiles: []string{"/models/local.gguf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			refs, files, err := presetModelRefs(tt.preset)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("presetModelRefs() error = %v", err)
+			}
+			if got := rawIDs(refs); !slices.Equal(got, tt.wantRefs) {
+				t.Errorf("refs = %v, want %v", got, tt.wantRefs)
+			}
+			if !slices.Equal(files, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", files, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestMissingRefs(t *testing.T) {
+	// Arrange
+	modelsDir := t.TempDir()
+	ctx := context.Background()
+	meta := metadata.NewManager(modelsDir)
+	meta.Add(metadata.ModelEntry{Repo: "org/have", Quant: "Q4_K_M", Filename: "have.gguf"})
+	meta.AddLora(metadata.LoraEntry{Repo: "org/lora", File: "have.gguf", Filename: "org_lora_have.gguf"})
+	if err := meta.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	refs, err := parsePullSpecs([]string{"org/have:Q4_K_M", "org/want:Q4_K_M", "org/lora:have.gguf", "org/lora:want.gguf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	missing, err := missingRefs(ctx, modelsDir, refs)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("missingRefs() error = %v", err)
+	}
+	want := []string{"h:org/want:Q4_K_M", "h:org/lora:want.gguf"}
+	if got := rawIDs(missing); !slices.Equal(got, want) {
+		t.Errorf("missing = %v, want %v", got, want)
+	}
+}
+
+func TestLoadPresetForCommand_RejectsModels(t *testing.T) {
+	// Act
+	_, err := loadPresetForCommand(t.TempDir(), "h:org/repo:Q4_K_M")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "expected a preset") {
+		t.Fatalf("expected preset error, got %v", err)
+	}
+}
+
+func TestPresetRenameCmd_RejectsModels(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	cmd := &PresetRenameCmd{Identifier: "h:org/repo:Q4_K_M", NewName: "coder"}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "only presets can be renamed") {
+		t.Fatalf("expected preset error, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type PsCmd struct{}
+
+func (c *PsCmd) Run() error {
+	cl, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cl.Ps(context.Background())
+	if err != nil {
+		return clientError(err)
+	}
+	if resp.Status == "error" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	var data protocol.PsData
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
+	ui.PrintProcessList(processInfos(time.Now(), data.Processes))
+	return nil
+}
+
+// processInfos converts llama-server processes for display, with uptimes
+// as of now.
+func processInfos(now time.Time, procs []protocol.LlamaProcessData) []ui.LlamaProcessInfo {
+	var infos []ui.LlamaProcessInfo
+	for _, p := range procs {
+		info := ui.LlamaProcessInfo{
+			PID:    p.PID,
+			Preset: p.Preset,
+			State:  p.State,
+			Port:   p.Port,
+			Model:  p.Model,
+		}
+		if p.Mode == "router" {
+			info.Model = "router"
+		}
+		if !p.StartedAt.IsZero() {
+			info.Uptime = now.Sub(p.StartedAt).Truncate(time.Second).String()
+		}
+		if p.RSS != nil {
+			info.RSS = formatSize(*p.RSS)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
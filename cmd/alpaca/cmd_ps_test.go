@@ -0,0 +1,32 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+func TestProcessInfos(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 16, 10, 2, 3, 500, time.UTC)
+	rss := int64(4 << 30)
+	procs := []protocol.LlamaProcessData{
+		{PID: 4242, Preset: "qwen3", State: "running", StartedAt: now.Add(-62 * time.Minute), RSS: &rss, Port: 8080, Model: "/models/qwen3.gguf"},
+		{PID: 4343, Preset: "multi", State: "loading", Port: 8081, Mode: "router"},
+	}
+
+	// Act
+	got := processInfos(now, procs)
+
+	// Assert
+	want := []ui.LlamaProcessInfo{
+		{PID: 4242, Preset: "qwen3", State: "running", Uptime: "1h2m0s", RSS: "4.0 GB", Port: 8080, Model: "/models/qwen3.gguf"},
+		{PID: 4343, Preset: "multi", State: "loading", Port: 8081, Model: "router"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("processInfos() = %+v, want %+v", got, want)
+	}
+}
@@ -1,13 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
 )
 
 type PullCmd struct {
-	Identifier string `arg:"" help:"Model to download (format: h:org/repo:quant)"`
+	Identifier string   `arg:"" help:"Model to download (format: h:org/repo:quant, h:org/repo:adapter.gguf, ms:org/repo:quant from ModelScope, or oci://registry/repository:tag)"`
+	Endpoint   string   `help:"HuggingFace endpoint or mirror to download from (overrides HF_ENDPOINT and config; h: models only)" placeholder:"URL"`
+	WithFiles  []string `help:"Also download repository files matching these patterns next to the model, e.g. \"*.json\"" placeholder:"PATTERN"`
+	Verify     bool     `help:"Hash files already downloaded in full instead of trusting their recorded checksums"`
 }
 
 func (c *PullCmd) Run() error {
@@ -17,7 +23,11 @@ func (c *PullCmd) Run() error {
 	}
 
 	if id.Type != identifier.TypeHuggingFace {
-		return fmt.Errorf("pull only supports HuggingFace models\nFormat: alpaca pull h:org/repo:quant\nExample: alpaca pull h:TheBloke/CodeLlama-7B-GGUF:Q4_K_M")
+		return fmt.Errorf("pull only supports HuggingFace models (h:), ModelScope models (ms:) and OCI artifacts (oci://)\nFormat: alpaca pull h:org/repo:quant, ms:org/repo:quant or oci://registry/repository:tag\nExample: alpaca pull h:TheBloke/CodeLlama-7B-GGUF:Q4_K_M")
+	}
+	registry, err := pull.RegistryFor(id.Registry)
+	if err != nil {
+		return err
 	}
 
 	if id.Quant == "" {
@@ -29,8 +39,36 @@ func (c *PullCmd) Run() error {
 		return err
 	}
 
-	if err := pullModel(id.Repo, id.Quant, paths.Models); err != nil {
+	if id.IsRepoFile() {
+		if len(c.WithFiles) > 0 {
+			return fmt.Errorf("--with-files only applies to models, not LoRA adapters")
+		}
+		if err := pullLora(registry, id.Repo, id.Quant, paths.Models, c.Endpoint, c.Verify); err != nil {
+			printDownloadError("", err)
+			return errDownloadFailed()
+		}
+		return nil
+	}
+
+	if err := pullModel(registry, id.Repo, id.Quant, paths.Models, c.Endpoint, c.Verify); err != nil {
+		if errors.Is(err, errDownloadDeclined) {
+			ui.PrintInfo("Cancelled")
+			return nil
+		}
+		// The model itself was saved; only vision is missing
+		var mmprojErr *mmprojFailedError
+		if errors.As(err, &mmprojErr) {
+			ui.PrintWarning(err.Error())
+			return errDownloadFailed()
+		}
+		printDownloadError("", err)
 		return errDownloadFailed()
 	}
+	if len(c.WithFiles) > 0 {
+		if err := pullFiles(paths, registry, id.Repo, id.Quant, c.Endpoint, c.WithFiles); err != nil {
+			printDownloadError("", err)
+			return errDownloadFailed()
+		}
+	}
 	return nil
 }
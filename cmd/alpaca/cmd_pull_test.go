@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/d2verb/alpaca/internal/ui"
 )
 
 func TestPullCmd_InvalidIdentifierType(t *testing.T) {
@@ -73,3 +83,44 @@ func TestPullCmd_InvalidIdentifierFormat(t *testing.T) {
 		t.Errorf("expected invalid identifier error, got: %v", err)
 	}
 }
+
+func TestPullCmd_MmprojFailureReportedOnce(t *testing.T) {
+	// Arrange
+	content := []byte("model-content")
+	sum := sha256.Sum256(content)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			fmt.Fprintf(w, `{"ggufFile":{"rfilename":"model-Q4_K_M.gguf","size":%d,"lfs":{"sha256":"%s"}},"mmprojFile":{"rfilename":"mmproj-f16.gguf","size":10}}`,
+				len(content), hex.EncodeToString(sum[:]))
+		case strings.HasSuffix(r.URL.Path, "/model-Q4_K_M.gguf"):
+			w.Write(content)
+		case strings.HasPrefix(r.URL.Path, "/api/models/"):
+			fmt.Fprint(w, `{"cardData":{"license":"mit"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("HF_ENDPOINT", srv.URL)
+	var buf bytes.Buffer
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = os.Stdout })
+	cmd := &PullCmd{Identifier: "h:org/vision:Q4_K_M"}
+
+	// Act
+	err := cmd.Run()
+
+	// Assert
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != exitDownloadFailed {
+		t.Fatalf("Run() error = %v, want the download-failed exit code", err)
+	}
+	if n := strings.Count(buf.String(), "mmproj download failed"); n != 1 {
+		t.Errorf("mmproj failure reported %d times, want once:\n%s", n, buf.String())
+	}
+	if strings.Contains(buf.String(), ui.Symbol("✗")) {
+		t.Errorf("output has an error line besides the warning:\n%s", buf.String())
+	}
+}
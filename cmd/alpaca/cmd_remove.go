@@ -3,15 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/config"
 
 	"github.com/d2verb/alpaca/internal/identifier"
 	"github.com/d2verb/alpaca/internal/model"
 	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
 type RemoveCmd struct {
 	Identifier string `arg:"" help:"Identifier to remove (p:name or h:org/repo:quant)" predictor:"rm-identifier"`
+	Force      bool   `help:"Remove a model even if the loaded preset uses it"`
 }
 
 func (c *RemoveCmd) Run() error {
@@ -27,10 +34,13 @@ func (c *RemoveCmd) Run() error {
 
 	switch id.Type {
 	case identifier.TypePresetName:
-		return c.removePreset(id.PresetName, paths.Presets)
+		return c.removePreset(id.PresetName, paths)
 
 	case identifier.TypeHuggingFace:
-		return c.removeModel(id, paths.Models)
+		if id.IsRepoFile() {
+			return c.removeLora(id, paths)
+		}
+		return c.removeModel(id, paths)
 
 	case identifier.TypeModelFilePath, identifier.TypePresetFilePath:
 		return fmt.Errorf("file paths (f:) cannot be removed\nUse: alpaca rm p:preset-name or alpaca rm h:org/repo:quant")
@@ -40,8 +50,8 @@ func (c *RemoveCmd) Run() error {
 	}
 }
 
-func (c *RemoveCmd) removePreset(name, presetsDir string) error {
-	loader := preset.NewLoader(presetsDir)
+func (c *RemoveCmd) removePreset(name string, paths *config.Paths) error {
+	loader := preset.NewLoader(paths.Presets)
 
 	// Check if preset exists
 	exists, err := loader.Exists(name)
@@ -53,7 +63,11 @@ func (c *RemoveCmd) removePreset(name, presetsDir string) error {
 	}
 
 	// Confirmation prompt
-	if !promptConfirm(fmt.Sprintf("Delete preset '%s'?", name)) {
+	ok, err := promptConfirm(fmt.Sprintf("Delete preset '%s'?", name))
+	if err != nil {
+		return err
+	}
+	if !ok {
 		ui.PrintInfo("Cancelled")
 		return nil
 	}
@@ -62,13 +76,17 @@ func (c *RemoveCmd) removePreset(name, presetsDir string) error {
 	if err := loader.Remove(name); err != nil {
 		return mapPresetError(err, name)
 	}
+	// Slots saved with slot-save-path: auto belong to the preset alone.
+	if err := os.RemoveAll(filepath.Join(paths.Cache, name)); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not remove saved slots: %v", err))
+	}
 
 	ui.PrintSuccess(fmt.Sprintf("Preset '%s' removed", name))
 	return nil
 }
 
-func (c *RemoveCmd) removeModel(id *identifier.Identifier, modelsDir string) error {
-	modelMgr := model.NewManager(modelsDir)
+func (c *RemoveCmd) removeModel(id *identifier.Identifier, paths *config.Paths) error {
+	modelMgr := model.NewManager(paths.Models)
 	ctx := context.Background()
 
 	// Check if model exists
@@ -83,10 +101,18 @@ func (c *RemoveCmd) removeModel(id *identifier.Identifier, modelsDir string) err
 	// Build confirmation message with mmproj info
 	confirmMsg := fmt.Sprintf("Delete model 'h:%s:%s'?", id.Repo, id.Quant)
 	entry, err := modelMgr.GetDetails(ctx, id.Repo, id.Quant)
-	if err == nil && entry.Mmproj != nil {
+	if err != nil {
+		return fmt.Errorf("get model details: %w", err)
+	}
+	var files []string
+	for _, f := range entry.Files() {
+		files = append(files, filepath.Join(paths.Models, f))
+	}
+	if entry.Mmproj != nil {
 		refCount, refErr := modelMgr.MmprojReferenceCount(ctx, entry.Mmproj.Filename)
 		if refErr == nil {
 			if refCount <= 1 {
+				files = append(files, filepath.Join(paths.Models, entry.Mmproj.Filename))
 				confirmMsg = fmt.Sprintf("Delete model 'h:%s:%s' (and mmproj, %s)?", id.Repo, id.Quant, formatSize(entry.Mmproj.Size))
 			} else {
 				confirmMsg = fmt.Sprintf("Delete model 'h:%s:%s' (mmproj retained by other quants)?", id.Repo, id.Quant)
@@ -94,8 +120,16 @@ func (c *RemoveCmd) removeModel(id *identifier.Identifier, modelsDir string) err
 		}
 	}
 
+	if err := c.checkInUse(fmt.Sprintf("h:%s:%s", id.Repo, id.Quant), files, paths.Socket); err != nil {
+		return err
+	}
+
 	// Confirmation prompt
-	if !promptConfirm(confirmMsg) {
+	ok, err := promptConfirm(confirmMsg)
+	if err != nil {
+		return err
+	}
+	if !ok {
 		ui.PrintInfo("Cancelled")
 		return nil
 	}
@@ -108,3 +142,94 @@ func (c *RemoveCmd) removeModel(id *identifier.Identifier, modelsDir string) err
 	ui.PrintSuccess(fmt.Sprintf("Model 'h:%s:%s' removed", id.Repo, id.Quant))
 	return nil
 }
+
+func (c *RemoveCmd) removeLora(id *identifier.Identifier, paths *config.Paths) error {
+	modelMgr := model.NewManager(paths.Models)
+	ctx := context.Background()
+
+	exists, err := modelMgr.LoraExists(ctx, id.Repo, id.Quant)
+	if err != nil {
+		return fmt.Errorf("check lora adapter: %w", err)
+	}
+	if !exists {
+		return errModelNotFound(fmt.Sprintf("h:%s:%s", id.Repo, id.Quant))
+	}
+
+	path, err := modelMgr.GetLoraFilePath(ctx, id.Repo, id.Quant)
+	if err != nil {
+		return fmt.Errorf("get lora adapter path: %w", err)
+	}
+	if err := c.checkInUse(fmt.Sprintf("h:%s:%s", id.Repo, id.Quant), []string{path}, paths.Socket); err != nil {
+		return err
+	}
+
+	ok, err := promptConfirm(fmt.Sprintf("Delete LoRA adapter 'h:%s:%s'?", id.Repo, id.Quant))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ui.PrintInfo("Cancelled")
+		return nil
+	}
+
+	if err := modelMgr.RemoveLora(ctx, id.Repo, id.Quant); err != nil {
+		return fmt.Errorf("remove lora adapter: %w", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("LoRA adapter 'h:%s:%s' removed", id.Repo, id.Quant))
+	return nil
+}
+
+// checkInUse refuses to remove the files of ref while the preset loaded by
+// the local daemon uses one of them, unless --force is given. Deleting a
+// model under a running llama-server breaks its next load or mmap read.
+func (c *RemoveCmd) checkInUse(ref string, files []string, socket string) error {
+	presetName, inUse := loadedModelFiles(socket)
+	if fileInUse(files, inUse) == "" {
+		return nil
+	}
+	if c.Force {
+		ui.PrintWarning(fmt.Sprintf("'%s' is in use by the loaded preset '%s'", ref, presetName))
+		return nil
+	}
+	return fmt.Errorf("'%s' is in use by the loaded preset '%s'\nRun: alpaca unload, or use --force to remove it anyway", ref, presetName)
+}
+
+// loadedModelFiles returns the name and model files of the preset loaded by
+// the local daemon. It returns nothing when the daemon is not running or
+// nothing is loaded. A remote daemon (--host) is not asked: its files are
+// not in this models directory.
+func loadedModelFiles(socket string) (string, []string) {
+	if daemonHost != "" {
+		return "", nil
+	}
+	cl := client.New(socket)
+	cl.SetTimeouts(daemonTimeouts)
+	resp, err := cl.Status(context.Background())
+	if err != nil || resp.Status != protocol.StatusOK {
+		return "", nil
+	}
+	var data protocol.StatusData
+	if err := resp.Decode(&data); err != nil {
+		return "", nil
+	}
+	return data.Preset, data.Files
+}
+
+// fileInUse returns the first of files that is one of inUse, or "". Files
+// are compared without following links, so removing one name for a blob
+// shared with the loaded model is allowed: the blob itself is kept.
+func fileInUse(files, inUse []string) string {
+	for _, u := range inUse {
+		used, err := os.Lstat(u)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			if info, err := os.Lstat(f); err == nil && os.SameFile(info, used) {
+				return f
+			}
+		}
+	}
+	return ""
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/ui"
 )
 
 func TestRemoveCmd_FilePathIdentifierError(t *testing.T) {
@@ -36,3 +42,84 @@ func TestRemoveCmd_InvalidIdentifier(t *testing.T) {
 		t.Errorf("expected invalid identifier error, got: %v", err)
 	}
 }
+
+func TestFileInUse(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	model := filepath.Join(dir, "model.gguf")
+	other := filepath.Join(dir, "other.gguf")
+	blob := filepath.Join(dir, "blob")
+	for _, f := range []string{model, blob} {
+		if err := os.WriteFile(f, []byte("gguf"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("blob", other); err != nil {
+		t.Fatal(err)
+	}
+	shared := filepath.Join(dir, "shared.gguf")
+	if err := os.Symlink("blob", shared); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		inUse []string
+		want  string
+	}{
+		{"same path", []string{model}, []string{model}, model},
+		{"unclean path", []string{model}, []string{dir + "/./model.gguf"}, model},
+		{"link sharing a blob", []string{other}, []string{shared}, ""},
+		{"not loaded", []string{model}, nil, ""},
+		{"missing in-use file", []string{model}, []string{filepath.Join(dir, "gone.gguf")}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := fileInUse(tt.files, tt.inUse)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("fileInUse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveCmd_PresetRemovesSavedSlots(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ALPACA_PROFILE", "")
+	assumeYes = true
+	defer func() { assumeYes = false }()
+	ui.Output = io.Discard
+	defer func() { ui.Output = os.Stdout }()
+
+	paths, err := getPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := preset.NewLoader(paths.Presets).Create(&preset.Preset{Name: "coder", Model: "f:/m.gguf"}); err != nil {
+		t.Fatal(err)
+	}
+	slotDir := filepath.Join(paths.Cache, "coder")
+	if err := os.MkdirAll(slotDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(slotDir, "session.bin"), []byte("kv"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	err = (&RemoveCmd{Identifier: "p:coder"}).Run()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := os.Stat(slotDir); !os.IsNotExist(err) {
+		t.Errorf("slot directory should be removed, stat error = %v", err)
+	}
+}
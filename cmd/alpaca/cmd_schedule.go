@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/schedule"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type ScheduleCmd struct {
+	List ScheduleListCmd `cmd:"" name:"ls" help:"List scheduled loads and unloads"`
+	Add  ScheduleAddCmd  `cmd:"" help:"Load or unload a model at the times a cron expression selects"`
+	Rm   ScheduleRmCmd   `cmd:"" name:"rm" help:"Remove a schedule entry"`
+}
+
+// nextRunFormat shows the day of the week, since schedules are often
+// weekday-based.
+const nextRunFormat = "Mon 2006-01-02 15:04"
+
+type ScheduleListCmd struct{}
+
+func (c *ScheduleListCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return err
+	}
+	ui.PrintScheduleList(scheduleInfos(cfg.Schedule, time.Now()))
+	return nil
+}
+
+// scheduleInfos describes entries for display, with their next run after now.
+func scheduleInfos(entries []schedule.Entry, now time.Time) []ui.ScheduleInfo {
+	infos := make([]ui.ScheduleInfo, len(entries))
+	for i, e := range entries {
+		info := ui.ScheduleInfo{Cron: e.Cron, Action: e.Action()}
+		if err := e.Validate(); err != nil {
+			info.Problem = err.Error()
+		} else if expr, _ := schedule.Parse(e.Cron); !expr.Next(now).IsZero() {
+			info.Next = expr.Next(now).Format(nextRunFormat)
+		}
+		infos[i] = info
+	}
+	return infos
+}
+
+type ScheduleAddCmd struct {
+	Cron   string `arg:"" help:"When to run: minute hour day-of-month month day-of-week (e.g. '0 9 * * mon-fri')"`
+	Load   string `help:"Preset or model to load (p:name, h:org/repo:quant, f:path)" placeholder:"IDENTIFIER" predictor:"load-identifier"`
+	Unload bool   `help:"Unload the running model"`
+}
+
+func (c *ScheduleAddCmd) Run() error {
+	if (c.Load != "") == c.Unload {
+		return fmt.Errorf("specify either --load or --unload")
+	}
+	entry := schedule.Entry{Cron: c.Cron, Load: c.Load, Unload: c.Unload}
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	if id, _ := identifier.Parse(c.Load); id != nil && id.Type == identifier.TypePresetName {
+		if _, err := preset.NewLoader(paths.Presets).Load(id.PresetName); err != nil {
+			return mapPresetError(err, id.PresetName)
+		}
+	}
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return err
+	}
+	if err := config.SaveSchedule(paths.Config, append(cfg.Schedule, entry)); err != nil {
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Scheduled %s at '%s'", entry.Action(), entry.Cron))
+	if expr, _ := schedule.Parse(entry.Cron); !expr.Next(time.Now()).IsZero() {
+		ui.PrintInfo(fmt.Sprintf("Next run: %s", expr.Next(time.Now()).Format(nextRunFormat)))
+	} else {
+		ui.PrintWarning("The expression never matches a date; the entry will not run")
+	}
+	return nil
+}
+
+type ScheduleRmCmd struct {
+	Index int `arg:"" help:"Number of the entry, as shown by alpaca schedule ls"`
+}
+
+func (c *ScheduleRmCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return err
+	}
+	if c.Index < 1 || c.Index > len(cfg.Schedule) {
+		return fmt.Errorf("no schedule entry %d\nRun: alpaca schedule ls", c.Index)
+	}
+
+	removed := cfg.Schedule[c.Index-1]
+	entries := append(cfg.Schedule[:c.Index-1:c.Index-1], cfg.Schedule[c.Index:]...)
+	if err := config.SaveSchedule(paths.Config, entries); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Removed schedule entry %d (%s at '%s')", c.Index, removed.Action(), removed.Cron))
+	return nil
+}
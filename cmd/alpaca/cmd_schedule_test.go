@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/schedule"
+	"github.com/d2verb/alpaca/internal/ui"
+	"github.com/fatih/color"
+)
+
+func TestScheduleInfos(t *testing.T) {
+	// Arrange
+	now := time.Date(2026, 10, 16, 18, 30, 0, 0, time.Local) // Friday
+	entries := []schedule.Entry{
+		{Cron: "0 9 * * mon-fri", Load: "p:coder"},
+		{Cron: "0 0 30 2 *", Unload: true},
+		{Cron: "0 9 * *", Load: "p:coder"},
+	}
+
+	// Act
+	infos := scheduleInfos(entries, now)
+
+	// Assert
+	if infos[0].Action != "load p:coder" || infos[0].Next != "Mon 2026-10-19 09:00" || infos[0].Problem != "" {
+		t.Errorf("infos[0] = %+v", infos[0])
+	}
+	if infos[1].Action != "unload" || infos[1].Next != "" || infos[1].Problem != "" {
+		t.Errorf("infos[1] = %+v, want no next run", infos[1])
+	}
+	if !strings.Contains(infos[2].Problem, "must have 5 fields") {
+		t.Errorf("infos[2] = %+v, want a problem", infos[2])
+	}
+}
+
+func TestScheduleAddRm(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ALPACA_PROFILE", "")
+	presets := filepath.Join(home, ".alpaca", "presets")
+	if err := os.MkdirAll(presets, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(presets, "coder.yaml"), []byte("name: coder\nmodel: f:/models/coder.gguf\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+	var buf bytes.Buffer
+	ui.Output = &buf
+	defer func() { ui.Output = os.Stdout }()
+
+	// Act
+	errLoad := (&ScheduleAddCmd{Cron: "0 9 * * mon-fri", Load: "p:coder"}).Run()
+	errUnload := (&ScheduleAddCmd{Cron: "0 18 * * mon-fri", Unload: true}).Run()
+	errRm := (&ScheduleRmCmd{Index: 1}).Run()
+
+	// Assert
+	for _, err := range []error{errLoad, errUnload, errRm} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	cfg, err := config.Load(filepath.Join(home, ".alpaca", "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []schedule.Entry{{Cron: "0 18 * * mon-fri", Unload: true}}
+	if len(cfg.Schedule) != 1 || cfg.Schedule[0] != want[0] {
+		t.Errorf("Schedule = %+v, want %+v", cfg.Schedule, want)
+	}
+	if !strings.Contains(buf.String(), "Removed schedule entry 1 (load p:coder at '0 9 * * mon-fri')") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestScheduleAdd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     ScheduleAddCmd
+		wantErr string
+	}{
+		{"no action", ScheduleAddCmd{Cron: "0 9 * * *"}, "either --load or --unload"},
+		{"both actions", ScheduleAddCmd{Cron: "0 9 * * *", Load: "p:coder", Unload: true}, "either --load or --unload"},
+		{"bad cron", ScheduleAddCmd{Cron: "0 25 * * *", Unload: true}, "invalid hour"},
+		{"missing preset", ScheduleAddCmd{Cron: "0 9 * * *", Load: "p:missing"}, "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("ALPACA_PROFILE", "")
+
+			// Act
+			err := tt.cmd.Run()
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduleRm_OutOfRange(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ALPACA_PROFILE", "")
+
+	// Act
+	err := (&ScheduleRmCmd{Index: 1}).Run()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "no schedule entry 1") {
+		t.Errorf("Run() error = %v", err)
+	}
+}
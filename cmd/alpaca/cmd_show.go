@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/d2verb/alpaca/internal/identifier"
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/model"
 	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/pull"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
@@ -32,7 +36,7 @@ func (c *ShowCmd) Run() error {
 		return c.showPreset(id.PresetName, paths.Presets)
 
 	case identifier.TypeHuggingFace:
-		return c.showModel(id, paths.Models)
+		return showModel(id, paths.Models, paths.Presets)
 
 	case identifier.TypeModelFilePath, identifier.TypePresetFilePath:
 		return fmt.Errorf("cannot show file details\nUse: alpaca show p:name or alpaca show h:org/repo:quant")
@@ -53,13 +57,19 @@ func (c *ShowCmd) showPreset(name, presetsDir string) error {
 		c.showRouterPreset(p)
 	} else {
 		ui.PrintPresetDetails(ui.PresetDetails{
-			Name:       p.Name,
-			Model:      p.Model,
-			DraftModel: p.DraftModel,
-			Mmproj:     p.Mmproj,
-			Host:       p.GetHost(),
-			Port:       p.GetPort(),
-			Options:    p.Options,
+			Name:         p.Name,
+			Model:        p.Model,
+			DraftModel:   p.DraftModel,
+			Mmproj:       p.Mmproj,
+			Lora:         p.Lora,
+			Host:         p.GetHost(),
+			Port:         p.GetPort(),
+			GPU:          p.GPU.String(),
+			SystemPrompt: p.Sampling.SystemPrompt,
+			Sampling:     p.Sampling.String(),
+			Priority:     p.Priority.String(),
+			Health:       p.Health.String(),
+			Options:      p.Options,
 		})
 	}
 
@@ -68,26 +78,39 @@ func (c *ShowCmd) showPreset(name, presetsDir string) error {
 
 func (c *ShowCmd) showRouterPreset(p *preset.Preset) {
 	details := ui.RouterPresetDetails{
-		Name:        p.Name,
-		Host:        p.GetHost(),
-		Port:        p.GetPort(),
-		MaxModels:   p.MaxModels,
-		IdleTimeout: p.IdleTimeout,
-		Options:     p.Options,
+		Name:         p.Name,
+		Host:         p.GetHost(),
+		Port:         p.GetPort(),
+		MaxModels:    p.MaxModels,
+		IdleTimeout:  p.IdleTimeout,
+		GPU:          p.GPU.String(),
+		SystemPrompt: p.Sampling.SystemPrompt,
+		Sampling:     p.Sampling.String(),
+		Priority:     p.Priority.String(),
+		Health:       p.Health.String(),
+		Options:      p.Options,
 	}
 	for _, m := range p.Models {
 		details.Models = append(details.Models, ui.RouterModelDetail{
-			Name:       m.Name,
-			Model:      m.Model,
-			DraftModel: m.DraftModel,
-			Mmproj:     m.Mmproj,
-			Options:    m.Options,
+			Name:         m.Name,
+			Model:        m.Model,
+			DraftModel:   m.DraftModel,
+			Mmproj:       m.Mmproj,
+			Lora:         m.Lora,
+			Pin:          m.Pin,
+			TTL:          m.TTL,
+			GPU:          m.GPU.String(),
+			SystemPrompt: m.Sampling.SystemPrompt,
+			Sampling:     m.Sampling.String(),
+			Options:      m.Options,
 		})
 	}
 	ui.PrintRouterPresetDetails(details)
 }
 
-func (c *ShowCmd) showModel(id *identifier.Identifier, modelsDir string) error {
+// showModel prints everything recorded about a downloaded model, with the
+// presets in presetsDir that reference it.
+func showModel(id *identifier.Identifier, modelsDir, presetsDir string) error {
 	modelMgr := model.NewManager(modelsDir)
 	ctx := context.Background()
 
@@ -111,24 +134,107 @@ func (c *ShowCmd) showModel(id *identifier.Identifier, modelsDir string) error {
 		return fmt.Errorf("get file path: %w", err)
 	}
 
-	ui.PrintModelDetails(ui.ModelDetails{
+	// Models recorded before their GGUF info was are read on the fly
+	if entry.Architecture == "" {
+		e := *entry
+		e.ReadGGUFInfo(modelsDir)
+		entry = &e
+	}
+
+	details := ui.ModelDetails{
 		Repo:         entry.Repo,
 		Quant:        entry.Quant,
 		Filename:     entry.Filename,
 		Path:         filePath,
 		Size:         formatSize(entry.Size),
 		DownloadedAt: entry.DownloadedAt.Format("2006-01-02 15:04:05"),
+		Source:       modelSource(entry),
+		SHA256:       entry.SHA256,
 		Mmproj:       formatMmprojDetail(entry.Mmproj),
-	})
+		Shards:       entry.Shards,
+		ExtraFiles:   formatExtraFiles(entry.ExtraFiles),
+		Presets:      presetsUsing(presetsDir, entry, filepath.Dir(filePath)),
+	}
+	details.Architecture = entry.Architecture
+	if entry.Parameters > 0 {
+		details.Parameters = formatParameters(entry.Parameters)
+	}
+	if entry.ContextLength > 0 {
+		details.Context = fmt.Sprintf("%s tokens (%d)", formatContextLength(entry.ContextLength), entry.ContextLength)
+	}
+	if entry.Mmproj != nil {
+		details.MmprojSHA256 = entry.Mmproj.SHA256
+	}
+	ui.PrintModelDetails(details)
 
 	return nil
 }
 
-// formatMmprojDetail formats mmproj metadata for display.
+// modelSource returns where a model came from: the URL or path it was
+// added from, or its repository page on the registry it was pulled from.
+func modelSource(e *metadata.ModelEntry) string {
+	if e.Source != "" {
+		return e.Source
+	}
+	registry, err := pull.RegistryFor(e.Registry)
+	if err != nil {
+		return e.Repo
+	}
+	return registry.RepoURL(e.Repo)
+}
+
+// presetsUsing returns the presets in presetsDir that reference the model
+// of e, as p:name: by its h: identifier, or by the f: path of one of its
+// files in dir. Presets that fail to load are left out.
+func presetsUsing(presetsDir string, e *metadata.ModelEntry, dir string) []string {
+	loader := preset.NewLoader(presetsDir)
+	names, _ := loader.List()
+
+	files := e.Files()
+	if e.Mmproj != nil {
+		files = append(files, e.Mmproj.Filename)
+	}
+	var using []string
+	for _, name := range names {
+		p, err := loader.Load(name)
+		if err != nil {
+			continue
+		}
+		refs, paths, err := presetModelRefs(p)
+		if err != nil {
+			continue
+		}
+		uses := slices.ContainsFunc(refs, func(id *identifier.Identifier) bool {
+			return strings.EqualFold(id.Repo, e.Repo) && strings.EqualFold(id.Quant, e.Quant)
+		}) || slices.ContainsFunc(paths, func(path string) bool {
+			return filepath.Dir(path) == dir && slices.Contains(files, filepath.Base(path))
+		})
+		if uses {
+			using = append(using, "p:"+name)
+		}
+	}
+	return using
+}
+
+// formatExtraFiles formats extra file metadata for display, one line each.
+func formatExtraFiles(files []metadata.ExtraFileEntry) []string {
+	var lines []string
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("%s (%s)", f.Filename, formatSize(f.Size)))
+	}
+	return lines
+}
+
+// formatMmprojDetail formats mmproj metadata for display, with its
+// filename in the repository when known.
 // Returns empty string if mmproj is nil.
 func formatMmprojDetail(mmproj *metadata.MmprojEntry) string {
 	if mmproj == nil {
 		return ""
 	}
-	return fmt.Sprintf("%s (%s)", mmproj.Filename, formatSize(mmproj.Size))
+	detail := fmt.Sprintf("%s (%s)", mmproj.Filename, formatSize(mmproj.Size))
+	if mmproj.File != "" && mmproj.File != mmproj.Filename {
+		detail += ", upstream " + mmproj.File
+	}
+	return detail
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -230,6 +231,11 @@ func TestFormatMmprojDetail(t *testing.T) {
 			mmproj: &metadata.MmprojEntry{Filename: "org_model_mmproj-f16.gguf", Size: 892403712},
 			want:   "org_model_mmproj-f16.gguf (851.1 MB)",
 		},
+		{
+			name:   "mmproj with its filename in the repository",
+			mmproj: &metadata.MmprojEntry{Filename: "org_model_mmproj-f16.gguf", Size: 892403712, File: "mmproj-f16.gguf"},
+			want:   "org_model_mmproj-f16.gguf (851.1 MB), upstream mmproj-f16.gguf",
+		},
 	}
 
 	for _, tt := range tests {
@@ -242,6 +248,39 @@ func TestFormatMmprojDetail(t *testing.T) {
 	}
 }
 
+func TestPresetsUsing(t *testing.T) {
+	// Arrange
+	presetsDir := t.TempDir()
+	modelsDir := "/home/me/.alpaca/models"
+	presets := []*preset.Preset{
+		{Name: "by-ref", Model: "h:org/model-GGUF:q4_k_m"},
+		{Name: "by-path", Model: "f:" + modelsDir + "/model-Q8_0.gguf", Mmproj: "f:" + modelsDir + "/org_model-GGUF_mmproj-f16.gguf"},
+		{Name: "as-draft", Model: "h:org/big-GGUF:Q4_K_M", DraftModel: "h:org/model-GGUF:Q4_K_M"},
+		{Name: "other-quant", Model: "h:org/model-GGUF:Q8_0"},
+		{Name: "other-dir", Model: "f:/elsewhere/model-Q4_K_M.gguf"},
+	}
+	for _, p := range presets {
+		if err := preset.WriteFile(filepath.Join(presetsDir, p.Name+".yaml"), p); err != nil {
+			t.Fatalf("write preset: %v", err)
+		}
+	}
+	entry := &metadata.ModelEntry{
+		Repo:     "org/model-GGUF",
+		Quant:    "Q4_K_M",
+		Filename: "model-Q4_K_M.gguf",
+		Mmproj:   &metadata.MmprojEntry{Filename: "org_model-GGUF_mmproj-f16.gguf"},
+	}
+
+	// Act
+	got := presetsUsing(presetsDir, entry, modelsDir)
+
+	// Assert
+	want := []string{"p:as-draft", "p:by-path", "p:by-ref"}
+	if !slices.Equal(got, want) {
+		t.Errorf("presetsUsing() = %v, want %v", got, want)
+	}
+}
+
 func TestShowCmd_SinglePresetWithMmproj(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
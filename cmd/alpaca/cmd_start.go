@@ -2,27 +2,56 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/d2verb/alpaca/internal/allowlist"
+	"github.com/d2verb/alpaca/internal/client"
 	"github.com/d2verb/alpaca/internal/config"
 	"github.com/d2verb/alpaca/internal/daemon"
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/logging"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/migrate"
 	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/pathutil"
 	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/schedule"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
 type StartCmd struct {
-	Daemon bool `name:"daemon" hidden:"" help:"Run daemon process (internal)"`
+	Daemon bool   `name:"daemon" hidden:"" help:"Run daemon process (internal)"`
+	Listen string `help:"Also accept remote clients on this TCP address (e.g. 0.0.0.0:7070); addresses other than loopback require --tls-cert" placeholder:"ADDR"`
+
+	TLSCert     string `name:"tls-cert" help:"Serve --listen over TLS with this certificate (PEM)" type:"path" placeholder:"FILE"`
+	TLSKey      string `name:"tls-key" help:"Private key of --tls-cert (PEM)" type:"path" placeholder:"FILE"`
+	TLSClientCA string `name:"tls-client-ca" help:"Also require remote clients to present a certificate signed by these CAs (PEM)" type:"path" placeholder:"FILE"`
+
+	Proxy            string        `help:"Serve an HTTP proxy to llama-server on this address that loads presets on first request (e.g. 127.0.0.1:11434)" placeholder:"ADDR"`
+	ProxyLoadTimeout time.Duration `help:"Maximum time the proxy waits for an on-demand load" default:"2m"`
+	LogRequests      bool          `help:"Record each proxied request (model, path, status, tokens, latency) in logs/requests.log"`
+	IncludeBodies    bool          `help:"Also record request and response bodies (implies --log-requests)"`
 }
 
 func (c *StartCmd) Run() error {
+	if (c.LogRequests || c.IncludeBodies) && c.Proxy == "" {
+		return fmt.Errorf("--log-requests and --include-bodies require --proxy")
+	}
+	if (c.TLSCert != "" || c.TLSKey != "" || c.TLSClientCA != "") && c.Listen == "" {
+		return fmt.Errorf("--tls-cert, --tls-key and --tls-client-ca require --listen")
+	}
+
 	paths, err := getPaths()
 	if err != nil {
 		return err
@@ -36,22 +65,15 @@ func (c *StartCmd) Run() error {
 
 	if status.Running {
 		ui.PrintInfo(fmt.Sprintf("Daemon is already running (PID: %d)", status.PID))
+		warnStaleDaemon(paths.Socket)
 		return nil
 	}
 
-	// Clean up stale files if any
-	if status.SocketExists && !status.Running {
-		ui.PrintWarning("Cleaning up stale socket...")
-		os.Remove(paths.Socket)
-	}
-	if status.PID > 0 && !status.Running {
-		daemon.RemovePIDFile(paths.PID)
-	}
-
 	// Create directories if needed
 	if err := paths.EnsureDirectories(); err != nil {
 		return fmt.Errorf("create directories: %w", err)
 	}
+	migrateFiles(paths)
 
 	// Internal daemon mode: run the actual daemon process
 	if c.Daemon {
@@ -63,8 +85,40 @@ func (c *StartCmd) Run() error {
 }
 
 func (c *StartCmd) startBackground(paths *config.Paths) error {
+	// Report config and certificate problems here rather than in the
+	// daemon log
+	if _, err := config.Load(paths.Config); err != nil {
+		return err
+	}
+
 	// Re-exec ourselves with internal daemon flag
-	cmd := exec.Command(os.Args[0], "start", "--daemon")
+	args := []string{"start", "--daemon"}
+	if c.Listen != "" {
+		tlsConfig, err := c.remoteTLS()
+		if err != nil {
+			return err
+		}
+		if err := daemon.CheckListen(c.Listen, tlsConfig); err != nil {
+			return err
+		}
+		args = append(args, "--listen", c.Listen)
+		if c.TLSCert != "" {
+			args = append(args, "--tls-cert", c.TLSCert, "--tls-key", c.TLSKey)
+		}
+		if c.TLSClientCA != "" {
+			args = append(args, "--tls-client-ca", c.TLSClientCA)
+		}
+	}
+	if c.Proxy != "" {
+		args = append(args, "--proxy", c.Proxy, "--proxy-load-timeout", c.ProxyLoadTimeout.String())
+		if c.LogRequests {
+			args = append(args, "--log-requests")
+		}
+		if c.IncludeBodies {
+			args = append(args, "--include-bodies")
+		}
+	}
+	cmd := exec.Command(os.Args[0], args...)
 	cmd.Env = os.Environ()
 
 	// Detach from controlling terminal (Unix-like systems)
@@ -78,14 +132,48 @@ func (c *StartCmd) startBackground(paths *config.Paths) error {
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("start daemon: %w", err)
 	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
 
 	// Wait for daemon to become ready (max 5 seconds)
 	for range 50 {
-		time.Sleep(100 * time.Millisecond)
+		select {
+		case <-exited:
+			// A daemon started concurrently took the lock first.
+			if status, _ := daemon.GetDaemonStatus(paths.PID, paths.Socket); status.Running {
+				ui.PrintInfo(fmt.Sprintf("Daemon is already running (PID: %d)", status.PID))
+				return nil
+			}
+			return fmt.Errorf("daemon exited during startup, check logs: %s", paths.DaemonLog)
+		case <-time.After(100 * time.Millisecond):
+		}
 		if daemon.IsSocketAvailable(paths.Socket) {
+			if status, _ := daemon.GetDaemonStatus(paths.PID, paths.Socket); status.PID != 0 && status.PID != cmd.Process.Pid {
+				ui.PrintInfo(fmt.Sprintf("Daemon is already running (PID: %d)", status.PID))
+				return nil
+			}
 			// User-facing output (not logged)
 			ui.PrintSuccess(fmt.Sprintf("Daemon started (PID: %d)", cmd.Process.Pid))
 			ui.PrintInfo(fmt.Sprintf("Logs: %s", paths.DaemonLog))
+			if paths.Profile != "" {
+				ui.PrintInfo(fmt.Sprintf("Profile: %s", paths.Profile))
+			}
+			if c.Listen != "" {
+				scheme := "plaintext"
+				if c.TLSCert != "" {
+					scheme = "TLS"
+				}
+				ui.PrintInfo(fmt.Sprintf("Remote access on %s over %s (token: %s)", c.Listen, scheme, remoteTokenSource(paths)))
+			}
+			if c.Proxy != "" {
+				ui.PrintInfo(fmt.Sprintf("Proxy: %s", ui.FormatEndpoint("http://"+c.Proxy)))
+				if c.LogRequests || c.IncludeBodies {
+					ui.PrintInfo(fmt.Sprintf("Request log: %s", paths.RequestLog))
+				}
+			}
 			return nil
 		}
 	}
@@ -101,31 +189,305 @@ func (c *StartCmd) runDaemon(paths *config.Paths) error {
 	llamaLogWriter := logging.NewRotatingWriter(logging.DefaultConfig(paths.LlamaLog))
 	defer llamaLogWriter.Close()
 
-	// Write PID file
-	if err := daemon.WritePIDFile(paths.PID); err != nil {
-		return fmt.Errorf("write PID file: %w", err)
+	// Only one daemon runs at a time; of two racing starts, one stops here.
+	lock, err := daemon.AcquireLock(paths.PID)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return err
 	}
-	defer daemon.RemovePIDFile(paths.PID)
 
 	// Start daemon
 	presetLoader := preset.NewLoader(paths.Presets)
 	modelManager := model.NewManager(paths.Models)
 	d := daemon.New(presetLoader, modelManager, paths.RouterConfig, daemonLogWriter, llamaLogWriter)
+	d.SetStatePath(paths.State)
+	d.SetServerRecordPath(paths.ServerRecord)
+	d.SetTempFilesPath(paths.TempFiles)
+	if err := applyConfig(d, cfg, paths); err != nil {
+		return err
+	}
+	d.SetOptionSchemas(llama.NewSchemaCache(paths.Cache))
+	d.SetSlotCacheDir(paths.Cache)
+
+	allowedUIDs, err := daemon.LookupUIDs(cfg.AllowUsers)
+	if err != nil {
+		return fmt.Errorf("resolve allow-users: %w", err)
+	}
 
 	server := daemon.NewServer(d, paths.Socket, daemonLogWriter)
+	server.SetAllowedUIDs(allowedUIDs)
+	server.SetVersion(version)
+	server.SetMaxRequests(cfg.MaxRequests)
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// A llama-server left behind by a crashed daemon is adopted or terminated
+	// before clients can issue loads that would collide with its port.
+	adopted := d.AdoptOrphan(ctx)
+	d.CleanupTempFiles()
+
 	if err := server.Start(ctx); err != nil {
 		return fmt.Errorf("start server: %w", err)
 	}
 
+	if c.Listen != "" {
+		token, err := remoteToken(paths)
+		if err != nil {
+			server.Stop()
+			return err
+		}
+		tlsConfig, err := c.remoteTLS()
+		if err != nil {
+			server.Stop()
+			return err
+		}
+		if err := server.ListenTCP(ctx, c.Listen, token, tlsConfig); err != nil {
+			server.Stop()
+			return fmt.Errorf("listen on %s: %w", c.Listen, err)
+		}
+	}
+
+	if c.Proxy != "" {
+		proxy := daemon.NewProxy(d, c.ProxyLoadTimeout, daemonLogWriter)
+		if c.LogRequests || c.IncludeBodies {
+			requestLogWriter := logging.NewRotatingWriter(logging.DefaultConfig(paths.RequestLog))
+			defer requestLogWriter.Close()
+			proxy.SetRequestLog(requestLogWriter, c.IncludeBodies)
+		}
+		if err := proxy.Start(ctx, c.Proxy); err != nil {
+			server.Stop()
+			return fmt.Errorf("start proxy on %s: %w", c.Proxy, err)
+		}
+		defer proxy.Stop()
+	}
+
+	// SIGHUP re-reads config.yaml, as is conventional for daemons
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	logger := logging.NewLogger(daemonLogWriter)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				c.reloadConfig(paths, cfg, d, server, logger)
+			}
+		}
+	}()
+
+	if cfg.RestoreLastModel && !adopted {
+		// Failures are logged by the daemon; it stays idle.
+		go d.RestoreLastLoad(ctx)
+	}
+
+	if maxAge := cfg.PartialMaxAge(); maxAge > 0 {
+		go pruneStaleDownloads(ctx, modelManager, time.Now().Add(-maxAge), logger)
+	}
+
+	// The schedule is re-read each minute, so `alpaca schedule add` takes
+	// effect without a restart.
+	scheduler := schedule.New(d, func() ([]schedule.Entry, error) {
+		cfg, err := config.Load(paths.Config)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.Schedule, nil
+	}, logger)
+	go scheduler.Run(ctx)
+
 	<-ctx.Done()
 
 	if err := server.Stop(); err != nil {
 		return fmt.Errorf("stop server: %w", err)
 	}
 
+	// Stop llama-server before exiting so it never outlives the daemon.
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), d.ShutdownTimeout())
+	defer cancelShutdown()
+	if err := d.Kill(shutdownCtx); err != nil {
+		return fmt.Errorf("stop model: %w", err)
+	}
+	d.WaitHooks(shutdownCtx)
+
+	return nil
+}
+
+// applyConfig applies the settings of cfg that the daemon reads on each
+// load and unload, and so can change while it runs. A setting that cannot
+// be resolved fails before any is changed.
+func applyConfig(d *daemon.Daemon, cfg *config.Config, paths *config.Paths) error {
+	var llamaServer string
+	if cfg.LlamaServerPath != "" {
+		var err error
+		if llamaServer, err = pathutil.ResolveCommand(cfg.LlamaServerPath, paths.Home); err != nil {
+			return fmt.Errorf("resolve llama-server-path: %w", err)
+		}
+	}
+	hooks, err := cfg.Hooks.Resolve(paths.Home)
+	if err != nil {
+		return err
+	}
+	var allowList *allowlist.Source
+	if cfg.VerifySignatures {
+		manifest := cfg.ModelManifest
+		if manifest != "" && !strings.Contains(manifest, "://") {
+			if manifest, err = pathutil.ResolvePath(manifest, paths.Home); err != nil {
+				return fmt.Errorf("resolve model-manifest: %w", err)
+			}
+		}
+		if allowList, err = allowlist.NewSource(manifest, cfg.ManifestPublicKey); err != nil {
+			return fmt.Errorf("verify-signatures: %w", err)
+		}
+	}
+	var runLogs string
+	if cfg.RunLogs {
+		runLogs = paths.RunLogs
+	}
+
+	d.SetLlamaServerPath(llamaServer)
+	d.SetHooks(hooks)
+	d.SetAllowList(allowList)
+	d.SetRunLogs(runLogs, cfg.RunLogsKeep)
+	d.SetStartupTimeout(time.Duration(cfg.StartupTimeout) * time.Second)
+	d.SetStopGracePeriod(time.Duration(cfg.UnloadTimeout) * time.Second)
 	return nil
 }
+
+// reloadConfig re-reads config.yaml for a SIGHUP and applies what it can
+// (see applyConfig), along with the remote token of --listen. started is
+// the config the daemon started with; settings that differ from it but are
+// only read at startup are logged as needing a restart. A config that does
+// not load or apply leaves the current settings in place.
+func (c *StartCmd) reloadConfig(paths *config.Paths, started *config.Config, d *daemon.Daemon, server *daemon.Server, logger *slog.Logger) {
+	cfg, err := config.Load(paths.Config)
+	if err == nil {
+		err = applyConfig(d, cfg, paths)
+	}
+	if err != nil {
+		logger.Error("config reload failed; keeping the current settings", "error", err)
+		return
+	}
+	if c.Listen != "" {
+		token, err := remoteToken(paths)
+		if err == nil {
+			err = server.SetToken(token)
+		}
+		if err != nil {
+			logger.Error("remote token reload failed; keeping the current token", "error", err)
+		}
+	}
+	logger.Info("config reloaded", "path", paths.Config)
+	if keys := restartOnlyChanges(started, cfg); len(keys) > 0 {
+		logger.Warn("config changes take effect after a restart (alpaca stop && alpaca start)", "settings", strings.Join(keys, ", "))
+	}
+}
+
+// restartOnlyChanges returns the config.yaml keys that differ between
+// started and cfg but are only read when the daemon starts.
+func restartOnlyChanges(started, cfg *config.Config) []string {
+	var keys []string
+	if started.SocketDir != cfg.SocketDir {
+		keys = append(keys, "socket-dir")
+	}
+	if !slices.Equal(started.AllowUsers, cfg.AllowUsers) {
+		keys = append(keys, "allow-users")
+	}
+	if valueOr(started.ShareModels, true) != valueOr(cfg.ShareModels, true) {
+		keys = append(keys, "share-models")
+	}
+	if started.MaxRequests != cfg.MaxRequests {
+		keys = append(keys, "max-requests")
+	}
+	return keys
+}
+
+// pruneStaleDownloads deletes the partial downloads and leftover temporary
+// files not written to since cutoff, logging what it deleted.
+func pruneStaleDownloads(ctx context.Context, mgr *model.Manager, cutoff time.Time, logger *slog.Logger) {
+	result, err := mgr.PruneStale(ctx, cutoff)
+	if err != nil {
+		logger.Warn("stale download cleanup failed", "error", err)
+	}
+	if result == nil {
+		return
+	}
+	for _, p := range result.Partials {
+		logger.Info("deleted stale partial download", "file", p.Filename, "size", p.Size, "modified", p.ModTime.Format(time.DateOnly))
+	}
+	for _, f := range result.Temp {
+		logger.Info("deleted stale temporary file", "file", f)
+	}
+	if n := len(result.Partials) + len(result.Temp); n > 0 {
+		logger.Info("stale download cleanup done", "deleted", n, "freed", formatSize(result.Freed))
+	}
+}
+
+// remoteTLS returns the TLS configuration of --listen, or nil for
+// plaintext when no certificate is given.
+func (c *StartCmd) remoteTLS() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" {
+		if c.TLSClientCA != "" {
+			return nil, fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+		}
+		return nil, nil
+	}
+	if c.TLSCert == "" || c.TLSKey == "" {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be given together")
+	}
+	return daemon.ServerTLSConfig(c.TLSCert, c.TLSKey, c.TLSClientCA)
+}
+
+// remoteToken returns the token remote clients must present.
+// ALPACA_TOKEN takes precedence over the generated token file.
+func remoteToken(paths *config.Paths) (string, error) {
+	if token := os.Getenv("ALPACA_TOKEN"); token != "" {
+		return token, nil
+	}
+	return daemon.LoadOrCreateToken(paths.RemoteToken)
+}
+
+// remoteTokenSource describes where the remote token comes from, for display.
+func remoteTokenSource(paths *config.Paths) string {
+	if os.Getenv("ALPACA_TOKEN") != "" {
+		return "ALPACA_TOKEN"
+	}
+	return paths.RemoteToken
+}
+
+// warnStaleDaemon warns when the running daemon speaks another protocol
+// version, which happens when alpaca is upgraded without restarting it.
+func warnStaleDaemon(socket string) {
+	v, err := client.New(socket).Hello(context.Background())
+	if err != nil || v == protocol.Version {
+		return
+	}
+	mismatch := &client.VersionMismatchError{Client: protocol.Version, Daemon: v}
+	ui.PrintWarning(mismatch.Error() + "\nRun: alpaca stop && alpaca start")
+}
+
+// migrateFiles upgrades config.yaml and the model metadata written by an
+// older alpaca, keeping backups. Failures are reported but do not stop the
+// start: older formats are still read as upgraded.
+func migrateFiles(paths *config.Paths) {
+	migrations := []func() (*migrate.Result, error){
+		func() (*migrate.Result, error) { return config.Migrate(paths.Config) },
+		func() (*migrate.Result, error) { return metadata.Migrate(paths.Models) },
+	}
+	for _, m := range migrations {
+		res, err := m()
+		switch {
+		case err != nil:
+			ui.PrintWarning(err.Error())
+		case res != nil:
+			ui.PrintInfo(fmt.Sprintf("Upgraded %s to format version %d (backup: %s)", res.Path, res.To, res.Backup))
+		}
+	}
+}
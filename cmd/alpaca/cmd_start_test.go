@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/daemon"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func TestApplyConfig_KeepsSettingsOnError(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	paths := &config.Paths{Home: home}
+	d := daemon.New(preset.NewLoader(home), model.NewManager(home), "", io.Discard, io.Discard)
+	if err := applyConfig(d, &config.Config{UnloadTimeout: 20}, paths); err != nil {
+		t.Fatalf("applyConfig() error = %v", err)
+	}
+	bad := &config.Config{UnloadTimeout: 30, VerifySignatures: true, ModelManifest: "https://example.com/m.txt", ManifestPublicKey: "not hex"}
+
+	// Act
+	err := applyConfig(d, bad, paths)
+
+	// Assert
+	if err == nil {
+		t.Fatal("applyConfig() succeeded with an invalid manifest-public-key")
+	}
+	if got := d.ShutdownTimeout(); got != 25*time.Second {
+		t.Errorf("ShutdownTimeout() = %v, want 25s from the settings applied before", got)
+	}
+}
+
+func TestRestartOnlyChanges(t *testing.T) {
+	no := false
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want []string
+	}{
+		{"unchanged", config.Config{UnloadTimeout: 30, LlamaServerPath: "/opt/llama-server"}, nil},
+		{"socket dir", config.Config{SocketDir: "/run/alpaca"}, []string{"socket-dir"}},
+		{"allow users and share models", config.Config{AllowUsers: []string{"alice"}, ShareModels: &no}, []string{"allow-users", "share-models"}},
+		{"max requests", config.Config{MaxRequests: 4}, []string{"max-requests"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := restartOnlyChanges(&config.Config{}, &tt.cfg)
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("restartOnlyChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type StatsCmd struct{}
+
+func (c *StatsCmd) Run() error {
+	cl, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := cl.Stats(context.Background())
+	if err != nil {
+		return clientError(err)
+	}
+	if resp.Status == "error" {
+		return fmt.Errorf("%s", resp.Error)
+	}
+
+	var data protocol.StatsData
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
+	var stats []ui.UsageStatsInfo
+	for _, m := range data.Models {
+		stats = append(stats, ui.UsageStatsInfo{
+			Model:            m.Model,
+			Requests:         m.Requests,
+			Errors:           m.Errors,
+			PromptTokens:     m.PromptTokens,
+			CompletionTokens: m.CompletionTokens,
+			AvgLatency:       time.Duration(m.AvgLatencyMS) * time.Millisecond,
+		})
+	}
+	ui.PrintUsageStats(stats)
+	return nil
+}
@@ -1,8 +1,27 @@
 package main
 
-import "github.com/d2verb/alpaca/internal/ui"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
-type StatusCmd struct{}
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type StatusCmd struct {
+	Watch    bool          `short:"w" help:"Refresh the status continuously until interrupted"`
+	Interval time.Duration `default:"2s" help:"Refresh interval for --watch"`
+	Verbose  bool          `short:"v" help:"Also show the llama-server command line (and router config.ini)"`
+}
 
 func (c *StatusCmd) Run() error {
 	cl, err := newClient()
@@ -10,45 +29,293 @@ func (c *StatusCmd) Run() error {
 		return err
 	}
 
-	resp, err := cl.Status()
+	paths, err := getPaths()
 	if err != nil {
-		return errDaemonNotRunning()
+		return err
 	}
 
-	paths, err := getPaths()
+	if c.Watch {
+		return c.watch(cl, paths)
+	}
+
+	resp, err := cl.Status(context.Background())
 	if err != nil {
+		return clientError(err)
+	}
+	var data protocol.StatusData
+	if err := resp.Decode(&data); err != nil {
 		return err
 	}
+	printStatus(&data, paths.LlamaLog)
+	printProcesses(time.Now(), &data)
+	if paths.Profile != "" && daemonHost == "" {
+		ui.PrintKeyValue("Profile", paths.Profile)
+	}
+	if c.Verbose {
+		printInvocation(data.Command, data.ConfigINI)
+	}
+	printUpdateNotice(paths)
+	return nil
+}
+
+// routerModelInfos converts router models for display, keeping nil (not
+// reported) apart from empty.
+func routerModelInfos(models []protocol.RouterModelData) []ui.RouterModelInfo {
+	if models == nil {
+		return nil
+	}
+	infos := []ui.RouterModelInfo{}
+	for _, m := range models {
+		infos = append(infos, ui.RouterModelInfo{
+			ID:          m.ID,
+			Status:      m.Status,
+			Mmproj:      m.Mmproj,
+			ActiveSlots: valueOr(m.ActiveSlots, -1),
+			Queued:      valueOr(m.Queued, -1),
+		})
+	}
+	return infos
+}
 
-	state, _ := resp.Data["state"].(string)
-	preset, _ := resp.Data["preset"].(string)
-	endpoint, _ := resp.Data["endpoint"].(string)
-	mode, _ := resp.Data["mode"].(string)
-
-	if mode == "router" {
-		var models []ui.RouterModelInfo
-		if rawModels, ok := resp.Data["models"].([]any); ok {
-			for _, rm := range rawModels {
-				if m, ok := rm.(map[string]any); ok {
-					models = append(models, ui.RouterModelInfo{
-						ID:     stringVal(m, "id"),
-						Status: stringVal(m, "status"),
-						Mmproj: stringVal(m, "mmproj"),
-					})
-				}
-			}
+// printStatus prints a status response.
+func printStatus(data *protocol.StatusData, logPath string) {
+	if data.Mode == "router" {
+		ui.PrintRouterStatus(data.State, data.Detail, data.Preset, data.Endpoint, logPath, routerModelInfos(data.Models), data.ModelsError)
+	} else {
+		ui.PrintStatus(data.State, data.Detail, data.Preset, data.Endpoint, logPath, data.Mmproj)
+	}
+	if m := data.Metrics; m != nil {
+		ui.PrintServerMetrics(ui.ServerMetrics{
+			KVCacheUsage:          valueOr(m.KVCacheUsage, -1),
+			SlotsBusy:             valueOr(m.SlotsBusy, -1),
+			SlotsTotal:            valueOr(m.SlotsTotal, -1),
+			PromptTokensPerSec:    valueOr(m.PromptTokensPerSec, -1),
+			PredictedTokensPerSec: valueOr(m.PredictedTokensPerSec, -1),
+		})
+	}
+}
+
+// valueOr returns *p, or def when p is nil.
+func valueOr[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// printProcesses prints the daemon's version, uptime and resource usage,
+// and llama-server's when it runs. A daemon whose version differs from this
+// CLI's is flagged, since it keeps running the old binary until restarted.
+func printProcesses(now time.Time, data *protocol.StatusData) {
+	d := data.Daemon
+	if d == nil {
+		return
+	}
+	parts := []string{"alpaca " + d.Version}
+	if !d.StartedAt.IsZero() {
+		parts = append(parts, "up "+now.Sub(d.StartedAt).Truncate(time.Second).String())
+	}
+	ui.PrintKeyValue("Daemon", strings.Join(append(parts, processUsage(d)...), " · "))
+	if s := data.LlamaServer; s != nil {
+		ui.PrintKeyValue("llama-server", strings.Join(processUsage(s), " · "))
+	}
+	if d.Version != "" && d.Version != version {
+		ui.PrintWarning(fmt.Sprintf("The daemon runs alpaca %s, but this CLI is %s.\nRun: alpaca stop && alpaca start", d.Version, version))
+	}
+}
+
+// processUsage formats the PID, resident memory and CPU time of a process
+// in a status response, leaving out what the daemon could not read.
+func processUsage(p *protocol.ProcessData) []string {
+	parts := []string{fmt.Sprintf("PID %d", p.PID)}
+	if p.RSS != nil {
+		parts = append(parts, formatSize(*p.RSS)+" RSS")
+	}
+	if p.CPUSeconds != nil {
+		parts = append(parts, "CPU "+time.Duration(*p.CPUSeconds*float64(time.Second)).Truncate(time.Second).String())
+	}
+	return parts
+}
+
+// printInvocation prints the command line the running llama-server was
+// started with, quoted so it can be pasted into a shell, and the router
+// config.ini it reads.
+func printInvocation(argv []string, ini string) {
+	if len(argv) == 0 {
+		return
+	}
+	fmt.Fprintln(ui.Output)
+	ui.PrintLines("💻", "Command", []string{shellJoin(argv)})
+	if ini != "" {
+		fmt.Fprintln(ui.Output)
+		ui.PrintLines("📄", "config.ini", strings.Split(strings.TrimRight(ini, "\n"), "\n"))
+	}
+}
+
+// shellJoin joins argv into a POSIX shell command line, single-quoting
+// arguments that contain anything but safe characters.
+func shellJoin(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	safe := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=,+@%", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// watchLogLines is the number of llama-server log lines shown by --watch.
+const watchLogLines = 5
+
+// watch redraws the status every interval until interrupted.
+func (c *StatusCmd) watch(cl *client.Client, paths *config.Paths) error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Logs live on the daemon host; only a local daemon's log can be tailed.
+	tailPath := ""
+	if daemonHost == "" {
+		tailPath = paths.LlamaLog
+	}
+
+	live := isTerminal()
+	w := &statusWatcher{}
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		resp, err := cl.Status(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		frame := w.render(time.Now(), resp, err, paths.LlamaLog, tailPath, c.Interval)
+		if live {
+			// Home the cursor and clear, then draw the whole frame at once.
+			fmt.Fprint(os.Stdout, "\033[H\033[2J")
 		}
-		ui.PrintRouterStatus(state, preset, endpoint, paths.LlamaLog, models)
+		os.Stdout.Write(frame)
+		if !live {
+			fmt.Fprintln(os.Stdout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusWatcher renders watch frames and remembers state transitions.
+type statusWatcher struct {
+	last        string // "state preset" of the previous poll
+	transitions []string
+}
+
+// maxTransitions is the number of state transitions shown by --watch.
+const maxTransitions = 5
+
+// observe records a transition when the state or preset changed.
+func (w *statusWatcher) observe(now time.Time, state, preset string) {
+	key := state + " " + preset
+	if key == w.last {
+		return
+	}
+	from := strings.TrimSpace(w.last)
+	w.last = key
+	if from == "" {
+		return
+	}
+	line := fmt.Sprintf("%s  %s %s %s", now.Format("15:04:05"), from, ui.Symbol("→"), strings.TrimSpace(key))
+	w.transitions = append(w.transitions, line)
+	if len(w.transitions) > maxTransitions {
+		w.transitions = w.transitions[1:]
+	}
+}
+
+// render builds one frame. A status error means the daemon is unreachable.
+// An empty tailPath omits the log tail.
+func (w *statusWatcher) render(now time.Time, resp *protocol.Response, statusErr error, logPath, tailPath string, interval time.Duration) []byte {
+	var buf bytes.Buffer
+	orig := ui.Output
+	ui.Output = &buf
+	defer func() { ui.Output = orig }()
+
+	fmt.Fprintln(&buf, ui.Muted(fmt.Sprintf("Every %s · %s · Ctrl-C to exit", interval, now.Format("15:04:05"))))
+	fmt.Fprintln(&buf)
+
+	if statusErr != nil {
+		w.observe(now, "not running", "")
+		ui.PrintWarning("Daemon is not running")
 	} else {
-		mmproj := stringVal(resp.Data, "mmproj")
-		ui.PrintStatus(state, preset, endpoint, paths.LlamaLog, mmproj)
+		var data protocol.StatusData
+		if err := resp.Decode(&data); err != nil {
+			ui.PrintError(err.Error())
+		}
+		w.observe(now, data.State, data.Preset)
+		printStatus(&data, logPath)
+		printProcesses(now, &data)
+		if !data.Since.IsZero() && data.State != "idle" {
+			ui.PrintKeyValue("Uptime", now.Sub(data.Since).Truncate(time.Second).String())
+		}
 	}
 
-	return nil
+	if len(w.transitions) > 0 {
+		fmt.Fprintln(&buf)
+		ui.PrintLines("🔁", "Transitions", w.transitions)
+	}
+	if tailPath != "" {
+		if lines, err := tailLines(tailPath, watchLogLines); err == nil && len(lines) > 0 {
+			fmt.Fprintln(&buf)
+			ui.PrintLines("📜", "Recent log", lines)
+		}
+	}
+	return buf.Bytes()
 }
 
-// stringVal extracts a string value from a map, returning empty string if not found.
-func stringVal(m map[string]any, key string) string {
-	v, _ := m[key].(string)
-	return v
+// tailLines returns up to n last lines of the file at path.
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Log lines are short; the last 16KB holds far more than n of them.
+	const window = 16 * 1024
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := max(fi.Size()-window, 0)
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:] // first line is likely partial
+	}
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines[max(len(lines)-n, 0):], nil
 }
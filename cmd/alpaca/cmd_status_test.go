@@ -1,45 +1,245 @@
 package main
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
 
-func TestStringVal(t *testing.T) {
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/ui"
+	"github.com/fatih/color"
+)
+
+func TestValueOr(t *testing.T) {
+	// Arrange
+	four := 4
+
+	// Act
+	set, unset := valueOr(&four, -1), valueOr[int](nil, -1)
+
+	// Assert
+	if set != 4 || unset != -1 {
+		t.Errorf("valueOr() = %d, %d, want 4, -1", set, unset)
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		n       int
+		want    []string
+	}{
+		{name: "fewer lines than n", content: "a\nb\n", n: 5, want: []string{"a", "b"}},
+		{name: "more lines than n", content: "a\nb\nc\nd\n", n: 2, want: []string{"c", "d"}},
+		{name: "no trailing newline", content: "a\nb", n: 1, want: []string{"b"}},
+		{name: "empty file", content: "", n: 3, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "llama.log")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			got, err := tailLines(path, tt.n)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("tailLines() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("tailLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailLines_LargeFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "llama.log")
+	var b strings.Builder
+	for range 5000 {
+		b.WriteString("some log line that is reasonably long\n")
+	}
+	b.WriteString("last\n")
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	got, err := tailLines(path, 2)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("tailLines() error = %v", err)
+	}
+	want := []string{"some log line that is reasonably long", "last"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tailLines() = %q, want %q", got, want)
+	}
+}
+
+func TestStatusWatcher_Render(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	w := &statusWatcher{}
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	busy, total := 1, 4
+	idle := protocol.NewOKResponse(protocol.StatusData{State: "idle", Since: start})
+	running := protocol.NewOKResponse(protocol.StatusData{
+		State:    "running",
+		Preset:   "p:qwen",
+		Endpoint: "http://127.0.0.1:8080",
+		Since:    start.Add(time.Minute),
+		Metrics:  &protocol.MetricsData{SlotsBusy: &busy, SlotsTotal: &total},
+	})
+
+	// Act
+	w.render(start, idle, nil, "/tmp/llama.log", "", time.Second)
+	frame := string(w.render(start.Add(2*time.Minute), running, nil, "/tmp/llama.log", "", time.Second))
+	down := string(w.render(start.Add(3*time.Minute), nil, errors.New("connection refused"), "/tmp/llama.log", "", time.Second))
+
+	// Assert
+	if !strings.Contains(frame, "Uptime") || !strings.Contains(frame, "1m0s") {
+		t.Errorf("frame should show uptime since the running transition:\n%s", frame)
+	}
+	if !strings.Contains(frame, "1/4 busy") {
+		t.Errorf("frame should show llama-server metrics:\n%s", frame)
+	}
+	if !strings.Contains(frame, "10:02:00  idle → running p:qwen") {
+		t.Errorf("frame should record the idle → running transition:\n%s", frame)
+	}
+	if !strings.Contains(down, "Daemon is not running") {
+		t.Errorf("frame should report the daemon as down:\n%s", down)
+	}
+	if !strings.Contains(down, "running p:qwen → not running") {
+		t.Errorf("frame should record the daemon going away:\n%s", down)
+	}
+}
+
+func TestShellJoin(t *testing.T) {
 	tests := []struct {
 		name string
-		m    map[string]any
-		key  string
+		argv []string
 		want string
+	}{
+		{"plain", []string{"llama-server", "-m", "/models/q.gguf", "--port", "8080"}, "llama-server -m /models/q.gguf --port 8080"},
+		{"space", []string{"llama-server", "-m", "/My Models/q.gguf"}, "llama-server -m '/My Models/q.gguf'"},
+		{"single quote", []string{"echo", "it's"}, `echo 'it'\''s'`},
+		{"empty", []string{"llama-server", "--alias", ""}, "llama-server --alias ''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shellJoin(tt.argv); got != tt.want {
+				t.Errorf("shellJoin(%q) = %q, want %q", tt.argv, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintInvocation(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf strings.Builder
+	ui.Output = &buf
+	defer func() { ui.Output = os.Stdout }()
+	argv := []string{"llama-server", "--models-preset", "/home/u/.alpaca/router-config.ini"}
+
+	// Act
+	printInvocation(argv, "[chat]\nmodel = /m.gguf\n")
+
+	// Assert
+	out := buf.String()
+	if !strings.Contains(out, "  llama-server --models-preset /home/u/.alpaca/router-config.ini\n") {
+		t.Errorf("output should contain the command line:\n%s", out)
+	}
+	if !strings.Contains(out, "  [chat]\n  model = /m.gguf\n") {
+		t.Errorf("output should contain config.ini:\n%s", out)
+	}
+}
+
+func TestPrintProcesses(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+	origVersion := version
+	defer func() { version = origVersion }()
+	version = "v1.2.0"
+
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	daemonRSS, daemonCPU := int64(50<<20), 12.5
+	serverRSS, serverCPU := int64(4<<30), 200.0
+	tests := []struct {
+		name        string
+		data        protocol.StatusData
+		want        []string
+		wantWarning bool
 	}{
 		{
-			name: "existing string key",
-			m:    map[string]any{"id": "qwen3"},
-			key:  "id",
-			want: "qwen3",
+			name: "daemon and llama-server",
+			data: protocol.StatusData{
+				Daemon: &protocol.ProcessData{
+					Version: "v1.2.0", PID: 100, StartedAt: now.Add(-90 * time.Minute),
+					RSS: &daemonRSS, CPUSeconds: &daemonCPU,
+				},
+				LlamaServer: &protocol.ProcessData{PID: 200, RSS: &serverRSS, CPUSeconds: &serverCPU},
+			},
+			want: []string{
+				"  Daemon           alpaca v1.2.0 · up 1h30m0s · PID 100 · 50.0 MB RSS · CPU 12s\n",
+				"  llama-server     PID 200 · 4.0 GB RSS · CPU 3m20s\n",
+			},
 		},
 		{
-			name: "missing key returns empty",
-			m:    map[string]any{"id": "qwen3"},
-			key:  "status",
-			want: "",
+			name: "usage not readable",
+			data: protocol.StatusData{Daemon: &protocol.ProcessData{Version: "v1.2.0", PID: 100}},
+			want: []string{"  Daemon           alpaca v1.2.0 · PID 100\n"},
 		},
 		{
-			name: "non-string value returns empty",
-			m:    map[string]any{"count": 42},
-			key:  "count",
-			want: "",
+			name:        "older daemon",
+			data:        protocol.StatusData{Daemon: &protocol.ProcessData{Version: "v1.1.0", PID: 100}},
+			want:        []string{"alpaca stop && alpaca start"},
+			wantWarning: true,
 		},
 		{
-			name: "empty map",
-			m:    map[string]any{},
-			key:  "id",
-			want: "",
+			name: "daemon without process info",
+			data: protocol.StatusData{State: "idle"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := stringVal(tt.m, tt.key)
-			if got != tt.want {
-				t.Errorf("stringVal(%v, %q) = %q, want %q", tt.m, tt.key, got, tt.want)
+			// Arrange
+			var buf strings.Builder
+			ui.Output = &buf
+			defer func() { ui.Output = os.Stdout }()
+
+			// Act
+			printProcesses(now, &tt.data)
+
+			// Assert
+			out := buf.String()
+			for _, w := range tt.want {
+				if !strings.Contains(out, w) {
+					t.Errorf("output should contain %q:\n%s", w, out)
+				}
+			}
+			if len(tt.want) == 0 && out != "" {
+				t.Errorf("output = %q, want none", out)
+			}
+			if got := strings.Contains(out, "this CLI is v1.2.0"); got != tt.wantWarning {
+				t.Errorf("version warning = %v, want %v:\n%s", got, tt.wantWarning, out)
 			}
 		})
 	}
@@ -1,17 +1,22 @@
 package main
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"os"
 	"syscall"
 	"time"
 
+	"github.com/d2verb/alpaca/internal/client"
 	"github.com/d2verb/alpaca/internal/daemon"
+	"github.com/d2verb/alpaca/internal/protocol"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
-type StopCmd struct{}
+type StopCmd struct {
+	Timeout time.Duration `default:"20s" help:"How long to wait for the daemon to stop llama-server and exit"`
+	Force   bool          `help:"Kill the daemon if it has not exited within --timeout"`
+}
 
 func (c *StopCmd) Run() error {
 	paths, err := getPaths()
@@ -21,25 +26,18 @@ func (c *StopCmd) Run() error {
 
 	// Get daemon status
 	status, err := daemon.GetDaemonStatus(paths.PID, paths.Socket)
-	if err != nil && !errors.Is(err, daemon.ErrPIDFileNotFound) {
-		// If there's an error but socket exists, try to clean up
-		if status.SocketExists {
-			ui.PrintWarning("Stale daemon state detected")
-			ui.PrintInfo(fmt.Sprintf("Manual cleanup: rm %s", paths.Socket))
-		}
+	if err != nil {
 		return fmt.Errorf("check daemon status: %w", err)
 	}
 
 	if !status.Running {
 		ui.PrintInfo("Daemon is not running")
-		// Clean up stale files
-		daemon.RemovePIDFile(paths.PID)
-		if status.SocketExists {
-			os.Remove(paths.Socket)
-		}
 		return nil
 	}
 
+	// Best-effort: remember what is loaded so we can report it.
+	loaded := loadedPreset(client.New(paths.Socket))
+
 	// Send SIGTERM
 	process, err := os.FindProcess(status.PID)
 	if err != nil {
@@ -51,27 +49,60 @@ func (c *StopCmd) Run() error {
 		return fmt.Errorf("send SIGTERM: %w", err)
 	}
 
-	// Wait for process to exit (max 10 seconds)
-	for range 100 {
-		time.Sleep(100 * time.Millisecond)
-		running, err := daemon.IsProcessRunning(status.PID)
-		if err != nil {
-			return fmt.Errorf("check process: %w", err)
+	exited, err := waitForExit(status.PID, c.Timeout)
+	if err != nil {
+		return err
+	}
+	if !exited {
+		if !c.Force {
+			return fmt.Errorf("daemon did not stop within %s (use --force to kill it)", c.Timeout)
 		}
-		if !running {
-			ui.PrintSuccess("Daemon stopped")
-			daemon.RemovePIDFile(paths.PID)
-			return nil
+		ui.PrintWarning("Daemon did not stop gracefully, forcing...")
+		if err := process.Kill(); err != nil {
+			return fmt.Errorf("kill daemon: %w", err)
+		}
+		if loaded != "" {
+			ui.PrintWarning(fmt.Sprintf("llama-server for '%s' may still be running", loaded))
 		}
+		loaded = ""
 	}
 
-	// Force kill if still running
-	ui.PrintWarning("Daemon did not stop gracefully, forcing...")
-	if err := process.Kill(); err != nil {
-		return fmt.Errorf("kill daemon: %w", err)
+	if loaded != "" {
+		ui.PrintInfo(fmt.Sprintf("Unloaded '%s'", loaded))
 	}
-
-	daemon.RemovePIDFile(paths.PID)
 	ui.PrintSuccess("Daemon stopped")
 	return nil
 }
+
+// loadedPreset returns the name of the preset the daemon is running or
+// loading, or "" if it is idle or cannot be queried.
+func loadedPreset(cl *client.Client) string {
+	resp, err := cl.Status(context.Background())
+	if err != nil || resp.Status != protocol.StatusOK {
+		return ""
+	}
+	var data protocol.StatusData
+	if err := resp.Decode(&data); err != nil || data.State == string(daemon.StateIdle) {
+		return ""
+	}
+	return data.Preset
+}
+
+// waitForExit polls until the process exits or timeout elapses.
+// Reports whether the process exited.
+func waitForExit(pid int, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		running, err := daemon.IsProcessRunning(pid)
+		if err != nil {
+			return false, fmt.Errorf("check process: %w", err)
+		}
+		if !running {
+			return true, nil
+		}
+		if !time.Now().Before(deadline) {
+			return false, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestWaitForExit_ProcessExits(t *testing.T) {
+	// Arrange
+	cmd := exec.Command("sleep", "0.1")
+	if err := cmd.Start(); err != nil {
+		t.Skipf("sleep not available: %v", err)
+	}
+	go cmd.Wait() // reap so the PID disappears
+
+	// Act
+	exited, err := waitForExit(cmd.Process.Pid, 5*time.Second)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("waitForExit() error = %v", err)
+	}
+	if !exited {
+		t.Error("waitForExit() = false, want true")
+	}
+}
+
+func TestWaitForExit_Timeout(t *testing.T) {
+	// Arrange: the test process itself never exits during the wait
+	pid := os.Getpid()
+
+	// Act
+	start := time.Now()
+	exited, err := waitForExit(pid, 200*time.Millisecond)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("waitForExit() error = %v", err)
+	}
+	if exited {
+		t.Error("waitForExit() = true, want false")
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("waitForExit() returned after %s, before the timeout", elapsed)
+	}
+}
@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+type UICmd struct {
+	Interval time.Duration `default:"2s" help:"Status refresh interval"`
+}
+
+// Keys read from the terminal in raw mode.
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyEnter = "enter"
+	keyQuit  = "quit"
+)
+
+// uiFooter lists the key bindings.
+const uiFooter = "↑/↓ select · enter load · u unload · c cancel · p pull · r refresh · q quit"
+
+func (c *UICmd) Run() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	if !isTerminal() || !stdinIsTerminal() {
+		return fmt.Errorf("alpaca ui needs a terminal")
+	}
+	cl, err := newClient()
+	if err != nil {
+		return err
+	}
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return fmt.Errorf("set terminal mode: %w", err)
+	}
+	// Alternate screen, hidden cursor; both undone on exit.
+	fmt.Fprint(os.Stdout, "\033[?1049h\033[?25l")
+	defer func() {
+		fmt.Fprint(os.Stdout, "\033[?25h\033[?1049l")
+		restore()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &uiSession{cl: cl, paths: paths, ctx: ctx, events: make(chan func(*uiModel), 16)}
+	return s.loop(c.Interval)
+}
+
+// uiSession runs the terminal UI: it reads keys, polls the daemon, and
+// runs actions in the background, applying their results to the model on
+// the main loop.
+type uiSession struct {
+	cl     *client.Client
+	paths  *config.Paths
+	ctx    context.Context
+	events chan func(*uiModel) // results of background work
+}
+
+func (s *uiSession) loop(interval time.Duration) error {
+	keys := make(chan string)
+	go readKeys(os.Stdin, keys)
+
+	m := &uiModel{}
+	s.refresh(m, true)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s.draw(m)
+		select {
+		case key, ok := <-keys:
+			if !ok || key == keyQuit {
+				return nil
+			}
+			s.handleKey(m, key)
+		case apply := <-s.events:
+			apply(m)
+		case <-ticker.C:
+			s.refresh(m, false)
+		}
+	}
+}
+
+func (s *uiSession) draw(m *uiModel) {
+	width, height, err := terminalSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	// Raw mode turns off output processing, so lines end in \r\n.
+	frame := strings.ReplaceAll(m.render(width, height), "\n", "\r\n")
+	fmt.Fprint(os.Stdout, "\033[H\033[2J"+frame)
+}
+
+// refresh polls the daemon status and, with lists, the presets and models.
+func (s *uiSession) refresh(m *uiModel, lists bool) {
+	resp, err := s.cl.Status(s.ctx)
+	m.setStatus(resp, err)
+	if daemonHost == "" {
+		m.logLines, _ = tailLines(s.paths.LlamaLog, watchLogLines*2)
+	}
+	if !lists || err != nil {
+		return
+	}
+	var items []uiItem
+	if resp, err := s.cl.ListPresets(s.ctx); err == nil {
+		var data protocol.ListPresetsData
+		if resp.Decode(&data) == nil {
+			for _, name := range data.Presets {
+				items = append(items, uiItem{id: "p:" + name})
+			}
+		}
+	}
+	if resp, err := s.cl.ListModels(s.ctx); err == nil {
+		var data protocol.ListModelsData
+		if resp.Decode(&data) == nil {
+			for _, mm := range data.Models {
+				items = append(items, uiItem{
+					id:   fmt.Sprintf("h:%s:%s", mm.Repo, mm.Quant),
+					size: mm.Size,
+				})
+			}
+		}
+	}
+	m.setItems(items)
+}
+
+func (s *uiSession) handleKey(m *uiModel, key string) {
+	switch key {
+	case keyUp:
+		m.move(-1)
+	case keyDown:
+		m.move(1)
+	case "r":
+		s.refresh(m, true)
+	case "c":
+		// Not through run: the load being canceled is the action in progress.
+		go func() {
+			resp, err := s.cl.Cancel(s.ctx)
+			if err := responseErr(resp, err); err != nil {
+				s.send(func(m *uiModel) { m.setMessage(false, err.Error()) })
+			}
+		}()
+	case "u":
+		s.run(m, "Unloading...", func() (string, error) {
+			resp, err := s.cl.Unload(s.ctx, client.UnloadOptions{})
+			return "Model unloaded", responseErr(resp, err)
+		})
+	case keyEnter:
+		item, ok := m.selected()
+		if !ok {
+			return
+		}
+		s.run(m, fmt.Sprintf("Loading %s...", item.id), func() (string, error) {
+			resp, err := s.cl.Load(s.ctx, item.id, client.LoadOptions{})
+			return fmt.Sprintf("Loaded %s", item.id), responseErr(resp, err)
+		})
+	case "p":
+		item, ok := m.selected()
+		if !ok || !strings.HasPrefix(item.id, "h:") {
+			m.setMessage(false, "Select a model to pull")
+			return
+		}
+		s.run(m, fmt.Sprintf("Pulling %s...", item.id), func() (string, error) {
+			return fmt.Sprintf("Pulled %s", item.id), s.pull(item.id)
+		})
+	}
+}
+
+// run starts action in the background unless one is already running.
+func (s *uiSession) run(m *uiModel, busy string, action func() (string, error)) {
+	if m.busy != "" {
+		return
+	}
+	m.busy = busy
+	go func() {
+		done, err := action()
+		s.send(func(m *uiModel) {
+			m.busy = ""
+			if err != nil {
+				m.setMessage(false, err.Error())
+			} else {
+				m.setMessage(true, done)
+			}
+			s.refresh(m, true)
+		})
+	}()
+}
+
+// send delivers a result to the main loop, dropping it after exit.
+func (s *uiSession) send(apply func(*uiModel)) {
+	select {
+	case s.events <- apply:
+	case <-s.ctx.Done():
+	}
+}
+
+// pull downloads the model id, reporting progress in the busy line.
+func (s *uiSession) pull(id string) error {
+	parsed, err := identifier.Parse(id)
+	if err != nil {
+		return err
+	}
+	puller, err := newPuller(s.paths, s.paths.Models, "")
+	if err != nil {
+		return err
+	}
+	var last time.Time
+	puller.SetReportFunc(func(pr pull.Progress) {
+		if time.Since(last) < progressRedrawInterval && pr.Downloaded != pr.Total {
+			return
+		}
+		last = time.Now()
+		line := fmt.Sprintf("Pulling %s  %s", id, formatTransfer(pr, 20))
+		s.send(func(m *uiModel) { m.busy = line })
+	})
+	result, err := puller.Pull(s.ctx, parsed.Repo, parsed.Quant)
+	if err != nil {
+		return err
+	}
+	if result.MmprojFailed {
+		return fmt.Errorf("mmproj download failed - vision unavailable")
+	}
+	return nil
+}
+
+// responseErr returns the error of a daemon request, if any.
+func responseErr(resp *protocol.Response, err error) error {
+	if err != nil {
+		return clientError(err)
+	}
+	if resp.Status == protocol.StatusError {
+		return fmt.Errorf("%s", resp.Error)
+	}
+	return nil
+}
+
+// readKeys reads keys from r and sends them to keys, closing it when r
+// ends.
+func readKeys(r *os.File, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+	}
+}
+
+// parseKeys splits raw terminal input into keys. Arrow keys and Enter are
+// named; Ctrl-C, Esc, and q quit; j and k move like the arrows.
+func parseKeys(b []byte) []string {
+	var keys []string
+	for i := 0; i < len(b); i++ {
+		switch c := b[i]; {
+		case c == 0x1b && i+2 < len(b) && b[i+1] == '[':
+			switch b[i+2] {
+			case 'A':
+				keys = append(keys, keyUp)
+			case 'B':
+				keys = append(keys, keyDown)
+			}
+			i += 2
+		case c == 0x1b, c == 0x03, c == 'q':
+			keys = append(keys, keyQuit)
+		case c == '\r', c == '\n':
+			keys = append(keys, keyEnter)
+		case c == 'k':
+			keys = append(keys, keyUp)
+		case c == 'j':
+			keys = append(keys, keyDown)
+		default:
+			keys = append(keys, string(c))
+		}
+	}
+	return keys
+}
+
+// uiItem is a preset or model that can be loaded.
+type uiItem struct {
+	id   string
+	size int64 // models only
+}
+
+// uiModel is the state shown by the terminal UI.
+type uiModel struct {
+	state    string // empty when the daemon is not running
+	preset   string
+	endpoint string
+
+	items  []uiItem
+	cursor int
+
+	busy    string // action in progress
+	message string // result of the last action
+	ok      bool
+
+	logLines []string
+}
+
+func (m *uiModel) setStatus(resp *protocol.Response, err error) {
+	var data protocol.StatusData
+	if err != nil || resp.Status != protocol.StatusOK || resp.Decode(&data) != nil {
+		data = protocol.StatusData{}
+	}
+	m.state, m.preset, m.endpoint = data.State, data.Preset, data.Endpoint
+}
+
+// setItems replaces the list, keeping the selected item when it remains.
+func (m *uiModel) setItems(items []uiItem) {
+	var selected string
+	if item, ok := m.selected(); ok {
+		selected = item.id
+	}
+	m.items = items
+	m.cursor = max(slices.IndexFunc(items, func(it uiItem) bool { return it.id == selected }), 0)
+}
+
+func (m *uiModel) selected() (uiItem, bool) {
+	if m.cursor < len(m.items) {
+		return m.items[m.cursor], true
+	}
+	return uiItem{}, false
+}
+
+func (m *uiModel) move(delta int) {
+	if len(m.items) > 0 {
+		m.cursor = min(max(m.cursor+delta, 0), len(m.items)-1)
+	}
+}
+
+func (m *uiModel) setMessage(ok bool, message string) {
+	m.ok, m.message = ok, message
+}
+
+// render draws the model into a frame of at most height lines, each cut to
+// width columns.
+func (m *uiModel) render(width, height int) string {
+	var header []string
+	if m.state == "" {
+		header = append(header, ui.StatusBadge(""), ui.Muted("Run: alpaca start"))
+	} else {
+		line := ui.StatusBadge(m.state)
+		if m.preset != "" {
+			line += "  " + ui.Primary(m.preset)
+		}
+		if m.endpoint != "" {
+			line += "  " + ui.FormatEndpoint(m.endpoint)
+		}
+		header = append(header, line)
+	}
+
+	var status []string
+	switch {
+	case m.busy != "":
+		status = append(status, ui.Warning("…")+" "+m.busy)
+	case m.message != "" && m.ok:
+		status = append(status, ui.Success("✓")+" "+m.message)
+	case m.message != "":
+		// Errors can span lines (e.g. llama-server output); show them all.
+		for i, l := range strings.Split(m.message, "\n") {
+			if i == 0 {
+				l = ui.Error("✗") + " " + l
+			}
+			status = append(status, l)
+		}
+	}
+
+	var logs []string
+	if len(m.logLines) > 0 {
+		logs = append([]string{ui.Heading("Recent log")}, m.logLines...)
+	}
+
+	// The list gets the rows left over, at least a few.
+	fixed := len(header) + 1 + len(status) + 1 + 1 // blank lines and footer
+	listRows := max(height-fixed-len(logs)-1, 3)
+	if extra := height - fixed - listRows - 1; extra < len(logs) {
+		logs = logs[:max(extra, 0)]
+	}
+
+	lines := append(header, "")
+	lines = append(lines, m.renderList(listRows)...)
+	lines = append(lines, "")
+	lines = append(lines, status...)
+	if len(logs) > 0 {
+		lines = append(lines, "")
+		lines = append(lines, logs...)
+	}
+	lines = append(lines, "", ui.Muted(uiFooter))
+
+	for i, l := range lines {
+		lines[i] = truncateVisible(l, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderList draws the items in rows lines, scrolled to show the cursor.
+func (m *uiModel) renderList(rows int) []string {
+	if len(m.items) == 0 {
+		return []string{ui.Muted("No presets or models (alpaca new, alpaca pull)")}
+	}
+	start := max(min(m.cursor-rows/2, len(m.items)-rows), 0)
+	end := min(start+rows, len(m.items))
+	var lines []string
+	for i := start; i < end; i++ {
+		item := m.items[i]
+		line := "  " + item.id
+		if item.size > 0 {
+			line += "  " + ui.Muted(formatSize(item.size))
+		}
+		if i == m.cursor {
+			line = ui.Primary("›") + " " + ui.Primary(item.id) + strings.TrimPrefix(line, "  "+item.id)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncateVisible cuts s to width visible columns, skipping ANSI escape
+// sequences when counting and keeping them in the output.
+func truncateVisible(s string, width int) string {
+	var b strings.Builder
+	visible := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			b.WriteRune(r)
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+			continue
+		case r == '\033':
+			inEscape = true
+			b.WriteRune(r)
+			continue
+		}
+		if visible >= width {
+			continue
+		}
+		b.WriteRune(r)
+		visible++
+	}
+	return b.String()
+}
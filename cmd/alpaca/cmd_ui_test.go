@@ -0,0 +1,117 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/fatih/color"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"arrows", "\x1b[A\x1b[B", []string{keyUp, keyDown}},
+		{"vi keys", "kj", []string{keyUp, keyDown}},
+		{"enter", "\r", []string{keyEnter}},
+		{"quit keys", "q\x03\x1b", []string{keyQuit, keyQuit, keyQuit}},
+		{"actions", "up", []string{"u", "p"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := parseKeys([]byte(tt.input))
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseKeys(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUIModel_SetItemsKeepsSelection(t *testing.T) {
+	// Arrange
+	m := &uiModel{}
+	m.setItems([]uiItem{{id: "p:a"}, {id: "p:b"}})
+	m.move(1)
+
+	// Act
+	m.setItems([]uiItem{{id: "p:new"}, {id: "p:a"}, {id: "p:b"}})
+
+	// Assert
+	if item, _ := m.selected(); item.id != "p:b" {
+		t.Errorf("selected = %q, want %q", item.id, "p:b")
+	}
+}
+
+func TestUIModel_Render(t *testing.T) {
+	// Arrange
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+	m := &uiModel{}
+	m.setStatus(protocol.NewOKResponse(protocol.StatusData{
+		State:    "running",
+		Preset:   "coder",
+		Endpoint: "http://127.0.0.1:8080",
+	}), nil)
+	m.setItems([]uiItem{{id: "p:coder"}, {id: "h:org/repo:Q4_K_M", size: 2048}})
+	m.move(1)
+	m.setMessage(false, "load failed\nllama-server output:")
+	m.logLines = []string{"log line"}
+
+	// Act
+	frame := m.render(80, 24)
+
+	// Assert
+	for _, want := range []string{"coder", "http://127.0.0.1:8080", "  p:coder", "› h:org/repo:Q4_K_M", "2.0 KB", "✗ load failed", "llama-server output:", "log line", "q quit"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame missing %q:\n%s", want, frame)
+		}
+	}
+	if lines := strings.Count(frame, "\n") + 1; lines > 24 {
+		t.Errorf("frame has %d lines, want at most 24", lines)
+	}
+}
+
+func TestUIModel_RenderScrollsToCursor(t *testing.T) {
+	// Arrange
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+	m := &uiModel{}
+	var items []uiItem
+	for _, c := range "abcdefghijklmnopqrstuvwxyz" {
+		items = append(items, uiItem{id: "p:" + string(c)})
+	}
+	m.setItems(items)
+	m.move(len(items) - 1)
+
+	// Act
+	frame := m.render(80, 12)
+
+	// Assert
+	if !strings.Contains(frame, "› p:z") || strings.Contains(frame, "p:a\n") {
+		t.Errorf("frame not scrolled to the cursor:\n%s", frame)
+	}
+	if lines := strings.Count(frame, "\n") + 1; lines > 12 {
+		t.Errorf("frame has %d lines, want at most 12", lines)
+	}
+}
+
+func TestTruncateVisible(t *testing.T) {
+	// Arrange
+	s := "\033[32mhello\033[0m world"
+
+	// Act
+	got := truncateVisible(s, 3)
+
+	// Assert
+	if want := "\033[32mhel\033[0m"; got != want {
+		t.Errorf("truncateVisible() = %q, want %q", got, want)
+	}
+}
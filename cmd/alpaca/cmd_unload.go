@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/protocol"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
-type UnloadCmd struct{}
+type UnloadCmd struct {
+	Force bool `help:"Kill llama-server immediately instead of waiting for it to exit"`
+}
 
 func (c *UnloadCmd) Run() error {
 	cl, err := newClient()
@@ -14,15 +19,27 @@ func (c *UnloadCmd) Run() error {
 		return err
 	}
 
-	resp, err := cl.Unload()
+	resp, err := cl.Unload(context.Background(), client.UnloadOptions{Force: c.Force})
 	if err != nil {
-		return errDaemonNotRunning()
+		return clientError(err)
 	}
 
 	if resp.Status == "error" {
 		return fmt.Errorf("%s", resp.Error)
 	}
 
-	ui.PrintSuccess("Model stopped")
+	// Daemons predating the graceful field always stopped gracefully.
+	data := protocol.UnloadData{Graceful: true}
+	if err := resp.Decode(&data); err != nil {
+		return err
+	}
+	switch {
+	case data.Graceful:
+		ui.PrintSuccess("Model stopped")
+	case c.Force:
+		ui.PrintSuccess("Model killed")
+	default:
+		ui.PrintWarning("llama-server did not exit in time and was killed")
+	}
 	return nil
 }
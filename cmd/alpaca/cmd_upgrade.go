@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/d2verb/alpaca/internal/config"
 	"github.com/d2verb/alpaca/internal/receipt"
 	"github.com/d2verb/alpaca/internal/selfupdate"
 	"github.com/d2verb/alpaca/internal/ui"
@@ -184,3 +187,67 @@ func (c *UpgradeCmd) handleFingerprintMismatch(r *receipt.Receipt) error {
 
 	return nil
 }
+
+// releaseSummaryLines is how many lines of release notes upgrade-check
+// prints.
+const releaseSummaryLines = 15
+
+type UpgradeCheckCmd struct{}
+
+func (c *UpgradeCheckCmd) Run() error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	release, hasUpdate, err := selfupdate.New(version).Check(context.Background(), paths.Cache, time.Now())
+	if err != nil {
+		return fmt.Errorf("check for updates: %w", err)
+	}
+	printReleaseCheck(release, hasUpdate)
+	return nil
+}
+
+// printReleaseCheck prints the running and latest versions and, when the
+// latest is newer, a summary of its release notes.
+func printReleaseCheck(release *selfupdate.Release, hasUpdate bool) {
+	fmt.Fprintf(ui.Output, "  Current: v%s\n", strings.TrimPrefix(version, "v"))
+	fmt.Fprintf(ui.Output, "  Latest:  %s\n", release.TagName)
+	fmt.Fprintln(ui.Output)
+
+	if !hasUpdate {
+		ui.PrintSuccess("Already up to date")
+		return
+	}
+
+	lines, more := selfupdate.Summary(release.Body, releaseSummaryLines)
+	if len(lines) > 0 {
+		fmt.Fprintf(ui.Output, "Changes in %s:\n", release.TagName)
+		for _, line := range lines {
+			fmt.Fprintf(ui.Output, "  %s\n", line)
+		}
+		if more > 0 {
+			fmt.Fprintf(ui.Output, "  ... and %d more\n", more)
+		}
+		fmt.Fprintln(ui.Output)
+	}
+	if release.HTMLURL != "" {
+		ui.PrintKeyValue("Release notes", release.HTMLURL)
+	}
+	ui.PrintInfo("Update available. Run: alpaca upgrade")
+}
+
+// printUpdateNotice mentions a newer alpaca release, at most once a day,
+// unless update-check is off in the config.
+func printUpdateNotice(paths *config.Paths) {
+	cfg, err := config.Load(paths.Config)
+	if err != nil || !valueOr(cfg.UpdateCheck, true) {
+		return
+	}
+	latest := selfupdate.New(version).Notice(context.Background(), paths.Cache, time.Now())
+	if latest == "" {
+		return
+	}
+	fmt.Fprintln(ui.Output)
+	ui.PrintInfo(fmt.Sprintf("alpaca %s is available (you have v%s). Run: alpaca upgrade-check", latest, strings.TrimPrefix(version, "v")))
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/d2verb/alpaca/internal/receipt"
+	"github.com/d2verb/alpaca/internal/selfupdate"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
@@ -154,3 +155,54 @@ func TestUpgradeCmd_HandleUnknownInstall_WithoutForce(t *testing.T) {
 		t.Error("expected --force guidance")
 	}
 }
+
+func TestPrintReleaseCheck(t *testing.T) {
+	release := &selfupdate.Release{
+		TagName: "v1.1.0",
+		Body:    "## Features\n- Add upgrade-check\n",
+		HTMLURL: "https://github.com/d2verb/alpaca/releases/tag/v1.1.0",
+	}
+
+	tests := []struct {
+		name      string
+		hasUpdate bool
+		want      []string
+		notWant   []string
+	}{
+		{
+			name:      "update available",
+			hasUpdate: true,
+			want:      []string{"Latest:  v1.1.0", "Changes in v1.1.0:", "- Add upgrade-check", release.HTMLURL, "Run: alpaca upgrade"},
+		},
+		{
+			name:    "up to date",
+			want:    []string{"Already up to date"},
+			notWant: []string{"Changes in", "Run: alpaca upgrade"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			ui.Output = &buf
+			defer func() { ui.Output = os.Stdout }()
+
+			// Act
+			printReleaseCheck(release, tt.hasUpdate)
+
+			// Assert
+			output := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(output, s) {
+					t.Errorf("output missing %q:\n%s", s, output)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(output, s) {
+					t.Errorf("output contains %q:\n%s", s, output)
+				}
+			}
+		})
+	}
+}
@@ -22,6 +22,12 @@ func newRmIdentifierPredictor() complete.Predictor {
 	return newIdentifierPredictor([]string{"p:", "h:"})
 }
 
+// newModelIdentifierPredictor returns a predictor for 'model show'.
+// Supports: h:org/repo:quant
+func newModelIdentifierPredictor() complete.Predictor {
+	return newIdentifierPredictor([]string{"h:"})
+}
+
 // newLoadIdentifierPredictor returns a predictor for 'load' command.
 // Supports: p:preset-name, h:org/repo:quant, f:/path/to/file
 func newLoadIdentifierPredictor() complete.Predictor {
@@ -34,6 +40,12 @@ func newEditIdentifierPredictor() complete.Predictor {
 	return newIdentifierPredictor([]string{"p:", "f:"})
 }
 
+// newPresetIdentifierPredictor returns a predictor for 'preset rename'.
+// Supports: p:preset-name
+func newPresetIdentifierPredictor() complete.Predictor {
+	return newIdentifierPredictor([]string{"p:"})
+}
+
 // identifierPredictor implements complete.Predictor for identifier completion.
 type identifierPredictor struct {
 	validPrefixes []string
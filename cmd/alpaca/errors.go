@@ -1,6 +1,15 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
 
 // Exit codes for CLI commands.
 const (
@@ -37,6 +46,38 @@ func errDaemonNotRunning() *ExitError {
 	}
 }
 
+// errVersionMismatch reports a daemon that speaks another protocol version.
+// A local daemon is fixed by restarting it with the installed binary.
+func errVersionMismatch(mismatch *client.VersionMismatchError) *ExitError {
+	hint := "\nRun: alpaca stop && alpaca start"
+	if daemonHost != "" {
+		hint = "\nUpgrade alpaca on this machine or on the remote host so both match."
+	}
+	return &ExitError{
+		Code:    exitError,
+		Kind:    ExitKindError,
+		Message: "Daemon version mismatch: " + mismatch.Error() + "." + hint,
+	}
+}
+
+// clientError maps a daemon request failure to a user-facing error.
+// Authentication failures are reported as-is, version mismatches and
+// timeouts as such; anything else means the daemon could not be reached.
+func clientError(err error) error {
+	var mismatch *client.VersionMismatchError
+	switch {
+	case errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrPeerRejected):
+		return err
+	case errors.As(err, &mismatch):
+		return errVersionMismatch(mismatch)
+	case errors.Is(err, context.DeadlineExceeded):
+		return errors.New("daemon did not respond in time (raise --request-timeout)")
+	case errors.Is(err, context.Canceled):
+		return errors.New("canceled")
+	}
+	return errDaemonNotRunning()
+}
+
 func errPresetNotFound(name string) *ExitError {
 	return &ExitError{
 		Code:    exitPresetNotFound,
@@ -61,6 +102,40 @@ func errDownloadFailed() *ExitError {
 	}
 }
 
+// downloadAdvice suggests what to do about a failed download, or returns ""
+// when err is not a classified download error.
+func downloadAdvice(err error) string {
+	var dlErr *pull.DownloadError
+	if !errors.As(err, &dlErr) {
+		return ""
+	}
+	switch dlErr.Kind {
+	case pull.ErrorNetwork:
+		return "The connection failed. Check your network and run the command again; the download resumes where it stopped."
+	case pull.ErrorServer:
+		return "The download server is having trouble. Try again later, or add a mirror under hf-mirrors in ~/.alpaca/config.yaml."
+	case pull.ErrorRequest:
+		switch dlErr.Status {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return "Access was denied. The repository may be gated or private."
+		case http.StatusNotFound:
+			return "The file is no longer in the repository. Run: alpaca model quants <org/repo>"
+		}
+		return "The download server rejected the request."
+	case pull.ErrorIntegrity:
+		return "The downloaded file did not match its published SHA256 and was deleted. Run the command again; if it keeps failing, a proxy may be altering the file."
+	}
+	return ""
+}
+
+// printDownloadError prints a failed download with advice for its kind.
+func printDownloadError(prefix string, err error) {
+	ui.PrintError(prefix + err.Error())
+	if advice := downloadAdvice(err); advice != "" {
+		ui.PrintInfo(advice)
+	}
+}
+
 func errServerNotRunning() *ExitError {
 	return &ExitError{
 		Code:    exitError,
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/pull"
 )
 
 func TestExitErrorImplementsError(t *testing.T) {
@@ -83,3 +89,73 @@ func TestErrServerNotRunning(t *testing.T) {
 		t.Error("Message should not be empty")
 	}
 }
+
+func TestClientError(t *testing.T) {
+	t.Run("unauthorized is passed through", func(t *testing.T) {
+		err := clientError(fmt.Errorf("send: %w", client.ErrUnauthorized))
+
+		if !errors.Is(err, client.ErrUnauthorized) {
+			t.Errorf("clientError() = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("rejected peer is passed through", func(t *testing.T) {
+		err := clientError(fmt.Errorf("send: %w", client.ErrPeerRejected))
+
+		if !errors.Is(err, client.ErrPeerRejected) {
+			t.Errorf("clientError() = %v, want ErrPeerRejected", err)
+		}
+	})
+
+	t.Run("version mismatch asks for a restart", func(t *testing.T) {
+		err := clientError(fmt.Errorf("send: %w", &client.VersionMismatchError{Client: 2, Daemon: 1}))
+
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || !strings.Contains(exitErr.Message, "older alpaca") || !strings.Contains(exitErr.Message, "alpaca stop && alpaca start") {
+			t.Errorf("clientError() = %v, want version mismatch with restart hint", err)
+		}
+	})
+
+	t.Run("timeout is not reported as daemon not running", func(t *testing.T) {
+		err := clientError(context.DeadlineExceeded)
+
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) || !strings.Contains(err.Error(), "--request-timeout") {
+			t.Errorf("clientError() = %v, want timeout hint", err)
+		}
+	})
+
+	t.Run("other errors mean daemon not running", func(t *testing.T) {
+		err := clientError(errors.New("connect to daemon: no such file"))
+
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != exitDaemonNotRunning {
+			t.Errorf("clientError() = %v, want daemon not running exit error", err)
+		}
+	})
+}
+
+func TestDownloadAdvice(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string // substring; empty for no advice
+	}{
+		{"network", &pull.DownloadError{Kind: pull.ErrorNetwork, Err: errors.New("read response: unexpected EOF")}, "resumes where it stopped"},
+		{"server", &pull.DownloadError{Kind: pull.ErrorServer, Status: 503, Err: errors.New("status 503")}, "hf-mirrors"},
+		{"forbidden", &pull.DownloadError{Kind: pull.ErrorRequest, Status: 403, Err: errors.New("status 403")}, "gated or private"},
+		{"not found", &pull.DownloadError{Kind: pull.ErrorRequest, Status: 404, Err: errors.New("status 404")}, "alpaca model quants"},
+		{"integrity", fmt.Errorf("pull: %w", &pull.DownloadError{Kind: pull.ErrorIntegrity, Err: errors.New("mismatch")}), "SHA256"},
+		{"unclassified", errors.New("repository not found: org/repo"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := downloadAdvice(tt.err)
+
+			if tt.want == "" && got != "" || !strings.Contains(got, tt.want) {
+				t.Errorf("downloadAdvice() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,25 +1,31 @@
 package main
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/d2verb/alpaca/internal/client"
 	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/model"
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/pull"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
 // resolveLocalPreset resolves an identifier string from input or defaults to .alpaca.yaml.
-// If id is non-empty, it is returned as-is. Otherwise, the current directory is checked
-// for a local preset file and its path is returned as an f: identifier.
+// If id is non-empty (and not "."), it is returned as-is. Otherwise, the nearest
+// local preset file in the current directory or its parents is returned as an
+// f: identifier.
 func resolveLocalPreset(id string) (string, error) {
-	if id != "" {
+	if id != "" && id != "." {
 		return id, nil
 	}
 
@@ -28,17 +34,35 @@ func resolveLocalPreset(id string) (string, error) {
 		return "", fmt.Errorf("get working directory: %w", err)
 	}
 
-	presetPath := filepath.Join(cwd, LocalPresetFile)
-	if _, err := os.Stat(presetPath); err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("no %s found in current directory\nRun: alpaca new --local", LocalPresetFile)
-		}
-		return "", fmt.Errorf("check preset file: %w", err)
+	presetPath, err := findLocalPreset(cwd)
+	if err != nil {
+		return "", err
+	}
+	if presetPath == "" {
+		return "", fmt.Errorf("no %s found in current directory or its parents\nRun: alpaca new --local", LocalPresetFile)
 	}
-
 	return "f:" + presetPath, nil
 }
 
+// findLocalPreset walks up from dir to the filesystem root and returns the
+// path of the first local preset file found, or "" if there is none.
+func findLocalPreset(dir string) (string, error) {
+	for {
+		presetPath := filepath.Join(dir, LocalPresetFile)
+		if _, err := os.Stat(presetPath); err == nil {
+			return presetPath, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("check preset file: %w", err)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
 // mapPresetError converts preset package errors to user-friendly errors.
 func mapPresetError(err error, name string) error {
 	if preset.IsNotFound(err) {
@@ -47,9 +71,6 @@ func mapPresetError(err error, name string) error {
 	return err
 }
 
-// stdin is the input source for prompts. Can be replaced for testing.
-var stdin = bufio.NewReader(os.Stdin)
-
 // promptLine prompts the user for input and returns the trimmed response.
 // If defaultVal is provided, it's shown in brackets and returned if input is empty.
 func promptLine(label, defaultVal string) (string, error) {
@@ -58,7 +79,7 @@ func promptLine(label, defaultVal string) (string, error) {
 	} else {
 		fmt.Fprintf(ui.Output, "%s: ", label)
 	}
-	input, err := stdin.ReadString('\n')
+	input, err := ui.Input.ReadString('\n')
 	if err != nil {
 		return "", err
 	}
@@ -69,36 +90,146 @@ func promptLine(label, defaultVal string) (string, error) {
 	return input, nil
 }
 
+// assumeYes is set by --yes: confirmation prompts are answered yes
+// without asking.
+var assumeYes bool
+
+// stdinIsTerminal reports whether stdin is attached to a terminal.
+// Can be replaced for testing.
+var stdinIsTerminal = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
 // promptConfirm prompts the user for a yes/no confirmation.
-// Returns true only if user enters "y" or "Y".
-func promptConfirm(message string) bool {
-	ui.PrintConfirm(message)
-	input, err := stdin.ReadString('\n')
-	if err != nil {
-		return false
+// Returns true only if user enters "y" or "Y", or --yes is given. Without
+// a terminal to ask on, it fails instead of waiting for an answer that a
+// script will never give.
+func promptConfirm(message string) (bool, error) {
+	if assumeYes {
+		return true, nil
 	}
-	input = strings.TrimSpace(input)
-	return input == "y" || input == "Y"
+	if !stdinIsTerminal() {
+		return false, fmt.Errorf("cannot confirm %q: stdin is not a terminal\nUse --yes to confirm without a prompt", message)
+	}
+	return ui.Confirm(message), nil
 }
 
+// promptChoice prints message and numbered options and returns the option
+// the user picks by number. The first option is the default.
+func promptChoice(message string, options []string) (string, error) {
+	ui.PrintWarning(message)
+	for i, opt := range options {
+		fmt.Fprintf(ui.Output, "  %d) %s\n", i+1, opt)
+	}
+	for {
+		answer, err := promptLine(fmt.Sprintf("Choose 1-%d", len(options)), "1")
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		ui.PrintError(fmt.Sprintf("'%s' is not a number from 1 to %d", answer, len(options)))
+	}
+}
+
+// getPaths returns the paths of the active profile, with the socket and
+// models directories moved by config.yaml. Only those settings are read
+// here; commands load the rest of config.yaml where they need it, so a
+// mistake in it does not break unrelated commands. A config.yaml that does
+// not parse at all leaves the default paths, with a warning.
 func getPaths() (*config.Paths, error) {
 	paths, err := config.GetPaths()
 	if err != nil {
 		return nil, fmt.Errorf("get paths: %w", err)
 	}
+	cfg, err := config.LoadPathSettings(paths.Config)
+	if err != nil {
+		pathSettingsWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: %v; using the default socket and models paths\n", err)
+		})
+		return paths, nil
+	}
+	if err := paths.ApplyConfig(cfg); err != nil {
+		return nil, err
+	}
 	return paths, nil
 }
 
+// pathSettingsWarning prints getPaths' warning once per command.
+var pathSettingsWarning sync.Once
+
+// daemonHost is the remote daemon address from --host / ALPACA_HOST.
+// Empty means the local Unix socket.
+var daemonHost string
+
+// daemonTimeouts are the client timeouts from --request-timeout and
+// --connect-timeout. Zero fields keep the client defaults.
+var daemonTimeouts client.Timeouts
+
+// newClient returns a client for the local daemon, or for the remote daemon
+// when daemonHost is set.
 func newClient() (*client.Client, error) {
+	if daemonHost != "" {
+		token := os.Getenv("ALPACA_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("ALPACA_TOKEN is required to connect to remote daemon %s", daemonHost)
+		}
+		cl := client.NewRemote(daemonHost, token)
+		caFile, certFile, keyFile := os.Getenv("ALPACA_TLS_CA"), os.Getenv("ALPACA_TLS_CERT"), os.Getenv("ALPACA_TLS_KEY")
+		if caFile != "" || certFile != "" || keyFile != "" {
+			tlsConfig, err := client.TLSConfig(caFile, certFile, keyFile)
+			if err != nil {
+				return nil, err
+			}
+			cl.SetTLSConfig(tlsConfig)
+		}
+		cl.SetTimeouts(daemonTimeouts)
+		return cl, nil
+	}
 	paths, err := getPaths()
 	if err != nil {
 		return nil, err
 	}
-	return client.New(paths.Socket), nil
+	cl := client.New(paths.Socket)
+	cl.SetTimeouts(daemonTimeouts)
+	return cl, nil
+}
+
+// newPuller creates a puller using the configured HuggingFace endpoint and
+// mirrors. endpoint overrides HF_ENDPOINT and the config file's hf-endpoint.
+func newPuller(paths *config.Paths, modelsDir, endpoint string) (*pull.Puller, error) {
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return nil, err
+	}
+	return pull.NewFromConfig(modelsDir, cfg, endpoint)
+}
+
+// newRegistryPuller creates a puller for a model of registry. endpoint and
+// the configured endpoints are HuggingFace's; other registries are reached
+// at their own.
+func newRegistryPuller(paths *config.Paths, modelsDir, endpoint string, registry pull.Registry) (*pull.Puller, error) {
+	if registry == pull.HuggingFace {
+		return newPuller(paths, modelsDir, endpoint)
+	}
+	if endpoint != "" {
+		return nil, fmt.Errorf("--endpoint only applies to HuggingFace models, not %s", registry.Name())
+	}
+	puller, err := newPuller(paths, modelsDir, "")
+	if err != nil {
+		return nil, err
+	}
+	puller.SetRegistry(registry)
+	return puller, nil
 }
 
-// pullModel downloads a model from HuggingFace.
-func pullModel(repo, quant, modelsDir string) error {
+// pullModel downloads a model from registry.
+// endpoint optionally overrides the configured HuggingFace endpoint. With
+// verify, files already downloaded are hashed in full to check they are up
+// to date.
+func pullModel(registry pull.Registry, repo, quant, modelsDir, endpoint string, verify bool) error {
 	paths, err := getPaths()
 	if err != nil {
 		return err
@@ -108,13 +239,24 @@ func pullModel(repo, quant, modelsDir string) error {
 		return fmt.Errorf("create directories: %w", err)
 	}
 
-	puller := pull.NewPuller(modelsDir)
+	puller, err := newRegistryPuller(paths, modelsDir, endpoint, registry)
+	if err != nil {
+		return err
+	}
+	puller.SetVerify(verify)
 
 	// Get file info first
 	ui.PrintInfo("Fetching file list...")
+	terms, termsErr := puller.GetRepoTerms(context.Background(), repo)
 	info, err := puller.GetFileInfo(context.Background(), repo, quant)
 	if err != nil {
-		return err
+		return gatedError(registry, repo, terms, err)
+	}
+	if quant == pull.AutoQuant {
+		ui.PrintInfo(fmt.Sprintf("Selected quant %s for available memory", info.Quant))
+	}
+	if info.Shards > 0 {
+		ui.PrintInfo(fmt.Sprintf("Model is split into %d files (%s total)", info.Shards, formatSize(info.Size)))
 	}
 
 	// Show download plan when multiple files
@@ -128,10 +270,13 @@ func pullModel(repo, quant, modelsDir string) error {
 		fmt.Fprintf(ui.Output, "  Mmproj: %-*s  (%s)\n", nameWidth, info.MmprojOriginalFilename, formatSize(info.MmprojSize))
 	}
 
+	downloaded, _ := model.NewManager(modelsDir).Exists(context.Background(), repo, info.Quant)
+	if err := confirmRepoTerms(registry, repo, terms, termsErr, downloaded); err != nil {
+		return err
+	}
+
 	// Set up progress reporting
-	puller.SetProgressFunc(func(downloaded, total int64) {
-		printProgress(downloaded, total)
-	})
+	puller.SetReportFunc(printProgress)
 
 	// Set up file lifecycle callbacks
 	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
@@ -142,14 +287,14 @@ func pullModel(repo, quant, modelsDir string) error {
 		}
 	})
 	puller.SetFileSavedFunc(func(savedPath string) {
-		fmt.Fprintln(ui.Output) // End progress bar line
+		endProgressLine()
 		ui.PrintSuccess(fmt.Sprintf("Saved to: %s", savedPath))
 	})
 
 	// Download
 	result, err := puller.Pull(context.Background(), repo, quant)
 	if err != nil {
-		fmt.Fprintln(ui.Output) // End progress bar line
+		endProgressLine()
 		return err
 	}
 
@@ -158,16 +303,179 @@ func pullModel(repo, quant, modelsDir string) error {
 		return nil
 	}
 
-	// Report mmproj failure
 	if result.MmprojFailed {
-		fmt.Fprintln(ui.Output) // End progress bar line
-		ui.PrintWarning(fmt.Sprintf("mmproj download failed - vision unavailable. Run 'alpaca pull h:%s:%s' to retry.", repo, quant))
-		return errDownloadFailed()
+		endProgressLine()
+		return &mmprojFailedError{model: identifier.Model(registry.Scheme(), repo, quant)}
 	}
 
 	return nil
 }
 
+// mmprojFailedError is returned by pullModel when the model was downloaded
+// but its mmproj was not.
+type mmprojFailedError struct {
+	model string
+}
+
+func (e *mmprojFailedError) Error() string {
+	return fmt.Sprintf("mmproj download failed - vision unavailable. Run 'alpaca pull %s' to retry.", e.model)
+}
+
+// errDownloadDeclined is returned when the user declines to download a
+// repository whose terms restrict its use.
+var errDownloadDeclined = errors.New("download declined")
+
+// confirmRepoTerms shows the license of repo before its files are
+// downloaded. A gated repository or a license that forbids commercial use
+// must be confirmed, unless --yes is given or the files were downloaded
+// before (and confirmed then). Terms that cannot be fetched only warn: the
+// download does not depend on them.
+func confirmRepoTerms(registry pull.Registry, repo string, terms *pull.RepoTerms, termsErr error, downloaded bool) error {
+	if termsErr != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not fetch the license of %s: %v", repo, termsErr))
+		return nil
+	}
+	if terms == nil {
+		return nil
+	}
+	if terms.License == "" && terms.LicenseName == "" {
+		ui.PrintInfo(fmt.Sprintf("%s does not specify a license", repo))
+	} else {
+		ui.PrintInfo(fmt.Sprintf("%s is licensed under %s", repo, terms.DisplayLicense()))
+	}
+
+	var restrictions []string
+	if terms.Gated {
+		restrictions = append(restrictions, fmt.Sprintf("%s is gated: its terms must be accepted at %s", repo, registry.RepoURL(repo)))
+	}
+	if terms.NonCommercial() {
+		restrictions = append(restrictions, fmt.Sprintf("License %s does not allow commercial use", terms.DisplayLicense()))
+	}
+	if len(restrictions) == 0 || downloaded {
+		return nil
+	}
+	for _, r := range restrictions {
+		ui.PrintWarning(r)
+	}
+	ok, err := promptConfirm("Download anyway?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errDownloadDeclined
+	}
+	return nil
+}
+
+// gatedError adds to an error fetching the files of a gated repository
+// where access to it is requested.
+func gatedError(registry pull.Registry, repo string, terms *pull.RepoTerms, err error) error {
+	if terms == nil || !terms.Gated {
+		return err
+	}
+	return fmt.Errorf("%w\n%s is gated; request access at %s", err, repo, registry.RepoURL(repo))
+}
+
+// pullLora downloads a LoRA adapter file from registry.
+// endpoint and verify are as for pullModel.
+func pullLora(registry pull.Registry, repo, file, modelsDir, endpoint string, verify bool) error {
+	paths, err := getPaths()
+	if err != nil {
+		return err
+	}
+
+	if err := paths.EnsureDirectories(); err != nil {
+		return fmt.Errorf("create directories: %w", err)
+	}
+
+	puller, err := newRegistryPuller(paths, modelsDir, endpoint, registry)
+	if err != nil {
+		return err
+	}
+	puller.SetVerify(verify)
+
+	terms, termsErr := puller.GetRepoTerms(context.Background(), repo)
+	downloaded, _ := model.NewManager(modelsDir).LoraExists(context.Background(), repo, file)
+	if err := confirmRepoTerms(registry, repo, terms, termsErr, downloaded); err != nil {
+		return err
+	}
+
+	puller.SetReportFunc(printProgress)
+	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
+		ui.PrintInfo(fmt.Sprintf("Downloading LoRA adapter %s (%s)...", filename, formatSize(size)))
+	})
+	puller.SetFileSavedFunc(func(savedPath string) {
+		endProgressLine()
+		ui.PrintSuccess(fmt.Sprintf("Saved to: %s", savedPath))
+	})
+
+	result, err := puller.PullLora(context.Background(), repo, file)
+	if err != nil {
+		endProgressLine()
+		return err
+	}
+
+	if result.AlreadyUpToDate {
+		ui.PrintSuccess("LoRA adapter is already up to date.")
+	}
+	return nil
+}
+
+// pullFiles downloads the repository files matching patterns next to a
+// downloaded model.
+func pullFiles(paths *config.Paths, registry pull.Registry, repo, quant, endpoint string, patterns []string) error {
+	puller, err := newRegistryPuller(paths, paths.Models, endpoint, registry)
+	if err != nil {
+		return err
+	}
+	puller.SetReportFunc(printProgress)
+	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
+		ui.PrintInfo(fmt.Sprintf("[%d/%d] Downloading %s (%s)...", index, total, filename, formatSize(size)))
+	})
+	puller.SetFileSavedFunc(func(savedPath string) {
+		endProgressLine()
+		ui.PrintSuccess(fmt.Sprintf("Saved to: %s", savedPath))
+	})
+
+	files, err := puller.PullFiles(context.Background(), repo, quant, patterns)
+	if err != nil {
+		endProgressLine()
+		return err
+	}
+	for _, f := range files {
+		if f.AlreadyUpToDate {
+			ui.PrintSuccess(fmt.Sprintf("%s is already up to date.", f.File))
+		}
+	}
+	return nil
+}
+
+// pullMmproj downloads only the mmproj of a downloaded model.
+func pullMmproj(paths *config.Paths, repo, quant string) error {
+	puller, err := newPuller(paths, paths.Models, "")
+	if err != nil {
+		return err
+	}
+	puller.SetReportFunc(printProgress)
+	puller.SetFileStartFunc(func(filename string, size int64, index, total int) {
+		ui.PrintInfo(fmt.Sprintf("Downloading mmproj %s (%s)...", filename, formatSize(size)))
+	})
+	puller.SetFileSavedFunc(func(savedPath string) {
+		endProgressLine()
+		ui.PrintSuccess(fmt.Sprintf("Saved to: %s", savedPath))
+	})
+
+	result, err := puller.PullMmproj(context.Background(), repo, quant)
+	if err != nil {
+		endProgressLine()
+		return fmt.Errorf("download mmproj: %w", err)
+	}
+	if result.MmprojFilename == "" {
+		ui.PrintInfo(fmt.Sprintf("h:%s:%s no longer has an mmproj upstream.", repo, quant))
+	}
+	return nil
+}
+
 func formatSize(bytes int64) string {
 	const (
 		KB = 1024
@@ -186,16 +494,56 @@ func formatSize(bytes int64) string {
 	}
 }
 
-func printProgress(downloaded, total int64) {
-	if total <= 0 {
-		fmt.Printf("\r%s downloaded", formatSize(downloaded))
-		return
+// formatParameters formats a parameter count as models are named, e.g.
+// "8.2B" or "494M".
+func formatParameters(n int64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.1fB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.0fM", float64(n)/1e6)
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
+
+// formatContextLength formats a context length in tokens, using K for
+// multiples of 1024 (e.g. 131072 is "128K").
+func formatContextLength(n int) string {
+	if n >= 1024 && n%1024 == 0 {
+		return fmt.Sprintf("%dK", n/1024)
 	}
+	return strconv.Itoa(n)
+}
+
+// formatModelInfo summarizes what a model's GGUF metadata says about it,
+// e.g. "qwen3 · 8.2B params · 40K ctx". It is empty when nothing is known.
+func formatModelInfo(e *metadata.ModelEntry) string {
+	var parts []string
+	if e.Architecture != "" {
+		parts = append(parts, e.Architecture)
+	}
+	if e.Parameters > 0 {
+		parts = append(parts, formatParameters(e.Parameters)+" params")
+	}
+	if e.ContextLength > 0 {
+		parts = append(parts, formatContextLength(e.ContextLength)+" ctx")
+	}
+	return strings.Join(parts, " · ")
+}
 
-	percent := float64(downloaded) / float64(total) * 100
-	barWidth := 40
-	filled := int(percent / 100 * float64(barWidth))
+// printProgress redraws the progress bar line of a download. Plain output
+// has no progress bar, since it cannot be redrawn in place.
+func printProgress(pr pull.Progress) {
+	if ui.Plain() {
+		return
+	}
+	fmt.Printf("\r\033[K%s", formatTransfer(pr, 40))
+}
 
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
-	fmt.Printf("\r[%s] %.1f%% (%s / %s)", bar, percent, formatSize(downloaded), formatSize(total))
+// endProgressLine ends the line printProgress redraws.
+func endProgressLine() {
+	if !ui.Plain() {
+		fmt.Fprintln(ui.Output)
+	}
 }
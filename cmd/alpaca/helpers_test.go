@@ -1,6 +1,18 @@
 package main
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
 
 func TestFormatSize(t *testing.T) {
 	tests := []struct {
@@ -28,3 +40,274 @@ func TestFormatSize(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatModelInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry metadata.ModelEntry
+		want  string
+	}{
+		{"unknown", metadata.ModelEntry{}, ""},
+		{"all", metadata.ModelEntry{Architecture: "qwen3", Parameters: 8_190_735_360, ContextLength: 40960}, "qwen3 · 8.2B params · 40K ctx"},
+		{"small model", metadata.ModelEntry{Architecture: "qwen2", Parameters: 494_032_768, ContextLength: 32768}, "qwen2 · 494M params · 32K ctx"},
+		{"odd context", metadata.ModelEntry{Architecture: "phi2", ContextLength: 2000}, "phi2 · 2000 ctx"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatModelInfo(&tt.entry); got != tt.want {
+				t.Errorf("formatModelInfo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClient_RemoteRequiresToken(t *testing.T) {
+	// Arrange
+	daemonHost = "gpu-box:7070"
+	defer func() { daemonHost = "" }()
+	t.Setenv("ALPACA_TOKEN", "")
+
+	// Act
+	_, err := newClient()
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "ALPACA_TOKEN is required") {
+		t.Fatalf("newClient() error = %v, want missing token error", err)
+	}
+}
+
+func TestGetPaths_IgnoresUnrelatedConfigErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantSocket string
+	}{
+		{
+			name:       "invalid unrelated setting",
+			content:    "socket-dir: /run/alpaca\ndownload-attempts: many\n",
+			wantSocket: "/run/alpaca/alpaca.sock",
+		},
+		{
+			name:       "unparseable file",
+			content:    "socket-dir: [\n",
+			wantSocket: filepath.Join("HOME", ".alpaca", "alpaca.sock"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("ALPACA_PROFILE", "")
+			if err := os.MkdirAll(filepath.Join(home, ".alpaca"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(home, ".alpaca", "config.yaml"), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			paths, err := getPaths()
+
+			// Assert
+			if err != nil {
+				t.Fatalf("getPaths() error = %v", err)
+			}
+			want := strings.Replace(tt.wantSocket, "HOME", home, 1)
+			if paths.Socket != want {
+				t.Errorf("Socket = %q, want %q", paths.Socket, want)
+			}
+		})
+	}
+}
+
+func TestNewPuller_Endpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		env      string
+		endpoint string
+		wantErr  string
+	}{
+		{name: "defaults", config: ""},
+		{name: "config endpoint and mirrors", config: "hf-endpoint: https://hf-mirror.com\nhf-mirrors: [\"https://mirror.example\"]\n"},
+		{name: "invalid env endpoint", env: "hf-mirror.com", wantErr: "invalid endpoint"},
+		{name: "flag overrides invalid env", env: "hf-mirror.com", endpoint: "https://hf-mirror.com"},
+		{name: "invalid mirror", config: "hf-mirrors: [\"ftp://mirror\"]\n", wantErr: "invalid endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			paths := &config.Paths{Config: filepath.Join(dir, "config.yaml")}
+			if tt.config != "" {
+				if err := os.WriteFile(paths.Config, []byte(tt.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("HF_ENDPOINT", tt.env)
+
+			// Act
+			_, err := newPuller(paths, dir, tt.endpoint)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newPuller() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newPuller() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestPromptConfirm(t *testing.T) {
+	tests := []struct {
+		name     string
+		yes      bool
+		terminal bool
+		input    string
+		want     bool
+		wantErr  bool
+	}{
+		{"yes flag skips prompt", true, false, "", true, false},
+		{"terminal answers y", false, true, "y\n", true, false},
+		{"terminal answers default", false, true, "\n", false, false},
+		{"no terminal fails", false, false, "y\n", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			ui.Output = &buf
+			t.Cleanup(func() { ui.Output = os.Stdout })
+			setStdinInput(t, tt.input)
+			origYes, origTerminal := assumeYes, stdinIsTerminal
+			t.Cleanup(func() { assumeYes, stdinIsTerminal = origYes, origTerminal })
+			assumeYes = tt.yes
+			stdinIsTerminal = func() bool { return tt.terminal }
+
+			// Act
+			got, err := promptConfirm("Delete preset 'coder'?")
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("promptConfirm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("promptConfirm() = %v, want %v", got, tt.want)
+			}
+			if prompted := strings.Contains(buf.String(), "(y/N)"); prompted != tt.terminal {
+				t.Errorf("prompt shown = %v, want %v", prompted, tt.terminal)
+			}
+		})
+	}
+}
+
+func TestPromptChoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"picks by number", "2\n", "p:chat-long", false},
+		{"default is first", "\n", "p:chat", false},
+		{"asks again after invalid answer", "9\n2\n", "p:chat-long", false},
+		{"no answer", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			ui.Output = &buf
+			t.Cleanup(func() { ui.Output = os.Stdout })
+			setStdinInput(t, tt.input)
+
+			// Act
+			got, err := promptChoice("'chat' matches several presets and models:", []string{"p:chat", "p:chat-long"})
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("promptChoice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("promptChoice() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(buf.String(), "  2) p:chat-long\n") {
+				t.Errorf("options not listed:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestConfirmRepoTerms(t *testing.T) {
+	tests := []struct {
+		name       string
+		terms      *pull.RepoTerms
+		downloaded bool
+		input      string
+		wantErr    error
+		wantPrompt bool
+	}{
+		{"permissive license", &pull.RepoTerms{License: "apache-2.0"}, false, "", nil, false},
+		{"gated accepted", &pull.RepoTerms{License: "llama3.1", Gated: true}, false, "y\n", nil, true},
+		{"non-commercial declined", &pull.RepoTerms{License: "cc-by-nc-4.0"}, false, "\n", errDownloadDeclined, true},
+		{"downloaded before", &pull.RepoTerms{License: "cc-by-nc-4.0", Gated: true}, true, "", nil, false},
+		{"terms not published", nil, false, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			ui.Output = &buf
+			t.Cleanup(func() { ui.Output = os.Stdout })
+			setStdinInput(t, tt.input)
+			origYes, origTerminal := assumeYes, stdinIsTerminal
+			t.Cleanup(func() { assumeYes, stdinIsTerminal = origYes, origTerminal })
+			assumeYes = false
+			stdinIsTerminal = func() bool { return true }
+
+			// Act
+			err := confirmRepoTerms(pull.HuggingFace, "org/model", tt.terms, nil, tt.downloaded)
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("confirmRepoTerms() error = %v, want %v", err, tt.wantErr)
+			}
+			if prompted := strings.Contains(buf.String(), "(y/N)"); prompted != tt.wantPrompt {
+				t.Errorf("prompt shown = %v, want %v\noutput: %s", prompted, tt.wantPrompt, buf.String())
+			}
+			if tt.terms != nil && !strings.Contains(buf.String(), "org/model is licensed under "+tt.terms.License) {
+				t.Errorf("output = %q, want the license", buf.String())
+			}
+		})
+	}
+}
+
+func TestConfirmRepoTerms_FetchFailureWarns(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	ui.Output = &buf
+	t.Cleanup(func() { ui.Output = os.Stdout })
+
+	// Act
+	err := confirmRepoTerms(pull.HuggingFace, "org/model", nil, errors.New("status 500"), false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("confirmRepoTerms() error = %v, want nil", err)
+	}
+	if !strings.Contains(buf.String(), "Could not fetch the license of org/model") {
+		t.Errorf("output = %q, want a warning", buf.String())
+	}
+}
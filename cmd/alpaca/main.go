@@ -4,10 +4,13 @@ import (
 	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/willabides/kongplete"
 
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/config"
 	"github.com/d2verb/alpaca/internal/ui"
 )
 
@@ -17,21 +20,38 @@ var (
 )
 
 type CLI struct {
-	Start   StartCmd   `cmd:"" help:"Start the daemon"`
-	Stop    StopCmd    `cmd:"" help:"Stop the daemon"`
-	Status  StatusCmd  `cmd:"" help:"Show current status"`
-	Load    LoadCmd    `cmd:"" help:"Load a preset, model, or file"`
-	Unload  UnloadCmd  `cmd:"" help:"Stop the currently running model"`
-	Logs    LogsCmd    `cmd:"" help:"Show logs (daemon or server)"`
-	List    ListCmd    `cmd:"" name:"ls" help:"List presets and models"`
-	Show    ShowCmd    `cmd:"" help:"Show details of a preset or model"`
-	Remove  RemoveCmd  `cmd:"" name:"rm" help:"Remove a preset or model"`
-	Pull    PullCmd    `cmd:"" help:"Download a model"`
-	New     NewCmd     `cmd:"" help:"Create a new preset interactively"`
-	Edit    EditCmd    `cmd:"" help:"Edit a preset in your editor"`
-	Open    OpenCmd    `cmd:"" help:"Open llama-server in browser"`
-	Upgrade UpgradeCmd `cmd:"" help:"Upgrade alpaca to the latest version"`
-	Version VersionCmd `cmd:"" help:"Show version"`
+	Profile        string        `help:"Use the named profile: its own daemon, presets, config and logs under ~/.alpaca/profiles/NAME" env:"ALPACA_PROFILE" placeholder:"NAME"`
+	Host           string        `help:"Remote daemon address (host:port); requires ALPACA_TOKEN" env:"ALPACA_HOST" placeholder:"HOST:PORT"`
+	RequestTimeout time.Duration `help:"Limit for each daemon request, including load and unload (default: 30s for queries, none for load and unload)" env:"ALPACA_REQUEST_TIMEOUT" placeholder:"DURATION"`
+	ConnectTimeout time.Duration `help:"Limit for connecting to the daemon (default: 5s)" env:"ALPACA_CONNECT_TIMEOUT" placeholder:"DURATION"`
+	Yes            bool          `short:"y" help:"Answer yes to confirmation prompts (for scripts)"`
+	Plain          bool          `help:"Plain output: ASCII labels instead of emoji and symbols, no colors or redrawn lines (default when NO_COLOR is set or TERM=dumb)" env:"ALPACA_PLAIN"`
+
+	Start        StartCmd        `cmd:"" help:"Start the daemon"`
+	Stop         StopCmd         `cmd:"" help:"Stop the daemon"`
+	Status       StatusCmd       `cmd:"" help:"Show current status"`
+	Stats        StatsCmd        `cmd:"" help:"Show per-model request statistics"`
+	Ps           PsCmd           `cmd:"" help:"List running llama-server processes"`
+	Load         LoadCmd         `cmd:"" help:"Load a preset, model, or file"`
+	Unload       UnloadCmd       `cmd:"" help:"Stop the currently running model"`
+	Cancel       CancelCmd       `cmd:"" help:"Abort the model load in progress"`
+	Logs         LogsCmd         `cmd:"" help:"Show logs (daemon or server)"`
+	List         ListCmd         `cmd:"" name:"ls" help:"List presets and models"`
+	Show         ShowCmd         `cmd:"" help:"Show details of a preset or model"`
+	Remove       RemoveCmd       `cmd:"" name:"rm" help:"Remove a preset or model"`
+	Pull         PullCmd         `cmd:"" help:"Download a model"`
+	Model        ModelCmd        `cmd:"" help:"Manage models"`
+	Preset       PresetCmd       `cmd:"" help:"Manage presets"`
+	Schedule     ScheduleCmd     `cmd:"" help:"Load and unload models at set times"`
+	New          NewCmd          `cmd:"" help:"Create a new preset interactively"`
+	Edit         EditCmd         `cmd:"" help:"Edit a preset in your editor"`
+	Export       ExportCmd       `cmd:"" help:"Export config, presets and optionally models to an archive"`
+	Import       ImportCmd       `cmd:"" help:"Merge an archive written by export into this machine"`
+	Open         OpenCmd         `cmd:"" help:"Open llama-server in browser"`
+	UI           UICmd           `cmd:"" name:"ui" help:"Browse presets and models and control the daemon in an interactive terminal UI"`
+	Upgrade      UpgradeCmd      `cmd:"" help:"Upgrade alpaca to the latest version"`
+	UpgradeCheck UpgradeCheckCmd `cmd:"" name:"upgrade-check" help:"Check for a newer alpaca release and show its changes"`
+	Version      VersionCmd      `cmd:"" help:"Show version"`
 
 	// Completion commands
 	CompletionScript kongplete.InstallCompletions `cmd:"" name:"completion-script" help:"Output shell completion script"`
@@ -57,6 +77,8 @@ func main() {
 		kongplete.WithPredictor("rm-identifier", newRmIdentifierPredictor()),
 		kongplete.WithPredictor("load-identifier", newLoadIdentifierPredictor()),
 		kongplete.WithPredictor("edit-identifier", newEditIdentifierPredictor()),
+		kongplete.WithPredictor("preset-identifier", newPresetIdentifierPredictor()),
+		kongplete.WithPredictor("model-identifier", newModelIdentifierPredictor()),
 	)
 
 	ctx, err := parser.Parse(os.Args[1:])
@@ -64,6 +86,14 @@ func main() {
 		parser.FatalIfErrorf(err)
 	}
 
+	// getPaths reads the profile from the environment, which the daemon
+	// started by alpaca start inherits.
+	os.Setenv(config.ProfileEnv, cli.Profile)
+	daemonHost = cli.Host
+	assumeYes = cli.Yes
+	ui.SetPlain(cli.Plain || ui.PlainFromEnv())
+	daemonTimeouts = client.Timeouts{Dial: cli.ConnectTimeout, Request: cli.RequestTimeout}
+
 	err = ctx.Run()
 	if err != nil {
 		var exitErr *ExitError
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/alecthomas/kong"
+)
+
+func TestCLI_BuildsParser(t *testing.T) {
+	// Act
+	_, err := kong.New(&CLI{}, kong.Name("alpaca"))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("kong.New() error = %v", err)
+	}
+}
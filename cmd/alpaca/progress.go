@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/pull"
+	"github.com/d2verb/alpaca/internal/ui"
+)
+
+// multiProgress renders one progress line per concurrent download.
+// On a terminal the lines are redrawn in place; otherwise only state
+// changes (start, finish) are printed so logs stay readable.
+type multiProgress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	live     bool
+	rows     []progressRow
+	drawn    int // lines printed by the last redraw
+	lastDraw time.Time
+}
+
+type progressRow struct {
+	label    string
+	progress pull.Progress
+	status   string // empty while downloading
+}
+
+// progressRedrawInterval limits redraws; progress callbacks fire every 32KB.
+const progressRedrawInterval = 100 * time.Millisecond
+
+func newMultiProgress(out io.Writer, live bool, labels []string) *multiProgress {
+	rows := make([]progressRow, len(labels))
+	for i, label := range labels {
+		rows[i] = progressRow{label: label, status: "waiting"}
+	}
+	return &multiProgress{out: out, live: live, rows: rows}
+}
+
+// isTerminal reports whether stdout is attached to a terminal that output
+// may be redrawn on, which it never is with plain output.
+func isTerminal() bool {
+	if ui.Plain() {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// start marks row i as downloading.
+func (m *multiProgress) start(i int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[i].status = ""
+	if !m.live {
+		fmt.Fprintf(m.out, "%s: downloading\n", m.rows[i].label)
+		return
+	}
+	m.redraw()
+}
+
+// update records download progress for row i.
+func (m *multiProgress) update(i int, pr pull.Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[i].progress = pr
+	if m.live && time.Since(m.lastDraw) >= progressRedrawInterval {
+		m.redraw()
+	}
+}
+
+// finish marks row i as done with the given status.
+func (m *multiProgress) finish(i int, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rows[i].status = status
+	if !m.live {
+		fmt.Fprintf(m.out, "%s: %s\n", m.rows[i].label, status)
+		return
+	}
+	m.redraw()
+}
+
+// redraw moves the cursor back over the previous frame and reprints all rows.
+// Caller must hold m.mu.
+func (m *multiProgress) redraw() {
+	if m.drawn > 0 {
+		fmt.Fprintf(m.out, "\033[%dA", m.drawn)
+	}
+	width := 0
+	for _, r := range m.rows {
+		width = max(width, len(r.label))
+	}
+	for _, r := range m.rows {
+		fmt.Fprintf(m.out, "\r\033[K%-*s  %s\n", width, r.label, formatProgressRow(r))
+	}
+	m.drawn = len(m.rows)
+	m.lastDraw = time.Now()
+}
+
+func formatProgressRow(r progressRow) string {
+	if r.status != "" {
+		return r.status
+	}
+	return formatTransfer(r.progress, 30)
+}
+
+// formatTransfer formats download progress as a bar of barWidth cells
+// followed by the transfer rate, time left, and the shard or mmproj phase.
+func formatTransfer(pr pull.Progress, barWidth int) string {
+	var b strings.Builder
+	if pr.Total <= 0 {
+		fmt.Fprintf(&b, "%s downloaded", formatSize(pr.Downloaded))
+	} else {
+		percent := float64(pr.Downloaded) / float64(pr.Total) * 100
+		filled := min(int(percent/100*float64(barWidth)), barWidth)
+		bar := strings.Repeat(ui.Symbol("█"), filled) + strings.Repeat(ui.Symbol("░"), barWidth-filled)
+		fmt.Fprintf(&b, "[%s] %5.1f%% (%s / %s)", bar, percent, formatSize(pr.Downloaded), formatSize(pr.Total))
+	}
+	if pr.Rate > 0 {
+		fmt.Fprintf(&b, "  %s/s", formatSize(int64(pr.Rate)))
+	}
+	if pr.ETA > 0 {
+		fmt.Fprintf(&b, "  ETA %s", pr.ETA)
+	}
+	if pr.Shards > 1 {
+		fmt.Fprintf(&b, "  shard %d/%d", pr.Shard, pr.Shards)
+	}
+	if pr.Phase == pull.PhaseMmproj {
+		b.WriteString("  mmproj")
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/pull"
+)
+
+func TestMultiProgress_Plain(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	mp := newMultiProgress(&buf, false, []string{"a", "b"})
+
+	// Act
+	mp.start(0)
+	mp.update(0, pull.Progress{Downloaded: 50, Total: 100})
+	mp.finish(0, "done")
+
+	// Assert
+	want := "a: downloading\na: done\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestMultiProgress_LiveRedraw(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	mp := newMultiProgress(&buf, true, []string{"a", "bb"})
+
+	// Act
+	mp.start(0)
+	mp.finish(0, "done")
+
+	// Assert
+	out := buf.String()
+	if strings.Count(out, "\033[2A") != 1 {
+		t.Errorf("expected one cursor-up over the previous frame, got %q", out)
+	}
+	if !strings.Contains(out, "a   done") || !strings.Contains(out, "bb  waiting") {
+		t.Errorf("unexpected frame: %q", out)
+	}
+}
+
+func TestFormatProgressRow(t *testing.T) {
+	tests := []struct {
+		name string
+		row  progressRow
+		want string
+	}{
+		{"status", progressRow{status: "failed"}, "failed"},
+		{"unknown total", progressRow{progress: pull.Progress{Downloaded: 2048, Total: -1}}, "2.0 KB downloaded"},
+		{"half", progressRow{progress: pull.Progress{Downloaded: 512, Total: 1024}}, " 50.0% (512 B / 1.0 KB)"},
+		{"rate and eta", progressRow{progress: pull.Progress{Downloaded: 512, Total: 1024, Rate: 2048, ETA: 90 * time.Second}}, "(512 B / 1.0 KB)  2.0 KB/s  ETA 1m30s"},
+		{"shard", progressRow{progress: pull.Progress{Downloaded: 512, Total: 1024, Shard: 2, Shards: 3}}, "(512 B / 1.0 KB)  shard 2/3"},
+		{"mmproj", progressRow{progress: pull.Progress{Phase: pull.PhaseMmproj, Downloaded: 512, Total: 1024}}, "(512 B / 1.0 KB)  mmproj"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatProgressRow(tt.row)
+			if !strings.HasSuffix(got, tt.want) {
+				t.Errorf("formatProgressRow() = %q, want suffix %q", got, tt.want)
+			}
+		})
+	}
+}
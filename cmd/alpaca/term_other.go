@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package main
+
+import "errors"
+
+var errTerminalUnsupported = errors.New("interactive terminal is not supported on this platform")
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errTerminalUnsupported
+}
+
+func terminalSize(fd int) (width, height int, err error) {
+	return 0, 0, errTerminalUnsupported
+}
@@ -5,9 +5,11 @@ go 1.25.4
 require (
 	github.com/alecthomas/kong v1.13.0
 	github.com/fatih/color v1.18.0
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/posener/complete v1.2.3
 	github.com/willabides/kongplete v0.4.0
 	golang.org/x/mod v0.32.0
+	golang.org/x/sys v0.25.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -18,5 +20,4 @@ require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/riywo/loginshell v0.0.0-20200815045211-7d26008be1ab // indirect
-	golang.org/x/sys v0.25.0 // indirect
 )
@@ -0,0 +1,196 @@
+// Package allowlist restricts the model files the daemon may load to those
+// whose SHA256 is listed in a signed manifest.
+//
+// The manifest uses the sha256sum format of a release's checksums.txt
+// ("<hex>  <name>" per line) and is signed like a release: a raw Ed25519
+// signature of the file, stored next to it with ".sig" appended
+// (see tools/sign).
+package allowlist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	fetchTimeout    = 30 * time.Second
+	maxManifestSize = 16 << 20 // 16MB
+)
+
+// ErrNotApproved reports a model file whose hash is not in the manifest.
+var ErrNotApproved = errors.New("not in the signed model manifest")
+
+// Manifest is a verified list of approved SHA256 hashes.
+type Manifest struct {
+	names map[string]string // lowercase hex → name
+}
+
+// Parse reads a manifest in sha256sum format. Blank lines and lines
+// starting with # are ignored.
+func Parse(data []byte) (*Manifest, error) {
+	m := &Manifest{names: map[string]string{}}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sum, name, _ := strings.Cut(line, " ")
+		if !isSHA256(sum) {
+			return nil, fmt.Errorf("manifest line %d: invalid SHA256 %q", n, sum)
+		}
+		m.names[strings.ToLower(sum)] = strings.TrimLeft(strings.TrimSpace(name), "*")
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return m, nil
+}
+
+// Allows reports whether content with the given SHA256 is approved.
+func (m *Manifest) Allows(sum string) bool {
+	_, ok := m.names[strings.ToLower(sum)]
+	return ok
+}
+
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// Source is a signed manifest at an http(s) URL or a local path.
+type Source struct {
+	location  string
+	publicKey ed25519.PublicKey
+	client    *http.Client
+}
+
+// NewSource returns the manifest at location, signed by the hex-encoded
+// Ed25519 publicKey.
+func NewSource(location, publicKey string) (*Source, error) {
+	if location == "" {
+		return nil, fmt.Errorf("model-manifest is required")
+	}
+	key, err := hex.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("manifest-public-key must be a hex-encoded %d-byte Ed25519 public key", ed25519.PublicKeySize)
+	}
+	return &Source{
+		location:  location,
+		publicKey: ed25519.PublicKey(key),
+		client:    &http.Client{Timeout: fetchTimeout},
+	}, nil
+}
+
+// Fetch reads the manifest and its signature and returns the manifest if
+// the signature is valid.
+func (s *Source) Fetch(ctx context.Context) (*Manifest, error) {
+	data, err := s.read(ctx, s.location)
+	if err != nil {
+		return nil, fmt.Errorf("fetch model manifest: %w", err)
+	}
+	sig, err := s.read(ctx, s.location+".sig")
+	if err != nil {
+		return nil, fmt.Errorf("fetch model manifest signature: %w", err)
+	}
+	if !ed25519.Verify(s.publicKey, data, sig) {
+		return nil, fmt.Errorf("model manifest %s: Ed25519 signature verification failed", s.location)
+	}
+	return Parse(data)
+}
+
+func (s *Source) read(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(io.LimitReader(f, maxManifestSize))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", location, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+}
+
+// sumCache holds the hashes FileSum computed, so a multi-GB model is read
+// once per daemon rather than on every load.
+var sumCache sync.Map // fileKey -> string
+
+// fileKey identifies a file's content as long as it is not rewritten in
+// place with its size and modification time kept.
+type fileKey struct {
+	path  string
+	size  int64
+	mtime int64
+	inode uint64
+}
+
+func fileKeyOf(path string, info os.FileInfo) fileKey {
+	key := fileKey{path: path, size: info.Size(), mtime: info.ModTime().UnixNano()}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		key.inode = uint64(st.Ino)
+	}
+	return key
+}
+
+// FileSum returns the SHA256 of the content of the file at path. Links,
+// such as those into the blob store, are hashed by the file they point
+// to; a blob's name is not trusted, since any directory can be named
+// blobs. The hash is remembered while the file's size, modification time
+// and inode are unchanged.
+func FileSum(path string) (string, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(real)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	key := fileKeyOf(real, info)
+	if sum, ok := sumCache.Load(key); ok {
+		return sum.(string), nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	sumCache.Store(key, sum)
+	return sum, nil
+}
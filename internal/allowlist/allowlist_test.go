@@ -0,0 +1,218 @@
+package allowlist
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSigned writes a manifest and its signature into dir and returns the
+// manifest path and the hex public key.
+func writeSigned(t *testing.T, dir, content string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "approved.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".sig", ed25519.Sign(priv, []byte(content)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, hex.EncodeToString(pub)
+}
+
+func sumOf(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		allowed string
+		wantErr bool
+	}{
+		{"sha256sum format", sumOf("a") + "  model.gguf\n", sumOf("a"), false},
+		{"binary marker and comments", "# approved\n\n" + sumOf("a") + " *model.gguf\n", sumOf("a"), false},
+		{"uppercase hash", strings.ToUpper(sumOf("a")) + "  model.gguf\n", sumOf("a"), false},
+		{"invalid hash", "abc  model.gguf\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !m.Allows(tt.allowed) {
+				t.Errorf("Allows(%s) = false, want true", tt.allowed)
+			}
+		})
+	}
+}
+
+func TestSourceFetch_LocalFile(t *testing.T) {
+	// Arrange
+	path, pub := writeSigned(t, t.TempDir(), sumOf("a")+"  model.gguf\n")
+	src, err := NewSource(path, pub)
+	if err != nil {
+		t.Fatalf("NewSource() error = %v", err)
+	}
+
+	// Act
+	m, err := src.Fetch(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !m.Allows(sumOf("a")) || m.Allows(sumOf("b")) {
+		t.Error("manifest should allow exactly the listed hash")
+	}
+}
+
+func TestSourceFetch_TamperedManifest(t *testing.T) {
+	// Arrange
+	path, pub := writeSigned(t, t.TempDir(), sumOf("a")+"  model.gguf\n")
+	if err := os.WriteFile(path, []byte(sumOf("evil")+"  model.gguf\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, _ := NewSource(path, pub)
+
+	// Act
+	_, err := src.Fetch(context.Background())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Errorf("Fetch() error = %v, want signature failure", err)
+	}
+}
+
+func TestSourceFetch_HTTP(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	_, pub := writeSigned(t, dir, sumOf("a")+"  model.gguf\n")
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer srv.Close()
+	src, _ := NewSource(srv.URL+"/approved.txt", pub)
+
+	// Act
+	m, err := src.Fetch(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if !m.Allows(sumOf("a")) {
+		t.Error("manifest should allow the listed hash")
+	}
+}
+
+func TestNewSource_InvalidKey(t *testing.T) {
+	if _, err := NewSource("approved.txt", "abcd"); err == nil {
+		t.Error("NewSource() should reject a short key")
+	}
+	if _, err := NewSource("", strings.Repeat("00", ed25519.PublicKeySize)); err == nil {
+		t.Error("NewSource() should require a location")
+	}
+}
+
+func TestFileSum(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "plain.gguf")
+	if err := os.WriteFile(plain, []byte("weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sumOf("stored")
+	if err := os.MkdirAll(filepath.Join(dir, "blobs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "blobs", "sha256-"+sum), []byte("stored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	linked := filepath.Join(dir, "linked.gguf")
+	if err := os.Symlink(filepath.Join("blobs", "sha256-"+sum), linked); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	plainSum, err1 := FileSum(plain)
+	linkedSum, err2 := FileSum(linked)
+
+	// Assert
+	if err := errors.Join(err1, err2); err != nil {
+		t.Fatalf("FileSum() error = %v", err)
+	}
+	if plainSum != sumOf("weights") {
+		t.Errorf("FileSum(plain) = %s, want %s", plainSum, sumOf("weights"))
+	}
+	if linkedSum != sum {
+		t.Errorf("FileSum(linked) = %s, want %s", linkedSum, sum)
+	}
+}
+
+func TestFileSum_HashesForgedBlob(t *testing.T) {
+	// Arrange: a file outside the store named like an approved blob
+	approved := sumOf("approved weights")
+	forged := filepath.Join(t.TempDir(), "blobs", "sha256-"+approved)
+	if err := os.MkdirAll(filepath.Dir(forged), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(forged, []byte("other weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	got, err := FileSum(forged)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FileSum() error = %v", err)
+	}
+	if got != sumOf("other weights") {
+		t.Errorf("FileSum() = %s, want the hash of the content %s, not its name", got, sumOf("other weights"))
+	}
+}
+
+func TestFileSum_RehashesChangedFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(path, []byte("weights v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FileSum(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("weights v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	got, err := FileSum(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FileSum() error = %v", err)
+	}
+	if got != sumOf("weights v2") {
+		t.Errorf("FileSum() = %s, want the hash of the new content", got)
+	}
+}
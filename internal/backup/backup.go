@@ -0,0 +1,187 @@
+// Package backup exports and imports alpaca's state (config, presets and,
+// optionally, models) as a single gzip-compressed tar archive.
+//
+// The archive mirrors ~/.alpaca:
+//
+//	alpaca-backup.json     manifest
+//	config.yaml
+//	presets/*.yaml
+//	models/.metadata.json  only with models
+//	models/<file>          symlink into models/blobs, or a regular file
+//	models/blobs/sha256-<hex>
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// Version is the archive format version written to the manifest.
+const Version = 1
+
+const (
+	manifestName = "alpaca-backup.json"
+	configName   = "config.yaml"
+	presetsDir   = "presets"
+	modelsDir    = "models"
+	metadataName = ".metadata.json"
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Models    bool      `json:"models"` // model files and metadata are included
+}
+
+// Paths are the locations exported from and imported into.
+type Paths struct {
+	Config  string // config.yaml
+	Presets string // presets directory
+	Models  string // models directory
+}
+
+// Export writes an archive of the config file, presets and, if
+// includeModels is set, every model, mmproj and LoRA file recorded in
+// metadata. Files imported with --link are archived by content.
+func Export(ctx context.Context, w io.Writer, paths Paths, includeModels bool) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	manifest, err := json.Marshal(Manifest{Version: Version, CreatedAt: time.Now().UTC(), Models: includeModels})
+	if err != nil {
+		return err
+	}
+	if err := writeBytes(tw, manifestName, manifest); err != nil {
+		return err
+	}
+
+	if err := addFile(tw, configName, paths.Config); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	entries, err := os.ReadDir(paths.Presets)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read presets dir: %w", err)
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".yaml") {
+			continue
+		}
+		if err := addFile(tw, path.Join(presetsDir, e.Name()), filepath.Join(paths.Presets, e.Name())); err != nil {
+			return err
+		}
+	}
+
+	if includeModels {
+		if err := exportModels(ctx, tw, paths.Models); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
+
+// exportModels archives the metadata file and the files it records. Links
+// into the blob store stay links, and each blob is archived once.
+func exportModels(ctx context.Context, tw *tar.Writer, dir string) error {
+	meta := metadata.NewManager(dir)
+	if err := meta.Load(ctx); err != nil {
+		return fmt.Errorf("load metadata: %w", err)
+	}
+	if err := addFile(tw, path.Join(modelsDir, metadataName), filepath.Join(dir, metadataName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	store := blob.New(dir)
+	written := make(map[string]bool)
+	for _, name := range meta.AllFiles() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		b, ok := store.Target(name)
+		if !ok {
+			if err := addFile(tw, path.Join(modelsDir, name), filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			continue
+		}
+		target, _ := filepath.Rel(filepath.Dir(name), b)
+		hdr := &tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     path.Join(modelsDir, filepath.ToSlash(name)),
+			Linkname: filepath.ToSlash(target),
+			Mode:     0777,
+			ModTime:  time.Now(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		if !written[b] {
+			written[b] = true
+			if err := addFile(tw, path.Join(modelsDir, filepath.ToSlash(b)), filepath.Join(dir, b)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addFile archives the file at src (following links) as name.
+func addFile(tw *tar.Writer, name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", src)
+	}
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     info.Size(),
+		Mode:     int64(info.Mode().Perm()),
+		ModTime:  info.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	if _, err := io.Copy(tw, f); err != nil {
+		return fmt.Errorf("archive %s: %w", src, err)
+	}
+	return nil
+}
+
+// writeBytes archives data as name.
+func writeBytes(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(data)), Mode: 0644, ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	_, err := tw.Write(data)
+	return err
+}
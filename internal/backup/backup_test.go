@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// newState creates an alpaca home with the given config and presets
+// (filename -> content) and returns its paths.
+func newState(t *testing.T, cfg string, presets map[string]string) Paths {
+	t.Helper()
+	home := t.TempDir()
+	paths := Paths{
+		Config:  filepath.Join(home, "config.yaml"),
+		Presets: filepath.Join(home, "presets"),
+		Models:  filepath.Join(home, "models"),
+	}
+	for _, dir := range []string{paths.Presets, paths.Models} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cfg != "" {
+		if err := os.WriteFile(paths.Config, []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range presets {
+		if err := os.WriteFile(filepath.Join(paths.Presets, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+// addModel stores content as filename in the blob store and records it as
+// repo's Q4_K_M model.
+func addModel(t *testing.T, paths Paths, repo, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(paths.Models, filename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := fileSHA256(filepath.Join(paths.Models, filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := blob.New(paths.Models).Adopt(filename, sum); err != nil {
+		t.Fatal(err)
+	}
+	meta := metadata.NewManager(paths.Models)
+	err = meta.Commit(context.Background(), func(m *metadata.Manager) error {
+		return m.Add(metadata.ModelEntry{Repo: repo, Quant: "Q4_K_M", Filename: filename, Size: int64(len(content))})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportImport_MergesIntoExistingState(t *testing.T) {
+	// Arrange
+	src := newState(t, "hf-endpoint: https://mirror.example\nunload-timeout: 30\n", map[string]string{
+		"aaa.yaml": "name: coder\nmodel: \"h:org/coder-GGUF:Q4_K_M\"\n",
+		"bbb.yaml": "name: chat\nmodel: \"h:org/chat-GGUF:Q4_K_M\"\n",
+	})
+	addModel(t, src, "org/coder-GGUF", "coder-Q4_K_M.gguf", "coder-weights")
+	addModel(t, src, "org/chat-GGUF", "chat-Q4_K_M.gguf", "chat-weights")
+
+	dst := newState(t, "unload-timeout: 5\n", map[string]string{
+		"ccc.yaml": "name: chat\nmodel: \"h:org/other-GGUF:Q8_0\"\n",
+	})
+	addModel(t, dst, "org/chat-GGUF", "chat-Q4_K_M.gguf", "chat-weights")
+
+	var archive bytes.Buffer
+	if err := Export(context.Background(), &archive, src, true); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// Act
+	result, err := Import(context.Background(), &archive, dst)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	wantImported := []string{"config key hf-endpoint", "preset coder", "model h:org/coder-GGUF:Q4_K_M"}
+	if !slices.Equal(result.Imported, wantImported) {
+		t.Errorf("Imported = %v, want %v", result.Imported, wantImported)
+	}
+	wantSkipped := []string{
+		"config key unload-timeout (kept local value)",
+		"preset chat (already exists)",
+		"model h:org/chat-GGUF:Q4_K_M (already present)",
+	}
+	if !slices.Equal(result.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %v, want %v", result.Skipped, wantSkipped)
+	}
+
+	cfg, _ := os.ReadFile(dst.Config)
+	if !strings.Contains(string(cfg), "unload-timeout: 5") || !strings.Contains(string(cfg), "hf-endpoint: https://mirror.example") {
+		t.Errorf("merged config = %q", cfg)
+	}
+	if _, err := os.Stat(filepath.Join(dst.Presets, "aaa.yaml")); err != nil {
+		t.Errorf("preset coder not imported: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dst.Models, "coder-Q4_K_M.gguf"))
+	if err != nil || string(got) != "coder-weights" {
+		t.Errorf("coder-Q4_K_M.gguf = %q, %v, want coder-weights", got, err)
+	}
+	meta := metadata.NewManager(dst.Models)
+	if err := meta.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Find("org/coder-GGUF", "Q4_K_M") == nil || len(meta.List()) != 2 {
+		t.Errorf("metadata = %+v, want chat and coder", meta.List())
+	}
+}
+
+func TestExport_WithoutModels(t *testing.T) {
+	// Arrange
+	src := newState(t, "", nil)
+	addModel(t, src, "org/coder-GGUF", "coder-Q4_K_M.gguf", "coder-weights")
+	dst := newState(t, "", nil)
+	var archive bytes.Buffer
+	if err := Export(context.Background(), &archive, src, false); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// Act
+	result, err := Import(context.Background(), &archive, dst)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Imported) != 0 {
+		t.Errorf("Imported = %v, want nothing", result.Imported)
+	}
+	if _, err := os.Stat(filepath.Join(dst.Models, "coder-Q4_K_M.gguf")); !os.IsNotExist(err) {
+		t.Errorf("model imported without --include-models: %v", err)
+	}
+}
+
+func TestImport_Rejects(t *testing.T) {
+	manifest := `{"version":1,"models":true}`
+	tests := []struct {
+		name    string
+		entries []tar.Header
+		content map[string]string
+		wantErr string
+	}{
+		{
+			name:    "missing manifest",
+			entries: []tar.Header{{Name: "config.yaml", Typeflag: tar.TypeReg}},
+			wantErr: "not an alpaca backup",
+		},
+		{
+			name:    "path traversal",
+			entries: []tar.Header{{Name: "../evil.yaml", Typeflag: tar.TypeReg}},
+			wantErr: "unexpected file",
+		},
+		{
+			name:    "link outside blob store",
+			entries: []tar.Header{{Name: "models/x.gguf", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+			wantErr: "unexpected link",
+		},
+		{
+			name:    "invalid preset",
+			entries: []tar.Header{{Name: manifestName, Typeflag: tar.TypeReg}, {Name: "presets/a.yaml", Typeflag: tar.TypeReg}},
+			content: map[string]string{manifestName: manifest, "presets/a.yaml": "name: Bad Name!\n"},
+			wantErr: "preset a.yaml",
+		},
+		{
+			name:    "corrupted blob",
+			entries: []tar.Header{{Name: manifestName, Typeflag: tar.TypeReg}, {Name: "models/blobs/sha256-00", Typeflag: tar.TypeReg}},
+			content: map[string]string{manifestName: manifest, "models/blobs/sha256-00": "data"},
+			wantErr: "integrity verification failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			tw := tar.NewWriter(gz)
+			for _, hdr := range tt.entries {
+				data := tt.content[hdr.Name]
+				hdr.Size = int64(len(data))
+				hdr.Mode = 0644
+				if err := tw.WriteHeader(&hdr); err != nil {
+					t.Fatal(err)
+				}
+				tw.Write([]byte(data))
+			}
+			tw.Close()
+			gz.Close()
+			dst := newState(t, "", nil)
+
+			// Act
+			_, err := Import(context.Background(), &buf, dst)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Import() error = %v, want %q", err, tt.wantErr)
+			}
+			entries, _ := os.ReadDir(dst.Presets)
+			if len(entries) != 0 {
+				t.Errorf("presets changed by rejected import: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/preset"
+	"gopkg.in/yaml.v3"
+)
+
+// Result reports what Import merged into the local state.
+type Result struct {
+	Imported []string // e.g. "preset coder", "model h:org/repo:Q4_K_M"
+	Skipped  []string // items already present locally, with the reason
+}
+
+// Import merges an archive written by Export into paths. Nothing present
+// locally is overwritten: config keys already set, presets whose name
+// exists, and models or LoRA adapters already in metadata are kept and
+// reported as skipped. The whole archive is unpacked into a staging
+// directory and validated (config, presets, metadata and model hashes)
+// before anything is changed.
+func Import(ctx context.Context, r io.Reader, paths Paths) (*Result, error) {
+	if err := os.MkdirAll(paths.Models, 0755); err != nil {
+		return nil, fmt.Errorf("create models dir: %w", err)
+	}
+	// Staged next to the models directory, so model files are moved into
+	// place rather than copied.
+	stage, err := os.MkdirTemp(filepath.Dir(paths.Models), ".import-")
+	if err != nil {
+		return nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(stage)
+
+	if err := extract(r, stage); err != nil {
+		return nil, err
+	}
+	a, err := validate(ctx, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if a.config != nil {
+		if err := mergeConfig(a.config, paths.Config, result); err != nil {
+			return nil, err
+		}
+	}
+	if err := importPresets(a.presets, paths.Presets, result); err != nil {
+		return nil, err
+	}
+	if a.metadata != nil {
+		if err := importModels(ctx, a.metadata, filepath.Join(stage, modelsDir), paths.Models, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// extract unpacks the archive into dir. Only the paths of the archive
+// layout are accepted; links may only point into models/blobs.
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("read archive: %w", err)
+	}
+	defer gz.Close()
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		if !allowedPath(name) {
+			return fmt.Errorf("unexpected file in archive: %s", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+			if err := root.MkdirAll(path.Dir(name), 0755); err != nil {
+				return err
+			}
+			f, err := root.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+			if err != nil {
+				return fmt.Errorf("extract %s: %w", name, err)
+			}
+			_, err = io.Copy(f, tr)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return fmt.Errorf("extract %s: %w", name, err)
+			}
+		case tar.TypeSymlink:
+			target := path.Join(path.Dir(name), hdr.Linkname)
+			if !strings.HasPrefix(name, modelsDir+"/") || path.IsAbs(hdr.Linkname) || path.Dir(target) != path.Join(modelsDir, blob.Dir) {
+				return fmt.Errorf("unexpected link in archive: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := root.MkdirAll(path.Dir(name), 0755); err != nil {
+				return err
+			}
+			if err := root.Symlink(hdr.Linkname, name); err != nil {
+				return fmt.Errorf("extract %s: %w", name, err)
+			}
+		default:
+			return fmt.Errorf("unexpected file in archive: %s", hdr.Name)
+		}
+	}
+}
+
+// allowedPath reports whether name belongs to the archive layout.
+func allowedPath(name string) bool {
+	if !filepath.IsLocal(name) {
+		return false
+	}
+	switch dir := path.Dir(name); {
+	case name == manifestName || name == configName:
+		return true
+	case dir == presetsDir:
+		return strings.HasSuffix(name, ".yaml")
+	case dir == modelsDir, dir == path.Join(modelsDir, blob.Dir):
+		return true
+	}
+	return false
+}
+
+// archive is the validated content of an unpacked archive.
+type archive struct {
+	config   []byte            // nil if absent
+	presets  map[string]string // preset name -> staged file
+	metadata *metadata.Manager // nil unless models are included
+}
+
+// validate checks everything staged in dir with the same validators used
+// when alpaca reads its own files.
+func validate(ctx context.Context, dir string) (*archive, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, fmt.Errorf("not an alpaca backup: %s missing", manifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestName, err)
+	}
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported backup version %d (this alpaca reads version %d)", manifest.Version, Version)
+	}
+
+	a := &archive{presets: make(map[string]string)}
+	configPath := filepath.Join(dir, configName)
+	if data, err := os.ReadFile(configPath); err == nil {
+		if _, err := config.Load(configPath); err != nil {
+			return nil, err
+		}
+		a.config = data
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, presetsDir, "*.yaml"))
+	for _, f := range files {
+		p, err := preset.LoadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("preset %s: %w", filepath.Base(f), err)
+		}
+		if _, dup := a.presets[p.Name]; dup {
+			return nil, fmt.Errorf("preset %s appears twice in archive", p.Name)
+		}
+		a.presets[p.Name] = f
+	}
+
+	if !manifest.Models {
+		return a, nil
+	}
+	models := filepath.Join(dir, modelsDir)
+	a.metadata = metadata.NewManager(models)
+	if err := a.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	for _, f := range a.metadata.AllFiles() {
+		if !filepath.IsLocal(f) {
+			return nil, fmt.Errorf("invalid filename in metadata: %s", f)
+		}
+		if _, err := os.Stat(filepath.Join(models, f)); err != nil {
+			return nil, fmt.Errorf("model file %s missing from archive", f)
+		}
+	}
+	blobs, err := blob.New(models).List()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blobs {
+		sum, err := fileSHA256(filepath.Join(models, b.Name))
+		if err != nil {
+			return nil, err
+		}
+		if sum != blob.Sum(b.Name) {
+			return nil, fmt.Errorf("integrity verification failed for %s: hash mismatch", b.Name)
+		}
+	}
+	return a, nil
+}
+
+// mergeConfig adds the archived config keys that are not set in the local
+// config file; keys already set keep their local value.
+func mergeConfig(archived []byte, dest string, result *Result) error {
+	local, err := os.ReadFile(dest)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read config: %w", err)
+	}
+	var localDoc, archivedDoc yaml.Node
+	if err := yaml.Unmarshal(local, &localDoc); err != nil {
+		return fmt.Errorf("parse config %s: %w", dest, err)
+	}
+	if err := yaml.Unmarshal(archived, &archivedDoc); err != nil {
+		return fmt.Errorf("parse archived config: %w", err)
+	}
+	if len(archivedDoc.Content) == 0 {
+		return nil
+	}
+	if len(localDoc.Content) == 0 {
+		if err := os.WriteFile(dest, archived, 0644); err != nil {
+			return fmt.Errorf("write config: %w", err)
+		}
+		result.Imported = append(result.Imported, "config")
+		return nil
+	}
+
+	localMap, archivedMap := localDoc.Content[0], archivedDoc.Content[0]
+	if localMap.Kind != yaml.MappingNode || archivedMap.Kind != yaml.MappingNode {
+		return fmt.Errorf("config is not a mapping")
+	}
+	set := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(localMap.Content); i += 2 {
+		set[localMap.Content[i].Value] = localMap.Content[i+1]
+	}
+	added := false
+	for i := 0; i+1 < len(archivedMap.Content); i += 2 {
+		key, value := archivedMap.Content[i], archivedMap.Content[i+1]
+		if existing, ok := set[key.Value]; ok {
+			if !sameNode(existing, value) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("config key %s (kept local value)", key.Value))
+			}
+			continue
+		}
+		localMap.Content = append(localMap.Content, key, value)
+		result.Imported = append(result.Imported, "config key "+key.Value)
+		added = true
+	}
+	if !added {
+		return nil
+	}
+
+	merged, err := yaml.Marshal(&localDoc)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	var cfg config.Config
+	if err := yaml.Unmarshal(merged, &cfg); err != nil {
+		return fmt.Errorf("merged config is invalid: %w", err)
+	}
+	if err := os.WriteFile(dest, merged, 0644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
+
+// sameNode reports whether two YAML values encode the same.
+func sameNode(a, b *yaml.Node) bool {
+	ea, errA := yaml.Marshal(a)
+	eb, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ea, eb)
+}
+
+// importPresets copies presets whose name is not taken locally. Files are
+// copied as written, keeping comments and ${VAR} references.
+func importPresets(presets map[string]string, dir string, result *Result) error {
+	if len(presets) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create presets dir: %w", err)
+	}
+	loader := preset.NewLoader(dir)
+	for _, name := range slices.Sorted(maps.Keys(presets)) {
+		exists, err := loader.Exists(name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("preset %s (already exists)", name))
+			continue
+		}
+		src := presets[name]
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(dir, filepath.Base(src))
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			if errors.Is(err, os.ErrExist) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("preset %s (file %s exists)", name, filepath.Base(src)))
+				continue
+			}
+			return fmt.Errorf("write preset: %w", err)
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("write preset: %w", err)
+		}
+		result.Imported = append(result.Imported, "preset "+name)
+	}
+	return nil
+}
+
+// importModels moves the archived files of models and LoRA adapters not in
+// local metadata into the models directory and records them. An entry
+// whose files would replace different local content is skipped.
+func importModels(ctx context.Context, archived *metadata.Manager, stageDir, modelsDir string, result *Result) error {
+	local := metadata.NewManager(modelsDir)
+	if err := local.Load(ctx); err != nil {
+		return fmt.Errorf("load metadata: %w", err)
+	}
+	im := &modelImporter{stageDir: stageDir, modelsDir: modelsDir, staged: blob.New(stageDir), store: blob.New(modelsDir)}
+
+	var models []metadata.ModelEntry
+	for _, e := range archived.List() {
+		label := fmt.Sprintf("model h:%s:%s", e.Repo, e.Quant)
+		if local.Find(e.Repo, e.Quant) != nil {
+			result.Skipped = append(result.Skipped, label+" (already present)")
+			continue
+		}
+		files := e.Files()
+		if e.Mmproj != nil {
+			files = append(files, e.Mmproj.Filename)
+		}
+		for _, x := range e.ExtraFiles {
+			files = append(files, x.Filename)
+		}
+		if err := im.place(files); err != nil {
+			if errors.Is(err, errConflict) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s (%v)", label, err))
+				continue
+			}
+			return err
+		}
+		models = append(models, e)
+		result.Imported = append(result.Imported, label)
+	}
+
+	var loras []metadata.LoraEntry
+	for _, l := range archived.ListLoras() {
+		label := fmt.Sprintf("lora h:%s:%s", l.Repo, l.File)
+		if local.FindLora(l.Repo, l.File) != nil {
+			result.Skipped = append(result.Skipped, label+" (already present)")
+			continue
+		}
+		if err := im.place([]string{l.Filename}); err != nil {
+			if errors.Is(err, errConflict) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s (%v)", label, err))
+				continue
+			}
+			return err
+		}
+		loras = append(loras, l)
+		result.Imported = append(result.Imported, label)
+	}
+
+	if len(models) == 0 && len(loras) == 0 {
+		return nil
+	}
+	return local.Commit(ctx, func(m *metadata.Manager) error {
+		for _, e := range models {
+			if err := m.Add(e); err != nil {
+				return err
+			}
+		}
+		for _, l := range loras {
+			m.AddLora(l)
+		}
+		return nil
+	})
+}
+
+// errConflict reports a local file with different content under an
+// archived file's name.
+var errConflict = errors.New("conflicts with a different local file")
+
+// modelImporter moves staged model files into the models directory.
+type modelImporter struct {
+	stageDir  string
+	modelsDir string
+	staged    *blob.Store
+	store     *blob.Store
+}
+
+// place moves the staged files into the blob store and links them under
+// their names. Files already present locally with the same content are
+// left alone. Either every file is placed or, on a conflict, none is.
+func (im *modelImporter) place(names []string) error {
+	sums := make([]string, len(names))
+	present := make([]bool, len(names))
+	for i, name := range names {
+		sum, err := im.sum(im.staged, im.stageDir, name)
+		if err != nil {
+			return err
+		}
+		sums[i] = sum
+		if _, err := os.Lstat(filepath.Join(im.modelsDir, name)); err != nil {
+			continue
+		}
+		localSum, err := im.sum(im.store, im.modelsDir, name)
+		if err != nil || localSum != sum {
+			return fmt.Errorf("%s %w", name, errConflict)
+		}
+		present[i] = true
+	}
+
+	for i, name := range names {
+		if present[i] {
+			continue
+		}
+		if im.store.Has(sums[i]) {
+			if err := im.store.Link(name, sums[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		src := filepath.Join(im.stageDir, name)
+		if b, ok := im.staged.Target(name); ok {
+			src = filepath.Join(im.stageDir, b)
+		}
+		if err := os.Rename(src, filepath.Join(im.modelsDir, name)); err != nil {
+			return fmt.Errorf("import %s: %w", name, err)
+		}
+		if err := im.store.Adopt(name, sums[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sum returns the SHA256 of name in dir, read from its blob link if it has
+// one and hashed otherwise.
+func (im *modelImporter) sum(store *blob.Store, dir, name string) (string, error) {
+	if b, ok := store.Target(name); ok {
+		return blob.Sum(b), nil
+	}
+	return fileSHA256(filepath.Join(dir, name))
+}
+
+// fileSHA256 returns the hex-encoded SHA256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
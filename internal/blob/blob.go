@@ -0,0 +1,151 @@
+// Package blob stores model files by content in the models directory.
+//
+// Each file is kept once at blobs/sha256-<hex>. The names recorded in
+// metadata (and passed to llama-server) are symlinks into the store, so a
+// file registered under several repositories or names uses disk space once.
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dir is the store's directory, relative to the models directory.
+const Dir = "blobs"
+
+// Name returns the blob holding content with the given SHA256, relative to
+// the models directory.
+func Name(sum string) string {
+	return filepath.Join(Dir, "sha256-"+strings.ToLower(sum))
+}
+
+// Sum returns the SHA256 of the content held by blob, as returned by Name.
+func Sum(blob string) string {
+	return strings.TrimPrefix(filepath.Base(blob), "sha256-")
+}
+
+// Info describes a stored blob.
+type Info struct {
+	Name    string // relative to the models directory
+	Size    int64
+	ModTime time.Time
+}
+
+// Store is the blob store of a models directory.
+type Store struct {
+	modelsDir string
+}
+
+// New returns the blob store of modelsDir.
+func New(modelsDir string) *Store {
+	return &Store{modelsDir: modelsDir}
+}
+
+// Has reports whether content with the given SHA256 is stored.
+func (s *Store) Has(sum string) bool {
+	info, err := os.Stat(filepath.Join(s.modelsDir, Name(sum)))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// Adopt moves the regular file name (relative to the models directory),
+// whose SHA256 is sum, into the store and replaces it with a link. If the
+// content is already stored, the file is deleted instead.
+func (s *Store) Adopt(name, sum string) error {
+	root, err := os.OpenRoot(s.modelsDir)
+	if err != nil {
+		return fmt.Errorf("open models dir: %w", err)
+	}
+	defer root.Close()
+
+	if err := root.MkdirAll(Dir, 0755); err != nil {
+		return fmt.Errorf("create blob store: %w", err)
+	}
+	blob := Name(sum)
+	if info, err := root.Stat(blob); err == nil && info.Mode().IsRegular() {
+		if err := root.Remove(name); err != nil {
+			return fmt.Errorf("remove duplicate %s: %w", name, err)
+		}
+	} else if err := root.Rename(name, blob); err != nil {
+		return fmt.Errorf("store %s: %w", name, err)
+	}
+	return link(root, name, blob)
+}
+
+// Link points name at the stored content with the given SHA256, replacing
+// any file at name.
+func (s *Store) Link(name, sum string) error {
+	root, err := os.OpenRoot(s.modelsDir)
+	if err != nil {
+		return fmt.Errorf("open models dir: %w", err)
+	}
+	defer root.Close()
+	return link(root, name, Name(sum))
+}
+
+// link replaces name with a relative symlink to blob. The blob's
+// modification time is refreshed so a concurrent gc, which spares recent
+// blobs, does not delete it before the new name is recorded in metadata.
+func link(root *os.Root, name, blob string) error {
+	target, err := filepath.Rel(filepath.Dir(name), blob)
+	if err != nil {
+		return err
+	}
+	if err := root.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("replace %s: %w", name, err)
+	}
+	if err := root.Symlink(target, name); err != nil {
+		return fmt.Errorf("link %s: %w", name, err)
+	}
+	now := time.Now()
+	root.Chtimes(blob, now, now)
+	return nil
+}
+
+// Target returns the blob that name links to. ok is false if name is not a
+// link into the store.
+func (s *Store) Target(name string) (blob string, ok bool) {
+	target, err := os.Readlink(filepath.Join(s.modelsDir, name))
+	if err != nil || filepath.IsAbs(target) {
+		return "", false
+	}
+	blob = filepath.Join(filepath.Dir(name), target)
+	if filepath.Dir(blob) != Dir {
+		return "", false
+	}
+	return blob, true
+}
+
+// List returns the stored blobs.
+func (s *Store) List() ([]Info, error) {
+	entries, err := os.ReadDir(filepath.Join(s.modelsDir, Dir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read blob store: %w", err)
+	}
+	var blobs []Info
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		blobs = append(blobs, Info{Name: filepath.Join(Dir, e.Name()), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return blobs, nil
+}
+
+// Remove deletes a stored blob.
+func (s *Store) Remove(blob string) error {
+	if filepath.Dir(blob) != Dir {
+		return fmt.Errorf("not a blob: %s", blob)
+	}
+	if err := os.Remove(filepath.Join(s.modelsDir, blob)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sum(data string) string {
+	h := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(h[:])
+}
+
+func TestAdopt(t *testing.T) {
+	tests := []struct {
+		name        string
+		alreadyHeld bool
+	}{
+		{"new content", false},
+		{"content already stored", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			s := New(dir)
+			if tt.alreadyHeld {
+				os.WriteFile(filepath.Join(dir, "other.gguf"), []byte("weights"), 0644)
+				if err := s.Adopt("other.gguf", sum("weights")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0644)
+
+			// Act
+			err := s.Adopt("model.gguf", sum("weights"))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Adopt() error = %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "model.gguf"))
+			if err != nil || string(got) != "weights" {
+				t.Errorf("model.gguf = %q, %v, want weights", got, err)
+			}
+			blob, ok := s.Target("model.gguf")
+			if !ok || blob != Name(sum("weights")) {
+				t.Errorf("Target() = %q, %v, want %q", blob, ok, Name(sum("weights")))
+			}
+			blobs, err := s.List()
+			if err != nil || len(blobs) != 1 || blobs[0].Size != int64(len("weights")) {
+				t.Errorf("List() = %+v, %v, want one blob", blobs, err)
+			}
+		})
+	}
+}
+
+func TestTarget_NotInStore(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "plain.gguf"), []byte("x"), 0644)
+	os.Symlink("/elsewhere/model.gguf", filepath.Join(dir, "linked.gguf"))
+	s := New(dir)
+
+	for _, name := range []string{"plain.gguf", "linked.gguf", "missing.gguf"} {
+		// Act
+		_, ok := s.Target(name)
+
+		// Assert
+		if ok {
+			t.Errorf("Target(%q) ok = true, want false", name)
+		}
+	}
+}
+
+func TestRemove_RejectsPathsOutsideStore(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("x"), 0644)
+
+	// Act
+	err := New(dir).Remove("model.gguf")
+
+	// Assert
+	if err == nil {
+		t.Error("Remove() error = nil, want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model.gguf")); err != nil {
+		t.Errorf("model.gguf removed: %v", err)
+	}
+}
@@ -3,36 +3,138 @@ package client
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"syscall"
 	"time"
 
 	"github.com/d2verb/alpaca/internal/protocol"
 )
 
-const socketTimeout = 30 * time.Second
+const (
+	// DefaultTimeout bounds requests that answer right away (status,
+	// stats). Loads and unloads wait as long as the daemon needs.
+	DefaultTimeout = 30 * time.Second
 
-// Client communicates with the daemon via Unix socket.
+	// DefaultDialTimeout bounds connecting to the daemon.
+	DefaultDialTimeout = 5 * time.Second
+
+	// retryDelay is the pause before retrying a transient connect failure.
+	retryDelay = 200 * time.Millisecond
+
+	// cancelWait is how long a canceled request waits for the daemon to
+	// confirm it stopped the work.
+	cancelWait = 15 * time.Second
+)
+
+// ErrUnauthorized is returned when a remote daemon rejects the client token.
+var ErrUnauthorized = errors.New("remote daemon rejected the token (check ALPACA_TOKEN)")
+
+// ErrPeerRejected is returned when the local daemon refuses connections from
+// the calling user.
+var ErrPeerRejected = errors.New("daemon rejected this user (ask its owner to add you to allow-users)")
+
+// VersionMismatchError is returned when the daemon speaks another protocol
+// version than this client, typically because alpaca was upgraded while the
+// daemon kept running.
+type VersionMismatchError struct {
+	Client int
+	Daemon int // 0 for daemons that predate versioning
+}
+
+func (e *VersionMismatchError) Error() string {
+	if e.Daemon < e.Client {
+		return fmt.Sprintf("the daemon is running an older alpaca (protocol version %d, this CLI: %d)", e.Daemon, e.Client)
+	}
+	return fmt.Sprintf("the daemon is running a newer alpaca (protocol version %d, this CLI: %d)", e.Daemon, e.Client)
+}
+
+// Client communicates with the daemon via Unix socket or, for remote
+// daemons, via TCP.
 type Client struct {
-	socketPath string
+	network  string
+	address  string
+	token    string
+	tls      *tls.Config // nil for plaintext
+	timeouts Timeouts
+}
+
+// Timeouts bound a client's requests.
+type Timeouts struct {
+	Dial    time.Duration // connecting; zero uses DefaultDialTimeout
+	Request time.Duration // whole request; zero uses each command's default
 }
 
 // New creates a new daemon client.
 func New(socketPath string) *Client {
-	return &Client{socketPath: socketPath}
+	return &Client{network: "unix", address: socketPath}
 }
 
-// Send sends a request to the daemon and returns the response.
-func (c *Client) Send(req *protocol.Request) (*protocol.Response, error) {
-	conn, err := net.DialTimeout("unix", c.socketPath, socketTimeout)
+// NewRemote creates a client for a daemon listening on a TCP address
+// (host:port). The token is sent with every request. Daemons off the
+// local machine are reached over TLS, verified against the system's CAs
+// unless SetTLSConfig says otherwise.
+func NewRemote(addr, token string) *Client {
+	c := &Client{network: "tcp", address: addr, token: token}
+	if !protocol.IsLoopback(addr) {
+		c.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c
+}
+
+// SetTLSConfig makes a remote client connect over TLS with cfg, e.g. to
+// trust the daemon's own CA or present a client certificate. A nil cfg
+// selects plaintext, which only loopback daemons accept.
+func (c *Client) SetTLSConfig(cfg *tls.Config) {
+	c.tls = cfg
+}
+
+// SetTimeouts overrides the default timeouts.
+func (c *Client) SetTimeouts(t Timeouts) {
+	c.timeouts = t
+}
+
+// Send sends a request to the daemon and returns the response, giving up
+// after DefaultTimeout.
+func (c *Client) Send(ctx context.Context, req *protocol.Request) (*protocol.Response, error) {
+	return c.send(ctx, req, DefaultTimeout)
+}
+
+// send sends a request that may take up to timeout (zero: no limit). When
+// ctx ends first, the daemon is asked to cancel the request and the
+// context's error is returned once it confirms or cancelWait passes.
+func (c *Client) send(ctx context.Context, req *protocol.Request, timeout time.Duration) (*protocol.Response, error) {
+	return c.sendWithProgress(ctx, req, timeout, nil)
+}
+
+// sendWithProgress is like send but passes the interim StatusProgress
+// responses sent before the final one to progress, if set.
+func (c *Client) sendWithProgress(ctx context.Context, req *protocol.Request, timeout time.Duration, progress func(*protocol.Response)) (*protocol.Response, error) {
+	if c.timeouts.Request > 0 {
+		timeout = c.timeouts.Request
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := c.dial(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("connect to daemon: %w", err)
 	}
 	defer conn.Close()
-	conn.SetDeadline(time.Now().Add(socketTimeout))
 
 	// Send request
+	if c.token != "" {
+		authed := *req
+		authed.Token = c.token
+		req = &authed
+	}
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -42,34 +144,189 @@ func (c *Client) Send(req *protocol.Request) (*protocol.Response, error) {
 		return nil, fmt.Errorf("write request: %w", err)
 	}
 
-	// Read response
-	reader := bufio.NewReader(conn)
-	line, err := reader.ReadBytes('\n')
-	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
-	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now().Add(cancelWait))
+		cancelReq, _ := json.Marshal(protocol.NewRequest(protocol.CmdCancel, nil))
+		conn.Write(append(cancelReq, '\n'))
+	})
+	defer stop()
 
+	// Read response, after any progress responses
+	reader := bufio.NewReader(conn)
 	var resp protocol.Response
-	if err := json.Unmarshal(line, &resp); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read response: %w", err)
+		}
+
+		resp = protocol.Response{}
+		if err := json.Unmarshal(line, &resp); err != nil {
+			return nil, fmt.Errorf("unmarshal response: %w", err)
+		}
+		if resp.Status != protocol.StatusProgress {
+			break
+		}
+		if progress != nil {
+			progress(&resp)
+		}
+	}
+	if resp.ErrorCode == protocol.ErrCodeUnauthorized {
+		if c.network == "unix" {
+			return nil, ErrPeerRejected
+		}
+		return nil, ErrUnauthorized
+	}
+	if resp.Version != protocol.Version {
+		return nil, &VersionMismatchError{Client: protocol.Version, Daemon: resp.Version}
 	}
 
 	return &resp, nil
 }
 
+// dial connects to the daemon, retrying once if the connect failed for a
+// transient reason.
+func (c *Client) dial(ctx context.Context) (net.Conn, error) {
+	d := net.Dialer{Timeout: c.timeouts.Dial}
+	if d.Timeout == 0 {
+		d.Timeout = DefaultDialTimeout
+	}
+	dial := d.DialContext
+	if c.tls != nil {
+		dial = (&tls.Dialer{NetDialer: &d, Config: c.tls}).DialContext
+	}
+	conn, err := dial(ctx, c.network, c.address)
+	if err == nil || !isTransient(err) {
+		return conn, err
+	}
+	select {
+	case <-time.After(retryDelay):
+	case <-ctx.Done():
+		return nil, err
+	}
+	return dial(ctx, c.network, c.address)
+}
+
+// isTransient reports whether a connect failure may succeed on retry: the
+// daemon's accept queue was full or the connection was reset. A missing
+// socket or refused connection means the daemon is not running.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// Hello returns the daemon's protocol version. Unlike other requests it
+// succeeds when the versions differ; daemons that predate versioning report 0.
+func (c *Client) Hello(ctx context.Context) (int, error) {
+	resp, err := c.Send(ctx, protocol.NewRequest(protocol.CmdHello, nil))
+	var mismatch *VersionMismatchError
+	if errors.As(err, &mismatch) {
+		return mismatch.Daemon, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var data protocol.HelloData
+	if err := resp.Decode(&data); err != nil {
+		return 0, err
+	}
+	return data.Version, nil
+}
+
 // Status sends a status request to the daemon.
-func (c *Client) Status() (*protocol.Response, error) {
-	return c.Send(protocol.NewRequest(protocol.CmdStatus, nil))
+func (c *Client) Status(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdStatus, nil))
+}
+
+// LoadOptions holds optional parameters for a load request.
+type LoadOptions struct {
+	DraftModel  string // draft model override (f:/path or h:org/repo:quant)
+	LlamaServer string // llama-server binary override (command name or path)
+	DryRun      bool   // resolve and return the llama-server command without starting it
+	Watch       bool   // reload the preset when its file changes
+	Strict      bool   // refuse a ctx-size beyond the model's trained context length
+
+	// Progress, if set, is called with each phase the daemon reports while
+	// it loads. Daemons from before progress reporting report none.
+	Progress func(protocol.LoadProgressData)
 }
 
-// Load sends a load request to the daemon.
-func (c *Client) Load(identifier string) (*protocol.Response, error) {
-	return c.Send(protocol.NewRequest(protocol.CmdLoad, map[string]any{
+// Load sends a load request to the daemon and waits until the model is
+// ready. Canceling ctx cancels the load on the daemon.
+func (c *Client) Load(ctx context.Context, identifier string, opts LoadOptions) (*protocol.Response, error) {
+	args := map[string]any{
 		"identifier": identifier,
-	}))
+	}
+	if opts.DraftModel != "" {
+		args["draft_model"] = opts.DraftModel
+	}
+	if opts.LlamaServer != "" {
+		args["llama_server"] = opts.LlamaServer
+	}
+	if opts.DryRun {
+		args["dry_run"] = true
+	}
+	if opts.Watch {
+		args["watch"] = true
+	}
+	if opts.Strict {
+		args["strict"] = true
+	}
+	if opts.Progress == nil {
+		return c.send(ctx, protocol.NewRequest(protocol.CmdLoad, args), 0)
+	}
+	args["progress"] = true
+	return c.sendWithProgress(ctx, protocol.NewRequest(protocol.CmdLoad, args), 0, func(resp *protocol.Response) {
+		var data protocol.LoadProgressData
+		if resp.Decode(&data) == nil {
+			opts.Progress(data)
+		}
+	})
+}
+
+// UnloadOptions holds optional parameters for an unload request.
+type UnloadOptions struct {
+	Force bool // SIGKILL llama-server instead of waiting for it to exit
 }
 
 // Unload sends an unload request to the daemon.
-func (c *Client) Unload() (*protocol.Response, error) {
-	return c.Send(protocol.NewRequest(protocol.CmdUnload, nil))
+func (c *Client) Unload(ctx context.Context, opts UnloadOptions) (*protocol.Response, error) {
+	var args map[string]any
+	if opts.Force {
+		args = map[string]any{"force": true}
+	}
+	return c.send(ctx, protocol.NewRequest(protocol.CmdUnload, args), 0)
+}
+
+// Cancel asks the daemon to abort the load in progress.
+func (c *Client) Cancel(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdCancel, nil))
+}
+
+// ListPresets requests the names of the daemon's presets.
+func (c *Client) ListPresets(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdListPresets, nil))
+}
+
+// ListModels requests the daemon's downloaded models.
+func (c *Client) ListModels(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdListModels, nil))
+}
+
+// Complete requests the preset names, models, aliases and router model
+// names identifiers can be completed to.
+func (c *Client) Complete(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdComplete, nil))
+}
+
+// Ps requests the llama-server processes the daemon runs.
+func (c *Client) Ps(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdPs, nil))
+}
+
+// Stats sends a usage statistics request to the daemon.
+func (c *Client) Stats(ctx context.Context) (*protocol.Response, error) {
+	return c.Send(ctx, protocol.NewRequest(protocol.CmdStats, nil))
 }
@@ -2,11 +2,16 @@ package client
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"net"
 	"os"
 	"path/filepath"
+	"slices"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/d2verb/alpaca/internal/protocol"
 )
@@ -69,19 +74,159 @@ func TestNew(t *testing.T) {
 	if client == nil {
 		t.Fatal("New() returned nil")
 	}
-	if client.socketPath != "/tmp/test.sock" {
-		t.Errorf("socketPath = %q, want %q", client.socketPath, "/tmp/test.sock")
+	if client.network != "unix" || client.address != "/tmp/test.sock" {
+		t.Errorf("network/address = %q/%q, want %q/%q", client.network, client.address, "unix", "/tmp/test.sock")
+	}
+}
+
+// testTCPServer creates a TCP server for testing remote clients.
+// Returns the listen address.
+func testTCPServer(t *testing.T, handler func(req *protocol.Request) *protocol.Response) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return // Server closed
+			}
+			go handleTestConnection(conn, handler)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return listener.Addr().String()
+}
+
+// decodeData decodes the data of resp into a T.
+func decodeData[T any](t *testing.T, resp *protocol.Response) T {
+	t.Helper()
+	var data T
+	if err := resp.Decode(&data); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return data
+}
+
+func TestNewRemote_SendsToken(t *testing.T) {
+	// Arrange
+	addr := testTCPServer(t, func(req *protocol.Request) *protocol.Response {
+		if req.Token != "secret" {
+			t.Errorf("token = %q, want %q", req.Token, "secret")
+		}
+		return protocol.NewOKResponse(protocol.StatusData{State: "idle"})
+	})
+	client := NewRemote(addr, "secret")
+
+	// Act
+	resp, err := client.Status(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if data := decodeData[protocol.StatusData](t, resp); data.State != "idle" {
+		t.Errorf("state = %v, want idle", data.State)
+	}
+}
+
+func TestNewRemote_Unauthorized(t *testing.T) {
+	// Arrange
+	addr := testTCPServer(t, func(req *protocol.Request) *protocol.Response {
+		return protocol.NewErrorResponseWithCode(protocol.ErrCodeUnauthorized, "unauthorized")
+	})
+	client := NewRemote(addr, "wrong")
+
+	// Act
+	_, err := client.Status(context.Background())
+
+	// Assert
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Status() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestNew_PeerRejected(t *testing.T) {
+	// Arrange
+	socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+		return protocol.NewErrorResponseWithCode(protocol.ErrCodeUnauthorized, "unauthorized")
+	})
+	client := New(socketPath)
+
+	// Act
+	_, err := client.Status(context.Background())
+
+	// Assert
+	if !errors.Is(err, ErrPeerRejected) {
+		t.Fatalf("Status() error = %v, want ErrPeerRejected", err)
+	}
+}
+
+func TestClient_VersionMismatch(t *testing.T) {
+	// Arrange
+	socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+		return &protocol.Response{Status: protocol.StatusOK} // daemon before versioning
+	})
+	client := New(socketPath)
+
+	// Act
+	_, err := client.Status(context.Background())
+
+	// Assert
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Status() error = %v, want VersionMismatchError", err)
+	}
+	if mismatch.Client != protocol.Version || mismatch.Daemon != 0 {
+		t.Errorf("mismatch = %+v, want client %d, daemon 0", mismatch, protocol.Version)
+	}
+}
+
+func TestClient_Hello(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *protocol.Response
+		want int
+	}{
+		{"same version", protocol.NewOKResponse(protocol.HelloData{Version: protocol.Version}), protocol.Version},
+		{"newer daemon", &protocol.Response{Status: protocol.StatusOK, Version: protocol.Version + 1}, protocol.Version + 1},
+		{"daemon before versioning", &protocol.Response{Status: protocol.StatusError, Error: "unknown command"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+				return tt.resp
+			})
+
+			// Act
+			got, err := New(socketPath).Hello(context.Background())
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Hello() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Hello() = %d, want %d", got, tt.want)
+			}
+		})
 	}
 }
 
 func TestClient_Send(t *testing.T) {
 	t.Run("successful request/response", func(t *testing.T) {
 		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
-			return protocol.NewOKResponse(map[string]any{"received": req.Command})
+			return protocol.NewOKResponse(protocol.StatusData{State: req.Command})
 		})
 
 		client := New(socketPath)
-		resp, err := client.Send(protocol.NewRequest("test", nil))
+		resp, err := client.Send(context.Background(), protocol.NewRequest("test", nil))
 
 		if err != nil {
 			t.Fatalf("Send() error = %v", err)
@@ -89,14 +234,14 @@ func TestClient_Send(t *testing.T) {
 		if resp.Status != protocol.StatusOK {
 			t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 		}
-		if resp.Data["received"] != "test" {
-			t.Errorf("Data[received] = %v, want %q", resp.Data["received"], "test")
+		if data := decodeData[protocol.StatusData](t, resp); data.State != "test" {
+			t.Errorf("state = %v, want %q", data.State, "test")
 		}
 	})
 
 	t.Run("connection refused when server not running", func(t *testing.T) {
 		client := New("/tmp/nonexistent.sock")
-		_, err := client.Send(protocol.NewRequest("test", nil))
+		_, err := client.Send(context.Background(), protocol.NewRequest("test", nil))
 
 		if err == nil {
 			t.Error("Send() expected error when server not running")
@@ -110,40 +255,41 @@ func TestClient_Status(t *testing.T) {
 			if req.Command != protocol.CmdStatus {
 				t.Errorf("expected status command, got %q", req.Command)
 			}
-			return protocol.NewOKResponse(map[string]any{"state": "idle"})
+			return protocol.NewOKResponse(protocol.StatusData{State: "idle"})
 		})
 
 		client := New(socketPath)
-		resp, err := client.Status()
+		resp, err := client.Status(context.Background())
 
 		if err != nil {
 			t.Fatalf("Status() error = %v", err)
 		}
-		if resp.Data["state"] != "idle" {
-			t.Errorf("state = %v, want %q", resp.Data["state"], "idle")
+		if data := decodeData[protocol.StatusData](t, resp); data.State != "idle" {
+			t.Errorf("state = %v, want %q", data.State, "idle")
 		}
 	})
 
 	t.Run("returns running state with preset info", func(t *testing.T) {
 		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
-			return protocol.NewOKResponse(map[string]any{
-				"state":    "running",
-				"preset":   "codellama-7b",
-				"endpoint": "http://localhost:8080",
+			return protocol.NewOKResponse(protocol.StatusData{
+				State:    "running",
+				Preset:   "codellama-7b",
+				Endpoint: "http://localhost:8080",
 			})
 		})
 
 		client := New(socketPath)
-		resp, err := client.Status()
+		resp, err := client.Status(context.Background())
 
 		if err != nil {
 			t.Fatalf("Status() error = %v", err)
 		}
-		if resp.Data["state"] != "running" {
-			t.Errorf("state = %v, want %q", resp.Data["state"], "running")
+		data := decodeData[protocol.StatusData](t, resp)
+		if data.State != "running" {
+			t.Errorf("state = %v, want %q", data.State, "running")
 		}
-		if resp.Data["preset"] != "codellama-7b" {
-			t.Errorf("preset = %v, want %q", resp.Data["preset"], "codellama-7b")
+		if data.Preset != "codellama-7b" {
+			t.Errorf("preset = %v, want %q", data.Preset, "codellama-7b")
 		}
 	})
 }
@@ -161,11 +307,11 @@ func TestClient_Load(t *testing.T) {
 			if id != "p:my-preset" {
 				t.Errorf("identifier = %q, want %q", id, "p:my-preset")
 			}
-			return protocol.NewOKResponse(map[string]any{"endpoint": "http://localhost:8080"})
+			return protocol.NewOKResponse(protocol.LoadData{Endpoint: "http://localhost:8080"})
 		})
 
 		client := New(socketPath)
-		resp, err := client.Load("p:my-preset")
+		resp, err := client.Load(context.Background(), "p:my-preset", LoadOptions{})
 
 		if err != nil {
 			t.Fatalf("Load() error = %v", err)
@@ -173,12 +319,88 @@ func TestClient_Load(t *testing.T) {
 		if resp.Status != protocol.StatusOK {
 			t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 		}
-		if resp.Data["endpoint"] != "http://localhost:8080" {
-			t.Errorf("endpoint = %v, want %q", resp.Data["endpoint"], "http://localhost:8080")
+		if data := decodeData[protocol.LoadData](t, resp); data.Endpoint != "http://localhost:8080" {
+			t.Errorf("endpoint = %v, want %q", data.Endpoint, "http://localhost:8080")
+		}
+	})
+
+	t.Run("sends draft model override", func(t *testing.T) {
+		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+			draft, _ := req.Args["draft_model"].(string)
+			if draft != "h:org/draft:Q4_K_M" {
+				t.Errorf("draft_model = %q, want %q", draft, "h:org/draft:Q4_K_M")
+			}
+			return protocol.NewOKResponse(nil)
+		})
+
+		client := New(socketPath)
+		if _, err := client.Load(context.Background(), "h:org/model:Q4_K_M", LoadOptions{DraftModel: "h:org/draft:Q4_K_M"}); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+	})
+
+	t.Run("sends watch", func(t *testing.T) {
+		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+			if watch, _ := req.Args["watch"].(bool); !watch {
+				t.Errorf("watch = %v, want true", req.Args["watch"])
+			}
+			return protocol.NewOKResponse(nil)
+		})
+
+		client := New(socketPath)
+		if _, err := client.Load(context.Background(), "f:/work/preset.yaml", LoadOptions{Watch: true}); err != nil {
+			t.Fatalf("Load() error = %v", err)
 		}
 	})
 }
 
+func TestClient_LoadProgress(t *testing.T) {
+	// Arrange
+	socketPath := filepath.Join("/tmp", "alpaca-test-"+filepath.Base(t.TempDir())+".sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		os.Remove(socketPath)
+	})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadBytes('\n')
+		var req protocol.Request
+		json.Unmarshal(line, &req)
+		if progress, _ := req.Args["progress"].(bool); progress {
+			for _, phase := range []string{protocol.LoadPhaseResolving, protocol.LoadPhaseStarting} {
+				data, _ := json.Marshal(protocol.NewProgressResponse(protocol.LoadProgressData{Phase: phase}))
+				conn.Write(append(data, '\n'))
+			}
+		}
+		data, _ := json.Marshal(protocol.NewOKResponse(protocol.LoadData{Endpoint: "http://localhost:8080"}))
+		conn.Write(append(data, '\n'))
+	}()
+	var phases []string
+	opts := LoadOptions{Progress: func(p protocol.LoadProgressData) { phases = append(phases, p.Phase) }}
+
+	// Act
+	resp, err := New(socketPath).Load(context.Background(), "p:test", opts)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if data := decodeData[protocol.LoadData](t, resp); data.Endpoint != "http://localhost:8080" {
+		t.Errorf("endpoint = %q, want the final response", data.Endpoint)
+	}
+	if want := []string{protocol.LoadPhaseResolving, protocol.LoadPhaseStarting}; !slices.Equal(phases, want) {
+		t.Errorf("phases = %q, want %q", phases, want)
+	}
+}
+
 func TestClient_Unload(t *testing.T) {
 	t.Run("sends unload command", func(t *testing.T) {
 		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
@@ -192,7 +414,7 @@ func TestClient_Unload(t *testing.T) {
 		})
 
 		client := New(socketPath)
-		resp, err := client.Unload()
+		resp, err := client.Unload(context.Background(), UnloadOptions{})
 
 		if err != nil {
 			t.Fatalf("Unload() error = %v", err)
@@ -202,3 +424,176 @@ func TestClient_Unload(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_UnloadForce(t *testing.T) {
+	socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+		if force, _ := req.Args["force"].(bool); !force {
+			t.Errorf("args = %v, want force=true", req.Args)
+		}
+		return protocol.NewOKResponse(protocol.UnloadData{Graceful: false})
+	})
+
+	client := New(socketPath)
+	if _, err := client.Unload(context.Background(), UnloadOptions{Force: true}); err != nil {
+		t.Fatalf("Unload() error = %v", err)
+	}
+}
+
+func TestClient_Ps(t *testing.T) {
+	// Arrange
+	socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+		if req.Command != protocol.CmdPs {
+			t.Errorf("command = %q, want %q", req.Command, protocol.CmdPs)
+		}
+		return protocol.NewOKResponse(protocol.PsData{Processes: []protocol.LlamaProcessData{}})
+	})
+	client := New(socketPath)
+
+	// Act
+	resp, err := client.Ps(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Ps() error = %v", err)
+	}
+	if resp.Status != protocol.StatusOK {
+		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+	}
+}
+
+func TestClient_Stats(t *testing.T) {
+	t.Run("sends stats command", func(t *testing.T) {
+		socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+			if req.Command != protocol.CmdStats {
+				t.Errorf("command = %q, want %q", req.Command, protocol.CmdStats)
+			}
+			return protocol.NewOKResponse(protocol.ListModelsData{Models: []protocol.ModelData{}})
+		})
+
+		client := New(socketPath)
+		resp, err := client.Stats(context.Background())
+
+		if err != nil {
+			t.Fatalf("Stats() error = %v", err)
+		}
+		if resp.Status != protocol.StatusOK {
+			t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+		}
+	})
+}
+
+func TestClient_Lists(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		send    func(*Client) (*protocol.Response, error)
+	}{
+		{"presets", protocol.CmdListPresets, func(c *Client) (*protocol.Response, error) { return c.ListPresets(context.Background()) }},
+		{"models", protocol.CmdListModels, func(c *Client) (*protocol.Response, error) { return c.ListModels(context.Background()) }},
+		{"completions", protocol.CmdComplete, func(c *Client) (*protocol.Response, error) { return c.Complete(context.Background()) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+				if req.Command != tt.command {
+					t.Errorf("command = %q, want %q", req.Command, tt.command)
+				}
+				return protocol.NewOKResponse(map[string]any{tt.name: []any{}})
+			})
+
+			// Act
+			resp, err := tt.send(New(socketPath))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if resp.Status != protocol.StatusOK {
+				t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+			}
+		})
+	}
+}
+
+func TestClient_Cancel(t *testing.T) {
+	socketPath := testServer(t, func(req *protocol.Request) *protocol.Response {
+		if req.Command != protocol.CmdCancel {
+			t.Errorf("command = %q, want %q", req.Command, protocol.CmdCancel)
+		}
+		return protocol.NewOKResponse(protocol.CancelData{Canceled: true})
+	})
+
+	client := New(socketPath)
+	resp, err := client.Cancel(context.Background())
+
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if data := decodeData[protocol.CancelData](t, resp); !data.Canceled {
+		t.Errorf("canceled = %v, want true", data.Canceled)
+	}
+}
+
+func TestClient_SendTimeoutSendsCancel(t *testing.T) {
+	// Arrange
+	canceled := make(chan string, 1)
+	socketPath := filepath.Join("/tmp", "alpaca-test-"+filepath.Base(t.TempDir())+".sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		os.Remove(socketPath)
+	})
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reader.ReadBytes('\n') // the request, never answered
+		line, _ := reader.ReadBytes('\n')
+		var req protocol.Request
+		json.Unmarshal(line, &req)
+		canceled <- req.Command
+		conn.Write([]byte(`{"status":"error","error":"load canceled"}` + "\n"))
+	}()
+	client := New(socketPath)
+	client.SetTimeouts(Timeouts{Request: 50 * time.Millisecond})
+
+	// Act
+	_, err = client.Load(context.Background(), "p:slow", LoadOptions{})
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Load() error = %v, want context.DeadlineExceeded", err)
+	}
+	if got := <-canceled; got != protocol.CmdCancel {
+		t.Errorf("second message = %q, want %q", got, protocol.CmdCancel)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"accept queue full", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.EAGAIN)}, true},
+		{"connection reset", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNRESET)}, true},
+		{"daemon not running", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, false},
+		{"no socket", &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ENOENT)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,14 +1,33 @@
-// Package config handles Alpaca path configuration.
+// Package config handles Alpaca path and user configuration.
 package config
 
 import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+
+	"github.com/d2verb/alpaca/internal/pathutil"
 )
 
+// ProfileEnv names the profile GetPaths returns the paths of. The CLI sets
+// it from --profile, so a daemon it starts inherits the profile.
+const ProfileEnv = "ALPACA_PROFILE"
+
+// profileNamePattern keeps profile names usable as a directory name.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateProfile checks that name can be used as a profile name.
+func ValidateProfile(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s': use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
 // Paths holds common paths used by Alpaca.
 type Paths struct {
+	Profile      string // empty for the default profile
 	Home         string
 	Socket       string
 	PID          string
@@ -17,31 +36,83 @@ type Paths struct {
 	Logs         string
 	DaemonLog    string
 	LlamaLog     string
+	RequestLog   string
+	RunLogs      string
 	RouterConfig string
+	TempFiles    string
+	Config       string
+	RemoteToken  string
+	State        string
+	ServerRecord string
+	Cache        string
 }
 
-// GetPaths returns the paths for the current user.
+// GetPaths returns the paths for the current user and the profile named
+// by ALPACA_PROFILE.
 func GetPaths() (*Paths, error) {
+	return GetProfilePaths(os.Getenv(ProfileEnv))
+}
+
+// GetProfilePaths returns the paths of profile. The default profile (empty
+// name) lives in ~/.alpaca; a named profile keeps everything but models in
+// ~/.alpaca/profiles/<name>, so its daemon runs beside the others. Models
+// are shared with the default profile.
+func GetProfilePaths(profile string) (*Paths, error) {
+	if profile != "" {
+		if err := ValidateProfile(profile); err != nil {
+			return nil, err
+		}
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("get user home dir: %w", err)
 	}
 
-	alpacaHome := filepath.Join(home, ".alpaca")
+	rootHome := filepath.Join(home, ".alpaca")
+	alpacaHome := rootHome
+	if profile != "" {
+		alpacaHome = filepath.Join(rootHome, "profiles", profile)
+	}
 	logsDir := filepath.Join(alpacaHome, "logs")
 	return &Paths{
+		Profile:      profile,
 		Home:         alpacaHome,
 		Socket:       filepath.Join(alpacaHome, "alpaca.sock"),
 		PID:          filepath.Join(alpacaHome, "alpaca.pid"),
 		Presets:      filepath.Join(alpacaHome, "presets"),
-		Models:       filepath.Join(alpacaHome, "models"),
+		Models:       filepath.Join(rootHome, "models"),
 		Logs:         logsDir,
 		DaemonLog:    filepath.Join(logsDir, "daemon.log"),
 		LlamaLog:     filepath.Join(logsDir, "llama.log"),
+		RequestLog:   filepath.Join(logsDir, "requests.log"),
+		RunLogs:      filepath.Join(logsDir, "runs"),
 		RouterConfig: filepath.Join(alpacaHome, "router-config.ini"),
+		Config:       filepath.Join(alpacaHome, "config.yaml"),
+		RemoteToken:  filepath.Join(alpacaHome, "remote-token"),
+		State:        filepath.Join(alpacaHome, "state.json"),
+		ServerRecord: filepath.Join(alpacaHome, "llama-server.json"),
+		TempFiles:    filepath.Join(alpacaHome, "temp-files.json"),
+		Cache:        filepath.Join(alpacaHome, "cache"),
 	}, nil
 }
 
+// ApplyConfig adjusts the paths to the settings of cfg: socket-dir moves
+// the socket, and share-models: false gives a named profile its own models
+// directory.
+func (p *Paths) ApplyConfig(cfg *Config) error {
+	if cfg.SocketDir != "" {
+		dir, err := pathutil.ResolvePath(cfg.SocketDir, p.Home)
+		if err != nil {
+			return fmt.Errorf("resolve socket-dir: %w", err)
+		}
+		p.Socket = filepath.Join(dir, "alpaca.sock")
+	}
+	if p.Profile != "" && cfg.ShareModels != nil && !*cfg.ShareModels {
+		p.Models = filepath.Join(p.Home, "models")
+	}
+	return nil
+}
+
 // EnsureDirectories creates the required directories if they don't exist.
 func (p *Paths) EnsureDirectories() error {
 	dirs := []string{p.Home, p.Presets, p.Models, p.Logs}
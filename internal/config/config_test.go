@@ -31,6 +31,11 @@ func TestGetPaths(t *testing.T) {
 		{"DaemonLog", paths.DaemonLog, filepath.Join(logsDir, "daemon.log")},
 		{"LlamaLog", paths.LlamaLog, filepath.Join(logsDir, "llama.log")},
 		{"RouterConfig", paths.RouterConfig, filepath.Join(alpacaHome, "router-config.ini")},
+		{"Config", paths.Config, filepath.Join(alpacaHome, "config.yaml")},
+		{"RemoteToken", paths.RemoteToken, filepath.Join(alpacaHome, "remote-token")},
+		{"State", paths.State, filepath.Join(alpacaHome, "state.json")},
+		{"ServerRecord", paths.ServerRecord, filepath.Join(alpacaHome, "llama-server.json")},
+		{"TempFiles", paths.TempFiles, filepath.Join(alpacaHome, "temp-files.json")},
 	}
 
 	for _, tt := range tests {
@@ -114,3 +119,77 @@ func TestPaths_EnsureDirectories(t *testing.T) {
 		t.Errorf("EnsureDirectories() second call error = %v", err)
 	}
 }
+
+func TestGetProfilePaths(t *testing.T) {
+	// Arrange
+	home, _ := os.UserHomeDir()
+	profileHome := filepath.Join(home, ".alpaca", "profiles", "cuda")
+
+	// Act
+	paths, err := GetProfilePaths("cuda")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetProfilePaths() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Profile", paths.Profile, "cuda"},
+		{"Home", paths.Home, profileHome},
+		{"Socket", paths.Socket, filepath.Join(profileHome, "alpaca.sock")},
+		{"PID", paths.PID, filepath.Join(profileHome, "alpaca.pid")},
+		{"Presets", paths.Presets, filepath.Join(profileHome, "presets")},
+		{"Models", paths.Models, filepath.Join(home, ".alpaca", "models")}, // shared
+		{"DaemonLog", paths.DaemonLog, filepath.Join(profileHome, "logs", "daemon.log")},
+		{"Config", paths.Config, filepath.Join(profileHome, "config.yaml")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPaths_ProfileFromEnv(t *testing.T) {
+	// Arrange
+	t.Setenv(ProfileEnv, "cpu")
+
+	// Act
+	paths, err := GetPaths()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetPaths() error = %v", err)
+	}
+	if paths.Profile != "cpu" || filepath.Base(paths.Home) != "cpu" {
+		t.Errorf("GetPaths() = profile %q, home %q, want the cpu profile", paths.Profile, paths.Home)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"cuda", false},
+		{"gpu-1_fast", false},
+		{"", true},
+		{"../escape", true},
+		{"a/b", true},
+		{"-flag", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfile(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
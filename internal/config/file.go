@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/schedule"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds user settings read from ~/.alpaca/config.yaml.
+// All fields are optional; a missing file yields the zero Config.
+type Config struct {
+	// SchemaVersion is the format version of the file, maintained by
+	// alpaca; see Migrate.
+	SchemaVersion int `yaml:"schema-version,omitempty"`
+
+	// DraftModels maps a HuggingFace repo (org/repo) to the draft model
+	// identifier used by `alpaca load --draft auto`.
+	DraftModels map[string]string `yaml:"draft-models,omitempty"`
+
+	// HFEndpoint replaces https://huggingface.co for downloads
+	// (e.g. https://hf-mirror.com or a corporate proxy).
+	HFEndpoint string `yaml:"hf-endpoint,omitempty"`
+
+	// HFMirrors are fallback endpoints tried in order when the primary
+	// endpoint is unreachable or returns a server error.
+	HFMirrors []string `yaml:"hf-mirrors,omitempty"`
+
+	// DownloadAttempts is how many times a download is tried before it
+	// fails on network and server errors. Defaults to 4; 1 disables retries.
+	DownloadAttempts int `yaml:"download-attempts,omitempty"`
+
+	// DownloadConnectTimeout is how many seconds connecting to a download
+	// server, or the proxy in HTTPS_PROXY, may take. Defaults to 30.
+	DownloadConnectTimeout int `yaml:"download-connect-timeout,omitempty"`
+
+	// DownloadTLSMinVersion is the oldest TLS version downloads accept,
+	// "1.2" (the default) or "1.3".
+	DownloadTLSMinVersion string `yaml:"download-tls-min-version,omitempty"`
+
+	// DownloadCABundle is a PEM file of certificate authorities trusted for
+	// downloads in addition to the system's, e.g. the one a TLS-intercepting
+	// corporate proxy signs with. Absolute, or relative to the home directory
+	// with ~/.
+	DownloadCABundle string `yaml:"download-ca-bundle,omitempty"`
+
+	// DownloadMaxIdleConns is how many idle connections downloads keep open
+	// for reuse. Defaults to 100.
+	DownloadMaxIdleConns int `yaml:"download-max-idle-conns,omitempty"`
+
+	// AutoQuantHeadroomGB is memory in GB kept free when choosing a quant
+	// for org/repo:auto (context, KV cache, other apps). Defaults to 2.
+	AutoQuantHeadroomGB *float64 `yaml:"auto-quant-headroom-gb,omitempty"`
+
+	// RestoreLastModel reloads the last successfully loaded model when the
+	// daemon starts (e.g. after a reboot via the service unit).
+	RestoreLastModel bool `yaml:"restore-last-model,omitempty"`
+
+	// LlamaServerPath is the llama-server binary used when a preset does
+	// not set its own. Defaults to llama-server from PATH.
+	LlamaServerPath string `yaml:"llama-server-path,omitempty"`
+
+	// StartupTimeout is how many seconds llama-server may take to become
+	// ready before the load fails. Presets may override it. Defaults to 60.
+	StartupTimeout int `yaml:"startup-timeout,omitempty"`
+
+	// UnloadTimeout is how many seconds llama-server may take to exit after
+	// SIGTERM before it is killed. Defaults to 10.
+	UnloadTimeout int `yaml:"unload-timeout,omitempty"`
+
+	// RunLogs also writes each llama-server run's output to its own file
+	// in logs/runs, next to the combined llama.log.
+	RunLogs bool `yaml:"run-logs,omitempty"`
+
+	// RunLogsKeep is how many run log files are kept; older ones are
+	// deleted when a run starts. Defaults to 20.
+	RunLogsKeep int `yaml:"run-logs-keep,omitempty"`
+
+	// VerifySignatures refuses to load model files whose SHA256 is not
+	// listed in the signed ModelManifest.
+	VerifySignatures bool `yaml:"verify-signatures,omitempty"`
+
+	// ModelManifest is the URL or path of the approved-hash manifest. Its
+	// Ed25519 signature is read from the same location with ".sig" appended.
+	ModelManifest string `yaml:"model-manifest,omitempty"`
+
+	// ManifestPublicKey is the hex-encoded Ed25519 key that signs ModelManifest.
+	ManifestPublicKey string `yaml:"manifest-public-key,omitempty"`
+
+	// Hooks are run by the daemon around model loads. Presets may
+	// override individual hooks.
+	Hooks preset.Hooks `yaml:"hooks,omitempty"`
+
+	// SocketDir is the directory holding the daemon's Unix socket.
+	// Defaults to ~/.alpaca. Relative paths are resolved against ~/.alpaca.
+	SocketDir string `yaml:"socket-dir,omitempty"`
+
+	// ShareModels keeps a named profile's models in ~/.alpaca/models, shared
+	// with the default profile. Defaults to true; false gives the profile
+	// its own models directory. Ignored by the default profile.
+	ShareModels *bool `yaml:"share-models,omitempty"`
+
+	// UpdateCheck lets `alpaca status` check GitHub once a day for a newer
+	// alpaca release and mention it. Defaults to true.
+	UpdateCheck *bool `yaml:"update-check,omitempty"`
+
+	// PartialMaxAgeDays is how many days a partial download or leftover
+	// temporary file in the models directory may go unwritten before the
+	// daemon deletes it when it starts. Defaults to 30; 0 keeps them.
+	PartialMaxAgeDays *int `yaml:"partial-max-age-days,omitempty"`
+
+	// MaxRequests is how many requests, other than loads, unloads and
+	// cancels, the daemon handles at once; more wait briefly, then fail as
+	// busy. Defaults to 16.
+	MaxRequests int `yaml:"max-requests,omitempty"`
+
+	// AllowUsers are user names or numeric IDs, besides the daemon's owner
+	// and root, that may use the daemon through its socket.
+	AllowUsers []string `yaml:"allow-users,omitempty"`
+
+	// Schedule loads and unloads models at set times while the daemon
+	// runs; see `alpaca schedule`.
+	Schedule []schedule.Entry `yaml:"schedule,omitempty"`
+}
+
+// DefaultAutoQuantHeadroomGB is the headroom used when none is configured.
+const DefaultAutoQuantHeadroomGB = 2.0
+
+// AutoQuantHeadroom returns the configured headroom in bytes.
+func (c *Config) AutoQuantHeadroom() int64 {
+	gb := DefaultAutoQuantHeadroomGB
+	if c.AutoQuantHeadroomGB != nil {
+		gb = *c.AutoQuantHeadroomGB
+	}
+	return int64(gb * (1 << 30))
+}
+
+// DefaultPartialMaxAgeDays is the partial download age limit used when
+// none is configured.
+const DefaultPartialMaxAgeDays = 30
+
+// PartialMaxAge returns how long partial downloads are kept, or 0 when
+// they are never deleted automatically.
+func (c *Config) PartialMaxAge() time.Duration {
+	days := DefaultPartialMaxAgeDays
+	if c.PartialMaxAgeDays != nil {
+		days = max(*c.PartialMaxAgeDays, 0)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Load reads the config file at path.
+// Returns an empty Config if the file does not exist.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	// Read older formats as upgraded, until Migrate rewrites the file
+	if doc, err := schema.Decode(data); err == nil && cfg.SchemaVersion < SchemaVersion {
+		if _, err := schema.Upgrade(doc); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+		if err := doc.Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// LoadPathSettings reads only the settings of the config file at path that
+// ApplyConfig uses (socket-dir and share-models). Mistakes in other
+// settings are left for Load to report, so they do not keep commands from
+// finding the daemon and the models.
+func LoadPathSettings(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	var settings struct {
+		SocketDir   string `yaml:"socket-dir"`
+		ShareModels *bool  `yaml:"share-models"`
+	}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return &Config{SocketDir: settings.SocketDir, ShareModels: settings.ShareModels}, nil
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_MissingFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.DraftModels) != 0 {
+		t.Errorf("DraftModels = %v, want empty", cfg.DraftModels)
+	}
+}
+
+func TestLoad_DraftModels(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "draft-models:\n  Qwen/Qwen3-8B-GGUF: \"h:Qwen/Qwen3-0.6B-GGUF:Q8_0\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.DraftModels["Qwen/Qwen3-8B-GGUF"]; got != "h:Qwen/Qwen3-0.6B-GGUF:Q8_0" {
+		t.Errorf("DraftModels[Qwen/Qwen3-8B-GGUF] = %q", got)
+	}
+}
+
+func TestLoad_InvalidYAML(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("draft-models: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	_, err := Load(path)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "parse config") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestLoadPathSettings(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "socket-dir: /run/alpaca\nshare-models: false\ndownload-attempts: many\nhooks: [\"not a map\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg, err := LoadPathSettings(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadPathSettings() error = %v", err)
+	}
+	if cfg.SocketDir != "/run/alpaca" {
+		t.Errorf("SocketDir = %q, want /run/alpaca", cfg.SocketDir)
+	}
+	if cfg.ShareModels == nil || *cfg.ShareModels {
+		t.Errorf("ShareModels = %v, want false", cfg.ShareModels)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() succeeded, want an error for download-attempts")
+	}
+}
+
+func TestLoad_Endpoints(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "hf-endpoint: https://hf-mirror.com\nhf-mirrors:\n  - https://mirror-a.example\n  - https://mirror-b.example\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.HFEndpoint != "https://hf-mirror.com" {
+		t.Errorf("HFEndpoint = %q", cfg.HFEndpoint)
+	}
+	if len(cfg.HFMirrors) != 2 || cfg.HFMirrors[1] != "https://mirror-b.example" {
+		t.Errorf("HFMirrors = %v", cfg.HFMirrors)
+	}
+}
+
+func TestConfig_AutoQuantHeadroom(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int64
+	}{
+		{"default", "", 2 << 30},
+		{"configured", "auto-quant-headroom-gb: 4.5\n", 4.5 * (1 << 30)},
+		{"zero", "auto-quant-headroom-gb: 0\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			cfg, err := Load(path)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := cfg.AutoQuantHeadroom(); got != tt.want {
+				t.Errorf("AutoQuantHeadroom() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_PartialMaxAge(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    time.Duration
+	}{
+		{"default", "", 30 * 24 * time.Hour},
+		{"configured", "partial-max-age-days: 7\n", 7 * 24 * time.Hour},
+		{"disabled", "partial-max-age-days: 0\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			cfg, err := Load(path)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if got := cfg.PartialMaxAge(); got != tt.want {
+				t.Errorf("PartialMaxAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_RestoreLastModel(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("restore-last-model: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !cfg.RestoreLastModel {
+		t.Error("RestoreLastModel = false, want true")
+	}
+}
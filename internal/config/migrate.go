@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/migrate"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the config.yaml format written by this build.
+const SchemaVersion = 1
+
+// schemaVersionKey records the format version in config.yaml.
+const schemaVersionKey = "schema-version"
+
+// schema upgrades config.yaml as a YAML node tree, so comments and key
+// order survive.
+var schema = migrate.Schema[*yaml.Node]{
+	Steps: []func(*yaml.Node) error{
+		prefixDraftModels, // 0 → 1
+	},
+	Decode: func(data []byte) (*yaml.Node, error) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("config is not a mapping")
+		}
+		return &doc, nil
+	},
+	Encode: func(doc *yaml.Node) ([]byte, error) {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	},
+	Version: func(doc *yaml.Node) (int, error) {
+		v := mappingValue(doc.Content[0], schemaVersionKey)
+		if v == nil {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number, got '%s'", schemaVersionKey, v.Value)
+		}
+		return n, nil
+	},
+	SetVersion: func(doc *yaml.Node, version int) {
+		root := doc.Content[0]
+		value := strconv.Itoa(version)
+		if v := mappingValue(root, schemaVersionKey); v != nil {
+			v.Value = value
+			return
+		}
+		// First, so it is not mistaken for part of the last section
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: schemaVersionKey},
+			{Kind: yaml.ScalarNode, Tag: "!!int", Value: value},
+		}, root.Content...)
+	},
+}
+
+// Migrate upgrades the config file at path to SchemaVersion, keeping the
+// original as a backup. It returns nil when there was nothing to upgrade.
+func Migrate(path string) (*migrate.Result, error) {
+	return schema.File(path)
+}
+
+// mappingValue returns the value of key in mapping m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// prefixDraftModels rewrites draft-models from the format before
+// identifier prefixes: repos may no longer carry "h:", and draft models
+// must be h: or f: identifiers.
+func prefixDraftModels(doc *yaml.Node) error {
+	drafts := mappingValue(doc.Content[0], "draft-models")
+	if drafts == nil || drafts.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(drafts.Content); i += 2 {
+		key, value := drafts.Content[i], drafts.Content[i+1]
+		key.Value = strings.TrimPrefix(key.Value, "h:")
+		if value.Kind != yaml.ScalarNode || hasIdentifierPrefix(value.Value) {
+			continue
+		}
+		if strings.HasPrefix(value.Value, "/") || strings.HasPrefix(value.Value, "~") || strings.HasPrefix(value.Value, ".") {
+			value.Value = "f:" + value.Value
+		} else {
+			value.Value = "h:" + value.Value
+		}
+	}
+	return nil
+}
+
+// hasIdentifierPrefix reports whether s starts with h:, p: or f:.
+func hasIdentifierPrefix(s string) bool {
+	return len(s) >= 2 && s[1] == ':' && strings.ContainsRune("hpf", rune(s[0]))
+}
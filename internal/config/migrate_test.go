@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const configV0 = `# Drafts for speculative decoding
+draft-models:
+  h:Qwen/Qwen3-8B-GGUF: Qwen/Qwen3-0.6B-GGUF:Q8_0
+  org/local: /models/draft.gguf
+  org/done: "h:org/draft:Q4_K_M"
+
+hf-endpoint: https://hf-mirror.com
+`
+
+func TestMigrate(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(configV0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	res, err := Migrate(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if res == nil || res.From != 0 || res.To != SchemaVersion {
+		t.Fatalf("Migrate() = %+v, want upgrade from 0", res)
+	}
+	data, _ := os.ReadFile(path)
+	out := string(data)
+	for _, want := range []string{
+		"schema-version: 1\n",
+		"# Drafts for speculative decoding\n",
+		"Qwen/Qwen3-8B-GGUF: h:Qwen/Qwen3-0.6B-GGUF:Q8_0\n",
+		"org/local: f:/models/draft.gguf\n",
+		`org/done: "h:org/draft:Q4_K_M"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("migrated config should contain %q:\n%s", want, out)
+		}
+	}
+
+	// Act: a second run finds nothing to do
+	res, err = Migrate(path)
+
+	// Assert
+	if res != nil || err != nil {
+		t.Errorf("second Migrate() = %+v, %v, want nothing to do", res, err)
+	}
+}
+
+func TestLoad_ReadsOldFormatAsUpgraded(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(configV0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := cfg.DraftModels["Qwen/Qwen3-8B-GGUF"]; got != "h:Qwen/Qwen3-0.6B-GGUF:Q8_0" {
+		t.Errorf("draft model = %q, want the prefixed identifier", got)
+	}
+	if cfg.HFEndpoint != "https://hf-mirror.com" {
+		t.Errorf("HFEndpoint = %q, want the other keys kept", cfg.HFEndpoint)
+	}
+	if got, _ := os.ReadFile(path); string(got) != configV0 {
+		t.Error("Load() should not rewrite the file")
+	}
+}
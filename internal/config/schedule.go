@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/d2verb/alpaca/internal/schedule"
+	"gopkg.in/yaml.v3"
+)
+
+// scheduleKey is the config.yaml key holding the schedule.
+const scheduleKey = "schedule"
+
+// SaveSchedule replaces the schedule in the config file at path, creating
+// the file if needed. Other settings and their comments are kept; an empty
+// schedule removes the key.
+func SaveSchedule(path string, entries []schedule.Entry) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	if len(data) > 0 {
+		if doc, err = schema.Decode(data); err != nil {
+			return fmt.Errorf("parse config %s: %w", path, err)
+		}
+	} else {
+		schema.SetVersion(doc, SchemaVersion)
+	}
+
+	root := doc.Content[0]
+	idx := -1
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == scheduleKey {
+			idx = i
+			break
+		}
+	}
+
+	switch {
+	case len(entries) == 0 && idx >= 0:
+		root.Content = append(root.Content[:idx], root.Content[idx+2:]...)
+	case len(entries) > 0:
+		var value yaml.Node
+		if err := value.Encode(entries); err != nil {
+			return fmt.Errorf("encode schedule: %w", err)
+		}
+		if idx >= 0 {
+			// Keep comments attached to the old value
+			value.HeadComment = root.Content[idx+1].HeadComment
+			root.Content[idx+1] = &value
+		} else {
+			root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: scheduleKey}, &value)
+		}
+	}
+
+	out, err := schema.Encode(doc)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/schedule"
+)
+
+func TestSaveSchedule(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "schema-version: 1\n# Mirror for the office network\nhf-endpoint: https://hf-mirror.com\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries := []schedule.Entry{
+		{Cron: "0 9 * * mon-fri", Load: "p:coder"},
+		{Cron: "0 18 * * mon-fri", Unload: true},
+	}
+
+	// Act
+	err := SaveSchedule(path, entries)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SaveSchedule() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), original) {
+		t.Errorf("other settings should be kept:\n%s", data)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !slices.Equal(cfg.Schedule, entries) {
+		t.Errorf("Schedule = %+v, want %+v", cfg.Schedule, entries)
+	}
+
+	// Act: replace, then remove
+	if err := SaveSchedule(path, entries[1:]); err != nil {
+		t.Fatalf("SaveSchedule() error = %v", err)
+	}
+	cfg, _ = Load(path)
+	if !slices.Equal(cfg.Schedule, entries[1:]) {
+		t.Errorf("Schedule = %+v, want %+v", cfg.Schedule, entries[1:])
+	}
+	if err := SaveSchedule(path, nil); err != nil {
+		t.Fatalf("SaveSchedule() error = %v", err)
+	}
+
+	// Assert
+	data, _ = os.ReadFile(path)
+	if string(data) != original {
+		t.Errorf("config = %q, want %q", data, original)
+	}
+}
+
+func TestSaveSchedule_CreatesFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	// Act
+	err := SaveSchedule(path, []schedule.Entry{{Cron: "0 9 * * *", Load: "p:coder"}})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SaveSchedule() error = %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.SchemaVersion != SchemaVersion || len(cfg.Schedule) != 1 {
+		t.Errorf("config = %+v, want current schema version and one entry", cfg)
+	}
+}
@@ -0,0 +1,68 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+)
+
+// Completions lists what identifiers and model names can be completed to.
+type Completions struct {
+	Presets      []string
+	Models       []string            // identifiers of downloaded models
+	Aliases      []string            // values of the alias option in presets
+	RouterModels map[string][]string // router preset name to its model names
+}
+
+// Completions reads the presets and downloaded models for completion. It
+// takes no lock, so it answers while a model is loading. Presets that
+// cannot be read are left out and reported in the error, as are models
+// when the metadata cannot be read; the lists hold what could be read.
+func (d *Daemon) Completions(ctx context.Context) (Completions, error) {
+	c := Completions{Presets: []string{}, Models: []string{}, Aliases: []string{}, RouterModels: map[string][]string{}}
+	var errs []error
+
+	names, err := d.presets.List()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, name := range names {
+		p, err := d.presets.Load(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("preset %s: %w", name, err))
+			continue
+		}
+		c.Presets = append(c.Presets, name)
+		if alias := p.Options["alias"]; alias != "" {
+			c.Aliases = append(c.Aliases, alias)
+		}
+		if !p.IsRouter() {
+			continue
+		}
+		models := []string{}
+		for _, m := range p.Models {
+			models = append(models, m.Name)
+			if alias := m.Options["alias"]; alias != "" {
+				c.Aliases = append(c.Aliases, alias)
+			}
+		}
+		c.RouterModels[name] = models
+	}
+
+	entries, err := d.models.List(ctx)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("list models: %w", err))
+	}
+	for _, e := range entries {
+		c.Models = append(c.Models, identifier.Model(e.Registry, e.Repo, e.Quant))
+	}
+
+	slices.Sort(c.Presets)
+	slices.Sort(c.Models)
+	slices.Sort(c.Aliases)
+	c.Aliases = slices.Compact(c.Aliases)
+	return c, errors.Join(errs...)
+}
@@ -10,19 +10,25 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/d2verb/alpaca/internal/allowlist"
+	"github.com/d2verb/alpaca/internal/gguf"
 	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/logging"
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/sysinfo"
 )
 
 // presetLoader loads and lists presets.
 type presetLoader interface {
 	Load(name string) (*preset.Preset, error)
+	FindPath(name string) (string, error)
 	List() ([]string, error)
 }
 
@@ -31,19 +37,28 @@ type modelManager interface {
 	List(ctx context.Context) ([]metadata.ModelEntry, error)
 	GetFilePath(ctx context.Context, repo, quant string) (string, error)
 	GetDetails(ctx context.Context, repo, quant string) (*metadata.ModelEntry, error)
+	GetLoraFilePath(ctx context.Context, repo, file string) (string, error)
 }
 
 // llamaProcess manages llama-server process lifecycle.
 type llamaProcess interface {
 	Start(args []string) error
 	Stop(ctx context.Context) error
+	Terminate(ctx context.Context, grace time.Duration) (bool, error)
 	SetLogWriter(w io.Writer)
+	SetPriority(pr llama.Priority)
 	Done() <-chan struct{}
 	ExitErr() error
+	Output() []string
+	LogTail() []string
+	Pid() int
 }
 
 // healthChecker waits for llama-server to become ready.
-type healthChecker func(ctx context.Context, endpoint string) error
+type healthChecker func(ctx context.Context, endpoint string, opts llama.WaitOptions) error
+
+// warmer waits for llama-server to complete a one-token prompt.
+type warmer func(ctx context.Context, endpoint, prompt string) error
 
 // State represents the daemon state.
 type State string
@@ -58,6 +73,10 @@ const (
 // Run/Kill request (generation mismatch), not by caller context cancellation.
 var ErrSuperseded = errors.New("operation superseded by newer request")
 
+// ErrCanceled indicates that the client canceled a Run before the model
+// became ready.
+var ErrCanceled = errors.New("load canceled")
+
 // Daemon manages llama-server lifecycle.
 type Daemon struct {
 	// mu protects the process field and serializes Run/Kill operations.
@@ -74,43 +93,187 @@ type Daemon struct {
 	// state+preset pair with a single load.
 	snapshot atomic.Pointer[daemonSnapshot]
 
+	// invocation is the command line of the running llama-server, written
+	// under mu and read lock-free by status.
+	invocation atomic.Pointer[Invocation]
+
 	process llamaProcess // protected by mu
 
-	presets        presetLoader
-	models         modelManager
-	configPath     string // path for router mode config.ini
-	logger         *slog.Logger
-	llamaLogWriter io.Writer
+	presets    presetLoader
+	models     modelManager
+	configPath string // base path for router mode config.ini; each run writes its own next to it
+	statePath  string // path for the last-load state file; empty disables it
+
+	serverRecordPath string // path for the spawned llama-server record; empty disables it
+	temps            tempFiles
+	logger           *slog.Logger
+	llamaLogWriter   io.Writer
 
-	// startupMu protects cancelStartup.
+	// settings come from config.yaml and may be replaced while the daemon
+	// runs; see runtimeSettings. settingsMu serializes the replacements.
+	settings   atomic.Pointer[runtimeSettings]
+	settingsMu sync.Mutex
+
+	// startupMu protects cancelStartup and cancelRun.
 	// Separate from mu so Kill() can cancel startup without acquiring mu.
 	startupMu     sync.Mutex
 	startupGen    uint64
 	cancelStartup context.CancelFunc
+	runCancelGen  uint64
+	cancelRun     context.CancelFunc // cancels the Run in progress (CancelLoad)
+
+	watchMu   sync.Mutex
+	stopWatch context.CancelFunc // stops the watch of the running preset's file
+
+	slotCacheDir string // holds a directory per preset with slot-save-path: auto
+
+	watchDebounce time.Duration // quiet period before an edited preset is reloaded
 
-	startupTimeout time.Duration
+	stats usageStats // per-model request counters, fed by the proxy
+
+	routerStatus routerStatusCache // last /models answer in router mode
+
+	hookWG sync.WaitGroup // background post-load/post-unload hooks
 
 	// Test hooks (optional, defaults to real implementations)
-	newProcess   func(path string) llamaProcess
-	waitForReady healthChecker
-	httpClient   *http.Client // for FetchModelStatuses
+	newProcess    func(path string) llamaProcess
+	waitForReady  healthChecker
+	warmUp        warmer
+	httpClient    *http.Client                                                          // for FetchModelStatuses
+	validateModel func(path string) error                                               // checks model files before llama-server starts
+	optionSchema  func(ctx context.Context, command string) (llama.OptionSchema, error) // nil skips option checks
+	attachProcess func(pid int) (llamaProcess, error)
+	commandLine   func(pid int) (string, error)
+	detectGPU     func() sysinfo.GPU
+	freeMemory    func() int64
+	portInUse     func(addr string) bool
+	inspectModel  func(path string) (*gguf.Info, error)
+	execHook      func(ctx context.Context, command string, env []string) ([]byte, error)
 }
 
 type daemonSnapshot struct {
 	state  State
 	preset *preset.Preset
+	since  time.Time
+	detail string
 }
 
 // RuntimeStatus is a consistent daemon runtime status view.
 type RuntimeStatus struct {
 	State  State
 	Preset *preset.Preset
+	Since  time.Time // when State (or Preset) last changed
+	Detail string    // what llama-server reports while loading, e.g. "loading model 43%"
+}
+
+// Invocation is how a llama-server is (or would be) started, for
+// reproducing it by hand.
+type Invocation struct {
+	PID       int
+	Command   string
+	Args      []string
+	ConfigINI string // contents of the router config.ini; empty in single mode
+
+	configPath string // the router config.ini llama-server reads; empty in single mode
+}
+
+// Invocation returns how the current llama-server was started, or nil when
+// none is running.
+// This method is lock-free and returns immediately.
+func (d *Daemon) Invocation() *Invocation {
+	return d.invocation.Load()
 }
 
-// llamaServerCommand is the command to run llama-server.
+// newInvocation records command run with args as process pid for p,
+// reading back the router config.ini at configPath llama-server was given.
+func (d *Daemon) newInvocation(p *preset.Preset, pid int, command string, args []string, configPath string) *Invocation {
+	inv := &Invocation{PID: pid, Command: command, Args: args}
+	if p.IsRouter() {
+		inv.configPath = configPath
+		if data, err := os.ReadFile(configPath); err == nil {
+			inv.ConfigINI = string(data)
+		}
+	}
+	return inv
+}
+
+// llamaServerCommand is the default command to run llama-server.
 // It relies on PATH resolution to find the binary.
 const llamaServerCommand = "llama-server"
 
+// SetLlamaServerPath sets the llama-server binary used for presets that do
+// not set llama-server-path. An empty path restores the PATH default.
+func (d *Daemon) SetLlamaServerPath(path string) {
+	d.updateSettings(func(s *runtimeSettings) { s.llamaServerPath = path })
+}
+
+// serverCommand returns the llama-server binary for p.
+func (d *Daemon) serverCommand(p *preset.Preset) string {
+	if p.LlamaServerPath != "" {
+		return p.LlamaServerPath
+	}
+	if path := d.settings.Load().llamaServerPath; path != "" {
+		return path
+	}
+	return llamaServerCommand
+}
+
+// SetAllowList restricts loads to model files listed in the signed
+// manifest of src. nil allows every file.
+func (d *Daemon) SetAllowList(src *allowlist.Source) {
+	d.updateSettings(func(s *runtimeSettings) { s.allowList = src })
+}
+
+// SetOptionSchemas checks preset options against the llama-server option
+// schemas in cache before each load. nil disables the check.
+func (d *Daemon) SetOptionSchemas(cache *llama.SchemaCache) {
+	if cache == nil {
+		d.optionSchema = nil
+		return
+	}
+	d.optionSchema = cache.Load
+}
+
+// SetSlotCacheDir sets the directory that holds the saved slots of presets
+// with slot-save-path: auto, each in a subdirectory named after the preset.
+func (d *Daemon) SetSlotCacheDir(dir string) {
+	d.slotCacheDir = dir
+}
+
+// SetStopGracePeriod sets how long llama-server may take to exit after
+// SIGTERM before it is killed. Zero or less restores the default.
+func (d *Daemon) SetStopGracePeriod(grace time.Duration) {
+	if grace <= 0 {
+		grace = llama.GracefulShutdownTimeout
+	}
+	d.updateSettings(func(s *runtimeSettings) { s.stopGrace = grace })
+}
+
+// SetStartupTimeout sets how long llama-server may take to become ready
+// for presets that do not set startup-timeout. Zero or less restores the
+// default.
+func (d *Daemon) SetStartupTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultStartupTimeout
+	}
+	d.updateSettings(func(s *runtimeSettings) { s.startupTimeout = timeout })
+}
+
+// startupTimeoutFor returns how long llama-server may take to become ready
+// when serving p.
+func (d *Daemon) startupTimeoutFor(p *preset.Preset) time.Duration {
+	if p.StartupTimeout > 0 {
+		return time.Duration(p.StartupTimeout) * time.Second
+	}
+	return d.settings.Load().startupTimeout
+}
+
+// ShutdownTimeout bounds how long the daemon spends stopping llama-server
+// when it exits. llama-server gets SIGKILL if it has not exited by then.
+func (d *Daemon) ShutdownTimeout() time.Duration {
+	return d.settings.Load().stopGrace + 5*time.Second
+}
+
 // defaultStartupTimeout is the maximum time to wait for llama-server to become ready.
 const defaultStartupTimeout = 60 * time.Second
 
@@ -134,11 +297,27 @@ func New(presets presetLoader, models modelManager, configPath string, daemonLog
 		newProcess: func(path string) llamaProcess {
 			return llama.NewProcess(path)
 		},
-		waitForReady:   llama.WaitForReady,
-		httpClient:     &http.Client{},
-		startupTimeout: defaultStartupTimeout,
+		waitForReady:  llama.WaitForReady,
+		warmUp:        llama.WarmUp,
+		httpClient:    &http.Client{},
+		validateModel: gguf.Validate,
+		attachProcess: func(pid int) (llamaProcess, error) {
+			return llama.AttachProcess(pid)
+		},
+		commandLine:   llama.CommandLine,
+		detectGPU:     sysinfo.DetectGPU,
+		freeMemory:    sysinfo.DetectFreeMemory,
+		portInUse:     portInUse,
+		inspectModel:  gguf.InspectModel,
+		execHook:      execHookCommand,
+		watchDebounce: defaultWatchDebounce,
 	}
-	d.snapshot.Store(&daemonSnapshot{state: StateIdle})
+	d.settings.Store(&runtimeSettings{
+		runLogsKeep:    defaultRunLogsKeep,
+		startupTimeout: defaultStartupTimeout,
+		stopGrace:      llama.GracefulShutdownTimeout,
+	})
+	d.snapshot.Store(&daemonSnapshot{state: StateIdle, since: time.Now()})
 	return d
 }
 
@@ -164,16 +343,35 @@ func (d *Daemon) StatusSnapshot() RuntimeStatus {
 	return RuntimeStatus{
 		State:  snap.state,
 		Preset: snap.preset,
+		Since:  snap.since,
+		Detail: snap.detail,
 	}
 }
 
 func (d *Daemon) setSnapshot(state State, p *preset.Preset) {
+	since := time.Now()
+	if prev := d.snapshot.Load(); prev != nil && prev.state == state && prev.preset == p {
+		since = prev.since
+	}
 	d.snapshot.Store(&daemonSnapshot{
 		state:  state,
 		preset: p,
+		since:  since,
 	})
 }
 
+// setLoadingDetail records what llama-server reports while p loads. It
+// does nothing once the load has finished or been superseded.
+func (d *Daemon) setLoadingDetail(p *preset.Preset, detail string) {
+	prev := d.snapshot.Load()
+	if prev == nil || prev.state != StateLoading || prev.preset != p {
+		return
+	}
+	next := *prev
+	next.detail = detail
+	d.snapshot.CompareAndSwap(prev, &next)
+}
+
 // ListPresets returns all available preset names.
 func (d *Daemon) ListPresets() ([]string, error) {
 	return d.presets.List()
@@ -204,12 +402,58 @@ func (d *Daemon) ListModels(ctx context.Context) ([]ModelInfo, error) {
 	return models, nil
 }
 
+// RunOptions holds optional overrides applied to the preset loaded by Run.
+type RunOptions struct {
+	// DraftModel overrides the draft model (f:/path or h:org/repo:quant).
+	// Not supported for router presets.
+	DraftModel string
+
+	// LlamaServer overrides the llama-server binary (command name or path).
+	LlamaServer string
+
+	// Watch reloads the preset when its file changes, as if it set
+	// watch: true. Only presets can be watched.
+	Watch bool
+
+	// Strict refuses to load when ctx-size exceeds the context length a
+	// model was trained with, rather than warning.
+	Strict bool
+
+	// Progress, if set, is called as the load enters each phase (one of
+	// the protocol.LoadPhase values) and with what llama-server reports
+	// during the health check. It is not kept for reloads.
+	Progress func(phase, detail string)
+}
+
+// report calls o.Progress, if set.
+func (o RunOptions) report(phase, detail string) {
+	if o.Progress != nil {
+		o.Progress(phase, detail)
+	}
+}
+
 // Run loads and runs a model (preset name, file path, or HuggingFace format).
 // Returns error if HuggingFace model is not downloaded (use CLI to pull first).
 func (d *Daemon) Run(ctx context.Context, input string) error {
+	return d.RunWithOptions(ctx, input, RunOptions{})
+}
+
+// RunWithOptions is like Run but applies opts to the loaded preset.
+// It returns ErrCanceled if ctx is canceled or CancelLoad is called before
+// the model is ready.
+func (d *Daemon) RunWithOptions(ctx context.Context, input string, opts RunOptions) (err error) {
 	d.logger.Info("run requested", "input", input)
 
 	d.cancelExistingStartup()
+	d.unwatchPreset()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer func() {
+		if err != nil && ctx.Err() != nil && !errors.Is(err, ErrSuperseded) {
+			err = ErrCanceled
+		}
+	}()
 
 	// Locking strategy:
 	// 1) beginRun: short mu section to reserve generation and stop old process.
@@ -219,37 +463,54 @@ func (d *Daemon) Run(ctx context.Context, input string) error {
 	if err != nil {
 		return err
 	}
+	d.setRunCancel(myGen, cancel)
+	defer d.clearRunCancel(myGen)
 
 	// Heavy operations run outside mu for better Kill()/Run() responsiveness.
-	p, err := d.loadPreset(ctx, input)
+	opts.report(protocol.LoadPhaseResolving, "")
+	p, err := d.loadPreset(ctx, input, opts)
 	if err != nil {
 		return err
 	}
+	watched := d.readWatchedFile(input, p)
 	if !d.setLoadingIfCurrent(myGen, p) {
 		return ErrSuperseded
 	}
 
-	args, err := d.prepareArgsAndConfig(p)
+	opts.report(protocol.LoadPhaseStarting, "")
+	if err := d.runHook(ctx, hookPreLoad, p); err != nil {
+		d.resetIfCurrent(myGen)
+		return err
+	}
+
+	warnings := d.OptionWarnings(ctx, p)
+	for _, w := range warnings {
+		d.logger.Warn("suspicious llama-server option", "preset", p.Name, "problem", w)
+	}
+
+	args, configPath, err := d.prepareArgsAndConfig(p)
 	if err != nil {
 		d.resetIfCurrent(myGen)
 		return err
 	}
 
-	start, err := d.startProcess(ctx, myGen, args)
+	command := d.serverCommand(p)
+	start, err := d.startProcess(ctx, myGen, p, command, args, configPath)
 	if !start.current {
-		d.cleanupRouterConfig(p)
+		d.cleanupRouterConfig(configPath)
 		return ErrSuperseded
 	}
 	if err != nil {
-		d.cleanupRouterConfig(p)
+		d.cleanupRouterConfig(configPath)
 		if p.IsRouter() && !errors.Is(err, exec.ErrNotFound) {
 			return fmt.Errorf("%w (requires llama-server b7350 or later)", err)
 		}
 		return err
 	}
 	defer start.startupCancel()
+	d.writeServerRecord(start.proc.Pid(), p, command, args, configPath, input, opts)
 
-	timeoutCtx, timeoutCancel := context.WithTimeout(start.startupCtx, d.startupTimeout)
+	timeoutCtx, timeoutCancel := context.WithTimeout(start.startupCtx, d.startupTimeoutFor(p))
 	defer timeoutCancel()
 
 	// Monitor process death → cancel health check
@@ -262,10 +523,55 @@ func (d *Daemon) Run(ctx context.Context, input string) error {
 	}()
 
 	// Wait for llama-server to become ready
-	err = d.waitForReady(timeoutCtx, p.Endpoint())
+	opts.report(protocol.LoadPhaseHealthCheck, "")
+	err = d.waitForReady(timeoutCtx, p.Endpoint(), llama.WaitOptions{
+		Exited: start.proc.Done(),
+		Progress: func(detail string) {
+			d.setLoadingDetail(p, detail)
+			opts.report(protocol.LoadPhaseHealthCheck, detail)
+		},
+		Check: healthCheck(p),
+	})
+	if err == nil && p.Warmup {
+		d.logger.Info("warming up model", "preset", p.Name)
+		opts.report(protocol.LoadPhaseWarmup, "")
+		err = d.warmUp(timeoutCtx, p.Endpoint(), p.GetWarmupPrompt())
+	}
 	d.clearStartupCancel(myGen)
 
-	return d.finalizeRun(ctx, myGen, start.proc, p, err)
+	if err := d.finalizeRun(ctx, myGen, start.proc, p, err); err != nil {
+		// Noted before llama-server's output, which ends the message.
+		var processErr *llama.ProcessError
+		if len(warnings) > 0 && errors.As(err, &processErr) {
+			processErr.Err = fmt.Errorf("%w (check preset options: %s)", processErr.Err, strings.Join(warnings, "; "))
+		}
+		return err
+	}
+	opts.Progress = nil
+	d.saveLastLoad(input, opts)
+	d.watchPreset(input, opts, watched)
+	d.runHookAsync(hookPostLoad, p)
+	return nil
+}
+
+// Plan resolves input and opts as RunWithOptions does — preset, model
+// files, mmproj, GPU layers — and returns the llama-server invocation it
+// would start, along with option warnings. Nothing is started or stopped
+// and the router config.ini is not written; its path shows the run's
+// random suffix as XXXXXXXX.
+func (d *Daemon) Plan(ctx context.Context, input string, opts RunOptions) (*Invocation, []string, error) {
+	p, err := d.loadPreset(ctx, input, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	inv := &Invocation{Command: d.serverCommand(p)}
+	if p.IsRouter() {
+		inv.Args = p.BuildRouterArgs(d.routerConfigPath(planRouterConfigSuffix))
+		inv.ConfigINI = p.GenerateConfigINI()
+	} else {
+		inv.Args = p.BuildArgs()
+	}
+	return inv, d.OptionWarnings(ctx, p), nil
 }
 
 func (d *Daemon) beginRun(ctx context.Context) (uint64, error) {
@@ -277,7 +583,7 @@ func (d *Daemon) beginRun(ctx context.Context) (uint64, error) {
 
 	if d.process != nil {
 		d.logger.Info("stopping current model")
-		if err := d.stopLocked(ctx); err != nil {
+		if _, err := d.stopLocked(ctx, false); err != nil {
 			return 0, fmt.Errorf("stop current model: %w", err)
 		}
 	}
@@ -309,7 +615,7 @@ type startProcessResult struct {
 	current       bool
 }
 
-func (d *Daemon) startProcess(ctx context.Context, gen uint64, args []string) (startProcessResult, error) {
+func (d *Daemon) startProcess(ctx context.Context, gen uint64, p *preset.Preset, command string, args []string, configPath string) (startProcessResult, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
@@ -317,15 +623,31 @@ func (d *Daemon) startProcess(ctx context.Context, gen uint64, args []string) (s
 		return startProcessResult{current: false}, nil
 	}
 
-	proc := d.newProcess(llamaServerCommand)
-	proc.SetLogWriter(d.llamaLogWriter)
+	proc := d.newProcess(command)
+	runLog := d.openRunLog(p.Name)
+	if runLog != nil {
+		proc.SetLogWriter(io.MultiWriter(d.llamaLogWriter, bestEffortWriter{runLog}))
+	} else {
+		proc.SetLogWriter(d.llamaLogWriter)
+	}
+	proc.SetPriority(d.llamaPriority(p))
 	if err := proc.Start(args); err != nil {
+		if runLog != nil {
+			runLog.Close()
+		}
 		d.resetState()
 		return startProcessResult{current: true}, err
 	}
+	if runLog != nil {
+		go func() {
+			<-proc.Done()
+			runLog.Close()
+		}()
+	}
 
 	startupCtx, startupCancel := context.WithCancel(ctx)
 	d.process = proc
+	d.invocation.Store(d.newInvocation(p, proc.Pid(), command, args, configPath))
 	d.setStartupCancel(gen, startupCancel)
 	return startProcessResult{
 		proc:          proc,
@@ -345,28 +667,45 @@ func (d *Daemon) finalizeRun(ctx context.Context, gen uint64, proc llamaProcess,
 	}
 
 	if waitErr != nil {
+		canceled := ctx.Err() != nil
+		var output []string
 		// Determine cause and build user-friendly error message
 		select {
 		case <-proc.Done():
 			waitErr = fmt.Errorf("llama-server exited unexpectedly: %w", proc.ExitErr())
+			output = proc.Output()
 		default:
-			if errors.Is(waitErr, context.DeadlineExceeded) {
-				waitErr = fmt.Errorf("server did not become ready within %s", d.startupTimeout)
+			if errors.Is(waitErr, context.DeadlineExceeded) && !canceled {
+				waitErr = fmt.Errorf("server did not become ready within %s (raise startup-timeout in the preset or config.yaml)", d.startupTimeoutFor(p))
 			}
 		}
 
-		if stopErr := d.process.Stop(ctx); stopErr != nil {
+		inv := d.invocation.Load()
+
+		// The request context may be what ended the wait; cleanup must
+		// still give llama-server its grace period.
+		if stopErr := d.process.Stop(context.WithoutCancel(ctx)); stopErr != nil {
 			d.logger.Warn("failed to stop process during cleanup", "error", stopErr)
 		}
 		d.process = nil
 		d.resetState()
-		d.cleanupRouterConfig(p)
+		if inv != nil {
+			d.cleanupRouterConfig(inv.configPath)
+		}
+		d.removeServerRecord()
+
+		if canceled {
+			d.logger.Info("load canceled", "preset", p.Name)
+			return ErrCanceled
+		}
 
-		processErr := &llama.ProcessError{Op: llama.ProcessOpWait, Err: waitErr}
 		if p.IsRouter() {
-			return fmt.Errorf("%w (requires llama-server b7350 or later)", processErr)
+			waitErr = fmt.Errorf("%w (requires llama-server b7350 or later)", waitErr)
+		}
+		return &LoadFailure{
+			Err:         &llama.ProcessError{Op: llama.ProcessOpWait, Err: waitErr, Output: output},
+			Diagnostics: d.diagnose(proc, p, inv),
 		}
-		return processErr
 	}
 
 	d.setSnapshot(StateRunning, p)
@@ -374,53 +713,90 @@ func (d *Daemon) finalizeRun(ctx context.Context, gen uint64, proc llamaProcess,
 	return nil
 }
 
+// KillOptions holds optional parameters for KillWithOptions.
+type KillOptions struct {
+	// Force kills llama-server with SIGKILL instead of asking it to exit.
+	Force bool
+}
+
 // Kill stops the currently running model.
 func (d *Daemon) Kill(ctx context.Context) error {
-	d.logger.Info("kill requested")
+	_, err := d.KillWithOptions(ctx, KillOptions{})
+	return err
+}
+
+// KillWithOptions stops the currently running model and reports whether
+// llama-server exited on its own (true when nothing was running).
+func (d *Daemon) KillWithOptions(ctx context.Context, opts KillOptions) (bool, error) {
+	d.logger.Info("kill requested", "force", opts.Force)
 
 	d.cancelExistingStartup()
+	d.unwatchPreset()
 
 	d.mu.Lock()
 	defer d.mu.Unlock()
 	d.runGen++
 	hadProcess := d.process != nil
 
-	if err := d.stopLocked(ctx); err != nil {
-		return err
+	graceful, err := d.stopLocked(ctx, opts.Force)
+	if err != nil {
+		return false, err
 	}
 	if !hadProcess {
 		d.resetState()
 	}
-	return nil
+	return graceful, nil
 }
 
-func (d *Daemon) stopLocked(ctx context.Context) error {
+// stopLocked stops the running process, escalating to SIGKILL after the
+// grace period (immediately with force). Reports whether it exited on its own.
+func (d *Daemon) stopLocked(ctx context.Context, force bool) (bool, error) {
 	if d.process == nil {
-		return nil
+		return true, nil
 	}
 
-	if err := d.process.Stop(ctx); err != nil {
-		return err
+	grace := d.settings.Load().stopGrace
+	if force {
+		grace = 0
+	}
+	graceful, err := d.process.Terminate(ctx, grace)
+	if err != nil {
+		return false, err
+	}
+	if !graceful && !force {
+		d.logger.Warn("llama-server did not exit after SIGTERM, killed", "grace", grace)
 	}
 
 	p := d.CurrentPreset()
+	inv := d.invocation.Load()
 	d.process = nil
 	d.resetState()
-	d.cleanupRouterConfig(p)
+	if inv != nil {
+		d.cleanupRouterConfig(inv.configPath)
+	}
+	d.removeServerRecord()
 
 	d.logger.Info("model stopped")
-	return nil
+	if p != nil {
+		d.runHookAsync(hookPostUnload, p)
+	}
+	return graceful, nil
 }
 
-// cleanupRouterConfig removes the router config.ini file (best-effort).
-func (d *Daemon) cleanupRouterConfig(p *preset.Preset) {
-	if p != nil && p.IsRouter() && d.configPath != "" {
-		os.Remove(d.configPath) // ignore error
+// cleanupRouterConfig removes a run's router config.ini, if it wrote one
+// (best-effort).
+func (d *Daemon) cleanupRouterConfig(configPath string) {
+	if configPath == "" {
+		return
+	}
+	if err := d.temps.remove(configPath); err != nil {
+		d.logger.Warn("failed to remove router config", "path", configPath, "error", err)
 	}
 }
 
 // resetState clears state and preset to idle state.
 func (d *Daemon) resetState() {
+	d.invocation.Store(nil)
 	d.setSnapshot(StateIdle, nil)
 }
 
@@ -433,6 +809,36 @@ func (d *Daemon) cancelExistingStartup() {
 	}
 }
 
+// CancelLoad aborts the load in progress, if any: llama-server is stopped,
+// the daemon returns to idle, and the Run returns ErrCanceled. Reports
+// whether a load was in progress.
+func (d *Daemon) CancelLoad() bool {
+	d.startupMu.Lock()
+	cancel := d.cancelRun
+	d.startupMu.Unlock()
+	if cancel == nil {
+		return false
+	}
+	d.logger.Info("load cancel requested")
+	cancel()
+	return true
+}
+
+func (d *Daemon) setRunCancel(gen uint64, cancel context.CancelFunc) {
+	d.startupMu.Lock()
+	d.runCancelGen = gen
+	d.cancelRun = cancel
+	d.startupMu.Unlock()
+}
+
+func (d *Daemon) clearRunCancel(gen uint64) {
+	d.startupMu.Lock()
+	if d.runCancelGen == gen {
+		d.cancelRun = nil
+	}
+	d.startupMu.Unlock()
+}
+
 func (d *Daemon) setStartupCancel(gen uint64, cancel context.CancelFunc) {
 	d.startupMu.Lock()
 	d.startupGen = gen
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// ctxSizeKeys are the llama-server option names for the context size.
+var ctxSizeKeys = []string{"ctx-size", "c"}
+
+// ctxSize returns the context size opts set, or 0 when it is unset, left
+// to the model (0) or not a number.
+func ctxSize(opts preset.Options) int {
+	for _, k := range ctxSizeKeys {
+		if v, ok := opts[k]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return 0
+			}
+			return n
+		}
+	}
+	return 0
+}
+
+// contextWarnings returns a warning for each model of p whose ctx-size is
+// larger than the context length the model was trained with, past which
+// output quality degrades without any error from llama-server. Models whose
+// GGUF metadata cannot be read or does not declare a context length are
+// not checked.
+func (d *Daemon) contextWarnings(p *preset.Preset) []string {
+	if !p.IsRouter() {
+		if w := d.contextWarning(p.Model, ctxSize(p.Options)); w != "" {
+			return []string{w}
+		}
+		return nil
+	}
+	shared := ctxSize(p.Options)
+	var warnings []string
+	for _, m := range p.Models {
+		n := ctxSize(m.Options)
+		if n == 0 {
+			n = shared
+		}
+		if w := d.contextWarning(m.Model, n); w != "" {
+			warnings = append(warnings, fmt.Sprintf("model '%s': %s", m.Name, w))
+		}
+	}
+	return warnings
+}
+
+// contextWarning checks a ctx-size of n for the model file at model
+// (f:path), returning "" when it fits.
+func (d *Daemon) contextWarning(model string, n int) string {
+	if n == 0 || !strings.HasPrefix(model, "f:") {
+		return ""
+	}
+	path := strings.TrimPrefix(model, "f:")
+	info, err := d.inspectModel(path)
+	if err != nil {
+		d.logger.Debug("context length not checked", "model", path, "error", err)
+		return ""
+	}
+	if info.ContextLength == 0 || n <= info.ContextLength {
+		return ""
+	}
+	return fmt.Sprintf("ctx-size %d exceeds the model's trained context length %d; output quality may degrade", n, info.ContextLength)
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/gguf"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+func TestDaemonContextWarnings(t *testing.T) {
+	const exceeds = "ctx-size 65536 exceeds the model's trained context length 40960; output quality may degrade"
+
+	tests := []struct {
+		name    string
+		preset  *preset.Preset
+		inspect func(path string) (*gguf.Info, error)
+		want    []string
+	}{
+		{
+			name:   "within trained context",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "32768"}},
+		},
+		{
+			name:   "exceeds trained context",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "65536"}},
+			want:   []string{exceeds},
+		},
+		{
+			name:   "short option name",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"c": "65536"}},
+			want:   []string{exceeds},
+		},
+		{
+			name:   "left to the model",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "0"}},
+		},
+		{
+			name:   "not a number",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "64k"}},
+		},
+		{
+			name:   "unknown trained context",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "65536"}},
+			inspect: func(path string) (*gguf.Info, error) {
+				return &gguf.Info{}, nil
+			},
+		},
+		{
+			name:   "unreadable model",
+			preset: &preset.Preset{Model: "f:/m.gguf", Options: preset.Options{"ctx-size": "65536"}},
+			inspect: func(path string) (*gguf.Info, error) {
+				return nil, errors.New("not a GGUF file")
+			},
+		},
+		{
+			name: "router models override the shared ctx-size",
+			preset: &preset.Preset{
+				Mode:    "router",
+				Options: preset.Options{"ctx-size": "65536"},
+				Models: []preset.ModelEntry{
+					{Name: "a", Model: "f:/a.gguf"},
+					{Name: "b", Model: "f:/b.gguf", Options: preset.Options{"ctx-size": "8192"}},
+				},
+			},
+			want: []string{"model 'a': " + exceeds},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.inspectModel = tt.inspect
+			if d.inspectModel == nil {
+				d.inspectModel = func(path string) (*gguf.Info, error) {
+					return &gguf.Info{ContextLength: 40960}, nil
+				}
+			}
+
+			// Act
+			got := d.OptionWarnings(context.Background(), tt.preset)
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("OptionWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleLoad_StrictRefusesExcessContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		want   string
+	}{
+		{name: "warns", strict: false, want: protocol.StatusOK},
+		{name: "strict refuses", strict: true, want: protocol.StatusError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+				"long": {Name: "long", Model: "f:/m.gguf", Port: 8080, Options: preset.Options{"ctx-size": "131072"}},
+			}}
+			d := newTestDaemon(presets, &stubModelManager{})
+			d.inspectModel = func(path string) (*gguf.Info, error) {
+				return &gguf.Info{ContextLength: 32768}, nil
+			}
+			proc := &mockProcess{}
+			d.newProcess = func(path string) llamaProcess { return proc }
+			d.waitForReady = mockHealthChecker(nil)
+			server := NewServer(d, "/tmp/test.sock", io.Discard)
+
+			// Act
+			resp := server.handleLoad(context.Background(), &protocol.Request{
+				Command: protocol.CmdLoad,
+				Args:    map[string]any{"identifier": "p:long", "strict": tt.strict},
+			})
+
+			// Assert
+			if resp.Status != tt.want {
+				t.Fatalf("Status = %q (%s), want %q", resp.Status, resp.Error, tt.want)
+			}
+			const warning = "ctx-size 131072 exceeds the model's trained context length 32768"
+			if tt.strict {
+				if !strings.Contains(resp.Error, warning) || proc.startCalled {
+					t.Errorf("error = %q, started = %v; want the warning and no llama-server", resp.Error, proc.startCalled)
+				}
+				return
+			}
+			if got := decodeData[protocol.LoadData](t, resp).Warnings; len(got) != 1 || !strings.HasPrefix(got[0], warning) {
+				t.Errorf("warnings = %q, want %q", got, warning)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package daemon
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+// gpuLayersKeys are the llama-server option names for the GPU layer count.
+var gpuLayersKeys = []string{"n-gpu-layers", "gpu-layers", "ngl"}
+
+// gpuLayersAuto is the option value replaced by a computed layer count.
+const gpuLayersAuto = preset.GPULayersAuto
+
+// gpuLayersHeadroom is VRAM left free for the KV cache and compute buffers
+// when computing gpu-layers: auto.
+const gpuLayersHeadroom = 2 << 30
+
+// autoGPULayersKey returns the option key set to "auto", or "".
+func autoGPULayersKey(opts preset.Options) string {
+	for _, k := range gpuLayersKeys {
+		if opts[k] == gpuLayersAuto {
+			return k
+		}
+	}
+	return ""
+}
+
+// resolveGPULayers replaces gpu-layers: auto, as a field or an option, with
+// a concrete layer count computed from the model size and detected VRAM.
+func (d *Daemon) resolveGPULayers(p *preset.Preset) (*preset.Preset, error) {
+	if p.IsRouter() {
+		if key := autoGPULayersKey(p.Options); key != "" {
+			return nil, fmt.Errorf("%s: auto must be set per model in router mode", key)
+		}
+		if p.GPU.Layers == gpuLayersAuto {
+			return nil, fmt.Errorf("gpu-layers: auto must be set per model in router mode")
+		}
+		var models []preset.ModelEntry
+		for i, m := range p.Models {
+			key := autoGPULayersKey(m.Options)
+			if key == "" && m.GPU.Layers != gpuLayersAuto {
+				continue
+			}
+			n, err := d.autoGPULayers(m.Model)
+			if err != nil {
+				return nil, fmt.Errorf("model '%s': %w", m.Name, err)
+			}
+			if models == nil {
+				models = append([]preset.ModelEntry(nil), p.Models...)
+			}
+			if key != "" {
+				models[i].Options = withOption(m.Options, key, n)
+			} else {
+				models[i].GPU.Layers = strconv.Itoa(n)
+			}
+		}
+		if models == nil {
+			return p, nil
+		}
+		resolved := *p
+		resolved.Models = models
+		return &resolved, nil
+	}
+
+	key := autoGPULayersKey(p.Options)
+	if key == "" && p.GPU.Layers != gpuLayersAuto {
+		return p, nil
+	}
+	n, err := d.autoGPULayers(p.Model)
+	if err != nil {
+		return nil, err
+	}
+	resolved := *p
+	if key != "" {
+		resolved.Options = withOption(p.Options, key, n)
+	} else {
+		resolved.GPU.Layers = strconv.Itoa(n)
+	}
+	return &resolved, nil
+}
+
+// checkGPUCount checks tensor-split and main-gpu of p against the number
+// of detected GPUs. Nothing is checked when no GPU is detected, since
+// detection does not cover every backend.
+func (d *Daemon) checkGPUCount(p *preset.Preset) error {
+	settings := []preset.GPU{p.GPU}
+	for _, m := range p.Models {
+		settings = append(settings, m.GPU)
+	}
+	var gpu sysinfo.GPU
+	for i, g := range settings {
+		if len(g.TensorSplit) == 0 && g.MainGPU == nil {
+			continue
+		}
+		if gpu.Count == 0 {
+			if gpu = d.detectGPU(); gpu.Count == 0 {
+				return nil
+			}
+		}
+		prefix := ""
+		if i > 0 {
+			prefix = fmt.Sprintf("model '%s': ", p.Models[i-1].Name)
+		}
+		if n := len(g.TensorSplit); n > gpu.Count {
+			return fmt.Errorf("%stensor-split has %d values but %s", prefix, n, gpuCountText(gpu.Count))
+		}
+		if g.MainGPU != nil && *g.MainGPU >= gpu.Count {
+			return fmt.Errorf("%smain-gpu %d does not exist: %s", prefix, *g.MainGPU, gpuCountText(gpu.Count))
+		}
+	}
+	return nil
+}
+
+func gpuCountText(n int) string {
+	if n == 1 {
+		return "only 1 GPU was detected"
+	}
+	return fmt.Sprintf("only %d GPUs were detected", n)
+}
+
+// autoGPULayers computes the layer count for the model file at model (f:path).
+func (d *Daemon) autoGPULayers(model string) (int, error) {
+	path := strings.TrimPrefix(model, "f:")
+	info, err := d.inspectModel(path)
+	if err != nil {
+		return 0, fmt.Errorf("gpu-layers auto: %w", err)
+	}
+	if info.BlockCount == 0 {
+		return 0, fmt.Errorf("gpu-layers auto: %s does not declare its layer count; set gpu-layers explicitly", path)
+	}
+
+	gpu := d.detectGPU()
+	n := sysinfo.GPULayers(gpu, info.Size, info.BlockCount, gpuLayersHeadroom)
+	d.logger.Info("gpu layers selected",
+		"model", path,
+		"backend", gpu.Backend,
+		"vram", gpu.VRAM,
+		"model_size", info.Size,
+		"layers", info.BlockCount,
+		"gpu_layers", n,
+	)
+	return n, nil
+}
+
+// withOption returns a copy of opts with key set to n.
+func withOption(opts preset.Options, key string, n int) preset.Options {
+	out := maps.Clone(opts)
+	out[key] = strconv.Itoa(n)
+	return out
+}
@@ -0,0 +1,218 @@
+package daemon
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/gguf"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+func TestRun_GPULayersAuto(t *testing.T) {
+	const gb = int64(1 << 30)
+
+	tests := []struct {
+		name     string
+		gpu      sysinfo.GPU
+		wantArgs []string
+	}{
+		{"fits in VRAM", sysinfo.GPU{Backend: sysinfo.BackendCUDA, VRAM: 24 * gb}, []string{"--n-gpu-layers", "33"}},
+		{"partial offload", sysinfo.GPU{Backend: sysinfo.BackendCUDA, VRAM: 8 * gb}, []string{"--n-gpu-layers", "18"}},
+		{"no GPU", sysinfo.GPU{}, []string{"--n-gpu-layers", "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			presets := &stubPresetLoader{
+				presets: map[string]*preset.Preset{
+					"test": {Name: "test", Model: "f:/models/model.gguf", Options: preset.Options{"n-gpu-layers": "auto"}},
+				},
+			}
+			d := newTestDaemon(presets, &stubModelManager{})
+			d.detectGPU = func() sysinfo.GPU { return tt.gpu }
+			d.inspectModel = func(path string) (*gguf.Info, error) {
+				return &gguf.Info{Size: 11 * gb, BlockCount: 32}, nil
+			}
+			mockProc := &mockProcess{}
+			d.newProcess = func(path string) llamaProcess { return mockProc }
+			d.waitForReady = mockHealthChecker(nil)
+
+			// Act
+			err := d.Run(context.Background(), "p:test")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			i := slices.Index(mockProc.receivedArgs, "--n-gpu-layers")
+			if i < 0 || !slices.Equal(mockProc.receivedArgs[i:i+2], tt.wantArgs) {
+				t.Errorf("args = %v, want %v", mockProc.receivedArgs, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestResolveGPULayers_Router(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.detectGPU = func() sysinfo.GPU { return sysinfo.GPU{Backend: sysinfo.BackendMetal, VRAM: 48 << 30} }
+	d.inspectModel = func(path string) (*gguf.Info, error) {
+		return &gguf.Info{Size: 5 << 30, BlockCount: 36}, nil
+	}
+	p := &preset.Preset{
+		Name: "router",
+		Mode: "router",
+		Models: []preset.ModelEntry{
+			{Name: "a", Model: "f:/models/a.gguf", Options: preset.Options{"gpu-layers": "auto"}},
+			{Name: "b", Model: "f:/models/b.gguf", Options: preset.Options{"gpu-layers": "10"}},
+		},
+	}
+
+	// Act
+	got, err := d.resolveGPULayers(p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("resolveGPULayers() error = %v", err)
+	}
+	if v := got.Models[0].Options["gpu-layers"]; v != "37" {
+		t.Errorf("model a gpu-layers = %q, want 37", v)
+	}
+	if v := got.Models[1].Options["gpu-layers"]; v != "10" {
+		t.Errorf("model b gpu-layers = %q, want 10", v)
+	}
+	if p.Models[0].Options["gpu-layers"] != "auto" {
+		t.Error("original preset should not be modified")
+	}
+}
+
+func TestResolveGPULayers_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		preset  *preset.Preset
+		info    *gguf.Info
+		wantErr string
+	}{
+		{
+			name:    "router top-level auto",
+			preset:  &preset.Preset{Name: "r", Mode: "router", Options: preset.Options{"ngl": "auto"}},
+			info:    &gguf.Info{BlockCount: 32},
+			wantErr: "must be set per model",
+		},
+		{
+			name:    "unknown layer count",
+			preset:  &preset.Preset{Name: "s", Model: "f:/models/m.gguf", Options: preset.Options{"ngl": "auto"}},
+			info:    &gguf.Info{Size: 1 << 30},
+			wantErr: "does not declare its layer count",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.detectGPU = func() sysinfo.GPU { return sysinfo.GPU{} }
+			d.inspectModel = func(path string) (*gguf.Info, error) { return tt.info, nil }
+
+			// Act
+			_, err := d.resolveGPULayers(tt.preset)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveGPULayers() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveGPULayers_Field(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.detectGPU = func() sysinfo.GPU { return sysinfo.GPU{Backend: sysinfo.BackendCUDA, VRAM: 24 << 30, Count: 1} }
+	d.inspectModel = func(path string) (*gguf.Info, error) {
+		return &gguf.Info{Size: 5 << 30, BlockCount: 32}, nil
+	}
+	p := &preset.Preset{Name: "s", Model: "f:/models/m.gguf", GPU: preset.GPU{Layers: "auto"}}
+
+	// Act
+	got, err := d.resolveGPULayers(p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("resolveGPULayers() error = %v", err)
+	}
+	if got.GPU.Layers != "33" {
+		t.Errorf("gpu-layers = %q, want 33", got.GPU.Layers)
+	}
+	if p.GPU.Layers != "auto" {
+		t.Error("original preset should not be modified")
+	}
+}
+
+func TestCheckGPUCount(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		preset  *preset.Preset
+		gpus    int
+		wantErr string // empty for no error
+	}{
+		{
+			name:   "split fits",
+			preset: &preset.Preset{Name: "s", GPU: preset.GPU{TensorSplit: []float64{3, 1}, MainGPU: intPtr(1)}},
+			gpus:   2,
+		},
+		{
+			name:    "split longer than GPUs",
+			preset:  &preset.Preset{Name: "s", GPU: preset.GPU{TensorSplit: []float64{1, 1, 1}}},
+			gpus:    2,
+			wantErr: "tensor-split has 3 values but only 2 GPUs were detected",
+		},
+		{
+			name:    "main gpu missing",
+			preset:  &preset.Preset{Name: "s", GPU: preset.GPU{MainGPU: intPtr(1)}},
+			gpus:    1,
+			wantErr: "main-gpu 1 does not exist: only 1 GPU was detected",
+		},
+		{
+			name: "router model",
+			preset: &preset.Preset{Name: "r", Mode: "router", Models: []preset.ModelEntry{
+				{Name: "big", GPU: preset.GPU{TensorSplit: []float64{1, 1}}},
+			}},
+			gpus:    1,
+			wantErr: "model 'big': tensor-split has 2 values",
+		},
+		{
+			name:   "no GPU detected",
+			preset: &preset.Preset{Name: "s", GPU: preset.GPU{TensorSplit: []float64{1, 1}}},
+			gpus:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.detectGPU = func() sysinfo.GPU { return sysinfo.GPU{Backend: sysinfo.BackendCUDA, VRAM: 24 << 30, Count: tt.gpus} }
+
+			// Act
+			err := d.checkGPUCount(tt.preset)
+
+			// Assert
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkGPUCount() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkGPUCount() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// ServerMetrics is llama-server's load as reported by its /metrics and
+// /props endpoints. Values llama-server did not report are negative.
+type ServerMetrics struct {
+	KVCacheUsage          float64 // fraction of the KV cache in use
+	SlotsBusy             int
+	SlotsTotal            int
+	PromptTokensPerSec    float64 // average prompt processing throughput
+	PredictedTokensPerSec float64 // average generation throughput
+}
+
+// Prometheus metric names exported by llama-server.
+const (
+	metricKVCacheUsage       = "llamacpp:kv_cache_usage_ratio"
+	metricRequestsProcessing = "llamacpp:requests_processing"
+	metricRequestsDeferred   = "llamacpp:requests_deferred"
+	metricPromptTokensPerSec = "llamacpp:prompt_tokens_seconds"
+	metricPredictedPerSec    = "llamacpp:predicted_tokens_seconds"
+)
+
+// MetricsEnabled reports whether p starts llama-server with --metrics.
+// Only single-mode servers are scraped; in router mode each model runs in
+// its own child process.
+func MetricsEnabled(p *preset.Preset) bool {
+	return p != nil && !p.IsRouter() && p.Options["metrics"] == "true"
+}
+
+// FetchMetrics scrapes the running llama-server's metrics.
+// Returns nil unless a preset that enables metrics is running, or on any
+// error (graceful degradation).
+func (d *Daemon) FetchMetrics(ctx context.Context) *ServerMetrics {
+	snap := d.StatusSnapshot()
+	p := snap.Preset
+	if snap.State != StateRunning || !MetricsEnabled(p) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	values, err := d.fetchPrometheus(ctx, p.Endpoint()+"/metrics")
+	if err != nil {
+		return nil
+	}
+	m := &ServerMetrics{
+		KVCacheUsage:          metricOr(values, metricKVCacheUsage),
+		SlotsBusy:             int(metricOr(values, metricRequestsProcessing)),
+		SlotsTotal:            -1,
+		PromptTokensPerSec:    metricOr(values, metricPromptTokensPerSec),
+		PredictedTokensPerSec: metricOr(values, metricPredictedPerSec),
+	}
+
+	// /metrics has no slot count; /props does.
+	var props struct {
+		TotalSlots int `json:"total_slots"`
+	}
+	if err := d.getJSON(ctx, p.Endpoint()+"/props", &props); err == nil && props.TotalSlots > 0 {
+		m.SlotsTotal = props.TotalSlots
+	}
+	return m
+}
+
+// metricOr returns the named metric, or -1 if it was not reported.
+func metricOr(values map[string]float64, name string) float64 {
+	if v, ok := values[name]; ok {
+		return v
+	}
+	return -1
+}
+
+// fetchPrometheus returns the unlabeled samples of a Prometheus text
+// exposition at url.
+func (d *Daemon) fetchPrometheus(ctx context.Context, url string) (map[string]float64, error) {
+	body, err := d.get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return parsePrometheus(body)
+}
+
+// parsePrometheus reads "name value" samples, skipping comments and
+// labeled series.
+func parsePrometheus(r io.Reader) (map[string]float64, error) {
+	values := map[string]float64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.Contains(fields[0], "{") {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = v
+	}
+	return values, scanner.Err()
+}
+
+// getJSON decodes the JSON response to a GET of url into v.
+func (d *Daemon) getJSON(ctx context.Context, url string, v any) error {
+	body, err := d.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+// get returns the body of a successful GET of url, limited to 1MB.
+func (d *Daemon) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return http.MaxBytesReader(nil, resp.Body, 1<<20), nil
+}
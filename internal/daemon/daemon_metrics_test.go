@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+const testMetrics = `# HELP llamacpp:prompt_tokens_seconds Average prompt throughput in tokens/s.
+# TYPE llamacpp:prompt_tokens_seconds gauge
+llamacpp:prompt_tokens_seconds 512.5
+llamacpp:predicted_tokens_seconds 42.25
+llamacpp:kv_cache_usage_ratio 0.375
+llamacpp:requests_processing 1
+llamacpp:requests_deferred 0
+`
+
+// newMetricsTestDaemon returns a daemon running a single-mode preset served
+// by handler.
+func newMetricsTestDaemon(t *testing.T, options preset.Options, handler http.HandlerFunc) *Daemon {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.httpClient = srv.Client()
+	d.setSnapshot(StateRunning, &preset.Preset{
+		Name:    "test",
+		Model:   "f:/path/to/model.gguf",
+		Host:    u.Hostname(),
+		Port:    port,
+		Options: options,
+	})
+	return d
+}
+
+func TestFetchMetrics(t *testing.T) {
+	// Arrange
+	d := newMetricsTestDaemon(t, preset.Options{"metrics": "true"}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/metrics":
+			io.WriteString(w, testMetrics)
+		case "/props":
+			json.NewEncoder(w).Encode(map[string]any{"total_slots": 4})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	// Act
+	m := d.FetchMetrics(context.Background())
+
+	// Assert
+	want := ServerMetrics{
+		KVCacheUsage:          0.375,
+		SlotsBusy:             1,
+		SlotsTotal:            4,
+		PromptTokensPerSec:    512.5,
+		PredictedTokensPerSec: 42.25,
+	}
+	if m == nil || *m != want {
+		t.Errorf("FetchMetrics() = %+v, want %+v", m, want)
+	}
+}
+
+func TestFetchMetrics_MissingValuesAreNegative(t *testing.T) {
+	// Arrange
+	d := newMetricsTestDaemon(t, preset.Options{"metrics": "true"}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			io.WriteString(w, "llamacpp:requests_processing 0\n")
+			return
+		}
+		http.NotFound(w, r)
+	})
+
+	// Act
+	m := d.FetchMetrics(context.Background())
+
+	// Assert
+	if m == nil {
+		t.Fatal("FetchMetrics() = nil")
+	}
+	if m.SlotsBusy != 0 || m.SlotsTotal >= 0 || m.KVCacheUsage >= 0 {
+		t.Errorf("FetchMetrics() = %+v, want busy 0 and unknown total and KV cache", m)
+	}
+	data := metricsData(m)
+	if data.KVCacheUsage != nil {
+		t.Errorf("metricsData().KVCacheUsage = %v, want nil", *data.KVCacheUsage)
+	}
+}
+
+func TestFetchMetrics_NotEnabled(t *testing.T) {
+	// Arrange
+	d := newMetricsTestDaemon(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL.Path)
+	})
+
+	// Act
+	m := d.FetchMetrics(context.Background())
+
+	// Assert
+	if m != nil {
+		t.Errorf("FetchMetrics() = %+v, want nil without --metrics", m)
+	}
+}
+
+func TestParsePrometheus(t *testing.T) {
+	// Arrange
+	input := testMetrics + "labeled{slot=\"0\"} 3\nbroken\n"
+
+	// Act
+	values, err := parsePrometheus(strings.NewReader(input))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("parsePrometheus() error = %v", err)
+	}
+	if len(values) != 5 {
+		t.Errorf("values = %v, want 5 unlabeled samples", values)
+	}
+	if values["llamacpp:predicted_tokens_seconds"] != 42.25 {
+		t.Errorf("predicted_tokens_seconds = %v, want 42.25", values["llamacpp:predicted_tokens_seconds"])
+	}
+}
@@ -0,0 +1,132 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// stubOptionSchema knows ctx-size (a number) and mlock (a flag).
+func stubOptionSchema(ctx context.Context, command string) (llama.OptionSchema, error) {
+	return llama.OptionSchema{
+		"ctx-size": {Kind: llama.OptionNumber},
+		"mlock":    {Kind: llama.OptionFlag},
+	}, nil
+}
+
+func TestDaemonOptionWarnings(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema func(ctx context.Context, command string) (llama.OptionSchema, error)
+		opts   preset.Options
+		want   []string
+	}{
+		{
+			name:   "valid options",
+			schema: stubOptionSchema,
+			opts:   preset.Options{"ctx-size": "4096", "mlock": "true"},
+		},
+		{
+			name:   "typo and malformed value",
+			schema: stubOptionSchema,
+			opts:   preset.Options{"ctxsize": "4096", "mlock": "yes"},
+			want: []string{
+				"unknown llama-server option 'ctxsize' (did you mean 'ctx-size'?)",
+				"option 'mlock' is a flag and takes true or false, got 'yes'",
+			},
+		},
+		{
+			name:   "checks disabled",
+			schema: nil,
+			opts:   preset.Options{"ctxsize": "4096"},
+		},
+		{
+			name: "schema unavailable",
+			schema: func(ctx context.Context, command string) (llama.OptionSchema, error) {
+				return nil, errors.New("executable file not found")
+			},
+			opts: preset.Options{"ctxsize": "4096"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.optionSchema = tt.schema
+			p := &preset.Preset{Name: "p", Model: "f:/m.gguf", Options: tt.opts}
+
+			// Act
+			got := d.OptionWarnings(context.Background(), p)
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("OptionWarnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleLoad_ReturnsOptionWarnings(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"typo": {Name: "typo", Model: "f:/m.gguf", Port: 8080, Options: preset.Options{"ctx-size": "8k"}},
+	}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.optionSchema = stubOptionSchema
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = mockHealthChecker(nil)
+	server := NewServer(d, "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handleLoad(context.Background(), &protocol.Request{
+		Command: protocol.CmdLoad,
+		Args:    map[string]any{"identifier": "p:typo"},
+	})
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q (%s), want ok", resp.Status, resp.Error)
+	}
+	want := []string{"option 'ctx-size' expects a number, got '8k'"}
+	if got := decodeData[protocol.LoadData](t, resp).Warnings; !slices.Equal(got, want) {
+		t.Errorf("warnings = %v, want %q", got, want)
+	}
+}
+
+func TestDaemonRun_StartupFailureMentionsOptionWarnings(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"typo": {Name: "typo", Model: "f:/m.gguf", Options: preset.Options{"ctxsize": "4096"}},
+	}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.optionSchema = stubOptionSchema
+	done := make(chan struct{})
+	close(done)
+	d.newProcess = func(path string) llamaProcess {
+		return &mockProcess{doneCh: done, exitError: fmt.Errorf("exit status 1"), output: []string{"error: invalid argument: --ctxsize"}}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:typo")
+
+	// Assert
+	if !llama.IsProcessError(err) {
+		t.Fatalf("Run() error = %v, want a process error", err)
+	}
+	if !strings.Contains(err.Error(), "(check preset options: unknown llama-server option 'ctxsize' (did you mean 'ctx-size'?))\nllama-server output:") {
+		t.Errorf("Run() error = %q, want the option warning before llama-server's output", err)
+	}
+}
@@ -2,11 +2,18 @@ package daemon
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"maps"
+	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/d2verb/alpaca/internal/allowlist"
 	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/model"
 	"github.com/d2verb/alpaca/internal/preset"
 )
 
@@ -40,6 +47,38 @@ func (d *Daemon) autoResolveMmproj(ctx context.Context, mmproj *string, modelPat
 	d.logger.Info("using mmproj", attrs...)
 }
 
+// isModelDir reports whether ref is an f: reference to a directory.
+func isModelDir(ref string) bool {
+	path, ok := strings.CutPrefix(ref, "f:")
+	if !ok {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// resolveModelDir expands an f: reference to a directory into the model file
+// it holds, and sets mmproj to the directory's mmproj file when it is empty
+// and the directory has exactly one. The modelName parameter is used for
+// router-mode logging; pass empty string for non-router cases.
+func (d *Daemon) resolveModelDir(ref string, mmproj *string, modelName string) (string, error) {
+	dir := strings.TrimPrefix(ref, "f:")
+	path, dirMmproj, err := model.ResolveDir(dir)
+	if err != nil {
+		return "", err
+	}
+	if mmproj != nil && *mmproj == "" && dirMmproj != "" {
+		*mmproj = "f:" + dirMmproj
+		attrs := []any{"path", dirMmproj}
+		if modelName != "" {
+			attrs = append(attrs, "model", modelName)
+		}
+		attrs = append(attrs, "source", "found next to the model")
+		d.logger.Info("using mmproj", attrs...)
+	}
+	return "f:" + path, nil
+}
+
 // resolveHFPreset creates a preset from HuggingFace format (h:repo:quant).
 // Returns error if model is not downloaded.
 func (d *Daemon) resolveHFPreset(ctx context.Context, repo, quant string) (*preset.Preset, error) {
@@ -54,11 +93,115 @@ func (d *Daemon) resolveHFPreset(ctx context.Context, repo, quant string) (*pres
 	return p, nil
 }
 
-// resolveModel resolves the model and draft-model fields in a preset if they use HuggingFace format.
+// resolveModel resolves the model and draft-model fields in a preset if they use HuggingFace format,
+// then validates that every referenced model file is a GGUF file.
 // Returns a new preset with the resolved model paths without mutating the original.
 // Returns the original preset as-is if no resolution is needed.
-// Returns error if HuggingFace model is not downloaded.
+// Returns error if HuggingFace model is not downloaded or a file is not a valid GGUF.
 func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Preset, error) {
+	resolved, err := d.resolveModelRefs(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.validateModelFiles(ctx, resolved); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// validateModelFiles checks the model, draft model, mmproj, and LoRA files
+// of a resolved preset so a corrupted download fails fast with a clear error
+// instead of a llama-server crash and health check timeout. With an
+// allow-list set, every file's SHA256 must also be in the signed manifest.
+func (d *Daemon) validateModelFiles(ctx context.Context, p *preset.Preset) error {
+	var manifest *allowlist.Manifest
+	if allowList := d.settings.Load().allowList; allowList != nil {
+		m, err := allowList.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		manifest = m
+	}
+
+	check := func(field, ref string) error {
+		path, ok := strings.CutPrefix(ref, "f:")
+		if !ok {
+			return nil
+		}
+		if err := d.validateModel(path); err != nil {
+			return fmt.Errorf("invalid %s: %w", field, err)
+		}
+		if manifest == nil {
+			return nil
+		}
+		sum, err := allowlist.FileSum(path)
+		if err != nil {
+			return fmt.Errorf("hash %s: %w", field, err)
+		}
+		if !manifest.Allows(sum) {
+			return fmt.Errorf("%s %s (sha256 %s): %w", field, path, sum, allowlist.ErrNotApproved)
+		}
+		return nil
+	}
+	checkAll := func(model, draft, mmproj string, loras []string) error {
+		if err := check("model", model); err != nil {
+			return err
+		}
+		if err := check("draft model", draft); err != nil {
+			return err
+		}
+		if preset.IsMmprojActive(mmproj) {
+			if err := check("mmproj", mmproj); err != nil {
+				return err
+			}
+		}
+		for _, l := range loras {
+			if err := check("lora", l); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !p.IsRouter() {
+		return checkAll(p.Model, p.DraftModel, p.Mmproj, p.Lora)
+	}
+	for i, m := range p.Models {
+		if err := checkAll(m.Model, m.DraftModel, m.Mmproj, m.Lora); err != nil {
+			return fmt.Errorf("%w in models[%d]", err, i)
+		}
+	}
+	return nil
+}
+
+// modelFiles returns the local files a resolved preset makes llama-server
+// read: the model, draft model, active mmproj, and LoRA files of the preset
+// or of each router model.
+func modelFiles(p *preset.Preset) []string {
+	var files []string
+	add := func(model, draft, mmproj string, loras []string) {
+		refs := append([]string{model, draft}, loras...)
+		if preset.IsMmprojActive(mmproj) {
+			refs = append(refs, mmproj)
+		}
+		for _, ref := range refs {
+			if path, ok := strings.CutPrefix(ref, "f:"); ok {
+				files = append(files, path)
+			}
+		}
+	}
+	if !p.IsRouter() {
+		add(p.Model, p.DraftModel, p.Mmproj, p.Lora)
+	}
+	for _, m := range p.Models {
+		add(m.Model, m.DraftModel, m.Mmproj, m.Lora)
+	}
+	return files
+}
+
+// resolveModelRefs resolves HuggingFace references in a preset to local file
+// paths, and f: references to directories to the model file they hold.
+func (d *Daemon) resolveModelRefs(ctx context.Context, p *preset.Preset) (*preset.Preset, error) {
 	if p.IsRouter() {
 		return d.resolveRouterModels(ctx, p)
 	}
@@ -68,7 +211,7 @@ func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Pr
 		return nil, fmt.Errorf("invalid model field in preset: %w", err)
 	}
 
-	needsResolve := id.Type == identifier.TypeHuggingFace
+	needsResolve := id.Type == identifier.TypeHuggingFace || isModelDir(p.Model)
 
 	var draftID *identifier.Identifier
 	if p.DraftModel != "" {
@@ -77,11 +220,15 @@ func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Pr
 			return nil, fmt.Errorf("invalid draft-model field in preset: %w", err)
 		}
 		draftID = parsed
-		if parsed.Type == identifier.TypeHuggingFace {
+		if parsed.Type == identifier.TypeHuggingFace || isModelDir(p.DraftModel) {
 			needsResolve = true
 		}
 	}
 
+	if hasHFLora(p.Lora) {
+		needsResolve = true
+	}
+
 	if !needsResolve {
 		return p, nil
 	}
@@ -90,6 +237,11 @@ func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Pr
 	resolved := *p
 	resolved.Options = maps.Clone(p.Options)
 
+	resolved.Lora, err = d.resolveLoras(ctx, p.Lora)
+	if err != nil {
+		return nil, err
+	}
+
 	if id.Type == identifier.TypeHuggingFace {
 		modelPath, err := d.models.GetFilePath(ctx, id.Repo, id.Quant)
 		if err != nil {
@@ -98,6 +250,11 @@ func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Pr
 		resolved.Model = "f:" + modelPath
 
 		d.autoResolveMmproj(ctx, &resolved.Mmproj, modelPath, id.Repo, id.Quant, "")
+	} else if isModelDir(p.Model) {
+		resolved.Model, err = d.resolveModelDir(p.Model, &resolved.Mmproj, "")
+		if err != nil {
+			return nil, fmt.Errorf("resolve model: %w", err)
+		}
 	}
 
 	if draftID != nil && draftID.Type == identifier.TypeHuggingFace {
@@ -106,12 +263,18 @@ func (d *Daemon) resolveModel(ctx context.Context, p *preset.Preset) (*preset.Pr
 			return nil, fmt.Errorf("resolve draft model %s:%s: %w", draftID.Repo, draftID.Quant, err)
 		}
 		resolved.DraftModel = "f:" + draftPath
+	} else if isModelDir(p.DraftModel) {
+		resolved.DraftModel, err = d.resolveModelDir(p.DraftModel, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("resolve draft model: %w", err)
+		}
 	}
 
 	return &resolved, nil
 }
 
-// resolveRouterModels resolves HuggingFace model references in router mode Models[].
+// resolveRouterModels resolves HuggingFace model references and f: references
+// to directories in router mode Models[].
 func (d *Daemon) resolveRouterModels(ctx context.Context, p *preset.Preset) (*preset.Preset, error) {
 	// Validate all model identifiers and check if any need HF resolution.
 	needsResolve := false
@@ -120,7 +283,7 @@ func (d *Daemon) resolveRouterModels(ctx context.Context, p *preset.Preset) (*pr
 		if err != nil {
 			return nil, fmt.Errorf("invalid model field in models[%d]: %w", i, err)
 		}
-		if id.Type == identifier.TypeHuggingFace {
+		if id.Type == identifier.TypeHuggingFace || isModelDir(m.Model) {
 			needsResolve = true
 		}
 
@@ -129,10 +292,14 @@ func (d *Daemon) resolveRouterModels(ctx context.Context, p *preset.Preset) (*pr
 			if err != nil {
 				return nil, fmt.Errorf("invalid draft-model field in models[%d]: %w", i, err)
 			}
-			if did.Type == identifier.TypeHuggingFace {
+			if did.Type == identifier.TypeHuggingFace || isModelDir(m.DraftModel) {
 				needsResolve = true
 			}
 		}
+
+		if hasHFLora(m.Lora) {
+			needsResolve = true
+		}
 	}
 
 	if !needsResolve {
@@ -159,6 +326,12 @@ func (d *Daemon) resolveRouterModels(ctx context.Context, p *preset.Preset) (*pr
 			resolved.Models[i].Model = "f:" + modelPath
 
 			d.autoResolveMmproj(ctx, &resolved.Models[i].Mmproj, modelPath, id.Repo, id.Quant, m.Name)
+		} else if isModelDir(m.Model) {
+			path, err := d.resolveModelDir(m.Model, &resolved.Models[i].Mmproj, m.Name)
+			if err != nil {
+				return nil, fmt.Errorf("resolve model in models[%d]: %w", i, err)
+			}
+			resolved.Models[i].Model = path
 		}
 
 		if m.DraftModel != "" {
@@ -169,21 +342,73 @@ func (d *Daemon) resolveRouterModels(ctx context.Context, p *preset.Preset) (*pr
 					return nil, fmt.Errorf("resolve draft model %s:%s in models[%d]: %w", did.Repo, did.Quant, i, err)
 				}
 				resolved.Models[i].DraftModel = "f:" + draftPath
+			} else if isModelDir(m.DraftModel) {
+				path, err := d.resolveModelDir(m.DraftModel, nil, m.Name)
+				if err != nil {
+					return nil, fmt.Errorf("resolve draft model in models[%d]: %w", i, err)
+				}
+				resolved.Models[i].DraftModel = path
 			}
 		}
+
+		loras, err := d.resolveLoras(ctx, m.Lora)
+		if err != nil {
+			return nil, fmt.Errorf("%w in models[%d]", err, i)
+		}
+		resolved.Models[i].Lora = loras
 	}
 
 	return &resolved, nil
 }
 
+// hasHFLora reports whether any LoRA adapter reference uses HuggingFace format.
+func hasHFLora(loras []string) bool {
+	for _, l := range loras {
+		if strings.HasPrefix(l, "h:") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveLoras resolves HuggingFace LoRA adapter references (h:org/repo:file.gguf)
+// to local file paths. Returns a new slice; f: references are kept as-is.
+func (d *Daemon) resolveLoras(ctx context.Context, loras []string) ([]string, error) {
+	if len(loras) == 0 {
+		return loras, nil
+	}
+	resolved := make([]string, len(loras))
+	for i, l := range loras {
+		id, err := identifier.Parse(l)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lora field in preset: %w", err)
+		}
+		if id.Type != identifier.TypeHuggingFace {
+			resolved[i] = l
+			continue
+		}
+		loraPath, err := d.models.GetLoraFilePath(ctx, id.Repo, id.Quant)
+		if err != nil {
+			return nil, fmt.Errorf("resolve lora %s:%s: %w", id.Repo, id.Quant, err)
+		}
+		resolved[i] = "f:" + loraPath
+	}
+	return resolved, nil
+}
+
 // loadPreset parses the input identifier and loads the corresponding preset.
-// It resolves HuggingFace model references to local file paths.
-func (d *Daemon) loadPreset(ctx context.Context, input string) (*preset.Preset, error) {
+// It applies RunOptions overrides and resolves HuggingFace model references
+// to local file paths.
+func (d *Daemon) loadPreset(ctx context.Context, input string, opts RunOptions) (*preset.Preset, error) {
 	id, err := identifier.Parse(input)
 	if err != nil {
 		return nil, fmt.Errorf("parse identifier: %w", err)
 	}
 
+	if opts.Watch && id.Type != identifier.TypePresetName && id.Type != identifier.TypePresetFilePath {
+		return nil, fmt.Errorf("cannot watch %s: only presets can be watched", input)
+	}
+
 	var p *preset.Preset
 
 	switch id.Type {
@@ -208,33 +433,173 @@ func (d *Daemon) loadPreset(ctx context.Context, input string) (*preset.Preset,
 			return nil, fmt.Errorf("resolve HuggingFace model: %w", err)
 		}
 		// resolveHFPreset already returns a fully resolved local-file preset.
-		return p, nil
+		if opts.DraftModel == "" {
+			if err := d.validateModelFiles(ctx, p); err != nil {
+				return nil, fmt.Errorf("resolve model: %w", err)
+			}
+			return d.finalizePreset(p, opts)
+		}
 
 	default:
 		return nil, fmt.Errorf("unknown identifier type")
 	}
 
+	if opts.Watch && !p.Watch {
+		watched := *p
+		watched.Watch = true
+		p = &watched
+	}
+
+	if opts.DraftModel != "" {
+		if p.IsRouter() {
+			return nil, fmt.Errorf("draft model override is not supported for router presets")
+		}
+		overridden := *p
+		overridden.DraftModel = opts.DraftModel
+		p = &overridden
+	}
+
 	p, err = d.resolveModel(ctx, p)
 	if err != nil {
 		return nil, fmt.Errorf("resolve model: %w", err)
 	}
 
-	return p, nil
+	return d.finalizePreset(p, opts)
 }
 
-// prepareArgsAndConfig builds llama-server args and writes config.ini for router mode.
-func (d *Daemon) prepareArgsAndConfig(p *preset.Preset) ([]string, error) {
+// finalizePreset applies load-time settings to a preset whose models are
+// resolved to local files. With opts.Strict it refuses a ctx-size beyond a
+// model's trained context length.
+func (d *Daemon) finalizePreset(p *preset.Preset, opts RunOptions) (*preset.Preset, error) {
+	if err := d.checkGPUCount(p); err != nil {
+		return nil, err
+	}
+	if opts.Strict {
+		if warnings := d.contextWarnings(p); len(warnings) > 0 {
+			return nil, fmt.Errorf("%s (refused by --strict)", strings.Join(warnings, "; "))
+		}
+	}
+	p, err := d.resolveSlotSavePath(p)
+	if err != nil {
+		return nil, err
+	}
+	return d.resolveGPULayers(withLlamaServer(p, opts.LlamaServer))
+}
+
+// resolveSlotSavePath returns p with slot-save-path: auto replaced by the
+// preset's directory under the slot cache directory, or p itself.
+func (d *Daemon) resolveSlotSavePath(p *preset.Preset) (*preset.Preset, error) {
+	if p.SlotSavePath != preset.SlotSaveAuto {
+		return p, nil
+	}
+	if d.slotCacheDir == "" {
+		return nil, fmt.Errorf("slot-save-path: auto needs a cache directory")
+	}
+	resolved := *p
+	resolved.SlotSavePath = filepath.Join(d.slotCacheDir, p.Name)
+	return &resolved, nil
+}
+
+// llamaPriority returns the priority p sets for llama-server, logging the
+// fields this platform cannot apply.
+func (d *Daemon) llamaPriority(p *preset.Preset) llama.Priority {
+	cpus, _ := p.Priority.CPUs() // validated when the preset was loaded
+	pr := llama.Priority{Nice: p.Priority.Nice, CPUs: cpus, IO: p.Priority.IOPriority}
+	if unsupported := pr.Unsupported(); len(unsupported) > 0 {
+		d.logger.Warn("priority fields not supported on this platform; ignored", "preset", p.Name, "fields", strings.Join(unsupported, ", "))
+	}
+	return pr
+}
+
+// healthCheck returns the readiness probe p configures for llama-server.
+func healthCheck(p *preset.Preset) llama.HealthCheck {
+	return llama.HealthCheck{
+		Path:         p.Health.Path,
+		Method:       p.Health.Method,
+		ExpectStatus: p.Health.ExpectStatus,
+		ExpectJSON:   p.Health.ExpectJSON,
+	}
+}
+
+// withLlamaServer returns p with its llama-server binary overridden,
+// or p itself when path is empty.
+func withLlamaServer(p *preset.Preset, path string) *preset.Preset {
+	if path == "" {
+		return p
+	}
+	overridden := *p
+	overridden.LlamaServerPath = path
+	return &overridden
+}
+
+// OptionWarnings returns the options of p that the llama-server binary
+// serving it does not accept, or whose values do not fit, including a
+// ctx-size beyond a model's trained context length. Options are checked
+// against the binary only when option checks are enabled and its options
+// can be read.
+func (d *Daemon) OptionWarnings(ctx context.Context, p *preset.Preset) []string {
+	warnings := d.contextWarnings(p)
+	if d.optionSchema == nil {
+		return warnings
+	}
+	command := d.serverCommand(p)
+	schema, err := d.optionSchema(ctx, command)
+	if err != nil {
+		d.logger.Debug("llama-server options not checked", "command", command, "error", err)
+		return warnings
+	}
+	for _, err := range p.CheckOptions(schema.Check) {
+		warnings = append(warnings, err.Error())
+	}
+	return warnings
+}
+
+// prepareArgsAndConfig builds llama-server args and, in router mode,
+// writes the run's config.ini, returning its path. It creates the
+// slot-save-path directory, which llama-server expects to exist.
+func (d *Daemon) prepareArgsAndConfig(p *preset.Preset) ([]string, string, error) {
+	if p.SlotSavePath != "" {
+		if err := os.MkdirAll(p.SlotSavePath, 0755); err != nil {
+			return nil, "", fmt.Errorf("create slot-save-path: %w", err)
+		}
+	}
+
 	if p.IsRouter() {
 		d.logger.Info("loading router preset", "preset", p.Name, "models", len(p.Models))
 
-		content := p.GenerateConfigINI()
-		if err := atomicWriteFile(d.configPath, content); err != nil {
-			return nil, fmt.Errorf("write router config: %w", err)
+		// Recorded first, so the file is removed even if the daemon is
+		// killed before it can remove it
+		configPath := d.newRouterConfigPath()
+		if err := d.temps.add(configPath); err != nil {
+			d.logger.Warn("failed to record router config", "path", configPath, "error", err)
+		}
+		if err := atomicWriteFile(configPath, p.GenerateConfigINI()); err != nil {
+			d.cleanupRouterConfig(configPath)
+			return nil, "", fmt.Errorf("write router config: %w", err)
 		}
 
-		return p.BuildRouterArgs(d.configPath), nil
+		return p.BuildRouterArgs(configPath), configPath, nil
 	}
 
 	d.logger.Info("loading model", "preset", p.Name, "model", p.Model)
-	return p.BuildArgs(), nil
+	return p.BuildArgs(), "", nil
+}
+
+// newRouterConfigPath returns a path for a run's router config.ini, next
+// to the base path with a random suffix, e.g. router-config-9f86d081.ini.
+// Runs never share the file, so one being stopped cannot remove the file
+// of the next.
+func (d *Daemon) newRouterConfigPath() string {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return d.routerConfigPath(hex.EncodeToString(suffix))
+}
+
+// planRouterConfigSuffix stands in for the random suffix of the router
+// config.ini in a dry run, which writes no file.
+const planRouterConfigSuffix = "XXXXXXXX"
+
+// routerConfigPath returns the router config.ini path with suffix.
+func (d *Daemon) routerConfigPath(suffix string) string {
+	return strings.TrimSuffix(d.configPath, ".ini") + "-" + suffix + ".ini"
 }
@@ -2,9 +2,17 @@ package daemon
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/d2verb/alpaca/internal/allowlist"
+	"github.com/d2verb/alpaca/internal/gguf"
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/preset"
 )
@@ -612,3 +620,237 @@ func TestResolveModel_RouterModeWithExplicitMmproj(t *testing.T) {
 		t.Errorf("Models[0].Mmproj = %q, want %q (should preserve explicit)", resolved.Models[0].Mmproj, "f:/custom/mmproj.gguf")
 	}
 }
+
+func TestResolveModel_LoraHuggingFace(t *testing.T) {
+	// Arrange
+	models := &mapModelManager{
+		loras: map[string]string{
+			"org/lora-repo:adapter.gguf": "/resolved/org_lora-repo_adapter.gguf",
+		},
+	}
+	d := newTestDaemon(&stubPresetLoader{}, models)
+
+	p := &preset.Preset{
+		Name:  "lora-test",
+		Model: "f:/path/to/model.gguf",
+		Lora:  []string{"h:org/lora-repo:adapter.gguf", "f:/path/to/local.gguf"},
+	}
+
+	// Act
+	resolved, err := d.resolveModel(context.Background(), p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"f:/resolved/org_lora-repo_adapter.gguf", "f:/path/to/local.gguf"}
+	if len(resolved.Lora) != 2 || resolved.Lora[0] != want[0] || resolved.Lora[1] != want[1] {
+		t.Errorf("Lora = %v, want %v", resolved.Lora, want)
+	}
+	if p.Lora[0] != "h:org/lora-repo:adapter.gguf" {
+		t.Errorf("original preset mutated: Lora[0] = %q", p.Lora[0])
+	}
+}
+
+func TestResolveModel_LoraNotDownloaded(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &mapModelManager{})
+
+	p := &preset.Preset{
+		Name:  "lora-test",
+		Model: "f:/path/to/model.gguf",
+		Lora:  []string{"h:org/lora-repo:adapter.gguf"},
+	}
+
+	// Act
+	_, err := d.resolveModel(context.Background(), p)
+
+	// Assert
+	var notFound *metadata.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestResolveModel_RouterModeLora(t *testing.T) {
+	// Arrange
+	models := &mapModelManager{
+		loras: map[string]string{
+			"org/lora-repo:adapter.gguf": "/resolved/adapter.gguf",
+		},
+	}
+	d := newTestDaemon(&stubPresetLoader{}, models)
+
+	p := &preset.Preset{
+		Name: "router-lora",
+		Mode: "router",
+		Models: []preset.ModelEntry{
+			{Name: "tuned", Model: "f:/path/to/model.gguf", Lora: []string{"h:org/lora-repo:adapter.gguf"}},
+		},
+	}
+
+	// Act
+	resolved, err := d.resolveModel(context.Background(), p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := resolved.Models[0].Lora; len(got) != 1 || got[0] != "f:/resolved/adapter.gguf" {
+		t.Errorf("Models[0].Lora = %v, want [f:/resolved/adapter.gguf]", got)
+	}
+	if p.Models[0].Lora[0] != "h:org/lora-repo:adapter.gguf" {
+		t.Errorf("original preset mutated: Models[0].Lora = %v", p.Models[0].Lora)
+	}
+}
+
+func TestResolveModel_ValidatesGGUF(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.gguf")
+	header := append([]byte("GGUF"), 3, 0, 0, 0)
+	header = append(header, make([]byte, 16)...)
+	if err := os.WriteFile(valid, header, 0644); err != nil {
+		t.Fatal(err)
+	}
+	corrupt := filepath.Join(dir, "corrupt.gguf")
+	if err := os.WriteFile(corrupt, []byte("<html>error</html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		preset  *preset.Preset
+		wantErr string
+	}{
+		{
+			name:   "valid model",
+			preset: &preset.Preset{Name: "ok", Model: "f:" + valid},
+		},
+		{
+			name:    "corrupt model",
+			preset:  &preset.Preset{Name: "bad", Model: "f:" + corrupt},
+			wantErr: "invalid model: " + corrupt + ": corrupted or not a GGUF file",
+		},
+		{
+			name:    "corrupt draft model",
+			preset:  &preset.Preset{Name: "bad", Model: "f:" + valid, DraftModel: "f:" + corrupt},
+			wantErr: "invalid draft model",
+		},
+		{
+			name: "corrupt router model",
+			preset: &preset.Preset{Name: "router", Mode: "router", Models: []preset.ModelEntry{
+				{Name: "a", Model: "f:" + valid},
+				{Name: "b", Model: "f:" + corrupt},
+			}},
+			wantErr: "in models[1]",
+		},
+		{
+			name:    "missing file",
+			preset:  &preset.Preset{Name: "missing", Model: "f:" + filepath.Join(dir, "missing.gguf")},
+			wantErr: "file not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.validateModel = gguf.Validate
+
+			// Act
+			_, err := d.resolveModel(context.Background(), tt.preset)
+
+			// Assert
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("resolveModel() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("resolveModel() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveModel_AllowList(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	approved := filepath.Join(dir, "approved.gguf")
+	other := filepath.Join(dir, "other.gguf")
+	os.WriteFile(approved, []byte("approved weights"), 0644)
+	os.WriteFile(other, []byte("other weights"), 0644)
+	sum := sha256.Sum256([]byte("approved weights"))
+	manifest := hex.EncodeToString(sum[:]) + "  approved.gguf\n"
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(dir, "manifest.txt")
+	os.WriteFile(manifestPath, []byte(manifest), 0644)
+	os.WriteFile(manifestPath+".sig", ed25519.Sign(priv, []byte(manifest)), 0644)
+	src, err := allowlist.NewSource(manifestPath, hex.EncodeToString(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		model   string
+		wantErr bool
+	}{
+		{"listed", approved, false},
+		{"not listed", other, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.SetAllowList(src)
+
+			// Act
+			_, err := d.resolveModel(context.Background(), &preset.Preset{Name: "test", Model: "f:" + tt.model})
+
+			// Assert
+			if tt.wantErr != errors.Is(err, allowlist.ErrNotApproved) {
+				t.Errorf("resolveModel() error = %v, want ErrNotApproved: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("resolveModel() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestResolveModel_Directory(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	header := append([]byte("GGUF"), 3, 0, 0, 0)
+	header = append(header, make([]byte, 16)...)
+	for _, name := range []string{"qwen3-Q4_K_M.gguf", "mmproj-F16.gguf"} {
+		if err := os.WriteFile(filepath.Join(dir, name), header, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	p := &preset.Preset{Name: "test", Model: "f:" + dir}
+
+	// Act
+	resolved, err := d.resolveModel(context.Background(), p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "f:" + filepath.Join(dir, "qwen3-Q4_K_M.gguf"); resolved.Model != want {
+		t.Errorf("Model = %q, want %q", resolved.Model, want)
+	}
+	if want := "f:" + filepath.Join(dir, "mmproj-F16.gguf"); resolved.Mmproj != want {
+		t.Errorf("Mmproj = %q, want %q", resolved.Mmproj, want)
+	}
+	if p.Model != "f:"+dir {
+		t.Errorf("Original preset mutated: Model = %q", p.Model)
+	}
+}
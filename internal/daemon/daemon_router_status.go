@@ -2,15 +2,23 @@ package daemon
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
+	"errors"
+	"net/url"
+	"sync"
 	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
 )
 
 // RouterModelStatus represents the status of a single model in router mode.
 type RouterModelStatus struct {
 	ID     string            `json:"id"`
 	Status routerModelStatus `json:"status"`
+
+	// Load of a loaded model, from its /metrics; nil when llama-server
+	// did not report it.
+	ActiveSlots *int `json:"-"` // slots processing a request
+	Queued      *int `json:"-"` // requests waiting for a slot
 }
 
 // routerModelStatus wraps the status object from llama-server's /models API.
@@ -19,42 +27,97 @@ type routerModelStatus struct {
 	Value string `json:"value"` // "loaded", "loading", "unloaded"
 }
 
+const (
+	// routerStatusTTL is how long a /models answer is reused, so clients
+	// polling status (status --watch, alpaca ui) do not query llama-server
+	// on every request.
+	routerStatusTTL = time.Second
+
+	// routerStatusAttempts bounds the queries of one fetch; llama-server
+	// can briefly refuse connections while it starts or stops a model.
+	routerStatusAttempts = 3
+	routerStatusRetryGap = 100 * time.Millisecond
+)
+
+// routerStatusCache holds the last successful /models answer.
+type routerStatusCache struct {
+	mu        sync.Mutex
+	preset    *preset.Preset // preset the answer is for; a reload invalidates it
+	fetchedAt time.Time
+	statuses  []RouterModelStatus
+}
+
 // FetchModelStatuses queries the running llama-server's /models endpoint
-// to get the status of each model in router mode.
-// Returns nil for non-router presets or on any error (graceful degradation).
-func (d *Daemon) FetchModelStatuses(ctx context.Context) []RouterModelStatus {
+// to get the status of each model in router mode. Answers are cached for
+// routerStatusTTL and failed queries retried a few times.
+// Returns nil and no error for non-router presets; the error explains why
+// llama-server could not be queried.
+func (d *Daemon) FetchModelStatuses(ctx context.Context) ([]RouterModelStatus, error) {
 	p := d.CurrentPreset()
 	if p == nil || !p.IsRouter() {
-		return nil
+		return nil, nil
+	}
+
+	c := &d.routerStatus
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.preset == p && time.Since(c.fetchedAt) < routerStatusTTL {
+		return c.statuses, nil
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Endpoint()+"/models", nil)
-	if err != nil {
-		return nil
-	}
-
-	resp, err := d.httpClient.Do(req)
-	if err != nil {
-		return nil
+	var err error
+	for attempt := 1; ; attempt++ {
+		// Parse the response: {"data": [{"id": "...", "status": "..."}]}
+		var body struct {
+			Data []RouterModelStatus `json:"data"`
+		}
+		if err = d.getJSON(ctx, p.Endpoint()+"/models", &body); err == nil {
+			statuses := body.Data
+			if statuses == nil {
+				statuses = []RouterModelStatus{}
+			}
+			d.fetchModelLoad(ctx, p, statuses)
+			c.preset, c.fetchedAt, c.statuses = p, time.Now(), statuses
+			return statuses, nil
+		}
+		if attempt == routerStatusAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(routerStatusRetryGap):
+		}
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, errors.New("llama-server did not answer in time")
 	}
+	return nil, err
+}
 
-	// Parse the response: {"data": [{"id": "...", "status": "..."}]}
-	// Limit response body to 1MB to prevent excessive memory usage
-	limitedBody := http.MaxBytesReader(nil, resp.Body, 1<<20)
-	var body struct {
-		Data []RouterModelStatus `json:"data"`
-	}
-	if err := json.NewDecoder(limitedBody).Decode(&body); err != nil {
-		return nil
+// fetchModelLoad fills in the active slots and queued requests of the
+// loaded models in statuses. The router forwards /metrics?model=<id> to the
+// model's llama-server; builds that do not, and models running without
+// --metrics, leave them unknown.
+func (d *Daemon) fetchModelLoad(ctx context.Context, p *preset.Preset, statuses []RouterModelStatus) {
+	for i := range statuses {
+		s := &statuses[i]
+		if s.Status.Value != "loaded" {
+			continue
+		}
+		values, err := d.fetchPrometheus(ctx, p.Endpoint()+"/metrics?model="+url.QueryEscape(s.ID))
+		if err != nil {
+			continue
+		}
+		if v, ok := values[metricRequestsProcessing]; ok {
+			n := int(v)
+			s.ActiveSlots = &n
+		}
+		if v, ok := values[metricRequestsDeferred]; ok {
+			n := int(v)
+			s.Queued = &n
+		}
 	}
-
-	return body.Data
 }
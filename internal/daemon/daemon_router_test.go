@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/d2verb/alpaca/internal/preset"
@@ -56,8 +57,12 @@ func TestDaemonRun_RouterModeSuccess(t *testing.T) {
 		t.Errorf("State() = %q, want %q", d.State(), StateRunning)
 	}
 
-	// Verify config.ini was written
-	content, err := os.ReadFile(configPath)
+	// Verify config.ini was written under a name of the run's own, next to configPath
+	runConfig := routerConfigArg(t, mockProc.receivedArgs)
+	if runConfig == configPath || filepath.Dir(runConfig) != configDir || !strings.HasPrefix(filepath.Base(runConfig), "router-config-") {
+		t.Errorf("--models-preset = %s, want a run-scoped file next to %s", runConfig, configPath)
+	}
+	content, err := os.ReadFile(runConfig)
 	if err != nil {
 		t.Fatalf("config.ini not written: %v", err)
 	}
@@ -67,18 +72,6 @@ func TestDaemonRun_RouterModeSuccess(t *testing.T) {
 	if !strings.Contains(string(content), "[mistral]") {
 		t.Errorf("config.ini missing [mistral] section")
 	}
-
-	// Verify BuildRouterArgs was used (should contain --models-preset)
-	foundModelsPreset := false
-	for _, arg := range mockProc.receivedArgs {
-		if arg == "--models-preset" {
-			foundModelsPreset = true
-			break
-		}
-	}
-	if !foundModelsPreset {
-		t.Errorf("args should contain --models-preset, got %v", mockProc.receivedArgs)
-	}
 }
 
 func TestDaemonRun_RouterModeResolvesModels(t *testing.T) {
@@ -124,7 +117,7 @@ func TestDaemonRun_RouterModeResolvesModels(t *testing.T) {
 	}
 
 	// Verify config.ini contains resolved path
-	content, err := os.ReadFile(configPath)
+	content, err := os.ReadFile(routerConfigArg(t, mockProc.receivedArgs))
 	if err != nil {
 		t.Fatalf("config.ini not written: %v", err)
 	}
@@ -242,7 +235,8 @@ func TestDaemonKill_CleansUpConfigFile(t *testing.T) {
 	}
 
 	// Verify config.ini exists
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	runConfig := routerConfigArg(t, mockProc.receivedArgs)
+	if _, err := os.Stat(runConfig); os.IsNotExist(err) {
 		t.Fatal("config.ini should exist after Run()")
 	}
 
@@ -253,7 +247,7 @@ func TestDaemonKill_CleansUpConfigFile(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Kill() failed: %v", err)
 	}
-	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+	if _, err := os.Stat(runConfig); !os.IsNotExist(err) {
 		t.Error("config.ini should be cleaned up after Kill()")
 	}
 	if d.State() != StateIdle {
@@ -261,9 +255,35 @@ func TestDaemonKill_CleansUpConfigFile(t *testing.T) {
 	}
 }
 
+// routerDaemon returns a daemon running a router preset served by srv.
+func routerDaemon(t *testing.T, srv *httptest.Server) *Daemon {
+	t.Helper()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse test server URL: %v", err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.httpClient = srv.Client()
+	d.setSnapshot(StateRunning, &preset.Preset{
+		Mode: "router",
+		Host: u.Hostname(),
+		Port: port,
+	})
+	return d
+}
+
 func TestFetchModelStatuses_Success(t *testing.T) {
 	// Arrange
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			if model := r.URL.Query().Get("model"); model != "qwen3" {
+				t.Errorf("metrics requested for %q, want only the loaded qwen3", model)
+			}
+			w.Write([]byte("llamacpp:requests_processing 2\nllamacpp:requests_deferred 5\n"))
+			return
+		}
 		if r.URL.Path != "/models" {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 		}
@@ -276,27 +296,15 @@ func TestFetchModelStatuses_Success(t *testing.T) {
 		json.NewEncoder(w).Encode(resp)
 	}))
 	defer srv.Close()
-
-	// Parse srv.URL to get host and port
-	u, err := url.Parse(srv.URL)
-	if err != nil {
-		t.Fatalf("parse test server URL: %v", err)
-	}
-	port, _ := strconv.Atoi(u.Port())
-
-	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
-	d.httpClient = srv.Client()
-	// Set a router preset pointing to the mock server
-	d.setSnapshot(StateRunning, &preset.Preset{
-		Mode: "router",
-		Host: u.Hostname(),
-		Port: port,
-	})
+	d := routerDaemon(t, srv)
 
 	// Act
-	statuses := d.FetchModelStatuses(context.Background())
+	statuses, err := d.FetchModelStatuses(context.Background())
 
 	// Assert
+	if err != nil {
+		t.Fatalf("FetchModelStatuses() error = %v", err)
+	}
 	if len(statuses) != 2 {
 		t.Fatalf("len(statuses) = %d, want 2", len(statuses))
 	}
@@ -306,6 +314,36 @@ func TestFetchModelStatuses_Success(t *testing.T) {
 	if statuses[1].ID != "gemma3" || statuses[1].Status.Value != "unloaded" {
 		t.Errorf("statuses[1] = {ID:%s, Status:%s}, want {ID:gemma3, Status:unloaded}", statuses[1].ID, statuses[1].Status.Value)
 	}
+	if a, q := statuses[0].ActiveSlots, statuses[0].Queued; a == nil || *a != 2 || q == nil || *q != 5 {
+		t.Errorf("qwen3 active slots = %v, queued = %v, want 2 and 5", a, q)
+	}
+	if statuses[1].ActiveSlots != nil || statuses[1].Queued != nil {
+		t.Error("unloaded model has a load")
+	}
+}
+
+func TestFetchModelStatuses_MetricsUnavailable(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metrics" {
+			http.Error(w, "metrics not enabled", http.StatusNotImplemented)
+			return
+		}
+		w.Write([]byte(`{"data": [{"id": "qwen3", "status": {"value": "loaded"}}]}`))
+	}))
+	defer srv.Close()
+	d := routerDaemon(t, srv)
+
+	// Act
+	statuses, err := d.FetchModelStatuses(context.Background())
+
+	// Assert
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("FetchModelStatuses() = %v, %v, want qwen3", statuses, err)
+	}
+	if statuses[0].ActiveSlots != nil || statuses[0].Queued != nil {
+		t.Errorf("active slots = %v, queued = %v, want unknown", statuses[0].ActiveSlots, statuses[0].Queued)
+	}
 }
 
 func TestFetchModelStatuses_NonRouterReturnsNil(t *testing.T) {
@@ -317,11 +355,11 @@ func TestFetchModelStatuses_NonRouterReturnsNil(t *testing.T) {
 	})
 
 	// Act
-	statuses := d.FetchModelStatuses(context.Background())
+	statuses, err := d.FetchModelStatuses(context.Background())
 
 	// Assert
-	if statuses != nil {
-		t.Errorf("expected nil for non-router preset, got %v", statuses)
+	if statuses != nil || err != nil {
+		t.Errorf("FetchModelStatuses() = %v, %v, want nil, nil for non-router preset", statuses, err)
 	}
 }
 
@@ -330,41 +368,86 @@ func TestFetchModelStatuses_NoPresetReturnsNil(t *testing.T) {
 	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
 
 	// Act
-	statuses := d.FetchModelStatuses(context.Background())
+	statuses, err := d.FetchModelStatuses(context.Background())
 
 	// Assert
-	if statuses != nil {
-		t.Errorf("expected nil when no preset loaded, got %v", statuses)
+	if statuses != nil || err != nil {
+		t.Errorf("FetchModelStatuses() = %v, %v, want nil, nil when no preset loaded", statuses, err)
 	}
 }
 
-func TestFetchModelStatuses_ServerErrorReturnsNil(t *testing.T) {
+func TestFetchModelStatuses_ServerError(t *testing.T) {
 	// Arrange
+	var requests atomic.Int32
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("internal server error"))
 	}))
 	defer srv.Close()
+	d := routerDaemon(t, srv)
 
-	u, err := url.Parse(srv.URL)
+	// Act
+	statuses, err := d.FetchModelStatuses(context.Background())
+
+	// Assert
+	if statuses != nil || err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("FetchModelStatuses() = %v, %v, want an error naming the status", statuses, err)
+	}
+	if got := requests.Load(); got != routerStatusAttempts {
+		t.Errorf("requests = %d, want %d", got, routerStatusAttempts)
+	}
+}
+
+func TestFetchModelStatuses_RetriesTransientFailure(t *testing.T) {
+	// Arrange
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data": []}`))
+	}))
+	defer srv.Close()
+	d := routerDaemon(t, srv)
+
+	// Act
+	statuses, err := d.FetchModelStatuses(context.Background())
+
+	// Assert
 	if err != nil {
-		t.Fatalf("parse test server URL: %v", err)
+		t.Fatalf("FetchModelStatuses() error = %v", err)
 	}
-	port, _ := strconv.Atoi(u.Port())
+	if statuses == nil || len(statuses) != 0 {
+		t.Errorf("statuses = %#v, want empty and non-nil (no models is not an error)", statuses)
+	}
+}
 
-	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
-	d.httpClient = srv.Client()
-	d.setSnapshot(StateRunning, &preset.Preset{
-		Mode: "router",
-		Host: u.Hostname(),
-		Port: port,
-	})
+func TestFetchModelStatuses_Cached(t *testing.T) {
+	// Arrange
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/models" {
+			requests.Add(1)
+		}
+		w.Write([]byte(`{"data": [{"id": "qwen3", "status": {"value": "loaded"}}]}`))
+	}))
+	defer srv.Close()
+	d := routerDaemon(t, srv)
 
 	// Act
-	statuses := d.FetchModelStatuses(context.Background())
+	d.FetchModelStatuses(context.Background())
+	statuses, err := d.FetchModelStatuses(context.Background())
+	reloaded := *d.CurrentPreset()
+	d.setSnapshot(StateRunning, &reloaded)
+	d.FetchModelStatuses(context.Background())
 
 	// Assert
-	if statuses != nil {
-		t.Errorf("expected nil on server error, got %v", statuses)
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("FetchModelStatuses() = %v, %v, want the cached answer", statuses, err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2 (cached once, refetched after reload)", got)
 	}
 }
@@ -2,9 +2,12 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/preset"
 )
 
@@ -280,3 +283,78 @@ func TestDaemonRun_PresetWithHFModelNotFound(t *testing.T) {
 		t.Error("Process.Start() should not be called when model resolution fails")
 	}
 }
+
+func TestDaemonCancelLoad(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"big": {Name: "big", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+	}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess { return mockProc }
+	waiting := make(chan struct{})
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		close(waiting)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if d.CancelLoad() {
+		t.Fatal("CancelLoad() = true with no load in progress")
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- d.Run(context.Background(), "p:big") }()
+	<-waiting
+
+	// Act
+	canceled := d.CancelLoad()
+
+	// Assert
+	if !canceled {
+		t.Error("CancelLoad() = false during a load")
+	}
+	if err := <-errc; !errors.Is(err, ErrCanceled) {
+		t.Fatalf("Run() error = %v, want ErrCanceled", err)
+	}
+	if d.State() != StateIdle {
+		t.Errorf("State() = %q, want %q", d.State(), StateIdle)
+	}
+	if !mockProc.stopCalled {
+		t.Error("Process.Stop() should be called when the load is canceled")
+	}
+}
+
+func TestDaemonRun_ProcessExitIncludesOutput(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf"},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+
+	done := make(chan struct{})
+	close(done)
+	d.newProcess = func(path string) llamaProcess {
+		return &mockProcess{
+			doneCh:    done,
+			exitError: fmt.Errorf("exit status 1"),
+			output:    []string{"ggml_backend_cuda_buffer_type_alloc_buffer: allocating 9000 MiB on device 0: cudaMalloc failed: out of memory"},
+		}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:test-preset")
+
+	// Assert
+	var processErr *llama.ProcessError
+	if !errors.As(err, &processErr) {
+		t.Fatalf("Run() error = %v, want a ProcessError", err)
+	}
+	if len(processErr.Output) != 1 || !strings.Contains(err.Error(), "llama-server output:\n  ggml_backend_cuda_buffer_type_alloc_buffer") {
+		t.Errorf("Run() error = %q, want llama-server's output", err)
+	}
+}
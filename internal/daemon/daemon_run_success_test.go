@@ -2,8 +2,13 @@ package daemon
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"testing"
 
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/preset"
 )
 
@@ -52,6 +57,71 @@ func TestDaemonRun_PresetNameSuccess(t *testing.T) {
 	}
 }
 
+func TestDaemonRun_Priority(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"background": {
+				Name:     "background",
+				Model:    "f:/path/to/model.gguf",
+				Priority: preset.Priority{Nice: 10, CPUAffinity: "0-1", IOPriority: preset.IOPriorityIdle},
+			},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess {
+		return mockProc
+	}
+	d.waitForReady = mockHealthChecker(nil)
+
+	// Act
+	err := d.Run(context.Background(), "p:background")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := llama.Priority{Nice: 10, CPUs: []int{0, 1}, IO: "idle"}
+	if !reflect.DeepEqual(mockProc.priority, want) {
+		t.Errorf("priority = %+v, want %+v", mockProc.priority, want)
+	}
+}
+
+func TestDaemonRun_HealthCheck(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"sidecar": {
+				Name:   "sidecar",
+				Model:  "f:/path/to/model.gguf",
+				Health: preset.Health{Path: "/ready", ExpectStatus: 204, ExpectJSON: map[string]string{"status": "ok"}},
+			},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.newProcess = func(path string) llamaProcess {
+		return &mockProcess{}
+	}
+	var got llama.HealthCheck
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		got = opts.Check
+		return nil
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:sidecar")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := llama.HealthCheck{Path: "/ready", ExpectStatus: 204, ExpectJSON: map[string]string{"status": "ok"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("health check = %+v, want %+v", got, want)
+	}
+}
+
 func TestDaemonRun_FilePathSuccess(t *testing.T) {
 	// Arrange
 	presets := &stubPresetLoader{}
@@ -130,3 +200,35 @@ func TestDaemonRun_HuggingFaceSuccess(t *testing.T) {
 		t.Errorf("Preset.Model = %q, want %q", preset.Model, "f:/models/codellama-7b.Q4_K_M.gguf")
 	}
 }
+
+func TestDaemonRun_SlotSavePathAuto(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"coder": {Name: "coder", Model: "f:/path/to/model.gguf", SlotSavePath: preset.SlotSaveAuto},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+	cacheDir := t.TempDir()
+	d.SetSlotCacheDir(cacheDir)
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess {
+		return mockProc
+	}
+	d.waitForReady = mockHealthChecker(nil)
+
+	// Act
+	err := d.Run(context.Background(), "p:coder")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slotDir := filepath.Join(cacheDir, "coder")
+	if info, err := os.Stat(slotDir); err != nil || !info.IsDir() {
+		t.Errorf("slot directory %s not created: %v", slotDir, err)
+	}
+	if i := slices.Index(mockProc.receivedArgs, "--slot-save-path"); i < 0 || mockProc.receivedArgs[i+1] != slotDir {
+		t.Errorf("args = %v, want --slot-save-path %s", mockProc.receivedArgs, slotDir)
+	}
+}
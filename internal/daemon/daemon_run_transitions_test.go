@@ -2,11 +2,13 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/preset"
 )
 
@@ -201,7 +203,7 @@ func TestDaemonRun_ProcessDiesDuringStartup(t *testing.T) {
 	d.newProcess = func(path string) llamaProcess {
 		return mockProc
 	}
-	d.waitForReady = func(ctx context.Context, endpoint string) error {
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
 		<-ctx.Done()
 		return ctx.Err()
 	}
@@ -239,13 +241,13 @@ func TestDaemonRun_StartupTimeout(t *testing.T) {
 	}
 	models := &stubModelManager{}
 	d := newTestDaemon(presets, models)
-	d.startupTimeout = 50 * time.Millisecond // short timeout for test
+	d.SetStartupTimeout(50 * time.Millisecond) // short timeout for test
 
 	mockProc := &mockProcess{}
 	d.newProcess = func(path string) llamaProcess {
 		return mockProc
 	}
-	d.waitForReady = func(ctx context.Context, endpoint string) error {
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
 		<-ctx.Done()
 		return ctx.Err()
 	}
@@ -260,11 +262,115 @@ func TestDaemonRun_StartupTimeout(t *testing.T) {
 	if !strings.Contains(err.Error(), "did not become ready") {
 		t.Errorf("error should contain user-friendly timeout message, got: %s", err)
 	}
+	if !strings.Contains(err.Error(), "50ms (raise startup-timeout") {
+		t.Errorf("error should name the timeout and how to raise it, got: %s", err)
+	}
+	if d.State() != StateIdle {
+		t.Errorf("State() = %q, want %q", d.State(), StateIdle)
+	}
+}
+
+func TestDaemonRun_WarmupGatesRunning(t *testing.T) {
+	// Arrange
+	testPreset := &preset.Preset{
+		Name:         "test-preset",
+		Model:        "f:/path/to/model.gguf",
+		Warmup:       true,
+		WarmupPrompt: "Hi",
+	}
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{"test-preset": testPreset}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	warmupStarted := make(chan string, 1)
+	release := make(chan struct{})
+	d.warmUp = func(ctx context.Context, endpoint, prompt string) error {
+		warmupStarted <- prompt
+		<-release
+		return nil
+	}
+
+	// Act
+	runDone := make(chan error, 1)
+	go func() { runDone <- d.Run(context.Background(), "p:test-preset") }()
+	prompt := <-warmupStarted
+	stateDuringWarmup := d.State()
+	close(release)
+	err := <-runDone
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if prompt != "Hi" {
+		t.Errorf("warmup prompt = %q, want %q", prompt, "Hi")
+	}
+	if stateDuringWarmup != StateLoading {
+		t.Errorf("State() during warmup = %q, want %q", stateDuringWarmup, StateLoading)
+	}
+	if d.State() != StateRunning {
+		t.Errorf("State() = %q, want %q", d.State(), StateRunning)
+	}
+}
+
+func TestDaemonRun_WarmupFailure(t *testing.T) {
+	// Arrange
+	testPreset := &preset.Preset{Name: "test-preset", Model: "f:/path/to/model.gguf", Warmup: true}
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{"test-preset": testPreset}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess { return mockProc }
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	d.warmUp = func(ctx context.Context, endpoint, prompt string) error {
+		return errors.New("warmup completion: 500 Internal Server Error: out of memory")
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:test-preset")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "out of memory") {
+		t.Fatalf("Run() error = %v, want warmup failure", err)
+	}
+	if !mockProc.stopCalled {
+		t.Error("llama-server should be stopped after a failed warmup")
+	}
 	if d.State() != StateIdle {
 		t.Errorf("State() = %q, want %q", d.State(), StateIdle)
 	}
 }
 
+func TestDaemonStartupTimeoutFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		configTimeout time.Duration
+		presetSeconds int
+		want          time.Duration
+	}{
+		{"default", 0, 0, defaultStartupTimeout},
+		{"config", 3 * time.Minute, 0, 3 * time.Minute},
+		{"preset overrides config", 3 * time.Minute, 600, 10 * time.Minute},
+		{"preset overrides default", 0, 90, 90 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+			d.SetStartupTimeout(tt.configTimeout)
+			p := &preset.Preset{Name: "p", Model: "f:/m.gguf", StartupTimeout: tt.presetSeconds}
+
+			// Act
+			got := d.startupTimeoutFor(p)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("startupTimeoutFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDaemonKill_DuringStartup(t *testing.T) {
 	// Arrange
 	testPreset := &preset.Preset{
@@ -286,7 +392,7 @@ func TestDaemonKill_DuringStartup(t *testing.T) {
 	d.newProcess = func(path string) llamaProcess {
 		return mockProc
 	}
-	d.waitForReady = func(ctx context.Context, endpoint string) error {
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
 		close(healthCheckStarted)
 		<-ctx.Done()
 		return ctx.Err()
@@ -348,7 +454,7 @@ func TestDaemonRun_CancelsPreviousStartup(t *testing.T) {
 	d.newProcess = func(path string) llamaProcess {
 		return &mockProcess{}
 	}
-	d.waitForReady = func(ctx context.Context, endpoint string) error {
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
 		if firstCall {
 			firstCall = false
 			close(healthCheckStarted)
@@ -387,3 +493,33 @@ func TestDaemonRun_CancelsPreviousStartup(t *testing.T) {
 		t.Fatal("first Run() did not return after cancellation")
 	}
 }
+
+func TestDaemonRun_LoadingDetail(t *testing.T) {
+	// Arrange
+	testPreset := &preset.Preset{Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080}
+	d := newTestDaemon(&stubPresetLoader{presets: map[string]*preset.Preset{"test-preset": testPreset}}, &stubModelManager{})
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{doneCh: make(chan struct{})} }
+	var duringLoad RuntimeStatus
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		if opts.Exited == nil {
+			t.Error("Exited should be the process's done channel")
+		}
+		opts.Progress("loading model 43%")
+		duringLoad = d.StatusSnapshot()
+		return nil
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:test-preset")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if duringLoad.State != StateLoading || duringLoad.Detail != "loading model 43%" {
+		t.Errorf("status while loading = %v %q, want loading with detail", duringLoad.State, duringLoad.Detail)
+	}
+	if got := d.StatusSnapshot(); got.State != StateRunning || got.Detail != "" {
+		t.Errorf("status when ready = %v %q, want running without detail", got.State, got.Detail)
+	}
+}
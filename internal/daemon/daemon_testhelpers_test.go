@@ -3,14 +3,18 @@ package daemon
 import (
 	"context"
 	"io"
+	"testing"
+	"time"
 
 	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
 )
 
 type stubPresetLoader struct {
 	presets map[string]*preset.Preset
+	paths   map[string]string // preset files by name, for FindPath
 	names   []string
 	listErr error
 }
@@ -23,6 +27,14 @@ func (s *stubPresetLoader) Load(name string) (*preset.Preset, error) {
 	return p, nil
 }
 
+func (s *stubPresetLoader) FindPath(name string) (string, error) {
+	path, ok := s.paths[name]
+	if !ok {
+		return "", &preset.NotFoundError{Name: name}
+	}
+	return path, nil
+}
+
 func (s *stubPresetLoader) List() ([]string, error) {
 	if s.listErr != nil {
 		return nil, s.listErr
@@ -63,12 +75,36 @@ func (s *stubModelManager) GetDetails(ctx context.Context, repo, quant string) (
 	return nil, &metadata.NotFoundError{Repo: repo, Quant: quant}
 }
 
+func (s *stubModelManager) GetLoraFilePath(ctx context.Context, repo, file string) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	return "", &metadata.NotFoundError{Repo: repo, Quant: file}
+}
+
+// newTestDaemon creates a daemon whose model file validation is a no-op,
+// so tests can reference model paths that do not exist.
 func newTestDaemon(presets presetLoader, models modelManager) *Daemon {
-	return New(presets, models, "", io.Discard, io.Discard)
+	return newTestDaemonWithConfigPath(presets, models, "")
 }
 
 func newTestDaemonWithConfigPath(presets presetLoader, models modelManager, configPath string) *Daemon {
-	return New(presets, models, configPath, io.Discard, io.Discard)
+	d := New(presets, models, configPath, io.Discard, io.Discard)
+	d.validateModel = func(string) error { return nil }
+	return d
+}
+
+// routerConfigArg returns the config.ini path llama-server was given with
+// --models-preset.
+func routerConfigArg(t *testing.T, args []string) string {
+	t.Helper()
+	for i, arg := range args {
+		if arg == "--models-preset" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	t.Fatalf("args should contain --models-preset, got %v", args)
+	return ""
 }
 
 // mockProcess is a mock implementation of llamaProcess for testing.
@@ -78,9 +114,16 @@ type mockProcess struct {
 	startCalled  bool
 	stopCalled   bool
 	logWriter    io.Writer
+	priority     llama.Priority
 	receivedArgs []string
 	doneCh       chan struct{}
 	exitError    error
+	output       []string
+	logTail      []string
+	pid          int
+
+	ignoresSIGTERM bool          // Terminate reports a kill
+	stopGrace      time.Duration // grace passed to the last Terminate
 }
 
 func (m *mockProcess) Start(args []string) error {
@@ -97,10 +140,24 @@ func (m *mockProcess) Stop(ctx context.Context) error {
 	return m.stopErr
 }
 
+// Terminate exits gracefully unless ignoresSIGTERM is set or grace is zero.
+func (m *mockProcess) Terminate(ctx context.Context, grace time.Duration) (bool, error) {
+	m.stopCalled = true
+	m.stopGrace = grace
+	if m.stopErr != nil {
+		return false, m.stopErr
+	}
+	return grace > 0 && !m.ignoresSIGTERM, nil
+}
+
 func (m *mockProcess) SetLogWriter(w io.Writer) {
 	m.logWriter = w
 }
 
+func (m *mockProcess) SetPriority(pr llama.Priority) {
+	m.priority = pr
+}
+
 // Done returns doneCh. When doneCh is nil (default), the returned nil channel
 // blocks forever in select, simulating a process that never exits.
 func (m *mockProcess) Done() <-chan struct{} {
@@ -111,9 +168,21 @@ func (m *mockProcess) ExitErr() error {
 	return m.exitError
 }
 
+func (m *mockProcess) Output() []string {
+	return m.output
+}
+
+func (m *mockProcess) LogTail() []string {
+	return m.logTail
+}
+
+func (m *mockProcess) Pid() int {
+	return m.pid
+}
+
 // mockHealthChecker returns a health checker function that can be configured to succeed or fail.
 func mockHealthChecker(err error) healthChecker {
-	return func(ctx context.Context, endpoint string) error {
+	return func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
 		return err
 	}
 }
@@ -122,6 +191,7 @@ func mockHealthChecker(err error) healthChecker {
 type mapModelManager struct {
 	paths   map[string]string               // key: "repo:quant", value: file path
 	entries map[string]*metadata.ModelEntry // key: "repo:quant", optional detailed entries
+	loras   map[string]string               // key: "repo:file", value: file path
 }
 
 func (m *mapModelManager) List(ctx context.Context) ([]metadata.ModelEntry, error) {
@@ -150,3 +220,21 @@ func (m *mapModelManager) GetDetails(ctx context.Context, repo, quant string) (*
 	}
 	return nil, &metadata.NotFoundError{Repo: repo, Quant: quant}
 }
+
+func (m *mapModelManager) GetLoraFilePath(ctx context.Context, repo, file string) (string, error) {
+	path, ok := m.loras[repo+":"+file]
+	if !ok {
+		return "", &metadata.NotFoundError{Repo: repo, Quant: file}
+	}
+	return path, nil
+}
+
+// decodeData decodes the data of resp into a T.
+func decodeData[T any](t *testing.T, resp *protocol.Response) T {
+	t.Helper()
+	var data T
+	if err := resp.Decode(&data); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return data
+}
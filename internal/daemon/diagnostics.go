@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"errors"
+	"net"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// LoadFailure is the error of a load whose llama-server ran but did not
+// become ready. It wraps the *llama.ProcessError with what the daemon saw
+// at the time, so the client can tell why without reading the logs.
+type LoadFailure struct {
+	Err         error
+	Diagnostics protocol.LoadFailureData
+}
+
+func (e *LoadFailure) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoadFailure) Unwrap() error {
+	return e.Err
+}
+
+// diagnose collects the diagnostics of proc, run for p as inv, after it
+// failed to become ready. Call it once proc is stopped, so the port check
+// finds only other processes.
+func (d *Daemon) diagnose(proc llamaProcess, p *preset.Preset, inv *Invocation) protocol.LoadFailureData {
+	data := protocol.LoadFailureData{
+		LogTail:    proc.LogTail(),
+		Addr:       net.JoinHostPort(p.Host, strconv.Itoa(p.Port)),
+		FreeMemory: d.freeMemory(),
+	}
+	if inv != nil {
+		data.Command = append([]string{inv.Command}, inv.Args...)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(proc.ExitErr(), &exitErr) && exitErr.ExitCode() >= 0 {
+		code := exitErr.ExitCode()
+		data.ExitCode = &code
+	}
+	data.PortInUse = d.portInUse(data.Addr)
+	return data
+}
+
+// portInUse reports whether another process listens on addr.
+func portInUse(addr string) bool {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Is(err, syscall.EADDRINUSE)
+	}
+	ln.Close()
+	return false
+}
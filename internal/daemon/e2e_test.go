@@ -0,0 +1,289 @@
+//go:build e2e
+
+package daemon_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/daemon"
+	"github.com/d2verb/alpaca/internal/model"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// These tests run the daemon as alpaca start does, serving a real unix
+// socket, with the fake llama-server of internal/llama/testdata/fakeproc
+// in place of llama-server. Run them with: go test -tags e2e ./internal/daemon/
+
+var (
+	fakeServerPath string
+	buildOnce      sync.Once
+	buildErr       error
+)
+
+// buildFakeServer builds the fake llama-server once per test run.
+func buildFakeServer(t *testing.T) string {
+	t.Helper()
+
+	buildOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "alpaca-e2e-bin")
+		if err != nil {
+			buildErr = err
+			return
+		}
+		fakeServerPath = filepath.Join(dir, "llama-server")
+		cmd := exec.Command("go", "build", "-o", fakeServerPath, "../llama/testdata/fakeproc")
+		cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			buildErr = fmt.Errorf("%w\n%s", err, out)
+		}
+	})
+
+	if buildErr != nil {
+		t.Fatalf("failed to build fake llama-server: %v", buildErr)
+	}
+	return fakeServerPath
+}
+
+// e2eEnv is a daemon serving a socket in a temporary alpaca home.
+type e2eEnv struct {
+	client     *client.Client
+	presetsDir string
+	modelPath  string // a valid, empty GGUF file
+}
+
+// startE2E builds the fake llama-server and starts a daemon that uses it.
+// env sets FAKEPROC_ variables scripting the fake server.
+func startE2E(t *testing.T, env map[string]string) *e2eEnv {
+	t.Helper()
+
+	fake := buildFakeServer(t)
+	home := t.TempDir()
+	t.Setenv("FAKEPROC_SERVER", "1")
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	e := &e2eEnv{
+		presetsDir: filepath.Join(home, "presets"),
+		modelPath:  filepath.Join(home, "model-Q4_K_M.gguf"),
+	}
+	modelsDir := filepath.Join(home, "models")
+	for _, dir := range []string{e.presetsDir, modelsDir} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header := append([]byte("GGUF"), 3, 0, 0, 0)
+	header = append(header, make([]byte, 16)...)
+	if err := os.WriteFile(e.modelPath, header, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unix socket paths are limited to about 100 bytes, which a temporary
+	// directory under $TMPDIR may exceed on macOS.
+	sockDir, err := os.MkdirTemp("", "alpaca-e2e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(sockDir) })
+	socket := filepath.Join(sockDir, "alpaca.sock")
+
+	d := daemon.New(preset.NewLoader(e.presetsDir), model.NewManager(modelsDir),
+		filepath.Join(home, "config.ini"), io.Discard, io.Discard)
+	d.SetLlamaServerPath(fake)
+	server := daemon.NewServer(d, socket, io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := server.Start(ctx); err != nil {
+		cancel()
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() {
+		unloadCtx, unloadCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer unloadCancel()
+		d.Kill(unloadCtx)
+		server.Stop()
+		cancel()
+	})
+
+	e.client = client.New(socket)
+	return e
+}
+
+// writePreset saves p in the presets directory.
+func (e *e2eEnv) writePreset(t *testing.T, p *preset.Preset) {
+	t.Helper()
+	if err := preset.WriteFile(filepath.Join(e.presetsDir, p.Name+".yaml"), p); err != nil {
+		t.Fatalf("write preset: %v", err)
+	}
+}
+
+// status returns the daemon's status.
+func (e *e2eEnv) status(t *testing.T) protocol.StatusData {
+	t.Helper()
+	resp, err := e.client.Status(context.Background())
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	var data protocol.StatusData
+	if err := resp.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// freePort returns a TCP port nothing listens on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestE2E_LoadStatusUnload(t *testing.T) {
+	// Arrange
+	e := startE2E(t, map[string]string{"FAKEPROC_LOAD_DELAY": "300ms"})
+	port := freePort(t)
+	e.writePreset(t, &preset.Preset{Name: "e2e", Model: "f:" + e.modelPath, Port: port, Warmup: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Act
+	resp, err := e.client.Load(ctx, "p:e2e", client.LoadOptions{})
+
+	// Assert
+	if err != nil || resp.Status != "ok" {
+		t.Fatalf("load: err = %v, resp = %+v", err, resp)
+	}
+	status := e.status(t)
+	if status.State != "running" || status.Preset != "e2e" || status.LlamaServer == nil {
+		t.Fatalf("status = %+v, want e2e running", status)
+	}
+	models, err := http.Get(status.Endpoint + "/v1/models")
+	if err != nil {
+		t.Fatalf("llama-server not reachable at %s: %v", status.Endpoint, err)
+	}
+	models.Body.Close()
+	if models.StatusCode != http.StatusOK {
+		t.Errorf("GET /v1/models = %d, want 200", models.StatusCode)
+	}
+
+	resp, err = e.client.Unload(ctx, client.UnloadOptions{})
+	if err != nil || resp.Status != "ok" {
+		t.Fatalf("unload: err = %v, resp = %+v", err, resp)
+	}
+	if status := e.status(t); status.State != "idle" {
+		t.Errorf("state after unload = %s, want idle", status.State)
+	}
+	if _, err := http.Get(status.Endpoint + "/health"); err == nil {
+		t.Error("llama-server still answers after unload")
+	}
+}
+
+func TestE2E_LoadProgress(t *testing.T) {
+	// Arrange
+	e := startE2E(t, map[string]string{"FAKEPROC_LOAD_DELAY": "300ms"})
+	e.writePreset(t, &preset.Preset{Name: "e2e", Model: "f:" + e.modelPath, Port: freePort(t)})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var phases []string
+	opts := client.LoadOptions{Progress: func(p protocol.LoadProgressData) {
+		if len(phases) == 0 || phases[len(phases)-1] != p.Phase {
+			phases = append(phases, p.Phase)
+		}
+	}}
+
+	// Act
+	resp, err := e.client.Load(ctx, "p:e2e", opts)
+
+	// Assert
+	if err != nil || resp.Status != "ok" {
+		t.Fatalf("load: err = %v, resp = %+v", err, resp)
+	}
+	want := []string{protocol.LoadPhaseResolving, protocol.LoadPhaseStarting, protocol.LoadPhaseHealthCheck}
+	if !slices.Equal(phases, want) {
+		t.Errorf("phases = %q, want %q", phases, want)
+	}
+}
+
+func TestE2E_Router(t *testing.T) {
+	// Arrange
+	e := startE2E(t, nil)
+	e.writePreset(t, &preset.Preset{
+		Name: "workspace",
+		Mode: "router",
+		Port: freePort(t),
+		Models: []preset.ModelEntry{
+			{Name: "chat", Model: "f:" + e.modelPath},
+			{Name: "embed", Model: "f:" + e.modelPath, Options: preset.Options{"embeddings": "true"}},
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Act
+	resp, err := e.client.Load(ctx, "p:workspace", client.LoadOptions{})
+
+	// Assert
+	if err != nil || resp.Status != "ok" {
+		t.Fatalf("load: err = %v, resp = %+v", err, resp)
+	}
+	status := e.status(t)
+	if status.Mode != "router" || len(status.Models) != 2 {
+		t.Fatalf("status = %+v, want two router models", status)
+	}
+	for i, want := range []string{"chat", "embed"} {
+		if m := status.Models[i]; m.ID != want || m.Status != "loaded" {
+			t.Errorf("models[%d] = %+v, want %s loaded", i, m, want)
+		}
+	}
+}
+
+func TestE2E_LoadFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+	}{
+		{name: "health check reports an error", fail: "health"},
+		{name: "llama-server exits", fail: "exit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e := startE2E(t, map[string]string{"FAKEPROC_FAIL": tt.fail})
+			e.writePreset(t, &preset.Preset{Name: "broken", Model: "f:" + e.modelPath, Port: freePort(t)})
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			// Act
+			resp, err := e.client.Load(ctx, "p:broken", client.LoadOptions{})
+
+			// Assert
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if resp.Status != "error" || resp.ErrorCode != protocol.ErrCodeServerFailed {
+				t.Errorf("resp = %+v, want a server_failed error", resp)
+			}
+			if status := e.status(t); status.State != "idle" {
+				t.Errorf("state = %s, want idle", status.State)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// defaultHookTimeout bounds a hook when neither config nor preset sets one.
+const defaultHookTimeout = 30 * time.Second
+
+// Hook events, passed to hooks as ALPACA_HOOK.
+const (
+	hookPreLoad    = "pre-load"
+	hookPostLoad   = "post-load"
+	hookPostUnload = "post-unload"
+)
+
+// SetHooks sets the hooks from config.yaml. Preset hooks override them.
+func (d *Daemon) SetHooks(h preset.Hooks) {
+	d.updateSettings(func(s *runtimeSettings) { s.hooks = h })
+}
+
+// WaitHooks waits for post-load and post-unload hooks still running,
+// giving up when ctx is done.
+func (d *Daemon) WaitHooks(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		d.hookWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.logger.Warn("hooks still running at shutdown")
+	}
+}
+
+// hookFor returns the command and timeout of the event's hook for p.
+func (d *Daemon) hookFor(event string, p *preset.Preset) (string, time.Duration) {
+	h := d.settings.Load().hooks.Merge(p.Hooks)
+	timeout := defaultHookTimeout
+	if h.Timeout > 0 {
+		timeout = time.Duration(h.Timeout) * time.Second
+	}
+	switch event {
+	case hookPreLoad:
+		return h.PreLoad, timeout
+	case hookPostLoad:
+		return h.PostLoad, timeout
+	case hookPostUnload:
+		return h.PostUnload, timeout
+	}
+	return "", timeout
+}
+
+// runHook runs the event's hook for p, if any, and logs its output.
+func (d *Daemon) runHook(ctx context.Context, event string, p *preset.Preset) error {
+	command, timeout := d.hookFor(event, p)
+	if command == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	out, err := d.execHook(ctx, command, hookEnv(event, p))
+	for line := range strings.Lines(string(out)) {
+		d.logger.Info("hook output", "hook", event, "line", strings.TrimRight(line, "\r\n"))
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		d.logger.Warn("hook failed", "hook", event, "command", command, "error", err)
+		return fmt.Errorf("%s hook %s: %w", event, command, err)
+	}
+	d.logger.Info("hook finished", "hook", event, "command", command, "duration", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// runHookAsync runs the event's hook in the background; failures are only
+// logged. WaitHooks waits for it.
+func (d *Daemon) runHookAsync(event string, p *preset.Preset) {
+	if command, _ := d.hookFor(event, p); command == "" {
+		return
+	}
+	d.hookWG.Add(1)
+	go func() {
+		defer d.hookWG.Done()
+		_ = d.runHook(context.Background(), event, p)
+	}()
+}
+
+// hookEnv describes the event and preset to a hook.
+func hookEnv(event string, p *preset.Preset) []string {
+	mode := "single"
+	model := ""
+	if p.IsRouter() {
+		mode = "router"
+	} else {
+		model = strings.TrimPrefix(p.Model, "f:")
+	}
+	return append(os.Environ(),
+		"ALPACA_HOOK="+event,
+		"ALPACA_PRESET="+p.Name,
+		"ALPACA_MODE="+mode,
+		"ALPACA_MODEL="+model,
+		"ALPACA_ENDPOINT="+p.Endpoint(),
+	)
+}
+
+// execHookCommand runs command with env and returns its combined output.
+func execHookCommand(ctx context.Context, command string, env []string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, command)
+	cmd.Env = env
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Don't wait on pipes held open by children the hook left running.
+	cmd.WaitDelay = time.Second
+	err := cmd.Run()
+	return out.Bytes(), err
+}
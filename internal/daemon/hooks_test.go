@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/logging"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// hookRecorder records hook invocations in place of executing them.
+type hookRecorder struct {
+	mu    sync.Mutex
+	calls []string // command of each call
+	envs  [][]string
+	fail  map[string]error
+}
+
+func (r *hookRecorder) exec(_ context.Context, command string, env []string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, command)
+	r.envs = append(r.envs, env)
+	return nil, r.fail[command]
+}
+
+func newHookTestDaemon(p *preset.Preset) (*Daemon, *mockProcess, *hookRecorder) {
+	d := newTestDaemon(&stubPresetLoader{presets: map[string]*preset.Preset{p.Name: p}}, &stubModelManager{})
+	proc := &mockProcess{}
+	d.newProcess = func(string) llamaProcess { return proc }
+	d.waitForReady = mockHealthChecker(nil)
+	rec := &hookRecorder{}
+	d.execHook = rec.exec
+	return d, proc, rec
+}
+
+func TestHooks_RunAroundLoadAndUnload(t *testing.T) {
+	// Arrange
+	p := &preset.Preset{Name: "chat", Model: "f:/models/chat.gguf", Port: 8081,
+		Hooks: preset.Hooks{PostLoad: "/preset/post-load"}}
+	d, _, rec := newHookTestDaemon(p)
+	d.SetHooks(preset.Hooks{PreLoad: "/cfg/pre-load", PostLoad: "/cfg/post-load", PostUnload: "/cfg/post-unload"})
+
+	// Act
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	d.WaitHooks(context.Background())
+	if err := d.Kill(context.Background()); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	d.WaitHooks(context.Background())
+
+	// Assert
+	want := []string{"/cfg/pre-load", "/preset/post-load", "/cfg/post-unload"}
+	if !slices.Equal(rec.calls, want) {
+		t.Errorf("hooks = %v, want %v", rec.calls, want)
+	}
+	env := rec.envs[0]
+	for _, kv := range []string{
+		"ALPACA_HOOK=pre-load",
+		"ALPACA_PRESET=chat",
+		"ALPACA_MODE=single",
+		"ALPACA_MODEL=/models/chat.gguf",
+		"ALPACA_ENDPOINT=http://127.0.0.1:8081",
+	} {
+		if !slices.Contains(env, kv) {
+			t.Errorf("pre-load env missing %q", kv)
+		}
+	}
+}
+
+func TestHooks_PreLoadFailureAbortsLoad(t *testing.T) {
+	// Arrange
+	p := &preset.Preset{Name: "chat", Model: "f:/models/chat.gguf",
+		Hooks: preset.Hooks{PreLoad: "/bin/check", PostLoad: "/bin/notify"}}
+	d, proc, rec := newHookTestDaemon(p)
+	rec.fail = map[string]error{"/bin/check": errors.New("exit status 1")}
+
+	// Act
+	err := d.Run(context.Background(), "p:chat")
+	d.WaitHooks(context.Background())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "pre-load hook /bin/check") {
+		t.Fatalf("Run() error = %v, want pre-load hook error", err)
+	}
+	if proc.startCalled {
+		t.Error("llama-server should not start when pre-load fails")
+	}
+	if d.State() != StateIdle {
+		t.Errorf("State = %q, want %q", d.State(), StateIdle)
+	}
+	if !slices.Equal(rec.calls, []string{"/bin/check"}) {
+		t.Errorf("hooks = %v, want only pre-load", rec.calls)
+	}
+}
+
+func TestRunHook_ExecutesScript(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	script := filepath.Join(dir, "hook.sh")
+	content := "#!/bin/sh\necho \"ready $ALPACA_PRESET\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.logger = logging.NewLogger(&logs)
+	p := &preset.Preset{Name: "chat", Model: "f:/m.gguf", Hooks: preset.Hooks{PostLoad: script}}
+
+	// Act
+	err := d.runHook(context.Background(), hookPostLoad, p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("runHook() error = %v", err)
+	}
+	if !strings.Contains(logs.String(), "ready chat") {
+		t.Errorf("hook output should be logged, got:\n%s", logs.String())
+	}
+}
+
+func TestRunHook_Timeout(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	script := filepath.Join(dir, "slow.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nsleep 5\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	p := &preset.Preset{Name: "chat", Model: "f:/m.gguf", Hooks: preset.Hooks{PreLoad: script, Timeout: 1}}
+
+	// Act
+	err := d.runHook(context.Background(), hookPreLoad, p)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Errorf("runHook() error = %v, want timeout", err)
+	}
+}
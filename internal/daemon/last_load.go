@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LastLoad records the most recent successful load so it can be restored
+// when the daemon restarts.
+type LastLoad struct {
+	Identifier  string `json:"identifier"`
+	DraftModel  string `json:"draft_model,omitempty"`
+	LlamaServer string `json:"llama_server,omitempty"`
+	Watch       bool   `json:"watch,omitempty"`
+	Strict      bool   `json:"strict,omitempty"`
+}
+
+// ReadLastLoad reads the state file at path.
+// Returns nil without error if nothing has been recorded.
+func ReadLastLoad(path string) (*LastLoad, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	var last LastLoad
+	if err := json.Unmarshal(data, &last); err != nil {
+		return nil, fmt.Errorf("parse state file %s: %w", path, err)
+	}
+	if last.Identifier == "" {
+		return nil, nil
+	}
+	return &last, nil
+}
+
+// SetStatePath enables persisting the last successful load to path.
+// An empty path (the default) disables persistence.
+func (d *Daemon) SetStatePath(path string) {
+	d.statePath = path
+}
+
+// RestoreLastLoad loads the identifier recorded by the previous daemon run.
+// It does nothing if no state path is set or nothing was recorded.
+func (d *Daemon) RestoreLastLoad(ctx context.Context) error {
+	if d.statePath == "" {
+		return nil
+	}
+	last, err := ReadLastLoad(d.statePath)
+	if err != nil {
+		d.logger.Warn("failed to read last load", "error", err)
+		return err
+	}
+	if last == nil {
+		return nil
+	}
+
+	d.logger.Info("restoring last model", "input", last.Identifier)
+	if err := d.RunWithOptions(ctx, last.Identifier, RunOptions{DraftModel: last.DraftModel, LlamaServer: last.LlamaServer, Watch: last.Watch, Strict: last.Strict}); err != nil {
+		d.logger.Warn("failed to restore last model", "input", last.Identifier, "error", err)
+		return err
+	}
+	return nil
+}
+
+// saveLastLoad records a successful load. Failures are logged, not returned:
+// the model is already running.
+func (d *Daemon) saveLastLoad(input string, opts RunOptions) {
+	if d.statePath == "" {
+		return
+	}
+	last := LastLoad{Identifier: input, DraftModel: opts.DraftModel, LlamaServer: opts.LlamaServer, Watch: opts.Watch, Strict: opts.Strict}
+	data, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		d.logger.Warn("failed to encode last load", "error", err)
+		return
+	}
+	if err := atomicWriteFile(d.statePath, string(data)+"\n"); err != nil {
+		d.logger.Warn("failed to save last load", "error", err)
+	}
+}
+
+// clearLastLoad forgets the recorded load after an explicit unload,
+// so the next daemon start stays idle.
+func (d *Daemon) clearLastLoad() {
+	if d.statePath == "" {
+		return
+	}
+	if err := os.Remove(d.statePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Warn("failed to clear last load", "error", err)
+	}
+}
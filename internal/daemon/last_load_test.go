@@ -0,0 +1,125 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+func newLastLoadTestDaemon(t *testing.T) (*Daemon, string) {
+	t.Helper()
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = mockHealthChecker(nil)
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	d.SetStatePath(statePath)
+	return d, statePath
+}
+
+func TestRunWithOptions_SavesLastLoad(t *testing.T) {
+	// Arrange
+	d, statePath := newLastLoadTestDaemon(t)
+
+	// Act
+	err := d.RunWithOptions(context.Background(), "p:test-preset", RunOptions{DraftModel: "f:/path/to/draft.gguf", Watch: true})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+	last, err := ReadLastLoad(statePath)
+	if err != nil {
+		t.Fatalf("ReadLastLoad() error = %v", err)
+	}
+	if last == nil || last.Identifier != "p:test-preset" || last.DraftModel != "f:/path/to/draft.gguf" || !last.Watch {
+		t.Errorf("ReadLastLoad() = %+v, want p:test-preset with draft, watched", last)
+	}
+}
+
+func TestRunWithOptions_FailureKeepsPreviousLastLoad(t *testing.T) {
+	// Arrange
+	d, statePath := newLastLoadTestDaemon(t)
+	if err := d.Run(context.Background(), "p:test-preset"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Act
+	err := d.Run(context.Background(), "p:missing")
+
+	// Assert
+	if err == nil {
+		t.Fatal("Run() expected error for missing preset")
+	}
+	last, err := ReadLastLoad(statePath)
+	if err != nil {
+		t.Fatalf("ReadLastLoad() error = %v", err)
+	}
+	if last == nil || last.Identifier != "p:test-preset" {
+		t.Errorf("ReadLastLoad() = %+v, want p:test-preset", last)
+	}
+}
+
+func TestHandleUnload_ClearsLastLoad(t *testing.T) {
+	// Arrange
+	d, statePath := newLastLoadTestDaemon(t)
+	server := NewServer(d, "/tmp/test.sock", io.Discard)
+	if err := d.Run(context.Background(), "p:test-preset"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Act
+	server.handleUnload(context.Background(), &protocol.Request{Command: protocol.CmdUnload})
+
+	// Assert
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("state file should be removed after unload, stat error = %v", err)
+	}
+}
+
+func TestRestoreLastLoad(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string // empty means no state file
+		wantState State
+		wantErr   bool
+	}{
+		{"restores recorded preset", `{"identifier": "p:test-preset"}`, StateRunning, false},
+		{"no state file", "", StateIdle, false},
+		{"recorded preset missing", `{"identifier": "p:missing"}`, StateIdle, true},
+		{"corrupted state file", `{`, StateIdle, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d, statePath := newLastLoadTestDaemon(t)
+			if tt.state != "" {
+				if err := os.WriteFile(statePath, []byte(tt.state), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Act
+			err := d.RestoreLastLoad(context.Background())
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RestoreLastLoad() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if d.State() != tt.wantState {
+				t.Errorf("State() = %q, want %q", d.State(), tt.wantState)
+			}
+		})
+	}
+}
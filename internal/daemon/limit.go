@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// DefaultMaxRequests is how many requests the server handles at once
+// unless configured otherwise.
+const DefaultMaxRequests = 16
+
+const (
+	// queueWait is how long a request waits for a free slot before it is
+	// rejected as busy.
+	queueWait = 2 * time.Second
+
+	// slowRequest is how long a limited request may take before it is
+	// logged as slow.
+	slowRequest = 5 * time.Second
+)
+
+// SetMaxRequests sets how many requests the server handles at once. Loads,
+// unloads and cancels do not count against it and are never turned away,
+// so a flood of status polls cannot keep a model from being loaded or
+// stopped. n <= 0 uses DefaultMaxRequests. Call before Start.
+func (s *Server) SetMaxRequests(n int) {
+	if n <= 0 {
+		n = DefaultMaxRequests
+	}
+	s.slots = make(chan struct{}, n)
+}
+
+// unlimited reports whether a command bypasses the request limit.
+func unlimited(command string) bool {
+	switch command {
+	case protocol.CmdLoad, protocol.CmdUnload, protocol.CmdCancel, protocol.CmdHello:
+		return true
+	}
+	return false
+}
+
+// serveRequest handles req within the request limit: it waits up to
+// queueWait for a free slot and answers busy if none frees up. Limited
+// requests that take longer than slowRequest are logged.
+func (s *Server) serveRequest(ctx context.Context, req *protocol.Request) *protocol.Response {
+	if unlimited(req.Command) {
+		return s.handleRequest(ctx, req)
+	}
+
+	timer := time.NewTimer(s.queueWait)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+	case <-timer.C:
+		s.logger.Warn("request rejected: server busy", "command", req.Command, "in_flight", cap(s.slots))
+		return protocol.NewErrorResponseWithCode(protocol.ErrCodeBusy, fmt.Sprintf(
+			"daemon is busy with %d requests; try again shortly", cap(s.slots)))
+	case <-ctx.Done():
+		return protocol.NewErrorResponse(ctx.Err().Error())
+	}
+	defer func() { <-s.slots }()
+
+	start := time.Now()
+	resp := s.handleRequest(ctx, req)
+	if elapsed := time.Since(start); elapsed > slowRequest {
+		s.logger.Warn("slow request", "command", req.Command, "duration", elapsed.Round(time.Millisecond))
+	}
+	return resp
+}
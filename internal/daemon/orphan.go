@@ -0,0 +1,193 @@
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// adoptReadyTimeout bounds the health check of an orphaned llama-server.
+const adoptReadyTimeout = 5 * time.Second
+
+// serverRecord identifies the llama-server spawned by the daemon, so a
+// restarted daemon can find it if the previous one crashed.
+type serverRecord struct {
+	PID         int    `json:"pid"`
+	Port        int    `json:"port"`
+	ArgsHash    string `json:"args_hash"`
+	Identifier  string `json:"identifier"`
+	DraftModel  string `json:"draft_model,omitempty"`
+	LlamaServer string `json:"llama_server,omitempty"`
+
+	// RouterConfig is the config.ini a router llama-server reads. Records
+	// written before config.ini was run-scoped leave it empty.
+	RouterConfig string `json:"router_config,omitempty"`
+}
+
+// SetServerRecordPath enables tracking the spawned llama-server in a file
+// at path. An empty path (the default) disables tracking.
+func (d *Daemon) SetServerRecordPath(path string) {
+	d.serverRecordPath = path
+}
+
+// hashArgs hashes the command line of command run with args.
+func hashArgs(command string, args []string) string {
+	return hashCommandLine(strings.Join(append([]string{command}, args...), " "))
+}
+
+// hashCommandLine hashes a command line as ps reports it (argv joined by spaces).
+func hashCommandLine(line string) string {
+	sum := sha256.Sum256([]byte(line))
+	return hex.EncodeToString(sum[:])
+}
+
+// buildArgs returns the llama-server arguments for p, reading its router
+// config.ini from configPath, without side effects.
+func (d *Daemon) buildArgs(p *preset.Preset, configPath string) []string {
+	if p.IsRouter() {
+		return p.BuildRouterArgs(configPath)
+	}
+	return p.BuildArgs()
+}
+
+func (d *Daemon) writeServerRecord(pid int, p *preset.Preset, command string, args []string, configPath, input string, opts RunOptions) {
+	if d.serverRecordPath == "" {
+		return
+	}
+	rec := serverRecord{
+		PID:          pid,
+		Port:         p.Port,
+		ArgsHash:     hashArgs(command, args),
+		Identifier:   input,
+		DraftModel:   opts.DraftModel,
+		LlamaServer:  opts.LlamaServer,
+		RouterConfig: configPath,
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		d.logger.Warn("failed to encode server record", "error", err)
+		return
+	}
+	if err := atomicWriteFile(d.serverRecordPath, string(data)+"\n"); err != nil {
+		d.logger.Warn("failed to write server record", "error", err)
+	}
+}
+
+func (d *Daemon) removeServerRecord() {
+	if d.serverRecordPath == "" {
+		return
+	}
+	if err := os.Remove(d.serverRecordPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		d.logger.Warn("failed to remove server record", "error", err)
+	}
+}
+
+// routerConfig returns the config.ini the recorded server reads, or base
+// for records written before config.ini was run-scoped.
+func (rec *serverRecord) routerConfig(base string) string {
+	if rec.RouterConfig != "" {
+		return rec.RouterConfig
+	}
+	return base
+}
+
+func (d *Daemon) readServerRecord() (*serverRecord, error) {
+	data, err := os.ReadFile(d.serverRecordPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read server record: %w", err)
+	}
+	var rec serverRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse server record %s: %w", d.serverRecordPath, err)
+	}
+	return &rec, nil
+}
+
+// AdoptOrphan looks for a llama-server left running by a previous daemon
+// (e.g. after a crash). If it still serves the recorded preset unchanged and
+// is healthy, it becomes the daemon's running model; otherwise it is
+// terminated so it does not hold the port. Processes whose command line no
+// longer matches the record (PID reuse) are left alone.
+// Reports whether a server was adopted. Call before serving requests.
+func (d *Daemon) AdoptOrphan(ctx context.Context) bool {
+	if d.serverRecordPath == "" {
+		return false
+	}
+	rec, err := d.readServerRecord()
+	if err != nil {
+		d.logger.Warn("failed to read server record", "error", err)
+		d.removeServerRecord()
+		return false
+	}
+	if rec == nil {
+		return false
+	}
+
+	if running, err := IsProcessRunning(rec.PID); err != nil || !running {
+		d.removeServerRecord()
+		return false
+	}
+	cmdline, err := d.commandLine(rec.PID)
+	if err != nil || hashCommandLine(cmdline) != rec.ArgsHash {
+		d.logger.Info("recorded PID no longer runs the recorded llama-server, leaving it alone", "pid", rec.PID)
+		d.removeServerRecord()
+		return false
+	}
+
+	proc, err := d.attachProcess(rec.PID)
+	if err != nil {
+		d.logger.Warn("failed to attach to orphaned llama-server", "pid", rec.PID, "error", err)
+		d.removeServerRecord()
+		return false
+	}
+
+	p, err := d.adoptablePreset(ctx, rec)
+	if err != nil {
+		d.logger.Info("terminating orphaned llama-server", "pid", rec.PID, "port", rec.Port, "reason", err)
+		if err := proc.Stop(ctx); err != nil {
+			d.logger.Warn("failed to stop orphaned llama-server", "pid", rec.PID, "error", err)
+		}
+		d.removeServerRecord()
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.runGen++
+	d.process = proc
+	configPath := rec.routerConfig(d.configPath)
+	d.invocation.Store(d.newInvocation(p, proc.Pid(), d.serverCommand(p), d.buildArgs(p, configPath), configPath))
+	d.setSnapshot(StateRunning, p)
+	d.logger.Info("adopted orphaned llama-server", "pid", rec.PID, "preset", p.Name, "endpoint", p.Endpoint())
+	return true
+}
+
+// adoptablePreset resolves the recorded identifier and checks that it still
+// produces the running command line and that the server answers.
+func (d *Daemon) adoptablePreset(ctx context.Context, rec *serverRecord) (*preset.Preset, error) {
+	p, err := d.loadPreset(ctx, rec.Identifier, RunOptions{DraftModel: rec.DraftModel, LlamaServer: rec.LlamaServer})
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", rec.Identifier, err)
+	}
+	if hashArgs(d.serverCommand(p), d.buildArgs(p, rec.routerConfig(d.configPath))) != rec.ArgsHash {
+		return nil, fmt.Errorf("%s has changed since the server started", rec.Identifier)
+	}
+	readyCtx, cancel := context.WithTimeout(ctx, adoptReadyTimeout)
+	defer cancel()
+	if err := d.waitForReady(readyCtx, p.Endpoint(), llama.WaitOptions{Check: healthCheck(p)}); err != nil {
+		return nil, fmt.Errorf("server not ready: %w", err)
+	}
+	return p, nil
+}
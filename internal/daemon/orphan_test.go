@@ -0,0 +1,163 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func newOrphanTestDaemon(t *testing.T) (*Daemon, string) {
+	t.Helper()
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+		},
+	}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.waitForReady = mockHealthChecker(nil)
+
+	recordPath := filepath.Join(t.TempDir(), "llama-server.json")
+	d.SetServerRecordPath(recordPath)
+	return d, recordPath
+}
+
+func writeTestRecord(t *testing.T, path string, rec serverRecord) {
+	t.Helper()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// presetCommandLine returns the command line llama-server would run for p.
+func presetCommandLine(p *preset.Preset) string {
+	return strings.Join(append([]string{llamaServerCommand}, p.BuildArgs()...), " ")
+}
+
+func TestRun_WritesAndRemovesServerRecord(t *testing.T) {
+	// Arrange
+	d, recordPath := newOrphanTestDaemon(t)
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{pid: 4242} }
+
+	// Act
+	if err := d.Run(context.Background(), "p:test-preset"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	rec, err := d.readServerRecord()
+
+	// Assert
+	if err != nil || rec == nil {
+		t.Fatalf("readServerRecord() = %v, %v", rec, err)
+	}
+	if rec.PID != 4242 || rec.Port != 8080 || rec.Identifier != "p:test-preset" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.ArgsHash != hashCommandLine(presetCommandLine(d.CurrentPreset())) {
+		t.Error("ArgsHash does not match the preset's command line")
+	}
+
+	if err := d.Kill(context.Background()); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	if _, err := os.Stat(recordPath); !os.IsNotExist(err) {
+		t.Errorf("record should be removed after Kill, stat error = %v", err)
+	}
+}
+
+func TestAdoptOrphan(t *testing.T) {
+	livePID := os.Getpid() // any running process; its command line is stubbed
+	p := &preset.Preset{Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080}
+	matching := presetCommandLine(p)
+
+	tests := []struct {
+		name        string
+		identifier  string
+		cmdline     string
+		healthErr   error
+		wantAdopted bool
+		wantStopped bool
+	}{
+		{"healthy unchanged server is adopted", "p:test-preset", matching, nil, true, false},
+		{"preset removed since start is terminated", "p:missing", matching, nil, false, true},
+		{"unhealthy server is terminated", "p:test-preset", matching, errors.New("not ready"), false, true},
+		{"reused PID is left alone", "p:test-preset", "/usr/bin/vim notes.txt", nil, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d, recordPath := newOrphanTestDaemon(t)
+			d.waitForReady = mockHealthChecker(tt.healthErr)
+			d.commandLine = func(pid int) (string, error) { return tt.cmdline, nil }
+			proc := &mockProcess{pid: livePID}
+			d.attachProcess = func(pid int) (llamaProcess, error) { return proc, nil }
+			writeTestRecord(t, recordPath, serverRecord{
+				PID:        livePID,
+				Port:       8080,
+				ArgsHash:   hashCommandLine(matching),
+				Identifier: tt.identifier,
+			})
+
+			// Act
+			adopted := d.AdoptOrphan(context.Background())
+
+			// Assert
+			if adopted != tt.wantAdopted {
+				t.Errorf("AdoptOrphan() = %v, want %v", adopted, tt.wantAdopted)
+			}
+			if proc.stopCalled != tt.wantStopped {
+				t.Errorf("Stop called = %v, want %v", proc.stopCalled, tt.wantStopped)
+			}
+			if tt.wantAdopted {
+				if d.State() != StateRunning || d.CurrentPreset().Name != "test-preset" {
+					t.Errorf("State() = %q, preset = %v; want running test-preset", d.State(), d.CurrentPreset())
+				}
+				if _, err := os.Stat(recordPath); err != nil {
+					t.Errorf("record should be kept for an adopted server: %v", err)
+				}
+			} else {
+				if d.State() != StateIdle {
+					t.Errorf("State() = %q, want %q", d.State(), StateIdle)
+				}
+				if _, err := os.Stat(recordPath); !os.IsNotExist(err) {
+					t.Errorf("record should be removed, stat error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAdoptOrphan_ExitedProcess(t *testing.T) {
+	// Arrange
+	d, recordPath := newOrphanTestDaemon(t)
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("true not available: %v", err)
+	}
+	writeTestRecord(t, recordPath, serverRecord{PID: cmd.Process.Pid, Identifier: "p:test-preset"})
+	d.attachProcess = func(pid int) (llamaProcess, error) {
+		t.Fatal("attachProcess should not be called for an exited process")
+		return nil, nil
+	}
+
+	// Act
+	adopted := d.AdoptOrphan(context.Background())
+
+	// Assert
+	if adopted {
+		t.Error("AdoptOrphan() = true, want false")
+	}
+	if _, err := os.Stat(recordPath); !os.IsNotExist(err) {
+		t.Errorf("record should be removed, stat error = %v", err)
+	}
+}
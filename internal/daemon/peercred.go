@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+)
+
+// errUnsupportedPeerCred is returned by peerUID where the platform cannot
+// report the peer of a Unix socket.
+var errUnsupportedPeerCred = errors.New("peer credentials not supported on this platform")
+
+// SetAllowedUIDs sets the users, besides the daemon's own user and root,
+// that may connect to the Unix socket. Call before Start.
+func (s *Server) SetAllowedUIDs(uids []int) {
+	s.allowedUIDs = uids
+}
+
+// socketModes returns the permissions of a newly created socket directory
+// and of the socket file. Both are owner-only unless other users are
+// allowed, in which case they must be able to reach and connect to the
+// socket and the peer check does the filtering. Without peer credentials
+// the socket stays owner-only.
+func (s *Server) socketModes() (dir, file os.FileMode) {
+	if len(s.allowedUIDs) > 0 && peerCredSupported {
+		return 0711, 0666
+	}
+	return 0700, 0600
+}
+
+// authorizePeer reports whether the process behind a Unix socket connection
+// may use the daemon.
+func (s *Server) authorizePeer(conn net.Conn) bool {
+	uid, err := s.peerUID(conn)
+	if err != nil {
+		if errors.Is(err, errUnsupportedPeerCred) {
+			return true // only the socket file's permissions apply
+		}
+		s.logger.Warn("rejecting connection with unknown peer", "error", err)
+		return false
+	}
+	if uid == os.Getuid() || uid == 0 || slices.Contains(s.allowedUIDs, uid) {
+		return true
+	}
+	s.logger.Warn("rejecting connection from another user", "uid", uid)
+	return false
+}
+
+// LookupUIDs resolves user names or numeric IDs to user IDs.
+func LookupUIDs(users []string) ([]int, error) {
+	uids := make([]int, 0, len(users))
+	for _, name := range users {
+		if uid, err := strconv.Atoi(name); err == nil {
+			uids = append(uids, uid)
+			continue
+		}
+		u, err := user.Lookup(name)
+		if err != nil {
+			return nil, fmt.Errorf("look up user %q: %w", name, err)
+		}
+		uid, err := strconv.Atoi(u.Uid)
+		if err != nil {
+			return nil, fmt.Errorf("user %q has non-numeric uid %q", name, u.Uid)
+		}
+		uids = append(uids, uid)
+	}
+	return uids, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package daemon
+
+import "net"
+
+// peerCredSupported reports whether peerUID can identify the peer.
+const peerCredSupported = false
+
+// peerUID is not supported on this platform; only the socket file's
+// permissions restrict access.
+func peerUID(conn net.Conn) (int, error) {
+	return 0, errUnsupportedPeerCred
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 )
 
 var (
@@ -18,6 +19,9 @@ var (
 	ErrInvalidPIDFile = errors.New("invalid PID file")
 	// ErrProcessNotFound is returned when the process does not exist.
 	ErrProcessNotFound = errors.New("process not found")
+	// ErrAlreadyRunning is returned by AcquireLock when another daemon
+	// holds the lock.
+	ErrAlreadyRunning = errors.New("daemon is already running")
 )
 
 // DaemonStatus represents the current status of the daemon.
@@ -27,14 +31,83 @@ type DaemonStatus struct {
 	SocketExists bool
 }
 
-// WritePIDFile writes the current process ID to the specified file.
-func WritePIDFile(path string) error {
-	pid := os.Getpid()
-	data := []byte(strconv.Itoa(pid))
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("write PID file: %w", err)
+// Lock is the daemon's single-instance lock: an exclusive flock on the PID
+// file, held for the daemon's lifetime. The kernel releases it when the
+// daemon exits, however it exits, so a crashed daemon never blocks the next
+// start. The file itself is never removed; only the lock says whether a
+// daemon is running.
+type Lock struct {
+	f *os.File
+}
+
+// A status check holds a shared lock on the PID file for an instant, so a
+// starting daemon retries briefly before concluding another one runs.
+const (
+	lockAttempts      = 10
+	lockRetryInterval = 20 * time.Millisecond
+)
+
+// AcquireLock takes the lock on the PID file at path and writes the current
+// process ID to it. Returns ErrAlreadyRunning if another daemon holds it.
+func AcquireLock(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open PID file: %w", err)
+	}
+	for attempt := 1; ; attempt++ {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			f.Close()
+			return nil, fmt.Errorf("lock PID file: %w", err)
+		}
+		if attempt == lockAttempts {
+			f.Close()
+			return nil, ErrAlreadyRunning
+		}
+		time.Sleep(lockRetryInterval)
+	}
+
+	if err := f.Truncate(0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write PID file: %w", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write PID file: %w", err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release empties the PID file and releases the lock.
+func (l *Lock) Release() error {
+	l.f.Truncate(0)
+	return l.f.Close()
+}
+
+// lockHeld reports whether a daemon holds the lock on the PID file at path.
+func lockHeld(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("open PID file: %w", err)
+	}
+	defer f.Close()
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_SH|syscall.LOCK_NB)
+	switch {
+	case err == nil:
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		return false, nil
+	case errors.Is(err, syscall.EWOULDBLOCK):
+		return true, nil
+	default:
+		return false, fmt.Errorf("check PID file lock: %w", err)
 	}
-	return nil
 }
 
 // ReadPIDFile reads the process ID from the specified file.
@@ -108,49 +181,30 @@ func IsSocketAvailable(socketPath string) bool {
 	return true
 }
 
-// GetDaemonStatus checks the daemon status by examining both the PID file
-// and socket availability. This provides a unified view of daemon state.
+// GetDaemonStatus checks the daemon status by examining the lock on the PID
+// file and socket availability. A socket that accepts connections while the
+// lock is free belongs to a daemon from before the lock existed and counts
+// as running.
 func GetDaemonStatus(pidPath, socketPath string) (*DaemonStatus, error) {
 	status := &DaemonStatus{}
 
 	// Check socket first (quick check)
 	status.SocketExists = IsSocketAvailable(socketPath)
 
-	// Read PID file
-	pid, err := ReadPIDFile(pidPath)
+	held, err := lockHeld(pidPath)
 	if err != nil {
-		if errors.Is(err, ErrPIDFileNotFound) {
-			// No PID file, daemon not running
-			return status, nil
-		}
-		// Invalid PID file, might be corrupted
-		return status, fmt.Errorf("read PID: %w", err)
+		return status, err
 	}
-
-	status.PID = pid
-
-	// Check if process is running
-	running, err := IsProcessRunning(pid)
-	if err != nil {
-		return status, fmt.Errorf("check process %d: %w", pid, err)
+	status.Running = held || status.SocketExists
+	if !status.Running {
+		// Whatever the PID file says is left over from a stopped daemon.
+		return status, nil
 	}
 
-	status.Running = running
-
-	// Consistency check: if socket exists but process not running,
-	// or if process running but socket doesn't exist, something is wrong
-	if status.SocketExists && !status.Running {
-		return status, fmt.Errorf("socket exists but process %d not running (stale socket?)", pid)
+	pid, err := ReadPIDFile(pidPath)
+	if err != nil {
+		return status, fmt.Errorf("read PID: %w", err)
 	}
-
+	status.PID = pid
 	return status, nil
 }
-
-// RemovePIDFile removes the PID file. It's safe to call even if the file doesn't exist.
-func RemovePIDFile(path string) error {
-	err := os.Remove(path)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove PID file: %w", err)
-	}
-	return nil
-}
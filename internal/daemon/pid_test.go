@@ -5,31 +5,38 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"testing"
 )
 
-func TestWritePIDFile(t *testing.T) {
+func TestAcquireLock(t *testing.T) {
 	// Arrange
-	tmpDir := t.TempDir()
-	pidPath := filepath.Join(tmpDir, "test.pid")
+	pidPath := filepath.Join(t.TempDir(), "test.pid")
 
 	// Act
-	err := WritePIDFile(pidPath)
+	lock, err := AcquireLock(pidPath)
 
 	// Assert
 	if err != nil {
-		t.Fatalf("WritePIDFile failed: %v", err)
+		t.Fatalf("AcquireLock failed: %v", err)
+	}
+	if pid, err := ReadPIDFile(pidPath); err != nil || pid != os.Getpid() {
+		t.Errorf("ReadPIDFile() = %d, %v, want %d", pid, err, os.Getpid())
 	}
 
-	data, err := os.ReadFile(pidPath)
-	if err != nil {
-		t.Fatalf("Failed to read PID file: %v", err)
+	if _, err := AcquireLock(pidPath); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("second AcquireLock error = %v, want ErrAlreadyRunning", err)
 	}
 
-	if len(data) == 0 {
-		t.Error("PID file is empty")
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	again, err := AcquireLock(pidPath)
+	if err != nil {
+		t.Fatalf("AcquireLock after Release failed: %v", err)
 	}
+	again.Release()
 }
 
 func TestReadPIDFile(t *testing.T) {
@@ -232,10 +239,11 @@ func TestGetDaemonStatus(t *testing.T) {
 		socketPath := filepath.Join("/tmp", "alpaca-test-status.sock")
 		defer os.Remove(socketPath)
 
-		// Create PID file with current process
-		if err := WritePIDFile(pidPath); err != nil {
-			t.Fatalf("Failed to write PID file: %v", err)
+		lock, err := AcquireLock(pidPath)
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
 		}
+		defer lock.Release()
 
 		// Create socket
 		listener, err := net.Listen("unix", socketPath)
@@ -265,12 +273,18 @@ func TestGetDaemonStatus(t *testing.T) {
 		}
 	})
 
-	t.Run("daemon not running - no PID file", func(t *testing.T) {
+	t.Run("daemon starting - lock held before socket", func(t *testing.T) {
 		// Arrange
 		tmpDir := t.TempDir()
 		pidPath := filepath.Join(tmpDir, "test.pid")
 		socketPath := filepath.Join(tmpDir, "test.sock")
 
+		lock, err := AcquireLock(pidPath)
+		if err != nil {
+			t.Fatalf("Failed to acquire lock: %v", err)
+		}
+		defer lock.Release()
+
 		// Act
 		status, err := GetDaemonStatus(pidPath, socketPath)
 
@@ -279,117 +293,116 @@ func TestGetDaemonStatus(t *testing.T) {
 			t.Fatalf("GetDaemonStatus failed: %v", err)
 		}
 
-		if status.Running {
-			t.Error("Expected daemon to not be running")
-		}
-
-		if status.SocketExists {
-			t.Error("Expected socket to not exist")
-		}
-
-		if status.PID != 0 {
-			t.Errorf("Expected PID 0, got %d", status.PID)
+		if !status.Running || status.SocketExists {
+			t.Errorf("status = %+v, want running without socket", status)
 		}
 	})
 
-	t.Run("stale socket - process not running", func(t *testing.T) {
+	t.Run("daemon not running - no PID file", func(t *testing.T) {
 		// Arrange
 		tmpDir := t.TempDir()
 		pidPath := filepath.Join(tmpDir, "test.pid")
-		socketPath := filepath.Join("/tmp", "alpaca-test-stale.sock")
-		defer os.Remove(socketPath)
-
-		// Create PID file with non-existent process
-		if err := os.WriteFile(pidPath, []byte("99999999"), 0644); err != nil {
-			t.Fatalf("Failed to write PID file: %v", err)
-		}
-
-		// Create socket
-		listener, err := net.Listen("unix", socketPath)
-		if err != nil {
-			t.Fatalf("Failed to create socket: %v", err)
-		}
-		defer listener.Close()
+		socketPath := filepath.Join(tmpDir, "test.sock")
 
 		// Act
 		status, err := GetDaemonStatus(pidPath, socketPath)
 
 		// Assert
-		if err == nil {
-			t.Error("Expected error for stale socket")
+		if err != nil {
+			t.Fatalf("GetDaemonStatus failed: %v", err)
 		}
 
 		if status.Running {
 			t.Error("Expected daemon to not be running")
 		}
 
-		if !status.SocketExists {
-			t.Error("Expected socket to exist")
+		if status.SocketExists {
+			t.Error("Expected socket to not exist")
+		}
+
+		if status.PID != 0 {
+			t.Errorf("Expected PID 0, got %d", status.PID)
 		}
 	})
 
-	t.Run("invalid PID file", func(t *testing.T) {
+	t.Run("crashed daemon - PID file names a live process", func(t *testing.T) {
 		// Arrange
 		tmpDir := t.TempDir()
 		pidPath := filepath.Join(tmpDir, "test.pid")
 		socketPath := filepath.Join(tmpDir, "test.sock")
 
-		// Create invalid PID file
-		if err := os.WriteFile(pidPath, []byte("invalid"), 0644); err != nil {
+		// A crashed daemon's PID reused by another process (here, the test)
+		if err := os.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
 			t.Fatalf("Failed to write PID file: %v", err)
 		}
+		// Socket file left behind, with no listener
+		if err := os.WriteFile(socketPath, nil, 0600); err != nil {
+			t.Fatalf("Failed to write socket file: %v", err)
+		}
 
 		// Act
 		status, err := GetDaemonStatus(pidPath, socketPath)
 
 		// Assert
-		if err == nil {
-			t.Error("Expected error for invalid PID file")
-		}
-
-		if !errors.Is(err, ErrInvalidPIDFile) {
-			t.Errorf("Expected ErrInvalidPIDFile, got %v", err)
+		if err != nil {
+			t.Fatalf("GetDaemonStatus failed: %v", err)
 		}
 
-		if status.Running {
-			t.Error("Expected daemon to not be running")
+		if status.Running || status.SocketExists || status.PID != 0 {
+			t.Errorf("status = %+v, want not running", status)
 		}
 	})
-}
 
-func TestRemovePIDFile(t *testing.T) {
-	t.Run("remove existing file", func(t *testing.T) {
+	t.Run("socket accepting without lock", func(t *testing.T) {
 		// Arrange
 		tmpDir := t.TempDir()
 		pidPath := filepath.Join(tmpDir, "test.pid")
-		if err := WritePIDFile(pidPath); err != nil {
+		socketPath := filepath.Join("/tmp", "alpaca-test-unlocked.sock")
+		defer os.Remove(socketPath)
+
+		if err := os.WriteFile(pidPath, []byte("12345"), 0644); err != nil {
 			t.Fatalf("Failed to write PID file: %v", err)
 		}
+		listener, err := net.Listen("unix", socketPath)
+		if err != nil {
+			t.Fatalf("Failed to create socket: %v", err)
+		}
+		defer listener.Close()
 
 		// Act
-		err := RemovePIDFile(pidPath)
+		status, err := GetDaemonStatus(pidPath, socketPath)
 
 		// Assert
 		if err != nil {
-			t.Errorf("RemovePIDFile failed: %v", err)
+			t.Fatalf("GetDaemonStatus failed: %v", err)
 		}
 
-		if _, err := os.Stat(pidPath); !os.IsNotExist(err) {
-			t.Error("Expected PID file to be removed")
+		if !status.Running || status.PID != 12345 {
+			t.Errorf("status = %+v, want running with PID 12345", status)
 		}
 	})
 
-	t.Run("remove non-existent file", func(t *testing.T) {
+	t.Run("invalid PID file without lock", func(t *testing.T) {
 		// Arrange
 		tmpDir := t.TempDir()
-		pidPath := filepath.Join(tmpDir, "nonexistent.pid")
+		pidPath := filepath.Join(tmpDir, "test.pid")
+		socketPath := filepath.Join(tmpDir, "test.sock")
+
+		// Create invalid PID file
+		if err := os.WriteFile(pidPath, []byte("invalid"), 0644); err != nil {
+			t.Fatalf("Failed to write PID file: %v", err)
+		}
 
 		// Act
-		err := RemovePIDFile(pidPath)
+		status, err := GetDaemonStatus(pidPath, socketPath)
 
 		// Assert
 		if err != nil {
-			t.Errorf("RemovePIDFile should not fail for non-existent file: %v", err)
+			t.Errorf("GetDaemonStatus failed: %v", err)
+		}
+
+		if status.Running {
+			t.Error("Expected daemon to not be running")
 		}
 	})
 }
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// progressKey is the context key of a request's progressWriter.
+type progressKey struct{}
+
+// progressWriter sends interim responses on the connection of a request
+// until its final response is written; later ones are dropped, so a
+// callback that outlives the request cannot corrupt the stream.
+type progressWriter struct {
+	s    *Server
+	conn net.Conn
+
+	mu   sync.Mutex
+	done bool
+}
+
+// send writes data as a StatusProgress response.
+func (w *progressWriter) send(data any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.s.writeResponse(w.conn, protocol.NewProgressResponse(data))
+	}
+}
+
+// finish writes the final response.
+func (w *progressWriter) finish(resp *protocol.Response) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.done = true
+	w.s.writeResponse(w.conn, resp)
+}
+
+// loadProgress returns a RunOptions.Progress that sends each load phase on
+// the connection of the request ctx belongs to, or nil without one.
+func loadProgress(ctx context.Context) func(phase, detail string) {
+	w, ok := ctx.Value(progressKey{}).(*progressWriter)
+	if !ok {
+		return nil
+	}
+	return func(phase, detail string) {
+		w.send(protocol.LoadProgressData{Phase: phase, Detail: detail})
+	}
+}
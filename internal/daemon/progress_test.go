@@ -0,0 +1,75 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+func TestDaemonRun_ReportsProgress(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"test": {Name: "test", Model: "f:/m.gguf", Port: 8080, Warmup: true},
+	}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		opts.Progress("loading model 43%")
+		return nil
+	}
+	d.warmUp = func(ctx context.Context, endpoint, prompt string) error { return nil }
+	var got []string
+	opts := RunOptions{Progress: func(phase, detail string) { got = append(got, phase+" "+detail) }}
+
+	// Act
+	err := d.RunWithOptions(context.Background(), "p:test", opts)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+	want := []string{"resolving ", "starting ", "health_check ", "health_check loading model 43%", "warmup "}
+	if !slices.Equal(got, want) {
+		t.Errorf("progress = %q, want %q", got, want)
+	}
+}
+
+func TestProgressWriter_DropsAfterFinish(t *testing.T) {
+	// Arrange
+	client, conn := net.Pipe()
+	defer client.Close()
+	w := &progressWriter{s: NewServer(newTestDaemon(&stubPresetLoader{}, &stubModelManager{}), "/tmp/unused.sock", io.Discard), conn: conn}
+	progress := loadProgress(context.WithValue(context.Background(), progressKey{}, w))
+	var statuses []string
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		scanner := bufio.NewScanner(client)
+		for scanner.Scan() {
+			var resp protocol.Response
+			json.Unmarshal(scanner.Bytes(), &resp)
+			statuses = append(statuses, resp.Status)
+		}
+	}()
+
+	// Act
+	progress(protocol.LoadPhaseStarting, "")
+	w.finish(protocol.NewOKResponse(nil))
+	progress(protocol.LoadPhaseWarmup, "")
+	conn.Close()
+	<-read
+
+	// Assert
+	want := []string{protocol.StatusProgress, protocol.StatusOK}
+	if !slices.Equal(statuses, want) {
+		t.Errorf("responses = %q, want %q", statuses, want)
+	}
+}
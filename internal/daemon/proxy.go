@@ -0,0 +1,396 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/logging"
+	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// DefaultProxyLoadTimeout is the default time the proxy waits for an
+// on-demand load to become ready.
+const DefaultProxyLoadTimeout = 2 * time.Minute
+
+// maxProxyInspectBytes limits how much of a request body the proxy buffers
+// to find the "model" field when nothing is loaded.
+const maxProxyInspectBytes = 64 << 20
+
+// statusClientClosedRequest is logged for requests whose client went away
+// before llama-server answered (the status nginx uses for the same case).
+const statusClientClosedRequest = 499
+
+// errNoModelRequested is returned when nothing is loaded and the request
+// does not name a loadable model.
+var errNoModelRequested = errors.New("no model loaded; set \"model\" to a preset name (or p:name / h:org/repo:quant) to load it on demand")
+
+// Proxy is an HTTP reverse proxy in front of llama-server.
+// When no model is loaded, a request whose JSON body names a preset
+// ("model": "name" or "p:name") or a downloaded HuggingFace model
+// ("h:org/repo:quant") is loaded on demand before being forwarded.
+type Proxy struct {
+	daemon      *Daemon
+	loadTimeout time.Duration
+	logger      *slog.Logger
+	server      *http.Server
+
+	requestLog *slog.Logger // nil unless SetRequestLog was called
+	logBodies  bool
+
+	// loadMu serializes on-demand loads so concurrent requests trigger
+	// a single Run.
+	loadMu sync.Mutex
+}
+
+// NewProxy creates a proxy for the daemon's llama-server.
+func NewProxy(d *Daemon, loadTimeout time.Duration, logWriter io.Writer) *Proxy {
+	if logWriter == nil {
+		panic("logWriter must not be nil")
+	}
+	if loadTimeout <= 0 {
+		loadTimeout = DefaultProxyLoadTimeout
+	}
+	return &Proxy{
+		daemon:      d,
+		loadTimeout: loadTimeout,
+		logger:      logging.NewLogger(logWriter),
+	}
+}
+
+// Start starts serving the proxy on addr.
+func (p *Proxy) Start(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	p.server = p.newServer()
+
+	p.logger.Info("proxy started", "addr", listener.Addr().String())
+	go func() {
+		if err := p.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.logger.Error("proxy stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// newServer returns the proxy's HTTP server. Besides HTTP/1.1 it accepts
+// HTTP/2 without TLS (h2c), which clients that multiplex streams use.
+func (p *Proxy) newServer() *http.Server {
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &http.Server{Handler: p, Protocols: &protocols}
+}
+
+// Stop stops the proxy, waiting briefly for in-flight requests.
+func (p *Proxy) Stop() error {
+	if p.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return p.server.Shutdown(ctx)
+}
+
+// ServeHTTP forwards the request to llama-server, loading a model first
+// if nothing is running.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := newRequestRecord(w, r, p.requestLog != nil && p.logBodies)
+	if p.requestLog != nil {
+		// Deferred: a client that disconnects mid-stream aborts the
+		// handler with http.ErrAbortHandler
+		defer p.logRequest(rec, r)
+	}
+	p.serve(rec, r)
+}
+
+// serve handles r, noting the model and token usage in w.
+func (p *Proxy) serve(w *requestRecord, r *http.Request) {
+	snap := p.daemon.StatusSnapshot()
+	if snap.State != StateRunning {
+		if model, err := p.loadOnDemand(r); err != nil {
+			w.model = model
+			p.writeLoadError(w, err)
+			return
+		}
+		snap = p.daemon.StatusSnapshot()
+	}
+	if snap.State != StateRunning || snap.Preset == nil {
+		writeProxyError(w, http.StatusServiceUnavailable, "no model loaded")
+		return
+	}
+
+	target, err := url.Parse(snap.Preset.Endpoint())
+	if err != nil {
+		writeProxyError(w, http.StatusInternalServerError, fmt.Sprintf("invalid endpoint: %v", err))
+		return
+	}
+	model := p.statsKey(snap.Preset, r)
+	w.model = model
+	if err := addSystemPrompt(r, snap.Preset); err != nil {
+		p.logger.Debug("system prompt not added", "error", err)
+	}
+	start := time.Now()
+	// The outgoing request carries r's context, so a client that
+	// disconnects cancels the request to llama-server, which then stops
+	// generating.
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+		},
+		// Flush every write, so streamed tokens (server-sent events)
+		// reach the client as llama-server sends them
+		FlushInterval: -1,
+		ModifyResponse: func(resp *http.Response) error {
+			failed := resp.StatusCode >= http.StatusInternalServerError
+			resp.Body = newUsageReader(resp.Body, resp.Header.Get("Content-Type"), func(u tokenUsage) {
+				w.usage = u
+				p.daemon.stats.record(model, requestUsage{
+					failed:           failed,
+					promptTokens:     u.PromptTokens,
+					completionTokens: u.CompletionTokens,
+					latency:          time.Since(start),
+				})
+			})
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if r.Context().Err() != nil {
+				p.logger.Debug("client disconnected", "path", r.URL.Path)
+				p.daemon.stats.record(model, requestUsage{latency: time.Since(start)})
+				w.WriteHeader(statusClientClosedRequest)
+				return
+			}
+			p.logger.Warn("proxy upstream error", "error", err)
+			p.daemon.stats.record(model, requestUsage{failed: true, latency: time.Since(start)})
+			writeProxyError(w, http.StatusBadGateway, "llama-server unavailable")
+		},
+	}
+	rp.ServeHTTP(w, r)
+}
+
+// statsKey names the model a request is counted against: the running
+// preset, or "preset/model" for a router preset's model.
+func (p *Proxy) statsKey(ps *preset.Preset, r *http.Request) string {
+	if !ps.IsRouter() {
+		return ps.Name
+	}
+	model, err := peekModelField(r)
+	if err != nil || model == "" {
+		return ps.Name
+	}
+	return ps.Name + "/" + model
+}
+
+// loadOnDemand loads the model named in the request body and waits until it
+// is ready. The request body is restored for forwarding. Returns the
+// requested model.
+func (p *Proxy) loadOnDemand(r *http.Request) (string, error) {
+	model, err := peekModelField(r)
+	if err != nil {
+		return "", err
+	}
+	input, ok := p.resolveModelID(model)
+	if !ok {
+		return model, errNoModelRequested
+	}
+
+	p.loadMu.Lock()
+	defer p.loadMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.Context(), p.loadTimeout)
+	defer cancel()
+
+	// Another request (or the CLI) may have loaded a model while we waited.
+	switch p.daemon.State() {
+	case StateRunning:
+		return model, nil
+	case StateLoading:
+		return model, p.waitForRunning(ctx)
+	}
+
+	p.logger.Info("loading model on demand", "input", input)
+	if err := p.daemon.Run(ctx, input); err != nil {
+		// Run reports startup failures in its own terms; surface our timeout.
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return model, ctxErr
+		}
+		return model, err
+	}
+	return model, nil
+}
+
+// waitForRunning polls the daemon state until a load in progress finishes.
+func (p *Proxy) waitForRunning(ctx context.Context) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		switch p.daemon.State() {
+		case StateRunning:
+			return nil
+		case StateIdle:
+			return errors.New("model load failed")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// resolveModelID maps the request's model field to a daemon identifier.
+// Bare names and p: identifiers must name an existing preset; h: identifiers
+// are passed through. File paths are never loaded from HTTP requests.
+func (p *Proxy) resolveModelID(model string) (string, bool) {
+	if model == "" {
+		return "", false
+	}
+	if !strings.Contains(model, ":") {
+		model = "p:" + model
+	}
+
+	id, err := identifier.Parse(model)
+	if err != nil {
+		return "", false
+	}
+	switch id.Type {
+	case identifier.TypePresetName:
+		if _, err := p.daemon.presets.Load(id.PresetName); err != nil {
+			return "", false
+		}
+		return model, true
+	case identifier.TypeHuggingFace:
+		return model, true
+	default:
+		return "", false
+	}
+}
+
+// peekModelField reads the "model" field from a JSON request body and
+// restores the body so it can still be forwarded.
+func peekModelField(r *http.Request) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return "", nil
+	}
+	orig := r.Body
+	body, err := io.ReadAll(io.LimitReader(orig, maxProxyInspectBytes+1))
+	if err != nil {
+		orig.Close()
+		return "", fmt.Errorf("read request body: %w", err)
+	}
+	if len(body) > maxProxyInspectBytes {
+		// Leave the body intact so it can still be forwarded.
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), orig), orig}
+		return "", fmt.Errorf("request body too large to inspect (limit %d bytes)", maxProxyInspectBytes)
+	}
+	orig.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	var payload struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", nil
+	}
+	return payload.Model, nil
+}
+
+// addSystemPrompt adds the preset's system prompt as the first message of
+// a chat completion request that has no system message, so the preset's
+// default behavior applies to clients that do not set their own. The
+// request is left as it is when it cannot be parsed.
+func addSystemPrompt(r *http.Request, ps *preset.Preset) error {
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/chat/completions") {
+		return nil
+	}
+	model, err := peekModelField(r)
+	if err != nil {
+		return err
+	}
+	prompt := ps.SystemPromptFor(model)
+	if prompt == "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload map[string]json.RawMessage
+	var messages []json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(payload["messages"], &messages); err != nil {
+		return err
+	}
+	if len(messages) > 0 {
+		var first struct {
+			Role string `json:"role"`
+		}
+		if json.Unmarshal(messages[0], &first) == nil && (first.Role == "system" || first.Role == "developer") {
+			return nil
+		}
+	}
+
+	system, _ := json.Marshal(map[string]string{"role": "system", "content": prompt})
+	payload["messages"], _ = json.Marshal(append([]json.RawMessage{system}, messages...))
+	body, err = json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil
+}
+
+// writeLoadError maps an on-demand load failure to an HTTP error response.
+func (p *Proxy) writeLoadError(w http.ResponseWriter, err error) {
+	p.logger.Warn("on-demand load failed", "error", err)
+
+	var modelNotFound *metadata.NotFoundError
+	switch {
+	case errors.Is(err, errNoModelRequested):
+		writeProxyError(w, http.StatusServiceUnavailable, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		writeProxyError(w, http.StatusGatewayTimeout, "timed out waiting for model to load")
+	case preset.IsNotFound(err), errors.As(err, &modelNotFound):
+		writeProxyError(w, http.StatusNotFound, err.Error())
+	default:
+		writeProxyError(w, http.StatusBadGateway, err.Error())
+	}
+}
+
+// writeProxyError writes an OpenAI-style JSON error.
+func writeProxyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"message": message,
+			"code":    status,
+		},
+	})
+}
@@ -0,0 +1,409 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// newUpstreamPreset starts a fake llama-server and returns a preset pointing at it.
+func newUpstreamPreset(t *testing.T, name string) *preset.Preset {
+	t.Helper()
+	return newUpstreamPresetWithHandler(t, name, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write([]byte("upstream:" + r.URL.Path + ":" + string(body)))
+	})
+}
+
+// newUpstreamPresetWithHandler starts a fake llama-server serving handler.
+func newUpstreamPresetWithHandler(t *testing.T, name string, handler http.HandlerFunc) *preset.Preset {
+	t.Helper()
+
+	upstream := httptest.NewServer(handler)
+	t.Cleanup(upstream.Close)
+
+	host, portStr, err := net.SplitHostPort(strings.TrimPrefix(upstream.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return &preset.Preset{Name: name, Model: "f:/path/model.gguf", Host: host, Port: port}
+}
+
+// newProxyTestDaemon creates a daemon with mocked process and health check.
+func newProxyTestDaemon(presets map[string]*preset.Preset) (*Daemon, *mockProcess) {
+	d := newTestDaemon(&stubPresetLoader{presets: presets}, &stubModelManager{})
+	proc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess { return proc }
+	d.waitForReady = mockHealthChecker(nil)
+	return d, proc
+}
+
+func TestProxy_ForwardsToRunningModel(t *testing.T) {
+	// Arrange
+	p := newUpstreamPreset(t, "chat")
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	proxy := NewProxy(d, 0, io.Discard)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"other"}`))
+	rec := httptest.NewRecorder()
+
+	// Act
+	proxy.ServeHTTP(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if got := rec.Body.String(); got != `upstream:/v1/chat/completions:{"model":"other"}` {
+		t.Errorf("body = %q", got)
+	}
+}
+
+func TestProxy_AddsSystemPrompt(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want string
+	}{
+		{
+			name: "chat without system message",
+			path: "/v1/chat/completions",
+			body: `{"messages":[{"role":"user","content":"hi"}]}`,
+			want: `{"messages":[{"content":"Be brief.","role":"system"},{"role":"user","content":"hi"}]}`,
+		},
+		{
+			name: "chat with its own system message",
+			path: "/v1/chat/completions",
+			body: `{"messages":[{"role":"system","content":"Be verbose."}]}`,
+			want: `{"messages":[{"role":"system","content":"Be verbose."}]}`,
+		},
+		{
+			name: "plain completion",
+			path: "/v1/completions",
+			body: `{"prompt":"hi"}`,
+			want: `{"prompt":"hi"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p := newUpstreamPreset(t, "chat")
+			p.Sampling.SystemPrompt = "Be brief."
+			d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+			if err := d.Run(context.Background(), "p:chat"); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			proxy := NewProxy(d, 0, io.Discard)
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			// Act
+			proxy.ServeHTTP(rec, req)
+
+			// Assert
+			if got := rec.Body.String(); got != "upstream:"+tt.path+":"+tt.want {
+				t.Errorf("body = %q, want upstream to receive %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProxy_LoadsPresetOnDemand(t *testing.T) {
+	// Arrange
+	p := newUpstreamPreset(t, "chat")
+	d, proc := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	proxy := NewProxy(d, 0, io.Discard)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"chat"}`))
+	rec := httptest.NewRecorder()
+
+	// Act
+	proxy.ServeHTTP(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", rec.Code, rec.Body.String())
+	}
+	if !proc.startCalled {
+		t.Error("expected llama-server to be started on demand")
+	}
+	if d.State() != StateRunning {
+		t.Errorf("State = %q, want %q", d.State(), StateRunning)
+	}
+	if got := rec.Body.String(); got != `upstream:/v1/chat/completions:{"model":"chat"}` {
+		t.Errorf("body should be forwarded intact, got %q", got)
+	}
+}
+
+func TestProxy_IdleWithoutLoadableModel(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"no body model", `{"messages":[]}`, http.StatusServiceUnavailable},
+		{"unknown preset", `{"model":"missing"}`, http.StatusServiceUnavailable},
+		{"file path is never loaded", `{"model":"f:/etc/passwd"}`, http.StatusServiceUnavailable},
+		{"HuggingFace model not downloaded", `{"model":"h:org/repo:Q4_K_M"}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d, proc := newProxyTestDaemon(nil)
+			proxy := NewProxy(d, 0, io.Discard)
+			req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+
+			// Act
+			proxy.ServeHTTP(rec, req)
+
+			// Assert
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if proc.startCalled {
+				t.Error("llama-server should not be started")
+			}
+		})
+	}
+}
+
+func TestProxy_LoadTimeout(t *testing.T) {
+	// Arrange
+	p := newUpstreamPreset(t, "chat")
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	proxy := NewProxy(d, 50*time.Millisecond, io.Discard)
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"chat"}`))
+	rec := httptest.NewRecorder()
+
+	// Act
+	proxy.ServeHTTP(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d (body: %s)", rec.Code, http.StatusGatewayTimeout, rec.Body.String())
+	}
+}
+
+func TestProxy_RecordsUsageStats(t *testing.T) {
+	// Arrange
+	p := newUpstreamPresetWithHandler(t, "chat", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":34}}`))
+	})
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	proxy := NewProxy(d, 0, io.Discard)
+
+	// Act
+	for range 2 {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{}`))
+		proxy.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// Assert
+	stats := d.UsageStats()
+	if len(stats) != 1 {
+		t.Fatalf("UsageStats() = %+v, want one model", stats)
+	}
+	got := stats[0]
+	if got.Model != "chat" || got.Requests != 2 || got.Errors != 0 {
+		t.Errorf("stats = %+v, want 2 requests for chat", got)
+	}
+	if got.PromptTokens != 24 || got.CompletionTokens != 68 {
+		t.Errorf("tokens = %d/%d, want 24/68", got.PromptTokens, got.CompletionTokens)
+	}
+}
+
+func TestProxy_RecordsUpstreamFailure(t *testing.T) {
+	// Arrange
+	p := newUpstreamPresetWithHandler(t, "chat", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	proxy := NewProxy(d, 0, io.Discard)
+
+	// Act
+	proxy.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+
+	// Assert
+	stats := d.UsageStats()
+	if len(stats) != 1 || stats[0].Errors != 1 {
+		t.Errorf("UsageStats() = %+v, want one error for chat", stats)
+	}
+}
+
+// newStreamingUpstream returns a preset whose fake llama-server sends one
+// server-sent event, then waits for release (or the request to be canceled)
+// before sending the last one. canceled is closed if the request is canceled.
+func newStreamingUpstream(t *testing.T, release <-chan struct{}) (p *preset.Preset, canceled <-chan struct{}) {
+	t.Helper()
+	done := make(chan struct{})
+	p = newUpstreamPresetWithHandler(t, "chat", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"content\":\"one\"}\n\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+			w.Write([]byte("data: {\"content\":\"two\"}\n\ndata: [DONE]\n\n"))
+		case <-r.Context().Done():
+			close(done)
+		}
+	})
+	return p, done
+}
+
+// readLine reads one line from r, failing the test if none arrives in time.
+func readLine(t *testing.T, r *bufio.Reader) string {
+	t.Helper()
+	lines := make(chan string, 1)
+	go func() {
+		line, _ := r.ReadString('\n')
+		lines <- line
+	}()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("no data received; the response is buffered")
+		return ""
+	}
+}
+
+func TestProxy_StreamsEventsUnbuffered(t *testing.T) {
+	// Arrange
+	release := make(chan struct{})
+	p, _ := newStreamingUpstream(t, release)
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	srv := httptest.NewServer(NewProxy(d, 0, io.Discard))
+	t.Cleanup(srv.Close)
+
+	// Act
+	resp, err := http.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(`{"stream":true}`))
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	body := bufio.NewReader(resp.Body)
+	first := readLine(t, body)
+	close(release)
+	rest, _ := io.ReadAll(body)
+
+	// Assert
+	if first != "data: {\"content\":\"one\"}\n" {
+		t.Errorf("first line = %q, want the first event before the stream ends", first)
+	}
+	if !strings.HasSuffix(string(rest), "data: [DONE]\n\n") {
+		t.Errorf("rest of stream = %q, want it to end with [DONE]", rest)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+}
+
+func TestProxy_ClientDisconnectCancelsUpstream(t *testing.T) {
+	// Arrange
+	p, canceled := newStreamingUpstream(t, nil)
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	proxy := NewProxy(d, 0, io.Discard)
+	var logBuf bytes.Buffer
+	proxy.SetRequestLog(&logBuf, false)
+	srv := httptest.NewServer(proxy)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "POST", srv.URL+"/v1/chat/completions", strings.NewReader(`{"stream":true}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	readLine(t, bufio.NewReader(resp.Body))
+
+	// Act
+	cancel()
+
+	// Assert
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream request was not canceled after the client disconnected")
+	}
+	srv.Close() // wait for the handler to finish
+	if stats := d.UsageStats(); len(stats) != 1 || stats[0].Errors != 0 {
+		t.Errorf("UsageStats() = %+v, want one request without errors", stats)
+	}
+	if !strings.Contains(logBuf.String(), `"path":"/v1/chat/completions"`) {
+		t.Errorf("request log = %q, want the aborted request logged", logBuf.String())
+	}
+}
+
+func TestProxy_ServesHTTP2Cleartext(t *testing.T) {
+	// Arrange
+	p := newUpstreamPreset(t, "chat")
+	d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+	if err := d.Run(context.Background(), "p:chat"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewProxy(d, 0, io.Discard).newServer()
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+
+	// Act
+	resp, err := client.Post("http://"+listener.Addr().String()+"/v1/models", "application/json", strings.NewReader(`{}`))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("POST error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+	if string(body) != "upstream:/v1/models:{}" {
+		t.Errorf("body = %q", body)
+	}
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// maxLoggedBodyBytes limits how much of each request and response body is
+// written to the request log.
+const maxLoggedBodyBytes = 64 << 10
+
+// SetRequestLog records one entry per proxied request (model, path, status,
+// token counts, latency) as JSON lines in w. Bodies are included only if
+// includeBodies is set, since they contain prompts and completions.
+func (p *Proxy) SetRequestLog(w io.Writer, includeBodies bool) {
+	p.requestLog = slog.New(slog.NewJSONHandler(w, nil))
+	p.logBodies = includeBodies
+}
+
+// requestRecord wraps the client's ResponseWriter to collect what the
+// request log reports about one request.
+type requestRecord struct {
+	http.ResponseWriter
+	start    time.Time
+	status   int
+	model    string
+	usage    tokenUsage
+	reqBody  *cappedBuffer // nil unless bodies are logged
+	respBody *cappedBuffer
+}
+
+// newRequestRecord starts recording r. With bodies, the request body is
+// captured as it is read.
+func newRequestRecord(w http.ResponseWriter, r *http.Request, bodies bool) *requestRecord {
+	rec := &requestRecord{ResponseWriter: w, start: time.Now()}
+	if bodies {
+		rec.reqBody = &cappedBuffer{}
+		rec.respBody = &cappedBuffer{}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, rec.reqBody), r.Body}
+		}
+	}
+	return rec
+}
+
+func (rec *requestRecord) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *requestRecord) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.respBody != nil {
+		rec.respBody.Write(b)
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the client connection, so
+// streamed responses are still flushed.
+func (rec *requestRecord) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// logRequest writes the finished request to the request log.
+func (p *Proxy) logRequest(rec *requestRecord, r *http.Request) {
+	attrs := []any{
+		"method", r.Method,
+		"path", r.URL.Path,
+		"model", rec.model,
+		"status", rec.status,
+		"latency_ms", time.Since(rec.start).Milliseconds(),
+		"prompt_tokens", rec.usage.PromptTokens,
+		"completion_tokens", rec.usage.CompletionTokens,
+	}
+	if rec.reqBody != nil {
+		attrs = append(attrs, "request_body", rec.reqBody.String(), "response_body", rec.respBody.String())
+	}
+	p.requestLog.Info("request", attrs...)
+}
+
+// cappedBuffer keeps the first maxLoggedBodyBytes written to it.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (c *cappedBuffer) Write(b []byte) (int, error) {
+	if room := maxLoggedBodyBytes - c.buf.Len(); len(b) > room {
+		c.buf.Write(b[:room])
+		c.truncated = true
+	} else {
+		c.buf.Write(b)
+	}
+	return len(b), nil
+}
+
+func (c *cappedBuffer) String() string {
+	if c.truncated {
+		return c.buf.String() + "...(truncated)"
+	}
+	return c.buf.String()
+}
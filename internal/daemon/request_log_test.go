@@ -0,0 +1,131 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// decodeRequestLog parses the JSON lines written to the request log.
+func decodeRequestLog(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestProxy_RequestLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		includeBodies bool
+	}{
+		{"metadata only", false},
+		{"with bodies", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p := newUpstreamPresetWithHandler(t, "chat", func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"usage":{"prompt_tokens":12,"completion_tokens":34}}`))
+			})
+			d, _ := newProxyTestDaemon(map[string]*preset.Preset{"chat": p})
+			if err := d.Run(context.Background(), "p:chat"); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			proxy := NewProxy(d, 0, io.Discard)
+			var logBuf bytes.Buffer
+			proxy.SetRequestLog(&logBuf, tt.includeBodies)
+			req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"prompt":"secret"}`))
+
+			// Act
+			proxy.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			entries := decodeRequestLog(t, &logBuf)
+			if len(entries) != 1 {
+				t.Fatalf("log entries = %d, want 1", len(entries))
+			}
+			e := entries[0]
+			if e["model"] != "chat" || e["path"] != "/v1/chat/completions" || e["status"] != float64(200) {
+				t.Errorf("entry = %v, want model chat, path /v1/chat/completions, status 200", e)
+			}
+			if e["prompt_tokens"] != float64(12) || e["completion_tokens"] != float64(34) {
+				t.Errorf("tokens = %v/%v, want 12/34", e["prompt_tokens"], e["completion_tokens"])
+			}
+			if _, ok := e["latency_ms"]; !ok {
+				t.Error("latency_ms missing")
+			}
+			body, hasBody := e["request_body"]
+			if hasBody != tt.includeBodies {
+				t.Fatalf("request_body present = %v, want %v", hasBody, tt.includeBodies)
+			}
+			if tt.includeBodies {
+				if body != `{"prompt":"secret"}` {
+					t.Errorf("request_body = %q", body)
+				}
+				if !strings.Contains(e["response_body"].(string), "prompt_tokens") {
+					t.Errorf("response_body = %q", e["response_body"])
+				}
+			}
+		})
+	}
+}
+
+func TestProxy_RequestLogLoadFailure(t *testing.T) {
+	// Arrange
+	d, _ := newProxyTestDaemon(nil)
+	proxy := NewProxy(d, 0, io.Discard)
+	var logBuf bytes.Buffer
+	proxy.SetRequestLog(&logBuf, false)
+	req := httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(`{"model":"missing"}`))
+
+	// Act
+	proxy.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	entries := decodeRequestLog(t, &logBuf)
+	if len(entries) != 1 {
+		t.Fatalf("log entries = %d, want 1", len(entries))
+	}
+	if entries[0]["model"] != "missing" || entries[0]["status"] != float64(http.StatusServiceUnavailable) {
+		t.Errorf("entry = %v, want model missing, status 503", entries[0])
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	// Arrange
+	var c cappedBuffer
+	chunk := bytes.Repeat([]byte("a"), maxLoggedBodyBytes-1)
+
+	// Act
+	c.Write(chunk)
+	n, err := c.Write([]byte("bcd"))
+
+	// Assert
+	if n != 3 || err != nil {
+		t.Errorf("Write() = %d, %v, want 3, nil", n, err)
+	}
+	got := c.String()
+	if !strings.HasSuffix(got, "ab...(truncated)") || len(got) != maxLoggedBodyBytes+len("...(truncated)") {
+		t.Errorf("String() has unexpected tail %q (len %d)", got[len(got)-20:], len(got))
+	}
+}
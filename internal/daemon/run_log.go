@@ -0,0 +1,156 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultRunLogsKeep is how many run logs are kept when not configured.
+const defaultRunLogsKeep = 20
+
+// runLogTimeFormat is the timestamp in run log file names.
+const runLogTimeFormat = "20060102-150405"
+
+// unsafeNameChars matches characters replaced in the preset part of run
+// log file names (model file paths make preset names).
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SetRunLogs writes each llama-server run's output to its own file in dir,
+// in addition to the combined log, keeping the newest keep files. An empty
+// dir disables run logs; keep of zero or less restores the default.
+func (d *Daemon) SetRunLogs(dir string, keep int) {
+	if keep <= 0 {
+		keep = defaultRunLogsKeep
+	}
+	d.updateSettings(func(s *runtimeSettings) {
+		s.runLogDir = dir
+		s.runLogsKeep = keep
+	})
+}
+
+// RunLogName returns the file name of the run log for presetName started at t.
+func RunLogName(presetName string, t time.Time) string {
+	return runLogPrefix(presetName) + t.Format(runLogTimeFormat) + ".log"
+}
+
+// runLogPrefix returns the part of run log file names before the timestamp.
+func runLogPrefix(presetName string) string {
+	return "llama-" + strings.Trim(unsafeNameChars.ReplaceAllString(presetName, "_"), "_") + "-"
+}
+
+// FindRunLog returns the run log in dir selected by sel: "latest", a
+// preset name (its latest run), or a file name in dir.
+func FindRunLog(dir, sel string) (string, error) {
+	logs, err := RunLogs(dir)
+	if err != nil {
+		return "", err
+	}
+	if len(logs) == 0 {
+		return "", fmt.Errorf("no run logs in %s\nHint: Set 'run-logs: true' in config.yaml and restart the daemon", dir)
+	}
+	if sel == "latest" {
+		return logs[len(logs)-1], nil
+	}
+	if path := filepath.Join(dir, sel); filepath.Base(sel) == sel && slices.Contains(logs, path) {
+		return path, nil
+	}
+	prefix := runLogPrefix(sel)
+	for _, path := range slices.Backward(logs) {
+		// Another preset's name may extend this one's, e.g. "qwen" and "qwen-big".
+		ts, ok := strings.CutPrefix(strings.TrimSuffix(filepath.Base(path), ".log"), prefix)
+		if _, err := time.Parse(runLogTimeFormat, ts); ok && err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no run log for '%s' in %s", sel, dir)
+}
+
+// openRunLog creates the run log for presetName and prunes old ones. It
+// returns nil if run logs are disabled or the file cannot be created; the
+// run then goes to the combined log only.
+func (d *Daemon) openRunLog(presetName string) *os.File {
+	s := d.settings.Load()
+	if s.runLogDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(s.runLogDir, 0755); err != nil {
+		d.logger.Warn("failed to create run log directory", "error", err)
+		return nil
+	}
+	path := filepath.Join(s.runLogDir, RunLogName(presetName, time.Now()))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		d.logger.Warn("failed to create run log", "error", err)
+		return nil
+	}
+	d.logger.Info("llama-server output", "run_log", path)
+	d.pruneRunLogs(s.runLogDir, s.runLogsKeep)
+	return f
+}
+
+// pruneRunLogs deletes all but the newest keep run logs in dir.
+func (d *Daemon) pruneRunLogs(dir string, keep int) {
+	logs, err := RunLogs(dir)
+	if err != nil || len(logs) <= keep {
+		return
+	}
+	for _, path := range logs[:len(logs)-keep] {
+		if err := os.Remove(path); err != nil {
+			d.logger.Warn("failed to remove old run log", "path", path, "error", err)
+		}
+	}
+}
+
+// RunLogs returns the run logs in dir, oldest first.
+func RunLogs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read run logs: %w", err)
+	}
+	type runLog struct {
+		path    string
+		modTime time.Time
+	}
+	var logs []runLog
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "llama-") || !strings.HasSuffix(e.Name(), ".log") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		logs = append(logs, runLog{path: filepath.Join(dir, e.Name()), modTime: info.ModTime()})
+	}
+	slices.SortStableFunc(logs, func(a, b runLog) int {
+		if c := a.modTime.Compare(b.modTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path, b.path)
+	})
+	paths := make([]string, len(logs))
+	for i, l := range logs {
+		paths[i] = l.path
+	}
+	return paths, nil
+}
+
+// bestEffortWriter never fails, so a full disk under a run log cannot stop
+// llama-server's output from reaching the combined log.
+type bestEffortWriter struct {
+	w io.Writer
+}
+
+func (b bestEffortWriter) Write(p []byte) (int, error) {
+	b.w.Write(p)
+	return len(p), nil
+}
@@ -0,0 +1,141 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func TestRunLogName(t *testing.T) {
+	ts := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	tests := []struct {
+		preset string
+		want   string
+	}{
+		{"qwen3-coder", "llama-qwen3-coder-20260314-150926.log"},
+		{"/home/me/models/gemma 3.gguf", "llama-home_me_models_gemma_3.gguf-20260314-150926.log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			if got := RunLogName(tt.preset, ts); got != tt.want {
+				t.Errorf("RunLogName(%q) = %q, want %q", tt.preset, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeRunLogs creates the named files in dir, each one second newer than
+// the one before.
+func writeRunLogs(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	base := time.Now().Add(-time.Hour)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindRunLog(t *testing.T) {
+	dir := t.TempDir()
+	writeRunLogs(t, dir,
+		"llama-qwen-20260101-100000.log",
+		"llama-qwen-20260101-110000.log",
+		"llama-qwen-big-20260101-120000.log",
+		"notes.txt",
+	)
+
+	tests := []struct {
+		sel     string
+		want    string
+		wantErr string
+	}{
+		{sel: "latest", want: "llama-qwen-big-20260101-120000.log"},
+		{sel: "qwen", want: "llama-qwen-20260101-110000.log"},
+		{sel: "qwen-big", want: "llama-qwen-big-20260101-120000.log"},
+		{sel: "llama-qwen-20260101-100000.log", want: "llama-qwen-20260101-100000.log"},
+		{sel: "gemma", wantErr: "no run log for 'gemma'"},
+		{sel: "../notes.txt", wantErr: "no run log for '../notes.txt'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sel, func(t *testing.T) {
+			// Act
+			got, err := FindRunLog(dir, tt.sel)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FindRunLog() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != filepath.Join(dir, tt.want) {
+				t.Errorf("FindRunLog() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindRunLog_NoRunLogs(t *testing.T) {
+	// Act
+	_, err := FindRunLog(filepath.Join(t.TempDir(), "runs"), "latest")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "run-logs: true") {
+		t.Errorf("FindRunLog() error = %v, want a hint to enable run-logs", err)
+	}
+}
+
+func TestDaemonRun_WritesRunLog(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeRunLogs(t, dir, "llama-old-20250101-000000.log", "llama-older-20250101-000001.log")
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"coder": {Name: "coder", Model: "f:/m.gguf"},
+	}}
+	d := newTestDaemon(presets, &stubModelManager{})
+	d.SetRunLogs(dir, 2)
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess { return mockProc }
+	d.waitForReady = mockHealthChecker(nil)
+
+	// Act
+	err := d.Run(context.Background(), "p:coder")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	mockProc.logWriter.Write([]byte("main: server is listening\n"))
+
+	logs, err := RunLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || filepath.Base(logs[0]) != "llama-older-20250101-000001.log" {
+		t.Fatalf("run logs = %v, want the newest old log and the new one", logs)
+	}
+	if !strings.HasPrefix(filepath.Base(logs[1]), "llama-coder-") {
+		t.Errorf("new run log = %s, want llama-coder-<ts>.log", logs[1])
+	}
+	data, err := os.ReadFile(logs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "main: server is listening\n" {
+		t.Errorf("run log = %q, want llama-server output", data)
+	}
+}
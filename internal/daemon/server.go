@@ -3,13 +3,19 @@ package daemon
 import (
 	"bufio"
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/d2verb/alpaca/internal/llama"
@@ -17,14 +23,28 @@ import (
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/sysinfo"
 )
 
-// Server handles Unix socket communication.
+// Server handles Unix socket communication and, optionally, remote
+// clients over TCP.
 type Server struct {
-	daemon     *Daemon
-	socketPath string
-	listener   net.Listener
-	logger     *slog.Logger
+	daemon      *Daemon
+	socketPath  string
+	listener    net.Listener
+	tcpListener net.Listener
+	token       atomic.Pointer[string] // required on TCP connections
+	allowedUIDs []int                  // other users allowed on the Unix socket
+	logger      *slog.Logger
+	startedAt   time.Time
+	version     string        // alpaca version reported by status
+	slots       chan struct{} // in-flight requests; see SetMaxRequests
+	queueWait   time.Duration
+
+	// Test hooks (optional, default to the platform implementations)
+	peerUID      func(conn net.Conn) (int, error)
+	processUsage func(pid int) (sysinfo.ProcessUsage, error)
+	inspect      func(pid int, args []string) llama.ProcessInfo
 }
 
 // NewServer creates a new daemon server.
@@ -36,38 +56,117 @@ func NewServer(daemon *Daemon, socketPath string, logWriter io.Writer) *Server {
 		daemon:     daemon,
 		socketPath: socketPath,
 		logger:     logging.NewLogger(logWriter),
+		startedAt:  time.Now(),
+		slots:      make(chan struct{}, DefaultMaxRequests),
+		queueWait:  queueWait,
+		peerUID:    peerUID,
+
+		processUsage: sysinfo.DetectProcessUsage,
+		inspect:      llama.Inspect,
 	}
 }
 
+// SetVersion sets the alpaca version reported by status.
+func (s *Server) SetVersion(version string) {
+	s.version = version
+}
+
 // Start starts listening on the Unix socket.
 func (s *Server) Start(ctx context.Context) error {
+	dirMode, socketMode := s.socketModes()
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), dirMode); err != nil {
+		return err
+	}
+
 	// Remove existing socket file
 	if err := os.Remove(s.socketPath); err != nil && !os.IsNotExist(err) {
 		return err
 	}
 
-	listener, err := net.Listen("unix", s.socketPath)
+	// Bind the socket in a private directory and move it into place once
+	// its mode is set, so it is never briefly accessible to other users.
+	tmpDir, err := os.MkdirTemp(filepath.Dir(s.socketPath), ".s")
 	if err != nil {
 		return err
 	}
-	s.listener = listener
-
-	// Set socket permissions to owner-only (0600)
-	if err := os.Chmod(s.socketPath, 0600); err != nil {
+	defer os.RemoveAll(tmpDir)
+	tmpPath := filepath.Join(tmpDir, "s")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, socketMode); err != nil {
+		listener.Close()
+		return err
+	}
+	if err := os.Rename(tmpPath, s.socketPath); err != nil {
 		listener.Close()
 		return err
 	}
+	// Closing would unlink the temporary path; Stop removes the socket
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	s.listener = listener
 
 	s.logger.Info("server started", "socket", s.socketPath)
-	go s.acceptLoop(ctx)
+	go s.acceptLoop(ctx, listener, false)
+	return nil
+}
+
+// ListenTCP starts an additional TCP listener for remote clients.
+// Every request received on it must carry token. Connections use TLS
+// with tlsConfig; without it only loopback addresses are accepted, since
+// the token would otherwise cross the network in plaintext.
+func (s *Server) ListenTCP(ctx context.Context, addr, token string, tlsConfig *tls.Config) error {
+	if token == "" {
+		return errors.New("remote listener requires a token")
+	}
+	if err := CheckListen(addr, tlsConfig); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s.tcpListener = listener
+	s.token.Store(&token)
+
+	s.logger.Info("remote listener started", "addr", listener.Addr().String())
+	go s.acceptLoop(ctx, listener, true)
+	return nil
+}
+
+// CheckListen reports an error if the remote listener may not use addr
+// with tlsConfig: plaintext is only allowed on loopback addresses.
+func CheckListen(addr string, tlsConfig *tls.Config) error {
+	if tlsConfig == nil && !protocol.IsLoopback(addr) {
+		return fmt.Errorf("listening on %s requires TLS (--tls-cert and --tls-key); without it, bind to 127.0.0.1 and use SSH port forwarding", addr)
+	}
+	return nil
+}
+
+// SetToken replaces the token remote clients must present, e.g. after the
+// token file was changed. It applies from the next request.
+func (s *Server) SetToken(token string) error {
+	if token == "" {
+		return errors.New("remote listener requires a token")
+	}
+	s.token.Store(&token)
 	return nil
 }
 
 // Stop stops the server.
 func (s *Server) Stop() error {
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
 	if s.listener != nil {
 		err := s.listener.Close()
 		if err == nil {
+			os.Remove(s.socketPath)
 			s.logger.Info("server stopped")
 		}
 		return err
@@ -75,9 +174,9 @@ func (s *Server) Stop() error {
 	return nil
 }
 
-func (s *Server) acceptLoop(ctx context.Context) {
+func (s *Server) acceptLoop(ctx context.Context, listener net.Listener, remote bool) {
 	for {
-		conn, err := s.listener.Accept()
+		conn, err := listener.Accept()
 		if err != nil {
 			select {
 			case <-ctx.Done():
@@ -90,13 +189,18 @@ func (s *Server) acceptLoop(ctx context.Context) {
 				continue
 			}
 		}
-		go s.handleConnection(ctx, conn)
+		go s.handleConnection(ctx, conn, remote)
 	}
 }
 
-func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
+func (s *Server) handleConnection(ctx context.Context, conn net.Conn, remote bool) {
 	defer conn.Close()
 
+	if !remote && !s.authorizePeer(conn) {
+		s.writeResponse(conn, protocol.NewErrorResponseWithCode(protocol.ErrCodeUnauthorized, "unauthorized"))
+		return
+	}
+
 	reader := bufio.NewReader(conn)
 	line, err := reader.ReadBytes('\n')
 	if err != nil {
@@ -114,8 +218,58 @@ func (s *Server) handleConnection(ctx context.Context, conn net.Conn) {
 		return
 	}
 
-	resp := s.handleRequest(ctx, &req)
-	s.writeResponse(conn, resp)
+	if remote && subtle.ConstantTimeCompare([]byte(req.Token), []byte(*s.token.Load())) != 1 {
+		s.logger.Warn("unauthorized remote request", "remote", conn.RemoteAddr().String())
+		s.writeResponse(conn, protocol.NewErrorResponseWithCode(protocol.ErrCodeUnauthorized, "unauthorized"))
+		return
+	}
+
+	// Clients without a version (the macOS app, older scripts) are served
+	// as before versioning
+	if req.Version != 0 && req.Version != protocol.Version && req.Command != protocol.CmdHello {
+		s.logger.Warn("request from another protocol version", "command", req.Command, "version", req.Version)
+		s.writeResponse(conn, protocol.NewErrorResponseWithCode(protocol.ErrCodeVersionMismatch, fmt.Sprintf(
+			"this alpaca CLI speaks protocol version %d but the daemon speaks %d; restart the daemon after upgrading (alpaca stop && alpaca start)",
+			req.Version, protocol.Version)))
+		return
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if remote {
+		reqCtx = context.WithValue(reqCtx, remoteKey{}, true)
+	}
+	go s.watchCancel(reader, cancel)
+
+	w := &progressWriter{s: s, conn: conn}
+	w.finish(s.serveRequest(context.WithValue(reqCtx, progressKey{}, w), &req))
+}
+
+// remoteKey is the context key marking a request received on the TCP
+// listener.
+type remoteKey struct{}
+
+// isRemote reports whether the request of ctx came from a remote client.
+func isRemote(ctx context.Context) bool {
+	remote, _ := ctx.Value(remoteKey{}).(bool)
+	return remote
+}
+
+// watchCancel cancels the request in progress when the client sends
+// CmdCancel on its connection. It returns once the connection is closed.
+func (s *Server) watchCancel(reader *bufio.Reader, cancel context.CancelFunc) {
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var req protocol.Request
+		if json.Unmarshal(line, &req) == nil && req.Command == protocol.CmdCancel {
+			s.logger.Info("request canceled by client")
+			cancel()
+			return
+		}
+	}
 }
 
 func (s *Server) handleRequest(ctx context.Context, req *protocol.Request) *protocol.Response {
@@ -128,13 +282,23 @@ func (s *Server) handleRequest(ctx context.Context, req *protocol.Request) *prot
 	case protocol.CmdLoad:
 		resp = s.handleLoad(ctx, req)
 	case protocol.CmdUnload:
-		resp = s.handleUnload(ctx)
+		resp = s.handleUnload(ctx, req)
 	case protocol.CmdListPresets:
 		resp = s.handleListPresets()
 	case protocol.CmdListModels:
 		resp = s.handleListModels(ctx)
+	case protocol.CmdStats:
+		resp = s.handleStats()
+	case protocol.CmdPs:
+		resp = s.handlePs()
+	case protocol.CmdComplete:
+		resp = s.handleComplete(ctx)
+	case protocol.CmdCancel:
+		resp = s.handleCancel()
+	case protocol.CmdHello:
+		resp = protocol.NewOKResponse(protocol.HelloData{Version: protocol.Version})
 	default:
-		resp = protocol.NewErrorResponse("unknown command")
+		resp = protocol.NewErrorResponseWithCode(protocol.ErrCodeUnknownCommand, "unknown command")
 	}
 
 	if resp.Status == protocol.StatusError {
@@ -145,62 +309,223 @@ func (s *Server) handleRequest(ctx context.Context, req *protocol.Request) *prot
 
 func (s *Server) handleStatus(ctx context.Context) *protocol.Response {
 	snap := s.daemon.StatusSnapshot()
-	data := map[string]any{
-		"state": string(snap.State),
+	data := protocol.StatusData{
+		State:  string(snap.State),
+		Since:  snap.Since.UTC().Truncate(time.Second),
+		Detail: snap.Detail,
+		Daemon: s.daemonInfo(),
 	}
 	if p := snap.Preset; p != nil {
-		data["preset"] = p.Name
-		data["endpoint"] = p.Endpoint()
+		data.Preset = p.Name
+		data.Endpoint = p.Endpoint()
+		data.Files = modelFiles(p)
+		if inv := s.daemon.Invocation(); inv != nil {
+			data.Command = append([]string{inv.Command}, inv.Args...)
+			data.ConfigINI = inv.ConfigINI
+			if inv.PID > 0 {
+				data.LlamaServer = s.processInfo(inv.PID)
+			}
+		}
 
 		// Add mmproj path for single mode
 		if preset.IsMmprojActive(p.Mmproj) {
-			data["mmproj"] = strings.TrimPrefix(p.Mmproj, "f:")
+			data.Mmproj = strings.TrimPrefix(p.Mmproj, "f:")
 		}
 
 		if p.IsRouter() {
-			data["mode"] = "router"
-
-			// Build mmproj map from preset models
-			mmprojMap := map[string]string{}
-			for _, m := range p.Models {
-				if preset.IsMmprojActive(m.Mmproj) {
-					mmprojMap[m.Name] = strings.TrimPrefix(m.Mmproj, "f:")
-				}
-			}
+			data.Mode = "router"
+			data.Models, data.ModelsError = s.routerModels(ctx, p)
+		}
 
-			if statuses := s.daemon.FetchModelStatuses(ctx); statuses != nil {
-				models := []map[string]any{}
-				for _, m := range statuses {
-					modelData := map[string]any{
-						"id":     m.ID,
-						"status": m.Status.Value,
-					}
-					if mmprojPath, ok := mmprojMap[m.ID]; ok {
-						modelData["mmproj"] = mmprojPath
-					}
-					models = append(models, modelData)
-				}
-				data["models"] = models
-			}
+		if m := s.daemon.FetchMetrics(ctx); m != nil {
+			data.Metrics = metricsData(m)
 		}
 	}
 	return protocol.NewOKResponse(data)
 }
 
+// handlePs lists the llama-server processes the daemon runs: the one of
+// the loaded preset, if any.
+func (s *Server) handlePs() *protocol.Response {
+	data := protocol.PsData{Processes: []protocol.LlamaProcessData{}}
+	snap := s.daemon.StatusSnapshot()
+	inv := s.daemon.Invocation()
+	if snap.Preset == nil || inv == nil || inv.PID <= 0 {
+		return protocol.NewOKResponse(data)
+	}
+
+	info := s.inspect(inv.PID, inv.Args)
+	proc := protocol.LlamaProcessData{
+		PID:       info.PID,
+		Preset:    snap.Preset.Name,
+		State:     string(snap.State),
+		StartedAt: info.StartedAt.UTC().Truncate(time.Second),
+		Port:      info.Port,
+		Model:     info.Model,
+	}
+	if info.RSS >= 0 {
+		proc.RSS = &info.RSS
+	}
+	if snap.Preset.IsRouter() {
+		proc.Mode = "router"
+	}
+	data.Processes = append(data.Processes, proc)
+	return protocol.NewOKResponse(data)
+}
+
+// routerModels returns the models llama-server reports for router preset
+// p, or why they could not be read. The models are a non-nil slice when
+// they were read.
+func (s *Server) routerModels(ctx context.Context, p *preset.Preset) ([]protocol.RouterModelData, string) {
+	// Build mmproj map from preset models
+	mmprojMap := map[string]string{}
+	for _, m := range p.Models {
+		if preset.IsMmprojActive(m.Mmproj) {
+			mmprojMap[m.Name] = strings.TrimPrefix(m.Mmproj, "f:")
+		}
+	}
+
+	statuses, err := s.daemon.FetchModelStatuses(ctx)
+	if err != nil {
+		s.logger.Debug("router model status unavailable", "error", err)
+		return nil, err.Error()
+	}
+	models := []protocol.RouterModelData{}
+	for _, m := range statuses {
+		models = append(models, protocol.RouterModelData{
+			ID:          m.ID,
+			Status:      m.Status.Value,
+			Mmproj:      mmprojMap[m.ID],
+			ActiveSlots: m.ActiveSlots,
+			Queued:      m.Queued,
+		})
+	}
+	return models, ""
+}
+
+// missingRouterModels returns the models of router preset p that are not
+// among the models llama-server reported.
+func missingRouterModels(p *preset.Preset, models []protocol.RouterModelData) []string {
+	var missing []string
+	for _, m := range p.Models {
+		if !slices.ContainsFunc(models, func(r protocol.RouterModelData) bool { return r.ID == m.Name }) {
+			missing = append(missing, m.Name)
+		}
+	}
+	return missing
+}
+
+// daemonInfo describes the daemon process: its version, when it started,
+// and its resource usage.
+func (s *Server) daemonInfo() *protocol.ProcessData {
+	info := s.processInfo(os.Getpid())
+	info.Version = s.version
+	info.StartedAt = s.startedAt.UTC().Truncate(time.Second)
+	return info
+}
+
+// processInfo returns the PID and, when it can be read, the resident memory
+// (bytes) and CPU time (seconds) of process pid.
+func (s *Server) processInfo(pid int) *protocol.ProcessData {
+	info := &protocol.ProcessData{PID: pid}
+	if u, err := s.processUsage(pid); err == nil {
+		cpu := u.CPU.Seconds()
+		info.RSS, info.CPUSeconds = &u.RSS, &cpu
+	}
+	return info
+}
+
+// metricsData converts m to status response fields, leaving out values
+// llama-server did not report.
+func metricsData(m *ServerMetrics) *protocol.MetricsData {
+	data := &protocol.MetricsData{}
+	if m.KVCacheUsage >= 0 {
+		data.KVCacheUsage = &m.KVCacheUsage
+	}
+	if m.SlotsBusy >= 0 {
+		data.SlotsBusy = &m.SlotsBusy
+	}
+	if m.SlotsTotal >= 0 {
+		data.SlotsTotal = &m.SlotsTotal
+	}
+	if m.PromptTokensPerSec >= 0 {
+		data.PromptTokensPerSec = &m.PromptTokensPerSec
+	}
+	if m.PredictedTokensPerSec >= 0 {
+		data.PredictedTokensPerSec = &m.PredictedTokensPerSec
+	}
+	return data
+}
+
 func (s *Server) handleLoad(ctx context.Context, req *protocol.Request) *protocol.Response {
 	identifier, ok := req.Args["identifier"].(string)
 	if !ok {
 		return protocol.NewErrorResponse("identifier required")
 	}
 
-	if err := s.daemon.Run(ctx, identifier); err != nil {
+	var opts RunOptions
+	if draft, ok := req.Args["draft_model"].(string); ok {
+		opts.DraftModel = draft
+	}
+	if server, ok := req.Args["llama_server"].(string); ok && server != "" {
+		// The binary runs as the daemon's user, so only local clients,
+		// who could run it themselves, may choose it
+		if isRemote(ctx) {
+			return protocol.NewErrorResponse("llama_server cannot be set by remote clients; set llama-server-path in the daemon's config.yaml or preset instead")
+		}
+		opts.LlamaServer = server
+	}
+	if watch, ok := req.Args["watch"].(bool); ok {
+		opts.Watch = watch
+	}
+	if strict, ok := req.Args["strict"].(bool); ok {
+		opts.Strict = strict
+	}
+	if progress, _ := req.Args["progress"].(bool); progress {
+		opts.Progress = loadProgress(ctx)
+	}
+
+	if dryRun, _ := req.Args["dry_run"].(bool); dryRun {
+		return s.handleDryRun(ctx, identifier, opts)
+	}
+
+	if err := s.daemon.RunWithOptions(ctx, identifier, opts); err != nil {
 		code, msg := classifyLoadError(err)
+		var failure *LoadFailure
+		if errors.As(err, &failure) {
+			return protocol.NewErrorResponseWithData(code, msg, failure.Diagnostics)
+		}
 		return protocol.NewErrorResponseWithCode(code, msg)
 	}
 
-	preset := s.daemon.CurrentPreset()
-	return protocol.NewOKResponse(map[string]any{
-		"endpoint": preset.Endpoint(),
+	p := s.daemon.CurrentPreset()
+	data := protocol.LoadData{
+		Endpoint: p.Endpoint(),
+		Warnings: s.daemon.OptionWarnings(ctx, p),
+	}
+	// Reported right away, so the client learns which models the router
+	// registered without asking for status
+	if p.IsRouter() {
+		data.Models, data.ModelsError = s.routerModels(ctx, p)
+		if data.ModelsError == "" {
+			data.MissingModels = missingRouterModels(p, data.Models)
+		}
+	}
+	return protocol.NewOKResponse(data)
+}
+
+// handleDryRun answers a load with dry_run set: the llama-server command
+// the load would run, without running it.
+func (s *Server) handleDryRun(ctx context.Context, identifier string, opts RunOptions) *protocol.Response {
+	inv, warnings, err := s.daemon.Plan(ctx, identifier, opts)
+	if err != nil {
+		code, msg := classifyLoadError(err)
+		return protocol.NewErrorResponseWithCode(code, msg)
+	}
+	return protocol.NewOKResponse(protocol.LoadData{
+		Command:   append([]string{inv.Command}, inv.Args...),
+		ConfigINI: inv.ConfigINI,
+		Warnings:  warnings,
 	})
 }
 
@@ -224,11 +549,18 @@ func classifyLoadError(err error) (code, message string) {
 	return "", msg
 }
 
-func (s *Server) handleUnload(ctx context.Context) *protocol.Response {
-	if err := s.daemon.Kill(ctx); err != nil {
+func (s *Server) handleUnload(ctx context.Context, req *protocol.Request) *protocol.Response {
+	force, _ := req.Args["force"].(bool)
+	graceful, err := s.daemon.KillWithOptions(ctx, KillOptions{Force: force})
+	if err != nil {
 		return protocol.NewErrorResponse(err.Error())
 	}
-	return protocol.NewOKResponse(nil)
+	s.daemon.clearLastLoad()
+	return protocol.NewOKResponse(protocol.UnloadData{Graceful: graceful})
+}
+
+func (s *Server) handleCancel() *protocol.Response {
+	return protocol.NewOKResponse(protocol.CancelData{Canceled: s.daemon.CancelLoad()})
 }
 
 func (s *Server) handleListPresets() *protocol.Response {
@@ -236,11 +568,9 @@ func (s *Server) handleListPresets() *protocol.Response {
 	if err != nil && len(presets) == 0 {
 		return protocol.NewErrorResponse(err.Error())
 	}
-	data := map[string]any{
-		"presets": presets,
-	}
+	data := protocol.ListPresetsData{Presets: presets}
 	if err != nil {
-		data["warning"] = err.Error()
+		data.Warning = err.Error()
 	}
 	return protocol.NewOKResponse(data)
 }
@@ -251,12 +581,47 @@ func (s *Server) handleListModels(ctx context.Context) *protocol.Response {
 		return protocol.NewErrorResponse(err.Error())
 	}
 
-	return protocol.NewOKResponse(map[string]any{
-		"models": models,
-	})
+	data := protocol.ListModelsData{Models: []protocol.ModelData{}}
+	for _, m := range models {
+		data.Models = append(data.Models, protocol.ModelData{Repo: m.Repo, Quant: m.Quant, Size: m.Size})
+	}
+	return protocol.NewOKResponse(data)
+}
+
+func (s *Server) handleComplete(ctx context.Context) *protocol.Response {
+	c, err := s.daemon.Completions(ctx)
+	if err != nil && len(c.Presets) == 0 && len(c.Models) == 0 {
+		return protocol.NewErrorResponse(err.Error())
+	}
+	data := protocol.CompleteData{
+		Presets:      c.Presets,
+		Models:       c.Models,
+		Aliases:      c.Aliases,
+		RouterModels: c.RouterModels,
+	}
+	if err != nil {
+		data.Warning = err.Error()
+	}
+	return protocol.NewOKResponse(data)
+}
+
+func (s *Server) handleStats() *protocol.Response {
+	data := protocol.StatsData{Models: []protocol.ModelStatsData{}}
+	for _, m := range s.daemon.UsageStats() {
+		data.Models = append(data.Models, protocol.ModelStatsData{
+			Model:            m.Model,
+			Requests:         m.Requests,
+			Errors:           m.Errors,
+			PromptTokens:     m.PromptTokens,
+			CompletionTokens: m.CompletionTokens,
+			AvgLatencyMS:     m.AvgLatency().Milliseconds(),
+		})
+	}
+	return protocol.NewOKResponse(data)
 }
 
 func (s *Server) writeResponse(conn net.Conn, resp *protocol.Response) {
+	resp.Version = protocol.Version
 	data, err := json.Marshal(resp)
 	if err != nil {
 		s.logger.Error("marshal response failed", "error", err)
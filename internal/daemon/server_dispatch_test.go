@@ -148,6 +148,25 @@ func TestHandleRequest_Unload(t *testing.T) {
 	}
 }
 
+func TestHandleRequest_CancelWhenIdle(t *testing.T) {
+	// Arrange
+	daemon := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	req := &protocol.Request{Command: protocol.CmdCancel}
+
+	// Act
+	resp := server.handleRequest(context.Background(), req)
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+	}
+	if data := decodeData[protocol.CancelData](t, resp); data.Canceled {
+		t.Errorf("canceled = %v, want false", data.Canceled)
+	}
+}
+
 func TestHandleRequest_ListPresets(t *testing.T) {
 	// Arrange
 	presets := &stubPresetLoader{
@@ -205,4 +224,7 @@ func TestHandleRequest_UnknownCommand(t *testing.T) {
 	if resp.Error != "unknown command" {
 		t.Errorf("Error = %q, want %q", resp.Error, "unknown command")
 	}
+	if resp.ErrorCode != protocol.ErrCodeUnknownCommand {
+		t.Errorf("ErrorCode = %q, want %q", resp.ErrorCode, protocol.ErrCodeUnknownCommand)
+	}
 }
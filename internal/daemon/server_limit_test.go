@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// newLimitedServer returns a server allowing one request at a time, which
+// is taken, so limited requests wait out a short queue.
+func newLimitedServer(t *testing.T) *Server {
+	t.Helper()
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+	server.SetMaxRequests(1)
+	server.queueWait = 50 * time.Millisecond
+	server.slots <- struct{}{}
+	return server
+}
+
+func TestServeRequest_Limit(t *testing.T) {
+	tests := []struct {
+		command  string
+		wantCode string // empty for success
+	}{
+		{protocol.CmdStatus, protocol.ErrCodeBusy},
+		{protocol.CmdListPresets, protocol.ErrCodeBusy},
+		{protocol.CmdCancel, ""},
+		{protocol.CmdUnload, ""},
+		{protocol.CmdHello, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			// Arrange
+			server := newLimitedServer(t)
+
+			// Act
+			resp := server.serveRequest(context.Background(), protocol.NewRequest(tt.command, nil))
+
+			// Assert
+			if resp.ErrorCode != tt.wantCode {
+				t.Errorf("ErrorCode = %q (%s), want %q", resp.ErrorCode, resp.Error, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestServeRequest_WaitsForSlot(t *testing.T) {
+	// Arrange
+	server := newLimitedServer(t)
+	server.queueWait = 5 * time.Second
+	time.AfterFunc(20*time.Millisecond, func() { <-server.slots })
+
+	// Act
+	resp := server.serveRequest(context.Background(), protocol.NewRequest(protocol.CmdStatus, nil))
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q (%s), want ok once a slot frees up", resp.Status, resp.Error)
+	}
+	if len(server.slots) != 0 {
+		t.Errorf("in-flight after request = %d, want 0 after the request released its slot", len(server.slots))
+	}
+}
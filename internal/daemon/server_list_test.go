@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/d2verb/alpaca/internal/metadata"
+	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
 )
 
@@ -27,11 +30,7 @@ func TestHandleListPresets_Success(t *testing.T) {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
 
-	presetList, ok := resp.Data["presets"].([]string)
-	if !ok {
-		t.Fatal("presets data should be []string")
-	}
-
+	presetList := decodeData[protocol.ListPresetsData](t, resp).Presets
 	if len(presetList) != 3 {
 		t.Fatalf("len(presets) = %d, want 3", len(presetList))
 	}
@@ -81,11 +80,7 @@ func TestHandleListModels_Success(t *testing.T) {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
 
-	modelList, ok := resp.Data["models"].([]ModelInfo)
-	if !ok {
-		t.Fatal("models data should be []ModelInfo")
-	}
-
+	modelList := decodeData[protocol.ListModelsData](t, resp).Models
 	if len(modelList) != 2 {
 		t.Fatalf("len(models) = %d, want 2", len(modelList))
 	}
@@ -120,3 +115,48 @@ func TestHandleListModels_Error(t *testing.T) {
 		t.Errorf("Error = %q, want %q", resp.Error, "failed to read metadata")
 	}
 }
+
+func TestHandleComplete(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		names: []string{"fleet", "coder", "broken"},
+		presets: map[string]*preset.Preset{
+			"coder": {Name: "coder", Model: "f:/m.gguf", Options: preset.Options{"alias": "gpt-coder"}},
+			"fleet": {Name: "fleet", Mode: "router", Models: []preset.ModelEntry{
+				{Name: "qwen", Model: "f:/q.gguf"},
+				{Name: "embed", Model: "f:/e.gguf", Options: preset.Options{"alias": "text-embedding"}},
+			}},
+		},
+	}
+	models := &stubModelManager{
+		entries: []metadata.ModelEntry{
+			{Repo: "org/repo", Quant: "Q4_K_M"},
+			{Repo: "org/ms-repo", Quant: "Q8_0", Registry: "ms"},
+		},
+	}
+	server := NewServer(newTestDaemon(presets, models), "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handleComplete(context.Background())
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q (%s), want ok", resp.Status, resp.Error)
+	}
+	data := decodeData[protocol.CompleteData](t, resp)
+	if want := []string{"coder", "fleet"}; !slices.Equal(data.Presets, want) {
+		t.Errorf("Presets = %v, want %v", data.Presets, want)
+	}
+	if want := []string{"h:org/repo:Q4_K_M", "ms:org/ms-repo:Q8_0"}; !slices.Equal(data.Models, want) {
+		t.Errorf("Models = %v, want %v", data.Models, want)
+	}
+	if want := []string{"gpt-coder", "text-embedding"}; !slices.Equal(data.Aliases, want) {
+		t.Errorf("Aliases = %v, want %v", data.Aliases, want)
+	}
+	if want := []string{"qwen", "embed"}; !slices.Equal(data.RouterModels["fleet"], want) || len(data.RouterModels) != 1 {
+		t.Errorf("RouterModels = %v, want fleet: %v", data.RouterModels, want)
+	}
+	if !strings.Contains(data.Warning, "preset broken") {
+		t.Errorf("Warning = %q, want the unreadable preset", data.Warning)
+	}
+}
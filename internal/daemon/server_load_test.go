@@ -2,10 +2,22 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/llama"
 	"github.com/d2verb/alpaca/internal/metadata"
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
@@ -50,8 +62,8 @@ func TestHandleLoad_Success(t *testing.T) {
 	if resp.Status != protocol.StatusOK {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
-	if resp.Data["endpoint"] != "http://127.0.0.1:8080" {
-		t.Errorf("endpoint = %v, want %q", resp.Data["endpoint"], "http://127.0.0.1:8080")
+	if data := decodeData[protocol.LoadData](t, resp); data.Endpoint != "http://127.0.0.1:8080" {
+		t.Errorf("endpoint = %v, want %q", data.Endpoint, "http://127.0.0.1:8080")
 	}
 }
 
@@ -180,3 +192,348 @@ func TestHandleLoad_ServerStartFailed(t *testing.T) {
 		t.Errorf("ErrorCode = %q, want %q", resp.ErrorCode, protocol.ErrCodeServerFailed)
 	}
 }
+
+func TestHandleLoad_ServerExitedIncludesDiagnostics(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+		},
+	}
+	daemon := newTestDaemon(presets, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	done := make(chan struct{})
+	close(done)
+	daemon.newProcess = func(path string) llamaProcess {
+		return &mockProcess{
+			doneCh:    done,
+			exitError: exitErr,
+			logTail:   []string{"main: loading model", "error: couldn't bind HTTP server socket"},
+		}
+	}
+	daemon.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	var checked string
+	daemon.portInUse = func(addr string) bool {
+		checked = addr
+		return true
+	}
+	daemon.freeMemory = func() int64 { return 8 << 30 }
+
+	req := &protocol.Request{
+		Command: protocol.CmdLoad,
+		Args:    map[string]any{"identifier": "p:test-preset"},
+	}
+
+	// Act
+	resp := server.handleLoad(context.Background(), req)
+
+	// Assert
+	if resp.Status != protocol.StatusError || resp.ErrorCode != protocol.ErrCodeServerFailed {
+		t.Fatalf("response = %+v, want a server_failed error", resp)
+	}
+	var data protocol.LoadFailureData
+	if err := resp.Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+	if data.ExitCode == nil || *data.ExitCode != 3 {
+		t.Errorf("exit code = %v, want 3", data.ExitCode)
+	}
+	if len(data.LogTail) != 2 || !slices.Contains(data.Command, "/path/to/model.gguf") {
+		t.Errorf("log tail = %q, command = %q", data.LogTail, data.Command)
+	}
+	if checked != "127.0.0.1:8080" || data.Addr != checked || !data.PortInUse || data.FreeMemory != 8<<30 {
+		t.Errorf("addr = %q (checked %q), port in use = %v, free memory = %d", data.Addr, checked, data.PortInUse, data.FreeMemory)
+	}
+}
+
+func TestHandleLoad_DraftModelOverride(t *testing.T) {
+	// Arrange
+	models := &mapModelManager{
+		paths: map[string]string{
+			"org/model-8B-GGUF:Q4_K_M":   "/models/model-8b.gguf",
+			"org/model-0.6B-GGUF:Q4_K_M": "/models/model-0.6b.gguf",
+		},
+	}
+	daemon := newTestDaemon(&stubPresetLoader{}, models)
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	mockProc := &mockProcess{}
+	daemon.newProcess = func(path string) llamaProcess {
+		return mockProc
+	}
+	daemon.waitForReady = mockHealthChecker(nil)
+
+	req := &protocol.Request{
+		Command: protocol.CmdLoad,
+		Args: map[string]any{
+			"identifier":  "h:org/model-8B-GGUF:Q4_K_M",
+			"draft_model": "h:org/model-0.6B-GGUF:Q4_K_M",
+		},
+	}
+
+	// Act
+	resp := server.handleLoad(context.Background(), req)
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q, want %q (error: %s)", resp.Status, protocol.StatusOK, resp.Error)
+	}
+	if !slices.Contains(mockProc.receivedArgs, "/models/model-0.6b.gguf") {
+		t.Errorf("args = %v, want --model-draft /models/model-0.6b.gguf", mockProc.receivedArgs)
+	}
+}
+
+func TestHandleLoad_DraftModelOverrideRouterRejected(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"router": {
+				Name:   "router",
+				Mode:   "router",
+				Models: []preset.ModelEntry{{Name: "a", Model: "f:/path/a.gguf"}},
+			},
+		},
+	}
+	daemon := newTestDaemon(presets, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	req := &protocol.Request{
+		Command: protocol.CmdLoad,
+		Args: map[string]any{
+			"identifier":  "p:router",
+			"draft_model": "f:/path/draft.gguf",
+		},
+	}
+
+	// Act
+	resp := server.handleLoad(context.Background(), req)
+
+	// Assert
+	if resp.Status != protocol.StatusError {
+		t.Fatalf("Status = %q, want %q", resp.Status, protocol.StatusError)
+	}
+	if !strings.Contains(resp.Error, "not supported for router presets") {
+		t.Errorf("Error = %q, want router rejection", resp.Error)
+	}
+}
+
+func TestHandleLoad_RouterModels(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data": [{"id": "qwen3", "status": {"value": "loading"}}]}`)
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(u.Port())
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"router": {
+				Name: "router",
+				Mode: "router",
+				Host: u.Hostname(),
+				Port: port,
+				Models: []preset.ModelEntry{
+					{Name: "qwen3", Model: "f:/path/qwen3.gguf"},
+					{Name: "gemma3", Model: "f:/path/gemma3.gguf"},
+				},
+			},
+		},
+	}
+	daemon := newTestDaemonWithConfigPath(presets, &stubModelManager{}, filepath.Join(t.TempDir(), "config.ini"))
+	daemon.httpClient = srv.Client()
+	daemon.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	daemon.waitForReady = mockHealthChecker(nil)
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	req := &protocol.Request{
+		Command: protocol.CmdLoad,
+		Args:    map[string]any{"identifier": "p:router"},
+	}
+
+	// Act
+	resp := server.handleLoad(context.Background(), req)
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q, want %q: %s", resp.Status, protocol.StatusOK, resp.Error)
+	}
+	data := decodeData[protocol.LoadData](t, resp)
+	if want := []protocol.RouterModelData{{ID: "qwen3", Status: "loading"}}; !slices.Equal(data.Models, want) {
+		t.Errorf("models = %+v, want %+v", data.Models, want)
+	}
+	if want := []string{"gemma3"}; !slices.Equal(data.MissingModels, want) {
+		t.Errorf("missing_models = %v, want %v", data.MissingModels, want)
+	}
+}
+
+func TestHandleLoad_LlamaServerSelection(t *testing.T) {
+	tests := []struct {
+		name         string
+		presetServer string
+		daemonServer string
+		requestArg   string
+		want         string
+	}{
+		{"defaults to PATH lookup", "", "", "", "llama-server"},
+		{"config default", "", "/opt/default/llama-server", "", "/opt/default/llama-server"},
+		{"preset overrides config", "/opt/cuda/llama-server", "/opt/default/llama-server", "", "/opt/cuda/llama-server"},
+		{"request overrides preset", "/opt/cuda/llama-server", "/opt/default/llama-server", "/opt/vulkan/llama-server", "/opt/vulkan/llama-server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			presets := &stubPresetLoader{
+				presets: map[string]*preset.Preset{
+					"test": {Name: "test", Model: "f:/path/model.gguf", LlamaServerPath: tt.presetServer},
+				},
+			}
+			daemon := newTestDaemon(presets, &stubModelManager{})
+			daemon.SetLlamaServerPath(tt.daemonServer)
+			var gotPath string
+			daemon.newProcess = func(path string) llamaProcess {
+				gotPath = path
+				return &mockProcess{}
+			}
+			daemon.waitForReady = mockHealthChecker(nil)
+			server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+			args := map[string]any{"identifier": "p:test"}
+			if tt.requestArg != "" {
+				args["llama_server"] = tt.requestArg
+			}
+
+			// Act
+			resp := server.handleLoad(context.Background(), &protocol.Request{Command: protocol.CmdLoad, Args: args})
+
+			// Assert
+			if resp.Status != protocol.StatusOK {
+				t.Fatalf("Status = %q, want %q (error: %s)", resp.Status, protocol.StatusOK, resp.Error)
+			}
+			if gotPath != tt.want {
+				t.Errorf("llama-server path = %q, want %q", gotPath, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_LoadCanceledByClient(t *testing.T) {
+	// Arrange
+	server, socketPath := startSocketServer(t, nil, nil)
+	d := server.daemon
+	d.presets = &stubPresetLoader{presets: map[string]*preset.Preset{
+		"slow": {Name: "slow", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+	}}
+	d.newProcess = func(path string) llamaProcess { return &mockProcess{} }
+	waiting := make(chan struct{})
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error {
+		close(waiting)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-waiting
+		cancel()
+	}()
+
+	// Act
+	_, err := client.New(socketPath).Load(ctx, "p:slow", client.LoadOptions{})
+
+	// Assert
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Load() error = %v, want context.Canceled", err)
+	}
+	if d.State() != StateIdle {
+		t.Errorf("State() = %q, want %q after cancel", d.State(), StateIdle)
+	}
+}
+
+func TestHandleLoad_DryRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		wantArgs   []string
+		wantINI    string
+	}{
+		{
+			name:       "single",
+			identifier: "p:single",
+			wantArgs:   []string{"llama-server", "-m", "/path/to/model.gguf"},
+		},
+		{
+			name:       "router",
+			identifier: "p:router",
+			wantArgs:   []string{"llama-server", "--models-preset"},
+			wantINI:    "[a]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			presets := &stubPresetLoader{
+				presets: map[string]*preset.Preset{
+					"single": {Name: "single", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+					"router": {
+						Name:   "router",
+						Mode:   "router",
+						Models: []preset.ModelEntry{{Name: "a", Model: "f:/path/a.gguf"}},
+					},
+				},
+			}
+			configPath := filepath.Join(t.TempDir(), "config.ini")
+			daemon := newTestDaemonWithConfigPath(presets, &stubModelManager{}, configPath)
+			server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+			started := false
+			daemon.newProcess = func(path string) llamaProcess {
+				started = true
+				return &mockProcess{}
+			}
+
+			req := &protocol.Request{
+				Command: protocol.CmdLoad,
+				Args:    map[string]any{"identifier": tt.identifier, "dry_run": true},
+			}
+
+			// Act
+			resp := server.handleLoad(context.Background(), req)
+
+			// Assert
+			if resp.Status != protocol.StatusOK {
+				t.Fatalf("Status = %q (%s), want %q", resp.Status, resp.Error, protocol.StatusOK)
+			}
+			data := decodeData[protocol.LoadData](t, resp)
+			command, ini := data.Command, data.ConfigINI
+			if len(command) < len(tt.wantArgs) || !slices.Equal(command[:len(tt.wantArgs)], tt.wantArgs) {
+				t.Errorf("command = %v, want prefix %v", command, tt.wantArgs)
+			}
+			if !strings.Contains(ini, tt.wantINI) || (tt.wantINI == "") != (ini == "") {
+				t.Errorf("config_ini = %q, want it to contain %q", ini, tt.wantINI)
+			}
+			if tt.wantINI != "" {
+				want := strings.TrimSuffix(configPath, ".ini") + "-XXXXXXXX.ini"
+				if got := routerConfigArg(t, command); got != want {
+					t.Errorf("--models-preset = %s, want the run-scoped placeholder %s", got, want)
+				}
+			}
+			if started {
+				t.Error("dry run started llama-server")
+			}
+			if daemon.State() != StateIdle {
+				t.Errorf("State() = %v, want %v", daemon.State(), StateIdle)
+			}
+			if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+				t.Errorf("dry run wrote %s", configPath)
+			}
+		})
+	}
+}
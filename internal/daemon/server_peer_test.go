@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// startSocketServer starts a server on a Unix socket in a fresh directory.
+// peerUID, if non-nil, replaces the peer credential lookup.
+func startSocketServer(t *testing.T, allowed []int, peerUID func(net.Conn) (int, error)) (*Server, string) {
+	t.Helper()
+
+	// Use /tmp directly to keep the socket path short (macOS limit)
+	dir, err := os.MkdirTemp("/tmp", "alpaca-peer-")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socketPath := filepath.Join(dir, "sub", "alpaca.sock")
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, socketPath, io.Discard)
+	server.SetAllowedUIDs(allowed)
+	if peerUID != nil {
+		server.peerUID = peerUID
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := server.Start(ctx); err != nil {
+		cancel()
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		server.Stop()
+	})
+	return server, socketPath
+}
+
+func fixedPeer(uid int) func(net.Conn) (int, error) {
+	return func(net.Conn) (int, error) { return uid, nil }
+}
+
+func TestServer_PeerCheck(t *testing.T) {
+	other := os.Getuid() + 1000
+	tests := []struct {
+		name    string
+		allowed []int
+		peer    func(net.Conn) (int, error)
+		wantErr error
+	}{
+		{"same user", nil, nil, nil},
+		{"root", nil, fixedPeer(0), nil},
+		{"other user", nil, fixedPeer(other), client.ErrPeerRejected},
+		{"allowed other user", []int{other}, fixedPeer(other), nil},
+		{"peer lookup failure", nil, func(net.Conn) (int, error) { return 0, errors.New("boom") }, client.ErrPeerRejected},
+		{"unsupported platform", nil, func(net.Conn) (int, error) { return 0, errUnsupportedPeerCred }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			_, socketPath := startSocketServer(t, tt.allowed, tt.peer)
+			cl := client.New(socketPath)
+
+			// Act
+			resp, err := cl.Status(context.Background())
+
+			// Assert
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Status() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			if resp.Status != protocol.StatusOK {
+				t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+			}
+		})
+	}
+}
+
+func TestServer_SocketMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []int
+		wantDir os.FileMode
+		want    os.FileMode
+	}{
+		{"owner only", nil, 0700, 0600},
+		{"shared with allowed users", []int{os.Getuid() + 1000}, 0711, 0666},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.allowed != nil && !peerCredSupported {
+				t.Skip("peer credentials not supported on this platform")
+			}
+
+			// Arrange & Act
+			_, socketPath := startSocketServer(t, tt.allowed, nil)
+
+			// Assert
+			fi, err := os.Stat(socketPath)
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if got := fi.Mode().Perm(); got != tt.want {
+				t.Errorf("socket mode = %o, want %o", got, tt.want)
+			}
+			di, err := os.Stat(filepath.Dir(socketPath))
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if got := di.Mode().Perm(); got != tt.wantDir {
+				t.Errorf("socket dir mode = %o, want %o", got, tt.wantDir)
+			}
+			entries, err := os.ReadDir(filepath.Dir(socketPath))
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("socket dir holds %d entries, want only the socket", len(entries))
+			}
+		})
+	}
+}
+
+func TestServer_StopRemovesSocket(t *testing.T) {
+	// Arrange
+	server, socketPath := startSocketServer(t, nil, nil)
+
+	// Act
+	err := server.Stop()
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket still exists after Stop(): %v", err)
+	}
+}
+
+func TestLookupUIDs(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skipf("current user unavailable: %v", err)
+	}
+	currentUID, _ := strconv.Atoi(current.Uid)
+
+	t.Run("names and numeric IDs", func(t *testing.T) {
+		// Act
+		uids, err := LookupUIDs([]string{"4242", current.Username})
+
+		// Assert
+		if err != nil {
+			t.Fatalf("LookupUIDs() error = %v", err)
+		}
+		if len(uids) != 2 || uids[0] != 4242 || uids[1] != currentUID {
+			t.Errorf("LookupUIDs() = %v, want [4242 %d]", uids, currentUID)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		// Act
+		_, err := LookupUIDs([]string{"no-such-alpaca-user"})
+
+		// Assert
+		if err == nil {
+			t.Error("LookupUIDs() error = nil, want error")
+		}
+	})
+}
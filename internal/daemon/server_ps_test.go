@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/preset"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+func TestHandlePs(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+		},
+	}
+	daemon := newTestDaemon(presets, &stubModelManager{})
+	daemon.newProcess = func(path string) llamaProcess {
+		return &mockProcess{pid: 4242}
+	}
+	daemon.waitForReady = mockHealthChecker(nil)
+	if err := daemon.Run(context.Background(), "p:test-preset"); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+	started := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	var gotArgs []string
+	server.inspect = func(pid int, args []string) llama.ProcessInfo {
+		gotArgs = args
+		return llama.ProcessInfo{PID: pid, StartedAt: started, RSS: 4 << 30, Port: 8080, Model: "/path/to/model.gguf"}
+	}
+
+	// Act
+	resp := server.handlePs()
+
+	// Assert
+	procs := decodeData[protocol.PsData](t, resp).Processes
+	if len(procs) != 1 {
+		t.Fatalf("processes = %+v, want one", procs)
+	}
+	p := procs[0]
+	if p.PID != 4242 || p.Preset != "test-preset" || p.State != string(StateRunning) || p.Port != 8080 || p.Model != "/path/to/model.gguf" || p.Mode != "" {
+		t.Errorf("process = %+v", p)
+	}
+	if !p.StartedAt.Equal(started) || p.RSS == nil || *p.RSS != 4<<30 {
+		t.Errorf("started_at = %v, rss = %v, want %v and 4 GiB", p.StartedAt, p.RSS, started)
+	}
+	if !slices.Contains(gotArgs, "/path/to/model.gguf") {
+		t.Errorf("inspected args = %v, want llama-server's", gotArgs)
+	}
+}
+
+func TestHandlePs_Idle(t *testing.T) {
+	// Arrange
+	daemon := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handlePs()
+
+	// Assert
+	procs := decodeData[protocol.PsData](t, resp).Processes
+	if procs == nil || len(procs) != 0 {
+		t.Errorf("processes = %#v, want empty list", procs)
+	}
+}
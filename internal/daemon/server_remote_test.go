@@ -0,0 +1,282 @@
+package daemon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// startRemoteServer starts a server with only a TCP listener on a random port.
+func startRemoteServer(t *testing.T, token string) string {
+	t.Helper()
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := server.ListenTCP(ctx, "127.0.0.1:0", token, nil); err != nil {
+		cancel()
+		t.Fatalf("ListenTCP() error = %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		server.Stop()
+	})
+	return server.tcpListener.Addr().String()
+}
+
+func TestListenTCP_ValidToken(t *testing.T) {
+	// Arrange
+	addr := startRemoteServer(t, "secret")
+	cl := client.NewRemote(addr, "secret")
+
+	// Act
+	resp, err := cl.Status(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if resp.Status != protocol.StatusOK {
+		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+	}
+}
+
+func TestListenTCP_InvalidToken(t *testing.T) {
+	// Arrange
+	addr := startRemoteServer(t, "secret")
+	cl := client.NewRemote(addr, "wrong")
+
+	// Act
+	_, err := cl.Status(context.Background())
+
+	// Assert
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("Status() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestListenTCP_RequiresToken(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+
+	// Act
+	err := server.ListenTCP(context.Background(), "127.0.0.1:0", "", nil)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
+
+func TestSetToken(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := server.ListenTCP(ctx, "127.0.0.1:0", "old", nil); err != nil {
+		t.Fatalf("ListenTCP() error = %v", err)
+	}
+	defer server.Stop()
+	addr := server.tcpListener.Addr().String()
+
+	// Act
+	err := server.SetToken("new")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SetToken() error = %v", err)
+	}
+	if _, err := client.NewRemote(addr, "old").Status(ctx); !errors.Is(err, client.ErrUnauthorized) {
+		t.Errorf("old token: Status() error = %v, want ErrUnauthorized", err)
+	}
+	if _, err := client.NewRemote(addr, "new").Status(ctx); err != nil {
+		t.Errorf("new token: Status() error = %v", err)
+	}
+	if err := server.SetToken(""); err == nil {
+		t.Error("SetToken(\"\") succeeded, want an error")
+	}
+}
+
+func TestListenTCP_RejectsLlamaServer(t *testing.T) {
+	// Arrange
+	addr := startRemoteServer(t, "secret")
+	cl := client.NewRemote(addr, "secret")
+
+	// Act
+	resp, err := cl.Load(context.Background(), "p:any", client.LoadOptions{LlamaServer: "/tmp/evil"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if resp.Status != protocol.StatusError || !strings.Contains(resp.Error, "remote clients") {
+		t.Errorf("Load() = %s %q, want a refusal of llama_server", resp.Status, resp.Error)
+	}
+}
+
+func TestListenTCP_NonLoopbackRequiresTLS(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+
+	// Act
+	err := server.ListenTCP(context.Background(), "0.0.0.0:0", "secret", nil)
+
+	// Assert
+	if err == nil {
+		server.Stop()
+		t.Fatal("ListenTCP() succeeded on 0.0.0.0 without TLS, want an error")
+	}
+	if !strings.Contains(err.Error(), "requires TLS") {
+		t.Errorf("error = %v, want it to mention TLS", err)
+	}
+}
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 and its key
+// to dir as name.pem and name-key.pem. The certificate is its own CA.
+func writeTestCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// startTLSServer starts a server whose TCP listener uses the TLS
+// configuration of ServerTLSConfig(certFile, keyFile, clientCAFile).
+func startTLSServer(t *testing.T, certFile, keyFile, clientCAFile string) string {
+	t.Helper()
+
+	tlsConfig, err := ServerTLSConfig(certFile, keyFile, clientCAFile)
+	if err != nil {
+		t.Fatalf("ServerTLSConfig() error = %v", err)
+	}
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(d, "/tmp/unused.sock", io.Discard)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := server.ListenTCP(ctx, "127.0.0.1:0", "secret", tlsConfig); err != nil {
+		cancel()
+		t.Fatalf("ListenTCP() error = %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		server.Stop()
+	})
+	return server.tcpListener.Addr().String()
+}
+
+func TestListenTCP_TLS(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server")
+	addr := startTLSServer(t, certFile, keyFile, "")
+	tlsConfig, err := client.TLSConfig(certFile, "", "")
+	if err != nil {
+		t.Fatalf("TLSConfig() error = %v", err)
+	}
+	cl := client.NewRemote(addr, "secret")
+	cl.SetTLSConfig(tlsConfig)
+	plain := client.NewRemote(addr, "secret")
+	plain.SetTimeouts(client.Timeouts{Request: time.Second})
+
+	// Act
+	resp, err := cl.Status(context.Background())
+	_, plainErr := plain.Status(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if resp.Status != protocol.StatusOK {
+		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+	}
+	if plainErr == nil {
+		t.Error("plaintext client succeeded against the TLS listener")
+	}
+}
+
+func TestListenTCP_ClientCertificate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server")
+	clientCert, clientKey := writeTestCert(t, dir, "client")
+	addr := startTLSServer(t, certFile, keyFile, clientCert)
+
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+		wantErr  bool
+	}{
+		{name: "with client certificate", certFile: clientCert, keyFile: clientKey},
+		{name: "without client certificate", wantErr: true},
+		{name: "with an unknown certificate", certFile: certFile, keyFile: keyFile, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := client.TLSConfig(certFile, tt.certFile, tt.keyFile)
+			if err != nil {
+				t.Fatalf("TLSConfig() error = %v", err)
+			}
+			cl := client.NewRemote(addr, "secret")
+			cl.SetTLSConfig(tlsConfig)
+
+			// Act
+			_, err = cl.Status(context.Background())
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Status() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
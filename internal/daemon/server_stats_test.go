@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+func TestHandleStats(t *testing.T) {
+	// Arrange
+	daemon := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	daemon.stats.record("chat", requestUsage{promptTokens: 10, completionTokens: 20, latency: 1500 * time.Millisecond})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handleStats()
+
+	// Assert
+	if resp.Status != protocol.StatusOK {
+		t.Fatalf("Status = %q, want %q", resp.Status, protocol.StatusOK)
+	}
+	models := decodeData[protocol.StatsData](t, resp).Models
+	if len(models) != 1 {
+		t.Fatalf("models = %+v, want one entry", models)
+	}
+	want := protocol.ModelStatsData{Model: "chat", Requests: 1, PromptTokens: 10, CompletionTokens: 20, AvgLatencyMS: 1500}
+	if models[0] != want {
+		t.Errorf("model stats = %+v, want %+v", models[0], want)
+	}
+}
+
+func TestHandleStats_Empty(t *testing.T) {
+	// Arrange
+	daemon := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handleStats()
+
+	// Assert
+	models := decodeData[protocol.StatsData](t, resp).Models
+	if models == nil || len(models) != 0 {
+		t.Errorf("models = %#v, want empty list", models)
+	}
+}
@@ -2,12 +2,20 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
+	"github.com/d2verb/alpaca/internal/sysinfo"
 )
 
 func TestHandleStatus_Idle(t *testing.T) {
@@ -24,13 +32,14 @@ func TestHandleStatus_Idle(t *testing.T) {
 	if resp.Status != protocol.StatusOK {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
-	if resp.Data["state"] != string(StateIdle) {
-		t.Errorf("state = %v, want %q", resp.Data["state"], StateIdle)
+	data := decodeData[protocol.StatusData](t, resp)
+	if data.State != string(StateIdle) {
+		t.Errorf("state = %v, want %q", data.State, StateIdle)
 	}
-	if _, exists := resp.Data["preset"]; exists {
+	if data.Preset != "" {
 		t.Error("preset should not exist when idle")
 	}
-	if _, exists := resp.Data["endpoint"]; exists {
+	if data.Endpoint != "" {
 		t.Error("endpoint should not exist when idle")
 	}
 }
@@ -73,14 +82,66 @@ func TestHandleStatus_Running(t *testing.T) {
 	if resp.Status != protocol.StatusOK {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
-	if resp.Data["state"] != string(StateRunning) {
-		t.Errorf("state = %v, want %q", resp.Data["state"], StateRunning)
+	data := decodeData[protocol.StatusData](t, resp)
+	if data.State != string(StateRunning) {
+		t.Errorf("state = %v, want %q", data.State, StateRunning)
 	}
-	if resp.Data["preset"] != "test-preset" {
-		t.Errorf("preset = %v, want %q", resp.Data["preset"], "test-preset")
+	if data.Preset != "test-preset" {
+		t.Errorf("preset = %v, want %q", data.Preset, "test-preset")
 	}
-	if resp.Data["endpoint"] != "http://127.0.0.1:8080" {
-		t.Errorf("endpoint = %v, want %q", resp.Data["endpoint"], "http://127.0.0.1:8080")
+	if data.Endpoint != "http://127.0.0.1:8080" {
+		t.Errorf("endpoint = %v, want %q", data.Endpoint, "http://127.0.0.1:8080")
+	}
+	if time.Since(data.Since) > time.Minute {
+		t.Errorf("since = %v, want a recent time", data.Since)
+	}
+}
+
+func TestHandleStatus_ProcessInfo(t *testing.T) {
+	// Arrange
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf", Host: "127.0.0.1", Port: 8080},
+		},
+	}
+	daemon := newTestDaemon(presets, &stubModelManager{})
+	daemon.newProcess = func(path string) llamaProcess {
+		return &mockProcess{pid: 4242}
+	}
+	daemon.waitForReady = mockHealthChecker(nil)
+	if err := daemon.Run(context.Background(), "p:test-preset"); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+	server.SetVersion("v1.2.3")
+	server.processUsage = func(pid int) (sysinfo.ProcessUsage, error) {
+		if pid == 4242 {
+			return sysinfo.ProcessUsage{RSS: 4 << 30, CPU: 90 * time.Second}, nil
+		}
+		return sysinfo.ProcessUsage{}, errors.New("no such process")
+	}
+
+	// Act
+	resp := server.handleStatus(context.Background())
+
+	// Assert
+	data := decodeData[protocol.StatusData](t, resp)
+	d := data.Daemon
+	if d == nil {
+		t.Fatal("daemon = nil, want process info")
+	}
+	if d.PID != os.Getpid() || d.Version != "v1.2.3" {
+		t.Errorf("daemon = %+v, want pid %d and version v1.2.3", d, os.Getpid())
+	}
+	if d.RSS != nil {
+		t.Errorf("daemon rss = %v, want none when usage cannot be read", *d.RSS)
+	}
+	if time.Since(d.StartedAt) > time.Minute {
+		t.Errorf("started_at = %v, want a recent time", d.StartedAt)
+	}
+	got := data.LlamaServer
+	if got == nil || got.PID != 4242 || got.RSS == nil || *got.RSS != 4<<30 || got.CPUSeconds == nil || *got.CPUSeconds != 90 {
+		t.Errorf("llama_server = %+v, want pid 4242, 4 GiB and 90 seconds", got)
 	}
 }
 
@@ -128,19 +189,41 @@ func TestHandleStatus_RouterMode(t *testing.T) {
 	if resp.Status != protocol.StatusOK {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
-	if resp.Data["state"] != string(StateRunning) {
-		t.Errorf("state = %v, want %q", resp.Data["state"], StateRunning)
+	data := decodeData[protocol.StatusData](t, resp)
+	if data.State != string(StateRunning) {
+		t.Errorf("state = %v, want %q", data.State, StateRunning)
 	}
-	if resp.Data["preset"] != "multi-model" {
-		t.Errorf("preset = %v, want %q", resp.Data["preset"], "multi-model")
+	if data.Preset != "multi-model" {
+		t.Errorf("preset = %v, want %q", data.Preset, "multi-model")
 	}
-	if resp.Data["mode"] != "router" {
-		t.Errorf("mode = %v, want %q", resp.Data["mode"], "router")
+	if data.Mode != "router" {
+		t.Errorf("mode = %v, want %q", data.Mode, "router")
 	}
 
 	// No mode field for non-router presets is verified in TestHandleStatus_SingleModeNoModeField
 }
 
+func TestHandleStatus_RouterModelsError(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	server := NewServer(routerDaemon(t, srv), "/tmp/test.sock", io.Discard)
+
+	// Act
+	resp := server.handleStatus(context.Background())
+
+	// Assert
+	data := decodeData[protocol.StatusData](t, resp)
+	if data.Models != nil {
+		t.Error("models should not exist when llama-server could not be queried")
+	}
+	if !strings.Contains(data.ModelsError, "503") {
+		t.Errorf("models_error = %v, want the failed query", data.ModelsError)
+	}
+}
+
 func TestHandleStatus_SingleModeNoModeField(t *testing.T) {
 	// Arrange - single mode should not have a "mode" field
 	testPreset := &preset.Preset{
@@ -174,10 +257,11 @@ func TestHandleStatus_SingleModeNoModeField(t *testing.T) {
 	resp := server.handleStatus(context.Background())
 
 	// Assert
-	if _, exists := resp.Data["mode"]; exists {
+	data := decodeData[protocol.StatusData](t, resp)
+	if data.Mode != "" {
 		t.Error("mode should not exist for single mode presets")
 	}
-	if _, exists := resp.Data["models"]; exists {
+	if data.Models != nil {
 		t.Error("models should not exist for single mode presets")
 	}
 }
@@ -220,15 +304,112 @@ func TestHandleStatus_RunningWithMmproj(t *testing.T) {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
 
-	mmprojPath, ok := resp.Data["mmproj"].(string)
-	if !ok {
-		t.Fatal("mmproj should be present in status response as string")
-	}
+	mmprojPath := decodeData[protocol.StatusData](t, resp).Mmproj
 	if mmprojPath != "/path/to/mmproj.gguf" {
 		t.Errorf("mmproj = %v, want %q", mmprojPath, "/path/to/mmproj.gguf")
 	}
 }
 
+func TestHandleStatus_Files(t *testing.T) {
+	// Arrange
+	testPreset := &preset.Preset{
+		Name:       "files-preset",
+		Model:      "f:/path/to/model.gguf",
+		DraftModel: "f:/path/to/draft.gguf",
+		Mmproj:     "f:/path/to/mmproj.gguf",
+		Host:       "127.0.0.1",
+		Port:       8080,
+	}
+
+	presets := &stubPresetLoader{
+		presets: map[string]*preset.Preset{
+			"files-preset": testPreset,
+		},
+	}
+	models := &stubModelManager{}
+	daemon := newTestDaemon(presets, models)
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+
+	daemon.newProcess = func(path string) llamaProcess {
+		return &mockProcess{}
+	}
+	daemon.waitForReady = mockHealthChecker(nil)
+
+	if err := daemon.Run(context.Background(), "p:files-preset"); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// Act
+	resp := server.handleStatus(context.Background())
+
+	// Assert
+	want := []string{"/path/to/model.gguf", "/path/to/draft.gguf", "/path/to/mmproj.gguf"}
+	if got := decodeData[protocol.StatusData](t, resp).Files; !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestHandleStatus_Command(t *testing.T) {
+	// Arrange
+	testPreset := &preset.Preset{
+		Name:    "cmd-preset",
+		Model:   "f:/path/to/model.gguf",
+		Host:    "127.0.0.1",
+		Port:    8080,
+		Options: preset.Options{"ctx-size": "4096"},
+	}
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{"cmd-preset": testPreset}}
+	daemon := newTestDaemon(presets, &stubModelManager{})
+	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+	mockProc := &mockProcess{}
+	daemon.newProcess = func(path string) llamaProcess { return mockProc }
+	daemon.waitForReady = mockHealthChecker(nil)
+	if err := daemon.Run(context.Background(), "p:cmd-preset"); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+
+	// Act
+	running := server.handleStatus(context.Background())
+	if err := daemon.Kill(context.Background()); err != nil {
+		t.Fatalf("Kill() failed: %v", err)
+	}
+	idle := server.handleStatus(context.Background())
+
+	// Assert
+	want := append([]string{"llama-server"}, mockProc.receivedArgs...)
+	runningData := decodeData[protocol.StatusData](t, running)
+	if got := runningData.Command; !slices.Equal(got, want) {
+		t.Errorf("command = %v, want %v", got, want)
+	}
+	if !slices.Contains(want, "--ctx-size") {
+		t.Errorf("command %v lacks the preset's options", want)
+	}
+	if runningData.ConfigINI != "" {
+		t.Error("config_ini should not exist in single mode")
+	}
+	if decodeData[protocol.StatusData](t, idle).Command != nil {
+		t.Error("command should not exist after unload")
+	}
+}
+
+func TestNewInvocation_RouterConfig(t *testing.T) {
+	// Arrange
+	configPath := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(configPath, []byte("[chat]\nmodel = /m.gguf\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	daemon := newTestDaemonWithConfigPath(&stubPresetLoader{}, &stubModelManager{}, configPath)
+	p := &preset.Preset{Name: "router", Mode: "router"}
+
+	// Act
+	inv := daemon.newInvocation(p, 4242, "llama-server", []string{"--models-preset", configPath}, configPath)
+
+	// Assert
+	if inv.ConfigINI != "[chat]\nmodel = /m.gguf\n" {
+		t.Errorf("ConfigINI = %q, want the file contents", inv.ConfigINI)
+	}
+}
+
 func TestHandleStatus_RunningWithoutMmproj(t *testing.T) {
 	// Arrange - preset without mmproj should not include mmproj field
 	testPreset := &preset.Preset{
@@ -262,7 +443,7 @@ func TestHandleStatus_RunningWithoutMmproj(t *testing.T) {
 	resp := server.handleStatus(context.Background())
 
 	// Assert
-	if _, exists := resp.Data["mmproj"]; exists {
+	if decodeData[protocol.StatusData](t, resp).Mmproj != "" {
 		t.Error("mmproj should not exist when no mmproj is set")
 	}
 }
@@ -301,7 +482,7 @@ func TestHandleStatus_MmprojNoneNotIncluded(t *testing.T) {
 	resp := server.handleStatus(context.Background())
 
 	// Assert
-	if _, exists := resp.Data["mmproj"]; exists {
+	if decodeData[protocol.StatusData](t, resp).Mmproj != "" {
 		t.Error("mmproj should not exist when mmproj is 'none'")
 	}
 }
@@ -331,8 +512,8 @@ func TestHandleStatus_RouterModeWithMmproj(t *testing.T) {
 	if resp.Status != protocol.StatusOK {
 		t.Errorf("Status = %q, want %q", resp.Status, protocol.StatusOK)
 	}
-	if resp.Data["mode"] != "router" {
-		t.Errorf("mode = %v, want %q", resp.Data["mode"], "router")
+	if data := decodeData[protocol.StatusData](t, resp); data.Mode != "router" {
+		t.Errorf("mode = %v, want %q", data.Mode, "router")
 	}
 	// Note: Without a running llama-server, FetchModelStatuses returns nil,
 	// so "models" won't be present. This test verifies the mmproj map is built correctly
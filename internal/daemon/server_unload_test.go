@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/d2verb/alpaca/internal/preset"
 	"github.com/d2verb/alpaca/internal/protocol"
@@ -41,7 +42,7 @@ func TestHandleUnload_Success(t *testing.T) {
 	}
 
 	// Act
-	resp := server.handleUnload(context.Background())
+	resp := server.handleUnload(context.Background(), &protocol.Request{Command: protocol.CmdUnload})
 
 	// Assert
 	if resp.Status != protocol.StatusOK {
@@ -60,7 +61,7 @@ func TestHandleUnload_WhenIdle(t *testing.T) {
 	server := NewServer(daemon, "/tmp/test.sock", io.Discard)
 
 	// Act
-	resp := server.handleUnload(context.Background())
+	resp := server.handleUnload(context.Background(), &protocol.Request{Command: protocol.CmdUnload})
 
 	// Assert
 	if resp.Status != protocol.StatusOK {
@@ -101,7 +102,7 @@ func TestHandleUnload_Error(t *testing.T) {
 	}
 
 	// Act
-	resp := server.handleUnload(context.Background())
+	resp := server.handleUnload(context.Background(), &protocol.Request{Command: protocol.CmdUnload})
 
 	// Assert
 	if resp.Status != protocol.StatusError {
@@ -111,3 +112,52 @@ func TestHandleUnload_Error(t *testing.T) {
 		t.Errorf("Error = %q, want %q", resp.Error, "failed to stop process")
 	}
 }
+
+func TestHandleUnload_ReportsGraceful(t *testing.T) {
+	tests := []struct {
+		name           string
+		force          bool
+		ignoresSIGTERM bool
+		wantGraceful   bool
+		wantGrace      time.Duration
+	}{
+		{name: "exits on SIGTERM", wantGraceful: true, wantGrace: 30 * time.Second},
+		{name: "killed after grace period", ignoresSIGTERM: true, wantGraceful: false, wantGrace: 30 * time.Second},
+		{name: "force kills immediately", force: true, wantGraceful: false, wantGrace: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			presets := &stubPresetLoader{
+				presets: map[string]*preset.Preset{
+					"test-preset": {Name: "test-preset", Model: "f:/path/to/model.gguf"},
+				},
+			}
+			daemon := newTestDaemon(presets, &stubModelManager{})
+			daemon.SetStopGracePeriod(30 * time.Second)
+			server := NewServer(daemon, "/tmp/test.sock", io.Discard)
+			mockProc := &mockProcess{ignoresSIGTERM: tt.ignoresSIGTERM}
+			daemon.newProcess = func(path string) llamaProcess { return mockProc }
+			daemon.waitForReady = mockHealthChecker(nil)
+			if err := daemon.Run(context.Background(), "p:test-preset"); err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			req := protocol.NewRequest(protocol.CmdUnload, map[string]any{"force": tt.force})
+
+			// Act
+			resp := server.handleUnload(context.Background(), req)
+
+			// Assert
+			if resp.Status != protocol.StatusOK {
+				t.Fatalf("Status = %q, want %q (%s)", resp.Status, protocol.StatusOK, resp.Error)
+			}
+			if got := decodeData[protocol.UnloadData](t, resp).Graceful; got != tt.wantGraceful {
+				t.Errorf("graceful = %v, want %v", got, tt.wantGraceful)
+			}
+			if mockProc.stopGrace != tt.wantGrace {
+				t.Errorf("grace = %v, want %v", mockProc.stopGrace, tt.wantGrace)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// sendRaw sends req to the remote server at addr as is, without the client
+// filling in the protocol version.
+func sendRaw(t *testing.T, addr string, req protocol.Request) protocol.Response {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	var resp protocol.Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	return resp
+}
+
+func TestServer_VersionNegotiation(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      protocol.Request
+		wantCode string // empty for success
+	}{
+		{"current version", protocol.Request{Command: protocol.CmdStatus, Version: protocol.Version}, ""},
+		{"client without version", protocol.Request{Command: protocol.CmdStatus}, ""},
+		{"newer client", protocol.Request{Command: protocol.CmdStatus, Version: protocol.Version + 1}, protocol.ErrCodeVersionMismatch},
+		{"hello from any version", protocol.Request{Command: protocol.CmdHello, Version: protocol.Version + 1}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			addr := startRemoteServer(t, "secret")
+			tt.req.Token = "secret"
+
+			// Act
+			resp := sendRaw(t, addr, tt.req)
+
+			// Assert
+			if resp.Version != protocol.Version {
+				t.Errorf("response Version = %d, want %d", resp.Version, protocol.Version)
+			}
+			if resp.ErrorCode != tt.wantCode {
+				t.Fatalf("ErrorCode = %q (%s), want %q", resp.ErrorCode, resp.Error, tt.wantCode)
+			}
+			if tt.req.Command == protocol.CmdHello {
+				if got := decodeData[protocol.HelloData](t, &resp).Version; got != protocol.Version {
+					t.Errorf("hello version = %v, want %d", got, protocol.Version)
+				}
+			}
+		})
+	}
+}
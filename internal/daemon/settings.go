@@ -0,0 +1,31 @@
+package daemon
+
+import (
+	"time"
+
+	"github.com/d2verb/alpaca/internal/allowlist"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// runtimeSettings are the config.yaml settings the daemon reads on each
+// load and unload, so a config reload (SIGHUP) can change them while it
+// runs. They are replaced as a whole: a load sees either the old settings
+// or the new ones, never a mix.
+type runtimeSettings struct {
+	llamaServerPath string            // llama-server binary used when the preset sets none
+	allowList       *allowlist.Source // signed manifest of loadable model hashes; nil allows all
+	hooks           preset.Hooks      // hooks from config.yaml
+	runLogDir       string            // per-run llama-server logs; empty disables them
+	runLogsKeep     int
+	startupTimeout  time.Duration
+	stopGrace       time.Duration // SIGTERM-to-SIGKILL grace period when stopping llama-server
+}
+
+// updateSettings applies fn to a copy of the settings and publishes it.
+func (d *Daemon) updateSettings(fn func(s *runtimeSettings)) {
+	d.settingsMu.Lock()
+	defer d.settingsMu.Unlock()
+	s := *d.settings.Load()
+	fn(&s)
+	d.settings.Store(&s)
+}
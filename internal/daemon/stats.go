@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxUsageScanBytes limits how much of a non-streaming response body is
+// buffered to read its "usage" object.
+const maxUsageScanBytes = 4 << 20
+
+// ModelStats holds the request counters of one model.
+// Counters survive model swaps and reset when the daemon restarts.
+type ModelStats struct {
+	Model            string
+	Requests         int64
+	Errors           int64 // upstream failures and 5xx responses
+	PromptTokens     int64
+	CompletionTokens int64
+	TotalLatency     time.Duration // summed over Requests
+}
+
+// AvgLatency returns the mean request latency.
+func (s ModelStats) AvgLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+// usageStats accumulates ModelStats keyed by model.
+type usageStats struct {
+	mu     sync.Mutex
+	models map[string]*ModelStats
+}
+
+// requestUsage is what one proxied request contributes to the counters.
+type requestUsage struct {
+	failed           bool
+	promptTokens     int64
+	completionTokens int64
+	latency          time.Duration
+}
+
+func (u *usageStats) record(model string, r requestUsage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.models == nil {
+		u.models = make(map[string]*ModelStats)
+	}
+	s, ok := u.models[model]
+	if !ok {
+		s = &ModelStats{Model: model}
+		u.models[model] = s
+	}
+	s.Requests++
+	if r.failed {
+		s.Errors++
+	}
+	s.PromptTokens += r.promptTokens
+	s.CompletionTokens += r.completionTokens
+	s.TotalLatency += r.latency
+}
+
+// UsageStats returns the per-model counters, most requested first.
+func (d *Daemon) UsageStats() []ModelStats {
+	d.stats.mu.Lock()
+	defer d.stats.mu.Unlock()
+	out := make([]ModelStats, 0, len(d.stats.models))
+	for _, s := range d.stats.models {
+		out = append(out, *s)
+	}
+	slices.SortFunc(out, func(a, b ModelStats) int {
+		if a.Requests != b.Requests {
+			return int(b.Requests - a.Requests)
+		}
+		return strings.Compare(a.Model, b.Model)
+	})
+	return out
+}
+
+// usageReader wraps a llama-server response body and reads the OpenAI-style
+// "usage" object from it, either from the JSON body or from the last
+// server-sent event carrying one. done is called once, when the body is
+// closed.
+type usageReader struct {
+	body   io.ReadCloser
+	stream bool
+	buf    bytes.Buffer // pending partial line (stream) or body (JSON)
+	usage  tokenUsage
+	done   func(tokenUsage)
+	once   sync.Once
+}
+
+// tokenUsage is the "usage" object of an OpenAI-style response.
+type tokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+func newUsageReader(body io.ReadCloser, contentType string, done func(tokenUsage)) *usageReader {
+	return &usageReader{
+		body:   body,
+		stream: strings.HasPrefix(contentType, "text/event-stream"),
+		done:   done,
+	}
+}
+
+func (r *usageReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	if n > 0 {
+		r.scan(p[:n])
+	}
+	return n, err
+}
+
+func (r *usageReader) Close() error {
+	err := r.body.Close()
+	r.once.Do(func() {
+		if !r.stream && r.buf.Len() > 0 {
+			r.parse(r.buf.Bytes())
+		}
+		r.done(r.usage)
+	})
+	return err
+}
+
+func (r *usageReader) scan(p []byte) {
+	if !r.stream {
+		if r.buf.Len()+len(p) <= maxUsageScanBytes {
+			r.buf.Write(p)
+		} else {
+			r.buf.Reset() // too large to be worth parsing
+		}
+		return
+	}
+
+	r.buf.Write(p)
+	for {
+		i := bytes.IndexByte(r.buf.Bytes(), '\n')
+		if i < 0 {
+			return // keep the partial line
+		}
+		line := r.buf.Next(i + 1)
+		if data, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			r.parse(bytes.TrimSpace(data))
+		}
+	}
+}
+
+// parse records the usage object of a JSON document, if it has one.
+func (r *usageReader) parse(data []byte) {
+	var payload struct {
+		Usage *tokenUsage `json:"usage"`
+	}
+	if json.Unmarshal(data, &payload) == nil && payload.Usage != nil {
+		r.usage = *payload.Usage
+	}
+}
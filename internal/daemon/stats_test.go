@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsageReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        tokenUsage
+	}{
+		{
+			name:        "JSON body",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7}}`,
+			want:        tokenUsage{PromptTokens: 5, CompletionTokens: 7},
+		},
+		{
+			name:        "JSON body without usage",
+			contentType: "application/json",
+			body:        `{"status":"ok"}`,
+			want:        tokenUsage{},
+		},
+		{
+			name:        "event stream takes the last usage",
+			contentType: "text/event-stream",
+			body: "data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n" +
+				"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":9}}\n\n" +
+				"data: [DONE]\n\n",
+			want: tokenUsage{PromptTokens: 3, CompletionTokens: 9},
+		},
+		{
+			name:        "non-JSON body",
+			contentType: "text/html",
+			body:        "<html></html>",
+			want:        tokenUsage{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var got tokenUsage
+			calls := 0
+			r := newUsageReader(io.NopCloser(strings.NewReader(tt.body)), tt.contentType, func(u tokenUsage) {
+				got = u
+				calls++
+			})
+
+			// Act: read in small chunks so stream lines span reads
+			buf := make([]byte, 7)
+			var out strings.Builder
+			for {
+				n, err := r.Read(buf)
+				out.Write(buf[:n])
+				if err != nil {
+					break
+				}
+			}
+			r.Close()
+			r.Close()
+
+			// Assert
+			if out.String() != tt.body {
+				t.Errorf("body = %q, want it passed through unchanged", out.String())
+			}
+			if calls != 1 {
+				t.Errorf("done called %d times, want 1", calls)
+			}
+			if got != tt.want {
+				t.Errorf("usage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageStats_SortedByRequests(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.stats.record("a", requestUsage{latency: 100 * time.Millisecond})
+	d.stats.record("b", requestUsage{latency: 100 * time.Millisecond})
+	d.stats.record("b", requestUsage{failed: true, latency: 300 * time.Millisecond})
+
+	// Act
+	stats := d.UsageStats()
+
+	// Assert
+	if len(stats) != 2 || stats[0].Model != "b" || stats[1].Model != "a" {
+		t.Fatalf("UsageStats() = %+v, want b then a", stats)
+	}
+	if stats[0].Errors != 1 {
+		t.Errorf("b errors = %d, want 1", stats[0].Errors)
+	}
+	if got := stats[0].AvgLatency(); got != 200*time.Millisecond {
+		t.Errorf("b AvgLatency() = %v, want 200ms", got)
+	}
+}
@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// tempFiles records the temporary files the daemon writes, such as the
+// run-scoped router config.ini, in a file. A daemon that is killed
+// before it removes them leaves the record behind, and the next one
+// removes them when it starts.
+type tempFiles struct {
+	mu    sync.Mutex
+	path  string // the record; empty disables it
+	files []string
+}
+
+type tempFilesRecord struct {
+	Files []string `json:"files"`
+}
+
+// SetTempFilesPath enables recording the daemon's temporary files in a
+// file at path. An empty path (the default) disables it.
+func (d *Daemon) SetTempFilesPath(path string) {
+	d.temps.path = path
+}
+
+// add records file before it is written.
+func (t *tempFiles) add(file string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if slices.Contains(t.files, file) {
+		return nil
+	}
+	t.files = append(t.files, file)
+	return t.saveLocked()
+}
+
+// remove deletes file and drops it from the record.
+func (t *tempFiles) remove(file string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	t.files = slices.DeleteFunc(t.files, func(f string) bool { return f == file })
+	return t.saveLocked()
+}
+
+func (t *tempFiles) saveLocked() error {
+	if t.path == "" {
+		return nil
+	}
+	if len(t.files) == 0 {
+		if err := os.Remove(t.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(tempFilesRecord{Files: t.files}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(t.path, string(data)+"\n")
+}
+
+// readTempFiles returns the files recorded at path, if any.
+func readTempFiles(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read temp files record: %w", err)
+	}
+	var rec tempFilesRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("parse temp files record %s: %w", path, err)
+	}
+	return rec.Files, nil
+}
+
+// CleanupTempFiles removes the temporary files a previous daemon recorded
+// and did not remove, e.g. because it was killed, and the router
+// config.ini of versions that wrote it under a fixed name. The config.ini
+// of an adopted llama-server is kept. Call after AdoptOrphan and before
+// serving requests.
+func (d *Daemon) CleanupTempFiles() {
+	if d.temps.path == "" {
+		return
+	}
+	files, err := readTempFiles(d.temps.path)
+	if err != nil {
+		d.logger.Warn("failed to read temp files record", "error", err)
+	}
+	if d.configPath != "" {
+		files = append(files, d.configPath)
+	}
+
+	var inUse string
+	if inv := d.Invocation(); inv != nil {
+		inUse = inv.configPath
+	}
+	d.temps.mu.Lock()
+	defer d.temps.mu.Unlock()
+	for _, f := range files {
+		if f == inUse {
+			if !slices.Contains(d.temps.files, f) {
+				d.temps.files = append(d.temps.files, f)
+			}
+			continue
+		}
+		err := os.Remove(f)
+		switch {
+		case err == nil:
+			d.logger.Info("removed temporary file left by a previous daemon", "path", f)
+		case !errors.Is(err, os.ErrNotExist):
+			d.logger.Warn("failed to remove temporary file left by a previous daemon", "path", f, "error", err)
+		}
+	}
+	if err := d.temps.saveLocked(); err != nil {
+		d.logger.Warn("failed to write temp files record", "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+func TestDaemonRun_RecordsRouterConfig(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	presets := &stubPresetLoader{presets: map[string]*preset.Preset{
+		"router": {Name: "router", Mode: "router", Models: []preset.ModelEntry{{Name: "chat", Model: "f:/models/chat.gguf"}}},
+	}}
+	d := newTestDaemonWithConfigPath(presets, &stubModelManager{}, filepath.Join(dir, "router-config.ini"))
+	recordPath := filepath.Join(dir, "temp-files.json")
+	d.SetTempFilesPath(recordPath)
+	mockProc := &mockProcess{}
+	d.newProcess = func(path string) llamaProcess { return mockProc }
+	d.waitForReady = mockHealthChecker(nil)
+
+	// Act
+	if err := d.Run(context.Background(), "p:router"); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	recorded, err := readTempFiles(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Kill(context.Background()); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+
+	// Assert
+	runConfig := routerConfigArg(t, mockProc.receivedArgs)
+	if !slices.Equal(recorded, []string{runConfig}) {
+		t.Errorf("recorded while running = %v, want [%s]", recorded, runConfig)
+	}
+	if _, err := os.Stat(recordPath); !os.IsNotExist(err) {
+		t.Errorf("record should be removed once the config is, stat error = %v", err)
+	}
+}
+
+func TestCleanupTempFiles(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	base := filepath.Join(dir, "router-config.ini")
+	leftover := filepath.Join(dir, "router-config-1a2b3c4d.ini")
+	adopted := filepath.Join(dir, "router-config-5e6f7a8b.ini")
+	for _, f := range []string{base, leftover, adopted} {
+		if err := os.WriteFile(f, []byte("[chat]\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recordPath := filepath.Join(dir, "temp-files.json")
+	if err := os.WriteFile(recordPath, []byte(`{"files": ["`+leftover+`", "`+adopted+`", "`+filepath.Join(dir, "gone.ini")+`"]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDaemonWithConfigPath(&stubPresetLoader{}, &stubModelManager{}, base)
+	d.SetTempFilesPath(recordPath)
+	router := &preset.Preset{Name: "router", Mode: "router"}
+	d.invocation.Store(d.newInvocation(router, 4242, "llama-server", []string{"--models-preset", adopted}, adopted))
+
+	// Act
+	d.CleanupTempFiles()
+
+	// Assert
+	for _, f := range []string{base, leftover} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("%s should be removed, stat error = %v", filepath.Base(f), err)
+		}
+	}
+	if _, err := os.Stat(adopted); err != nil {
+		t.Errorf("config.ini of the adopted server should be kept: %v", err)
+	}
+	recorded, err := readTempFiles(recordPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(recorded, []string{adopted}) {
+		t.Errorf("recorded = %v, want only the adopted server's config", recorded)
+	}
+}
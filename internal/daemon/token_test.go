@@ -0,0 +1,53 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrCreateToken_CreatesAndReuses(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "remote-token")
+
+	// Act
+	first, err := LoadOrCreateToken(path)
+	if err != nil {
+		t.Fatalf("first LoadOrCreateToken() error = %v", err)
+	}
+	second, err := LoadOrCreateToken(path)
+	if err != nil {
+		t.Fatalf("second LoadOrCreateToken() error = %v", err)
+	}
+
+	// Assert
+	if len(first) != 64 {
+		t.Errorf("token length = %d, want 64", len(first))
+	}
+	if first != second {
+		t.Errorf("token changed between calls: %q != %q", first, second)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat token file: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("token file permissions = %o, want 0600", perm)
+	}
+}
+
+func TestLoadOrCreateToken_EmptyFile(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "remote-token")
+	if err := os.WriteFile(path, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	_, err := LoadOrCreateToken(path)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error for empty token file")
+	}
+}
@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// defaultWatchDebounce is how long a watched preset file must stay unchanged
+// before it is reloaded, so an editor's write-and-rename counts as one edit.
+const defaultWatchDebounce = 500 * time.Millisecond
+
+// presetPath returns the file that defines the preset loaded from input.
+func (d *Daemon) presetPath(input string) (string, error) {
+	id, err := identifier.Parse(input)
+	if err != nil {
+		return "", err
+	}
+	switch id.Type {
+	case identifier.TypePresetName:
+		path, err := d.presets.FindPath(id.PresetName)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Abs(path)
+	case identifier.TypePresetFilePath:
+		return filepath.Abs(id.FilePath)
+	default:
+		return "", fmt.Errorf("%s is not a preset", input)
+	}
+}
+
+// watchedFile is a preset file to watch and its contents as loaded.
+type watchedFile struct {
+	path string
+	data []byte
+}
+
+// readWatchedFile reads the file of a preset that set watch: true right
+// after it was loaded from input, so later edits are compared with what was
+// loaded. It returns nil for other presets or if the file cannot be read.
+func (d *Daemon) readWatchedFile(input string, p *preset.Preset) *watchedFile {
+	if !p.Watch {
+		return nil
+	}
+	path, err := d.presetPath(input)
+	if err == nil {
+		var data []byte
+		if data, err = os.ReadFile(path); err == nil {
+			return &watchedFile{path: path, data: data}
+		}
+	}
+	d.logger.Warn("cannot watch preset", "input", input, "error", err)
+	return nil
+}
+
+// watchPreset starts watching the preset file f, loaded from input, if
+// it is not nil.
+func (d *Daemon) watchPreset(input string, opts RunOptions, f *watchedFile) {
+	if f == nil {
+		return
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		d.logger.Warn("cannot watch preset", "path", f.path, "error", err)
+		return
+	}
+	// Editors often replace the file by renaming, which drops a watch on the
+	// file itself; watch its directory instead.
+	if err := w.Add(filepath.Dir(f.path)); err != nil {
+		w.Close()
+		d.logger.Warn("cannot watch preset", "path", f.path, "error", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.watchMu.Lock()
+	if d.stopWatch != nil {
+		d.stopWatch()
+	}
+	d.stopWatch = cancel
+	d.watchMu.Unlock()
+
+	d.logger.Info("watching preset", "path", f.path)
+	go d.runPresetWatch(ctx, w, f, input, opts)
+}
+
+// unwatchPreset stops watching the preset file, if any.
+func (d *Daemon) unwatchPreset() {
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+	if d.stopWatch != nil {
+		d.stopWatch()
+		d.stopWatch = nil
+	}
+}
+
+func (d *Daemon) runPresetWatch(ctx context.Context, w *fsnotify.Watcher, f *watchedFile, input string, opts RunOptions) {
+	defer w.Close()
+
+	debounce := d.watchDebounce
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) == f.path && !ev.Has(fsnotify.Chmod) {
+				settled = time.After(debounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			d.logger.Warn("preset watch error", "path", f.path, "error", err)
+		case <-settled:
+			settled = nil
+			d.reloadWatchedPreset(ctx, f, input, opts)
+		}
+	}
+}
+
+// reloadWatchedPreset validates the edited preset and restarts llama-server
+// with it if the file differs from the one loaded. Only the file is
+// compared: the running preset also holds values resolved at load time,
+// such as gpu-layers: auto. An invalid edit is logged and the running
+// server is kept.
+func (d *Daemon) reloadWatchedPreset(ctx context.Context, f *watchedFile, input string, opts RunOptions) {
+	if d.State() != StateRunning {
+		return
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		d.logger.Warn("preset change ignored", "path", f.path, "error", err)
+		return
+	}
+	if bytes.Equal(data, f.data) {
+		return
+	}
+	if _, err := d.loadPreset(ctx, input, opts); err != nil {
+		d.logger.Warn("preset change ignored", "path", f.path, "error", err)
+		return
+	}
+
+	d.logger.Info("preset changed, reloading", "path", f.path)
+	// The reload replaces this watch, so it must not run under ctx.
+	if err := d.RunWithOptions(context.WithoutCancel(ctx), input, opts); err != nil {
+		d.logger.Error("reload after preset change failed", "path", f.path, "error", err)
+	}
+}
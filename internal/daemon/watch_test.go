@@ -0,0 +1,223 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/gguf"
+	"github.com/d2verb/alpaca/internal/llama"
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+// writePresetFile writes a single-mode preset with the given ctx-size.
+func writePresetFile(t *testing.T, path, ctxSize string) {
+	t.Helper()
+	content := "name: watched\nmodel: f:/path/to/model.gguf\nwatch: true\noptions:\n  ctx-size: " + ctxSize + "\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDaemon_WatchedPresetReloadsOnChange(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "watched.yaml")
+	writePresetFile(t, path, "4096")
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.watchDebounce = 10 * time.Millisecond
+	started := make(chan []string, 4)
+	d.newProcess = func(string) llamaProcess {
+		return &startRecorder{started: started}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	if err := d.Run(context.Background(), "f:"+path); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-started
+	t.Cleanup(d.unwatchPreset)
+
+	// Act
+	writePresetFile(t, path, "8192")
+
+	// Assert
+	select {
+	case args := <-started:
+		if i := slices.Index(args, "--ctx-size"); i < 0 || args[i+1] != "8192" {
+			t.Errorf("restarted with args %v, want --ctx-size 8192", args)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("llama-server was not restarted after the preset changed")
+	}
+}
+
+func TestDaemon_WatchedPresetIgnoresInvalidOrUnchangedEdits(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "watched.yaml")
+	writePresetFile(t, path, "4096")
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.watchDebounce = 10 * time.Millisecond
+	started := make(chan []string, 4)
+	d.newProcess = func(string) llamaProcess {
+		return &startRecorder{started: started}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	if err := d.Run(context.Background(), "f:"+path); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-started
+	t.Cleanup(d.unwatchPreset)
+
+	// Act
+	writePresetFile(t, path, "4096")
+	if err := os.WriteFile(path, []byte("name: watched\nmode: bogus\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	select {
+	case args := <-started:
+		t.Errorf("llama-server restarted with %v, want the running server kept", args)
+	case <-time.After(300 * time.Millisecond):
+	}
+	if d.State() != StateRunning {
+		t.Errorf("State() = %q, want %q", d.State(), StateRunning)
+	}
+}
+
+func TestDaemon_WatchedPresetIgnoresRuntimeChanges(t *testing.T) {
+	// Arrange: gpu-layers auto resolves differently once VRAM changes
+	path := filepath.Join(t.TempDir(), "watched.yaml")
+	content := []byte("name: watched\nmodel: f:/path/to/model.gguf\nwatch: true\ngpu-layers: auto\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.watchDebounce = 10 * time.Millisecond
+	var vram atomic.Int64
+	vram.Store(24 << 30)
+	d.detectGPU = func() sysinfo.GPU { return sysinfo.GPU{Backend: sysinfo.BackendCUDA, VRAM: vram.Load(), Count: 1} }
+	d.inspectModel = func(string) (*gguf.Info, error) { return &gguf.Info{Size: 16 << 30, BlockCount: 32}, nil }
+	started := make(chan []string, 4)
+	d.newProcess = func(string) llamaProcess {
+		return &startRecorder{started: started}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	if err := d.Run(context.Background(), "f:"+path); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	<-started
+	t.Cleanup(d.unwatchPreset)
+
+	// Act
+	vram.Store(12 << 30)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	select {
+	case args := <-started:
+		t.Errorf("llama-server restarted with %v, want the unchanged file ignored", args)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestDaemon_WatchOptionReloadsUnwatchedPreset(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "workspace.yaml")
+	writeCtxSize := func(ctxSize string) {
+		t.Helper()
+		content := "name: workspace\nmodel: f:/path/to/model.gguf\noptions:\n  ctx-size: " + ctxSize + "\n"
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCtxSize("4096")
+
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.watchDebounce = 10 * time.Millisecond
+	started := make(chan []string, 4)
+	d.newProcess = func(string) llamaProcess {
+		return &startRecorder{started: started}
+	}
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	if err := d.RunWithOptions(context.Background(), "f:"+path, RunOptions{Watch: true}); err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+	<-started
+	t.Cleanup(d.unwatchPreset)
+
+	// Act
+	writeCtxSize("8192")
+
+	// Assert
+	select {
+	case args := <-started:
+		if i := slices.Index(args, "--ctx-size"); i < 0 || args[i+1] != "8192" {
+			t.Errorf("restarted with args %v, want --ctx-size 8192", args)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("llama-server was not restarted after the preset changed")
+	}
+}
+
+func TestDaemon_WatchOptionRejectsModels(t *testing.T) {
+	// Arrange
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.newProcess = func(string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+
+	// Act
+	err := d.RunWithOptions(context.Background(), "f:/path/to/model.gguf", RunOptions{Watch: true})
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "only presets can be watched") {
+		t.Errorf("RunWithOptions() error = %v, want only presets can be watched", err)
+	}
+	if d.State() != StateIdle {
+		t.Errorf("State() = %q, want idle", d.State())
+	}
+}
+
+func TestDaemon_UnloadStopsPresetWatch(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "watched.yaml")
+	writePresetFile(t, path, "4096")
+	d := newTestDaemon(&stubPresetLoader{}, &stubModelManager{})
+	d.newProcess = func(string) llamaProcess { return &mockProcess{} }
+	d.waitForReady = func(ctx context.Context, endpoint string, opts llama.WaitOptions) error { return nil }
+	if err := d.Run(context.Background(), "f:"+path); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Act
+	if err := d.Kill(context.Background()); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+
+	// Assert
+	d.watchMu.Lock()
+	defer d.watchMu.Unlock()
+	if d.stopWatch != nil {
+		t.Error("preset watch should stop on unload")
+	}
+}
+
+// startRecorder is a mockProcess that reports the args of each start.
+type startRecorder struct {
+	mockProcess
+	started chan<- []string
+}
+
+func (s *startRecorder) Start(args []string) error {
+	s.started <- args
+	return s.mockProcess.Start(args)
+}
@@ -0,0 +1,89 @@
+// Package gguf reads and validates GGUF model file headers.
+package gguf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Magic is the 4-byte signature at the start of every GGUF file.
+const Magic = "GGUF"
+
+// Limits used to reject obviously corrupted headers. Real models have at
+// most a few thousand tensors and a few hundred metadata entries.
+const (
+	maxTensorCount = 1 << 20
+	maxKVCount     = 1 << 20
+)
+
+// ErrInvalid is returned (wrapped) when a file is not a usable GGUF file.
+var ErrInvalid = errors.New("corrupted or not a GGUF file")
+
+// Header is the fixed part of a GGUF file header.
+type Header struct {
+	Version         uint32
+	TensorCount     uint64
+	MetadataKVCount uint64
+}
+
+// ReadHeader reads and sanity-checks a GGUF header.
+func ReadHeader(r io.Reader) (*Header, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("%w: file too short", ErrInvalid)
+	}
+	if string(magic[:]) != Magic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrInvalid, magic[:])
+	}
+
+	var h Header
+	if err := binary.Read(r, binary.LittleEndian, &h.Version); err != nil {
+		return nil, fmt.Errorf("%w: truncated header", ErrInvalid)
+	}
+
+	switch h.Version {
+	case 1:
+		// Version 1 used 32-bit counts
+		var counts [2]uint32
+		if err := binary.Read(r, binary.LittleEndian, &counts); err != nil {
+			return nil, fmt.Errorf("%w: truncated header", ErrInvalid)
+		}
+		h.TensorCount, h.MetadataKVCount = uint64(counts[0]), uint64(counts[1])
+	case 2, 3:
+		var counts [2]uint64
+		if err := binary.Read(r, binary.LittleEndian, &counts); err != nil {
+			return nil, fmt.Errorf("%w: truncated header", ErrInvalid)
+		}
+		h.TensorCount, h.MetadataKVCount = counts[0], counts[1]
+	default:
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalid, h.Version)
+	}
+
+	if h.TensorCount > maxTensorCount {
+		return nil, fmt.Errorf("%w: implausible tensor count %d", ErrInvalid, h.TensorCount)
+	}
+	if h.MetadataKVCount > maxKVCount {
+		return nil, fmt.Errorf("%w: implausible metadata count %d", ErrInvalid, h.MetadataKVCount)
+	}
+	return &h, nil
+}
+
+// Validate checks that the file at path exists and starts with a valid GGUF header.
+func Validate(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file not found: %s", path)
+		}
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := ReadHeader(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// header builds a GGUF header with 64-bit counts (version 2+).
+func header(magic string, version uint32, tensors, kvs uint64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(magic)
+	binary.Write(&buf, binary.LittleEndian, version)
+	binary.Write(&buf, binary.LittleEndian, tensors)
+	binary.Write(&buf, binary.LittleEndian, kvs)
+	return buf.Bytes()
+}
+
+func TestReadHeader(t *testing.T) {
+	v1 := new(bytes.Buffer)
+	v1.WriteString(Magic)
+	binary.Write(v1, binary.LittleEndian, []uint32{1, 291, 19})
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantTensors uint64
+		wantErr     string
+	}{
+		{"version 3", header(Magic, 3, 291, 24), 291, ""},
+		{"version 2", header(Magic, 2, 10, 5), 10, ""},
+		{"version 1", v1.Bytes(), 291, ""},
+		{"empty", nil, 0, "file too short"},
+		{"bad magic", header("GGML", 3, 1, 1), 0, "bad magic"},
+		{"unsupported version", header(Magic, 99, 1, 1), 0, "unsupported version"},
+		{"truncated", header(Magic, 3, 1, 1)[:12], 0, "truncated header"},
+		{"implausible tensors", header(Magic, 3, 1<<40, 1), 0, "implausible tensor count"},
+		{"implausible metadata", header(Magic, 3, 1, 1<<40), 0, "implausible metadata count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			h, err := ReadHeader(bytes.NewReader(tt.data))
+
+			// Assert
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadHeader() error = %v, want ErrInvalid containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadHeader() error = %v", err)
+			}
+			if h.TensorCount != tt.wantTensors {
+				t.Errorf("TensorCount = %d, want %d", h.TensorCount, tt.wantTensors)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.gguf")
+	os.WriteFile(valid, header(Magic, 3, 1, 1), 0644)
+	html := filepath.Join(dir, "html.gguf")
+	os.WriteFile(html, []byte("<!DOCTYPE html><html>rate limited</html>"), 0644)
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr string
+	}{
+		{"valid", valid, ""},
+		{"not gguf", html, "corrupted or not a GGUF file"},
+		{"missing", filepath.Join(dir, "missing.gguf"), "file not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := Validate(tt.path)
+
+			// Assert
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// Metadata value types as stored in the file.
+const (
+	typeUint8   = 0
+	typeInt8    = 1
+	typeUint16  = 2
+	typeInt16   = 3
+	typeUint32  = 4
+	typeInt32   = 5
+	typeFloat32 = 6
+	typeBool    = 7
+	typeString  = 8
+	typeArray   = 9
+	typeUint64  = 10
+	typeInt64   = 11
+	typeFloat64 = 12
+)
+
+// maxStringLen rejects corrupted string lengths before allocating.
+const maxStringLen = 1 << 24
+
+// Metadata holds scalar metadata values keyed by name (e.g.
+// "general.architecture"). Integers are stored as int64 or uint64, floats as
+// float64. Arrays (tokenizer vocabularies) are skipped.
+type Metadata map[string]any
+
+// String returns the string value for key.
+func (m Metadata) String(key string) (string, bool) {
+	s, ok := m[key].(string)
+	return s, ok
+}
+
+// Int returns the integer value for key, whatever its stored width.
+func (m Metadata) Int(key string) (int64, bool) {
+	switch v := m[key].(type) {
+	case int64:
+		return v, true
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// ReadMetadata reads the header and the scalar metadata that follows it.
+// Version 1 files are not supported.
+func ReadMetadata(r io.Reader) (*Header, Metadata, error) {
+	h, md, _, err := readMetadata(r)
+	return h, md, err
+}
+
+// readMetadata is ReadMetadata, also returning the reader positioned at the
+// tensor infos that follow the metadata.
+func readMetadata(r io.Reader) (*Header, Metadata, *bufio.Reader, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if h.Version < 2 {
+		return nil, nil, nil, fmt.Errorf("%w: metadata of version %d files is not supported", ErrInvalid, h.Version)
+	}
+
+	br := bufio.NewReader(r)
+	md := make(Metadata, h.MetadataKVCount)
+	for range h.MetadataKVCount {
+		key, err := readString(br)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var typ uint32
+		if err := binary.Read(br, binary.LittleEndian, &typ); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: truncated metadata", ErrInvalid)
+		}
+		v, err := readValue(br, typ)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("metadata %q: %w", key, err)
+		}
+		if v != nil {
+			md[key] = v
+		}
+	}
+	return h, md, br, nil
+}
+
+// maxTensorDims rejects corrupted tensor dimension counts; ggml tensors
+// have at most 4 dimensions.
+const maxTensorDims = 4
+
+// countParameters reads n tensor infos and returns the total number of
+// elements of the tensors.
+func countParameters(r *bufio.Reader, n uint64) (int64, error) {
+	var total int64
+	for range n {
+		if _, err := readString(r); err != nil {
+			return 0, err
+		}
+		var dims uint32
+		if err := binary.Read(r, binary.LittleEndian, &dims); err != nil {
+			return 0, truncated(err)
+		}
+		if dims > maxTensorDims {
+			return 0, fmt.Errorf("%w: implausible tensor dimension count %d", ErrInvalid, dims)
+		}
+		elements := int64(1)
+		for range dims {
+			var d uint64
+			if err := binary.Read(r, binary.LittleEndian, &d); err != nil {
+				return 0, truncated(err)
+			}
+			if d > math.MaxInt32 {
+				return 0, fmt.Errorf("%w: implausible tensor dimension %d", ErrInvalid, d)
+			}
+			elements *= int64(d)
+		}
+		// Type and data offset
+		var rest [12]byte
+		if _, err := io.ReadFull(r, rest[:]); err != nil {
+			return 0, truncated(err)
+		}
+		total += elements
+	}
+	return total, nil
+}
+
+// readValue reads one value of type typ. Arrays are consumed and nil returned.
+func readValue(r *bufio.Reader, typ uint32) (any, error) {
+	var err error
+	switch typ {
+	case typeUint8:
+		var v uint8
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), truncated(err)
+	case typeInt8:
+		var v int8
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return int64(v), truncated(err)
+	case typeUint16:
+		var v uint16
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), truncated(err)
+	case typeInt16:
+		var v int16
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return int64(v), truncated(err)
+	case typeUint32:
+		var v uint32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return uint64(v), truncated(err)
+	case typeInt32:
+		var v int32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return int64(v), truncated(err)
+	case typeUint64:
+		var v uint64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return v, truncated(err)
+	case typeInt64:
+		var v int64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return v, truncated(err)
+	case typeFloat32:
+		var v float32
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return float64(v), truncated(err)
+	case typeFloat64:
+		var v float64
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return v, truncated(err)
+	case typeBool:
+		var v uint8
+		err = binary.Read(r, binary.LittleEndian, &v)
+		return v != 0, truncated(err)
+	case typeString:
+		return readString(r)
+	case typeArray:
+		var elemType uint32
+		var n uint64
+		if err := binary.Read(r, binary.LittleEndian, &elemType); err != nil {
+			return nil, truncated(err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, truncated(err)
+		}
+		for range n {
+			if _, err := readValue(r, elemType); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown metadata type %d", ErrInvalid, typ)
+	}
+}
+
+func readString(r *bufio.Reader) (string, error) {
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return "", truncated(err)
+	}
+	if n > maxStringLen {
+		return "", fmt.Errorf("%w: implausible string length %d", ErrInvalid, n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", truncated(err)
+	}
+	return string(buf), nil
+}
+
+func truncated(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: truncated metadata", ErrInvalid)
+}
+
+// Info summarizes a model file for sizing decisions.
+type Info struct {
+	Size          int64  // file size in bytes
+	Architecture  string // general.architecture (e.g. "llama")
+	BlockCount    int    // transformer layers; 0 if unknown
+	ContextLength int    // training context length; 0 if unknown
+	Parameters    int64  // elements of the file's tensors; 0 if unknown
+}
+
+// InspectSplit reads the metadata of a model split into the files at
+// paths, in order: the first holds the model's metadata, and Size and
+// Parameters are totals over all files.
+func InspectSplit(paths []string) (*Info, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no model files")
+	}
+	info, err := Inspect(paths[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths[1:] {
+		shard, err := Inspect(path)
+		if err != nil {
+			return nil, err
+		}
+		info.Size += shard.Size
+		info.Parameters += shard.Parameters
+	}
+	return info, nil
+}
+
+// firstShardPattern matches the first file of a model split by llama.cpp's
+// gguf-split, e.g. "Qwen3-235B-Q4_K_M-00001-of-00003.gguf".
+var firstShardPattern = regexp.MustCompile(`^(.*)-00001-of-(\d{5})\.gguf$`)
+
+// InspectModel reads the metadata of the model at path. When path is the
+// first file of a split model, the other files, which llama-server loads
+// from the same directory, are included as in InspectSplit.
+func InspectModel(path string) (*Info, error) {
+	m := firstShardPattern.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return Inspect(path)
+	}
+	count, _ := strconv.Atoi(m[2])
+	if count < 2 {
+		return Inspect(path)
+	}
+	paths := []string{path}
+	for i := 2; i <= count; i++ {
+		paths = append(paths, filepath.Join(filepath.Dir(path), fmt.Sprintf("%s-%05d-of-%05d.gguf", m[1], i, count)))
+	}
+	return InspectSplit(paths)
+}
+
+// Inspect reads the metadata of the model file at path.
+func Inspect(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("file not found: %s", path)
+		}
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", path, err)
+	}
+	h, md, br, err := readMetadata(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	info := &Info{Size: fi.Size()}
+	// The parameter count is informational; unreadable tensor infos leave it 0
+	info.Parameters, _ = countParameters(br, h.TensorCount)
+	info.Architecture, _ = md.String("general.architecture")
+	if n, ok := md.Int(info.Architecture + ".block_count"); ok {
+		info.BlockCount = int(n)
+	}
+	if n, ok := md.Int(info.Architecture + ".context_length"); ok {
+		info.ContextLength = int(n)
+	}
+	return info, nil
+}
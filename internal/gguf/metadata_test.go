@@ -0,0 +1,211 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// kv is a metadata entry for building test files.
+type kv struct {
+	key   string
+	typ   uint32
+	value any // written with binary.Write, or string for typeString
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	binary.Write(buf, binary.LittleEndian, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+// modelFile builds a version 3 GGUF file with the given metadata and no tensors.
+func modelFile(kvs ...kv) []byte {
+	buf := bytes.NewBuffer(header(Magic, 3, 0, uint64(len(kvs))))
+	for _, e := range kvs {
+		writeString(buf, e.key)
+		binary.Write(buf, binary.LittleEndian, e.typ)
+		if s, ok := e.value.(string); ok {
+			writeString(buf, s)
+		} else {
+			binary.Write(buf, binary.LittleEndian, e.value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// withTensors returns a copy of the modelFile data with tensor infos of the
+// given shapes appended and counted in the header.
+func withTensors(data []byte, shapes ...[]uint64) []byte {
+	buf := bytes.NewBuffer(bytes.Clone(data))
+	for i, shape := range shapes {
+		writeString(buf, fmt.Sprintf("blk.%d.weight", i))
+		binary.Write(buf, binary.LittleEndian, uint32(len(shape)))
+		binary.Write(buf, binary.LittleEndian, shape)
+		binary.Write(buf, binary.LittleEndian, uint32(0)) // type
+		binary.Write(buf, binary.LittleEndian, uint64(0)) // offset
+	}
+	out := buf.Bytes()
+	binary.LittleEndian.PutUint64(out[8:], uint64(len(shapes)))
+	return out
+}
+
+func TestReadMetadata(t *testing.T) {
+	// Arrange: a string array (like a tokenizer vocabulary) between scalars
+	vocab := new(bytes.Buffer)
+	binary.Write(vocab, binary.LittleEndian, uint32(typeString))
+	binary.Write(vocab, binary.LittleEndian, uint64(2))
+	writeString(vocab, "<s>")
+	writeString(vocab, "</s>")
+
+	data := modelFile(
+		kv{"general.architecture", typeString, "llama"},
+		kv{"llama.block_count", typeUint32, uint32(32)},
+		kv{"tokenizer.ggml.tokens", typeArray, vocab.Bytes()},
+		kv{"llama.rope.freq_base", typeFloat32, float32(10000)},
+		kv{"general.file_type", typeInt32, int32(15)},
+	)
+
+	// Act
+	_, md, err := ReadMetadata(bytes.NewReader(data))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ReadMetadata() error = %v", err)
+	}
+	if arch, _ := md.String("general.architecture"); arch != "llama" {
+		t.Errorf("architecture = %q, want llama", arch)
+	}
+	if n, ok := md.Int("llama.block_count"); !ok || n != 32 {
+		t.Errorf("block_count = %d, %v; want 32", n, ok)
+	}
+	if n, ok := md.Int("general.file_type"); !ok || n != 15 {
+		t.Errorf("file_type = %d, %v; want 15", n, ok)
+	}
+	if v, _ := md["llama.rope.freq_base"].(float64); v != 10000 {
+		t.Errorf("rope.freq_base = %v, want 10000", v)
+	}
+	if _, ok := md["tokenizer.ggml.tokens"]; ok {
+		t.Error("arrays should be skipped")
+	}
+}
+
+func TestReadMetadata_Truncated(t *testing.T) {
+	// Arrange
+	data := modelFile(kv{"general.architecture", typeString, "llama"})
+
+	// Act
+	_, _, err := ReadMetadata(bytes.NewReader(data[:len(data)-2]))
+
+	// Assert
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("ReadMetadata() error = %v, want ErrInvalid", err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	data := modelFile(
+		kv{"general.architecture", typeString, "qwen3"},
+		kv{"qwen3.block_count", typeUint32, uint32(36)},
+		kv{"qwen3.context_length", typeUint32, uint32(40960)},
+	)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	info, err := Inspect(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	want := Info{Size: int64(len(data)), Architecture: "qwen3", BlockCount: 36, ContextLength: 40960}
+	if *info != want {
+		t.Errorf("Inspect() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestInspectSplit(t *testing.T) {
+	// Arrange: the metadata is in the first shard, tensors in both
+	dir := t.TempDir()
+	first := withTensors(modelFile(
+		kv{"general.architecture", typeString, "llama"},
+		kv{"llama.context_length", typeUint32, uint32(131072)},
+	), []uint64{4096, 1000}, []uint64{4096})
+	second := withTensors(modelFile(), []uint64{10, 20, 3})
+	paths := []string{filepath.Join(dir, "m-00001-of-00002.gguf"), filepath.Join(dir, "m-00002-of-00002.gguf")}
+	os.WriteFile(paths[0], first, 0644)
+	os.WriteFile(paths[1], second, 0644)
+
+	// Act
+	info, err := InspectSplit(paths)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("InspectSplit() error = %v", err)
+	}
+	want := Info{Size: int64(len(first) + len(second)), Architecture: "llama", ContextLength: 131072, Parameters: 4096*1000 + 4096 + 600}
+	if *info != want {
+		t.Errorf("InspectSplit() = %+v, want %+v", *info, want)
+	}
+}
+
+func TestInspectModel(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	first := withTensors(modelFile(
+		kv{"general.architecture", typeString, "llama"},
+		kv{"llama.block_count", typeUint32, uint32(32)},
+	), []uint64{4096})
+	second := withTensors(modelFile(), []uint64{10})
+	os.WriteFile(filepath.Join(dir, "m-00001-of-00002.gguf"), first, 0644)
+	os.WriteFile(filepath.Join(dir, "m-00002-of-00002.gguf"), second, 0644)
+	os.WriteFile(filepath.Join(dir, "single.gguf"), first, 0644)
+
+	tests := []struct {
+		name     string
+		file     string
+		wantSize int64
+	}{
+		{"split model counts every shard", "m-00001-of-00002.gguf", int64(len(first) + len(second))},
+		{"single file", "single.gguf", int64(len(first))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			info, err := InspectModel(filepath.Join(dir, tt.file))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("InspectModel() error = %v", err)
+			}
+			if info.Size != tt.wantSize || info.BlockCount != 32 {
+				t.Errorf("InspectModel() = %+v, want Size %d and BlockCount 32", *info, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestInspectModel_MissingShard(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "m-00001-of-00003.gguf")
+	os.WriteFile(path, modelFile(kv{"general.architecture", typeString, "llama"}), 0644)
+	os.WriteFile(filepath.Join(dir, "m-00002-of-00003.gguf"), modelFile(), 0644)
+
+	// Act
+	_, err := InspectModel(path)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "m-00003-of-00003.gguf") {
+		t.Errorf("InspectModel() error = %v, want the missing shard named", err)
+	}
+}
@@ -10,12 +10,18 @@ type Type int
 
 const (
 	TypeUnknown        Type = iota
-	TypeModelFilePath       // f:/path/to/model.gguf
+	TypeModelFilePath       // f:/path/to/model.gguf, or a directory holding one model
 	TypePresetFilePath      // f:/path/to/preset.yaml
 	TypeHuggingFace         // h:org/repo:quant
 	TypePresetName          // p:preset-name
 )
 
+// Registry schemes of models pulled from somewhere other than HuggingFace.
+const (
+	RegistryModelScope = "ms"  // ms:org/repo:quant
+	RegistryOCI        = "oci" // oci://host/repository:tag, an OCI artifact
+)
+
 // Identifier represents a parsed identifier.
 type Identifier struct {
 	Raw  string
@@ -28,16 +34,59 @@ type Identifier struct {
 	Repo  string
 	Quant string
 
+	// Registry is the scheme of the registry a TypeHuggingFace identifier
+	// pulls from: empty for HuggingFace (h:), RegistryModelScope for ms:,
+	// RegistryOCI for oci://. The model is stored and referred to by
+	// repository and quant alike; for OCI artifacts the repository is
+	// host/repository and the quant is the tag.
+	Registry string
+
 	// For TypePresetName
 	PresetName string
 }
 
-// Parse categorizes an identifier using explicit prefixes (h:, p:, f:).
+// Parse categorizes an identifier using explicit prefixes (h:, ms:,
+// oci://, p:, f:).
 func Parse(input string) (*Identifier, error) {
 	if input == "" {
 		return nil, fmt.Errorf("identifier cannot be empty")
 	}
 
+	// OCI artifact: oci://host/repository:tag, the tag defaulting to
+	// "latest" as for container images
+	if value, ok := strings.CutPrefix(input, RegistryOCI+"://"); ok {
+		repo, tag := value, "latest"
+		if i := strings.LastIndex(value, ":"); i > strings.LastIndex(value, "/") {
+			repo, tag = value[:i], value[i+1:]
+		}
+		host, name, _ := strings.Cut(repo, "/")
+		if host == "" || name == "" || tag == "" {
+			return nil, fmt.Errorf("invalid OCI reference '%s'\nExpected: oci://registry/repository:tag", input)
+		}
+		return &Identifier{
+			Raw:      input,
+			Type:     TypeHuggingFace,
+			Repo:     repo,
+			Quant:    tag,
+			Registry: RegistryOCI,
+		}, nil
+	}
+
+	// ModelScope: ms:org/repo:quant, pulled from ModelScope
+	if value, ok := strings.CutPrefix(input, RegistryModelScope+":"); ok {
+		if value == "" {
+			return nil, fmt.Errorf("empty value after prefix '%s:'", RegistryModelScope)
+		}
+		repo, quant, _ := strings.Cut(value, ":")
+		return &Identifier{
+			Raw:      input,
+			Type:     TypeHuggingFace,
+			Repo:     repo,
+			Quant:    quant,
+			Registry: RegistryModelScope,
+		}, nil
+	}
+
 	// Check for valid prefix format (minimum: "x:y")
 	if len(input) < 3 || input[1] != ':' {
 		return nil, fmt.Errorf("invalid identifier format '%s'\nExpected: h:org/repo:quant, p:preset-name, or f:/path/to/file", input)
@@ -84,7 +133,7 @@ func Parse(input string) (*Identifier, error) {
 		}, nil
 
 	default:
-		return nil, fmt.Errorf("unknown prefix '%c:'\nExpected: h: (HuggingFace), p: (preset), or f: (file path)", prefix)
+		return nil, fmt.Errorf("unknown prefix '%c:'\nExpected: h: (HuggingFace), ms: (ModelScope), oci:// (OCI registry), p: (preset), or f: (file path)", prefix)
 	}
 }
 
@@ -94,3 +143,23 @@ func isPresetFile(path string) bool {
 	lower := strings.ToLower(path)
 	return strings.HasSuffix(lower, ".yaml") || strings.HasSuffix(lower, ".yml")
 }
+
+// IsRepoFile reports whether a HuggingFace identifier names a single file in
+// the repository (h:org/repo:adapter.gguf) instead of a quantization.
+// Used for LoRA adapters, which are not published as quants.
+func (id *Identifier) IsRepoFile() bool {
+	return id.Type == TypeHuggingFace && strings.HasSuffix(strings.ToLower(id.Quant), ".gguf")
+}
+
+// Model returns the identifier of quant in repo pulled from registry, an
+// Identifier Registry value ("" or "h" for HuggingFace).
+func Model(registry, repo, quant string) string {
+	switch registry {
+	case "", "h":
+		return "h:" + repo + ":" + quant
+	case RegistryOCI:
+		return RegistryOCI + "://" + repo + ":" + quant
+	default:
+		return registry + ":" + repo + ":" + quant
+	}
+}
@@ -141,12 +141,13 @@ func TestParse_PresetFilePaths(t *testing.T) {
 
 func TestParse_HuggingFace(t *testing.T) {
 	tests := []struct {
-		name      string
-		input     string
-		wantType  Type
-		wantRepo  string
-		wantQuant string
-		wantErr   bool
+		name         string
+		input        string
+		wantType     Type
+		wantRepo     string
+		wantQuant    string
+		wantRegistry string
+		wantErr      bool
 	}{
 		{
 			name:      "valid HF format with quant",
@@ -176,6 +177,38 @@ func TestParse_HuggingFace(t *testing.T) {
 			wantRepo:  "org/repo",
 			wantQuant: "Q4:extra",
 		},
+		{
+			name:         "ModelScope",
+			input:        "ms:Qwen/Qwen3-8B-GGUF:Q4_K_M",
+			wantType:     TypeHuggingFace,
+			wantRepo:     "Qwen/Qwen3-8B-GGUF",
+			wantQuant:    "Q4_K_M",
+			wantRegistry: RegistryModelScope,
+		},
+		{
+			name:         "OCI artifact",
+			input:        "oci://ghcr.io/org/model:q4",
+			wantType:     TypeHuggingFace,
+			wantRepo:     "ghcr.io/org/model",
+			wantQuant:    "q4",
+			wantRegistry: RegistryOCI,
+		},
+		{
+			name:         "OCI artifact without tag",
+			input:        "oci://ghcr.io/org/model",
+			wantType:     TypeHuggingFace,
+			wantRepo:     "ghcr.io/org/model",
+			wantQuant:    "latest",
+			wantRegistry: RegistryOCI,
+		},
+		{
+			name:         "OCI artifact on registry with port",
+			input:        "oci://localhost:5000/model",
+			wantType:     TypeHuggingFace,
+			wantRepo:     "localhost:5000/model",
+			wantQuant:    "latest",
+			wantRegistry: RegistryOCI,
+		},
 	}
 
 	for _, tt := range tests {
@@ -199,6 +232,9 @@ func TestParse_HuggingFace(t *testing.T) {
 			if id.Quant != tt.wantQuant {
 				t.Errorf("Quant = %v, want %v", id.Quant, tt.wantQuant)
 			}
+			if id.Registry != tt.wantRegistry {
+				t.Errorf("Registry = %v, want %v", id.Registry, tt.wantRegistry)
+			}
 			if id.Raw != tt.input {
 				t.Errorf("Raw = %v, want %v", id.Raw, tt.input)
 			}
@@ -289,6 +325,16 @@ func TestParse_Errors(t *testing.T) {
 			input:   "p:",
 			wantErr: "invalid identifier format",
 		},
+		{
+			name:    "empty value after ModelScope prefix",
+			input:   "ms:",
+			wantErr: "empty value after prefix 'ms:'",
+		},
+		{
+			name:    "OCI reference without repository",
+			input:   "oci://ghcr.io:q4",
+			wantErr: "invalid OCI reference",
+		},
 		{
 			name:    "old format without prefix",
 			input:   "org/repo:quant",
@@ -313,3 +359,49 @@ func TestParse_Errors(t *testing.T) {
 		})
 	}
 }
+
+func TestIdentifier_IsRepoFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"quant", "h:org/repo:Q4_K_M", false},
+		{"gguf file", "h:org/repo:adapter.gguf", true},
+		{"uppercase extension", "h:org/repo:ADAPTER.GGUF", true},
+		{"missing quant", "h:org/repo", false},
+		{"file path", "f:/path/adapter.gguf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := id.IsRepoFile(); got != tt.want {
+				t.Errorf("IsRepoFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		registry string
+		want     string
+	}{
+		{"", "h:org/repo:Q4_K_M"},
+		{"h", "h:org/repo:Q4_K_M"},
+		{RegistryModelScope, "ms:org/repo:Q4_K_M"},
+		{RegistryOCI, "oci://org/repo:Q4_K_M"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Model(tt.registry, "org/repo", "Q4_K_M"); got != tt.want {
+				t.Errorf("Model(%q) = %q, want %q", tt.registry, got, tt.want)
+			}
+		})
+	}
+}
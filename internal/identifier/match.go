@@ -0,0 +1,141 @@
+package identifier
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// HasPrefix reports whether input starts with a type prefix (h:, p:, f:
+// or any other single letter followed by a colon) or a registry prefix
+// (ms:, oci://).
+func HasPrefix(input string) bool {
+	return len(input) >= 2 && input[1] == ':' ||
+		strings.HasPrefix(input, RegistryModelScope+":") || strings.HasPrefix(input, RegistryOCI+"://")
+}
+
+// IsPresetPath reports whether input is a preset file path given without
+// the f: prefix, such as ./workspace.yaml.
+func IsPresetPath(input string) bool {
+	return !HasPrefix(input) && isPresetFile(input)
+}
+
+// Match returns the candidate identifiers (p:name, h:org/repo:quant) that
+// a name without prefix refers to, best first. Names are compared without
+// regard to case against a preset's name and a model's repo, with or
+// without its org and quant:
+//
+//  1. candidates the name equals exactly;
+//  2. otherwise, candidates the name is a prefix of;
+//  3. otherwise, candidates within a small edit distance, to forgive typos.
+//
+// More than one result means the name is ambiguous.
+func Match(name string, candidates []string) []string {
+	name = strings.ToLower(name)
+	if name == "" {
+		return nil
+	}
+
+	var exact []string
+	var prefix, nearby []scored
+	maxDist := 1
+	if len(name) >= 6 {
+		maxDist = 2
+	}
+	for _, c := range candidates {
+		keys := matchKeys(c)
+		switch {
+		case slices.Contains(keys, name):
+			exact = append(exact, c)
+		case slices.ContainsFunc(keys, func(k string) bool { return strings.HasPrefix(k, name) }):
+			shortest := -1
+			for _, k := range keys {
+				if strings.HasPrefix(k, name) && (shortest < 0 || len(k) < shortest) {
+					shortest = len(k)
+				}
+			}
+			prefix = append(prefix, scored{c, shortest})
+		default:
+			best := maxDist + 1
+			for _, k := range keys {
+				best = min(best, editDistance(name, k))
+			}
+			if best <= maxDist {
+				nearby = append(nearby, scored{c, best})
+			}
+		}
+	}
+
+	switch {
+	case len(exact) > 0:
+		return exact
+	case len(prefix) > 0:
+		return byDistance(prefix)
+	}
+	return byDistance(nearby)
+}
+
+// scored is a candidate with its distance from the name matched: the
+// length of the shortest key the name is a prefix of, or the edit distance
+// to the closest key.
+type scored struct {
+	id   string
+	dist int
+}
+
+// byDistance returns the identifiers of matches, closest first.
+func byDistance(matches []scored) []string {
+	slices.SortStableFunc(matches, func(a, b scored) int { return cmp.Compare(a.dist, b.dist) })
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+	return ids
+}
+
+// matchKeys returns the lowercase names a candidate can be referred to by.
+func matchKeys(id string) []string {
+	id = strings.ToLower(id)
+	switch {
+	case strings.HasPrefix(id, "p:"):
+		return []string{id[2:]}
+	case strings.HasPrefix(id, "h:"):
+		repo, quant, _ := strings.Cut(id[2:], ":")
+		keys := []string{repo, repo + ":" + quant}
+		if _, name, ok := strings.Cut(repo, "/"); ok {
+			keys = append(keys, name, name+":"+quant)
+		}
+		return keys
+	}
+	return nil
+}
+
+// editDistance returns the number of single-character insertions,
+// deletions, substitutions and transpositions of adjacent characters that
+// turn a into b (optimal string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// d[i][j] is the distance between the first i runes of a and the first
+	// j runes of b.
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,110 @@
+package identifier
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHasPrefix(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"p:coder", true},
+		{"h:org/repo:Q4_K_M", true},
+		{"f:/models/m.gguf", true},
+		{"ms:org/repo:Q4_K_M", true},
+		{"oci://ghcr.io/org/model:q4", true},
+		{"coder", false},
+		{"q", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := HasPrefix(tt.input); got != tt.want {
+				t.Errorf("HasPrefix(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPresetPath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"./workspace.yaml", true},
+		{"presets/chat.YML", true},
+		{"f:./workspace.yaml", false},
+		{"p:chat", false},
+		{"chat", false},
+		{"model.gguf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsPresetPath(tt.input); got != tt.want {
+				t.Errorf("IsPresetPath(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	candidates := []string{
+		"p:qwen3-coder",
+		"p:chat",
+		"p:chat-long",
+		"h:Qwen/Qwen3-8B-GGUF:Q4_K_M",
+		"h:Qwen/Qwen3-8B-GGUF:Q8_0",
+		"h:unsloth/gemma-3-4b-it-GGUF:Q4_K_M",
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"exact preset beats prefix", "chat", []string{"p:chat"}},
+		{"unique prefix", "gemma", []string{"h:unsloth/gemma-3-4b-it-GGUF:Q4_K_M"}},
+		{"case-insensitive", "QWEN3-C", []string{"p:qwen3-coder"}},
+		{"repo with org", "qwen/qwen3-8b-gguf", []string{"h:Qwen/Qwen3-8B-GGUF:Q4_K_M", "h:Qwen/Qwen3-8B-GGUF:Q8_0"}},
+		{"repo and quant", "qwen3-8b-gguf:q8_0", []string{"h:Qwen/Qwen3-8B-GGUF:Q8_0"}},
+		{"ambiguous prefix", "qwen3", []string{"p:qwen3-coder", "h:Qwen/Qwen3-8B-GGUF:Q4_K_M", "h:Qwen/Qwen3-8B-GGUF:Q8_0"}},
+		{"typo", "caht", []string{"p:chat"}},
+		{"typo in long name", "qwen3-codr", []string{"p:qwen3-coder"}},
+		{"no match", "llama", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := Match(tt.input, candidates)
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Match(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"chat", "chat", 0},
+		{"caht", "chat", 1},
+		{"chat", "chats", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
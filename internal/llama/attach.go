@@ -0,0 +1,62 @@
+package llama
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// attachPollInterval is how often an attached process is checked for exit.
+const attachPollInterval = 500 * time.Millisecond
+
+// AttachProcess returns a Process managing an already running llama-server
+// that was not started by this process (e.g. one left behind by a crashed
+// daemon). Its output cannot be captured and ExitErr is always nil.
+func AttachProcess(pid int) (*Process, error) {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return nil, fmt.Errorf("find process %d: %w", pid, err)
+	}
+
+	p := &Process{
+		cmd:  &exec.Cmd{Process: proc},
+		done: make(chan struct{}),
+	}
+	// Not our child, so Wait is unavailable; poll until it disappears.
+	go func() {
+		for processExists(proc) {
+			time.Sleep(attachPollInterval)
+		}
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// Pid returns the process ID, or 0 if the process has not been started.
+func (p *Process) Pid() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.cmd == nil || p.cmd.Process == nil {
+		return 0
+	}
+	return p.cmd.Process.Pid
+}
+
+// CommandLine returns the space-joined argv of a running process.
+func CommandLine(pid int) (string, error) {
+	out, err := exec.Command("ps", "-ww", "-o", "args=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("read command line of %d: %w", pid, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func processExists(proc *os.Process) bool {
+	err := proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
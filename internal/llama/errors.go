@@ -3,6 +3,7 @@ package llama
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // ProcessOp represents a llama-server process operation.
@@ -13,14 +14,25 @@ const (
 	ProcessOpWait  ProcessOp = "wait"
 )
 
+// OutputHeader introduces the llama-server output in ProcessError messages.
+const OutputHeader = "llama-server output:"
+
 // ProcessError indicates a llama-server process operation failed.
 type ProcessError struct {
 	Op  ProcessOp
 	Err error
+
+	// Output holds the last lines llama-server wrote to stderr when it
+	// exited unexpectedly; they usually name the cause.
+	Output []string
 }
 
 func (e *ProcessError) Error() string {
-	return fmt.Sprintf("%s llama-server: %v", e.Op, e.Err)
+	msg := fmt.Sprintf("%s llama-server: %v", e.Op, e.Err)
+	if len(e.Output) > 0 {
+		msg += "\n" + OutputHeader + "\n  " + strings.Join(e.Output, "\n  ")
+	}
+	return msg
 }
 
 func (e *ProcessError) Unwrap() error {
@@ -2,42 +2,263 @@ package llama
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
 const (
-	// HealthCheckInterval is the interval between health checks.
+	// HealthCheckInterval is the interval between warm-up attempts.
 	HealthCheckInterval = 500 * time.Millisecond
 	// HealthCheckTimeout is the timeout for a single health check.
 	HealthCheckTimeout = 5 * time.Second
 )
 
-// WaitForReady waits until the llama-server is ready to accept requests.
-func WaitForReady(ctx context.Context, endpoint string) error {
-	healthURL := endpoint + "/health"
-	client := &http.Client{Timeout: HealthCheckTimeout}
+// Health check backoff. Until llama-server opens its port, connections are
+// refused and it is probed often, since opening the port is quick. Once it
+// answers that the model is still loading, probes back off: large models
+// take minutes, and each probe is a request llama-server has to serve.
+const (
+	refusedProbeMin = 50 * time.Millisecond
+	refusedProbeMax = 500 * time.Millisecond
+	loadingProbeMin = 250 * time.Millisecond
+	loadingProbeMax = 2 * time.Second
+)
+
+// ErrExited is returned by WaitForReady when llama-server exited before it
+// became ready.
+var ErrExited = errors.New("llama-server exited")
+
+// HealthError is llama-server reporting an error from /health, such as a
+// model that failed to load, instead of loading or being ready.
+type HealthError struct {
+	Status  int    // HTTP status
+	Message string // llama-server's description, if any
+}
+
+func (e *HealthError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("llama-server health check failed (HTTP %d)", e.Status)
+	}
+	return fmt.Sprintf("llama-server health check failed: %s (HTTP %d)", e.Message, e.Status)
+}
+
+// WaitOptions holds optional parameters for WaitForReady.
+type WaitOptions struct {
+	// Exited is closed when llama-server exits. A refused connection after
+	// that ends the wait with ErrExited rather than probing until the
+	// context ends.
+	Exited <-chan struct{}
+
+	// Progress, if set, is called with what llama-server reports while it
+	// is not ready, such as "loading model" or "loading model 43%", each
+	// time that changes.
+	Progress func(string)
+
+	// Check replaces the probe of llama-server's GET /health, for servers
+	// and proxies in front of them that report readiness elsewhere.
+	Check HealthCheck
+}
+
+// HealthCheck describes the readiness probe. Zero fields keep the
+// llama-server defaults.
+type HealthCheck struct {
+	Path         string            // default "/health"
+	Method       string            // GET (default) or HEAD
+	ExpectStatus int               // status of a ready server; default 200
+	ExpectJSON   map[string]string // response fields (dotted paths) and the values they hold when ready
+}
 
-	ticker := time.NewTicker(HealthCheckInterval)
-	defer ticker.Stop()
+func (c HealthCheck) path() string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return "/health"
+}
+
+func (c HealthCheck) method() string {
+	if c.Method != "" {
+		return c.Method
+	}
+	return http.MethodGet
+}
+
+func (c HealthCheck) expectStatus() int {
+	if c.ExpectStatus != 0 {
+		return c.ExpectStatus
+	}
+	return http.StatusOK
+}
+
+// unmatched returns the first ExpectJSON field that body does not hold, as
+// "field=value", or "" when all match. Strings are compared as is, other
+// JSON values by their JSON text.
+func (c HealthCheck) unmatched(body []byte) string {
+	if len(c.ExpectJSON) == 0 {
+		return ""
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		doc = nil
+	}
+	keys := make([]string, 0, len(c.ExpectJSON))
+	for k := range c.ExpectJSON {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if jsonField(doc, k) != c.ExpectJSON[k] {
+			return k + "=" + c.ExpectJSON[k]
+		}
+	}
+	return ""
+}
 
+// jsonField returns the value at a dotted path such as "data.status" in
+// doc as text, or "" when there is none.
+func jsonField(doc any, path string) string {
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return ""
+		}
+		if doc, ok = obj[key]; !ok {
+			return ""
+		}
+	}
+	switch v := doc.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		text, _ := json.Marshal(v)
+		return string(text)
+	}
+}
+
+// WaitForReady waits until the llama-server is ready to accept requests:
+// until opts.Check answers with its expected status and fields. It fails
+// early when llama-server exits or reports an error.
+func WaitForReady(ctx context.Context, endpoint string, opts WaitOptions) error {
+	healthURL := endpoint + opts.Check.path()
+	client := &http.Client{Timeout: HealthCheckTimeout}
+
+	var delay, refusedDelay, loadingDelay time.Duration
+	var reported string
 	for {
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+		req, err := http.NewRequestWithContext(ctx, opts.Check.method(), healthURL, nil)
 		if err != nil {
 			return err
 		}
 		resp, err := client.Do(req)
-		if err == nil {
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			select {
+			case <-opts.Exited:
+				return ErrExited
+			default:
+			}
+			refusedDelay = nextDelay(refusedDelay, refusedProbeMin, refusedProbeMax)
+			delay = refusedDelay
+		} else {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
 			resp.Body.Close()
-			if resp.StatusCode == http.StatusOK {
-				return nil
+			h := parseHealth(resp.StatusCode, body)
+			desc := h.String()
+			if resp.StatusCode == opts.Check.expectStatus() {
+				unmatched := opts.Check.unmatched(body)
+				if unmatched == "" {
+					return nil
+				}
+				desc = "waiting for " + unmatched
+			} else if h.failed() {
+				return &HealthError{Status: resp.StatusCode, Message: h.message}
+			}
+			if desc != reported {
+				reported = desc
+				if opts.Progress != nil {
+					opts.Progress(desc)
+				}
 			}
+			loadingDelay = nextDelay(loadingDelay, loadingProbeMin, loadingProbeMax)
+			delay = loadingDelay
 		}
 
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
+		case <-opts.Exited:
+			return ErrExited
+		case <-time.After(delay):
 		}
 	}
 }
+
+// nextDelay doubles delay within [lo, hi].
+func nextDelay(delay, lo, hi time.Duration) time.Duration {
+	return min(max(delay*2, lo), hi)
+}
+
+// health is llama-server's answer to a health check that was not ready.
+type health struct {
+	status   int
+	message  string  // e.g. "Loading model"
+	progress float64 // fraction of the model loaded; negative when not reported
+}
+
+// parseHealth reads a /health response body. llama-server answers
+// {"error": {"message": "Loading model"}} while loading, and older builds
+// {"status": "loading model"}; either may carry a "progress" fraction.
+func parseHealth(status int, body []byte) health {
+	var raw struct {
+		Status   string   `json:"status"`
+		Progress *float64 `json:"progress"`
+		Error    struct {
+			Message  string   `json:"message"`
+			Progress *float64 `json:"progress"`
+		} `json:"error"`
+	}
+	h := health{status: status, progress: -1}
+	if json.Unmarshal(body, &raw) != nil {
+		return h
+	}
+	h.message = raw.Error.Message
+	if h.message == "" {
+		h.message = raw.Status
+	}
+	if p := raw.Progress; p != nil {
+		h.progress = *p
+	} else if p := raw.Error.Progress; p != nil {
+		h.progress = *p
+	}
+	return h
+}
+
+// failed reports whether llama-server answered with an error rather than
+// that it is still loading.
+func (h health) failed() bool {
+	if strings.EqualFold(h.message, "error") {
+		return true
+	}
+	return h.status >= 500 && h.status != http.StatusServiceUnavailable
+}
+
+// String describes the state, e.g. "loading model 43%".
+func (h health) String() string {
+	desc := strings.ToLower(h.message)
+	if desc == "" {
+		desc = "starting"
+	}
+	if h.progress >= 0 && h.progress <= 1 {
+		desc += fmt.Sprintf(" %d%%", int(h.progress*100))
+	}
+	return desc
+}
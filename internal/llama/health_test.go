@@ -2,8 +2,10 @@ package llama
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -22,7 +24,7 @@ func TestWaitForReady_Success(t *testing.T) {
 	ctx := context.Background()
 
 	// Act
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 
 	// Assert
 	if err != nil {
@@ -50,7 +52,7 @@ func TestWaitForReady_RetryThenSuccess(t *testing.T) {
 
 	// Act
 	start := time.Now()
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 	elapsed := time.Since(start)
 
 	// Assert
@@ -79,7 +81,7 @@ func TestWaitForReady_ContextTimeout(t *testing.T) {
 	defer cancel()
 
 	// Act
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 
 	// Assert
 	if err == nil {
@@ -107,7 +109,7 @@ func TestWaitForReady_ContextCanceled(t *testing.T) {
 	}()
 
 	// Act
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 
 	// Assert
 	if err == nil {
@@ -127,7 +129,7 @@ func TestWaitForReady_NetworkError(t *testing.T) {
 	invalidEndpoint := "http://localhost:9999"
 
 	// Act
-	err := WaitForReady(ctx, invalidEndpoint)
+	err := WaitForReady(ctx, invalidEndpoint, WaitOptions{})
 
 	// Assert
 	if err == nil {
@@ -153,7 +155,7 @@ func TestWaitForReady_ImmediateSuccess(t *testing.T) {
 
 	// Act
 	start := time.Now()
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 	elapsed := time.Since(start)
 
 	// Assert
@@ -176,12 +178,12 @@ func TestWaitForReady_MultipleStatusChanges(t *testing.T) {
 
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		count := callCount.Add(1)
-		// Pattern: 503, 500, 503, 200
+		// Pattern: 503, 404, 503, 200
 		switch count {
 		case 1:
 			w.WriteHeader(http.StatusServiceUnavailable)
 		case 2:
-			w.WriteHeader(http.StatusInternalServerError)
+			w.WriteHeader(http.StatusNotFound)
 		case 3:
 			w.WriteHeader(http.StatusServiceUnavailable)
 		default:
@@ -193,7 +195,7 @@ func TestWaitForReady_MultipleStatusChanges(t *testing.T) {
 	ctx := context.Background()
 
 	// Act
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 
 	// Assert
 	if err != nil {
@@ -216,10 +218,186 @@ func TestWaitForReady_SlowServer(t *testing.T) {
 	ctx := context.Background()
 
 	// Act
-	err := WaitForReady(ctx, mockServer.URL)
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
 
 	// Assert
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestWaitForReady_ReportsProgress(t *testing.T) {
+	// Arrange
+	var callCount atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch callCount.Add(1) {
+		case 1, 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":{"code":503,"message":"Loading model","type":"unavailable_error"}}`))
+		case 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"loading model","progress":0.43}`))
+		default:
+			w.Write([]byte(`{"status":"ok"}`))
+		}
+	}))
+	defer mockServer.Close()
+	var reports []string
+
+	// Act
+	err := WaitForReady(context.Background(), mockServer.URL, WaitOptions{
+		Progress: func(s string) { reports = append(reports, s) },
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"loading model", "loading model 43%"}; !slices.Equal(reports, want) {
+		t.Errorf("progress = %q, want %q", reports, want)
+	}
+}
+
+func TestWaitForReady_HealthError(t *testing.T) {
+	// Arrange
+	var callCount atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":"error","error":"Model failed to load"}`))
+	}))
+	defer mockServer.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Act
+	err := WaitForReady(ctx, mockServer.URL, WaitOptions{})
+
+	// Assert
+	var healthErr *HealthError
+	if !errors.As(err, &healthErr) || healthErr.Status != http.StatusInternalServerError {
+		t.Fatalf("WaitForReady() error = %v, want *HealthError", err)
+	}
+	if callCount.Load() != 1 {
+		t.Errorf("health checks = %d, want 1: errors should not be retried", callCount.Load())
+	}
+}
+
+func TestWaitForReady_Exited(t *testing.T) {
+	// Arrange - nothing listens, and the process has already exited
+	exited := make(chan struct{})
+	close(exited)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Act
+	start := time.Now()
+	err := WaitForReady(ctx, "http://localhost:9999", WaitOptions{Exited: exited})
+
+	// Assert
+	if !errors.Is(err, ErrExited) {
+		t.Fatalf("WaitForReady() error = %v, want ErrExited", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v, want an immediate failure", elapsed)
+	}
+}
+
+func TestParseHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		want       string
+		wantFailed bool
+	}{
+		{"loading", 503, `{"error":{"code":503,"message":"Loading model","type":"unavailable_error"}}`, "loading model", false},
+		{"loading with progress", 503, `{"status":"loading model","progress":0.5}`, "loading model 50%", false},
+		{"not json", 503, `Service Unavailable`, "starting", false},
+		{"error status", 200, `{"status":"error"}`, "error", true},
+		{"server error", 500, ``, "starting", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			h := parseHealth(tt.status, []byte(tt.body))
+
+			// Assert
+			if got := h.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := h.failed(); got != tt.wantFailed {
+				t.Errorf("failed() = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestWaitForReady_CustomCheck(t *testing.T) {
+	// Arrange - a sidecar that answers 204 on /ready, and reports ready in
+	// its body only from the third probe
+	var callCount atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/ready" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		if callCount.Add(1) < 3 {
+			w.Write([]byte(`{"backend":{"ready":false}}`))
+			return
+		}
+		w.Write([]byte(`{"backend":{"ready":true,"name":"llama"}}`))
+	}))
+	defer mockServer.Close()
+	var reports []string
+
+	// Act
+	err := WaitForReady(context.Background(), mockServer.URL, WaitOptions{
+		Progress: func(s string) { reports = append(reports, s) },
+		Check: HealthCheck{
+			Path:         "/ready",
+			ExpectStatus: http.StatusAccepted,
+			ExpectJSON:   map[string]string{"backend.ready": "true", "backend.name": "llama"},
+		},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callCount.Load() != 3 {
+		t.Errorf("health checks = %d, want 3", callCount.Load())
+	}
+	if want := []string{"waiting for backend.name=llama"}; !slices.Equal(reports, want) {
+		t.Errorf("progress = %q, want %q", reports, want)
+	}
+}
+
+func TestHealthCheck_Unmatched(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect map[string]string
+		body   string
+		want   string
+	}{
+		{name: "no fields", body: "not json", want: ""},
+		{name: "string match", expect: map[string]string{"status": "ok"}, body: `{"status":"ok"}`, want: ""},
+		{name: "number match", expect: map[string]string{"slots": "4"}, body: `{"slots":4}`, want: ""},
+		{name: "nested mismatch", expect: map[string]string{"a.b": "true"}, body: `{"a":{"b":false}}`, want: "a.b=true"},
+		{name: "missing field", expect: map[string]string{"status": "ok"}, body: `{}`, want: "status=ok"},
+		{name: "not json", expect: map[string]string{"status": "ok"}, body: "ok", want: "status=ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := HealthCheck{ExpectJSON: tt.expect}.unmatched([]byte(tt.body))
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("unmatched() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
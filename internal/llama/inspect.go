@@ -0,0 +1,45 @@
+package llama
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+// ProcessInfo describes a running llama-server.
+type ProcessInfo struct {
+	PID       int
+	StartedAt time.Time // zero when it could not be read
+	RSS       int64     // resident memory in bytes; -1 when it could not be read
+	Port      int       // from --port; 0 when not given
+	Model     string    // from -m; empty for a router, which has --models-preset
+}
+
+// Process inspection, replaceable in tests.
+var (
+	processStart = sysinfo.DetectProcessStart
+	processUsage = sysinfo.DetectProcessUsage
+)
+
+// Inspect describes llama-server process pid, started with args. Values
+// that cannot be read from the system are left unset rather than failing,
+// since a process may exit while it is inspected.
+func Inspect(pid int, args []string) ProcessInfo {
+	info := ProcessInfo{PID: pid, RSS: -1}
+	if started, err := processStart(pid); err == nil {
+		info.StartedAt = started
+	}
+	if u, err := processUsage(pid); err == nil {
+		info.RSS = u.RSS
+	}
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--port":
+			info.Port, _ = strconv.Atoi(args[i+1])
+		case "-m", "--model":
+			info.Model = args[i+1]
+		}
+	}
+	return info
+}
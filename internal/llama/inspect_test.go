@@ -0,0 +1,65 @@
+package llama
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+func TestInspect(t *testing.T) {
+	started := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		args     []string
+		readable bool
+		want     ProcessInfo
+	}{
+		{
+			name:     "single model",
+			args:     []string{"-m", "/models/qwen3.gguf", "--ctx-size", "4096", "--port", "8080", "--host", "127.0.0.1"},
+			readable: true,
+			want:     ProcessInfo{PID: 4242, StartedAt: started, RSS: 4 << 30, Port: 8080, Model: "/models/qwen3.gguf"},
+		},
+		{
+			name:     "router",
+			args:     []string{"--models-preset", "/tmp/config.ini", "--port", "8081"},
+			readable: true,
+			want:     ProcessInfo{PID: 4242, StartedAt: started, RSS: 4 << 30, Port: 8081},
+		},
+		{
+			name: "process gone",
+			args: []string{"-m", "/models/qwen3.gguf", "--port", "8080"},
+			want: ProcessInfo{PID: 4242, RSS: -1, Port: 8080, Model: "/models/qwen3.gguf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			origStart, origUsage := processStart, processUsage
+			t.Cleanup(func() { processStart, processUsage = origStart, origUsage })
+			processStart = func(int) (time.Time, error) {
+				if !tt.readable {
+					return time.Time{}, errors.New("no such process")
+				}
+				return started, nil
+			}
+			processUsage = func(int) (sysinfo.ProcessUsage, error) {
+				if !tt.readable {
+					return sysinfo.ProcessUsage{}, errors.New("no such process")
+				}
+				return sysinfo.ProcessUsage{RSS: 4 << 30}, nil
+			}
+
+			// Act
+			got := Inspect(4242, tt.args)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Inspect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package llama
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// helpTimeout bounds running llama-server --help.
+const helpTimeout = 10 * time.Second
+
+// ErrUnknownOption is returned by OptionSchema.Check for options that
+// llama-server does not accept.
+var ErrUnknownOption = errors.New("unknown llama-server option")
+
+// OptionKind is the kind of value a llama-server option takes.
+type OptionKind string
+
+const (
+	OptionFlag   OptionKind = "flag"   // no value, e.g. --mlock
+	OptionNumber OptionKind = "number" // N, e.g. --ctx-size N
+	OptionEnum   OptionKind = "enum"   // one of Values, e.g. --flash-attn [on|off|auto]
+	OptionString OptionKind = "string" // anything else, e.g. --lora FNAME
+)
+
+// OptionSpec describes the value of a llama-server option.
+type OptionSpec struct {
+	Kind   OptionKind `json:"kind"`
+	Values []string   `json:"values,omitempty"` // for OptionEnum
+}
+
+// OptionSchema maps long option names (without "--") to their values.
+type OptionSchema map[string]OptionSpec
+
+// optionLinePattern matches the option names and value hint at the start
+// of a llama-server --help line, e.g. "-c,    --ctx-size N   size of...".
+var optionLinePattern = regexp.MustCompile(`^\s*(-{1,2}[A-Za-z0-9][\w-]*(?:,\s+-{1,2}[A-Za-z0-9][\w-]*)*)(?: (\S+))?(?:\s{2,}|\s*$)`)
+
+// ParseHelp builds the option schema from llama-server --help output.
+// Value kinds are inferred from the hint after the names: none is a flag,
+// "N" a number, "[a|b]" or "{a,b}" an enum, and anything else a string.
+func ParseHelp(help string) OptionSchema {
+	schema := OptionSchema{}
+	for line := range strings.Lines(help) {
+		m := optionLinePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		spec := parseValueHint(m[2])
+		for name := range strings.SplitSeq(m[1], ",") {
+			name = strings.TrimSpace(name)
+			if long, ok := strings.CutPrefix(name, "--"); ok {
+				schema[long] = spec
+			}
+		}
+	}
+	return schema
+}
+
+func parseValueHint(hint string) OptionSpec {
+	switch {
+	case hint == "":
+		return OptionSpec{Kind: OptionFlag}
+	case hint == "N":
+		return OptionSpec{Kind: OptionNumber}
+	case len(hint) > 2 && hint[0] == '[' && hint[len(hint)-1] == ']' && strings.Contains(hint, "|"):
+		return OptionSpec{Kind: OptionEnum, Values: strings.Split(hint[1:len(hint)-1], "|")}
+	case len(hint) > 2 && hint[0] == '{' && hint[len(hint)-1] == '}' && strings.Contains(hint, ","):
+		return OptionSpec{Kind: OptionEnum, Values: strings.Split(hint[1:len(hint)-1], ",")}
+	default:
+		return OptionSpec{Kind: OptionString}
+	}
+}
+
+// Check reports whether key is a known option and value fits its kind.
+// Values are as written in a preset: "true" passes a flag and "false"
+// omits the option. Unknown keys return an error wrapping ErrUnknownOption.
+func (s OptionSchema) Check(key, value string) error {
+	spec, ok := s[key]
+	if !ok {
+		if similar := s.similar(key); similar != "" {
+			return fmt.Errorf("%w '%s' (did you mean '%s'?)", ErrUnknownOption, key, similar)
+		}
+		return fmt.Errorf("%w '%s'", ErrUnknownOption, key)
+	}
+	if value == "false" {
+		return nil
+	}
+	switch spec.Kind {
+	case OptionFlag:
+		if value != "true" {
+			return fmt.Errorf("option '%s' is a flag and takes true or false, got '%s'", key, value)
+		}
+	case OptionNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("option '%s' expects a number, got '%s'", key, value)
+		}
+	case OptionEnum:
+		if !slices.Contains(spec.Values, value) {
+			return fmt.Errorf("option '%s' expects one of %s, got '%s'", key, strings.Join(spec.Values, ", "), value)
+		}
+	}
+	return nil
+}
+
+// similar returns the known option closest to key when it is likely a
+// typo of it, or "".
+func (s OptionSchema) similar(key string) string {
+	bare := strings.ReplaceAll(key, "-", "")
+	best, bestDist := "", min(3, (len(bare)+1)/2)
+	for _, name := range slices.Sorted(maps.Keys(s)) {
+		d := editDistance(bare, strings.ReplaceAll(name, "-", ""))
+		if d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Schema returns the option schema of the llama-server binary command,
+// read from its --help output.
+func Schema(ctx context.Context, command string) (OptionSchema, error) {
+	ctx, cancel := context.WithTimeout(ctx, helpTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, command, "--help").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("run %s --help: %w", command, err)
+	}
+	schema := ParseHelp(string(out))
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("no options found in %s --help output", command)
+	}
+	return schema, nil
+}
+
+// SchemaCache keeps the option schema of each llama-server binary in
+// memory and in a directory, so --help runs once per build of a binary.
+// A binary is identified by its path, size and modification time.
+type SchemaCache struct {
+	dir string
+
+	mu      sync.Mutex
+	schemas map[string]OptionSchema // by binary key
+}
+
+// NewSchemaCache returns a cache that stores schemas in dir. An empty dir
+// keeps them in memory only.
+func NewSchemaCache(dir string) *SchemaCache {
+	return &SchemaCache{dir: dir, schemas: map[string]OptionSchema{}}
+}
+
+// Load returns the option schema of command (a name looked up in PATH or
+// a path), running its --help if the binary has not been seen before.
+func (c *SchemaCache) Load(ctx context.Context, command string) (OptionSchema, error) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return nil, err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%d\x00%d", path, info.Size(), info.ModTime().UnixNano()))
+	key := hex.EncodeToString(sum[:8])
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if schema, ok := c.schemas[key]; ok {
+		return schema, nil
+	}
+	file := filepath.Join(c.dir, "llama-server-options-"+key+".json")
+	if c.dir != "" {
+		if data, err := os.ReadFile(file); err == nil {
+			var schema OptionSchema
+			if json.Unmarshal(data, &schema) == nil && len(schema) > 0 {
+				c.schemas[key] = schema
+				return schema, nil
+			}
+		}
+	}
+
+	schema, err := Schema(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	c.schemas[key] = schema
+	if c.dir != "" {
+		// Best effort: a schema that cannot be saved is read again next time.
+		if data, err := json.Marshal(schema); err == nil && os.MkdirAll(c.dir, 0755) == nil {
+			tmp := file + ".tmp"
+			if os.WriteFile(tmp, data, 0644) == nil {
+				os.Rename(tmp, file)
+			}
+		}
+	}
+	return schema, nil
+}
@@ -0,0 +1,116 @@
+package llama
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testHelp = `----- common params -----
+
+-h,    --help, --usage                      print usage and exit
+-c,    --ctx-size N                         size of the prompt context (default: 4096)
+-fa,   --flash-attn [on|off|auto]           set Flash Attention use
+--mlock                                     force system to keep model in RAM
+--mmap, --no-mmap                           whether to memory-map model (default: enabled)
+                                            (env: LLAMA_ARG_MMAP)
+-sm,   --split-mode {none,layer,row}        how to split the model across multiple GPUs
+--lora FNAME                                path to LoRA adapter
+`
+
+func TestParseHelp(t *testing.T) {
+	// Act
+	schema := ParseHelp(testHelp)
+
+	// Assert
+	want := OptionSchema{
+		"help":       {Kind: OptionFlag},
+		"usage":      {Kind: OptionFlag},
+		"ctx-size":   {Kind: OptionNumber},
+		"flash-attn": {Kind: OptionEnum, Values: []string{"on", "off", "auto"}},
+		"mlock":      {Kind: OptionFlag},
+		"mmap":       {Kind: OptionFlag},
+		"no-mmap":    {Kind: OptionFlag},
+		"split-mode": {Kind: OptionEnum, Values: []string{"none", "layer", "row"}},
+		"lora":       {Kind: OptionString},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("ParseHelp() = %v, want %v", schema, want)
+	}
+}
+
+func TestOptionSchema_Check(t *testing.T) {
+	schema := ParseHelp(testHelp)
+
+	tests := []struct {
+		key, value string
+		wantErr    string // empty for no error
+	}{
+		{"ctx-size", "8192", ""},
+		{"ctx-size", "false", ""},
+		{"ctx-size", "8k", "option 'ctx-size' expects a number, got '8k'"},
+		{"mlock", "true", ""},
+		{"mlock", "1", "option 'mlock' is a flag and takes true or false, got '1'"},
+		{"flash-attn", "auto", ""},
+		{"flash-attn", "yes", "option 'flash-attn' expects one of on, off, auto, got 'yes'"},
+		{"lora", "/path/to/adapter.gguf", ""},
+		{"ctxsize", "8192", "unknown llama-server option 'ctxsize' (did you mean 'ctx-size'?)"},
+		{"batch-size", "512", "unknown llama-server option 'batch-size'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			// Act
+			err := schema.Check(tt.key, tt.value)
+
+			// Assert
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.HasPrefix(tt.wantErr, "unknown") != errors.Is(err, ErrUnknownOption) {
+				t.Errorf("errors.Is(%v, ErrUnknownOption) mismatch", err)
+			}
+		})
+	}
+}
+
+func TestSchemaCache_Load(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "llama-server")
+	script := "#!/bin/sh\necho run >> " + filepath.Join(dir, "runs") + "\ncat <<'EOF'\n" + testHelp + "EOF\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := filepath.Join(dir, "cache")
+	ctx := context.Background()
+
+	// Act
+	first, err1 := NewSchemaCache(cacheDir).Load(ctx, bin)
+	second, err2 := NewSchemaCache(cacheDir).Load(ctx, bin)
+
+	// Assert
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Load() errors = %v, %v", err1, err2)
+	}
+	if !reflect.DeepEqual(first, second) || first["ctx-size"].Kind != OptionNumber {
+		t.Errorf("cached schema = %v, want %v", second, first)
+	}
+	runs, err := os.ReadFile(filepath.Join(dir, "runs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(runs), "run"); got != 1 {
+		t.Errorf("--help ran %d times, want 1", got)
+	}
+}
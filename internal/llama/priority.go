@@ -0,0 +1,37 @@
+package llama
+
+import "os/exec"
+
+// Priority is how much CPU and disk time llama-server gets. The zero value
+// leaves it as the daemon's own.
+type Priority struct {
+	Nice int    // niceness, -20 to 19; zero leaves it
+	CPUs []int  // cores it may run on; empty allows all
+	IO   string // "idle", "low", "normal", or empty to leave it
+}
+
+// IsZero reports whether pr changes nothing.
+func (pr Priority) IsZero() bool {
+	return pr.Nice == 0 && len(pr.CPUs) == 0 && pr.IO == ""
+}
+
+// Unsupported returns the names of the preset fields of pr this platform
+// cannot apply; llama-server starts without them.
+func (pr Priority) Unsupported() []string {
+	return unsupportedPriority(pr)
+}
+
+// SetPriority sets the priority llama-server is started with.
+func (p *Process) SetPriority(pr Priority) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.priority = pr
+}
+
+// startCmd starts cmd with pr applied, if it is not zero.
+func startCmd(cmd *exec.Cmd, pr Priority) error {
+	if pr.IsZero() || cmd.Err != nil {
+		return cmd.Start()
+	}
+	return startWithPriority(cmd, pr)
+}
@@ -0,0 +1,43 @@
+package llama
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// Commands llama-server is started through to apply a priority. Both exec
+// the next command, so llama-server keeps their process ID.
+const (
+	niceCommand       = "/usr/bin/nice"
+	taskpolicyCommand = "/usr/sbin/taskpolicy"
+)
+
+// unsupportedPriority returns "cpu-affinity" if pr sets it: macOS does not
+// let processes be bound to cores.
+func unsupportedPriority(pr Priority) []string {
+	if len(pr.CPUs) > 0 {
+		return []string{"cpu-affinity"}
+	}
+	return nil
+}
+
+// startWithPriority starts cmd through nice for pr's niceness and through
+// taskpolicy, which throttles disk access, for an idle or low I/O
+// priority.
+func startWithPriority(cmd *exec.Cmd, pr Priority) error {
+	var prefix []string
+	if pr.Nice != 0 {
+		prefix = append(prefix, niceCommand, "-n", strconv.Itoa(pr.Nice))
+	}
+	switch pr.IO {
+	case "idle":
+		prefix = append(prefix, taskpolicyCommand, "-d", "throttle")
+	case "low":
+		prefix = append(prefix, taskpolicyCommand, "-d", "utility")
+	}
+	if len(prefix) > 0 {
+		cmd.Args = append(append(prefix, cmd.Path), cmd.Args[1:]...)
+		cmd.Path = prefix[0]
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,71 @@
+package llama
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// ioprio_set(2) arguments.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioClassBE    = 2
+	ioprioClassIdle  = 3
+)
+
+// unsupportedPriority returns nothing: Linux applies every field.
+func unsupportedPriority(Priority) []string {
+	return nil
+}
+
+// startWithPriority starts cmd from a thread given pr's niceness, CPU
+// affinity and I/O priority, which llama-server inherits when it is
+// forked. The thread is never unlocked, so it exits with its goroutine
+// instead of running other goroutines of the daemon at that priority.
+func startWithPriority(cmd *exec.Cmd, pr Priority) error {
+	errc := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		if err := setThreadPriority(pr); err != nil {
+			errc <- err
+			return
+		}
+		errc <- cmd.Start()
+	}()
+	return <-errc
+}
+
+// setThreadPriority applies pr to the calling thread.
+func setThreadPriority(pr Priority) error {
+	tid := unix.Gettid()
+	if len(pr.CPUs) > 0 {
+		var set unix.CPUSet
+		for _, cpu := range pr.CPUs {
+			set.Set(cpu)
+		}
+		if err := unix.SchedSetaffinity(tid, &set); err != nil {
+			return fmt.Errorf("set cpu-affinity: %w", err)
+		}
+	}
+	if pr.Nice != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, tid, pr.Nice); err != nil {
+			return fmt.Errorf("set nice %d: %w", pr.Nice, err)
+		}
+	}
+	if pr.IO != "" {
+		prio := ioprioClassBE<<ioprioClassShift | 4
+		switch pr.IO {
+		case "idle":
+			prio = ioprioClassIdle << ioprioClassShift
+		case "low":
+			prio = ioprioClassBE<<ioprioClassShift | 7
+		}
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(prio)); errno != 0 {
+			return fmt.Errorf("set io-priority: %w", errno)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package llama
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestStartAppliesPriority(t *testing.T) {
+	// Arrange
+	var allowed unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &allowed); err != nil {
+		t.Fatal(err)
+	}
+	cpu := 0
+	for !allowed.IsSet(cpu) {
+		cpu++
+	}
+	p := NewProcess("/bin/sleep")
+	p.SetLogWriter(&bytes.Buffer{})
+	p.SetPriority(Priority{Nice: 5, CPUs: []int{cpu}, IO: "idle"})
+
+	// Act
+	err := p.Start([]string{"60"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	defer p.Stop(context.Background())
+	// getpriority returns 20 - nice
+	if prio, err := unix.Getpriority(unix.PRIO_PROCESS, p.Pid()); err != nil || 20-prio != 5 {
+		t.Errorf("nice = %d (%v), want 5", 20-prio, err)
+	}
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(p.Pid(), &set); err != nil || set.Count() != 1 || !set.IsSet(cpu) {
+		t.Errorf("affinity = %d cores (%v), want core %d only", set.Count(), err, cpu)
+	}
+	if prio, err := unix.Getpriority(unix.PRIO_PROCESS, unix.Gettid()); err != nil || 20-prio != 0 {
+		t.Errorf("caller's nice = %d (%v), want it unchanged", 20-prio, err)
+	}
+}
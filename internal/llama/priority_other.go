@@ -0,0 +1,26 @@
+//go:build !linux && !darwin
+
+package llama
+
+import "os/exec"
+
+// unsupportedPriority returns every field pr sets: this platform applies
+// none of them.
+func unsupportedPriority(pr Priority) []string {
+	var fields []string
+	if pr.Nice != 0 {
+		fields = append(fields, "nice")
+	}
+	if len(pr.CPUs) > 0 {
+		fields = append(fields, "cpu-affinity")
+	}
+	if pr.IO != "" {
+		fields = append(fields, "io-priority")
+	}
+	return fields
+}
+
+// startWithPriority starts cmd without pr.
+func startWithPriority(cmd *exec.Cmd, pr Priority) error {
+	return cmd.Start()
+}
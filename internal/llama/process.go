@@ -13,7 +13,7 @@ import (
 )
 
 const (
-	// GracefulShutdownTimeout is the time to wait for graceful shutdown.
+	// GracefulShutdownTimeout is the default time to wait for graceful shutdown.
 	GracefulShutdownTimeout = 10 * time.Second
 )
 
@@ -23,6 +23,9 @@ type Process struct {
 	path      string
 	cmd       *exec.Cmd
 	logWriter io.Writer
+	priority  Priority
+	stderr    *lineTail     // last lines of stderr, for error messages
+	log       *lineTail     // last lines of stdout and stderr, for diagnostics
 	done      chan struct{} // closed when process exits
 	exitErr   error         // set before done is closed
 }
@@ -53,15 +56,17 @@ func (p *Process) Start(args []string) error {
 
 	p.cmd = exec.Command(p.path, args...)
 
+	p.stderr = newLineTail(outputTailLines)
+	p.log = newLineTail(logTailLines)
 	if p.logWriter != nil {
-		p.cmd.Stdout = p.logWriter
-		p.cmd.Stderr = p.logWriter
+		p.cmd.Stdout = io.MultiWriter(p.logWriter, p.log)
+		p.cmd.Stderr = io.MultiWriter(p.logWriter, p.log, p.stderr)
 	} else {
-		p.cmd.Stdout = os.Stdout
-		p.cmd.Stderr = os.Stderr
+		p.cmd.Stdout = io.MultiWriter(os.Stdout, p.log)
+		p.cmd.Stderr = io.MultiWriter(os.Stderr, p.log, p.stderr)
 	}
 
-	if err := p.cmd.Start(); err != nil {
+	if err := startCmd(p.cmd, p.priority); err != nil {
 		return &ProcessError{Op: ProcessOpStart, Err: err}
 	}
 
@@ -77,43 +82,60 @@ func (p *Process) Start(args []string) error {
 	return nil
 }
 
-// Stop stops the llama-server process gracefully.
+// Stop stops the llama-server process gracefully, killing it if it has not
+// exited within GracefulShutdownTimeout.
 func (p *Process) Stop(ctx context.Context) error {
+	_, err := p.Terminate(ctx, GracefulShutdownTimeout)
+	return err
+}
+
+// Terminate sends SIGTERM and waits up to grace for the process to exit
+// before sending SIGKILL. A grace of zero kills immediately.
+// Reports whether the process exited on its own.
+func (p *Process) Terminate(ctx context.Context, grace time.Duration) (bool, error) {
 	p.mu.Lock()
 	cmd := p.cmd
 	done := p.done
 	p.mu.Unlock()
 
 	if cmd == nil || cmd.Process == nil {
-		return nil
+		return true, nil
 	}
 
 	select {
 	case <-done:
-		return nil // already exited
+		return true, nil // already exited
 	default:
 	}
 
+	if grace <= 0 {
+		cmd.Process.Kill() // ignore error: process may have exited meanwhile
+		<-done
+		return false, nil
+	}
+
 	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
 		select {
 		case <-done:
-			return nil
+			return true, nil
 		default:
-			return fmt.Errorf("send SIGTERM: %w", err)
+			return false, fmt.Errorf("send SIGTERM: %w", err)
 		}
 	}
 
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
 	select {
 	case <-done:
-		return nil
-	case <-time.After(GracefulShutdownTimeout):
+		return true, nil
+	case <-timer.C:
 		cmd.Process.Kill() // ignore error: process may have exited between timeout and kill
 		<-done
-		return nil
+		return false, nil
 	case <-ctx.Done():
 		cmd.Process.Kill() // ignore error: best-effort cleanup
 		<-done
-		return ctx.Err()
+		return false, ctx.Err()
 	}
 }
 
@@ -133,6 +155,29 @@ func (p *Process) ExitErr() error {
 	return p.exitErr
 }
 
+// Output returns the last lines llama-server wrote to stderr, oldest first.
+// Complete once Done() is closed. Returns nil for attached processes.
+func (p *Process) Output() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.stderr == nil {
+		return nil
+	}
+	return p.stderr.Lines()
+}
+
+// LogTail returns the last lines llama-server wrote to stdout or stderr,
+// oldest first: the end of what went to the log writer. Returns nil for
+// attached processes.
+func (p *Process) LogTail() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.log == nil {
+		return nil
+	}
+	return p.log.Lines()
+}
+
 // IsRunning returns true if the process is running.
 func (p *Process) IsRunning() bool {
 	p.mu.RLock()
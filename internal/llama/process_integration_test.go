@@ -115,3 +115,42 @@ func TestProcess_StartAndStop_ForceKill(t *testing.T) {
 		t.Error("IsRunning() = true after forced Stop()")
 	}
 }
+
+func TestProcess_Terminate(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		grace        time.Duration
+		wantGraceful bool
+	}{
+		{"exits on SIGTERM", "-mode=sigterm", 5 * time.Second, true},
+		{"ignores SIGTERM past the grace period", "-mode=ignore-sigterm", 200 * time.Millisecond, false},
+		{"zero grace kills immediately", "-mode=sigterm", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			bin := buildFakeProc(t)
+			p := NewProcess(bin)
+			if err := p.Start([]string{tt.mode}); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+
+			// Act
+			graceful, err := p.Terminate(context.Background(), tt.grace)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Terminate() error = %v", err)
+			}
+			if graceful != tt.wantGraceful {
+				t.Errorf("Terminate() graceful = %v, want %v", graceful, tt.wantGraceful)
+			}
+			if p.IsRunning() {
+				t.Error("IsRunning() = true after Terminate()")
+			}
+		})
+	}
+}
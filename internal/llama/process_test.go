@@ -3,6 +3,9 @@ package llama
 import (
 	"bytes"
 	"context"
+	"os/exec"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -169,6 +172,48 @@ func TestExitErrSetOnAbnormalExit(t *testing.T) {
 	}
 }
 
+func TestOutputKeepsStderrTail(t *testing.T) {
+	p := NewProcess("/bin/sh")
+	log := &lockedBuffer{}
+	p.SetLogWriter(log)
+
+	err := p.Start([]string{"-c", "echo on stdout; for i in $(seq 1 15); do echo line $i >&2; done; printf 'error: unknown argument: --ctxsize' >&2; exit 1"})
+	if err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	<-p.Done()
+
+	got := p.Output()
+	if len(got) != outputTailLines || got[0] != "line 7" || got[len(got)-1] != "error: unknown argument: --ctxsize" {
+		t.Errorf("Output() = %q, want the last %d stderr lines", got, outputTailLines)
+	}
+	if !strings.Contains(log.String(), "on stdout") || !strings.Contains(log.String(), "line 1\n") {
+		t.Errorf("log = %q, want stdout and all of stderr", log.String())
+	}
+	if tail := p.LogTail(); len(tail) != 17 || !slices.Contains(tail, "on stdout") {
+		t.Errorf("LogTail() = %q, want stdout and stderr", tail)
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe for the concurrent stdout and stderr
+// copies of a process.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
 func TestStopOnAlreadyExitedProcess(t *testing.T) {
 	p := NewProcess("/bin/sh")
 	p.SetLogWriter(&bytes.Buffer{})
@@ -264,3 +309,56 @@ func TestConcurrentDoneAndExitErrNoRace(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestAttachProcess_StopAndDone(t *testing.T) {
+	// Arrange
+	cmd := exec.Command("/bin/sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	go cmd.Wait() // reap, as init would for an orphan
+
+	p, err := AttachProcess(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("AttachProcess() error = %v", err)
+	}
+
+	// Act
+	err = p.Stop(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+	if p.Pid() != cmd.Process.Pid {
+		t.Errorf("Pid() = %d, want %d", p.Pid(), cmd.Process.Pid)
+	}
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Done() was not closed after attached process exited")
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	// Arrange
+	cmd := exec.Command("/bin/sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start sleep: %v", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	// Act
+	got, err := CommandLine(cmd.Process.Pid)
+
+	// Assert
+	if err != nil {
+		t.Skipf("ps not available: %v", err)
+	}
+	if got != "/bin/sleep 60" {
+		t.Errorf("CommandLine() = %q, want %q", got, "/bin/sleep 60")
+	}
+}
@@ -0,0 +1,84 @@
+package llama
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// outputTailLines is how many lines of llama-server stderr a Process
+	// keeps for error messages.
+	outputTailLines = 10
+
+	// logTailLines is how many lines of llama-server output, stdout and
+	// stderr, a Process keeps for load failure diagnostics.
+	logTailLines = 20
+
+	// maxTailLineLen truncates long lines, e.g. progress dots.
+	maxTailLineLen = 500
+)
+
+// lineTail is an io.Writer that keeps the last non-blank lines written to it.
+type lineTail struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte // written after the last newline
+}
+
+func newLineTail(max int) *lineTail {
+	return &lineTail{max: max}
+}
+
+func (t *lineTail) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(b)
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			t.partial = append(t.partial, b...)
+			if len(t.partial) > maxTailLineLen {
+				t.partial = t.partial[:maxTailLineLen]
+			}
+			return n, nil
+		}
+		t.partial = append(t.partial, b[:i]...)
+		t.add(string(t.partial))
+		t.partial = t.partial[:0]
+		b = b[i+1:]
+	}
+}
+
+func (t *lineTail) add(line string) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(line) > maxTailLineLen {
+		line = line[:maxTailLineLen] + "..."
+	}
+	if len(t.lines) == t.max {
+		t.lines = slices.Delete(t.lines, 0, 1)
+	}
+	t.lines = append(t.lines, line)
+}
+
+// Lines returns the kept lines, oldest first, including an unterminated
+// last line.
+func (t *lineTail) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := slices.Clone(t.lines)
+	if last := strings.TrimRight(string(t.partial), "\r"); strings.TrimSpace(last) != "" {
+		if len(lines) == t.max {
+			lines = lines[1:]
+		}
+		lines = append(lines, last)
+	}
+	return lines
+}
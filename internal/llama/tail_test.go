@@ -0,0 +1,76 @@
+package llama
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLineTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{
+			name:   "keeps last lines",
+			writes: []string{"a\nb\n", "c\nd\n"},
+			want:   []string{"b", "c", "d"},
+		},
+		{
+			name:   "joins lines split across writes",
+			writes: []string{"fai", "led to alloc", "ate buffer\r\n"},
+			want:   []string{"failed to allocate buffer"},
+		},
+		{
+			name:   "skips blank lines",
+			writes: []string{"a\n\n  \nb\n"},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "includes unterminated last line",
+			writes: []string{"a\nb\nc\nexiting"},
+			want:   []string{"b", "c", "exiting"},
+		},
+		{
+			name:   "truncates long lines",
+			writes: []string{strings.Repeat(".", maxTailLineLen+100) + "\n"},
+			want:   []string{strings.Repeat(".", maxTailLineLen) + "..."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tail := newLineTail(3)
+
+			// Act
+			for _, w := range tt.writes {
+				tail.Write([]byte(w))
+			}
+
+			// Assert
+			if got := tail.Lines(); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessErrorIncludesOutput(t *testing.T) {
+	// Arrange
+	err := &ProcessError{Op: ProcessOpWait, Err: errString("exit status 1"), Output: []string{"error: unknown argument: --ctxsize", "usage: llama-server"}}
+
+	// Act
+	msg := err.Error()
+
+	// Assert
+	want := "wait llama-server: exit status 1\nllama-server output:\n  error: unknown argument: --ctxsize\n  usage: llama-server"
+	if msg != want {
+		t.Errorf("Error() = %q, want %q", msg, want)
+	}
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
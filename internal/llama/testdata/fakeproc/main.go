@@ -1,5 +1,7 @@
 // Package main provides a fake process for testing llama.Process.
 // This binary simulates various process behaviors for integration testing.
+// With FAKEPROC_SERVER=1 it instead acts as llama-server, taking
+// llama-server's arguments and serving its HTTP endpoints (see serve).
 package main
 
 import (
@@ -12,7 +14,12 @@ import (
 )
 
 func main() {
-	mode := flag.String("mode", "run", "Process mode: run, exit, sigterm, sleep, crash")
+	if os.Getenv(envServer) == "1" {
+		serve(os.Args[1:])
+		return
+	}
+
+	mode := flag.String("mode", "run", "Process mode: run, exit, sigterm, ignore-sigterm, sleep, crash")
 	exitCode := flag.Int("exit-code", 0, "Exit code for exit mode")
 	sleepDuration := flag.Duration("sleep", 5*time.Second, "Sleep duration for sleep mode")
 	flag.Parse()
@@ -38,6 +45,12 @@ func main() {
 		time.Sleep(50 * time.Millisecond) // Brief cleanup
 		os.Exit(0)
 
+	case "ignore-sigterm":
+		// Ignore SIGTERM and run until killed
+		signal.Ignore(syscall.SIGTERM)
+		fmt.Fprintln(os.Stdout, "ignoring SIGTERM")
+		time.Sleep(time.Hour)
+
 	case "sleep":
 		// Sleep for specified duration then exit
 		fmt.Fprintln(os.Stdout, "sleeping")
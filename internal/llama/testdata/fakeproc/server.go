@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Environment variables that script the fake llama-server. The daemon
+// starts llama-server with llama-server's own arguments, so its behavior
+// cannot be passed as flags.
+const (
+	envServer        = "FAKEPROC_SERVER"         // "1" to act as llama-server
+	envLoadDelay     = "FAKEPROC_LOAD_DELAY"     // how long /health reports loading, e.g. "500ms"
+	envResponseDelay = "FAKEPROC_RESPONSE_DELAY" // delay before answering /completion and /v1 requests
+	envFail          = "FAKEPROC_FAIL"           // "health": /health reports an error; "exit": exit before listening
+)
+
+// serverConfig is what the fake server takes from llama-server's arguments
+// and the environment.
+type serverConfig struct {
+	host          string
+	port          string
+	models        []string // model IDs: the model file, or the router's config.ini sections
+	loadDelay     time.Duration
+	responseDelay time.Duration
+	fail          string
+}
+
+// parseServerConfig reads the llama-server arguments the fake server needs,
+// ignoring the rest.
+func parseServerConfig(args []string) (serverConfig, error) {
+	cfg := serverConfig{host: "127.0.0.1", port: "8080", fail: os.Getenv(envFail)}
+	for i := 0; i < len(args)-1; i++ {
+		switch args[i] {
+		case "--host":
+			cfg.host = args[i+1]
+		case "--port":
+			cfg.port = args[i+1]
+		case "-m", "--model":
+			cfg.models = append(cfg.models, args[i+1])
+		case "--models-preset":
+			sections, err := iniSections(args[i+1])
+			if err != nil {
+				return cfg, err
+			}
+			cfg.models = append(cfg.models, sections...)
+		}
+	}
+
+	var err error
+	if cfg.loadDelay, err = envDuration(envLoadDelay); err != nil {
+		return cfg, err
+	}
+	if cfg.responseDelay, err = envDuration(envResponseDelay); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// envDuration parses the duration in environment variable name; zero if
+// it is unset.
+func envDuration(name string) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return d, nil
+}
+
+// iniSections returns the model sections of a router config.ini.
+func iniSections(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var sections []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && line != "[*]" {
+			sections = append(sections, line[1:len(line)-1])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// serve acts as llama-server until SIGTERM: /health reports loading for
+// the load delay, then ok; /models lists the models as loaded; /completion
+// and the /v1 endpoints answer with a fixed completion.
+func serve(args []string) {
+	cfg, err := parseServerConfig(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.fail == "exit" {
+		fmt.Fprintln(os.Stderr, "error: failed to load model")
+		os.Exit(1)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(cfg.host, cfg.port))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: couldn't bind HTTP server socket: %v\n", err)
+		os.Exit(1)
+	}
+	started := time.Now()
+	server := &http.Server{Handler: newHandler(cfg, started)}
+	fmt.Fprintf(os.Stdout, "server is listening on http://%s\n", listener.Addr())
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGTERM, os.Interrupt)
+	go func() {
+		<-sigc
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newHandler returns the fake llama-server's HTTP handler.
+func newHandler(cfg serverConfig, started time.Time) http.Handler {
+	loading := func() bool { return time.Since(started) < cfg.loadDelay }
+	writeJSON := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+	errorBody := func(msg string) any {
+		return map[string]any{"error": map[string]any{"message": msg}}
+	}
+	completion := func(w http.ResponseWriter, r *http.Request) {
+		if loading() {
+			writeJSON(w, http.StatusServiceUnavailable, errorBody("Loading model"))
+			return
+		}
+		select {
+		case <-time.After(cfg.responseDelay):
+		case <-r.Context().Done():
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"content": "hello",
+			"choices": []any{map[string]any{"index": 0, "text": "hello", "message": map[string]any{"role": "assistant", "content": "hello"}}},
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case cfg.fail == "health":
+			writeJSON(w, http.StatusInternalServerError, errorBody("failed to load model"))
+		case loading():
+			writeJSON(w, http.StatusServiceUnavailable, errorBody("Loading model"))
+		default:
+			writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+		}
+	})
+	models := func(w http.ResponseWriter, r *http.Request) {
+		data := []any{}
+		for _, m := range cfg.models {
+			data = append(data, map[string]any{"id": m, "object": "model", "status": map[string]any{"value": "loaded"}})
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": data})
+	}
+	mux.HandleFunc("GET /models", models)
+	mux.HandleFunc("GET /v1/models", models)
+	mux.HandleFunc("GET /props", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"total_slots": 1})
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "llamacpp:requests_processing 0")
+		fmt.Fprintln(w, "llamacpp:requests_deferred 0")
+	})
+	mux.HandleFunc("POST /completion", completion)
+	mux.HandleFunc("POST /v1/", completion)
+	return mux
+}
@@ -0,0 +1,56 @@
+package llama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WarmUp requests a one-token completion of prompt and returns once it
+// succeeds. /health can report OK before the weights are paged in, so this
+// is the stricter readiness check. A 503 (model still loading) is retried;
+// the first token may take minutes, so only ctx bounds the wait.
+func WarmUp(ctx context.Context, endpoint, prompt string) error {
+	body, err := json.Marshal(map[string]any{"prompt": prompt, "n_predict": 1})
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/completion", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("warmup completion: %w", err)
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return nil
+		case http.StatusServiceUnavailable:
+		default:
+			return fmt.Errorf("warmup completion: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
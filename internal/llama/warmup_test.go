@@ -0,0 +1,79 @@
+package llama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmUp_RetriesWhileLoading(t *testing.T) {
+	// Arrange
+	var calls atomic.Int32
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/completion" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if calls.Add(1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"content":"!"}`))
+	}))
+	defer srv.Close()
+
+	// Act
+	err := WarmUp(context.Background(), srv.URL, "Hi")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("WarmUp() error = %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if gotBody["prompt"] != "Hi" || gotBody["n_predict"] != float64(1) {
+		t.Errorf("body = %v, want prompt Hi and n_predict 1", gotBody)
+	}
+}
+
+func TestWarmUp_FailsOnError(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "context size exceeded", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	// Act
+	err := WarmUp(context.Background(), srv.URL, "Hi")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "context size exceeded") {
+		t.Fatalf("WarmUp() error = %v, want server message", err)
+	}
+}
+
+func TestWarmUp_ContextDeadline(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	err := WarmUp(ctx, srv.URL, "Hi")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WarmUp() error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -9,12 +9,16 @@ import (
 	"slices"
 	"sync"
 	"time"
+
+	"github.com/d2verb/alpaca/internal/gguf"
 )
 
 // MmprojEntry represents metadata for a multimodal projector file.
 type MmprojEntry struct {
 	Filename string `json:"filename"`
 	Size     int64  `json:"size"`
+	File     string `json:"file,omitempty"`   // original filename in the repository; empty if unknown
+	SHA256   string `json:"sha256,omitempty"` // upstream hash at download time; empty if unknown
 }
 
 // ModelEntry represents metadata for a downloaded model.
@@ -22,14 +26,88 @@ type ModelEntry struct {
 	Repo         string       `json:"repo"`
 	Quant        string       `json:"quant"`
 	Filename     string       `json:"filename"`
-	Size         int64        `json:"size"`
+	Size         int64        `json:"size"`             // total of all shards for split models
+	SHA256       string       `json:"sha256,omitempty"` // upstream hash at download time (first shard for split models)
+	Shards       []string     `json:"shards,omitempty"` // every file of a split model, Filename first; empty for a single file
 	Mmproj       *MmprojEntry `json:"mmproj,omitempty"`
+	MmprojFailed bool         `json:"mmproj_failed,omitempty"` // the manifest lists an mmproj whose download failed
+	Source       string       `json:"source,omitempty"`        // URL or local path for models added outside HuggingFace
+	Registry     string       `json:"registry,omitempty"`      // identifier scheme of the registry pulled from; empty for HuggingFace
+	AutoSelected bool         `json:"auto_selected,omitempty"` // quant was chosen by repo:auto
 	DownloadedAt time.Time    `json:"downloaded_at"`
+
+	// ExtraFiles are other repository files downloaded next to the model
+	// with pull --with-files, such as tokenizer_config.json.
+	ExtraFiles []ExtraFileEntry `json:"extra_files,omitempty"`
+
+	// Read from the GGUF metadata of the model files; see ReadGGUFInfo.
+	Architecture  string `json:"architecture,omitempty"`   // general.architecture, e.g. "llama"
+	ContextLength int    `json:"context_length,omitempty"` // training context length (n_ctx_train)
+	Parameters    int64  `json:"parameters,omitempty"`     // across all shards
+}
+
+// ExtraFileEntry represents metadata for a repository file that is not a
+// GGUF, downloaded alongside a model.
+type ExtraFileEntry struct {
+	File     string `json:"file"`     // path in the repository
+	Filename string `json:"filename"` // storage filename with repo prefix
+	Size     int64  `json:"size"`
+}
+
+// Files returns the model's files: the shards of a split model, or Filename.
+func (e ModelEntry) Files() []string {
+	if len(e.Shards) > 0 {
+		return e.Shards
+	}
+	return []string{e.Filename}
+}
+
+// ReadGGUFInfo sets Architecture, ContextLength and Parameters from the
+// GGUF metadata of the model's files in modelsDir. They are informational,
+// so a file that cannot be read leaves them unset.
+func (e *ModelEntry) ReadGGUFInfo(modelsDir string) {
+	files := e.Files()
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = filepath.Join(modelsDir, f)
+	}
+	info, err := gguf.InspectSplit(paths)
+	if err != nil {
+		return
+	}
+	e.Architecture, e.ContextLength, e.Parameters = info.Architecture, info.ContextLength, info.Parameters
+}
+
+// LoraEntry represents metadata for a downloaded LoRA adapter.
+// Adapters are keyed by repo + original filename rather than repo + quant.
+type LoraEntry struct {
+	Repo         string    `json:"repo"`
+	File         string    `json:"file"`     // original filename in the repository
+	Filename     string    `json:"filename"` // storage filename with repo prefix
+	Size         int64     `json:"size"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+}
+
+// AutoQuant is the quant placeholder that lets alpaca choose the quant.
+const AutoQuant = "auto"
+
+// FileChecksum is the SHA256 of a file in the models directory, computed
+// when the file had the recorded size and modification time.
+type FileChecksum struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+	SHA256  string    `json:"sha256"`
 }
 
 // Metadata holds all model entries.
 type Metadata struct {
-	Models []ModelEntry `json:"models"`
+	SchemaVersion int          `json:"schema_version"`
+	Models        []ModelEntry `json:"models"`
+	Loras         []LoraEntry  `json:"loras,omitempty"`
+
+	// Checksums caches file hashes by storage filename, so checking that a
+	// multi-GB model is up to date need not read it again.
+	Checksums map[string]FileChecksum `json:"checksums,omitempty"`
 }
 
 // Manager handles metadata persistence.
@@ -37,20 +115,28 @@ type Manager struct {
 	filePath string
 	data     *Metadata
 	mu       sync.Mutex
+	commitMu sync.Mutex // serializes Commit against other commits and reloads
 }
 
 // NewManager creates a new metadata manager.
 // The metadata file will be stored at modelsDir/.metadata.json
 func NewManager(modelsDir string) *Manager {
 	return &Manager{
-		filePath: filepath.Join(modelsDir, ".metadata.json"),
-		data:     &Metadata{Models: []ModelEntry{}},
+		filePath: filepath.Join(modelsDir, FileName),
+		data:     &Metadata{SchemaVersion: SchemaVersion, Models: []ModelEntry{}},
 	}
 }
 
 // Load reads metadata from disk.
 // If the file doesn't exist, returns an empty metadata (not an error).
 func (m *Manager) Load(ctx context.Context) error {
+	// Wait for in-flight commits so a reload cannot drop their entries
+	m.commitMu.Lock()
+	defer m.commitMu.Unlock()
+	return m.load(ctx)
+}
+
+func (m *Manager) load(ctx context.Context) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -64,7 +150,7 @@ func (m *Manager) Load(ctx context.Context) error {
 	if err != nil {
 		if os.IsNotExist(err) {
 			// File doesn't exist yet - treat as empty
-			m.data = &Metadata{Models: []ModelEntry{}}
+			m.data = &Metadata{SchemaVersion: SchemaVersion, Models: []ModelEntry{}}
 			return nil
 		}
 		return fmt.Errorf("read metadata file: %w", err)
@@ -72,7 +158,7 @@ func (m *Manager) Load(ctx context.Context) error {
 
 	if len(data) == 0 {
 		// Empty file - treat as empty metadata
-		m.data = &Metadata{Models: []ModelEntry{}}
+		m.data = &Metadata{SchemaVersion: SchemaVersion, Models: []ModelEntry{}}
 		return nil
 	}
 
@@ -80,6 +166,8 @@ func (m *Manager) Load(ctx context.Context) error {
 	if err := json.Unmarshal(data, &meta); err != nil {
 		return fmt.Errorf("parse metadata: %w", err)
 	}
+	// Older formats are read as upgraded; files from a newer build as they are
+	schema.Upgrade(&meta)
 
 	m.data = &meta
 	return nil
@@ -131,6 +219,35 @@ func (m *Manager) Save(ctx context.Context) error {
 	return nil
 }
 
+// MarkAutoSelected flags repo:quant as the automatically chosen quant for
+// repo, clearing the flag from the repo's other entries.
+func (m *Manager) MarkAutoSelected(repo, quant string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.data.Models {
+		if m.data.Models[i].Repo == repo {
+			m.data.Models[i].AutoSelected = m.data.Models[i].Quant == quant
+		}
+	}
+}
+
+// Commit reloads metadata from disk, applies fn, and saves the result.
+// Commits are serialized, so concurrent downloads sharing a Manager do not
+// overwrite each other's entries.
+func (m *Manager) Commit(ctx context.Context, fn func(m *Manager) error) error {
+	m.commitMu.Lock()
+	defer m.commitMu.Unlock()
+
+	if err := m.load(ctx); err != nil {
+		return err
+	}
+	if err := fn(m); err != nil {
+		return err
+	}
+	return m.Save(ctx)
+}
+
 // Add adds or updates a model entry.
 // If an entry with the same repo+quant exists, it's replaced.
 func (m *Manager) Add(entry ModelEntry) error {
@@ -154,7 +271,13 @@ func (m *Manager) Remove(repo, quant string) error {
 	defer m.mu.Unlock()
 
 	m.data.Models = slices.DeleteFunc(m.data.Models, func(e ModelEntry) bool {
-		return e.Repo == repo && e.Quant == quant
+		if e.Repo != repo || e.Quant != quant {
+			return false
+		}
+		for _, f := range e.Files() {
+			delete(m.data.Checksums, f)
+		}
+		return true
 	})
 
 	return nil
@@ -173,6 +296,15 @@ func (m *Manager) Find(repo, quant string) *ModelEntry {
 		}
 	}
 
+	// repo:auto resolves to the quant that was chosen automatically
+	if quant == AutoQuant {
+		for _, e := range m.data.Models {
+			if e.Repo == repo && e.AutoSelected {
+				return &e
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -185,6 +317,130 @@ func (m *Manager) List() []ModelEntry {
 	return slices.Clone(m.data.Models)
 }
 
+// AddLora adds or updates a LoRA adapter entry.
+// If an entry with the same repo+file exists, it's replaced.
+func (m *Manager) AddLora(entry LoraEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Loras = slices.DeleteFunc(m.data.Loras, func(e LoraEntry) bool {
+		return e.Repo == entry.Repo && e.File == entry.File
+	})
+	m.data.Loras = append(m.data.Loras, entry)
+}
+
+// RemoveLora removes a LoRA adapter entry.
+// Does nothing if the entry doesn't exist.
+func (m *Manager) RemoveLora(repo, file string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Loras = slices.DeleteFunc(m.data.Loras, func(e LoraEntry) bool {
+		if e.Repo != repo || e.File != file {
+			return false
+		}
+		delete(m.data.Checksums, e.Filename)
+		return true
+	})
+}
+
+// Checksum returns the SHA256 recorded for filename, or "" if none is
+// recorded or the file's size or modification time has changed since.
+func (m *Manager) Checksum(filename string, size int64, modTime time.Time) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.data.Checksums[filename]
+	if !ok || c.Size != size || !c.ModTime.Equal(modTime) {
+		return ""
+	}
+	return c.SHA256
+}
+
+// SetChecksum records the SHA256 of filename at its current size and
+// modification time.
+func (m *Manager) SetChecksum(filename string, c FileChecksum) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.Checksums == nil {
+		m.data.Checksums = map[string]FileChecksum{}
+	}
+	m.data.Checksums[filename] = c
+}
+
+// FindLora looks up a LoRA adapter entry.
+// Returns a copy of the entry, or nil if not found.
+func (m *Manager) FindLora(repo, file string) *LoraEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, e := range m.data.Loras {
+		if e.Repo == repo && e.File == file {
+			return &e
+		}
+	}
+	return nil
+}
+
+// ListLoras returns all LoRA adapter entries.
+func (m *Manager) ListLoras() []LoraEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.data.Loras)
+}
+
+// GetLoraFilePath resolves repo:file to the adapter's file path.
+// Returns an error if the adapter is not found in metadata.
+func (m *Manager) GetLoraFilePath(modelsDir, repo, file string) (string, error) {
+	entry := m.FindLora(repo, file)
+	if entry == nil {
+		return "", &NotFoundError{Repo: repo, Quant: file}
+	}
+
+	filePath := filepath.Join(modelsDir, entry.Filename)
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("lora file not found: %s (try running 'alpaca ls' and cleanup)", filePath)
+		}
+		return "", fmt.Errorf("check lora file: %w", err)
+	}
+
+	return filePath, nil
+}
+
+// AllFiles returns every file recorded in metadata (model files, mmproj
+// files, extra files and LoRA adapters), once each.
+func (m *Manager) AllFiles() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var files []string
+	add := func(f string) {
+		if f != "" && !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	for _, e := range m.data.Models {
+		for _, f := range e.Files() {
+			add(f)
+		}
+		if e.Mmproj != nil {
+			add(e.Mmproj.Filename)
+		}
+		for _, x := range e.ExtraFiles {
+			add(x.Filename)
+		}
+	}
+	for _, l := range m.data.Loras {
+		add(l.Filename)
+	}
+	return files
+}
+
 // MmprojReferenceCount returns the number of model entries that reference
 // the given mmproj filename. This is used for reference counting when
 // deleting or cleaning up mmproj files.
@@ -201,23 +457,41 @@ func (m *Manager) MmprojReferenceCount(filename string) int {
 	return count
 }
 
-// GetFilePath resolves repo:quant to the actual file path.
-// Returns an error if the model is not found in metadata.
+// ExtraFileReferenceCount returns the number of model entries that list
+// the given extra filename. Quants of one repository share their extra
+// files, so one is deleted only with the last entry that lists it.
+func (m *Manager) ExtraFileReferenceCount(filename string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, e := range m.data.Models {
+		if slices.ContainsFunc(e.ExtraFiles, func(x ExtraFileEntry) bool { return x.Filename == filename }) {
+			count++
+		}
+	}
+	return count
+}
+
+// GetFilePath resolves repo:quant to the actual file path (the first shard
+// of a split model). Returns an error if the model is not found in metadata.
 func (m *Manager) GetFilePath(modelsDir, repo, quant string) (string, error) {
 	entry := m.Find(repo, quant)
 	if entry == nil {
 		return "", &NotFoundError{Repo: repo, Quant: quant}
 	}
 
-	filePath := filepath.Join(modelsDir, entry.Filename)
-
-	// Verify file exists
-	if _, err := os.Stat(filePath); err != nil {
-		if os.IsNotExist(err) {
-			return "", fmt.Errorf("model file not found: %s (try running 'alpaca ls' and cleanup)", filePath)
+	// Verify files exist (every shard of a split model)
+	for _, f := range entry.Files() {
+		p := filepath.Join(modelsDir, f)
+		if _, err := os.Stat(p); err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("model file not found: %s (try running 'alpaca ls' and cleanup)", p)
+			}
+			return "", fmt.Errorf("check model file: %w", err)
 		}
-		return "", fmt.Errorf("check model file: %w", err)
 	}
 
-	return filePath, nil
+	// llama-server finds the other shards next to the first
+	return filepath.Join(modelsDir, entry.Filename), nil
 }
@@ -3,8 +3,12 @@ package metadata
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -366,6 +370,52 @@ func TestGetFilePathFileNotFound(t *testing.T) {
 	}
 }
 
+func TestGetFilePathShardedModel(t *testing.T) {
+	shards := []string{"model-00001-of-00002.gguf", "model-00002-of-00002.gguf"}
+	tests := []struct {
+		name    string
+		present []string
+		wantErr bool
+	}{
+		{"all shards present", shards, false},
+		{"missing shard", shards[:1], true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpDir := t.TempDir()
+			mgr := NewManager(tmpDir)
+			for _, f := range tt.present {
+				if err := os.WriteFile(filepath.Join(tmpDir, f), []byte("dummy"), 0644); err != nil {
+					t.Fatalf("create shard: %v", err)
+				}
+			}
+			entry := ModelEntry{Repo: "repo1", Quant: "Q4_K_M", Filename: shards[0], Shards: shards}
+			if err := mgr.Add(entry); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+
+			// Act
+			path, err := mgr.GetFilePath(tmpDir, "repo1", "Q4_K_M")
+
+			// Assert
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error for missing shard")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if want := filepath.Join(tmpDir, shards[0]); path != want {
+				t.Errorf("expected first shard %s, got %s", want, path)
+			}
+		})
+	}
+}
+
 func TestMmprojEntryJSONRoundTrip(t *testing.T) {
 	// Arrange
 	entry := MmprojEntry{
@@ -568,3 +618,250 @@ func TestMmprojReferenceCount(t *testing.T) {
 		})
 	}
 }
+
+func TestLoraEntries_AddFindRemove(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	ctx := context.Background()
+	mgr.AddLora(LoraEntry{Repo: "org/lora", File: "a.gguf", Filename: "org_lora_a.gguf", Size: 1})
+	mgr.AddLora(LoraEntry{Repo: "org/lora", File: "a.gguf", Filename: "org_lora_a.gguf", Size: 2})
+	mgr.AddLora(LoraEntry{Repo: "org/lora", File: "b.gguf", Filename: "org_lora_b.gguf", Size: 3})
+
+	// Act
+	if err := mgr.Save(ctx); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	reloaded := NewManager(tmpDir)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	// Assert
+	if got := len(reloaded.ListLoras()); got != 2 {
+		t.Fatalf("ListLoras() len = %d, want 2", got)
+	}
+	entry := reloaded.FindLora("org/lora", "a.gguf")
+	if entry == nil || entry.Size != 2 {
+		t.Fatalf("FindLora() = %+v, want replaced entry with size 2", entry)
+	}
+	reloaded.RemoveLora("org/lora", "a.gguf")
+	if reloaded.FindLora("org/lora", "a.gguf") != nil {
+		t.Error("FindLora() after RemoveLora should return nil")
+	}
+}
+
+func TestGetLoraFilePath(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	loraFile := filepath.Join(tmpDir, "org_lora_a.gguf")
+	if err := os.WriteFile(loraFile, []byte("dummy"), 0644); err != nil {
+		t.Fatalf("create lora file: %v", err)
+	}
+	mgr.AddLora(LoraEntry{Repo: "org/lora", File: "a.gguf", Filename: "org_lora_a.gguf"})
+
+	// Act
+	path, err := mgr.GetLoraFilePath(tmpDir, "org/lora", "a.gguf")
+	_, missingErr := mgr.GetLoraFilePath(tmpDir, "org/lora", "missing.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetLoraFilePath() error = %v", err)
+	}
+	if path != loraFile {
+		t.Errorf("path = %s, want %s", path, loraFile)
+	}
+	var notFound *NotFoundError
+	if !errors.As(missingErr, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", missingErr)
+	}
+}
+
+func TestCommit_Concurrent(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	ctx := context.Background()
+	const n = 10
+
+	// Act
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mgr.Load(ctx) // concurrent reloads must not drop committed entries
+			err := mgr.Commit(ctx, func(m *Manager) error {
+				return m.Add(ModelEntry{Repo: "org/repo", Quant: fmt.Sprintf("Q%d", i), Filename: fmt.Sprintf("m%d.gguf", i)})
+			})
+			if err != nil {
+				t.Errorf("Commit() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	reloaded := NewManager(tmpDir)
+	if err := reloaded.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := len(reloaded.List()); got != n {
+		t.Errorf("expected %d entries, got %d", n, got)
+	}
+}
+
+func TestCommit_FnError(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	wantErr := errors.New("boom")
+
+	// Act
+	err := mgr.Commit(context.Background(), func(m *Manager) error {
+		m.Add(ModelEntry{Repo: "org/repo", Quant: "Q4_K_M", Filename: "m.gguf"})
+		return wantErr
+	})
+
+	// Assert
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Commit() error = %v, want %v", err, wantErr)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, ".metadata.json")); !os.IsNotExist(statErr) {
+		t.Error("metadata should not be saved when fn fails")
+	}
+}
+
+func TestFind_AutoQuant(t *testing.T) {
+	// Arrange
+	mgr := NewManager(t.TempDir())
+	mgr.Add(ModelEntry{Repo: "org/repo", Quant: "Q8_0", Filename: "q8.gguf"})
+	mgr.Add(ModelEntry{Repo: "org/repo", Quant: "Q4_K_M", Filename: "q4.gguf"})
+	mgr.Add(ModelEntry{Repo: "org/other", Quant: "Q4_K_M", Filename: "other.gguf", AutoSelected: true})
+
+	// Act & Assert: nothing chosen yet for org/repo
+	if got := mgr.Find("org/repo", AutoQuant); got != nil {
+		t.Fatalf("expected nil before MarkAutoSelected, got %+v", got)
+	}
+
+	mgr.MarkAutoSelected("org/repo", "Q4_K_M")
+	got := mgr.Find("org/repo", AutoQuant)
+	if got == nil || got.Quant != "Q4_K_M" {
+		t.Fatalf("Find(auto) = %+v, want Q4_K_M", got)
+	}
+
+	mgr.MarkAutoSelected("org/repo", "Q8_0")
+	if got := mgr.Find("org/repo", AutoQuant); got == nil || got.Quant != "Q8_0" {
+		t.Errorf("Find(auto) after re-select = %+v, want Q8_0", got)
+	}
+	if got := mgr.Find("org/other", AutoQuant); got == nil || got.Quant != "Q4_K_M" {
+		t.Errorf("other repo should be unaffected, got %+v", got)
+	}
+}
+
+func TestAllFiles(t *testing.T) {
+	// Arrange
+	m := NewManager(t.TempDir())
+	m.Add(ModelEntry{Repo: "org/a", Quant: "Q4_K_M", Filename: "a-Q4.gguf", Mmproj: &MmprojEntry{Filename: "org_a_mmproj.gguf"}})
+	m.Add(ModelEntry{Repo: "org/a", Quant: "Q8_0", Filename: "a-Q8.gguf", Mmproj: &MmprojEntry{Filename: "org_a_mmproj.gguf"},
+		ExtraFiles: []ExtraFileEntry{{File: "config.json", Filename: "org_a_config.json"}}})
+	m.Add(ModelEntry{Repo: "org/big", Quant: "Q4_K_M", Filename: "big-00001-of-00002.gguf", Shards: []string{"big-00001-of-00002.gguf", "big-00002-of-00002.gguf"}})
+	m.AddLora(LoraEntry{Repo: "org/lora", File: "adapter.gguf", Filename: "org_lora_adapter.gguf"})
+
+	// Act
+	files := m.AllFiles()
+
+	// Assert
+	want := []string{"a-Q4.gguf", "org_a_mmproj.gguf", "a-Q8.gguf", "org_a_config.json", "big-00001-of-00002.gguf", "big-00002-of-00002.gguf", "org_lora_adapter.gguf"}
+	if !slices.Equal(files, want) {
+		t.Errorf("AllFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestExtraFileReferenceCount(t *testing.T) {
+	// Arrange
+	m := NewManager(t.TempDir())
+	config := ExtraFileEntry{File: "config.json", Filename: "org_a_config.json"}
+	m.Add(ModelEntry{Repo: "org/a", Quant: "Q4_K_M", Filename: "a-Q4.gguf", ExtraFiles: []ExtraFileEntry{config}})
+	m.Add(ModelEntry{Repo: "org/a", Quant: "Q8_0", Filename: "a-Q8.gguf", ExtraFiles: []ExtraFileEntry{config}})
+	m.Add(ModelEntry{Repo: "org/b", Quant: "Q4_K_M", Filename: "b-Q4.gguf"})
+
+	// Act & Assert
+	if got := m.ExtraFileReferenceCount("org_a_config.json"); got != 2 {
+		t.Errorf("ExtraFileReferenceCount() = %d, want 2", got)
+	}
+	if got := m.ExtraFileReferenceCount("org_b_config.json"); got != 0 {
+		t.Errorf("ExtraFileReferenceCount() = %d, want 0", got)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	mgr := NewManager(dir)
+	ctx := context.Background()
+	modTime := time.Date(2026, 10, 16, 9, 0, 0, 123456789, time.Local)
+	mgr.SetChecksum("model.gguf", FileChecksum{Size: 42, ModTime: modTime, SHA256: "abc"})
+	if err := mgr.Save(ctx); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded := NewManager(dir)
+	if err := loaded.Load(ctx); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		size     int64
+		modTime  time.Time
+		want     string
+	}{
+		{"unchanged", "model.gguf", 42, modTime.UTC(), "abc"},
+		{"size changed", "model.gguf", 43, modTime, ""},
+		{"modified", "model.gguf", 42, modTime.Add(time.Nanosecond), ""},
+		{"not recorded", "other.gguf", 42, modTime, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := loaded.Checksum(tt.filename, tt.size, tt.modTime)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Checksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveForgetsChecksums(t *testing.T) {
+	// Arrange
+	mgr := NewManager(t.TempDir())
+	modTime := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	if err := mgr.Add(ModelEntry{Repo: "org/repo", Quant: "Q4_K_M", Filename: "a-00001.gguf", Shards: []string{"a-00001.gguf", "a-00002.gguf"}}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	mgr.AddLora(LoraEntry{Repo: "org/lora", File: "adapter.gguf", Filename: "org_lora_adapter.gguf"})
+	for _, f := range []string{"a-00001.gguf", "a-00002.gguf", "org_lora_adapter.gguf", "b.gguf"} {
+		mgr.SetChecksum(f, FileChecksum{Size: 1, ModTime: modTime, SHA256: "sum"})
+	}
+
+	// Act
+	if err := mgr.Remove("org/repo", "Q4_K_M"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	mgr.RemoveLora("org/lora", "adapter.gguf")
+
+	// Assert
+	for _, f := range []string{"a-00001.gguf", "a-00002.gguf", "org_lora_adapter.gguf"} {
+		if mgr.Checksum(f, 1, modTime) != "" {
+			t.Errorf("checksum of %s should be forgotten", f)
+		}
+	}
+	if mgr.Checksum("b.gguf", 1, modTime) != "sum" {
+		t.Error("checksum of another file should be kept")
+	}
+}
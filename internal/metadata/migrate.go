@@ -0,0 +1,60 @@
+package metadata
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/migrate"
+)
+
+// FileName is the metadata file in the models directory.
+const FileName = ".metadata.json"
+
+// SchemaVersion is the metadata format written by this build.
+const SchemaVersion = 1
+
+var schema = migrate.Schema[*Metadata]{
+	Steps: []func(*Metadata) error{
+		normalizeEntries, // 0 → 1
+	},
+	Decode: func(data []byte) (*Metadata, error) {
+		var meta Metadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, err
+		}
+		return &meta, nil
+	},
+	Encode: func(meta *Metadata) ([]byte, error) {
+		return json.MarshalIndent(meta, "", "  ")
+	},
+	Version:    func(meta *Metadata) (int, error) { return meta.SchemaVersion, nil },
+	SetVersion: func(meta *Metadata, version int) { meta.SchemaVersion = version },
+}
+
+// Migrate upgrades the metadata file in modelsDir to SchemaVersion,
+// keeping the original as a backup. It returns nil when there was nothing
+// to upgrade.
+func Migrate(modelsDir string) (*migrate.Result, error) {
+	return schema.File(filepath.Join(modelsDir, FileName))
+}
+
+// normalizeEntries upgrades entries written before identifier prefixes and
+// mmproj tracking settled: repos are stored without "h:", a missing models
+// list is empty, and an mmproj entry without a file is no mmproj.
+func normalizeEntries(meta *Metadata) error {
+	if meta.Models == nil {
+		meta.Models = []ModelEntry{}
+	}
+	for i := range meta.Models {
+		e := &meta.Models[i]
+		e.Repo = strings.TrimPrefix(e.Repo, "h:")
+		if e.Mmproj != nil && e.Mmproj.Filename == "" {
+			e.Mmproj = nil
+		}
+	}
+	for i := range meta.Loras {
+		meta.Loras[i].Repo = strings.TrimPrefix(meta.Loras[i].Repo, "h:")
+	}
+	return nil
+}
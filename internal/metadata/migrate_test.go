@@ -0,0 +1,70 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const metadataV0 = `{
+  "models": [
+    {"repo": "h:org/model", "quant": "Q4_K_M", "filename": "model.gguf", "size": 10, "mmproj": {"filename": "", "size": 0}},
+    {"repo": "org/vision", "quant": "Q8_0", "filename": "vision.gguf", "size": 20, "mmproj": {"filename": "mmproj.gguf", "size": 5}}
+  ]
+}`
+
+func TestMigrate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(metadataV0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	res, err := Migrate(dir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if res == nil || res.From != 0 || res.To != SchemaVersion {
+		t.Fatalf("Migrate() = %+v, want upgrade from 0", res)
+	}
+	var meta Metadata
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatalf("migrated metadata does not parse: %v", err)
+	}
+	if meta.SchemaVersion != SchemaVersion {
+		t.Errorf("schema_version = %d, want %d", meta.SchemaVersion, SchemaVersion)
+	}
+	if meta.Models[0].Repo != "org/model" || meta.Models[0].Mmproj != nil {
+		t.Errorf("models[0] = %+v, want repo without h: and no empty mmproj", meta.Models[0])
+	}
+	if meta.Models[1].Mmproj == nil || meta.Models[1].Mmproj.Filename != "mmproj.gguf" {
+		t.Errorf("models[1].Mmproj = %+v, want it kept", meta.Models[1].Mmproj)
+	}
+}
+
+func TestLoad_ReadsOldFormatAsUpgraded(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte(metadataV0), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(dir)
+
+	// Act
+	err := mgr.Load(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entry := mgr.Find("org/model", "Q4_K_M"); entry == nil || entry.Mmproj != nil {
+		t.Errorf("Find(org/model) = %+v, want the upgraded entry", entry)
+	}
+}
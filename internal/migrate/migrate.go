@@ -0,0 +1,148 @@
+// Package migrate upgrades files written by older alpaca versions to the
+// current format, in place and with a backup of the original.
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Schema describes the versions of a file format. A document records its
+// version in a field of its own; files from before versioning have none
+// and are version 0.
+type Schema[D any] struct {
+	// Steps[i] upgrades a document from version i to i+1, so the current
+	// version is len(Steps). Steps must tolerate documents that already
+	// have the change, e.g. files edited by hand.
+	Steps []func(doc D) error
+
+	Decode     func(data []byte) (D, error)
+	Encode     func(doc D) ([]byte, error)
+	Version    func(doc D) (int, error)
+	SetVersion func(doc D, version int)
+}
+
+// Current returns the version Steps upgrade to.
+func (s Schema[D]) Current() int {
+	return len(s.Steps)
+}
+
+// Result describes an upgraded file.
+type Result struct {
+	Path   string
+	From   int
+	To     int
+	Backup string // copy of the file before the upgrade
+}
+
+// NewerError is returned for a file written by a newer alpaca, which this
+// build cannot read reliably.
+type NewerError struct {
+	Path    string
+	Version int
+	Current int
+}
+
+func (e *NewerError) Error() string {
+	name := e.Path
+	if name == "" {
+		name = "file"
+	}
+	return fmt.Sprintf("%s was written by a newer alpaca (format version %d, this build reads up to %d); upgrade alpaca", name, e.Version, e.Current)
+}
+
+// Upgrade applies the steps from the document's version to the current one
+// in memory and returns the version it had. Newer documents are left alone
+// and reported with a NewerError.
+func (s Schema[D]) Upgrade(doc D) (int, error) {
+	from, err := s.Version(doc)
+	if err != nil {
+		return 0, err
+	}
+	if from > s.Current() {
+		return from, &NewerError{Version: from, Current: s.Current()}
+	}
+	for v := from; v < s.Current(); v++ {
+		if err := s.Steps[v](doc); err != nil {
+			return from, fmt.Errorf("upgrade from version %d: %w", v, err)
+		}
+	}
+	if from < s.Current() {
+		s.SetVersion(doc, s.Current())
+	}
+	return from, nil
+}
+
+// File upgrades the file at path to the current version. It returns nil
+// and no error when the file is missing, empty, or already current. The
+// original is kept next to it as <path>.v<version>.bak, and the upgraded
+// file replaces it atomically.
+func (s Schema[D]) File(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || err == nil && len(data) == 0 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	from, err := s.Upgrade(doc)
+	var newer *NewerError
+	if errors.As(err, &newer) {
+		newer.Path = path
+		return nil, newer
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if from == s.Current() {
+		return nil, nil
+	}
+	out, err := s.Encode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", path, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if err := os.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return nil, fmt.Errorf("back up %s: %w", path, err)
+	}
+	if err := writeAtomic(path, out, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+	return &Result{Path: path, From: from, To: s.Current(), Backup: backup}, nil
+}
+
+// writeAtomic replaces path with data through a temporary file in the same
+// directory, so readers never see a partial file.
+func writeAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
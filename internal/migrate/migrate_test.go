@@ -0,0 +1,120 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// lines is a test format: the first line is the version, the rest are
+// entries. Step 0 → 1 upper-cases the entries and 1 → 2 appends "v2".
+type lines struct {
+	version int
+	entries []string
+}
+
+var testSchema = Schema[*lines]{
+	Steps: []func(*lines) error{
+		func(l *lines) error {
+			for i, e := range l.entries {
+				l.entries[i] = strings.ToUpper(e)
+			}
+			return nil
+		},
+		func(l *lines) error {
+			l.entries = append(l.entries, "v2")
+			return nil
+		},
+	},
+	Decode: func(data []byte) (*lines, error) {
+		parts := strings.Split(strings.TrimSpace(string(data)), "\n")
+		v, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		return &lines{version: v, entries: parts[1:]}, nil
+	},
+	Encode: func(l *lines) ([]byte, error) {
+		return []byte(strconv.Itoa(l.version) + "\n" + strings.Join(l.entries, "\n") + "\n"), nil
+	},
+	Version:    func(l *lines) (int, error) { return l.version, nil },
+	SetVersion: func(l *lines, v int) { l.version = v },
+}
+
+func TestSchemaFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // empty for no file
+		want     string // file content afterwards
+		wantFrom int    // -1 for no upgrade
+	}{
+		{"missing file", "", "", -1},
+		{"current", "2\nA\nv2\n", "2\nA\nv2\n", -1},
+		{"from 0", "0\na\nb\n", "2\nA\nB\nv2\n", 0},
+		{"from 1", "1\na\n", "2\na\nv2\n", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "file")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Act
+			res, err := testSchema.File(path)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("File() error = %v", err)
+			}
+			if tt.wantFrom < 0 {
+				if res != nil {
+					t.Errorf("File() = %+v, want no upgrade", res)
+				}
+			} else {
+				if res == nil || res.From != tt.wantFrom || res.To != 2 {
+					t.Fatalf("File() = %+v, want upgrade from %d to 2", res, tt.wantFrom)
+				}
+				backup, err := os.ReadFile(res.Backup)
+				if err != nil || string(backup) != tt.content {
+					t.Errorf("backup = %q, %v, want the original %q", backup, err, tt.content)
+				}
+				if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+					t.Errorf("upgraded file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+				}
+			}
+			if tt.content == "" {
+				return
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaFile_Newer(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "file")
+	os.WriteFile(path, []byte("3\nx\n"), 0644)
+
+	// Act
+	res, err := testSchema.File(path)
+
+	// Assert
+	var newer *NewerError
+	if !errors.As(err, &newer) || newer.Version != 3 || newer.Current != 2 || newer.Path != path {
+		t.Fatalf("File() = %+v, %v, want NewerError for version 3", res, err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "3\nx\n" {
+		t.Errorf("file = %q, want it untouched", got)
+	}
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/blob"
+)
+
+// RepoUsage is the disk space the files of one repository take.
+type RepoUsage struct {
+	Repo   string
+	Quants int   // downloaded quants
+	Models int64 // model files, every shard of every quant
+	Mmproj int64
+	Loras  int64
+	Extra  int64 // files downloaded with pull --with-files
+}
+
+// Total returns the space all of the repository's files take.
+func (r RepoUsage) Total() int64 {
+	return r.Models + r.Mmproj + r.Loras + r.Extra
+}
+
+// DiskUsage is the disk space the models directory takes, by what uses it.
+type DiskUsage struct {
+	Repos   []RepoUsage // largest first
+	Partial int64       // partial downloads, with their sidecar files
+	Unused  int64       // blobs nothing refers to; gc deletes them
+	Other   int64       // files alpaca did not download, such as its metadata
+}
+
+// Total returns the space the models directory takes.
+func (u *DiskUsage) Total() int64 {
+	total := u.Partial + u.Unused + u.Other
+	for _, r := range u.Repos {
+		total += r.Total()
+	}
+	return total
+}
+
+// DiskUsage adds up the size of every file in the models directory. A
+// file stored once in the blob store counts once, for the first
+// repository that refers to it. Files imported with --link live outside
+// the models directory and are not counted.
+func (m *Manager) DiskUsage(ctx context.Context) (*DiskUsage, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+
+	counted := make(map[string]bool)
+	size := func(name string) int64 {
+		if b, ok := m.blobs.Target(name); ok {
+			name = b
+		}
+		if counted[name] {
+			return 0
+		}
+		info, err := os.Lstat(filepath.Join(m.modelsDir, name))
+		if err != nil || !info.Mode().IsRegular() {
+			return 0
+		}
+		counted[name] = true
+		return info.Size()
+	}
+
+	repos := make(map[string]*RepoUsage)
+	repo := func(name string) *RepoUsage {
+		r := repos[name]
+		if r == nil {
+			r = &RepoUsage{Repo: name}
+			repos[name] = r
+		}
+		return r
+	}
+	for _, e := range m.metadata.List() {
+		r := repo(e.Repo)
+		r.Quants++
+		for _, f := range e.Files() {
+			r.Models += size(f)
+		}
+		if e.Mmproj != nil {
+			r.Mmproj += size(e.Mmproj.Filename)
+		}
+		for _, f := range e.ExtraFiles {
+			r.Extra += size(f.Filename)
+		}
+	}
+	for _, l := range m.metadata.ListLoras() {
+		repo(l.Repo).Loras += size(l.Filename)
+	}
+
+	usage := &DiskUsage{}
+	for _, r := range repos {
+		usage.Repos = append(usage.Repos, *r)
+	}
+	slices.SortFunc(usage.Repos, func(a, b RepoUsage) int {
+		return cmp.Or(cmp.Compare(b.Total(), a.Total()), strings.Compare(a.Repo, b.Repo))
+	})
+
+	// Whatever metadata does not account for
+	err := filepath.WalkDir(m.modelsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(m.modelsDir, path)
+		if err != nil || counted[rel] {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed while walking
+		}
+		switch {
+		case strings.HasSuffix(rel, partSuffix), strings.HasSuffix(rel, etagSuffix), strings.HasSuffix(rel, progressSuffix):
+			usage.Partial += info.Size()
+		case filepath.Dir(rel) == blob.Dir:
+			usage.Unused += info.Size()
+		default:
+			usage.Other += info.Size()
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("scan models directory: %w", err)
+	}
+	return usage, nil
+}
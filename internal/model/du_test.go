@@ -0,0 +1,79 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+func TestDiskUsage(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	files := map[string]string{
+		"org_a-Q4_K_M.gguf":      "0123456789",
+		"org_a-Q8_0.gguf":        "01234567890123456789",
+		"org_a-mmproj.gguf":      "01234",
+		"org_b-Q4_K_M.gguf":      "012",
+		"org_b-adapter.gguf":     "01",
+		"org_c-Q4_K_M.gguf.part": "0123456",
+		"org_c-Q4_K_M.gguf.etag": "01",
+		"unused.gguf":            "0123",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := blob.New(dir)
+	for _, name := range []string{"org_a-Q4_K_M.gguf", "unused.gguf"} {
+		if err := store.Adopt(name, hashOf(t, dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.Remove(filepath.Join(dir, "unused.gguf"))
+
+	meta := metadata.NewManager(dir)
+	if err := meta.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	mmproj := &metadata.MmprojEntry{Filename: "org_a-mmproj.gguf"}
+	meta.Add(metadata.ModelEntry{Repo: "org/a", Quant: "Q4_K_M", Filename: "org_a-Q4_K_M.gguf", Mmproj: mmproj})
+	meta.Add(metadata.ModelEntry{Repo: "org/a", Quant: "Q8_0", Filename: "org_a-Q8_0.gguf", Mmproj: mmproj})
+	meta.Add(metadata.ModelEntry{Repo: "org/b", Quant: "Q4_K_M", Filename: "org_b-Q4_K_M.gguf"})
+	meta.AddLora(metadata.LoraEntry{Repo: "org/b", File: "adapter.gguf", Filename: "org_b-adapter.gguf"})
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, metadata.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	usage, err := NewManager(dir).DiskUsage(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("DiskUsage() error = %v", err)
+	}
+	want := &DiskUsage{
+		Repos: []RepoUsage{
+			{Repo: "org/a", Quants: 2, Models: 30, Mmproj: 5},
+			{Repo: "org/b", Quants: 1, Models: 3, Loras: 2},
+		},
+		Partial: 9,
+		Unused:  4,
+		Other:   info.Size(),
+	}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("DiskUsage() = %+v, want %+v", usage, want)
+	}
+	if got := usage.Total(); got != 53+info.Size() {
+		t.Errorf("Total() = %d, want %d", got, 53+info.Size())
+	}
+}
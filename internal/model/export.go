@@ -0,0 +1,273 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// ManifestSuffix ends the name of the manifest Export writes next to the
+// files it copies, e.g. qwen3-8b-Q4_K_M.alpaca.json.
+const ManifestSuffix = ".alpaca.json"
+
+// manifestFormat is the version of the manifest format Export writes.
+const manifestFormat = 1
+
+// Manifest describes a model copied out of the models directory by Export,
+// so ImportManifest can register it on another machine.
+type Manifest struct {
+	Format   int            `json:"format"`
+	Registry string         `json:"registry,omitempty"` // identifier scheme; empty for HuggingFace
+	Repo     string         `json:"repo"`
+	Quant    string         `json:"quant"`
+	Files    []ManifestFile `json:"files"` // the model's files, shards in order
+	Mmproj   *ManifestFile  `json:"mmproj,omitempty"`
+}
+
+// ManifestFile is a file listed in a Manifest.
+type ManifestFile struct {
+	Name   string `json:"name"` // in the manifest's directory
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Export copies the files of a downloaded model, including its mmproj, to
+// destDir and writes a Manifest of them there. With link the files are
+// hard-linked instead, which needs destDir on the same file system. Files
+// that exist in destDir are not overwritten. It returns the manifest path.
+func (m *Manager) Export(ctx context.Context, repo, quant, destDir string, link bool) (string, *Manifest, error) {
+	entry, err := m.GetDetails(ctx, repo, quant)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", nil, fmt.Errorf("create %s: %w", destDir, err)
+	}
+
+	manifest := &Manifest{Format: manifestFormat, Registry: entry.Registry, Repo: entry.Repo, Quant: entry.Quant}
+	var written []string
+	export := func(name string) (*ManifestFile, error) {
+		f, err := m.exportFile(name, destDir, link)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, filepath.Join(destDir, f.Name))
+		return f, nil
+	}
+	for _, name := range entry.Files() {
+		f, err := export(name)
+		if err != nil {
+			removeAll(written)
+			return "", nil, err
+		}
+		manifest.Files = append(manifest.Files, *f)
+	}
+	if entry.Mmproj != nil {
+		if manifest.Mmproj, err = export(entry.Mmproj.Filename); err != nil {
+			removeAll(written)
+			return "", nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		removeAll(written)
+		return "", nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	path := filepath.Join(destDir, strings.TrimSuffix(filepath.Base(entry.Filename), ".gguf")+ManifestSuffix)
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		removeAll(written)
+		return "", nil, fmt.Errorf("write manifest: %w", err)
+	}
+	return path, manifest, nil
+}
+
+// exportFile copies or hard-links the model file name to destDir and
+// describes the result. Files in the blob store are not read again to
+// hash them when linked, since their blob names are their hashes.
+func (m *Manager) exportFile(name, destDir string, link bool) (*ManifestFile, error) {
+	src := filepath.Join(m.modelsDir, name)
+	dst := filepath.Join(destDir, filepath.Base(name))
+	if _, err := os.Lstat(dst); err == nil {
+		return nil, fmt.Errorf("file already exists: %s", dst)
+	}
+
+	var sum string
+	var err error
+	if link {
+		var resolved string
+		if resolved, err = filepath.EvalSymlinks(src); err == nil {
+			err = os.Link(resolved, dst)
+		}
+		if err == nil {
+			if b, ok := m.blobs.Target(name); ok {
+				sum = blob.Sum(b)
+			} else {
+				sum, err = hashFile(dst)
+			}
+		}
+	} else {
+		sum, err = copyFile(src, dst)
+	}
+	if err != nil {
+		os.Remove(dst)
+		return nil, fmt.Errorf("export %s: %w", name, err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		os.Remove(dst)
+		return nil, fmt.Errorf("export %s: %w", name, err)
+	}
+	return &ManifestFile{Name: filepath.Base(dst), Size: info.Size(), SHA256: sum}, nil
+}
+
+// ImportManifest registers the model described by a manifest Export wrote,
+// copying its files into the models directory and verifying their SHA256.
+// Files whose content is already stored are not copied again; an mmproj
+// shared with a downloaded model is reused.
+func (m *Manager) ImportManifest(ctx context.Context, path string) (*metadata.ModelEntry, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve manifest path: %w", err)
+	}
+	manifest, err := readManifest(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	if m.metadata.Find(manifest.Repo, manifest.Quant) != nil {
+		return nil, fmt.Errorf("model already exists: %s", identifier.Model(manifest.Registry, manifest.Repo, manifest.Quant))
+	}
+	if err := os.MkdirAll(m.modelsDir, 0755); err != nil {
+		return nil, fmt.Errorf("create models directory: %w", err)
+	}
+
+	srcDir := filepath.Dir(absPath)
+	var imported []string
+	fail := func(err error) (*metadata.ModelEntry, error) {
+		for _, name := range imported {
+			os.Remove(filepath.Join(m.modelsDir, name))
+		}
+		return nil, err
+	}
+
+	entry := metadata.ModelEntry{
+		Repo:         manifest.Repo,
+		Quant:        manifest.Quant,
+		Filename:     manifest.Files[0].Name,
+		SHA256:       manifest.Files[0].SHA256,
+		Source:       absPath,
+		Registry:     manifest.Registry,
+		DownloadedAt: time.Now().UTC(),
+	}
+	for _, f := range manifest.Files {
+		if _, err := m.importFile(srcDir, f, false); err != nil {
+			return fail(err)
+		}
+		imported = append(imported, f.Name)
+		entry.Size += f.Size
+		if len(manifest.Files) > 1 {
+			entry.Shards = append(entry.Shards, f.Name)
+		}
+	}
+	if f := manifest.Mmproj; f != nil {
+		added, err := m.importFile(srcDir, *f, true)
+		if err != nil {
+			return fail(err)
+		}
+		if added {
+			imported = append(imported, f.Name)
+		}
+		entry.Mmproj = &metadata.MmprojEntry{Filename: f.Name, Size: f.Size, SHA256: f.SHA256}
+	}
+
+	entry.ReadGGUFInfo(m.modelsDir)
+	if err := m.metadata.Add(entry); err != nil {
+		return fail(fmt.Errorf("add metadata entry: %w", err))
+	}
+	if err := m.metadata.Save(ctx); err != nil {
+		return fail(fmt.Errorf("save metadata: %w", err))
+	}
+	return &entry, nil
+}
+
+// importFile stores the manifest file f from srcDir in the models
+// directory and reports whether it added it. A shared file, such as an
+// mmproj, may already be there with the same content and is then kept.
+func (m *Manager) importFile(srcDir string, f ManifestFile, shared bool) (bool, error) {
+	if _, err := os.Lstat(filepath.Join(m.modelsDir, f.Name)); err == nil {
+		if b, ok := m.blobs.Target(f.Name); shared && ok && blob.Sum(b) == f.SHA256 {
+			return false, nil
+		}
+		return false, fmt.Errorf("file already exists in models directory: %s", f.Name)
+	}
+	if m.blobs.Has(f.SHA256) {
+		return true, m.blobs.Link(f.Name, f.SHA256)
+	}
+
+	dst := filepath.Join(m.modelsDir, f.Name)
+	sum, err := copyFile(filepath.Join(srcDir, f.Name), dst)
+	if err != nil {
+		os.Remove(dst)
+		return false, fmt.Errorf("import %s: %w", f.Name, err)
+	}
+	if sum != f.SHA256 {
+		os.Remove(dst)
+		return false, fmt.Errorf("integrity verification failed for %s: hash mismatch: expected %s, got %s", f.Name, f.SHA256, sum)
+	}
+	if err := m.blobs.Adopt(f.Name, sum); err != nil {
+		os.Remove(dst)
+		return false, fmt.Errorf("import %s: %w", f.Name, err)
+	}
+	return true, nil
+}
+
+// readManifest reads and checks a manifest written by Export.
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if manifest.Format != manifestFormat {
+		return nil, fmt.Errorf("manifest %s has format %d; this alpaca reads format %d", path, manifest.Format, manifestFormat)
+	}
+	if manifest.Repo == "" || manifest.Quant == "" || len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("manifest %s needs repo, quant and files", path)
+	}
+	files := manifest.Files
+	if manifest.Mmproj != nil {
+		files = append(files[:len(files):len(files)], *manifest.Mmproj)
+	}
+	for _, f := range files {
+		if f.Name != filepath.Base(f.Name) || !strings.HasSuffix(strings.ToLower(f.Name), ".gguf") {
+			return nil, fmt.Errorf("manifest %s: invalid file name %q", path, f.Name)
+		}
+		if b, err := hex.DecodeString(f.SHA256); err != nil || len(b) != sha256.Size || f.SHA256 != strings.ToLower(f.SHA256) {
+			return nil, fmt.Errorf("manifest %s: %s needs a lowercase hex sha256, got %q", path, f.Name, f.SHA256)
+		}
+	}
+	return &manifest, nil
+}
+
+// removeAll deletes the given files, ignoring errors.
+func removeAll(paths []string) {
+	for _, p := range paths {
+		os.Remove(p)
+	}
+}
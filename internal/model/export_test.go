@@ -0,0 +1,162 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// newExportSource returns a models directory holding org/vision:Q4_K_M
+// with an mmproj.
+func newExportSource(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "vision-Q4_K_M.gguf"), []byte("weights"), 0644)
+	os.WriteFile(filepath.Join(dir, "mmproj-f16.gguf"), []byte("projector"), 0644)
+	meta := metadata.NewManager(dir)
+	if err := meta.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	meta.Add(metadata.ModelEntry{
+		Repo:     "org/vision",
+		Quant:    "Q4_K_M",
+		Filename: "vision-Q4_K_M.gguf",
+		Size:     7,
+		Mmproj:   &metadata.MmprojEntry{Filename: "mmproj-f16.gguf", Size: 9},
+	})
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestExportAndImportManifest(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	src := newExportSource(t)
+	dest := filepath.Join(t.TempDir(), "usb")
+	target := t.TempDir()
+
+	// Act
+	manifestPath, manifest, err := NewManager(src).Export(ctx, "org/vision", "Q4_K_M", dest, false)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	entry, err := NewManager(target).ImportManifest(ctx, manifestPath)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ImportManifest() error = %v", err)
+	}
+	if filepath.Base(manifestPath) != "vision-Q4_K_M"+ManifestSuffix {
+		t.Errorf("manifest = %s, want vision-Q4_K_M%s", manifestPath, ManifestSuffix)
+	}
+	if manifest.Files[0].SHA256 != hashOf(t, src, "vision-Q4_K_M.gguf") || manifest.Mmproj == nil {
+		t.Errorf("manifest = %+v, want the model hash and an mmproj", manifest)
+	}
+	if entry.Repo != "org/vision" || entry.Quant != "Q4_K_M" || entry.Size != 7 || entry.Mmproj == nil || entry.Source != manifestPath {
+		t.Errorf("entry = %+v", entry)
+	}
+	for _, name := range []string{"vision-Q4_K_M.gguf", "mmproj-f16.gguf"} {
+		if got := hashOf(t, target, name); got != hashOf(t, dest, name) {
+			t.Errorf("%s hash = %s, want the exported content", name, got)
+		}
+	}
+	if _, err := NewManager(target).ImportManifest(ctx, manifestPath); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second ImportManifest() error = %v, want already exists", err)
+	}
+}
+
+func TestImportManifest_SharedMmproj(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	src := newExportSource(t)
+	dest := t.TempDir()
+	target := t.TempDir()
+	manifestPath, _, err := NewManager(src).Export(ctx, "org/vision", "Q4_K_M", dest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager(target).ImportManifest(ctx, manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dest, "other")
+	os.MkdirAll(other, 0755)
+	manifest, _ := os.ReadFile(manifestPath)
+	manifest = []byte(strings.ReplaceAll(string(manifest), "Q4_K_M", "Q8_0"))
+	os.WriteFile(filepath.Join(other, "vision-Q8_0.gguf"), []byte("weights"), 0644)
+	os.WriteFile(filepath.Join(other, "vision-Q8_0"+ManifestSuffix), manifest, 0644)
+
+	// Act
+	entry, err := NewManager(target).ImportManifest(ctx, filepath.Join(other, "vision-Q8_0"+ManifestSuffix))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ImportManifest() error = %v", err)
+	}
+	if entry.Mmproj == nil || entry.Mmproj.Filename != "mmproj-f16.gguf" {
+		t.Errorf("Mmproj = %+v, want the shared mmproj-f16.gguf", entry.Mmproj)
+	}
+}
+
+func TestImportManifest_HashMismatch(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	dest := t.TempDir()
+	target := t.TempDir()
+	manifestPath, _, err := NewManager(newExportSource(t)).Export(ctx, "org/vision", "Q4_K_M", dest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dest, "mmproj-f16.gguf"), []byte("corrupted"), 0644)
+
+	// Act
+	_, err = NewManager(target).ImportManifest(ctx, manifestPath)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "integrity verification failed") {
+		t.Fatalf("ImportManifest() error = %v, want a hash mismatch", err)
+	}
+	for _, name := range []string{"vision-Q4_K_M.gguf", "mmproj-f16.gguf"} {
+		if _, err := os.Lstat(filepath.Join(target, name)); !os.IsNotExist(err) {
+			t.Errorf("%s left in the models directory", name)
+		}
+	}
+	if ok, _ := NewManager(target).Exists(ctx, "org/vision", "Q4_K_M"); ok {
+		t.Error("model registered despite the mismatch")
+	}
+}
+
+func TestImportManifest_Invalid(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"other format", `{"format":2,"repo":"org/m","quant":"Q4_K_M","files":[{"name":"m.gguf","sha256":"` + sum + `"}]}`, "format 2"},
+		{"no files", `{"format":1,"repo":"org/m","quant":"Q4_K_M"}`, "needs repo, quant and files"},
+		{"path in name", `{"format":1,"repo":"org/m","quant":"Q4_K_M","files":[{"name":"../m.gguf","sha256":"` + sum + `"}]}`, "invalid file name"},
+		{"bad hash", `{"format":1,"repo":"org/m","quant":"Q4_K_M","files":[{"name":"m.gguf","sha256":"../x"}]}`, "lowercase hex sha256"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), "m"+ManifestSuffix)
+			os.WriteFile(path, []byte(tt.manifest), 0644)
+
+			// Act
+			_, err := NewManager(t.TempDir()).ImportManifest(context.Background(), path)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+)
+
+// gcGracePeriod spares recently stored blobs: a pull in progress stores its
+// files before it records them in metadata.
+const gcGracePeriod = time.Hour
+
+// GCResult reports what GC did (or, in a dry run, would do).
+type GCResult struct {
+	Migrated []string    // files moved into the blob store
+	Removed  []blob.Info // unreferenced blobs deleted
+}
+
+// Freed returns the disk space released by the removed blobs.
+func (r *GCResult) Freed() int64 {
+	var n int64
+	for _, b := range r.Removed {
+		n += b.Size
+	}
+	return n
+}
+
+// GC moves files downloaded before the blob store existed into it, then
+// deletes blobs no model, mmproj or LoRA adapter refers to. Files imported
+// with --link are left alone. With dryRun nothing is changed; files are not
+// hashed, so blobs a migration would reuse may be reported as removable.
+func (m *Manager) GC(ctx context.Context, dryRun bool) (*GCResult, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+
+	result := &GCResult{}
+	for _, f := range m.metadata.AllFiles() {
+		info, err := os.Lstat(filepath.Join(m.modelsDir, f))
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		result.Migrated = append(result.Migrated, f)
+		if dryRun {
+			continue
+		}
+		sum, err := hashFile(filepath.Join(m.modelsDir, f))
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", f, err)
+		}
+		if err := m.blobs.Adopt(f, sum); err != nil {
+			return nil, err
+		}
+	}
+
+	blobs, err := m.blobs.List()
+	if err != nil {
+		return nil, err
+	}
+	referenced := m.referencedBlobs()
+	for _, b := range blobs {
+		if referenced[b.Name] || time.Since(b.ModTime) < gcGracePeriod {
+			continue
+		}
+		if !dryRun {
+			if err := m.blobs.Remove(b.Name); err != nil {
+				return nil, err
+			}
+		}
+		result.Removed = append(result.Removed, b)
+	}
+	return result, nil
+}
+
+// referencedBlobs returns the blobs that files recorded in metadata link to.
+func (m *Manager) referencedBlobs() map[string]bool {
+	referenced := make(map[string]bool)
+	for _, f := range m.metadata.AllFiles() {
+		if b, ok := m.blobs.Target(f); ok {
+			referenced[b] = true
+		}
+	}
+	return referenced
+}
+
+// removeUnreferencedBlobs deletes those of blobs (left behind by removing
+// their last link) that no remaining metadata entry refers to.
+func (m *Manager) removeUnreferencedBlobs(blobs []string) error {
+	if len(blobs) == 0 {
+		return nil
+	}
+	referenced := m.referencedBlobs()
+	for _, b := range blobs {
+		if referenced[b] {
+			continue
+		}
+		if err := m.blobs.Remove(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// addEntry records filename as repo's Q4_K_M model in modelsDir's metadata.
+func addEntry(t *testing.T, modelsDir, repo, filename string) {
+	t.Helper()
+	meta := metadata.NewManager(modelsDir)
+	if err := meta.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.Add(metadata.ModelEntry{Repo: repo, Quant: "Q4_K_M", Filename: filename}); err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// hashOf returns the SHA256 of name in dir.
+func hashOf(t *testing.T, dir, name string) string {
+	t.Helper()
+	sum, err := hashFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sum
+}
+
+func TestGC_MigratesOldLayoutAndRemovesOrphans(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	addEntry(t, dir, "org/model", "model.gguf")
+
+	store := blob.New(dir)
+	os.WriteFile(filepath.Join(dir, "orphan.gguf"), []byte("old"), 0644)
+	os.WriteFile(filepath.Join(dir, "recent.gguf"), []byte("new"), 0644)
+	if err := store.Adopt("orphan.gguf", hashOf(t, dir, "orphan.gguf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Adopt("recent.gguf", hashOf(t, dir, "recent.gguf")); err != nil {
+		t.Fatal(err)
+	}
+	orphan, _ := store.Target("orphan.gguf")
+	os.Remove(filepath.Join(dir, "orphan.gguf"))
+	os.Remove(filepath.Join(dir, "recent.gguf"))
+	old := time.Now().Add(-2 * gcGracePeriod)
+	os.Chtimes(filepath.Join(dir, orphan), old, old)
+
+	mgr := NewManager(dir)
+
+	// Act
+	result, err := mgr.GC(context.Background(), false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(result.Migrated) != 1 || result.Migrated[0] != "model.gguf" {
+		t.Errorf("Migrated = %v, want [model.gguf]", result.Migrated)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Name != orphan || result.Freed() != 3 {
+		t.Errorf("Removed = %+v, want only %s (3 bytes)", result.Removed, orphan)
+	}
+	if _, ok := store.Target("model.gguf"); !ok {
+		t.Error("model.gguf not linked into the blob store")
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "model.gguf")); err != nil || string(got) != "weights" {
+		t.Errorf("model.gguf = %q, %v, want weights", got, err)
+	}
+	blobs, _ := store.List()
+	if len(blobs) != 2 {
+		t.Errorf("blobs left = %+v, want the model and the recent blob", blobs)
+	}
+}
+
+func TestGC_DryRunChangesNothing(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0644)
+	addEntry(t, dir, "org/model", "model.gguf")
+	mgr := NewManager(dir)
+
+	// Act
+	result, err := mgr.GC(context.Background(), true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GC() error = %v", err)
+	}
+	if len(result.Migrated) != 1 {
+		t.Errorf("Migrated = %v, want [model.gguf]", result.Migrated)
+	}
+	if fi, err := os.Lstat(filepath.Join(dir, "model.gguf")); err != nil || !fi.Mode().IsRegular() {
+		t.Errorf("model.gguf changed by dry run: %v", err)
+	}
+}
+
+func TestRemove_KeepsBlobSharedWithOtherModel(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	store := blob.New(dir)
+	for _, name := range []string{"a.gguf", "b.gguf"} {
+		os.WriteFile(filepath.Join(dir, name), []byte("weights"), 0644)
+		if err := store.Adopt(name, hashOf(t, dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addEntry(t, dir, "org/a", "a.gguf")
+	addEntry(t, dir, "org/b", "b.gguf")
+	shared, _ := store.Target("a.gguf")
+	mgr := NewManager(dir)
+	ctx := context.Background()
+
+	// Act
+	if err := mgr.Remove(ctx, "org/a", "Q4_K_M"); err != nil {
+		t.Fatalf("Remove(a) error = %v", err)
+	}
+	_, sharedErr := os.Stat(filepath.Join(dir, shared))
+	if err := mgr.Remove(ctx, "org/b", "Q4_K_M"); err != nil {
+		t.Fatalf("Remove(b) error = %v", err)
+	}
+	_, lastErr := os.Stat(filepath.Join(dir, shared))
+
+	// Assert
+	if sharedErr != nil {
+		t.Errorf("blob removed while still used by org/b: %v", sharedErr)
+	}
+	if !os.IsNotExist(lastErr) {
+		t.Errorf("blob kept after its last model was removed: %v", lastErr)
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LocalModel is a model in a directory of GGUF files alpaca does not
+// manage, e.g. one filled by hand or by another tool.
+type LocalModel struct {
+	Path   string // the file llama-server loads: the model, or its first shard
+	Shards int    // files of a split model; 1 otherwise
+	Size   int64  // of all its files
+}
+
+// LocalDir lists what ScanDir found in a directory.
+type LocalDir struct {
+	Models  []LocalModel
+	Loras   []LocalModel // LoRA adapters
+	Mmprojs []string     // multimodal projector files
+	Skipped []string     // GGUF files that cannot be loaded, with the reason
+}
+
+// ScanDir lists the GGUF files directly in dir: models, with the shards of
+// a split model taken together, LoRA adapters, and mmproj files. Split
+// models missing shards and files that are not valid GGUF are skipped.
+func ScanDir(dir string) (*LocalDir, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	result := &LocalDir{}
+	var models [][]string // files of each model, the first shard first
+	shards := make(map[string][]string)
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.EqualFold(filepath.Ext(name), ".gguf") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if strings.Contains(strings.ToLower(name), "mmproj") {
+			result.Mmprojs = append(result.Mmprojs, path)
+			continue
+		}
+		if sm := shardPattern.FindStringSubmatch(name); sm != nil && sm[3] != "00001" {
+			shards[sm[1]] = append(shards[sm[1]], path)
+			continue
+		}
+		models = append(models, []string{path})
+	}
+	for _, prefix := range slices.Sorted(maps.Keys(shards)) {
+		files := shards[prefix]
+		slices.Sort(files)
+		count, _ := strconv.Atoi(shardPattern.FindStringSubmatch(filepath.Base(files[0]))[3])
+		if len(files) != count {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %d of %d shards present", prefix, len(files), count))
+			continue
+		}
+		models = append(models, files)
+	}
+	slices.SortFunc(models, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+
+	for _, files := range models {
+		md, err := readGGUFMetadata(files[0])
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", filepath.Base(files[0]), err))
+			continue
+		}
+		m := LocalModel{Path: files[0], Shards: len(files)}
+		for _, f := range files {
+			if info, err := os.Stat(f); err == nil {
+				m.Size += info.Size()
+			}
+		}
+		if t, _ := md.String("general.type"); t == "adapter" {
+			result.Loras = append(result.Loras, m)
+			continue
+		}
+		result.Models = append(result.Models, m)
+	}
+	return result, nil
+}
+
+// ResolveDir picks the model to load from dir, for an f: identifier that
+// names a directory: the only model in it, or the first shard of its only
+// split model. mmproj is the directory's mmproj file when there is exactly
+// one, and empty otherwise.
+func ResolveDir(dir string) (path, mmproj string, err error) {
+	scanned, err := ScanDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	switch len(scanned.Models) {
+	case 0:
+		return "", "", fmt.Errorf("no GGUF model in %s", dir)
+	case 1:
+	default:
+		names := make([]string, len(scanned.Models))
+		for i, m := range scanned.Models {
+			names[i] = filepath.Base(m.Path)
+		}
+		return "", "", fmt.Errorf("%s holds %d models (%s); name the file to load", dir, len(names), strings.Join(names, ", "))
+	}
+	if len(scanned.Mmprojs) == 1 {
+		mmproj = scanned.Mmprojs[0]
+	}
+	return scanned.Models[0].Path, mmproj, nil
+}
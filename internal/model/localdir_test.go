@@ -0,0 +1,123 @@
+package model
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanDir(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		"qwen3-Q4_K_M.gguf":              ggufFile(),
+		"Big-Q4_K_M-00001-of-00002.gguf": ggufFile(),
+		"Big-Q4_K_M-00002-of-00002.gguf": []byte("second shard"),
+		"Half-Q4_0-00001-of-00002.gguf":  ggufFile(),
+		"mmproj-model-f16.gguf":          ggufFile(),
+		"adapter-f16.gguf":               ggufFile([2]string{"general.type", "adapter"}),
+		"broken.gguf":                    []byte("not a model"),
+		"notes.txt":                      []byte("mine"),
+	})
+
+	// Act
+	scanned, err := ScanDir(dir)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	if len(scanned.Models) != 2 {
+		t.Fatalf("models = %+v, want Big and qwen3", scanned.Models)
+	}
+	big := scanned.Models[0]
+	if big.Path != filepath.Join(dir, "Big-Q4_K_M-00001-of-00002.gguf") || big.Shards != 2 || big.Size != int64(len(ggufFile())+len("second shard")) {
+		t.Errorf("models[0] = %+v, want both shards of Big", big)
+	}
+	if scanned.Models[1].Path != filepath.Join(dir, "qwen3-Q4_K_M.gguf") || scanned.Models[1].Shards != 1 {
+		t.Errorf("models[1] = %+v, want qwen3", scanned.Models[1])
+	}
+	if len(scanned.Loras) != 1 || filepath.Base(scanned.Loras[0].Path) != "adapter-f16.gguf" {
+		t.Errorf("loras = %+v, want adapter-f16.gguf", scanned.Loras)
+	}
+	if len(scanned.Mmprojs) != 1 || filepath.Base(scanned.Mmprojs[0]) != "mmproj-model-f16.gguf" {
+		t.Errorf("mmprojs = %v, want mmproj-model-f16.gguf", scanned.Mmprojs)
+	}
+	if len(scanned.Skipped) != 2 {
+		t.Errorf("skipped = %v, want the incomplete split model and broken.gguf", scanned.Skipped)
+	}
+}
+
+func TestResolveDir(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		wantPath   string
+		wantMmproj string
+		wantErr    string
+	}{
+		{
+			name:     "single model",
+			files:    []string{"qwen3-Q4_K_M.gguf"},
+			wantPath: "qwen3-Q4_K_M.gguf",
+		},
+		{
+			name:     "split model",
+			files:    []string{"Big-Q4_K_M-00001-of-00002.gguf", "Big-Q4_K_M-00002-of-00002.gguf"},
+			wantPath: "Big-Q4_K_M-00001-of-00002.gguf",
+		},
+		{
+			name:       "model with mmproj",
+			files:      []string{"gemma-3-4b-it-Q4_K_M.gguf", "mmproj-model-f16.gguf"},
+			wantPath:   "gemma-3-4b-it-Q4_K_M.gguf",
+			wantMmproj: "mmproj-model-f16.gguf",
+		},
+		{
+			name:     "two mmprojs",
+			files:    []string{"gemma-3-4b-it-Q4_K_M.gguf", "mmproj-model-f16.gguf", "mmproj-model-f32.gguf"},
+			wantPath: "gemma-3-4b-it-Q4_K_M.gguf",
+		},
+		{
+			name:    "no model",
+			files:   []string{"mmproj-model-f16.gguf"},
+			wantErr: "no GGUF model",
+		},
+		{
+			name:    "several models",
+			files:   []string{"a-Q4_K_M.gguf", "b-Q4_K_M.gguf"},
+			wantErr: "holds 2 models (a-Q4_K_M.gguf, b-Q4_K_M.gguf)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			files := make(map[string][]byte)
+			for _, f := range tt.files {
+				files[f] = ggufFile()
+			}
+			writeFiles(t, dir, files)
+
+			// Act
+			path, mmproj, err := ResolveDir(dir)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveDir() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveDir() error = %v", err)
+			}
+			if path != filepath.Join(dir, tt.wantPath) {
+				t.Errorf("path = %q, want %q", path, tt.wantPath)
+			}
+			if tt.wantMmproj == "" && mmproj != "" || tt.wantMmproj != "" && mmproj != filepath.Join(dir, tt.wantMmproj) {
+				t.Errorf("mmproj = %q, want %q", mmproj, tt.wantMmproj)
+			}
+		})
+	}
+}
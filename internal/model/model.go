@@ -3,10 +3,16 @@ package model
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/d2verb/alpaca/internal/blob"
 	"github.com/d2verb/alpaca/internal/metadata"
 )
 
@@ -14,6 +20,7 @@ import (
 type Manager struct {
 	modelsDir string
 	metadata  *metadata.Manager
+	blobs     *blob.Store
 }
 
 // NewManager creates a new model manager.
@@ -21,6 +28,7 @@ func NewManager(modelsDir string) *Manager {
 	return &Manager{
 		modelsDir: modelsDir,
 		metadata:  metadata.NewManager(modelsDir),
+		blobs:     blob.New(modelsDir),
 	}
 }
 
@@ -32,49 +40,63 @@ func (m *Manager) List(ctx context.Context) ([]metadata.ModelEntry, error) {
 	return m.metadata.List(), nil
 }
 
-// Remove deletes a model file, its mmproj file (if unreferenced), and its metadata entry.
+// Remove deletes a model file, its mmproj and extra files (if unreferenced),
+// and its metadata entry. Stored content no other entry refers to is
+// deleted too.
 func (m *Manager) Remove(ctx context.Context, repo, quant string) error {
 	if err := m.metadata.Load(ctx); err != nil {
 		return fmt.Errorf("load metadata: %w", err)
 	}
 
-	// Get file path from metadata
-	filePath, err := m.metadata.GetFilePath(m.modelsDir, repo, quant)
-	if err != nil {
+	// Check the model and its files exist
+	if _, err := m.metadata.GetFilePath(m.modelsDir, repo, quant); err != nil {
 		return fmt.Errorf("get model file path: %w", err)
 	}
 
 	// Capture mmproj info before removing the metadata entry
 	entry := m.metadata.Find(repo, quant)
 	var mmprojFilename string
-	if entry != nil && entry.Mmproj != nil {
+	if entry.Mmproj != nil {
 		mmprojFilename = entry.Mmproj.Filename
 	}
+	shared := []string{mmprojFilename}
+	for _, x := range entry.ExtraFiles {
+		shared = append(shared, x.Filename)
+	}
 
-	// Remove model file
-	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("remove model file: %w", err)
+	// Remove model files (every shard of a split model)
+	var blobs []string
+	for _, f := range entry.Files() {
+		if b, ok := m.blobs.Target(f); ok {
+			blobs = append(blobs, b)
+		}
+		if err := os.Remove(filepath.Join(m.modelsDir, f)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove model file: %w", err)
+		}
 	}
 
-	// Remove metadata entry
-	if err := m.metadata.Remove(repo, quant); err != nil {
+	// Remove metadata entry (by its stored quant, so repo:auto works)
+	if err := m.metadata.Remove(repo, entry.Quant); err != nil {
 		return fmt.Errorf("remove metadata: %w", err)
 	}
 	if err := m.metadata.Save(ctx); err != nil {
 		return fmt.Errorf("save metadata: %w", err)
 	}
 
-	// Delete mmproj file if no other entries reference it
-	if mmprojFilename != "" {
-		if m.metadata.MmprojReferenceCount(mmprojFilename) == 0 {
-			mmprojPath := filepath.Join(m.modelsDir, mmprojFilename)
-			if err := os.Remove(mmprojPath); err != nil && !os.IsNotExist(err) {
-				return fmt.Errorf("remove mmproj file: %w", err)
-			}
+	// Delete the mmproj and extra files if no other entries reference them
+	for _, f := range shared {
+		if f == "" || m.metadata.MmprojReferenceCount(f) > 0 || m.metadata.ExtraFileReferenceCount(f) > 0 {
+			continue
+		}
+		if b, ok := m.blobs.Target(f); ok {
+			blobs = append(blobs, b)
+		}
+		if err := os.Remove(filepath.Join(m.modelsDir, f)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove %s: %w", f, err)
 		}
 	}
 
-	return nil
+	return m.removeUnreferencedBlobs(blobs)
 }
 
 // Exists checks if a model is downloaded.
@@ -117,3 +139,170 @@ func (m *Manager) GetDetails(ctx context.Context, repo, quant string) (*metadata
 
 	return entry, nil
 }
+
+// ListLoras returns all downloaded LoRA adapters from metadata.
+func (m *Manager) ListLoras(ctx context.Context) ([]metadata.LoraEntry, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	return m.metadata.ListLoras(), nil
+}
+
+// LoraExists checks if a LoRA adapter is downloaded.
+func (m *Manager) LoraExists(ctx context.Context, repo, file string) (bool, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return false, fmt.Errorf("load metadata: %w", err)
+	}
+	return m.metadata.FindLora(repo, file) != nil, nil
+}
+
+// GetLoraFilePath resolves repo:file to the adapter's file path.
+func (m *Manager) GetLoraFilePath(ctx context.Context, repo, file string) (string, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return "", fmt.Errorf("load metadata: %w", err)
+	}
+	return m.metadata.GetLoraFilePath(m.modelsDir, repo, file)
+}
+
+// RemoveLora deletes a LoRA adapter file and its metadata entry.
+func (m *Manager) RemoveLora(ctx context.Context, repo, file string) error {
+	if err := m.metadata.Load(ctx); err != nil {
+		return fmt.Errorf("load metadata: %w", err)
+	}
+
+	entry := m.metadata.FindLora(repo, file)
+	if entry == nil {
+		return &metadata.NotFoundError{Repo: repo, Quant: file}
+	}
+
+	blobPath, inStore := m.blobs.Target(entry.Filename)
+	filePath := filepath.Join(m.modelsDir, entry.Filename)
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove lora file: %w", err)
+	}
+
+	m.metadata.RemoveLora(repo, file)
+	if err := m.metadata.Save(ctx); err != nil {
+		return fmt.Errorf("save metadata: %w", err)
+	}
+	if inStore {
+		return m.removeUnreferencedBlobs([]string{blobPath})
+	}
+	return nil
+}
+
+// Import copies (or symlinks, when link is true) a local GGUF file into the
+// models directory and registers it as repo:quant.
+// If expectedSHA256 is non-empty the file content is verified before registering.
+func (m *Manager) Import(ctx context.Context, src, repo, quant string, link bool, expectedSHA256 string) (*metadata.ModelEntry, error) {
+	if !strings.HasSuffix(strings.ToLower(src), ".gguf") {
+		return nil, fmt.Errorf("not a .gguf file: %s", src)
+	}
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("resolve source path: %w", err)
+	}
+	info, err := os.Stat(absSrc)
+	if err != nil {
+		return nil, fmt.Errorf("stat source file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("not a file: %s", absSrc)
+	}
+
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+
+	if err := os.MkdirAll(m.modelsDir, 0755); err != nil {
+		return nil, fmt.Errorf("create models directory: %w", err)
+	}
+
+	filename := filepath.Base(absSrc)
+	destPath := filepath.Join(m.modelsDir, filename)
+	if _, err := os.Lstat(destPath); err == nil {
+		return nil, fmt.Errorf("file already exists in models directory: %s", filename)
+	}
+
+	var sum string
+	if link {
+		sum, err = hashFile(absSrc)
+		if err == nil {
+			err = os.Symlink(absSrc, destPath)
+		}
+	} else {
+		sum, err = copyFile(absSrc, destPath)
+	}
+	if err != nil {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("import %s: %w", filename, err)
+	}
+
+	if expectedSHA256 != "" && !strings.EqualFold(sum, expectedSHA256) {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("integrity verification failed for %s: hash mismatch: expected %s, got %s", filename, strings.ToLower(expectedSHA256), sum)
+	}
+	if !link {
+		if err := m.blobs.Adopt(filename, sum); err != nil {
+			os.Remove(destPath)
+			return nil, fmt.Errorf("import %s: %w", filename, err)
+		}
+	}
+
+	entry := metadata.ModelEntry{
+		Repo:         repo,
+		Quant:        quant,
+		Filename:     filename,
+		Size:         info.Size(),
+		Source:       absSrc,
+		DownloadedAt: time.Now().UTC(),
+	}
+	entry.ReadGGUFInfo(m.modelsDir)
+	if err := m.metadata.Add(entry); err != nil {
+		os.Remove(destPath)
+		return nil, fmt.Errorf("add metadata entry: %w", err)
+	}
+	if err := m.metadata.Save(ctx); err != nil {
+		return nil, fmt.Errorf("save metadata: %w", err)
+	}
+	return &entry, nil
+}
+
+// copyFile copies src to dst and returns the hex-encoded SHA256 of the content.
+func copyFile(src, dst string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), in); err != nil {
+		out.Close()
+		return "", err
+	}
+	if err := out.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashFile returns the hex-encoded SHA256 of a file's content.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -187,6 +188,40 @@ func TestRemoveSuccess(t *testing.T) {
 	}
 }
 
+func TestRemoveShardedModel(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	ctx := context.Background()
+	shards := []string{"model-00001-of-00002.gguf", "model-00002-of-00002.gguf"}
+	for _, f := range shards {
+		if err := os.WriteFile(filepath.Join(tmpDir, f), []byte("dummy"), 0644); err != nil {
+			t.Fatalf("create shard: %v", err)
+		}
+	}
+	metaMgr := metadata.NewManager(tmpDir)
+	entry := metadata.ModelEntry{Repo: "repo1", Quant: "Q4_K_M", Filename: shards[0], Shards: shards}
+	if err := metaMgr.Add(entry); err != nil {
+		t.Fatalf("add entry: %v", err)
+	}
+	if err := metaMgr.Save(ctx); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+
+	// Act
+	err := mgr.Remove(ctx, "repo1", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	for _, f := range shards {
+		if _, err := os.Stat(filepath.Join(tmpDir, f)); !os.IsNotExist(err) {
+			t.Errorf("shard %s should be deleted", f)
+		}
+	}
+}
+
 func TestRemoveNonExistent(t *testing.T) {
 	// Arrange
 	tmpDir := t.TempDir()
@@ -433,6 +468,42 @@ func TestRemoveModelWithMmprojSharedByOtherQuant(t *testing.T) {
 	}
 }
 
+func TestRemoveModelWithExtraFiles(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	mgr := NewManager(tmpDir)
+	ctx := context.Background()
+
+	for _, f := range []string{"model-q4.gguf", "model-q8.gguf", "repo1_config.json", "repo1_template.jinja"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, f), []byte(f), 0644); err != nil {
+			t.Fatalf("create %s: %v", f, err)
+		}
+	}
+	config := metadata.ExtraFileEntry{File: "config.json", Filename: "repo1_config.json"}
+	template := metadata.ExtraFileEntry{File: "template.jinja", Filename: "repo1_template.jinja"}
+
+	metaMgr := metadata.NewManager(tmpDir)
+	metaMgr.Add(metadata.ModelEntry{Repo: "repo1", Quant: "Q4_K_M", Filename: "model-q4.gguf", ExtraFiles: []metadata.ExtraFileEntry{config, template}})
+	metaMgr.Add(metadata.ModelEntry{Repo: "repo1", Quant: "Q8_0", Filename: "model-q8.gguf", ExtraFiles: []metadata.ExtraFileEntry{config}})
+	if err := metaMgr.Save(ctx); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+
+	// Act
+	err := mgr.Remove(ctx, "repo1", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "repo1_template.jinja")); !os.IsNotExist(err) {
+		t.Error("extra file no other entry lists should be deleted")
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "repo1_config.json")); err != nil {
+		t.Error("extra file should be kept when other entries still list it")
+	}
+}
+
 func TestRemoveNonExistentModel(t *testing.T) {
 	// Arrange
 	tmpDir := t.TempDir()
@@ -490,3 +561,170 @@ func TestGetDetailsWithMmproj(t *testing.T) {
 		t.Errorf("mmproj size = %d, want 851251104", details.Mmproj.Size)
 	}
 }
+
+func TestRemoveLora(t *testing.T) {
+	// Arrange
+	tmpDir := t.TempDir()
+	ctx := context.Background()
+	loraPath := filepath.Join(tmpDir, "org_lora_a.gguf")
+	if err := os.WriteFile(loraPath, []byte("adapter"), 0644); err != nil {
+		t.Fatalf("create lora file: %v", err)
+	}
+	metaMgr := metadata.NewManager(tmpDir)
+	metaMgr.AddLora(metadata.LoraEntry{Repo: "org/lora", File: "a.gguf", Filename: "org_lora_a.gguf"})
+	if err := metaMgr.Save(ctx); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+	mgr := NewManager(tmpDir)
+
+	// Act
+	err := mgr.RemoveLora(ctx, "org/lora", "a.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RemoveLora() error = %v", err)
+	}
+	if _, err := os.Stat(loraPath); !os.IsNotExist(err) {
+		t.Error("lora file should be deleted")
+	}
+	exists, err := mgr.LoraExists(ctx, "org/lora", "a.gguf")
+	if err != nil {
+		t.Fatalf("LoraExists() error = %v", err)
+	}
+	if exists {
+		t.Error("lora should no longer exist in metadata")
+	}
+}
+
+func TestRemoveLora_NotFound(t *testing.T) {
+	// Arrange
+	mgr := NewManager(t.TempDir())
+
+	// Act
+	err := mgr.RemoveLora(context.Background(), "org/lora", "missing.gguf")
+
+	// Assert
+	var notFound *metadata.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError, got %v", err)
+	}
+}
+
+func TestImport(t *testing.T) {
+	content := []byte("gguf-content")
+	tests := []struct {
+		name string
+		link bool
+	}{
+		{"copy", false},
+		{"symlink", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srcDir := t.TempDir()
+			modelsDir := t.TempDir()
+			src := filepath.Join(srcDir, "custom-Q4_K_M.gguf")
+			if err := os.WriteFile(src, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			mgr := NewManager(modelsDir)
+			ctx := context.Background()
+
+			// Act
+			entry, err := mgr.Import(ctx, src, "me/custom", "Q4_K_M", tt.link, "")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if entry.Source != src {
+				t.Errorf("Source = %q, want %q", entry.Source, src)
+			}
+			if entry.Size != int64(len(content)) {
+				t.Errorf("Size = %d, want %d", entry.Size, len(content))
+			}
+			dest := filepath.Join(modelsDir, "custom-Q4_K_M.gguf")
+			fi, err := os.Lstat(dest)
+			if err != nil {
+				t.Fatalf("imported file missing: %v", err)
+			}
+			if fi.Mode()&os.ModeSymlink == 0 {
+				t.Fatal("imported file is not a link")
+			}
+			// A copy is kept in the blob store; --link points at the source
+			target, _ := os.Readlink(dest)
+			if inStore := !filepath.IsAbs(target); inStore == tt.link {
+				t.Errorf("link target = %q, want in blob store = %v", target, !tt.link)
+			}
+			path, err := NewManager(modelsDir).GetFilePath(ctx, "me/custom", "Q4_K_M")
+			if err != nil {
+				t.Fatalf("GetFilePath() error = %v", err)
+			}
+			if path != dest {
+				t.Errorf("GetFilePath() = %q, want %q", path, dest)
+			}
+		})
+	}
+}
+
+func TestImport_HashMismatch(t *testing.T) {
+	// Arrange
+	srcDir := t.TempDir()
+	modelsDir := t.TempDir()
+	src := filepath.Join(srcDir, "model.gguf")
+	if err := os.WriteFile(src, []byte("gguf-content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewManager(modelsDir)
+	ctx := context.Background()
+
+	// Act
+	_, err := mgr.Import(ctx, src, "me/custom", "Q4_K_M", false, "0000")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected integrity error")
+	}
+	if _, statErr := os.Stat(filepath.Join(modelsDir, "model.gguf")); !os.IsNotExist(statErr) {
+		t.Error("copied file should be removed after hash mismatch")
+	}
+	if exists, _ := mgr.Exists(ctx, "me/custom", "Q4_K_M"); exists {
+		t.Error("metadata should not be registered after hash mismatch")
+	}
+}
+
+func TestImport_Rejects(t *testing.T) {
+	// Arrange
+	srcDir := t.TempDir()
+	modelsDir := t.TempDir()
+	bin := filepath.Join(srcDir, "model.bin")
+	os.WriteFile(bin, []byte("x"), 0644)
+	existing := filepath.Join(srcDir, "dup.gguf")
+	os.WriteFile(existing, []byte("x"), 0644)
+	os.WriteFile(filepath.Join(modelsDir, "dup.gguf"), []byte("y"), 0644)
+	mgr := NewManager(modelsDir)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"not gguf", bin},
+		{"missing", filepath.Join(srcDir, "missing.gguf")},
+		{"already exists", existing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := mgr.Import(ctx, tt.src, "me/custom", "Q4_K_M", false, "")
+
+			// Assert
+			if err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
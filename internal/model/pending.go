@@ -0,0 +1,190 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+)
+
+// Suffixes of the files a download in progress keeps next to the file it
+// writes: the content so far, the ETag it is resumed against, and the size
+// of the whole file for progress reporting.
+const (
+	partSuffix     = ".part"
+	etagSuffix     = ".etag"
+	progressSuffix = ".progress"
+)
+
+// tmpSuffix marks files written in full and then renamed into place, such
+// as the metadata file; one left behind was interrupted by a crash.
+const tmpSuffix = ".tmp"
+
+// Partial is a download that did not finish, such as one interrupted by
+// Ctrl-C or a dropped connection. Pulling the same model again resumes it.
+type Partial struct {
+	Filename string    // file being downloaded, relative to the models directory
+	Size     int64     // bytes downloaded so far
+	ModTime  time.Time // when the download last wrote to it
+	Model    string    // h:repo:quant whose file it replaces; empty for a new download
+}
+
+// Pending returns the partial downloads in the models directory, by
+// filename.
+func (m *Manager) Pending(ctx context.Context) ([]Partial, error) {
+	if err := m.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	owners := make(map[string]string)
+	for _, e := range m.metadata.List() {
+		spec := fmt.Sprintf("h:%s:%s", e.Repo, e.Quant)
+		for _, f := range e.Files() {
+			owners[f] = spec
+		}
+		if e.Mmproj != nil {
+			owners[e.Mmproj.Filename] = spec
+		}
+	}
+
+	partials := make(map[string]*Partial)
+	err := filepath.WalkDir(m.modelsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(m.modelsDir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel == blob.Dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, isPart := strings.CutSuffix(rel, partSuffix)
+		if !isPart {
+			var isSidecar bool
+			if name, isSidecar = strings.CutSuffix(rel, etagSuffix); !isSidecar {
+				if name, isSidecar = strings.CutSuffix(rel, progressSuffix); !isSidecar {
+					return nil
+				}
+			}
+		}
+		p := partials[name]
+		if p == nil {
+			p = &Partial{Filename: name, Model: owners[name]}
+			partials[name] = p
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed while walking
+		}
+		if isPart {
+			p.Size = info.Size()
+		}
+		if info.ModTime().After(p.ModTime) {
+			p.ModTime = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("list partial downloads: %w", err)
+	}
+
+	result := make([]Partial, 0, len(partials))
+	for _, p := range partials {
+		result = append(result, *p)
+	}
+	slices.SortFunc(result, func(a, b Partial) int { return strings.Compare(a.Filename, b.Filename) })
+	return result, nil
+}
+
+// RemovePartial deletes the .part, .etag and .progress files of the
+// partial download of filename, as Pending reports it. A pull still
+// writing it fails.
+func (m *Manager) RemovePartial(filename string) error {
+	root, err := os.OpenRoot(m.modelsDir)
+	if err != nil {
+		return fmt.Errorf("open models dir: %w", err)
+	}
+	defer root.Close()
+
+	found := false
+	for _, suffix := range []string{partSuffix, etagSuffix, progressSuffix} {
+		err := root.Remove(filename + suffix)
+		switch {
+		case err == nil:
+			found = true
+		case !os.IsNotExist(err):
+			return fmt.Errorf("remove %s: %w", filename+suffix, err)
+		}
+	}
+	if !found {
+		return fmt.Errorf("no partial download of '%s'", filename)
+	}
+	return nil
+}
+
+// PruneResult reports what PruneStale deleted.
+type PruneResult struct {
+	Partials []Partial // partial downloads
+	Temp     []string  // leftover temporary files, relative to the models directory
+	Freed    int64     // bytes
+}
+
+// PruneStale deletes the partial downloads and leftover temporary files in
+// the models directory that have not been written to since before cutoff.
+// Downloads written to since then are kept, so they can still be resumed.
+func (m *Manager) PruneStale(ctx context.Context, cutoff time.Time) (*PruneResult, error) {
+	partials, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &PruneResult{}
+	for _, p := range partials {
+		if !p.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := m.RemovePartial(p.Filename); err != nil {
+			return result, err
+		}
+		result.Partials = append(result.Partials, p)
+		result.Freed += p.Size
+	}
+
+	err = filepath.WalkDir(m.modelsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == filepath.Join(m.modelsDir, blob.Dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, tmpSuffix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || !info.Mode().IsRegular() || !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(m.modelsDir, path)
+		result.Temp = append(result.Temp, rel)
+		result.Freed += info.Size()
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return result, fmt.Errorf("prune temporary files: %w", err)
+	}
+	return result, nil
+}
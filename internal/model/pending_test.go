@@ -0,0 +1,154 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/blob"
+)
+
+func TestPending(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	addEntry(t, dir, "org/model", "model.gguf")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("model.gguf", "weights")
+	write("model.gguf.part", "new weights")
+	write("model.gguf.etag", `"abc"`)
+	write("other.gguf.part", "partial")
+	write("stale.gguf.etag", `"def"`)
+	if err := os.MkdirAll(filepath.Join(dir, blob.Dir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(blob.Dir, "ignored.part"), "blob")
+
+	// Act
+	partials, err := NewManager(dir).Pending(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+	var got []string
+	for _, p := range partials {
+		got = append(got, p.Filename+"|"+p.Model)
+		if p.ModTime.IsZero() {
+			t.Errorf("%s has no modification time", p.Filename)
+		}
+	}
+	want := "model.gguf|h:org/model:Q4_K_M,other.gguf|,stale.gguf|"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Pending() = %v, want %s", got, want)
+	}
+	if partials[0].Size != int64(len("new weights")) || partials[2].Size != 0 {
+		t.Errorf("sizes = %d, %d, want the .part sizes", partials[0].Size, partials[2].Size)
+	}
+}
+
+func TestPending_NoModelsDir(t *testing.T) {
+	// Arrange
+	mgr := NewManager(filepath.Join(t.TempDir(), "missing"))
+
+	// Act
+	partials, err := mgr.Pending(context.Background())
+
+	// Assert
+	if err != nil || len(partials) != 0 {
+		t.Errorf("Pending() = %v, %v, want none", partials, err)
+	}
+}
+
+func TestRemovePartial(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0644)
+	os.WriteFile(filepath.Join(dir, "model.gguf.part"), []byte("new"), 0644)
+	os.WriteFile(filepath.Join(dir, "model.gguf.etag"), []byte(`"abc"`), 0644)
+	os.WriteFile(filepath.Join(dir, "model.gguf.progress"), []byte(`{"total":10}`), 0644)
+	mgr := NewManager(dir)
+
+	// Act
+	err := mgr.RemovePartial("model.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RemovePartial() error = %v", err)
+	}
+	for _, name := range []string{"model.gguf.part", "model.gguf.etag", "model.gguf.progress"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model.gguf")); err != nil {
+		t.Errorf("model.gguf removed: %v", err)
+	}
+	if err := mgr.RemovePartial("model.gguf"); err == nil {
+		t.Error("RemovePartial() of a removed download should fail")
+	}
+}
+
+func TestPruneStale(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	write := func(name, content string, modTime time.Time) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("old.gguf.part", "partial", old)
+	write("old.gguf.etag", `"abc"`, old)
+	write("fresh.gguf.part", "resumable", time.Now())
+	write(".metadata-123.tmp", "{}", old)
+	write("new.tmp", "{}", time.Now())
+	write(filepath.Join(blob.Dir, "sha256-x.tmp"), "blob", old)
+	write("model.gguf", "weights", old)
+
+	// Act
+	result, err := NewManager(dir).PruneStale(context.Background(), time.Now().Add(-30*24*time.Hour))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PruneStale() error = %v", err)
+	}
+	if len(result.Partials) != 1 || result.Partials[0].Filename != "old.gguf" {
+		t.Errorf("Partials = %+v, want old.gguf", result.Partials)
+	}
+	if strings.Join(result.Temp, ",") != ".metadata-123.tmp" {
+		t.Errorf("Temp = %v, want [.metadata-123.tmp]", result.Temp)
+	}
+	if want := int64(len("partial") + len("{}")); result.Freed != want {
+		t.Errorf("Freed = %d, want %d", result.Freed, want)
+	}
+	for name, wantExists := range map[string]bool{
+		"old.gguf.part":                         false,
+		"old.gguf.etag":                         false,
+		".metadata-123.tmp":                     false,
+		"fresh.gguf.part":                       true,
+		"new.tmp":                               true,
+		filepath.Join(blob.Dir, "sha256-x.tmp"): true,
+		"model.gguf":                            true,
+	} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if exists := err == nil; exists != wantExists {
+			t.Errorf("%s exists = %v, want %v", name, exists, wantExists)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/blob"
+	"github.com/d2verb/alpaca/internal/gguf"
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// BackupFileName is where RebuildMetadata keeps the metadata file it
+// replaces, relative to the models directory.
+const BackupFileName = metadata.FileName + ".bak"
+
+// LocalOrg is the organization models and adapters whose repository cannot
+// be told from their files are recorded under.
+const LocalOrg = "local"
+
+// quantPattern matches the quant suffix of a GGUF filename, as pull names
+// quants, e.g. "Qwen3-8B-Q4_K_M.gguf" or "codellama-7b.Q5_K_S.gguf".
+var quantPattern = regexp.MustCompile(`(?i)[-._]((?:I?Q\d(?:_[A-Z0-9]+)*)|BF16|F16|F32)\.gguf$`)
+
+// shardPattern matches the name gguf-split gives each part of a split
+// model, e.g. "Qwen3-235B-Q4_K_M-00001-of-00003.gguf".
+var shardPattern = regexp.MustCompile(`^(.*)-(\d{5})-of-(\d{5})\.gguf$`)
+
+// repoKeys are the GGUF metadata keys that may name the HuggingFace
+// repository a file was published in, as "org/repo" or a URL.
+var repoKeys = []string{
+	"general.source.huggingface.repository",
+	"general.url",
+	"general.repo_url",
+	"general.source.url",
+	"general.source.repo_url",
+}
+
+// RebuildResult reports what RebuildMetadata recorded (or, in a dry run,
+// would record).
+type RebuildResult struct {
+	Corrupt bool                  // the metadata file could not be parsed and was replaced
+	Kept    int                   // recorded entries whose files are all present
+	Dropped []string              // recorded entries whose files are missing
+	Models  []metadata.ModelEntry // models recovered from unrecorded files
+	Loras   []metadata.LoraEntry  // LoRA adapters recovered from unrecorded files
+	Skipped []string              // unrecorded GGUF files left out, with the reason
+}
+
+// RebuildMetadata reconciles metadata with the files in the models
+// directory, for when metadata was deleted or corrupted. Entries whose files
+// are gone are dropped; GGUF files no entry refers to are recorded, with the
+// repository taken from the repo-prefixed names of mmproj and extra files
+// next to them or from the file's own GGUF metadata. Files whose repository
+// cannot be told are recorded under LocalOrg. Unless dryRun, the previous
+// metadata file is kept as BackupFileName; files are not hashed in a dry
+// run.
+func (m *Manager) RebuildMetadata(ctx context.Context, dryRun bool) (*RebuildResult, error) {
+	result := &RebuildResult{}
+	if err := m.metadata.Load(ctx); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("load metadata: %w", err)
+		}
+		result.Corrupt = true
+		m.metadata = metadata.NewManager(m.modelsDir)
+	}
+
+	m.dropMissing(result)
+
+	ggufs, others, err := m.unrecordedFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	// Repositories alpaca stored files of, as candidates for the rest
+	var repos []string
+	for _, e := range m.metadata.List() {
+		repos = append(repos, e.Repo)
+	}
+	for _, l := range m.metadata.ListLoras() {
+		repos = append(repos, l.Repo)
+	}
+	var mmprojs []string
+	var models [][]string // files of each model, the first shard first
+	shards := make(map[string][]string)
+	for _, f := range ggufs {
+		base := filepath.Base(f)
+		if strings.Contains(strings.ToLower(base), "mmproj") {
+			mmprojs = append(mmprojs, f)
+			repos = append(repos, prefixedRepos(base)...)
+			continue
+		}
+		if sm := shardPattern.FindStringSubmatch(base); sm != nil && sm[3] != "00001" {
+			key := filepath.Join(filepath.Dir(f), sm[1])
+			shards[key] = append(shards[key], f)
+			continue
+		}
+		models = append(models, []string{f})
+	}
+	for _, f := range others {
+		repos = append(repos, prefixedRepos(filepath.Base(f))...)
+	}
+	for key, files := range shards {
+		slices.Sort(files)
+		count, _ := strconv.Atoi(shardPattern.FindStringSubmatch(filepath.Base(files[0]))[3])
+		if len(files) != count {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %d of %d shards present", filepath.Base(key), len(files), count))
+			continue
+		}
+		models = append(models, files)
+	}
+	slices.SortFunc(models, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+
+	for _, files := range models {
+		md, err := readGGUFMetadata(filepath.Join(m.modelsDir, files[0]))
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", files[0], err))
+			continue
+		}
+		if t, _ := md.String("general.type"); t == "adapter" {
+			entry, err := m.recoverLora(files[0], md, repos, dryRun)
+			if err != nil {
+				return nil, err
+			}
+			m.metadata.AddLora(entry)
+			result.Loras = append(result.Loras, entry)
+			continue
+		}
+		entry, err := m.recoverModel(files, md, repos, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if err := m.metadata.Add(entry); err != nil {
+			return nil, err
+		}
+		result.Models = append(result.Models, entry)
+	}
+
+	for _, f := range mmprojs {
+		if !m.attachMmproj(f, result) {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: mmproj of no recorded model", f))
+		}
+	}
+	for _, f := range others {
+		m.attachExtraFile(f, result)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := m.backupMetadata(); err != nil {
+		return nil, err
+	}
+	if err := m.metadata.Save(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// dropMissing removes the entries whose model or adapter files are gone, and
+// the mmproj and extra files that are gone from the entries that remain.
+func (m *Manager) dropMissing(result *RebuildResult) {
+	exists := func(f string) bool {
+		_, err := os.Stat(filepath.Join(m.modelsDir, f))
+		return err == nil
+	}
+
+	for _, e := range m.metadata.List() {
+		if !allFunc(e.Files(), exists) {
+			m.metadata.Remove(e.Repo, e.Quant)
+			result.Dropped = append(result.Dropped, fmt.Sprintf("h:%s:%s", e.Repo, e.Quant))
+			continue
+		}
+		result.Kept++
+		changed := false
+		if e.Mmproj != nil && !exists(e.Mmproj.Filename) {
+			// Left for pull to download again
+			e.Mmproj, e.MmprojFailed = nil, true
+			changed = true
+		}
+		extras := slices.DeleteFunc(slices.Clone(e.ExtraFiles), func(x metadata.ExtraFileEntry) bool { return !exists(x.Filename) })
+		if len(extras) != len(e.ExtraFiles) {
+			e.ExtraFiles = extras
+			changed = true
+		}
+		if e.Architecture == "" {
+			// Recorded before model info was
+			e.ReadGGUFInfo(m.modelsDir)
+			changed = changed || e.Architecture != ""
+		}
+		if changed {
+			m.metadata.Add(e)
+		}
+	}
+	for _, l := range m.metadata.ListLoras() {
+		if !exists(l.Filename) {
+			m.metadata.RemoveLora(l.Repo, l.File)
+			result.Dropped = append(result.Dropped, fmt.Sprintf("h:%s:%s", l.Repo, l.File))
+			continue
+		}
+		result.Kept++
+	}
+}
+
+// unrecordedFiles returns the files in the models directory that metadata
+// does not refer to: GGUF files, and other files that may be extra files.
+// The blob store, hidden files and partial downloads are left out, as are
+// links whose blob is gone.
+func (m *Manager) unrecordedFiles() (ggufs, others []string, err error) {
+	recorded := make(map[string]bool)
+	for _, f := range m.metadata.AllFiles() {
+		recorded[f] = true
+	}
+
+	err = filepath.WalkDir(m.modelsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(m.modelsDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || rel == blob.Dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || recorded[rel] ||
+			strings.HasSuffix(rel, partSuffix) || strings.HasSuffix(rel, etagSuffix) {
+			return nil
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		if strings.HasSuffix(strings.ToLower(rel), ".gguf") {
+			ggufs = append(ggufs, rel)
+		} else {
+			others = append(others, rel)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("scan models dir: %w", err)
+	}
+	return ggufs, others, nil
+}
+
+// recoverModel builds the entry of a model stored as files, the first
+// shard first.
+func (m *Manager) recoverModel(files []string, md gguf.Metadata, repos []string, dryRun bool) (metadata.ModelEntry, error) {
+	name := filepath.Base(files[0])
+	if len(files) > 1 {
+		name = shardPattern.FindStringSubmatch(name)[1] + ".gguf"
+	}
+	stem := strings.TrimSuffix(name, filepath.Ext(name))
+	quant := stem
+	if q := quantPattern.FindStringSubmatch(name); q != nil {
+		quant = strings.ToUpper(q[1])
+		stem = strings.TrimRight(stem[:len(stem)-len(q[1])], "-._")
+	}
+
+	entry := metadata.ModelEntry{
+		Repo:     matchRepo(name, md, repos),
+		Quant:    quant,
+		Filename: files[0],
+	}
+	if len(files) > 1 {
+		entry.Shards = files
+	}
+	if entry.Repo == "" {
+		entry.Repo = LocalOrg + "/" + stem
+		entry.Source = filepath.Join(m.modelsDir, files[0])
+	}
+	for i, f := range files {
+		info, sum, err := m.recoverFile(f, dryRun)
+		if err != nil {
+			return metadata.ModelEntry{}, err
+		}
+		entry.Size += info.Size()
+		if i == 0 {
+			entry.SHA256 = sum
+			entry.DownloadedAt = info.ModTime().UTC()
+		}
+	}
+	entry.ReadGGUFInfo(m.modelsDir)
+	return entry, nil
+}
+
+// recoverLora builds the entry of the LoRA adapter stored as filename. Its
+// repository is told from its repo-prefixed name.
+func (m *Manager) recoverLora(filename string, md gguf.Metadata, repos []string, dryRun bool) (metadata.LoraEntry, error) {
+	base := filepath.Base(filename)
+	entry := metadata.LoraEntry{Repo: LocalOrg + "/" + strings.TrimSuffix(base, filepath.Ext(base)), File: base, Filename: filename}
+	if repo := embeddedRepo(md); repo != "" && strings.HasPrefix(base, repoPrefix(repo)) {
+		entry.Repo, entry.File = repo, strings.TrimPrefix(base, repoPrefix(repo))
+	} else if r := longestPrefixRepo(base, repos); r != "" {
+		entry.Repo, entry.File = r, strings.TrimPrefix(base, repoPrefix(r))
+	} else if guessed := prefixedRepos(base); len(guessed) > 0 {
+		// The repository name ends at its first underscore
+		entry.Repo, entry.File = guessed[0], strings.TrimPrefix(base, repoPrefix(guessed[0]))
+	}
+
+	info, _, err := m.recoverFile(filename, dryRun)
+	if err != nil {
+		return metadata.LoraEntry{}, err
+	}
+	entry.Size = info.Size()
+	entry.DownloadedAt = info.ModTime().UTC()
+	return entry, nil
+}
+
+// recoverFile stats filename and, unless dryRun, records its SHA256, which
+// is read from its blob's name if it links into the store.
+func (m *Manager) recoverFile(filename string, dryRun bool) (os.FileInfo, string, error) {
+	path := filepath.Join(m.modelsDir, filename)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if dryRun {
+		return info, "", nil
+	}
+	var sum string
+	if b, ok := m.blobs.Target(filename); ok {
+		sum = blob.Sum(b)
+	} else if sum, err = hashFile(path); err != nil {
+		return nil, "", fmt.Errorf("hash %s: %w", filename, err)
+	}
+	m.metadata.SetChecksum(filename, metadata.FileChecksum{Size: info.Size(), ModTime: info.ModTime(), SHA256: sum})
+	return info, sum, nil
+}
+
+// attachMmproj records mmproj file filename for every model of the
+// repository its name is prefixed with that has none. It reports whether
+// any model took it.
+func (m *Manager) attachMmproj(filename string, result *RebuildResult) bool {
+	info, err := os.Stat(filepath.Join(m.modelsDir, filename))
+	if err != nil {
+		return false
+	}
+	attached := false
+	for _, e := range m.metadata.List() {
+		if e.Mmproj != nil || !strings.HasPrefix(filepath.Base(filename), repoPrefix(e.Repo)) {
+			continue
+		}
+		e.Mmproj = &metadata.MmprojEntry{
+			Filename: filename,
+			Size:     info.Size(),
+			File:     strings.TrimPrefix(filepath.Base(filename), repoPrefix(e.Repo)),
+		}
+		e.MmprojFailed = false
+		m.metadata.Add(e)
+		updateRecovered(result, e)
+		attached = true
+	}
+	return attached
+}
+
+// attachExtraFile records filename as an extra file of every model of the
+// repository its name is prefixed with. Files no repository claims are
+// ignored, since anything may be kept in the models directory.
+func (m *Manager) attachExtraFile(filename string, result *RebuildResult) {
+	info, err := os.Stat(filepath.Join(m.modelsDir, filename))
+	if err != nil {
+		return
+	}
+	base := filepath.Base(filename)
+	for _, e := range m.metadata.List() {
+		prefix := repoPrefix(e.Repo)
+		if !strings.HasPrefix(base, prefix) {
+			continue
+		}
+		e.ExtraFiles = append(e.ExtraFiles, metadata.ExtraFileEntry{
+			File:     strings.TrimPrefix(base, prefix),
+			Filename: filename,
+			Size:     info.Size(),
+		})
+		m.metadata.Add(e)
+		updateRecovered(result, e)
+	}
+}
+
+// updateRecovered replaces the recovered entry for e's model, if any, with e.
+func updateRecovered(result *RebuildResult, e metadata.ModelEntry) {
+	for i, r := range result.Models {
+		if r.Repo == e.Repo && r.Quant == e.Quant {
+			result.Models[i] = e
+		}
+	}
+}
+
+// backupMetadata copies the metadata file, if any, to BackupFileName.
+func (m *Manager) backupMetadata() error {
+	data, err := os.ReadFile(filepath.Join(m.modelsDir, metadata.FileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(m.modelsDir, BackupFileName), data, 0644); err != nil {
+		return fmt.Errorf("back up metadata: %w", err)
+	}
+	return nil
+}
+
+// matchRepo returns the repository model file name was most likely pulled
+// from: one of repos whose name, without a GGUF suffix, starts name, or one
+// the file's GGUF metadata names. It returns "" if there is neither.
+func matchRepo(name string, md gguf.Metadata, repos []string) string {
+	var best, bestBase string
+	for _, r := range repos {
+		_, repoName, _ := strings.Cut(r, "/")
+		base := strings.TrimRight(trimSuffixFold(repoName, "GGUF"), "-_.")
+		if base != "" && len(base) > len(bestBase) && strings.HasPrefix(strings.ToLower(name), strings.ToLower(base)) {
+			best, bestBase = r, base
+		}
+	}
+	if best != "" {
+		return best
+	}
+	return embeddedRepo(md)
+}
+
+// embeddedRepo returns the GGUF repository md names, if any. Repositories of
+// the unquantized model that are often named there are ignored, since
+// their quants cannot be pulled.
+func embeddedRepo(md gguf.Metadata) string {
+	for _, key := range repoKeys {
+		v, _ := md.String(key)
+		if u, err := url.Parse(v); err == nil && u.Host != "" {
+			if !strings.HasSuffix(u.Host, "huggingface.co") {
+				continue
+			}
+			v = strings.Trim(u.Path, "/")
+		}
+		parts := strings.Split(v, "/")
+		if len(parts) >= 2 && parts[0] != "" && strings.HasSuffix(strings.ToUpper(parts[1]), "GGUF") {
+			return parts[0] + "/" + parts[1]
+		}
+	}
+	return ""
+}
+
+// longestPrefixRepo returns the repository of repos whose storage prefix
+// name has, preferring the longest, or "".
+func longestPrefixRepo(name string, repos []string) string {
+	var best string
+	for _, r := range repos {
+		if strings.HasPrefix(name, repoPrefix(r)) && len(r) > len(best) {
+			best = r
+		}
+	}
+	return best
+}
+
+// prefixedRepos returns the repositories a repo-prefixed storage name such
+// as "org_repo_file.gguf" may have been stored from: since both names may
+// contain underscores, every split is a candidate, the shortest repository
+// name first.
+func prefixedRepos(name string) []string {
+	org, rest, ok := strings.Cut(name, "_")
+	if !ok || org == "" {
+		return nil
+	}
+	var repos []string
+	for i, c := range rest {
+		if c == '_' && i > 0 {
+			repos = append(repos, org+"/"+rest[:i])
+		}
+	}
+	return repos
+}
+
+// repoPrefix returns the prefix of the storage names of repo's mmproj,
+// extra and adapter files.
+func repoPrefix(repo string) string {
+	return strings.ReplaceAll(repo, "/", "_") + "_"
+}
+
+// trimSuffixFold trims suffix from s, ignoring case.
+func trimSuffixFold(s, suffix string) string {
+	if len(s) >= len(suffix) && strings.EqualFold(s[len(s)-len(suffix):], suffix) {
+		return s[:len(s)-len(suffix)]
+	}
+	return s
+}
+
+// allFunc reports whether f holds for every element of s.
+func allFunc[T any](s []T, f func(T) bool) bool {
+	return !slices.ContainsFunc(s, func(v T) bool { return !f(v) })
+}
+
+// readGGUFMetadata reads the metadata of the GGUF file at path.
+func readGGUFMetadata(path string) (gguf.Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, md, err := gguf.ReadMetadata(f)
+	return md, err
+}
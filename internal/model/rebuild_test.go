@@ -0,0 +1,169 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// ggufFile builds a version 3 GGUF file with string metadata and no tensors.
+func ggufFile(kvs ...[2]string) []byte {
+	var buf bytes.Buffer
+	writeString := func(s string) {
+		binary.Write(&buf, binary.LittleEndian, uint64(len(s)))
+		buf.WriteString(s)
+	}
+	buf.WriteString("GGUF")
+	binary.Write(&buf, binary.LittleEndian, uint32(3))
+	binary.Write(&buf, binary.LittleEndian, uint64(0))
+	binary.Write(&buf, binary.LittleEndian, uint64(len(kvs)))
+	for _, kv := range kvs {
+		writeString(kv[0])
+		binary.Write(&buf, binary.LittleEndian, uint32(8)) // string
+		writeString(kv[1])
+	}
+	return buf.Bytes()
+}
+
+// writeFiles writes each name in files with its content into dir.
+func writeFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// specs returns the h:repo:quant of each entry.
+func specs(entries []metadata.ModelEntry) []string {
+	var s []string
+	for _, e := range entries {
+		s = append(s, "h:"+e.Repo+":"+e.Quant)
+	}
+	return s
+}
+
+func TestRebuildMetadata(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	meta := metadata.NewManager(dir)
+	meta.Add(metadata.ModelEntry{Repo: "org/kept-GGUF", Quant: "Q4_K_M", Filename: "kept-Q4_K_M.gguf"})
+	meta.Add(metadata.ModelEntry{Repo: "org/gone-GGUF", Quant: "Q8_0", Filename: "gone-Q8_0.gguf"})
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	model := ggufFile([2]string{"general.architecture", "gemma3"})
+	writeFiles(t, dir, map[string][]byte{
+		"kept-Q4_K_M.gguf":          ggufFile(),
+		"kept-Q8_0.gguf":            ggufFile(),
+		"gemma-3-4b-it-Q4_K_M.gguf": model,
+		"ggml-org_gemma-3-4b-it-GGUF_mmproj-model-f16.gguf": ggufFile(),
+		"ggml-org_gemma-3-4b-it-GGUF_tokenizer_config.json": []byte("{}"),
+		"Big-Q4_K_M-00001-of-00002.gguf":                    ggufFile([2]string{"general.url", "https://huggingface.co/org/Big-GGUF"}),
+		"Big-Q4_K_M-00002-of-00002.gguf":                    []byte("second shard"),
+		"mystery.Q5_K_S.gguf":                               ggufFile(),
+		"org_lora-GGUF_adapter-f16.gguf":                    ggufFile([2]string{"general.type", "adapter"}),
+		"broken.gguf":                                       []byte("not a model"),
+		"Half-Q4_0-00001-of-00002.gguf":                     ggufFile(),
+		"notes.txt":                                         []byte("mine"),
+	})
+
+	// Act
+	result, err := NewManager(dir).RebuildMetadata(context.Background(), false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RebuildMetadata() error = %v", err)
+	}
+	if result.Corrupt || result.Kept != 1 || !slices.Equal(result.Dropped, []string{"h:org/gone-GGUF:Q8_0"}) {
+		t.Errorf("corrupt = %v, kept = %d, dropped = %v", result.Corrupt, result.Kept, result.Dropped)
+	}
+	wantModels := []string{"h:org/Big-GGUF:Q4_K_M", "h:ggml-org/gemma-3-4b-it-GGUF:Q4_K_M", "h:org/kept-GGUF:Q8_0", "h:local/mystery:Q5_K_S"}
+	if got := specs(result.Models); !slices.Equal(got, wantModels) {
+		t.Errorf("models = %v, want %v", got, wantModels)
+	}
+	if len(result.Loras) != 1 || result.Loras[0].Repo != "org/lora-GGUF" || result.Loras[0].File != "adapter-f16.gguf" {
+		t.Errorf("loras = %+v, want org/lora-GGUF adapter-f16.gguf", result.Loras)
+	}
+	if len(result.Skipped) != 2 {
+		t.Errorf("skipped = %v, want broken.gguf and the incomplete split model", result.Skipped)
+	}
+
+	saved := metadata.NewManager(dir)
+	if err := saved.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	gemma := saved.Find("ggml-org/gemma-3-4b-it-GGUF", "Q4_K_M")
+	if gemma == nil || gemma.Mmproj == nil || gemma.Mmproj.Filename != "ggml-org_gemma-3-4b-it-GGUF_mmproj-model-f16.gguf" {
+		t.Fatalf("gemma = %+v, want its mmproj attached", gemma)
+	}
+	if len(gemma.ExtraFiles) != 1 || gemma.ExtraFiles[0].File != "tokenizer_config.json" {
+		t.Errorf("extra files = %+v, want tokenizer_config.json", gemma.ExtraFiles)
+	}
+	if gemma.SHA256 != hashOf(t, dir, "gemma-3-4b-it-Q4_K_M.gguf") || gemma.Size != int64(len(model)) {
+		t.Errorf("sha256 = %s, size = %d, want the file's", gemma.SHA256, gemma.Size)
+	}
+	big := saved.Find("org/Big-GGUF", "Q4_K_M")
+	if big == nil || len(big.Shards) != 2 || big.Filename != "Big-Q4_K_M-00001-of-00002.gguf" {
+		t.Errorf("big = %+v, want both shards", big)
+	}
+	if mystery := saved.Find("local/mystery", "Q5_K_S"); mystery == nil || mystery.Source == "" {
+		t.Errorf("mystery = %+v, want a source so it is not checked upstream", mystery)
+	}
+	if saved.Find("org/gone-GGUF", "Q8_0") != nil {
+		t.Error("entry with a missing file was kept")
+	}
+	if _, err := os.Stat(filepath.Join(dir, BackupFileName)); err != nil {
+		t.Errorf("previous metadata not backed up: %v", err)
+	}
+}
+
+func TestRebuildMetadata_Corrupt(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{
+		metadata.FileName:   []byte(`{"models": [`),
+		"qwen3-Q4_K_M.gguf": ggufFile(),
+	})
+
+	// Act
+	result, err := NewManager(dir).RebuildMetadata(context.Background(), false)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RebuildMetadata() error = %v", err)
+	}
+	if !result.Corrupt || !slices.Equal(specs(result.Models), []string{"h:local/qwen3:Q4_K_M"}) {
+		t.Errorf("corrupt = %v, models = %v", result.Corrupt, specs(result.Models))
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, BackupFileName)); string(got) != `{"models": [` {
+		t.Errorf("backup = %q, want the corrupted file", got)
+	}
+}
+
+func TestRebuildMetadata_DryRun(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string][]byte{"qwen3-Q4_K_M.gguf": ggufFile()})
+
+	// Act
+	result, err := NewManager(dir).RebuildMetadata(context.Background(), true)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RebuildMetadata() error = %v", err)
+	}
+	if len(result.Models) != 1 || result.Models[0].SHA256 != "" {
+		t.Errorf("models = %+v, want one, not hashed", result.Models)
+	}
+	if _, err := os.Stat(filepath.Join(dir, metadata.FileName)); !os.IsNotExist(err) {
+		t.Errorf("dry run wrote metadata: %v", err)
+	}
+}
@@ -47,3 +47,16 @@ func ResolvePath(path, baseDir string) (string, error) {
 	// All relative paths are resolved from baseDir
 	return filepath.Join(baseDir, path), nil
 }
+
+// ResolveCommand resolves an executable reference.
+// - Bare names (no path separator) are returned as-is for PATH lookup
+// - Anything else is resolved like ResolvePath
+func ResolveCommand(cmd, baseDir string) (string, error) {
+	if !strings.Contains(cmd, "/") {
+		if cmd == "" {
+			return "", fmt.Errorf("command cannot be empty")
+		}
+		return cmd, nil
+	}
+	return ResolvePath(cmd, baseDir)
+}
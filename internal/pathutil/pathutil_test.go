@@ -96,3 +96,35 @@ func TestResolvePath(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveCommand(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("failed to get home dir: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		cmd     string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare name is looked up in PATH", cmd: "llama-server-cuda", want: "llama-server-cuda"},
+		{name: "relative path is resolved from baseDir", cmd: "./bin/llama-server", want: "/base/dir/bin/llama-server"},
+		{name: "absolute path is returned as-is", cmd: "/opt/llama/llama-server", want: "/opt/llama/llama-server"},
+		{name: "tilde is expanded", cmd: "~/llama.cpp/build/bin/llama-server", want: filepath.Join(home, "llama.cpp/build/bin/llama-server")},
+		{name: "empty is rejected", cmd: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveCommand(tt.cmd, "/base/dir")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveCommand(%q) error = %v, wantErr %v", tt.cmd, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCommand(%q) = %q, want %q", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package preset
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+)
+
+// DraftModelNone, as a router model's draft-model, opts the model out of
+// the default draft model.
+const DraftModelNone = "none"
+
+// Defaults holds what the models of a router preset share unless they set
+// their own. Draft models and mmproj are written to each model's section
+// of config.ini; options go to the [*] section.
+type Defaults struct {
+	// DraftModel is the draft model of models without one.
+	DraftModel string `yaml:"draft-model,omitempty"`
+
+	// DraftModels maps a model repository (org/repo) to the draft model of
+	// that repository's models, taking precedence over DraftModel.
+	DraftModels map[string]string `yaml:"draft-models,omitempty"`
+
+	// Mmproj is the mmproj of models without one: "none" turns the
+	// automatic mmproj off, or an f: path.
+	Mmproj string `yaml:"mmproj,omitempty"`
+
+	// Options are llama-server options for every model, alongside the
+	// top-level options.
+	Options Options `yaml:"options,omitempty"`
+}
+
+// IsZero reports whether no default is set.
+func (d Defaults) IsZero() bool {
+	return d.DraftModel == "" && len(d.DraftModels) == 0 && d.Mmproj == "" && len(d.Options) == 0
+}
+
+// draftModelFor returns the default draft model of a model, or "".
+func (d Defaults) draftModelFor(model string) string {
+	if id, err := identifier.Parse(model); err == nil && id.Type == identifier.TypeHuggingFace {
+		if draft, ok := d.DraftModels[id.Repo]; ok {
+			return draft
+		}
+	}
+	return d.DraftModel
+}
+
+func (d Defaults) validate(topOptions Options) error {
+	if strings.ContainsAny(d.DraftModel, "\n\r") {
+		return fmt.Errorf("defaults: draft-model field must not contain newline characters")
+	}
+	for _, repo := range slices.Sorted(maps.Keys(d.DraftModels)) {
+		draft := d.DraftModels[repo]
+		if repo == "" || draft == "" || strings.ContainsAny(repo+draft, "\n\r") {
+			return fmt.Errorf("defaults: draft-models maps a repository (org/repo) to a draft model, got %q: %q", repo, draft)
+		}
+	}
+	if err := validateMmproj(d.Mmproj); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	if err := validateOptions(d.Options, reservedOptionsKeys); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	for _, k := range slices.Sorted(maps.Keys(d.Options)) {
+		if _, ok := topOptions[k]; ok {
+			return fmt.Errorf("options key '%s' is set both in options and in defaults; set one or the other", k)
+		}
+	}
+	return nil
+}
+
+// sharedOptions returns the options of every router model: the top-level
+// options and those in Defaults.
+func (p *Preset) sharedOptions() Options {
+	if len(p.Defaults.Options) == 0 {
+		return p.Options
+	}
+	options := maps.Clone(p.Options)
+	if options == nil {
+		options = Options{}
+	}
+	maps.Copy(options, p.Defaults.Options)
+	return options
+}
+
+// applyDefaults fills in what the models of a router preset leave to
+// Defaults and moves the default options to the top-level options, then
+// clears Defaults. A draft-model of "none", set by the model or mapped to
+// its repository, becomes empty.
+func (p *Preset) applyDefaults() {
+	for i := range p.Models {
+		m := &p.Models[i]
+		if m.DraftModel == "" {
+			m.DraftModel = p.Defaults.draftModelFor(m.Model)
+		}
+		if m.DraftModel == DraftModelNone {
+			m.DraftModel = ""
+		}
+		if m.Mmproj == "" {
+			m.Mmproj = p.Defaults.Mmproj
+		}
+	}
+	p.Options = p.sharedOptions()
+	p.Defaults = Defaults{}
+}
@@ -0,0 +1,89 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFile_RouterDefaults(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	content := `name: fleet
+mode: router
+options:
+  flash-attn: "on"
+defaults:
+  draft-model: f:./draft.gguf
+  draft-models:
+    Qwen/Qwen3-32B-GGUF: h:Qwen/Qwen3-0.6B-GGUF:Q8_0
+  mmproj: none
+  options:
+    ctx-size: 8192
+models:
+  - name: plain
+    model: f:/models/plain.gguf
+  - name: qwen
+    model: h:Qwen/Qwen3-32B-GGUF:Q4_K_M
+  - name: own
+    model: f:/models/own.gguf
+    draft-model: f:/models/own-draft.gguf
+    mmproj: f:/models/own-mmproj.gguf
+  - name: nodraft
+    model: f:/models/nodraft.gguf
+    draft-model: none
+`
+	path := filepath.Join(dir, "fleet.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	p, err := LoadFile(path)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	want := []struct{ draft, mmproj string }{
+		{"f:" + filepath.Join(dir, "draft.gguf"), "none"},
+		{"h:Qwen/Qwen3-0.6B-GGUF:Q8_0", "none"},
+		{"f:/models/own-draft.gguf", "f:/models/own-mmproj.gguf"},
+		{"", "none"},
+	}
+	for i, w := range want {
+		m := p.Models[i]
+		if m.DraftModel != w.draft || m.Mmproj != w.mmproj {
+			t.Errorf("model %s: draft-model = %q, mmproj = %q, want %q, %q", m.Name, m.DraftModel, m.Mmproj, w.draft, w.mmproj)
+		}
+	}
+	if p.Options["ctx-size"] != "8192" || p.Options["flash-attn"] != "on" {
+		t.Errorf("Options = %v, want ctx-size and flash-attn", p.Options)
+	}
+	if !p.Defaults.IsZero() {
+		t.Errorf("Defaults = %+v, want applied and cleared", p.Defaults)
+	}
+}
+
+func TestGenerateConfigINI_Defaults(t *testing.T) {
+	// Arrange
+	p := &Preset{
+		Name: "fleet",
+		Mode: "router",
+		Defaults: Defaults{
+			DraftModel: "f:/models/draft.gguf",
+			Options:    Options{"ctx-size": "8192"},
+		},
+		Models: []ModelEntry{{Name: "a", Model: "f:/models/a.gguf"}},
+	}
+	p.applyDefaults()
+
+	// Act
+	got := p.GenerateConfigINI()
+
+	// Assert
+	want := "[*]\nctx-size = 8192\n\n[a]\nmodel = /models/a.gguf\nmodel-draft = /models/draft.gguf\n"
+	if got != want {
+		t.Errorf("GenerateConfigINI() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+package preset
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandEnvKey is the top-level key that disables environment expansion.
+const expandEnvKey = "expand-env"
+
+// expandEnv expands ${VAR} and ${VAR:-default} in every string value of a
+// decoded preset document, unless the document sets expand-env: false.
+// Mapping keys are left alone. Unquoted values are re-typed after expansion,
+// so "port: ${PORT:-8080}" still decodes as an integer.
+func expandEnv(doc *yaml.Node, lookup func(string) (string, bool)) error {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == expandEnvKey && root.Content[i+1].Value == "false" {
+				return nil
+			}
+		}
+	}
+	return expandNode(root, lookup)
+}
+
+func expandNode(n *yaml.Node, lookup func(string) (string, bool)) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			if err := expandNode(n.Content[i], lookup); err != nil {
+				return fmt.Errorf("%s: %w", n.Content[i-1].Value, err)
+			}
+		}
+	case yaml.SequenceNode:
+		for _, c := range n.Content {
+			if err := expandNode(c, lookup); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(n.Value, "$") {
+			return nil
+		}
+		v, err := expandString(n.Value, lookup)
+		if err != nil {
+			return err
+		}
+		n.Value = v
+		if n.Style == 0 {
+			n.Tag = "" // re-resolve the plain scalar's type
+		}
+	}
+	return nil
+}
+
+// expandString expands ${VAR} and ${VAR:-default} in s. "$${" produces a
+// literal "${". A "$" not followed by "{" is kept as is. Without a default,
+// an unset variable is an error; a default also replaces an empty value.
+func expandString(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "$")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, "$${"):
+			b.WriteString("${")
+			s = s[3:]
+		case strings.HasPrefix(s, "${"):
+			end := strings.Index(s, "}")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated %q", s)
+			}
+			v, err := lookupVar(s[2:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			s = s[end+1:]
+		default:
+			b.WriteString("$")
+			s = s[1:]
+		}
+	}
+}
+
+func lookupVar(expr string, lookup func(string) (string, bool)) (string, error) {
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if name == "" {
+		return "", fmt.Errorf("empty variable name in ${%s}", expr)
+	}
+	v, ok := lookup(name)
+	if hasDefault {
+		if v == "" {
+			return def, nil
+		}
+		return v, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set (use ${%s:-default} for a fallback)", name, name)
+	}
+	return v, nil
+}
+
+// lookupEnv is the variable lookup used when loading presets.
+var lookupEnv = os.LookupEnv
@@ -0,0 +1,140 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpandString(t *testing.T) {
+	env := map[string]string{"MODELS": "/data/models", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "no variables", input: "f:/abs/model.gguf", want: "f:/abs/model.gguf"},
+		{name: "set variable", input: "f:${MODELS}/qwen.gguf", want: "f:/data/models/qwen.gguf"},
+		{name: "default unused when set", input: "${MODELS:-/opt}", want: "/data/models"},
+		{name: "default when unset", input: "${MISSING:-/opt/models}", want: "/opt/models"},
+		{name: "default when empty", input: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "empty without default", input: "a${EMPTY}b", want: "ab"},
+		{name: "escaped", input: "$${MODELS}", want: "${MODELS}"},
+		{name: "bare dollar kept", input: "cost $5 $MODELS", want: "cost $5 $MODELS"},
+		{name: "unset without default", input: "${MISSING}", wantErr: "MISSING is not set"},
+		{name: "unterminated", input: "${MODELS", wantErr: "unterminated"},
+		{name: "empty name", input: "${:-x}", wantErr: "empty variable name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandString(tt.input, lookup)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandString(%q) error = %v, want containing %q", tt.input, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandString(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("expandString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile_ExpandsEnvironment(t *testing.T) {
+	t.Run("expands paths, options and typed fields", func(t *testing.T) {
+		// Arrange
+		t.Setenv("ALPACA_TEST_MODELS", "/data/models")
+		t.Setenv("ALPACA_TEST_PORT", "9000")
+		tmpDir := t.TempDir()
+		content := `name: portable
+model: f:${ALPACA_TEST_MODELS}/qwen.gguf
+port: ${ALPACA_TEST_PORT}
+host: ${ALPACA_TEST_HOST:-0.0.0.0}
+options:
+  ctx-size: ${ALPACA_TEST_CTX:-8192}
+`
+		path := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		p, err := LoadFile(path)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+		if p.Model != "f:/data/models/qwen.gguf" {
+			t.Errorf("Model = %q", p.Model)
+		}
+		if p.Port != 9000 {
+			t.Errorf("Port = %d, want 9000", p.Port)
+		}
+		if p.Host != "0.0.0.0" {
+			t.Errorf("Host = %q, want 0.0.0.0", p.Host)
+		}
+		if p.Options["ctx-size"] != "8192" {
+			t.Errorf("Options[ctx-size] = %q, want 8192", p.Options["ctx-size"])
+		}
+	})
+
+	t.Run("expand-env false keeps values literal", func(t *testing.T) {
+		// Arrange
+		tmpDir := t.TempDir()
+		content := `name: literal
+model: f:/abs/model.gguf
+expand-env: false
+options:
+  chat-template: "${NOT_A_VAR}"
+`
+		path := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		p, err := LoadFile(path)
+
+		// Assert
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+		if p.Options["chat-template"] != "${NOT_A_VAR}" {
+			t.Errorf("Options[chat-template] = %q, want literal", p.Options["chat-template"])
+		}
+	})
+
+	t.Run("unset variable names the field", func(t *testing.T) {
+		// Arrange
+		tmpDir := t.TempDir()
+		content := `name: broken
+model: f:${ALPACA_TEST_UNSET_DIR}/model.gguf
+`
+		path := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Act
+		_, err := LoadFile(path)
+
+		// Assert
+		if err == nil || !strings.Contains(err.Error(), "model: environment variable ALPACA_TEST_UNSET_DIR is not set") {
+			t.Errorf("LoadFile() error = %v, want unset variable error for model", err)
+		}
+	})
+}
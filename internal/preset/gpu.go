@@ -0,0 +1,119 @@
+package preset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GPULayersAuto is the gpu-layers value the daemon replaces with a layer
+// count computed from the model size and detected VRAM.
+const GPULayersAuto = "auto"
+
+// GPU holds the GPU placement of a preset or router model. Each field maps
+// to a llama-server option, checked for consistency when the preset loads.
+type GPU struct {
+	// Layers is how many layers to offload (--n-gpu-layers): a count,
+	// "all", or "auto".
+	Layers string `yaml:"gpu-layers,omitempty"`
+
+	// TensorSplit is the share of the model each GPU holds (--tensor-split),
+	// e.g. [3, 1] puts three quarters on GPU 0.
+	TensorSplit []float64 `yaml:"tensor-split,omitempty,flow"`
+
+	// MainGPU is the GPU for intermediate results, or for the whole model
+	// with split-mode none (--main-gpu).
+	MainGPU *int `yaml:"main-gpu,omitempty"`
+}
+
+// gpuOptionAliases are the llama-server option names each GPU field sets.
+var gpuOptionAliases = map[string][]string{
+	"gpu-layers":   {"n-gpu-layers", "gpu-layers", "ngl"},
+	"tensor-split": {"tensor-split", "ts"},
+	"main-gpu":     {"main-gpu", "mg"},
+}
+
+// IsZero reports whether no GPU field is set.
+func (g GPU) IsZero() bool {
+	return g.Layers == "" && len(g.TensorSplit) == 0 && g.MainGPU == nil
+}
+
+// gpuOption is a set GPU field as a llama-server option.
+type gpuOption struct {
+	field string // preset field name
+	key   string // llama-server option name
+	value string
+}
+
+// options returns the set fields in argument order.
+func (g GPU) options() []gpuOption {
+	var opts []gpuOption
+	if g.Layers != "" {
+		opts = append(opts, gpuOption{"gpu-layers", "n-gpu-layers", g.Layers})
+	}
+	if len(g.TensorSplit) > 0 {
+		opts = append(opts, gpuOption{"tensor-split", "tensor-split", g.FormatTensorSplit()})
+	}
+	if g.MainGPU != nil {
+		opts = append(opts, gpuOption{"main-gpu", "main-gpu", strconv.Itoa(*g.MainGPU)})
+	}
+	return opts
+}
+
+// FormatTensorSplit returns TensorSplit as llama-server takes it, e.g. "3,1".
+func (g GPU) FormatTensorSplit() string {
+	parts := make([]string, len(g.TensorSplit))
+	for i, v := range g.TensorSplit {
+		parts[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// String describes the set fields for display, e.g.
+// "gpu-layers=auto tensor-split=3,1 main-gpu=0".
+func (g GPU) String() string {
+	var parts []string
+	for _, o := range g.options() {
+		parts = append(parts, o.field+"="+o.value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// validate checks the field values and that opts does not set the same
+// llama-server options.
+func (g GPU) validate(opts Options) error {
+	if g.Layers != "" && g.Layers != GPULayersAuto && g.Layers != "all" {
+		if n, err := strconv.Atoi(g.Layers); err != nil || n < 0 {
+			return fmt.Errorf("gpu-layers must be a non-negative number, 'all', or 'auto', got '%s'", g.Layers)
+		}
+	}
+	if len(g.TensorSplit) > 0 {
+		positive := false
+		for _, v := range g.TensorSplit {
+			if v < 0 {
+				return fmt.Errorf("tensor-split values must not be negative")
+			}
+			positive = positive || v > 0
+		}
+		if !positive {
+			return fmt.Errorf("tensor-split needs at least one value above 0")
+		}
+	}
+	if g.MainGPU != nil {
+		if *g.MainGPU < 0 {
+			return fmt.Errorf("main-gpu must not be negative")
+		}
+		if len(g.TensorSplit) > 0 && *g.MainGPU >= len(g.TensorSplit) {
+			return fmt.Errorf("main-gpu %d is not in tensor-split, which covers GPUs 0-%d", *g.MainGPU, len(g.TensorSplit)-1)
+		}
+	}
+
+	for _, o := range g.options() {
+		for _, k := range gpuOptionAliases[o.field] {
+			if _, ok := opts[k]; ok {
+				return fmt.Errorf("options key '%s' conflicts with the %s field; set one or the other", k, o.field)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package preset
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGPU_YAML(t *testing.T) {
+	// Arrange
+	input := "name: multi\nmodel: f:/m.gguf\ngpu-layers: 99\ntensor-split: [3, 1]\nmain-gpu: 0\n"
+
+	// Act
+	var p Preset
+	err := yaml.Unmarshal([]byte(input), &p)
+	out, marshalErr := yaml.Marshal(&p)
+
+	// Assert
+	if err != nil || marshalErr != nil {
+		t.Fatalf("yaml errors = %v, %v", err, marshalErr)
+	}
+	want := GPU{Layers: "99", TensorSplit: []float64{3, 1}, MainGPU: intPtr(0)}
+	if !reflect.DeepEqual(p.GPU, want) {
+		t.Errorf("GPU = %+v, want %+v", p.GPU, want)
+	}
+	if got := p.GPU.String(); got != "gpu-layers=99 tensor-split=3,1 main-gpu=0" {
+		t.Errorf("String() = %q", got)
+	}
+	var back Preset
+	if err := yaml.Unmarshal(out, &back); err != nil || !reflect.DeepEqual(back.GPU, want) {
+		t.Errorf("round trip = %+v (%v), want %+v\n%s", back.GPU, err, want, out)
+	}
+}
@@ -0,0 +1,78 @@
+package preset
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Health configures how the daemon decides that llama-server is ready
+// after it starts, for builds and proxies in front of them that report
+// readiness other than with 200 from GET /health. Zero fields keep those
+// defaults.
+type Health struct {
+	// Path is the readiness endpoint, e.g. "/ready".
+	Path string `yaml:"health-path,omitempty"`
+
+	// Method is GET or HEAD.
+	Method string `yaml:"health-method,omitempty"`
+
+	// ExpectStatus is the HTTP status of a ready server.
+	ExpectStatus int `yaml:"health-expect-status,omitempty"`
+
+	// ExpectJSON maps fields of the response body, as dotted paths such as
+	// "backend.status", to the values they hold once the server is ready.
+	ExpectJSON map[string]string `yaml:"health-expect-json,omitempty"`
+}
+
+// IsZero reports whether no health field is set.
+func (h Health) IsZero() bool {
+	return h.Path == "" && h.Method == "" && h.ExpectStatus == 0 && len(h.ExpectJSON) == 0
+}
+
+// String describes the set fields for display, e.g.
+// "GET /ready status=204 backend.status=ok".
+func (h Health) String() string {
+	if h.IsZero() {
+		return ""
+	}
+	method, path := h.Method, h.Path
+	if method == "" {
+		method = http.MethodGet
+	}
+	if path == "" {
+		path = "/health"
+	}
+	parts := []string{method, path}
+	if h.ExpectStatus != 0 {
+		parts = append(parts, "status="+strconv.Itoa(h.ExpectStatus))
+	}
+	for _, k := range slices.Sorted(maps.Keys(h.ExpectJSON)) {
+		parts = append(parts, k+"="+h.ExpectJSON[k])
+	}
+	return strings.Join(parts, " ")
+}
+
+func (h Health) validate() error {
+	if h.Path != "" && (!strings.HasPrefix(h.Path, "/") || strings.ContainsAny(h.Path, " \t\n\r")) {
+		return fmt.Errorf("health-path must be a path starting with '/', got %q", h.Path)
+	}
+	if h.Method != "" && h.Method != http.MethodGet && h.Method != http.MethodHead {
+		return fmt.Errorf("health-method must be GET or HEAD, got %q", h.Method)
+	}
+	if h.ExpectStatus != 0 && (h.ExpectStatus < 100 || h.ExpectStatus > 599) {
+		return fmt.Errorf("health-expect-status must be an HTTP status (100-599), got %d", h.ExpectStatus)
+	}
+	if len(h.ExpectJSON) > 0 && h.Method == http.MethodHead {
+		return fmt.Errorf("health-expect-json needs a response body; use health-method: GET")
+	}
+	for k := range h.ExpectJSON {
+		if k == "" || slices.Contains(strings.Split(k, "."), "") {
+			return fmt.Errorf("health-expect-json field %q must be a dotted path such as backend.status", k)
+		}
+	}
+	return nil
+}
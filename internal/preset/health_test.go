@@ -0,0 +1,37 @@
+package preset
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestHealth_YAML(t *testing.T) {
+	// Arrange
+	input := "name: sidecar\nmodel: f:/m.gguf\nhealth-path: /ready\nhealth-expect-status: 204\nhealth-expect-json:\n  backend.status: ok\n"
+
+	// Act
+	var p Preset
+	err := yaml.Unmarshal([]byte(input), &p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := p.Health.String(); got != "GET /ready status=204 backend.status=ok" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestHealth_StringDefault(t *testing.T) {
+	// Act
+	got := Health{}.String()
+
+	// Assert
+	if got != "" {
+		t.Errorf("String() = %q, want empty for the default check", got)
+	}
+}
@@ -0,0 +1,376 @@
+package preset
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// llamaSwapConfig is the part of a llama-swap config.yaml that ImportLlamaSwap reads.
+type llamaSwapConfig struct {
+	HealthCheckTimeout int            `yaml:"healthCheckTimeout"`
+	Macros             map[string]any `yaml:"macros"`
+	Models             yaml.Node      `yaml:"models"`
+	Groups             map[string]any `yaml:"groups"`
+	Hooks              map[string]any `yaml:"hooks"`
+}
+
+type llamaSwapModel struct {
+	Cmd     string         `yaml:"cmd"`
+	TTL     int            `yaml:"ttl"`
+	Aliases []string       `yaml:"aliases"`
+	Env     []string       `yaml:"env"`
+	Macros  map[string]any `yaml:"macros"`
+}
+
+// llamaSwapMacroPattern matches ${name} in a llama-swap cmd.
+var llamaSwapMacroPattern = regexp.MustCompile(`\$\{([a-zA-Z0-9_-]+)\}`)
+
+// llamaServerShortFlags maps llama-server's short flags to the long names
+// presets use as option keys.
+var llamaServerShortFlags = map[string]string{
+	"m":     "model",
+	"md":    "model-draft",
+	"mm":    "mmproj",
+	"hf":    "hf-repo",
+	"hfr":   "hf-repo",
+	"hfd":   "hf-repo-draft",
+	"hfrd":  "hf-repo-draft",
+	"hff":   "hf-file",
+	"a":     "alias",
+	"c":     "ctx-size",
+	"cd":    "ctx-size-draft",
+	"n":     "n-predict",
+	"t":     "threads",
+	"tb":    "threads-batch",
+	"b":     "batch-size",
+	"ub":    "ubatch-size",
+	"np":    "parallel",
+	"s":     "seed",
+	"fa":    "flash-attn",
+	"cb":    "cont-batching",
+	"nocb":  "no-cont-batching",
+	"ngl":   "n-gpu-layers",
+	"ngld":  "n-gpu-layers-draft",
+	"sm":    "split-mode",
+	"ts":    "tensor-split",
+	"mg":    "main-gpu",
+	"dev":   "device",
+	"devd":  "device-draft",
+	"ctk":   "cache-type-k",
+	"ctv":   "cache-type-v",
+	"ctkd":  "cache-type-k-draft",
+	"ctvd":  "cache-type-v-draft",
+	"ot":    "override-tensor",
+	"cmoe":  "cpu-moe",
+	"ncmoe": "n-cpu-moe",
+	"dt":    "defrag-thold",
+	"v":     "verbose",
+	"lv":    "verbosity",
+	"sp":    "special",
+	"kvu":   "kv-unified",
+	"to":    "timeout",
+	"j":     "json-schema",
+	"jf":    "json-schema-file",
+	"e":     "escape",
+	"p":     "prompt",
+	"f":     "file",
+}
+
+// ImportLlamaSwap converts a llama-swap config.yaml into a router preset
+// named name, one model per llama-swap model, in file order. Each model's
+// cmd must run llama-server; its flags become the model's fields and
+// options, and its ttl the model's ttl. The returned notes say what was
+// left out or needs checking, e.g. models that do not run llama-server,
+// env, aliases and groups.
+func ImportLlamaSwap(data []byte, name string) (*Preset, []string, error) {
+	var cfg llamaSwapConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("parse llama-swap config: %w", err)
+	}
+	if cfg.Models.Kind != yaml.MappingNode || len(cfg.Models.Content) == 0 {
+		return nil, nil, fmt.Errorf("llama-swap config has no models")
+	}
+
+	p := &Preset{Name: name, Mode: "router", StartupTimeout: cfg.HealthCheckTimeout}
+	var notes []string
+	var servers []string
+	for i := 0; i+1 < len(cfg.Models.Content); i += 2 {
+		id := cfg.Models.Content[i].Value
+		var m llamaSwapModel
+		if err := cfg.Models.Content[i+1].Decode(&m); err != nil {
+			return nil, nil, fmt.Errorf("model '%s': %w", id, err)
+		}
+		entry, server, modelNotes, err := importLlamaSwapModel(m, cfg.Macros)
+		for _, n := range modelNotes {
+			notes = append(notes, fmt.Sprintf("model '%s': %s", id, n))
+		}
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("model '%s' skipped: %v", id, err))
+			continue
+		}
+		entry.Name = uniqueModelName(SanitizeName(id), p.Models)
+		if entry.Name != id {
+			notes = append(notes, fmt.Sprintf("model '%s' renamed to '%s'; clients request it by the new name", id, entry.Name))
+		}
+		p.Models = append(p.Models, entry)
+		servers = append(servers, server)
+	}
+	if len(p.Models) == 0 {
+		return nil, notes, fmt.Errorf("no model in the llama-swap config runs llama-server")
+	}
+
+	// One llama-server serves every router model
+	if servers[0] != "llama-server" {
+		p.LlamaServerPath = servers[0]
+	}
+	for _, s := range servers[1:] {
+		if s != servers[0] {
+			notes = append(notes, fmt.Sprintf("models run different llama-server binaries; using %s", servers[0]))
+			break
+		}
+	}
+	if len(cfg.Groups) > 0 {
+		notes = append(notes, "groups are not imported; max-models and idle-timeout control how many models stay loaded")
+	}
+	if len(cfg.Hooks) > 0 {
+		notes = append(notes, "hooks are not imported; see hooks in docs/design/preset-format.md")
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, notes, fmt.Errorf("invalid preset: %w", err)
+	}
+	return p, notes, nil
+}
+
+// importLlamaSwapModel converts one llama-swap model. It returns the
+// entry without its name, the llama-server binary the cmd runs, and notes.
+func importLlamaSwapModel(m llamaSwapModel, macros map[string]any) (ModelEntry, string, []string, error) {
+	var notes []string
+	if len(m.Env) > 0 {
+		notes = append(notes, fmt.Sprintf("env not imported: %s", strings.Join(m.Env, " ")))
+	}
+	if len(m.Aliases) > 0 {
+		notes = append(notes, fmt.Sprintf("aliases not imported: %s", strings.Join(m.Aliases, ", ")))
+	}
+
+	args, err := splitLlamaSwapCmd(expandLlamaSwapMacros(m.Cmd, m.Macros, macros))
+	if err != nil {
+		return ModelEntry{}, "", notes, err
+	}
+	if len(args) == 0 {
+		return ModelEntry{}, "", notes, fmt.Errorf("cmd is empty")
+	}
+	if base := path.Base(filepath.ToSlash(args[0])); base != "llama-server" && base != "llama-server.exe" {
+		return ModelEntry{}, "", notes, fmt.Errorf("cmd runs %s, not llama-server", base)
+	}
+
+	entry := ModelEntry{TTL: max(m.TTL, 0)}
+	for _, f := range parseLlamaServerFlags(args[1:]) {
+		if f.err != "" {
+			notes = append(notes, f.err)
+			continue
+		}
+		switch f.key {
+		case "port", "host":
+			// llama-swap picks the port; the router preset sets its own
+		case "model":
+			entry.Model, notes = fileRef(f.value, notes)
+		case "model-draft":
+			entry.DraftModel, notes = fileRef(f.value, notes)
+		case "mmproj":
+			entry.Mmproj, notes = fileRef(f.value, notes)
+		case "lora":
+			var ref string
+			ref, notes = fileRef(f.value, notes)
+			entry.Lora = append(entry.Lora, ref)
+		case "hf-repo":
+			entry.Model, notes = hfRef(f.value, notes)
+		case "hf-repo-draft":
+			entry.DraftModel, notes = hfRef(f.value, notes)
+		case "hf-file":
+			return ModelEntry{}, "", notes, fmt.Errorf("--hf-file is not supported; use h:org/repo:quant")
+		default:
+			if entry.Options == nil {
+				entry.Options = Options{}
+			}
+			if _, ok := entry.Options[f.key]; ok {
+				notes = append(notes, fmt.Sprintf("--%s is given more than once; keeping %s", f.key, f.value))
+			}
+			entry.Options[f.key] = f.value
+		}
+	}
+	if entry.Model == "" {
+		return ModelEntry{}, "", notes, fmt.Errorf("cmd has no -m or -hf")
+	}
+	if entry.TTL > 0 {
+		for _, k := range []string{iniSleepIdleSeconds, iniLoadOnStartup} {
+			if _, ok := entry.Options[k]; ok {
+				notes = append(notes, fmt.Sprintf("--%s dropped; ttl is used instead", k))
+				delete(entry.Options, k)
+			}
+		}
+	}
+	return entry, args[0], notes, nil
+}
+
+// fileRef returns the f: reference for a path in a cmd, noting a relative
+// path, which llama-swap resolved from its own working directory.
+func fileRef(p string, notes []string) (string, []string) {
+	if !filepath.IsAbs(p) && !strings.HasPrefix(p, "~") {
+		notes = append(notes, fmt.Sprintf("%s is a relative path; make it absolute", p))
+	}
+	return "f:" + p, notes
+}
+
+// hfRef returns the h: reference for a -hf value, org/repo[:quant].
+// llama-server downloads Q4_K_M when no quant is given, so that is used.
+func hfRef(repo string, notes []string) (string, []string) {
+	if !strings.Contains(repo, ":") {
+		notes = append(notes, fmt.Sprintf("%s has no quant; using Q4_K_M like llama-server", repo))
+		repo += ":Q4_K_M"
+	}
+	return "h:" + repo, notes
+}
+
+// uniqueModelName returns name, or name with a number appended if a
+// model in models already has it.
+func uniqueModelName(name string, models []ModelEntry) string {
+	if name == "" {
+		name = "model"
+	}
+	taken := func(n string) bool {
+		for _, m := range models {
+			if m.Name == n {
+				return true
+			}
+		}
+		return false
+	}
+	unique := name
+	for i := 2; taken(unique); i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	return unique
+}
+
+// expandLlamaSwapMacros replaces ${name} in cmd with the model's macros,
+// then the config's. llama-swap's own macros, like ${PORT}, are left as is.
+func expandLlamaSwapMacros(cmd string, scopes ...map[string]any) string {
+	for range 10 { // macros may refer to other macros
+		expanded := llamaSwapMacroPattern.ReplaceAllStringFunc(cmd, func(s string) string {
+			name := s[2 : len(s)-1]
+			for _, macros := range scopes {
+				if v, ok := macros[name]; ok {
+					return fmt.Sprint(v)
+				}
+			}
+			return s
+		})
+		if expanded == cmd {
+			break
+		}
+		cmd = expanded
+	}
+	return cmd
+}
+
+// splitLlamaSwapCmd splits a llama-swap cmd into arguments the way
+// llama-swap does: lines starting with # are comments, a backslash at the
+// end of a line continues it, and quotes group words.
+func splitLlamaSwapCmd(cmd string) ([]string, error) {
+	var lines []string
+	for _, line := range strings.Split(cmd, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimSuffix(strings.TrimRight(line, " \t\r"), "\\"))
+	}
+
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+	for _, r := range strings.Join(lines, " ") {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("cmd has an unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// llamaServerFlag is a flag of a llama-server command line, by its long
+// name, or the note to give instead when it cannot be imported.
+type llamaServerFlag struct {
+	key, value string
+	err        string
+}
+
+// parseLlamaServerFlags reads llama-server flags. A flag not followed by a
+// value, like --jinja, is "true".
+func parseLlamaServerFlags(args []string) []llamaServerFlag {
+	var flags []llamaServerFlag
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !strings.HasPrefix(a, "-") || a == "-" || a == "--" {
+			flags = append(flags, llamaServerFlag{err: fmt.Sprintf("argument %q ignored", a)})
+			continue
+		}
+		key, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		if !strings.HasPrefix(a, "--") {
+			long, ok := llamaServerShortFlags[key]
+			if !ok {
+				flags = append(flags, llamaServerFlag{err: fmt.Sprintf("unknown flag %s ignored; add it to options by its long name", a)})
+				if i+1 < len(args) && isFlagValue(args[i+1]) {
+					i++
+				}
+				continue
+			}
+			key = long
+		}
+		if !hasValue {
+			value = "true"
+			if i+1 < len(args) && isFlagValue(args[i+1]) {
+				value = args[i+1]
+				i++
+			}
+		}
+		flags = append(flags, llamaServerFlag{key: key, value: value})
+	}
+	return flags
+}
+
+// isFlagValue reports whether a command line argument is a flag's value
+// rather than the next flag. Negative numbers are values.
+func isFlagValue(a string) bool {
+	if !strings.HasPrefix(a, "-") {
+		return true
+	}
+	_, err := strconv.ParseFloat(a, 64)
+	return err == nil
+}
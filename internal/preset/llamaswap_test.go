@@ -0,0 +1,172 @@
+package preset
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestImportLlamaSwap(t *testing.T) {
+	// Arrange
+	config := `healthCheckTimeout: 300
+macros:
+  server: /opt/llama.cpp/llama-server --port ${PORT}
+  ngl: 99
+models:
+  "qwen3:8b":
+    cmd: |
+      ${server}
+      # comment lines are skipped
+      -hf Qwen/Qwen3-8B-GGUF:Q4_K_M \
+      -c 16384 -ngl ${ngl} --jinja
+      --temp 0.7 --chat-template-kwargs '{"enable_thinking": false}'
+    ttl: 300
+    aliases: [qwen]
+  coder:
+    cmd: ${server} -m /models/coder.gguf -md /models/draft.gguf --lora /models/a.gguf -fa on --override-kv=tokenizer.ggml.add_bos_token=bool:false
+    env: ["CUDA_VISIBLE_DEVICES=0"]
+  whisper:
+    cmd: /opt/whisper/whisper-server --port ${PORT} -m /models/whisper.bin
+groups:
+  main:
+    members: [coder]
+`
+
+	// Act
+	p, notes, err := ImportLlamaSwap([]byte(config), "swap")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ImportLlamaSwap() error = %v", err)
+	}
+	want := &Preset{
+		Name:            "swap",
+		Mode:            "router",
+		StartupTimeout:  300,
+		LlamaServerPath: "/opt/llama.cpp/llama-server",
+		Models: []ModelEntry{
+			{
+				Name:  "qwen3-8b",
+				Model: "h:Qwen/Qwen3-8B-GGUF:Q4_K_M",
+				TTL:   300,
+				Options: Options{
+					"ctx-size":             "16384",
+					"n-gpu-layers":         "99",
+					"jinja":                "true",
+					"temp":                 "0.7",
+					"chat-template-kwargs": `{"enable_thinking": false}`,
+				},
+			},
+			{
+				Name:       "coder",
+				Model:      "f:/models/coder.gguf",
+				DraftModel: "f:/models/draft.gguf",
+				Lora:       []string{"f:/models/a.gguf"},
+				Options: Options{
+					"flash-attn":  "on",
+					"override-kv": "tokenizer.ggml.add_bos_token=bool:false",
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("ImportLlamaSwap() =\n%+v\nwant\n%+v", p, want)
+	}
+	wantNotes := []string{
+		"model 'qwen3:8b': aliases not imported: qwen",
+		"model 'qwen3:8b' renamed to 'qwen3-8b'; clients request it by the new name",
+		"model 'coder': env not imported: CUDA_VISIBLE_DEVICES=0",
+		"model 'whisper' skipped: cmd runs whisper-server, not llama-server",
+		"groups are not imported; max-models and idle-timeout control how many models stay loaded",
+	}
+	if !slices.Equal(notes, wantNotes) {
+		t.Errorf("notes = %q, want %q", notes, wantNotes)
+	}
+}
+
+func TestImportLlamaSwap_Models(t *testing.T) {
+	tests := []struct {
+		name      string
+		cmd       string
+		wantModel ModelEntry
+		wantNote  string
+	}{
+		{
+			name:      "hf repo without quant",
+			cmd:       "llama-server -hf org/repo-GGUF",
+			wantModel: ModelEntry{Name: "m", Model: "h:org/repo-GGUF:Q4_K_M"},
+			wantNote:  "org/repo-GGUF has no quant; using Q4_K_M like llama-server",
+		},
+		{
+			name:      "relative model path",
+			cmd:       "llama-server -m models/m.gguf",
+			wantModel: ModelEntry{Name: "m", Model: "f:models/m.gguf"},
+			wantNote:  "models/m.gguf is a relative path; make it absolute",
+		},
+		{
+			name:      "negative number value",
+			cmd:       "llama-server -m /m.gguf --seed -1 -ngl 99",
+			wantModel: ModelEntry{Name: "m", Model: "f:/m.gguf", Options: Options{"seed": "-1", "n-gpu-layers": "99"}},
+		},
+		{
+			name:      "unknown short flag",
+			cmd:       "llama-server -m /m.gguf -xyz 4",
+			wantModel: ModelEntry{Name: "m", Model: "f:/m.gguf"},
+			wantNote:  "unknown flag -xyz ignored; add it to options by its long name",
+		},
+		{
+			name:      "port and host dropped",
+			cmd:       "llama-server --host 0.0.0.0 --port ${PORT} -m /m.gguf",
+			wantModel: ModelEntry{Name: "m", Model: "f:/m.gguf"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			config := "models:\n  m:\n    cmd: " + tt.cmd + "\n"
+
+			// Act
+			p, notes, err := ImportLlamaSwap([]byte(config), "swap")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("ImportLlamaSwap() error = %v", err)
+			}
+			if !reflect.DeepEqual(p.Models, []ModelEntry{tt.wantModel}) {
+				t.Errorf("Models = %+v, want [%+v]", p.Models, tt.wantModel)
+			}
+			if tt.wantNote != "" && !slices.Contains(notes, "model 'm': "+tt.wantNote) {
+				t.Errorf("notes = %q, want %q", notes, tt.wantNote)
+			}
+			if tt.wantNote == "" && len(notes) > 0 {
+				t.Errorf("notes = %q, want none", notes)
+			}
+		})
+	}
+}
+
+func TestImportLlamaSwap_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"invalid yaml", "models: [", "parse llama-swap config"},
+		{"no models", "macros: {}\n", "has no models"},
+		{"no llama-server model", "models:\n  w:\n    cmd: whisper-server -m /w.bin\n", "no model in the llama-swap config runs llama-server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, _, err := ImportLlamaSwap([]byte(tt.config), "swap")
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ImportLlamaSwap() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -99,45 +99,60 @@ func (l *Loader) Exists(name string) (bool, error) {
 
 // Create creates a new preset file with a random filename.
 func (l *Loader) Create(p *Preset) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal preset: %w", err)
+	}
+	_, err = l.create(p, data)
+	return err
+}
+
+// CreateYAML creates a new preset file with a random filename from data,
+// written as is, so its comments are kept. It returns the file's path.
+func (l *Loader) CreateYAML(data []byte) (string, error) {
+	var p Preset
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return "", fmt.Errorf("parse yaml: %w", err)
+	}
+	return l.create(&p, data)
+}
+
+// create validates p and writes data, its YAML, to a new preset file,
+// returning its path.
+func (l *Loader) create(p *Preset, data []byte) (string, error) {
 	if err := ValidateName(p.Name); err != nil {
-		return err
+		return "", err
 	}
 	if err := p.Validate(); err != nil {
-		return fmt.Errorf("invalid preset: %w", err)
+		return "", fmt.Errorf("invalid preset: %w", err)
 	}
 
 	// Ensure directory exists
 	if err := os.MkdirAll(l.presetsDir, 0755); err != nil {
-		return fmt.Errorf("create presets dir: %w", err)
+		return "", fmt.Errorf("create presets dir: %w", err)
 	}
 
 	// Check if name already exists
 	exists, err := l.Exists(p.Name)
 	if err != nil {
-		return fmt.Errorf("check existing: %w", err)
+		return "", fmt.Errorf("check existing: %w", err)
 	}
 	if exists {
-		return &AlreadyExistsError{Name: p.Name}
+		return "", &AlreadyExistsError{Name: p.Name}
 	}
 
 	// Generate random filename
 	filename, err := generateFilename()
 	if err != nil {
-		return fmt.Errorf("generate filename: %w", err)
+		return "", fmt.Errorf("generate filename: %w", err)
 	}
 
 	path := filepath.Join(l.presetsDir, filename+".yaml")
-
-	data, err := yaml.Marshal(p)
-	if err != nil {
-		return fmt.Errorf("marshal preset: %w", err)
-	}
-
 	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("write preset: %w", err)
+		return "", fmt.Errorf("write preset: %w", err)
 	}
 
-	return nil
+	return path, nil
 }
 
 // Remove removes a preset by name.
@@ -152,6 +167,79 @@ func (l *Loader) Remove(name string) error {
 	return nil
 }
 
+// Rename changes the name of a preset. Only the name line of its file is
+// rewritten, so comments, formatting, and relative paths are kept.
+func (l *Loader) Rename(oldName, newName string) error {
+	if err := ValidateName(newName); err != nil {
+		return err
+	}
+	path, _, err := l.findByName(oldName)
+	if err != nil {
+		return err
+	}
+	if newName == oldName {
+		return nil
+	}
+	exists, err := l.Exists(newName)
+	if err != nil {
+		return fmt.Errorf("check existing: %w", err)
+	}
+	if exists {
+		return &AlreadyExistsError{Name: newName}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("read preset: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read preset: %w", err)
+	}
+	renamed, err := setName(data, newName)
+	if err != nil {
+		return fmt.Errorf("rename preset: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, renamed, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write preset: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write preset: %w", err)
+	}
+	return nil
+}
+
+// setName replaces the value of the top-level name field in preset YAML,
+// keeping the rest of the document byte for byte.
+func setName(data []byte, name string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("not a YAML mapping")
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if key.Value != "name" || value.Kind != yaml.ScalarNode || value.Line != key.Line {
+			continue
+		}
+		lines := strings.SplitAfter(string(data), "\n")
+		line := lines[key.Line-1]
+		end := strings.TrimRight(line, "\r\n")
+		newLine := line[:value.Column-1] + name
+		if value.LineComment != "" {
+			newLine += " " + value.LineComment
+		}
+		lines[key.Line-1] = newLine + line[len(end):]
+		return []byte(strings.Join(lines, "")), nil
+	}
+	return nil, fmt.Errorf("no name field on a single line")
+}
+
 // findByName searches for a preset by name and returns its path and preset.
 // If multiple presets have the same name, the first one found (in directory order)
 // is returned. This is intentional - users should avoid duplicate names.
@@ -222,8 +310,15 @@ func loadFromPath(absPath string) (*Preset, error) {
 		return nil, fmt.Errorf("read file: %w", err)
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse yaml: %w", err)
+	}
+	if err := expandEnv(&doc, lookupEnv); err != nil {
+		return nil, fmt.Errorf("expand environment: %w", err)
+	}
 	var preset Preset
-	if err := yaml.Unmarshal(data, &preset); err != nil {
+	if err := doc.Decode(&preset); err != nil {
 		return nil, fmt.Errorf("parse yaml: %w", err)
 	}
 
@@ -237,7 +332,29 @@ func loadFromPath(absPath string) (*Preset, error) {
 
 	baseDir := filepath.Dir(absPath)
 
+	if preset.LlamaServerPath != "" {
+		resolved, err := pathutil.ResolveCommand(preset.LlamaServerPath, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("resolve llama-server path: %w", err)
+		}
+		preset.LlamaServerPath = resolved
+	}
+
+	preset.Hooks, err = preset.Hooks.Resolve(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if preset.SlotSavePath != "" && preset.SlotSavePath != SlotSaveAuto {
+		resolved, err := pathutil.ResolvePath(preset.SlotSavePath, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("resolve slot-save-path: %w", err)
+		}
+		preset.SlotSavePath = resolved
+	}
+
 	if preset.IsRouter() {
+		preset.applyDefaults()
 		if err := resolveRouterModelPaths(&preset, baseDir); err != nil {
 			return nil, err
 		}
@@ -272,6 +389,12 @@ func resolveSingleModelPaths(preset *Preset, baseDir string) error {
 	}
 	preset.Mmproj = resolvedMmproj
 
+	resolvedLora, err := resolveLoraPaths(preset.Lora, baseDir)
+	if err != nil {
+		return fmt.Errorf("resolve lora path: %w", err)
+	}
+	preset.Lora = resolvedLora
+
 	return nil
 }
 
@@ -299,6 +422,12 @@ func resolveRouterModelPaths(preset *Preset, baseDir string) error {
 			return fmt.Errorf("resolve mmproj path for '%s': %w", m.Name, err)
 		}
 		m.Mmproj = resolvedMmproj
+
+		resolvedLora, err := resolveLoraPaths(m.Lora, baseDir)
+		if err != nil {
+			return fmt.Errorf("resolve lora path for '%s': %w", m.Name, err)
+		}
+		m.Lora = resolvedLora
 	}
 
 	return nil
@@ -339,6 +468,23 @@ func resolveMmprojPath(mmproj, baseDir string) (string, error) {
 	return "", fmt.Errorf("invalid mmproj value: %q", mmproj)
 }
 
+// resolveLoraPaths resolves f: paths in LoRA adapter references.
+// h: references are returned as-is (resolved by the daemon at load time).
+func resolveLoraPaths(loras []string, baseDir string) ([]string, error) {
+	if len(loras) == 0 {
+		return loras, nil
+	}
+	resolved := make([]string, len(loras))
+	for i, l := range loras {
+		r, err := resolveModelPath(l, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = r
+	}
+	return resolved, nil
+}
+
 // resolveModelPath resolves the model path in a preset.
 // - h: prefixed paths are returned as-is (HuggingFace identifiers)
 // - f: prefixed paths have relative paths resolved from baseDir
@@ -389,3 +389,74 @@ model: "f:/path/to/model.gguf"
 		}
 	})
 }
+
+func TestLoader_Rename(t *testing.T) {
+	t.Run("rewrites only the name line", func(t *testing.T) {
+		// Arrange
+		tmpDir := t.TempDir()
+		content := `# my coding preset
+name: "old-name" # shown in alpaca ls
+model: "f:./model.gguf"
+options:
+  ctx-size: 4096
+`
+		path := filepath.Join(tmpDir, "abc123.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		loader := NewLoader(tmpDir)
+
+		// Act
+		err := loader.Rename("old-name", "new-name")
+
+		// Assert
+		if err != nil {
+			t.Fatalf("Rename() error = %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Replace(content, `"old-name"`, "new-name", 1)
+		if string(got) != want {
+			t.Errorf("file = %q, want %q", got, want)
+		}
+		if exists, _ := loader.Exists("new-name"); !exists {
+			t.Error("renamed preset not found")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		old, new string
+		wantErr  string
+	}{
+		{"missing preset", "nonexistent", "other", "not found"},
+		{"invalid new name", "first", "bad name", "alphanumeric"},
+		{"name collision", "first", "second", "already exists"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpDir := t.TempDir()
+			for file, name := range map[string]string{"a.yaml": "first", "b.yaml": "second"} {
+				data := "name: " + name + "\nmodel: \"f:/path/to/model.gguf\"\n"
+				if err := os.WriteFile(filepath.Join(tmpDir, file), []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			loader := NewLoader(tmpDir)
+
+			// Act
+			err := loader.Rename(tt.old, tt.new)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Rename() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if exists, _ := loader.Exists("first"); !exists {
+				t.Error("preset 'first' changed after failed Rename()")
+			}
+		})
+	}
+}
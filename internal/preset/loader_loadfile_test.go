@@ -36,6 +36,111 @@ options:
 		}
 	})
 
+	t.Run("resolves relative lora paths and keeps HuggingFace references", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		preset := `name: tuned
+model: f:/abs/path/model.gguf
+lora:
+  - f:./adapters/style.gguf
+  - h:org/lora-repo:adapter.gguf
+`
+		presetPath := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(presetPath, []byte(preset), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := LoadFile(presetPath)
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+
+		want := []string{"f:" + filepath.Join(tmpDir, "adapters/style.gguf"), "h:org/lora-repo:adapter.gguf"}
+		if len(p.Lora) != len(want) || p.Lora[0] != want[0] || p.Lora[1] != want[1] {
+			t.Errorf("Lora = %v, want %v", p.Lora, want)
+		}
+	})
+
+	t.Run("resolves llama-server path relative to preset directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		preset := `name: cuda
+model: f:/abs/path/model.gguf
+llama-server-path: ./build-cuda/bin/llama-server
+`
+		presetPath := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(presetPath, []byte(preset), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := LoadFile(presetPath)
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+
+		want := filepath.Join(tmpDir, "build-cuda/bin/llama-server")
+		if p.LlamaServerPath != want {
+			t.Errorf("LlamaServerPath = %q, want %q", p.LlamaServerPath, want)
+		}
+	})
+
+	t.Run("resolves hook paths from preset directory", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		preset := `name: hooked
+model: f:/abs/path/model.gguf
+hooks:
+  pre-load: ./scripts/check.sh
+  post-load: notify-ready
+  timeout: 10
+`
+		presetPath := filepath.Join(tmpDir, ".alpaca.yaml")
+		if err := os.WriteFile(presetPath, []byte(preset), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := LoadFile(presetPath)
+		if err != nil {
+			t.Fatalf("LoadFile() error = %v", err)
+		}
+
+		want := Hooks{PreLoad: filepath.Join(tmpDir, "scripts/check.sh"), PostLoad: "notify-ready", Timeout: 10}
+		if p.Hooks != want {
+			t.Errorf("Hooks = %+v, want %+v", p.Hooks, want)
+		}
+	})
+
+	t.Run("resolves slot-save-path from preset directory", func(t *testing.T) {
+		tests := []struct {
+			value string
+			want  string
+		}{
+			{"./slots", "slots"},
+			{"auto", "auto"},
+		}
+		for _, tt := range tests {
+			tmpDir := t.TempDir()
+			preset := "name: cached\nmodel: f:/abs/path/model.gguf\nslot-save-path: " + tt.value + "\n"
+			presetPath := filepath.Join(tmpDir, ".alpaca.yaml")
+			if err := os.WriteFile(presetPath, []byte(preset), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := LoadFile(presetPath)
+			if err != nil {
+				t.Fatalf("LoadFile() error = %v", err)
+			}
+
+			want := tt.want
+			if want != SlotSaveAuto {
+				want = filepath.Join(tmpDir, want)
+			}
+			if p.SlotSavePath != want {
+				t.Errorf("SlotSavePath = %q, want %q", p.SlotSavePath, want)
+			}
+		}
+	})
+
 	t.Run("resolves dot-relative model path from preset directory", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
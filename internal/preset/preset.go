@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/d2verb/alpaca/internal/pathutil"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,16 +24,22 @@ const (
 	DefaultPort = 8080
 	// DefaultHost is the default host for llama-server.
 	DefaultHost = "127.0.0.1"
+	// DefaultWarmupPrompt is completed when warmup is set without a prompt.
+	DefaultWarmupPrompt = "Hello"
+	// SlotSaveAuto as slot-save-path keeps the preset's saved slots in a
+	// directory of its own under ~/.alpaca/cache.
+	SlotSaveAuto = "auto"
 )
 
 // reservedOptionsKeys are keys that cannot be used in the top-level options map.
 var reservedOptionsKeys = []string{
-	"port", "host", "model", "model-draft", "mmproj", "models-max", "sleep-idle-seconds",
+	"port", "host", "model", "model-draft", "mmproj", "lora", "models-max", "sleep-idle-seconds",
+	"slot-save-path", "cache-reuse",
 }
 
 // reservedModelEntryOptionsKeys are keys that cannot be used in ModelEntry options.
 var reservedModelEntryOptionsKeys = []string{
-	"port", "host", "model", "model-draft", "mmproj",
+	"port", "host", "model", "model-draft", "mmproj", "lora",
 }
 
 // Options is a map of llama-server options.
@@ -97,26 +104,116 @@ func SanitizeName(name string) string {
 
 // ModelEntry represents a single model in router mode.
 type ModelEntry struct {
-	Name       string  `yaml:"name"`
-	Model      string  `yaml:"model"`
-	DraftModel string  `yaml:"draft-model,omitempty"`
-	Mmproj     string  `yaml:"mmproj,omitempty" json:"mmproj,omitempty"`
-	Options    Options `yaml:"options,omitempty"`
+	Name       string   `yaml:"name"`
+	Model      string   `yaml:"model"`
+	DraftModel string   `yaml:"draft-model,omitempty"`
+	Mmproj     string   `yaml:"mmproj,omitempty" json:"mmproj,omitempty"`
+	Lora       []string `yaml:"lora,omitempty"`
+	Pin        bool     `yaml:"pin,omitempty"`
+	TTL        int      `yaml:"ttl,omitempty"`
+	GPU        GPU      `yaml:",inline"`
+	Sampling   Sampling `yaml:",inline"`
+	Options    Options  `yaml:"options,omitempty"`
 }
 
+// Router config.ini keys controlling a model's lifecycle.
+const (
+	iniSleepIdleSeconds = "sleep-idle-seconds"
+	iniLoadOnStartup    = "load-on-startup"
+)
+
 // Preset represents a model + argument combination.
 type Preset struct {
 	Name        string       `yaml:"name"`
 	Model       string       `yaml:"model,omitempty"`
 	DraftModel  string       `yaml:"draft-model,omitempty"`
 	Mmproj      string       `yaml:"mmproj,omitempty" json:"mmproj,omitempty"`
+	Lora        []string     `yaml:"lora,omitempty"`
 	Mode        string       `yaml:"mode,omitempty"`
 	Port        int          `yaml:"port,omitempty"`
 	Host        string       `yaml:"host,omitempty"`
 	MaxModels   int          `yaml:"max-models,omitempty"`
 	IdleTimeout int          `yaml:"idle-timeout,omitempty"`
+	GPU         GPU          `yaml:",inline"`
+	Sampling    Sampling     `yaml:",inline"`
+	Priority    Priority     `yaml:",inline"`
+	Health      Health       `yaml:",inline"`
 	Options     Options      `yaml:"options,omitempty"`
 	Models      []ModelEntry `yaml:"models,omitempty"`
+
+	// Defaults holds what router models share unless they set their own.
+	// Loading a preset applies and clears it.
+	Defaults Defaults `yaml:"defaults,omitempty"`
+
+	// LlamaServerPath overrides the llama-server binary for this preset.
+	LlamaServerPath string `yaml:"llama-server-path,omitempty"`
+
+	// StartupTimeout is how many seconds llama-server may take to become
+	// ready. Zero uses startup-timeout from config.yaml.
+	StartupTimeout int `yaml:"startup-timeout,omitempty"`
+
+	// Watch reloads the preset when its file changes while it is running.
+	Watch bool `yaml:"watch,omitempty"`
+
+	// Warmup makes the load wait for a one-token completion of
+	// WarmupPrompt after /health reports OK.
+	Warmup       bool   `yaml:"warmup,omitempty"`
+	WarmupPrompt string `yaml:"warmup-prompt,omitempty"`
+
+	// SlotSavePath is the directory llama-server saves and restores slots
+	// (prompt caches) in: SlotSaveAuto, or a path. It is created on load.
+	SlotSavePath string `yaml:"slot-save-path,omitempty"`
+
+	// CacheReuse is the minimum chunk size llama-server reuses from the
+	// prompt cache by shifting the KV cache. Zero leaves it disabled.
+	CacheReuse int `yaml:"cache-reuse,omitempty"`
+
+	// ExpandEnv set to false disables ${VAR} expansion when the file is loaded.
+	ExpandEnv *bool `yaml:"expand-env,omitempty"`
+
+	// Hooks overrides the hooks from config.yaml for this preset.
+	Hooks Hooks `yaml:"hooks,omitempty"`
+}
+
+// Hooks are executables the daemon runs around model loads.
+// Each is a command name (looked up in PATH) or a path; empty means none.
+type Hooks struct {
+	PreLoad    string `yaml:"pre-load,omitempty"`    // before llama-server starts; failure aborts the load
+	PostLoad   string `yaml:"post-load,omitempty"`   // after the model is ready
+	PostUnload string `yaml:"post-unload,omitempty"` // after llama-server stops
+	Timeout    int    `yaml:"timeout,omitempty"`     // seconds per hook; 0 uses the default
+}
+
+// Merge returns h with the hooks set in override replacing its own.
+func (h Hooks) Merge(override Hooks) Hooks {
+	if override.PreLoad != "" {
+		h.PreLoad = override.PreLoad
+	}
+	if override.PostLoad != "" {
+		h.PostLoad = override.PostLoad
+	}
+	if override.PostUnload != "" {
+		h.PostUnload = override.PostUnload
+	}
+	if override.Timeout != 0 {
+		h.Timeout = override.Timeout
+	}
+	return h
+}
+
+// Resolve resolves each hook with pathutil.ResolveCommand against baseDir.
+func (h Hooks) Resolve(baseDir string) (Hooks, error) {
+	for _, hook := range []*string{&h.PreLoad, &h.PostLoad, &h.PostUnload} {
+		if *hook == "" {
+			continue
+		}
+		resolved, err := pathutil.ResolveCommand(*hook, baseDir)
+		if err != nil {
+			return Hooks{}, fmt.Errorf("resolve hook %s: %w", *hook, err)
+		}
+		*hook = resolved
+	}
+	return h, nil
 }
 
 // GetPort returns the port, using default if not set.
@@ -140,6 +237,14 @@ func (p *Preset) Endpoint() string {
 	return fmt.Sprintf("http://%s:%d", p.GetHost(), p.GetPort())
 }
 
+// GetWarmupPrompt returns the warmup prompt, using default if not set.
+func (p *Preset) GetWarmupPrompt() string {
+	if p.WarmupPrompt != "" {
+		return p.WarmupPrompt
+	}
+	return DefaultWarmupPrompt
+}
+
 // IsRouter returns true if this preset uses router mode.
 func (p *Preset) IsRouter() bool {
 	return p.Mode == "router"
@@ -167,9 +272,25 @@ func (p *Preset) BuildArgs() []string {
 		args = append(args, "--mmproj", mmprojPath)
 	}
 
+	for _, l := range p.Lora {
+		args = append(args, "--lora", strings.TrimPrefix(l, "f:"))
+	}
+
 	args = append(args, "--port", strconv.Itoa(p.GetPort()))
 	args = append(args, "--host", p.GetHost())
 
+	for _, o := range p.GPU.options() {
+		args = append(args, "--"+o.key, o.value)
+	}
+
+	for _, o := range p.cacheOptions() {
+		args = append(args, "--"+o.key, o.value)
+	}
+
+	for _, o := range p.Sampling.options() {
+		args = append(args, "--"+o.key, o.value)
+	}
+
 	// Convert options map to CLI args (sorted by key)
 	for _, k := range slices.Sorted(maps.Keys(p.Options)) {
 		v := p.Options[k]
@@ -206,15 +327,23 @@ func (p *Preset) BuildRouterArgs(configPath string) []string {
 }
 
 // GenerateConfigINI generates config.ini content for router mode.
-// The returned string is ready to be written to a file.
+// The returned string is ready to be written to a file. Values are quoted
+// where INI parsing would change them (see iniValue); model names, which
+// become section names, and option keys are kept INI-safe by Validate.
 func (p *Preset) GenerateConfigINI() string {
 	var b strings.Builder
 
-	// [*] global section from top-level Options
-	if len(p.Options) > 0 {
+	// [*] global section from top-level GPU fields and Options
+	cache := p.cacheOptions()
+	sampling := p.Sampling.options()
+	if len(p.Options) > 0 || !p.GPU.IsZero() || len(cache) > 0 || len(sampling) > 0 {
 		b.WriteString("[*]\n")
+		writeGPU(&b, p.GPU)
+		for _, o := range append(cache, sampling...) {
+			writeINI(&b, o.key, o.value)
+		}
 		for _, k := range slices.Sorted(maps.Keys(p.Options)) {
-			fmt.Fprintf(&b, "%s = %s\n", k, p.Options[k])
+			writeINI(&b, k, p.Options[k])
 		}
 		b.WriteString("\n")
 	}
@@ -223,22 +352,40 @@ func (p *Preset) GenerateConfigINI() string {
 	for _, m := range p.Models {
 		fmt.Fprintf(&b, "[%s]\n", m.Name)
 
-		modelPath := strings.TrimPrefix(m.Model, "f:")
-		fmt.Fprintf(&b, "model = %s\n", modelPath)
+		writeINI(&b, "model", strings.TrimPrefix(m.Model, "f:"))
 
 		if m.DraftModel != "" {
-			draftPath := strings.TrimPrefix(m.DraftModel, "f:")
-			fmt.Fprintf(&b, "model-draft = %s\n", draftPath)
+			writeINI(&b, "model-draft", strings.TrimPrefix(m.DraftModel, "f:"))
 		}
 
 		if IsMmprojActive(m.Mmproj) {
-			mmprojPath := strings.TrimPrefix(m.Mmproj, "f:")
-			fmt.Fprintf(&b, "mmproj = %s\n", mmprojPath)
+			writeINI(&b, "mmproj", strings.TrimPrefix(m.Mmproj, "f:"))
+		}
+
+		if len(m.Lora) > 0 {
+			loraPaths := make([]string, len(m.Lora))
+			for i, l := range m.Lora {
+				loraPaths[i] = strings.TrimPrefix(l, "f:")
+			}
+			writeINI(&b, "lora", strings.Join(loraPaths, ","))
+		}
+
+		// Pinned models load with the router and never sleep (-1 disables it).
+		if m.Pin {
+			writeINI(&b, iniLoadOnStartup, "true")
+			writeINI(&b, iniSleepIdleSeconds, "-1")
+		} else if m.TTL > 0 {
+			writeINI(&b, iniSleepIdleSeconds, strconv.Itoa(m.TTL))
+		}
+
+		writeGPU(&b, m.GPU)
+		for _, o := range m.Sampling.options() {
+			writeINI(&b, o.key, o.value)
 		}
 
 		if len(m.Options) > 0 {
 			for _, k := range slices.Sorted(maps.Keys(m.Options)) {
-				fmt.Fprintf(&b, "%s = %s\n", k, m.Options[k])
+				writeINI(&b, k, m.Options[k])
 			}
 		}
 
@@ -248,6 +395,49 @@ func (p *Preset) GenerateConfigINI() string {
 	return strings.TrimRight(b.String(), "\n") + "\n"
 }
 
+// writeINI writes a "key = value" config.ini line.
+func writeINI(b *strings.Builder, key, value string) {
+	fmt.Fprintf(b, "%s = %s\n", key, iniValue(value))
+}
+
+// iniValue returns v as a config.ini value. A value with whitespace, which
+// parsers trim or split on, comment characters, or quotes is written in
+// double quotes, e.g. "My Models/llama 3.gguf". Nothing inside is escaped,
+// so a value reads the same quoted or not: Windows paths keep their single
+// backslashes either way.
+func iniValue(v string) string {
+	if !strings.ContainsAny(v, " \t#;\"") {
+		return v
+	}
+	return `"` + v + `"`
+}
+
+// serverOption is a llama-server option set by a preset field.
+type serverOption struct {
+	key   string
+	value string
+}
+
+// cacheOptions returns the llama-server options for the preset's prompt
+// cache settings, in order. SlotSavePath must be resolved by then.
+func (p *Preset) cacheOptions() []serverOption {
+	var opts []serverOption
+	if p.SlotSavePath != "" {
+		opts = append(opts, serverOption{"slot-save-path", p.SlotSavePath})
+	}
+	if p.CacheReuse > 0 {
+		opts = append(opts, serverOption{"cache-reuse", strconv.Itoa(p.CacheReuse)})
+	}
+	return opts
+}
+
+// writeGPU writes the set GPU fields as config.ini lines.
+func writeGPU(b *strings.Builder, g GPU) {
+	for _, o := range g.options() {
+		writeINI(b, o.key, o.value)
+	}
+}
+
 // Validate checks that the preset configuration is consistent.
 func (p *Preset) Validate() error {
 	mode := p.Mode
@@ -255,6 +445,28 @@ func (p *Preset) Validate() error {
 		mode = "single"
 	}
 
+	if p.Hooks.Timeout < 0 {
+		return fmt.Errorf("hooks timeout must not be negative")
+	}
+	if p.StartupTimeout < 0 {
+		return fmt.Errorf("startup-timeout must not be negative")
+	}
+	if p.WarmupPrompt != "" && !p.Warmup {
+		return fmt.Errorf("warmup-prompt requires warmup: true")
+	}
+	if p.CacheReuse < 0 {
+		return fmt.Errorf("cache-reuse must not be negative")
+	}
+	if strings.ContainsAny(p.SlotSavePath, "\n\r") {
+		return fmt.Errorf("slot-save-path must not contain newline characters")
+	}
+	if err := p.Priority.validate(); err != nil {
+		return err
+	}
+	if err := p.Health.validate(); err != nil {
+		return err
+	}
+
 	if mode != "single" && mode != "router" {
 		return fmt.Errorf("mode must be 'single' or 'router'")
 	}
@@ -275,6 +487,9 @@ func (p *Preset) validateSingle() error {
 	if p.IdleTimeout > 0 {
 		return fmt.Errorf("idle-timeout is only valid in router mode")
 	}
+	if !p.Defaults.IsZero() {
+		return fmt.Errorf("defaults is only valid in router mode")
+	}
 	if p.Model == "" {
 		return fmt.Errorf("model field is required")
 	}
@@ -287,6 +502,15 @@ func (p *Preset) validateSingle() error {
 	if err := validateMmproj(p.Mmproj); err != nil {
 		return err
 	}
+	if err := validateLora(p.Lora); err != nil {
+		return err
+	}
+	if err := p.GPU.validate(p.Options); err != nil {
+		return err
+	}
+	if err := p.Sampling.validate(p.Options); err != nil {
+		return err
+	}
 	return validateOptions(p.Options, reservedOptionsKeys)
 }
 
@@ -295,20 +519,40 @@ func (p *Preset) validateRouter() error {
 		return fmt.Errorf("router mode defines models in the 'models' list, not as a top-level field")
 	}
 	if p.DraftModel != "" {
-		return fmt.Errorf("router mode defines draft-model per model in the 'models' list, not as a top-level field")
+		return fmt.Errorf("router mode defines draft-model per model in the 'models' list, not as a top-level field; share one with defaults: draft-model")
 	}
 	if p.Mmproj != "" {
-		return fmt.Errorf("router mode defines mmproj per model in the 'models' list, not as a top-level field")
+		return fmt.Errorf("router mode defines mmproj per model in the 'models' list, not as a top-level field; share one with defaults: mmproj")
+	}
+	if len(p.Lora) > 0 {
+		return fmt.Errorf("router mode defines lora per model in the 'models' list, not as a top-level field")
+	}
+	if p.Warmup {
+		return fmt.Errorf("warmup is only valid in single mode")
 	}
 	if len(p.Models) == 0 {
 		return fmt.Errorf("at least one model is required for router mode")
 	}
 
+	if p.GPU.Layers == GPULayersAuto {
+		return fmt.Errorf("gpu-layers: auto must be set per model in router mode")
+	}
+	if err := p.Defaults.validate(p.Options); err != nil {
+		return err
+	}
+	shared := p.sharedOptions()
+	if err := p.GPU.validate(shared); err != nil {
+		return err
+	}
+	if err := p.Sampling.validate(shared); err != nil {
+		return err
+	}
 	if err := validateOptions(p.Options, reservedOptionsKeys); err != nil {
 		return err
 	}
 
 	seen := make(map[string]bool)
+	pinned := 0
 	for _, m := range p.Models {
 		if err := ValidateName(m.Name); err != nil {
 			return fmt.Errorf("invalid model name: %w", err)
@@ -325,6 +569,16 @@ func (p *Preset) validateRouter() error {
 		if err := validateModelEntry(m); err != nil {
 			return err
 		}
+		if m.Pin {
+			pinned++
+		}
+	}
+
+	if p.MaxModels > 0 && pinned > p.MaxModels {
+		return fmt.Errorf("%d models are pinned but max-models is %d", pinned, p.MaxModels)
+	}
+	if p.IdleTimeout > 0 && pinned == len(p.Models) {
+		return fmt.Errorf("idle-timeout has no effect when every model is pinned")
 	}
 
 	return nil
@@ -340,6 +594,28 @@ func validateModelEntry(m ModelEntry) error {
 	if err := validateMmproj(m.Mmproj); err != nil {
 		return err
 	}
+	if err := validateLora(m.Lora); err != nil {
+		return err
+	}
+	if m.TTL < 0 {
+		return fmt.Errorf("ttl for model '%s' must be positive", m.Name)
+	}
+	if m.Pin && m.TTL > 0 {
+		return fmt.Errorf("model '%s' cannot set both pin and ttl", m.Name)
+	}
+	if m.Pin || m.TTL > 0 {
+		for _, k := range []string{iniSleepIdleSeconds, iniLoadOnStartup} {
+			if _, ok := m.Options[k]; ok {
+				return fmt.Errorf("model '%s': options key '%s' conflicts with pin/ttl", m.Name, k)
+			}
+		}
+	}
+	if err := m.GPU.validate(m.Options); err != nil {
+		return fmt.Errorf("model '%s': %w", m.Name, err)
+	}
+	if err := m.Sampling.validate(m.Options); err != nil {
+		return fmt.Errorf("model '%s': %w", m.Name, err)
+	}
 
 	return validateOptions(m.Options, reservedModelEntryOptionsKeys)
 }
@@ -365,18 +641,75 @@ func validateMmproj(mmproj string) error {
 	return fmt.Errorf("invalid mmproj value: got %q; expected 'none', 'f:/path', or omit", mmproj)
 }
 
-// validateOptions checks that options keys are not reserved and do not contain newline characters.
+// validateLora validates LoRA adapter references.
+// Each entry must be an "f:" path or an "h:org/repo:file.gguf" repository file.
+func validateLora(loras []string) error {
+	for _, l := range loras {
+		if strings.ContainsAny(l, "\n\r") {
+			return fmt.Errorf("lora field must not contain newline characters")
+		}
+		if strings.Contains(l, ",") {
+			return fmt.Errorf("lora value must not contain commas: %q", l)
+		}
+		switch {
+		case strings.HasPrefix(l, "f:"):
+			if len(l) <= 2 {
+				return fmt.Errorf("lora 'f:' prefix requires a path")
+			}
+		case strings.HasPrefix(l, "h:"):
+			repo, file, ok := strings.Cut(l[2:], ":")
+			if !ok || repo == "" || !strings.HasSuffix(strings.ToLower(file), ".gguf") {
+				return fmt.Errorf("invalid lora value: got %q; expected 'h:org/repo:file.gguf'", l)
+			}
+		default:
+			return fmt.Errorf("invalid lora value: got %q; expected 'f:/path' or 'h:org/repo:file.gguf'", l)
+		}
+	}
+	return nil
+}
+
+// validateOptions checks that options keys are not reserved, are option
+// names that are safe as config.ini keys, and that neither keys nor values
+// contain newline characters.
 func validateOptions(opts Options, reserved []string) error {
-	for k, v := range opts {
+	for _, k := range slices.Sorted(maps.Keys(opts)) {
+		v := opts[k]
 		if strings.ContainsAny(k, "\n\r") {
 			return fmt.Errorf("options key must not contain newline characters")
 		}
 		if strings.ContainsAny(v, "\n\r") {
 			return fmt.Errorf("options value must not contain newline characters")
 		}
+		if k == "" || strings.ContainsAny(k, " \t=[]#;\"") {
+			return fmt.Errorf("options key %q must be an option name, without spaces, '=', brackets, '#', ';', or quotes", k)
+		}
 		if slices.Contains(reserved, k) {
 			return fmt.Errorf("options key %q is reserved and cannot be used in options; use the top-level %q field instead", k, k)
 		}
 	}
 	return nil
 }
+
+// CheckOptions calls check with each option key and value of the preset
+// and its router models, in key order, and returns the errors it reports.
+// Errors for router models are prefixed with the model name. Router keys
+// that only config.ini understands, like load-on-startup, are not checked.
+func (p *Preset) CheckOptions(check func(key, value string) error) []error {
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(p.Options)) {
+		if err := check(k, p.Options[k]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, m := range p.Models {
+		for _, k := range slices.Sorted(maps.Keys(m.Options)) {
+			if k == iniLoadOnStartup {
+				continue
+			}
+			if err := check(k, m.Options[k]); err != nil {
+				errs = append(errs, fmt.Errorf("model '%s': %w", m.Name, err))
+			}
+		}
+	}
+	return errs
+}
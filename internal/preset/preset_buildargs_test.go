@@ -46,6 +46,20 @@ func TestPreset_BuildArgs(t *testing.T) {
 				"--host", "127.0.0.1",
 			},
 		},
+		{
+			name: "with lora adapters",
+			preset: Preset{
+				Model: "/path/to/model.gguf",
+				Lora:  []string{"f:/path/to/a.gguf", "f:/path/to/b.gguf"},
+			},
+			want: []string{
+				"-m", "/path/to/model.gguf",
+				"--lora", "/path/to/a.gguf",
+				"--lora", "/path/to/b.gguf",
+				"--port", "8080",
+				"--host", "127.0.0.1",
+			},
+		},
 		{
 			name: "with boolean true option becomes flag",
 			preset: Preset{
@@ -158,6 +172,40 @@ func TestPreset_BuildArgs(t *testing.T) {
 				"--host", "127.0.0.1",
 			},
 		},
+		{
+			name: "with gpu fields before options",
+			preset: Preset{
+				Model:   "/path/to/model.gguf",
+				GPU:     GPU{Layers: "99", TensorSplit: []float64{0.75, 0.25}, MainGPU: intPtr(0)},
+				Options: Options{"ctx-size": "4096"},
+			},
+			want: []string{
+				"-m", "/path/to/model.gguf",
+				"--port", "8080",
+				"--host", "127.0.0.1",
+				"--n-gpu-layers", "99",
+				"--tensor-split", "0.75,0.25",
+				"--main-gpu", "0",
+				"--ctx-size", "4096",
+			},
+		},
+		{
+			name: "with prompt cache fields before options",
+			preset: Preset{
+				Model:        "/path/to/model.gguf",
+				SlotSavePath: "/cache/coder",
+				CacheReuse:   256,
+				Options:      Options{"ctx-size": "4096"},
+			},
+			want: []string{
+				"-m", "/path/to/model.gguf",
+				"--port", "8080",
+				"--host", "127.0.0.1",
+				"--slot-save-path", "/cache/coder",
+				"--cache-reuse", "256",
+				"--ctx-size", "4096",
+			},
+		},
 		{
 			name: "with mmproj none does not add flag",
 			preset: Preset{
@@ -303,6 +351,42 @@ func TestPreset_GenerateConfigINI(t *testing.T) {
 			},
 			want: "[llama]\nmodel = /path/to/llama.gguf\nctx-size = 2048\nthreads = 4\n\n[codellama]\nmodel = /path/to/codellama.gguf\ngpu-layers = 32\n",
 		},
+		{
+			name: "gpu fields in global and model sections",
+			preset: Preset{
+				Mode: "router",
+				GPU:  GPU{TensorSplit: []float64{1, 1}},
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/path/to/llama.gguf", GPU: GPU{Layers: "40", MainGPU: intPtr(1)}},
+				},
+			},
+			want: "[*]\ntensor-split = 1,1\n\n[llama]\nmodel = /path/to/llama.gguf\nn-gpu-layers = 40\nmain-gpu = 1\n",
+		},
+		{
+			name: "values quoted where INI parsing would change them",
+			preset: Preset{
+				Mode:    "router",
+				Options: Options{"chat-template": `{{ "x" }} # \ ;`},
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/My Models/llama 3.gguf", Lora: []string{"f:C:\\lora.gguf"}},
+					{Name: "qwen", Model: "f:C:\\My Models\\qwen.gguf", Lora: []string{"f:C:\\loras\\a.gguf"}},
+				},
+			},
+			want: "[*]\nchat-template = \"{{ \"x\" }} # \\ ;\"\n\n[llama]\nmodel = \"/My Models/llama 3.gguf\"\nlora = C:\\lora.gguf\n\n" +
+				"[qwen]\nmodel = \"C:\\My Models\\qwen.gguf\"\nlora = C:\\loras\\a.gguf\n",
+		},
+		{
+			name: "prompt cache fields in global section",
+			preset: Preset{
+				Mode:         "router",
+				SlotSavePath: "/cache/ws",
+				CacheReuse:   128,
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/path/to/llama.gguf"},
+				},
+			},
+			want: "[*]\nslot-save-path = /cache/ws\ncache-reuse = 128\n\n[llama]\nmodel = /path/to/llama.gguf\n",
+		},
 		{
 			name: "model with draft model",
 			preset: Preset{
@@ -366,6 +450,31 @@ func TestPreset_GenerateConfigINI(t *testing.T) {
 			},
 			want: "[no-vision]\nmodel = /path/to/model.gguf\n",
 		},
+		{
+			name: "model with lora adapters",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{
+						Name:  "tuned",
+						Model: "f:/path/to/model.gguf",
+						Lora:  []string{"f:/path/to/a.gguf", "f:/path/to/b.gguf"},
+					},
+				},
+			},
+			want: "[tuned]\nmodel = /path/to/model.gguf\nlora = /path/to/a.gguf,/path/to/b.gguf\n",
+		},
+		{
+			name: "pinned and ttl models",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{Name: "embed", Model: "f:/path/to/embed.gguf", Pin: true},
+					{Name: "chat", Model: "f:/path/to/chat.gguf", TTL: 600},
+				},
+			},
+			want: "[embed]\nmodel = /path/to/embed.gguf\nload-on-startup = true\nsleep-idle-seconds = -1\n\n[chat]\nmodel = /path/to/chat.gguf\nsleep-idle-seconds = 600\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -5,6 +5,10 @@ import (
 	"testing"
 )
 
+func intPtr(n int) *int { return &n }
+
+func floatPtr(f float64) *float64 { return &f }
+
 func TestPreset_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -162,6 +166,41 @@ func TestPreset_Validate(t *testing.T) {
 			},
 			wantErr: "options key must not contain newline characters",
 		},
+		{
+			name: "router mode with space in global options key",
+			preset: Preset{
+				Mode:    "router",
+				Options: Options{"ctx size": "4096"},
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/llama.gguf"},
+				},
+			},
+			wantErr: `options key "ctx size" must be an option name`,
+		},
+		{
+			name: "router mode with section brackets in model options key",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{
+						Name:    "llama",
+						Model:   "f:/llama.gguf",
+						Options: Options{"[other]": "x"},
+					},
+				},
+			},
+			wantErr: `options key "[other]" must be an option name`,
+		},
+		{
+			name: "router mode with model name unsafe as a section",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{Name: "llama]\n[x", Model: "f:/llama.gguf"},
+				},
+			},
+			wantErr: "invalid model name",
+		},
 		{
 			name: "router mode with newline in model options value",
 			preset: Preset{
@@ -446,6 +485,58 @@ func TestPreset_Validate(t *testing.T) {
 			},
 			wantErr: `options key "model-draft" is reserved`,
 		},
+		{
+			name: "single mode with valid lora adapters",
+			preset: Preset{
+				Model: "f:/path/to/model.gguf",
+				Lora:  []string{"f:/path/to/a.gguf", "h:org/repo:adapter.gguf"},
+			},
+		},
+		{
+			name: "single mode with lora missing prefix",
+			preset: Preset{
+				Model: "f:/path/to/model.gguf",
+				Lora:  []string{"/path/to/a.gguf"},
+			},
+			wantErr: "invalid lora value",
+		},
+		{
+			name: "single mode with lora quant instead of file",
+			preset: Preset{
+				Model: "f:/path/to/model.gguf",
+				Lora:  []string{"h:org/repo:Q4_K_M"},
+			},
+			wantErr: "expected 'h:org/repo:file.gguf'",
+		},
+		{
+			name: "single mode with reserved key lora in options",
+			preset: Preset{
+				Model:   "f:/path/to/model.gguf",
+				Options: Options{"lora": "/a.gguf"},
+			},
+			wantErr: `options key "lora" is reserved`,
+		},
+		{
+			name: "router mode with top-level lora",
+			preset: Preset{
+				Mode: "router",
+				Lora: []string{"f:/a.gguf"},
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/llama.gguf"},
+				},
+			},
+			wantErr: "router mode defines lora per model",
+		},
+		{
+			name: "router mode with invalid model lora",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{Name: "llama", Model: "f:/llama.gguf", Lora: []string{"f:/a.gguf,/b.gguf"}},
+				},
+			},
+			wantErr: "must not contain commas",
+		},
 		// model entry options allow models-max and sleep-idle-seconds (not reserved at model level)
 		{
 			name: "router mode model options allow non-reserved keys",
@@ -460,6 +551,246 @@ func TestPreset_Validate(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "router mode pin and ttl",
+			preset: Preset{
+				Mode:        "router",
+				IdleTimeout: 300,
+				Models: []ModelEntry{
+					{Name: "embed", Model: "f:/embed.gguf", Pin: true},
+					{Name: "chat", Model: "f:/chat.gguf", TTL: 60},
+				},
+			},
+		},
+		{
+			name: "router mode pin with ttl",
+			preset: Preset{
+				Mode:   "router",
+				Models: []ModelEntry{{Name: "embed", Model: "f:/embed.gguf", Pin: true, TTL: 60}},
+			},
+			wantErr: "cannot set both pin and ttl",
+		},
+		{
+			name: "router mode negative ttl",
+			preset: Preset{
+				Mode:   "router",
+				Models: []ModelEntry{{Name: "chat", Model: "f:/chat.gguf", TTL: -1}},
+			},
+			wantErr: "must be positive",
+		},
+		{
+			name: "router mode ttl conflicts with sleep-idle-seconds option",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{
+					{Name: "chat", Model: "f:/chat.gguf", TTL: 60, Options: Options{"sleep-idle-seconds": "30"}},
+				},
+			},
+			wantErr: "conflicts with pin/ttl",
+		},
+		{
+			name: "router mode more pinned models than max-models",
+			preset: Preset{
+				Mode:      "router",
+				MaxModels: 1,
+				Models: []ModelEntry{
+					{Name: "a", Model: "f:/a.gguf", Pin: true},
+					{Name: "b", Model: "f:/b.gguf", Pin: true},
+				},
+			},
+			wantErr: "2 models are pinned but max-models is 1",
+		},
+		{
+			name: "router mode idle-timeout with every model pinned",
+			preset: Preset{
+				Mode:        "router",
+				IdleTimeout: 300,
+				Models:      []ModelEntry{{Name: "a", Model: "f:/a.gguf", Pin: true}},
+			},
+			wantErr: "idle-timeout has no effect",
+		},
+		{
+			name: "negative startup-timeout",
+			preset: Preset{
+				Model:          "f:/a.gguf",
+				StartupTimeout: -1,
+			},
+			wantErr: "startup-timeout must not be negative",
+		},
+		{
+			name: "negative cache-reuse",
+			preset: Preset{
+				Model:      "f:/a.gguf",
+				CacheReuse: -1,
+			},
+			wantErr: "cache-reuse must not be negative",
+		},
+		{
+			name: "slot-save-path in options",
+			preset: Preset{
+				Model:   "f:/a.gguf",
+				Options: Options{"slot-save-path": "/tmp/slots"},
+			},
+			wantErr: `options key "slot-save-path" is reserved`,
+		},
+		{
+			name: "warmup-prompt without warmup",
+			preset: Preset{
+				Model:        "f:/a.gguf",
+				WarmupPrompt: "Hi",
+			},
+			wantErr: "warmup-prompt requires warmup: true",
+		},
+		{
+			name: "router mode warmup",
+			preset: Preset{
+				Mode:   "router",
+				Warmup: true,
+				Models: []ModelEntry{{Name: "a", Model: "f:/a.gguf"}},
+			},
+			wantErr: "warmup is only valid in single mode",
+		},
+		{
+			name: "valid gpu fields",
+			preset: Preset{
+				Model: "f:/a.gguf",
+				GPU:   GPU{Layers: "99", TensorSplit: []float64{3, 1}, MainGPU: intPtr(1)},
+			},
+		},
+		{
+			name:    "invalid gpu-layers",
+			preset:  Preset{Model: "f:/a.gguf", GPU: GPU{Layers: "most"}},
+			wantErr: "gpu-layers must be a non-negative number, 'all', or 'auto', got 'most'",
+		},
+		{
+			name:    "negative tensor-split",
+			preset:  Preset{Model: "f:/a.gguf", GPU: GPU{TensorSplit: []float64{1, -1}}},
+			wantErr: "tensor-split values must not be negative",
+		},
+		{
+			name:    "all-zero tensor-split",
+			preset:  Preset{Model: "f:/a.gguf", GPU: GPU{TensorSplit: []float64{0, 0}}},
+			wantErr: "tensor-split needs at least one value above 0",
+		},
+		{
+			name:    "main-gpu outside tensor-split",
+			preset:  Preset{Model: "f:/a.gguf", GPU: GPU{TensorSplit: []float64{1, 1}, MainGPU: intPtr(2)}},
+			wantErr: "main-gpu 2 is not in tensor-split, which covers GPUs 0-1",
+		},
+		{
+			name: "gpu field and option alias",
+			preset: Preset{
+				Model:   "f:/a.gguf",
+				GPU:     GPU{Layers: "20"},
+				Options: Options{"ngl": "10"},
+			},
+			wantErr: "options key 'ngl' conflicts with the gpu-layers field",
+		},
+		{
+			name:    "negative temp",
+			preset:  Preset{Model: "f:/a.gguf", Sampling: Sampling{Temp: floatPtr(-0.5)}},
+			wantErr: "temp must not be negative",
+		},
+		{
+			name:    "top-p above 1",
+			preset:  Preset{Model: "f:/a.gguf", Sampling: Sampling{TopP: floatPtr(1.5)}},
+			wantErr: "top-p must be between 0 and 1",
+		},
+		{
+			name:    "zero repeat-penalty",
+			preset:  Preset{Model: "f:/a.gguf", Sampling: Sampling{RepeatPenalty: floatPtr(0)}},
+			wantErr: "repeat-penalty must be above 0",
+		},
+		{
+			name: "sampling field and option",
+			preset: Preset{
+				Model:    "f:/a.gguf",
+				Sampling: Sampling{Temp: floatPtr(0.2)},
+				Options:  Options{"temp": "0.7"},
+			},
+			wantErr: "options key 'temp' conflicts with the temp field",
+		},
+		{
+			name:    "health-path without slash",
+			preset:  Preset{Model: "f:/a.gguf", Health: Health{Path: "ready"}},
+			wantErr: "health-path must be a path starting with '/'",
+		},
+		{
+			name:    "health-method POST",
+			preset:  Preset{Model: "f:/a.gguf", Health: Health{Method: "POST"}},
+			wantErr: "health-method must be GET or HEAD",
+		},
+		{
+			name:    "health-expect-status out of range",
+			preset:  Preset{Model: "f:/a.gguf", Health: Health{ExpectStatus: 42}},
+			wantErr: "health-expect-status must be an HTTP status",
+		},
+		{
+			name:    "health-expect-json with HEAD",
+			preset:  Preset{Model: "f:/a.gguf", Health: Health{Method: "HEAD", ExpectJSON: map[string]string{"status": "ok"}}},
+			wantErr: "health-expect-json needs a response body",
+		},
+		{
+			name:    "health-expect-json empty path segment",
+			preset:  Preset{Model: "f:/a.gguf", Health: Health{ExpectJSON: map[string]string{"a..b": "ok"}}},
+			wantErr: "must be a dotted path",
+		},
+		{
+			name:    "defaults in single mode",
+			preset:  Preset{Model: "f:/a.gguf", Defaults: Defaults{Mmproj: "none"}},
+			wantErr: "defaults is only valid in router mode",
+		},
+		{
+			name: "defaults invalid mmproj",
+			preset: Preset{
+				Mode:     "router",
+				Defaults: Defaults{Mmproj: "auto"},
+				Models:   []ModelEntry{{Name: "a", Model: "f:/a.gguf"}},
+			},
+			wantErr: "defaults: invalid mmproj value",
+		},
+		{
+			name: "defaults option also top-level",
+			preset: Preset{
+				Mode:     "router",
+				Options:  Options{"ctx-size": "4096"},
+				Defaults: Defaults{Options: Options{"ctx-size": "8192"}},
+				Models:   []ModelEntry{{Name: "a", Model: "f:/a.gguf"}},
+			},
+			wantErr: "options key 'ctx-size' is set both in options and in defaults",
+		},
+		{
+			name: "defaults option conflicts with sampling field",
+			preset: Preset{
+				Mode:     "router",
+				Sampling: Sampling{Temp: floatPtr(0.2)},
+				Defaults: Defaults{Options: Options{"temp": "0.7"}},
+				Models:   []ModelEntry{{Name: "a", Model: "f:/a.gguf"}},
+			},
+			wantErr: "options key 'temp' conflicts with the temp field",
+		},
+		{
+			name: "router top-level gpu-layers auto",
+			preset: Preset{
+				Mode:   "router",
+				GPU:    GPU{Layers: "auto"},
+				Models: []ModelEntry{{Name: "a", Model: "f:/a.gguf"}},
+			},
+			wantErr: "gpu-layers: auto must be set per model in router mode",
+		},
+		{
+			name: "router model gpu conflict",
+			preset: Preset{
+				Mode: "router",
+				Models: []ModelEntry{{
+					Name:    "a",
+					Model:   "f:/a.gguf",
+					GPU:     GPU{MainGPU: intPtr(0)},
+					Options: Options{"mg": "1"},
+				}},
+			},
+			wantErr: "model 'a': options key 'mg' conflicts with the main-gpu field",
+		},
 	}
 
 	for _, tt := range tests {
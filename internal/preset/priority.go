@@ -0,0 +1,98 @@
+package preset
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// IO priorities llama-server can be given.
+const (
+	IOPriorityIdle   = "idle"   // disk time only when no other process wants it
+	IOPriorityLow    = "low"    // lowest best-effort level
+	IOPriorityNormal = "normal" // the default best-effort level
+)
+
+// maxCPU bounds the core numbers cpu-affinity takes.
+const maxCPU = 1023
+
+// Priority lowers the share of CPU and disk time llama-server gets, so a
+// model server in the background leaves room for interactive work. The
+// daemon applies it to llama-server, and in router mode to the servers the
+// router starts, which inherit it.
+type Priority struct {
+	// Nice is llama-server's niceness, from -20 (most CPU time) to 19
+	// (least). Zero leaves it as the daemon's; below zero needs privileges.
+	Nice int `yaml:"nice,omitempty"`
+
+	// CPUAffinity lists the cores llama-server may run on, e.g. "0-3,8".
+	CPUAffinity string `yaml:"cpu-affinity,omitempty"`
+
+	// IOPriority is llama-server's disk priority: IOPriorityIdle,
+	// IOPriorityLow, or IOPriorityNormal.
+	IOPriority string `yaml:"io-priority,omitempty"`
+}
+
+// IsZero reports whether no priority field is set.
+func (pr Priority) IsZero() bool {
+	return pr == Priority{}
+}
+
+// String describes the set fields for display, e.g.
+// "nice=10 cpu-affinity=0-3 io-priority=idle".
+func (pr Priority) String() string {
+	var parts []string
+	if pr.Nice != 0 {
+		parts = append(parts, "nice="+strconv.Itoa(pr.Nice))
+	}
+	if pr.CPUAffinity != "" {
+		parts = append(parts, "cpu-affinity="+pr.CPUAffinity)
+	}
+	if pr.IOPriority != "" {
+		parts = append(parts, "io-priority="+pr.IOPriority)
+	}
+	return strings.Join(parts, " ")
+}
+
+// CPUs returns the cores CPUAffinity lists, in ascending order.
+func (pr Priority) CPUs() ([]int, error) {
+	if pr.CPUAffinity == "" {
+		return nil, nil
+	}
+	var cpus []int
+	for part := range strings.SplitSeq(pr.CPUAffinity, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		lo, err := strconv.Atoi(first)
+		if err != nil || lo < 0 || lo > maxCPU {
+			return nil, fmt.Errorf("cpu-affinity must list cores from 0 to %d, e.g. \"0-3,8\", got '%s'", maxCPU, pr.CPUAffinity)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(last); err != nil || hi < lo || hi > maxCPU {
+				return nil, fmt.Errorf("cpu-affinity must list cores from 0 to %d, e.g. \"0-3,8\", got '%s'", maxCPU, pr.CPUAffinity)
+			}
+		}
+		for cpu := lo; cpu <= hi; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	slices.Sort(cpus)
+	return slices.Compact(cpus), nil
+}
+
+// validate checks the field values.
+func (pr Priority) validate() error {
+	if pr.Nice < -20 || pr.Nice > 19 {
+		return fmt.Errorf("nice must be from -20 to 19, got %d", pr.Nice)
+	}
+	if _, err := pr.CPUs(); err != nil {
+		return err
+	}
+	switch pr.IOPriority {
+	case "", IOPriorityIdle, IOPriorityLow, IOPriorityNormal:
+	default:
+		return fmt.Errorf("io-priority must be '%s', '%s', or '%s', got '%s'", IOPriorityIdle, IOPriorityLow, IOPriorityNormal, pr.IOPriority)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package preset
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPriority_YAML(t *testing.T) {
+	// Arrange
+	input := "name: bg\nmodel: f:/m.gguf\nnice: 10\ncpu-affinity: 0-3,8\nio-priority: idle\n"
+
+	// Act
+	var p Preset
+	err := yaml.Unmarshal([]byte(input), &p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := Priority{Nice: 10, CPUAffinity: "0-3,8", IOPriority: IOPriorityIdle}
+	if p.Priority != want {
+		t.Errorf("Priority = %+v, want %+v", p.Priority, want)
+	}
+	if got := p.Priority.String(); got != "nice=10 cpu-affinity=0-3,8 io-priority=idle" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestPriority_CPUs(t *testing.T) {
+	tests := []struct {
+		affinity string
+		want     []int
+		wantErr  bool
+	}{
+		{affinity: "", want: nil},
+		{affinity: "2", want: []int{2}},
+		{affinity: "8, 0-3, 2", want: []int{0, 1, 2, 3, 8}},
+		{affinity: "3-1", wantErr: true},
+		{affinity: "0-", wantErr: true},
+		{affinity: "a", wantErr: true},
+		{affinity: "1024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.affinity, func(t *testing.T) {
+			// Act
+			got, err := Priority{CPUAffinity: tt.affinity}.CPUs()
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CPUs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CPUs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriority_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		priority Priority
+		wantErr  string
+	}{
+		{name: "valid", priority: Priority{Nice: 19, CPUAffinity: "0", IOPriority: IOPriorityLow}},
+		{name: "nice too low", priority: Priority{Nice: -21}, wantErr: "nice must be from -20 to 19"},
+		{name: "nice too high", priority: Priority{Nice: 20}, wantErr: "nice must be from -20 to 19"},
+		{name: "bad cpu list", priority: Priority{CPUAffinity: "0,x"}, wantErr: "cpu-affinity must list cores"},
+		{name: "unknown io priority", priority: Priority{IOPriority: "high"}, wantErr: "io-priority must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			p := &Preset{Name: "bg", Model: "f:/m.gguf", Priority: tt.priority}
+
+			// Act
+			err := p.Validate()
+
+			// Assert
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package preset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Sampling holds the default behavior of a preset or router model: a
+// system prompt and sampling parameters. Requests that set a parameter
+// themselves override it.
+type Sampling struct {
+	// SystemPrompt is added by the daemon's proxy as the first message of
+	// chat completion requests that have no system message.
+	SystemPrompt string `yaml:"system-prompt,omitempty"`
+
+	// Temp is the sampling temperature (--temp).
+	Temp *float64 `yaml:"temp,omitempty"`
+
+	// TopP is the nucleus sampling threshold (--top-p).
+	TopP *float64 `yaml:"top-p,omitempty"`
+
+	// RepeatPenalty penalizes repeated tokens (--repeat-penalty); 1 is off.
+	RepeatPenalty *float64 `yaml:"repeat-penalty,omitempty"`
+}
+
+// IsZero reports whether no sampling field is set.
+func (s Sampling) IsZero() bool {
+	return s.SystemPrompt == "" && s.Temp == nil && s.TopP == nil && s.RepeatPenalty == nil
+}
+
+// options returns the set sampling parameters as llama-server options, in
+// argument order. The preset fields are named after the options.
+func (s Sampling) options() []serverOption {
+	var opts []serverOption
+	for _, f := range []struct {
+		key   string
+		value *float64
+	}{
+		{"temp", s.Temp},
+		{"top-p", s.TopP},
+		{"repeat-penalty", s.RepeatPenalty},
+	} {
+		if f.value != nil {
+			opts = append(opts, serverOption{f.key, strconv.FormatFloat(*f.value, 'g', -1, 64)})
+		}
+	}
+	return opts
+}
+
+// String describes the set sampling parameters for display, e.g.
+// "temp=0.2 top-p=0.9". The system prompt is shown on its own.
+func (s Sampling) String() string {
+	var parts []string
+	for _, o := range s.options() {
+		parts = append(parts, o.key+"="+o.value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// validate checks the parameter ranges and that opts does not set the same
+// llama-server options.
+func (s Sampling) validate(opts Options) error {
+	if s.Temp != nil && *s.Temp < 0 {
+		return fmt.Errorf("temp must not be negative")
+	}
+	if s.TopP != nil && (*s.TopP < 0 || *s.TopP > 1) {
+		return fmt.Errorf("top-p must be between 0 and 1")
+	}
+	if s.RepeatPenalty != nil && *s.RepeatPenalty <= 0 {
+		return fmt.Errorf("repeat-penalty must be above 0")
+	}
+	for _, o := range s.options() {
+		if _, ok := opts[o.key]; ok {
+			return fmt.Errorf("options key '%s' conflicts with the %s field; set one or the other", o.key, o.key)
+		}
+	}
+	return nil
+}
+
+// SystemPromptFor returns the system prompt for requests to model, a
+// router model name: the model's own, or the preset's. model is ignored
+// in single mode.
+func (p *Preset) SystemPromptFor(model string) string {
+	for _, m := range p.Models {
+		if m.Name == model && m.Sampling.SystemPrompt != "" {
+			return m.Sampling.SystemPrompt
+		}
+	}
+	return p.Sampling.SystemPrompt
+}
@@ -0,0 +1,104 @@
+package preset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSampling_YAML(t *testing.T) {
+	// Arrange
+	input := "name: coder\nmodel: f:/m.gguf\nsystem-prompt: |\n  You are a coding assistant.\n  Answer with code.\ntemp: 0.2\ntop-p: 0.9\nrepeat-penalty: 1.1\n"
+
+	// Act
+	var p Preset
+	err := yaml.Unmarshal([]byte(input), &p)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("yaml error = %v", err)
+	}
+	want := Sampling{
+		SystemPrompt:  "You are a coding assistant.\nAnswer with code.\n",
+		Temp:          floatPtr(0.2),
+		TopP:          floatPtr(0.9),
+		RepeatPenalty: floatPtr(1.1),
+	}
+	if !reflect.DeepEqual(p.Sampling, want) {
+		t.Errorf("Sampling = %+v, want %+v", p.Sampling, want)
+	}
+	if got := p.Sampling.String(); got != "temp=0.2 top-p=0.9 repeat-penalty=1.1" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSampling_BuildArgs(t *testing.T) {
+	// Arrange
+	p := &Preset{
+		Name:     "coder",
+		Model:    "f:/m.gguf",
+		Sampling: Sampling{SystemPrompt: "Be brief.", Temp: floatPtr(0), TopP: floatPtr(0.95)},
+	}
+
+	// Act
+	args := strings.Join(p.BuildArgs(), " ")
+
+	// Assert
+	if !strings.Contains(args, "--temp 0 --top-p 0.95") {
+		t.Errorf("BuildArgs() = %s, want --temp 0 --top-p 0.95", args)
+	}
+	if strings.Contains(args, "Be brief.") {
+		t.Errorf("BuildArgs() = %s, want no system prompt", args)
+	}
+}
+
+func TestSampling_GenerateConfigINI(t *testing.T) {
+	// Arrange
+	p := &Preset{
+		Name:     "workspace",
+		Mode:     "router",
+		Sampling: Sampling{Temp: floatPtr(0.7)},
+		Models: []ModelEntry{
+			{Name: "coder", Model: "f:/coder.gguf", Sampling: Sampling{Temp: floatPtr(0.2), RepeatPenalty: floatPtr(1.1)}},
+		},
+	}
+
+	// Act
+	got := p.GenerateConfigINI()
+
+	// Assert
+	want := "[*]\ntemp = 0.7\n\n[coder]\nmodel = /coder.gguf\ntemp = 0.2\nrepeat-penalty = 1.1\n"
+	if got != want {
+		t.Errorf("GenerateConfigINI() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPreset_SystemPromptFor(t *testing.T) {
+	p := &Preset{
+		Mode:     "router",
+		Sampling: Sampling{SystemPrompt: "default"},
+		Models: []ModelEntry{
+			{Name: "coder", Sampling: Sampling{SystemPrompt: "code"}},
+			{Name: "chat"},
+		},
+	}
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "coder", want: "code"},
+		{model: "chat", want: "default"},
+		{model: "", want: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := p.SystemPromptFor(tt.model); got != tt.want {
+				t.Errorf("SystemPromptFor(%q) = %q, want %q", tt.model, got, tt.want)
+			}
+		})
+	}
+}
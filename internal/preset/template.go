@@ -0,0 +1,121 @@
+package preset
+
+import (
+	"bytes"
+	"slices"
+	"text/template"
+)
+
+// TemplateOptions selects what a starter preset written by Template holds.
+type TemplateOptions struct {
+	Name      string
+	Router    bool
+	Vision    bool // note how mmproj is picked up and disabled
+	Embedding bool // serve embeddings
+
+	// Models fills in the model, or in router mode the models, in order;
+	// in router mode, their options are written too, and a model without
+	// options gets a context size. Others lists more models in comments,
+	// for the user to pick from.
+	Models []ModelEntry
+	Others []string
+}
+
+var starterTemplate = template.Must(template.New("preset").Parse(`# Starter preset written by alpaca preset init.
+# Uncomment and edit what you need; docs/design/preset-format.md lists every field.
+name: {{.Name}}
+{{- if .Router}}
+mode: router
+
+# port: 8080
+# host: 127.0.0.1
+# max-models: 2           # models loaded at the same time
+# idle-timeout: 600       # seconds idle before a model is unloaded
+
+# llama-server options for every model ([*] section in config.ini)
+# options:
+#   flash-attn: on
+#   cache-type-k: q8_0
+
+# Models: h:org/repo:quant (downloaded with alpaca pull) or f:/path/to/model.gguf
+models:
+{{- range $i, $m := .Models}}
+  - name: {{$m.Name}}
+    model: {{printf "%q" $m.Model}}
+{{- if and $.Vision (eq $i 0)}}
+    # mmproj: none          # the mmproj downloaded with the model is used; none disables vision
+{{- end}}
+{{- if index $m.Options "embeddings"}}
+    # pin: true             # keep loaded
+{{- else}}
+    # ttl: 300              # seconds idle before this model is unloaded
+{{- end}}
+    options:
+{{- range $k, $v := $m.Options}}
+      {{$k}}: {{$v}}
+{{- end}}
+{{- if index $m.Options "embeddings"}}
+      # pooling: mean       # depends on the model; see its model card
+{{- end}}
+{{- end}}
+{{- else}}
+
+# Model: h:org/repo:quant (downloaded with alpaca pull) or f:/path/to/model.gguf
+model: {{printf "%q" (index .Models 0).Model}}
+{{- if .Vision}}
+
+# Multimodal projector: omitted, the one downloaded with the model is used.
+# "none" disables vision; "f:/path/to/mmproj.gguf" picks a file.
+# mmproj: none
+{{- else if not .Embedding}}
+
+# draft-model: "h:org/repo:quant"     # speculative decoding
+# lora:
+#   - "h:org/repo:adapter.gguf"
+{{- end}}
+
+# port: 8080
+# host: 127.0.0.1
+# gpu-layers: all
+# watch: true             # restart llama-server when this file is saved
+{{- if not .Embedding}}
+# warmup: true            # ready only after a one-token completion succeeds
+{{- end}}
+
+# llama-server options: long option names without the -- prefix
+options:
+{{- if .Embedding}}
+  embeddings: true
+  # pooling: mean         # depends on the model; see its model card
+  # ubatch-size: 2048     # must hold the longest input
+{{- else}}
+  ctx-size: 8192
+  # flash-attn: on
+  # threads: 8
+{{- end}}
+{{- end}}
+{{- if .Others}}
+
+# Other downloaded models:
+{{- range .Others}}
+#   {{.}}
+{{- end}}
+{{- end}}
+`))
+
+// Template returns a commented starter preset. opts.Models must not be
+// empty.
+func Template(opts TemplateOptions) []byte {
+	opts.Models = slices.Clone(opts.Models)
+	for i, m := range opts.Models {
+		if len(m.Options) == 0 {
+			opts.Models[i].Options = Options{"ctx-size": "8192"}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := starterTemplate.Execute(&buf, opts); err != nil {
+		panic(err) // the template is fixed and its data cannot fail it
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,74 @@
+package preset
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  TemplateOptions
+		check func(t *testing.T, p *Preset)
+	}{
+		{
+			name: "single",
+			opts: TemplateOptions{Name: "gemma", Models: []ModelEntry{{Model: "h:org/gemma-GGUF:Q4_K_M"}}, Others: []string{"h:org/other-GGUF:Q8_0"}},
+			check: func(t *testing.T, p *Preset) {
+				if p.Model != "h:org/gemma-GGUF:Q4_K_M" || p.Options["ctx-size"] != "8192" || p.Mmproj != "" {
+					t.Errorf("preset = %+v", p)
+				}
+			},
+		},
+		{
+			name: "single embedding",
+			opts: TemplateOptions{Name: "embed", Embedding: true, Models: []ModelEntry{{Model: "h:org/embed-GGUF:Q8_0"}}},
+			check: func(t *testing.T, p *Preset) {
+				if p.Options["embeddings"] != "true" {
+					t.Errorf("options = %v, want embeddings", p.Options)
+				}
+			},
+		},
+		{
+			name: "router",
+			opts: TemplateOptions{Name: "workspace", Router: true, Vision: true, Models: []ModelEntry{
+				{Name: "chat", Model: "h:org/chat-GGUF:Q4_K_M"},
+				{Name: "embed", Model: "h:org/embed-GGUF:Q8_0", Options: Options{"embeddings": "true"}},
+			}},
+			check: func(t *testing.T, p *Preset) {
+				if !p.IsRouter() || len(p.Models) != 2 || len(p.Options) != 0 {
+					t.Fatalf("preset = %+v", p)
+				}
+				if p.Models[0].Options["ctx-size"] != "8192" || p.Models[1].Options["embeddings"] != "true" {
+					t.Errorf("models = %+v", p.Models)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			loader := NewLoader(t.TempDir())
+
+			// Act
+			data := Template(tt.opts)
+			path, err := loader.CreateYAML(data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("CreateYAML() error = %v\n%s", err, data)
+			}
+			written, err := os.ReadFile(path)
+			if err != nil || !strings.HasPrefix(string(written), "# Starter preset") {
+				t.Errorf("written = %q, %v, want the template with its comments", written, err)
+			}
+			p, err := loader.Load(tt.opts.Name)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, p)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package protocol
+
+import "time"
+
+// This file defines the data of each command's successful response. Field
+// names are the wire format; the GUI and pkg/alpaca decode them too, so
+// fields may be added but not renamed.
+
+// HelloData answers CmdHello.
+type HelloData struct {
+	Version int `json:"version"`
+}
+
+// StatusData answers CmdStatus. Fields about the model are empty while the
+// daemon is idle.
+type StatusData struct {
+	State  string       `json:"state"`
+	Since  time.Time    `json:"since"`            // when State was entered, to the second
+	Detail string       `json:"detail,omitempty"` // what llama-server reports while loading, e.g. "loading model 43%"
+	Daemon *ProcessData `json:"daemon,omitempty"`
+
+	Preset      string       `json:"preset,omitempty"` // preset name, or the identifier of a model loaded directly
+	Endpoint    string       `json:"endpoint,omitempty"`
+	Files       []string     `json:"files,omitempty"`   // model files in use
+	Command     []string     `json:"command,omitempty"` // llama-server command line
+	ConfigINI   string       `json:"config_ini,omitempty"`
+	LlamaServer *ProcessData `json:"llama_server,omitempty"`
+	Mmproj      string       `json:"mmproj,omitempty"`
+	Mode        string       `json:"mode,omitempty"` // "router" for router presets
+
+	// Models lists a router preset's models: empty when llama-server
+	// reports none, nil when they could not be read (see ModelsError) or
+	// the preset is not a router.
+	Models      []RouterModelData `json:"models,omitzero"`
+	ModelsError string            `json:"models_error,omitempty"`
+
+	Metrics *MetricsData `json:"metrics,omitempty"`
+}
+
+// ProcessData describes the daemon or llama-server process. RSS and
+// CPUSeconds are nil when they could not be read.
+type ProcessData struct {
+	PID        int       `json:"pid"`
+	Version    string    `json:"version,omitempty"`   // daemon only
+	StartedAt  time.Time `json:"started_at,omitzero"` // daemon only
+	RSS        *int64    `json:"rss,omitempty"`       // resident memory in bytes
+	CPUSeconds *float64  `json:"cpu_seconds,omitempty"`
+}
+
+// RouterModelData is a model of a router preset.
+type RouterModelData struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // as reported by llama-server
+	Mmproj      string `json:"mmproj,omitempty"`
+	ActiveSlots *int   `json:"active_slots,omitempty"` // slots processing a request; nil if not reported
+	Queued      *int   `json:"queued,omitempty"`       // requests waiting for a slot; nil if not reported
+}
+
+// MetricsData are llama-server's performance counters. Values llama-server
+// does not report are nil.
+type MetricsData struct {
+	KVCacheUsage          *float64 `json:"kv_cache_usage,omitempty"` // fraction in use
+	SlotsBusy             *int     `json:"slots_busy,omitempty"`
+	SlotsTotal            *int     `json:"slots_total,omitempty"`
+	PromptTokensPerSec    *float64 `json:"prompt_tokens_per_sec,omitempty"`
+	PredictedTokensPerSec *float64 `json:"predicted_tokens_per_sec,omitempty"`
+}
+
+// LoadData answers CmdLoad: the endpoint of the loaded model, or with
+// dry_run the command that would run.
+type LoadData struct {
+	Endpoint  string   `json:"endpoint,omitempty"`
+	Command   []string `json:"command,omitempty"` // dry run only
+	ConfigINI string   `json:"config_ini,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"` // llama-server options that look wrong
+
+	// Models lists the models llama-server registered for a router preset,
+	// as StatusData does. MissingModels are the preset's models it did not
+	// register, e.g. because it could not parse their config.ini section.
+	Models        []RouterModelData `json:"models,omitzero"`
+	ModelsError   string            `json:"models_error,omitempty"`
+	MissingModels []string          `json:"missing_models,omitempty"`
+}
+
+// Load phases, in the order a load goes through them.
+const (
+	LoadPhaseResolving   = "resolving"    // reading the preset and resolving model files
+	LoadPhaseStarting    = "starting"     // running pre-load hooks and starting llama-server
+	LoadPhaseHealthCheck = "health_check" // waiting for llama-server to report ready
+	LoadPhaseWarmup      = "warmup"       // sending the warm-up prompt
+)
+
+// LoadProgressData is the data of a StatusProgress response to CmdLoad:
+// the phase the load entered, or what llama-server reports during the
+// health check.
+type LoadProgressData struct {
+	Phase  string `json:"phase"`
+	Detail string `json:"detail,omitempty"` // e.g. "loading model 43%"
+}
+
+// LoadFailureData is the data of a CmdLoad error with ErrCodeServerFailed
+// when llama-server ran but did not become ready: what the daemon saw at
+// the time, to tell why without reading the logs.
+type LoadFailureData struct {
+	Command    []string `json:"command,omitempty"`     // llama-server and its arguments
+	ExitCode   *int     `json:"exit_code,omitempty"`   // nil when llama-server did not exit on its own
+	LogTail    []string `json:"log_tail,omitempty"`    // last lines llama-server wrote to llama.log
+	Addr       string   `json:"addr,omitempty"`        // host:port llama-server was to listen on
+	PortInUse  bool     `json:"port_in_use"`           // another process holds Addr
+	FreeMemory int64    `json:"free_memory,omitempty"` // bytes available to a new process; 0 if unknown
+}
+
+// UnloadData answers CmdUnload.
+type UnloadData struct {
+	Graceful bool `json:"graceful"` // false when llama-server had to be killed
+}
+
+// CancelData answers CmdCancel.
+type CancelData struct {
+	Canceled bool `json:"canceled"` // false when no load was in progress
+}
+
+// ListPresetsData answers CmdListPresets.
+type ListPresetsData struct {
+	Presets []string `json:"presets"`
+	Warning string   `json:"warning,omitempty"` // presets that could not be read
+}
+
+// ListModelsData answers CmdListModels.
+type ListModelsData struct {
+	Models []ModelData `json:"models"`
+}
+
+// ModelData is a downloaded model.
+type ModelData struct {
+	Repo  string `json:"repo"`
+	Quant string `json:"quant"`
+	Size  int64  `json:"size"` // bytes
+}
+
+// CompleteData answers CmdComplete. Lists are sorted.
+type CompleteData struct {
+	Presets      []string            `json:"presets"`       // preset names, without p:
+	Models       []string            `json:"models"`        // downloaded models as identifiers, e.g. h:org/repo:Q4_K_M
+	Aliases      []string            `json:"aliases"`       // model names presets give llama-server with the alias option
+	RouterModels map[string][]string `json:"router_models"` // router preset name to its model names
+	Warning      string              `json:"warning,omitempty"`
+}
+
+// StatsData answers CmdStats.
+type StatsData struct {
+	Models []ModelStatsData `json:"models"`
+}
+
+// PsData answers CmdPs.
+type PsData struct {
+	Processes []LlamaProcessData `json:"processes"`
+}
+
+// LlamaProcessData is a llama-server process run by the daemon.
+type LlamaProcessData struct {
+	PID       int       `json:"pid"`
+	Preset    string    `json:"preset"`
+	State     string    `json:"state"` // daemon state, "loading" or "running"
+	StartedAt time.Time `json:"started_at,omitzero"`
+	RSS       *int64    `json:"rss,omitempty"`   // resident memory in bytes
+	Port      int       `json:"port,omitempty"`  // port llama-server listens on
+	Model     string    `json:"model,omitempty"` // model path; empty for a router preset
+	Mode      string    `json:"mode,omitempty"`  // "router" for router presets
+}
+
+// ModelStatsData are the request statistics of one model.
+type ModelStatsData struct {
+	Model            string `json:"model"`
+	Requests         int64  `json:"requests"`
+	Errors           int64  `json:"errors"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	AvgLatencyMS     int64  `json:"avg_latency_ms"`
+}
@@ -0,0 +1,107 @@
+package protocol
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// The GUI and pkg/alpaca read these payloads, so their JSON must not change
+// by accident.
+func TestPayload_WireFormat(t *testing.T) {
+	rss, cpu := int64(1024), 1.5
+	busy, usage, exitCode, queued := 1, 0.25, 1, 3
+	since := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		data any
+		want string
+	}{
+		{
+			name: "idle status",
+			data: StatusData{State: "idle", Since: since, Daemon: &ProcessData{PID: 1, Version: "1.0.0", StartedAt: since}},
+			want: `{"state":"idle","since":"2026-10-16T09:00:00Z","daemon":{"pid":1,"version":"1.0.0","started_at":"2026-10-16T09:00:00Z"}}`,
+		},
+		{
+			name: "router status",
+			data: StatusData{
+				State:       "running",
+				Since:       since,
+				Preset:      "multi",
+				Endpoint:    "http://127.0.0.1:8080",
+				LlamaServer: &ProcessData{PID: 2, RSS: &rss, CPUSeconds: &cpu},
+				Mode:        "router",
+				Models:      []RouterModelData{{ID: "qwen", Status: "loaded", Mmproj: "/m.gguf"}},
+				Metrics:     &MetricsData{SlotsBusy: &busy, KVCacheUsage: &usage},
+			},
+			want: `{"state":"running","since":"2026-10-16T09:00:00Z","preset":"multi","endpoint":"http://127.0.0.1:8080",` +
+				`"llama_server":{"pid":2,"rss":1024,"cpu_seconds":1.5},"mode":"router",` +
+				`"models":[{"id":"qwen","status":"loaded","mmproj":"/m.gguf"}],"metrics":{"kv_cache_usage":0.25,"slots_busy":1}}`,
+		},
+		{
+			name: "router status without models",
+			data: StatusData{State: "running", Since: since, Mode: "router", Models: []RouterModelData{}},
+			want: `{"state":"running","since":"2026-10-16T09:00:00Z","mode":"router","models":[]}`,
+		},
+		{
+			name: "router status with models error",
+			data: StatusData{State: "running", Since: since, Mode: "router", ModelsError: "503"},
+			want: `{"state":"running","since":"2026-10-16T09:00:00Z","mode":"router","models_error":"503"}`,
+		},
+		{
+			name: "dry-run load",
+			data: LoadData{Command: []string{"llama-server", "-m", "m.gguf"}, Warnings: []string{"unknown option"}},
+			want: `{"command":["llama-server","-m","m.gguf"],"warnings":["unknown option"]}`,
+		},
+		{
+			name: "router load",
+			data: LoadData{Endpoint: "http://127.0.0.1:8080", Models: []RouterModelData{{ID: "qwen", Status: "loaded", ActiveSlots: &busy, Queued: &queued}}, MissingModels: []string{"gemma"}},
+			want: `{"endpoint":"http://127.0.0.1:8080","models":[{"id":"qwen","status":"loaded","active_slots":1,"queued":3}],"missing_models":["gemma"]}`,
+		},
+		{
+			name: "load progress",
+			data: LoadProgressData{Phase: LoadPhaseHealthCheck, Detail: "loading model 43%"},
+			want: `{"phase":"health_check","detail":"loading model 43%"}`,
+		},
+		{
+			name: "load failure",
+			data: LoadFailureData{Command: []string{"llama-server", "--port", "8080"}, ExitCode: &exitCode, LogTail: []string{"error: couldn't bind"}, Addr: "127.0.0.1:8080", PortInUse: true},
+			want: `{"command":["llama-server","--port","8080"],"exit_code":1,"log_tail":["error: couldn't bind"],"addr":"127.0.0.1:8080","port_in_use":true}`,
+		},
+		{
+			name: "models",
+			data: ListModelsData{Models: []ModelData{{Repo: "org/repo", Quant: "Q4_K_M", Size: 42}}},
+			want: `{"models":[{"repo":"org/repo","quant":"Q4_K_M","size":42}]}`,
+		},
+		{
+			name: "complete",
+			data: CompleteData{Presets: []string{"fleet"}, Models: []string{"h:org/repo:Q4_K_M"}, Aliases: []string{}, RouterModels: map[string][]string{"fleet": {"qwen"}}},
+			want: `{"presets":["fleet"],"models":["h:org/repo:Q4_K_M"],"aliases":[],"router_models":{"fleet":["qwen"]}}`,
+		},
+		{
+			name: "ps",
+			data: PsData{Processes: []LlamaProcessData{{PID: 4242, Preset: "qwen", State: "running", StartedAt: since, Port: 8080, Model: "/models/qwen.gguf"}}},
+			want: `{"processes":[{"pid":4242,"preset":"qwen","state":"running","started_at":"2026-10-16T09:00:00Z","port":8080,"model":"/models/qwen.gguf"}]}`,
+		},
+		{
+			name: "stats",
+			data: StatsData{Models: []ModelStatsData{{Model: "qwen", Requests: 2, AvgLatencyMS: 150}}},
+			want: `{"models":[{"model":"qwen","requests":2,"errors":0,"prompt_tokens":0,"completion_tokens":0,"avg_latency_ms":150}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := json.Marshal(tt.data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("JSON =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,46 @@
 // Package protocol defines the JSON protocol for daemon communication.
 package protocol
 
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Version is the protocol version spoken by this build. It is bumped
+// whenever a command or a response field the CLI relies on is added or
+// changed, so a CLI and a daemon from different builds notice they do not
+// fully understand each other. Builds before versioning send no version (0).
+const Version = 3
+
 // Request represents a command request to the daemon.
 type Request struct {
 	Command string         `json:"command"`
 	Args    map[string]any `json:"args,omitempty"`
+	Token   string         `json:"token,omitempty"` // required on remote (TCP) connections
+	Version int            `json:"version,omitempty"`
 }
 
 // Response represents a response from the daemon.
 type Response struct {
-	Status    string         `json:"status"` // "ok" or "error"
-	Data      map[string]any `json:"data,omitempty"`
-	Error     string         `json:"error,omitempty"`
-	ErrorCode string         `json:"error_code,omitempty"`
+	Status    string          `json:"status"`         // "ok" or "error"
+	Data      json.RawMessage `json:"data,omitempty"` // the command's payload, e.g. StatusData; see Decode
+	Error     string          `json:"error,omitempty"`
+	ErrorCode string          `json:"error_code,omitempty"`
+	Version   int             `json:"version,omitempty"`
+}
+
+// Decode unmarshals the response's data into out, one of the payload
+// types. A response without data leaves out unchanged.
+func (r *Response) Decode(out any) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, out); err != nil {
+		return fmt.Errorf("decode response data: %w", err)
+	}
+	return nil
 }
 
 // Command names
@@ -22,12 +50,33 @@ const (
 	CmdUnload      = "unload"
 	CmdListPresets = "list_presets"
 	CmdListModels  = "list_models"
+	CmdStats       = "stats"
+	CmdPs          = "ps"
+
+	// CmdComplete returns everything an identifier can be completed to in
+	// one response, for shell completion and the TUI. It does not wait for
+	// a load in progress.
+	CmdComplete = "complete"
+
+	// CmdCancel aborts the load in progress. Sent as a request of its own
+	// it cancels whichever load is running; sent on the connection of a
+	// request still in progress it aborts that request, and the daemon then
+	// answers the original request.
+	CmdCancel = "cancel"
+
+	// CmdHello returns the daemon's protocol version. It is answered
+	// whatever version the client speaks.
+	CmdHello = "hello"
 )
 
 // Status values
 const (
 	StatusOK    = "ok"
 	StatusError = "error"
+
+	// StatusProgress marks an interim response sent before the final one,
+	// on a load with "progress" set. Its data is a LoadProgressData.
+	StatusProgress = "progress"
 )
 
 // Error codes for structured error handling
@@ -35,6 +84,14 @@ const (
 	ErrCodePresetNotFound = "preset_not_found"
 	ErrCodeModelNotFound  = "model_not_found"
 	ErrCodeServerFailed   = "server_failed"
+	ErrCodeUnauthorized   = "unauthorized"
+	ErrCodeUnknownCommand = "unknown_command"
+	// ErrCodeVersionMismatch rejects a request from a client speaking
+	// another protocol version.
+	ErrCodeVersionMismatch = "version_mismatch"
+	// ErrCodeBusy rejects a request while the daemon already handles as
+	// many as it allows; the client may retry.
+	ErrCodeBusy = "busy"
 )
 
 // NewRequest creates a new request with the given command and args.
@@ -42,22 +99,43 @@ func NewRequest(command string, args map[string]any) *Request {
 	return &Request{
 		Command: command,
 		Args:    args,
+		Version: Version,
 	}
 }
 
-// NewOKResponse creates a successful response with data.
-func NewOKResponse(data map[string]any) *Response {
-	return &Response{
-		Status: StatusOK,
-		Data:   data,
+// NewOKResponse creates a successful response with data, one of the
+// payload types, or nil.
+func NewOKResponse(data any) *Response {
+	resp := &Response{
+		Status:  StatusOK,
+		Version: Version,
 	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return NewErrorResponse(fmt.Sprintf("encode response: %v", err))
+		}
+		resp.Data = raw
+	}
+	return resp
+}
+
+// NewProgressResponse creates an interim response with data, one of the
+// payload types.
+func NewProgressResponse(data any) *Response {
+	resp := NewOKResponse(data)
+	if resp.Status == StatusOK {
+		resp.Status = StatusProgress
+	}
+	return resp
 }
 
 // NewErrorResponse creates an error response without a code.
 func NewErrorResponse(err string) *Response {
 	return &Response{
-		Status: StatusError,
-		Error:  err,
+		Status:  StatusError,
+		Error:   err,
+		Version: Version,
 	}
 }
 
@@ -67,5 +145,31 @@ func NewErrorResponseWithCode(code, message string) *Response {
 		Status:    StatusError,
 		Error:     message,
 		ErrorCode: code,
+		Version:   Version,
+	}
+}
+
+// NewErrorResponseWithData creates an error response with a structured
+// code and a payload describing the failure, e.g. LoadFailureData.
+func NewErrorResponseWithData(code, message string, data any) *Response {
+	resp := NewErrorResponseWithCode(code, message)
+	if raw, err := json.Marshal(data); err == nil {
+		resp.Data = raw
+	}
+	return resp
+}
+
+// IsLoopback reports whether the TCP address addr (host:port) is on the
+// loopback interface, so traffic to it does not leave the machine. An
+// empty host means all interfaces and is not loopback.
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = strings.Trim(addr, "[]")
+	}
+	if host == "localhost" {
+		return true
 	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
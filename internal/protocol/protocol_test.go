@@ -56,24 +56,24 @@ func TestNewRequest(t *testing.T) {
 
 func TestNewOKResponse(t *testing.T) {
 	tests := []struct {
-		name string
-		data map[string]any
+		name     string
+		data     any
+		wantData string
 	}{
 		{
-			name: "with nil data",
-			data: nil,
+			name:     "with nil data",
+			data:     nil,
+			wantData: "",
 		},
 		{
-			name: "with state data",
-			data: map[string]any{"state": "running"},
+			name:     "with cancel data",
+			data:     CancelData{Canceled: true},
+			wantData: `{"canceled":true}`,
 		},
 		{
-			name: "with multiple fields",
-			data: map[string]any{
-				"state":    "running",
-				"preset":   "codellama-7b",
-				"endpoint": "http://localhost:8080",
-			},
+			name:     "with load data",
+			data:     LoadData{Endpoint: "http://localhost:8080"},
+			wantData: `{"endpoint":"http://localhost:8080"}`,
 		},
 	}
 
@@ -87,20 +87,54 @@ func TestNewOKResponse(t *testing.T) {
 			if resp.Error != "" {
 				t.Errorf("Error = %q, want empty", resp.Error)
 			}
-			if tt.data == nil && resp.Data != nil {
-				t.Errorf("Data = %v, want nil", resp.Data)
-			}
-			if tt.data != nil {
-				for k, v := range tt.data {
-					if resp.Data[k] != v {
-						t.Errorf("Data[%q] = %v, want %v", k, resp.Data[k], v)
-					}
-				}
+			if string(resp.Data) != tt.wantData {
+				t.Errorf("Data = %s, want %s", resp.Data, tt.wantData)
 			}
 		})
 	}
 }
 
+func TestNewOKResponse_Unencodable(t *testing.T) {
+	// Act
+	resp := NewOKResponse(map[string]any{"bad": make(chan int)})
+
+	// Assert
+	if resp.Status != StatusError {
+		t.Errorf("Status = %q, want %q", resp.Status, StatusError)
+	}
+}
+
+func TestResponse_Decode(t *testing.T) {
+	// Arrange
+	resp := NewOKResponse(UnloadData{Graceful: true})
+
+	// Act
+	var got UnloadData
+	err := resp.Decode(&got)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !got.Graceful {
+		t.Errorf("Decode() = %+v, want Graceful", got)
+	}
+}
+
+func TestResponse_DecodeEmpty(t *testing.T) {
+	// Arrange
+	resp := NewErrorResponse("failed")
+	got := StatusData{State: "unchanged"}
+
+	// Act
+	err := resp.Decode(&got)
+
+	// Assert
+	if err != nil || got.State != "unchanged" {
+		t.Errorf("Decode() = %+v, %v, want data left alone", got, err)
+	}
+}
+
 func TestNewErrorResponse(t *testing.T) {
 	tests := []struct {
 		name string
@@ -169,7 +203,7 @@ func TestResponse_JSONRoundTrip(t *testing.T) {
 	}{
 		{
 			name: "ok response",
-			resp: NewOKResponse(map[string]any{"state": "idle"}),
+			resp: NewOKResponse(StatusData{State: "idle"}),
 		},
 		{
 			name: "error response",
@@ -198,3 +232,30 @@ func TestResponse_JSONRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:7070", true},
+		{"localhost:7070", true},
+		{"[::1]:7070", true},
+		{"0.0.0.0:7070", false},
+		{":7070", false},
+		{"192.168.1.10:7070", false},
+		{"gpu-box:7070", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			// Act
+			got := IsLoopback(tt.addr)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("IsLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
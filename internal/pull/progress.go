@@ -0,0 +1,148 @@
+package pull
+
+import "time"
+
+// Phase is the kind of file a pull is downloading.
+type Phase string
+
+const (
+	PhaseModel  Phase = "model"
+	PhaseMmproj Phase = "mmproj"
+	PhaseLora   Phase = "lora"
+	PhaseFile   Phase = "file" // a repository file pulled by PullFiles
+)
+
+// Progress reports a file download: how far it is, how fast it goes, and
+// how long it has left.
+type Progress struct {
+	Phase      Phase
+	File       string // file being downloaded; the current shard for split models
+	Shard      int    // 1-based shard of a split model; 0 for a single file
+	Shards     int
+	Downloaded int64         // bytes so far, across all shards of a split model
+	Total      int64         // -1 when unknown
+	Rate       float64       // bytes per second; 0 until measured
+	ETA        time.Duration // 0 when unknown
+}
+
+// ReportFunc is called during download with structured progress.
+type ReportFunc func(Progress)
+
+// SetReportFunc sets the structured progress callback.
+func (p *Puller) SetReportFunc(fn ReportFunc) {
+	p.onReport = fn
+}
+
+// rateSampleInterval is the minimum time between rate samples, so bursts
+// of 32KB reads do not make the rate jump around.
+const rateSampleInterval = 500 * time.Millisecond
+
+// rateSmoothing weighs the newest rate sample in the moving average.
+const rateSmoothing = 0.3
+
+// rateMeter measures transfer rate as a moving average of samples.
+type rateMeter struct {
+	last      time.Time
+	lastBytes int64
+	rate      float64
+}
+
+// update records that bytes have been transferred at now and returns the
+// current rate. The first call sets the baseline, so bytes resumed from a
+// partial download do not count.
+func (m *rateMeter) update(now time.Time, bytes int64) float64 {
+	if m.last.IsZero() || bytes < m.lastBytes {
+		m.last, m.lastBytes = now, bytes
+		return m.rate
+	}
+	dt := now.Sub(m.last)
+	if dt < rateSampleInterval {
+		return m.rate
+	}
+	sample := float64(bytes-m.lastBytes) / dt.Seconds()
+	if m.rate == 0 {
+		m.rate = sample
+	} else {
+		m.rate = rateSmoothing*sample + (1-rateSmoothing)*m.rate
+	}
+	m.last, m.lastBytes = now, bytes
+	return m.rate
+}
+
+// skip moves the baseline past n bytes that were not transferred, e.g. a
+// shard already on disk.
+func (m *rateMeter) skip(n int64) {
+	if !m.last.IsZero() {
+		m.lastBytes += n
+	}
+}
+
+// transfer is the file download being reported.
+type transfer struct {
+	phase  Phase
+	file   string
+	shard  int
+	shards int
+	offset int64 // bytes of earlier shards
+	total  int64 // size of all shards; 0 for a single file
+	meter  rateMeter
+}
+
+// startTransfer begins reporting the download of file.
+func (p *Puller) startTransfer(phase Phase, file string) {
+	p.transfer = &transfer{phase: phase, file: file}
+}
+
+// reportProgress reports downloaded of total bytes of the current file,
+// adding earlier shards of a split model.
+func (p *Puller) reportProgress(downloaded, total int64) {
+	t := p.currentTransfer()
+	downloaded += t.offset
+	if t.total > 0 {
+		total = t.total
+	}
+	if p.onProgress != nil {
+		p.onProgress(downloaded, total)
+	}
+	if p.onReport != nil {
+		p.onReport(t.progress(time.Now(), downloaded, total))
+	}
+}
+
+// reportDone reports the current file as complete at size bytes.
+func (p *Puller) reportDone(size int64) {
+	if size <= 0 {
+		return
+	}
+	if p.onProgress != nil {
+		p.onProgress(size, size)
+	}
+	if p.onReport != nil {
+		pr := p.currentTransfer().progress(time.Now(), size, size)
+		pr.Shard = pr.Shards
+		p.onReport(pr)
+	}
+}
+
+func (p *Puller) currentTransfer() *transfer {
+	if p.transfer == nil {
+		p.transfer = &transfer{phase: PhaseModel}
+	}
+	return p.transfer
+}
+
+func (t *transfer) progress(now time.Time, downloaded, total int64) Progress {
+	pr := Progress{
+		Phase:      t.phase,
+		File:       t.file,
+		Shard:      t.shard,
+		Shards:     t.shards,
+		Downloaded: downloaded,
+		Total:      total,
+		Rate:       t.meter.update(now, downloaded),
+	}
+	if pr.Rate > 0 && total > downloaded {
+		pr.ETA = time.Duration(float64(total-downloaded) / pr.Rate * float64(time.Second)).Round(time.Second)
+	}
+	return pr
+}
@@ -0,0 +1,46 @@
+package pull
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateMeter(t *testing.T) {
+	// Arrange
+	var m rateMeter
+	start := time.Unix(0, 0)
+
+	// Act
+	first := m.update(start, 1000) // resumed bytes only set the baseline
+	early := m.update(start.Add(100*time.Millisecond), 2000)
+	measured := m.update(start.Add(time.Second), 3000)
+	m.skip(5000) // a shard already on disk
+	smoothed := m.update(start.Add(2*time.Second), 8000+1000)
+
+	// Assert
+	if first != 0 || early != 0 {
+		t.Errorf("rate before first sample interval = %v, %v, want 0", first, early)
+	}
+	if measured != 2000 {
+		t.Errorf("rate = %v, want 2000", measured)
+	}
+	if want := rateSmoothing*1000 + (1-rateSmoothing)*2000; smoothed != want {
+		t.Errorf("smoothed rate = %v, want %v", smoothed, want)
+	}
+}
+
+func TestTransfer_ProgressETA(t *testing.T) {
+	// Arrange
+	tr := &transfer{phase: PhaseMmproj, file: "mmproj.gguf"}
+	start := time.Unix(0, 0)
+	tr.progress(start, 0, 10000)
+
+	// Act
+	pr := tr.progress(start.Add(time.Second), 1000, 10000)
+
+	// Assert
+	want := Progress{Phase: PhaseMmproj, File: "mmproj.gguf", Downloaded: 1000, Total: 10000, Rate: 1000, ETA: 9 * time.Second}
+	if pr != want {
+		t.Errorf("progress = %+v, want %+v", pr, want)
+	}
+}
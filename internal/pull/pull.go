@@ -1,4 +1,5 @@
-// Package pull handles downloading models from HuggingFace.
+// Package pull handles downloading models from HuggingFace and the other
+// registries in pull_registry.go.
 package pull
 
 import (
@@ -11,14 +12,20 @@ import (
 	"log/slog"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"time"
 
+	"github.com/d2verb/alpaca/internal/blob"
 	"github.com/d2verb/alpaca/internal/metadata"
 )
 
 const defaultHuggingFaceBaseURL = "https://huggingface.co"
 
+// DefaultEndpoint is the HuggingFace endpoint used when none is configured.
+const DefaultEndpoint = defaultHuggingFaceBaseURL
+
 // ProgressFunc is called during download with current and total bytes.
 type ProgressFunc func(downloaded, total int64)
 
@@ -28,15 +35,23 @@ type FileStartFunc func(filename string, size int64, index, total int)
 // FileSavedFunc is called after each file is successfully downloaded and verified.
 type FileSavedFunc func(savedPath string)
 
-// Puller handles model downloads from HuggingFace.
+// Puller handles model downloads from a registry, HuggingFace by default.
 type Puller struct {
-	modelsDir   string
-	client      *http.Client
-	onProgress  ProgressFunc
-	onFileStart FileStartFunc
-	onFileSaved FileSavedFunc
-	metadata    *metadata.Manager
-	baseURL     string
+	modelsDir    string
+	client       *http.Client
+	onProgress   ProgressFunc
+	onReport     ReportFunc
+	onFileStart  FileStartFunc
+	onFileSaved  FileSavedFunc
+	metadata     *metadata.Manager
+	blobs        *blob.Store
+	registry     Registry
+	baseURL      string           // active endpoint
+	endpoints    []string         // configured endpoints in failover order (optional)
+	memoryBudget MemoryBudgetFunc // used to resolve :auto quants (optional)
+	retry        RetryPolicy
+	verify       bool      // hash files even when a recorded checksum is current
+	transfer     *transfer // download being reported
 }
 
 // NewPuller creates a new model puller.
@@ -45,7 +60,10 @@ func NewPuller(modelsDir string) *Puller {
 		modelsDir: modelsDir,
 		client:    &http.Client{},
 		metadata:  metadata.NewManager(modelsDir),
+		blobs:     blob.New(modelsDir),
+		registry:  HuggingFace,
 		baseURL:   defaultHuggingFaceBaseURL,
+		retry:     DefaultRetryPolicy,
 	}
 }
 
@@ -64,8 +82,34 @@ func (p *Puller) SetFileSavedFunc(fn FileSavedFunc) {
 	p.onFileSaved = fn
 }
 
+// SetVerify makes checks of downloaded files hash them in full instead of
+// trusting the checksum recorded while their size and modification time
+// are unchanged.
+func (p *Puller) SetVerify(verify bool) {
+	p.verify = verify
+}
+
+// Clone returns a puller that shares p's HTTP client, endpoints, and metadata
+// manager but has no callbacks set. Use one clone per concurrent download so
+// each can report its own progress.
+func (p *Puller) Clone() *Puller {
+	return &Puller{
+		modelsDir:    p.modelsDir,
+		client:       p.client,
+		metadata:     p.metadata,
+		blobs:        p.blobs,
+		registry:     p.registry,
+		baseURL:      p.baseURL,
+		endpoints:    p.endpoints,
+		memoryBudget: p.memoryBudget,
+		retry:        p.retry,
+		verify:       p.verify,
+	}
+}
+
 // PullResult contains information about the downloaded file.
 type PullResult struct {
+	Quant           string // quant chosen for :auto; empty otherwise
 	Path            string
 	Filename        string
 	Size            int64
@@ -77,11 +121,13 @@ type PullResult struct {
 
 // FileInfo contains information about model and mmproj files from the manifest.
 type FileInfo struct {
+	Quant                  string // resolved quant
 	Filename               string
 	Size                   int64
 	MmprojFilename         string // storage filename with repo prefix; empty if no mmproj
 	MmprojOriginalFilename string // original filename before repo prefix; empty if no mmproj
 	MmprojSize             int64
+	Shards                 int // number of files a split model is stored in; 0 for a single file
 }
 
 // ggufFileInfo holds a GGUF filename and its optional LFS SHA256 hash,
@@ -94,16 +140,43 @@ type ggufFileInfo struct {
 	MmprojSHA256           string // SHA256 from LFS; empty if not available
 	MmprojSize             int64  // mmproj file size; 0 if no mmproj
 	MmprojOriginalFilename string // original filename from API (before prefix)
+
+	// Shards lists every file of a split model, in order. Filename, SHA256
+	// and Size then describe the first shard, the first shard, and the
+	// total. Nil for single-file models.
+	Shards []shardFile
+}
+
+// modelFiles returns the files that make up the model.
+func (fi ggufFileInfo) modelFiles() []shardFile {
+	if len(fi.Shards) > 0 {
+		return fi.Shards
+	}
+	return []shardFile{{Path: fi.Filename, Filename: fi.Filename, SHA256: fi.SHA256, Size: fi.Size}}
+}
+
+// filenames returns the storage filenames of the model's files.
+func (fi ggufFileInfo) filenames() []string {
+	var names []string
+	for _, f := range fi.modelFiles() {
+		names = append(names, f.Filename)
+	}
+	return names
 }
 
 // Pull downloads a model from HuggingFace.
+// A quant of AutoQuant selects the largest quant that fits in memory.
 func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, error) {
+	if quant == AutoQuant {
+		return p.pullAuto(ctx, repo)
+	}
+
 	// Load existing metadata
 	if err := p.metadata.Load(ctx); err != nil {
 		return nil, fmt.Errorf("load metadata: %w", err)
 	}
 
-	// Fetch manifest from HuggingFace v2 API
+	// Fetch manifest from the registry
 	fileInfo, err := p.fetchManifest(ctx, repo, quant)
 	if err != nil {
 		return nil, err
@@ -115,7 +188,21 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 	}
 
 	// Check if model is already downloaded and up to date
-	if result, ok := p.checkAlreadyUpToDate(repo, quant, fileInfo); ok {
+	if result, ok := p.checkAlreadyUpToDate(ctx, repo, quant, fileInfo); ok {
+		return result, nil
+	}
+
+	// Only the mmproj is missing (pulled before the repo had one, or its
+	// download failed): fetch it without downloading the model again
+	if existing := p.metadata.Find(repo, quant); existing != nil && existing.Mmproj == nil &&
+		fileInfo.MmprojFilename != "" && p.modelFilesCurrent(ctx, *existing, fileInfo) {
+		result, err := p.pullMmprojOnly(ctx, *existing, fileInfo)
+		if err != nil && result == nil {
+			return nil, err
+		}
+		if err != nil {
+			slog.Warn("mmproj download failed", "error", err)
+		}
 		return result, nil
 	}
 
@@ -125,37 +212,41 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 	}
 
 	// Notify: starting main model download
+	p.startTransfer(PhaseModel, fileInfo.Filename)
 	if p.onFileStart != nil {
 		p.onFileStart(fileInfo.Filename, fileInfo.Size, 1, totalFiles)
 	}
 
-	// Download file with OS-level path confinement
-	size, err := p.downloadFile(ctx, repo, fileInfo.Filename)
+	// Download and verify SHA256 integrity (fail-closed: reject if hash is
+	// missing or mismatched), with OS-level path confinement
+	var size int64
+	if len(fileInfo.Shards) > 0 {
+		size, err = p.downloadShards(ctx, repo, fileInfo.Shards, fileInfo.Size)
+	} else {
+		size, err = p.downloadVerified(ctx, repo, fileInfo.modelFiles()[0])
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify SHA256 integrity (fail-closed: reject if hash is missing or mismatched)
-	if fileInfo.SHA256 == "" {
-		p.removeDownloadedFile(fileInfo.Filename)
-		return nil, fmt.Errorf("integrity verification failed for %s: no SHA256 hash available from API", fileInfo.Filename)
-	}
-	if err := p.verifyFileHash(fileInfo.Filename, fileInfo.SHA256); err != nil {
-		p.removeDownloadedFile(fileInfo.Filename)
-		return nil, fmt.Errorf("integrity verification failed for %s: %w", fileInfo.Filename, err)
-	}
-
 	destPath := filepath.Join(p.modelsDir, fileInfo.Filename)
 
 	// Ensure progress shows 100% and notify saved
-	if p.onProgress != nil && size > 0 {
-		p.onProgress(size, size)
-	}
+	p.reportDone(size)
 	if p.onFileSaved != nil {
 		p.onFileSaved(destPath)
 	}
 
-	// Re-pull cleanup: remove outdated mmproj if filename changed or mmproj was removed
+	// Re-pull cleanup: remove previous model files if upstream renamed them,
+	// and the outdated mmproj if its filename changed or it was removed
+	if prev := p.metadata.Find(repo, quant); prev != nil {
+		current := fileInfo.filenames()
+		for _, f := range prev.Files() {
+			if !slices.Contains(current, f) {
+				p.removeDownloadedFile(f)
+			}
+		}
+	}
 	p.cleanupOldMmproj(repo, quant, fileInfo.MmprojFilename)
 
 	// Download mmproj if manifest includes one
@@ -163,6 +254,7 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 	mmprojFailed := false
 	if fileInfo.MmprojFilename != "" {
 		// Notify: starting mmproj download
+		p.startTransfer(PhaseMmproj, fileInfo.MmprojOriginalFilename)
 		if p.onFileStart != nil {
 			p.onFileStart(fileInfo.MmprojOriginalFilename, fileInfo.MmprojSize, 2, totalFiles)
 		}
@@ -174,9 +266,7 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 			// Continue without mmproj - save metadata without it
 		} else {
 			// Ensure progress shows 100% and notify saved
-			if p.onProgress != nil && mmprojEntry.Size > 0 {
-				p.onProgress(mmprojEntry.Size, mmprojEntry.Size)
-			}
+			p.reportDone(mmprojEntry.Size)
 			if p.onFileSaved != nil {
 				p.onFileSaved(filepath.Join(p.modelsDir, fileInfo.MmprojFilename))
 			}
@@ -189,14 +279,17 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 		Quant:        quant,
 		Filename:     fileInfo.Filename,
 		Size:         size,
+		SHA256:       fileInfo.SHA256,
 		Mmproj:       mmprojEntry,
+		MmprojFailed: mmprojFailed,
+		Registry:     schemeOf(p.registry),
 		DownloadedAt: time.Now().UTC(),
 	}
-	if err := p.metadata.Add(entry); err != nil {
-		return nil, fmt.Errorf("add metadata entry: %w", err)
+	if len(fileInfo.Shards) > 0 {
+		entry.Shards = fileInfo.filenames()
 	}
-	if err := p.metadata.Save(ctx); err != nil {
-		return nil, fmt.Errorf("save metadata: %w", err)
+	if err := p.commitEntry(ctx, entry); err != nil {
+		return nil, err
 	}
 
 	result := &PullResult{
@@ -213,19 +306,44 @@ func (p *Puller) Pull(ctx context.Context, repo, quant string) (*PullResult, err
 	return result, nil
 }
 
+// commitEntry adds or replaces a model entry and persists metadata.
+func (p *Puller) commitEntry(ctx context.Context, entry metadata.ModelEntry) error {
+	entry.ReadGGUFInfo(p.modelsDir)
+	err := p.metadata.Commit(ctx, func(m *metadata.Manager) error {
+		// Re-pulling must not forget that this quant was chosen by :auto,
+		// nor the extra files pulled with it
+		if existing := m.Find(entry.Repo, entry.Quant); existing != nil {
+			entry.AutoSelected = existing.AutoSelected
+			entry.ExtraFiles = existing.ExtraFiles
+		}
+		if err := m.Add(entry); err != nil {
+			return fmt.Errorf("add metadata entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("save metadata: %w", err)
+	}
+	return nil
+}
+
 // checkAlreadyUpToDate checks if the model and mmproj files already exist on
 // disk with matching SHA256 hashes. Returns the result and true only if
 // everything is fully up to date (including mmproj state changes).
-func (p *Puller) checkAlreadyUpToDate(repo, quant string, fileInfo ggufFileInfo) (*PullResult, bool) {
+func (p *Puller) checkAlreadyUpToDate(ctx context.Context, repo, quant string, fileInfo ggufFileInfo) (*PullResult, bool) {
 	if fileInfo.SHA256 == "" {
 		return nil, false
 	}
 
 	// Cheap checks first: file existence, metadata, mmproj consistency
 	destPath := filepath.Join(p.modelsDir, fileInfo.Filename)
-	info, err := os.Stat(destPath)
-	if err != nil {
-		return nil, false
+	var size int64
+	for _, f := range fileInfo.modelFiles() {
+		info, err := os.Stat(filepath.Join(p.modelsDir, f.Filename))
+		if err != nil {
+			return nil, false
+		}
+		size += info.Size()
 	}
 
 	// Metadata entry must exist; otherwise we need the full flow to register it
@@ -233,6 +351,9 @@ func (p *Puller) checkAlreadyUpToDate(repo, quant string, fileInfo ggufFileInfo)
 	if existing == nil {
 		return nil, false
 	}
+	if len(fileInfo.Shards) > 0 && !slices.Equal(existing.Files(), fileInfo.filenames()) {
+		return nil, false
+	}
 
 	// Check mmproj state matches between manifest and existing metadata
 	existingHasMmproj := existing.Mmproj != nil
@@ -245,15 +366,17 @@ func (p *Puller) checkAlreadyUpToDate(repo, quant string, fileInfo ggufFileInfo)
 		return nil, false
 	}
 
-	// Expensive: verify main model file hash (reads entire file)
-	if err := p.verifyFileHash(fileInfo.Filename, fileInfo.SHA256); err != nil {
-		return nil, false
+	// Expensive unless checksums are recorded: verify model file hashes
+	for _, f := range fileInfo.modelFiles() {
+		if !p.hashMatches(ctx, f.Filename, f.SHA256) {
+			return nil, false
+		}
 	}
 
 	result := &PullResult{
 		Path:            destPath,
 		Filename:        fileInfo.Filename,
-		Size:            info.Size(),
+		Size:            size,
 		AlreadyUpToDate: true,
 	}
 
@@ -262,14 +385,13 @@ func (p *Puller) checkAlreadyUpToDate(repo, quant string, fileInfo ggufFileInfo)
 			return nil, false
 		}
 		mmprojPath := filepath.Join(p.modelsDir, fileInfo.MmprojFilename)
-		if _, statErr := os.Stat(mmprojPath); statErr != nil {
+		if !p.hashMatches(ctx, fileInfo.MmprojFilename, fileInfo.MmprojSHA256) {
 			return nil, false
 		}
-		// Expensive: verify mmproj file hash
-		if err := p.verifyFileHash(fileInfo.MmprojFilename, fileInfo.MmprojSHA256); err != nil {
+		mmprojInfo, err := os.Stat(mmprojPath)
+		if err != nil {
 			return nil, false
 		}
-		mmprojInfo, _ := os.Stat(mmprojPath)
 		result.MmprojFilename = fileInfo.MmprojFilename
 		result.MmprojSize = mmprojInfo.Size()
 	}
@@ -278,17 +400,28 @@ func (p *Puller) checkAlreadyUpToDate(repo, quant string, fileInfo ggufFileInfo)
 }
 
 // GetFileInfo fetches info about the model and optional mmproj files without downloading.
+// AutoQuant is resolved the same way Pull resolves it.
 func (p *Puller) GetFileInfo(ctx context.Context, repo, quant string) (*FileInfo, error) {
+	if quant == AutoQuant {
+		resolved, err := p.resolveAutoQuant(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		quant = resolved
+	}
+
 	fileInfo, err := p.fetchManifest(ctx, repo, quant)
 	if err != nil {
 		return nil, err
 	}
 	return &FileInfo{
+		Quant:                  quant,
 		Filename:               fileInfo.Filename,
 		Size:                   fileInfo.Size,
 		MmprojFilename:         fileInfo.MmprojFilename,
 		MmprojOriginalFilename: fileInfo.MmprojOriginalFilename,
 		MmprojSize:             fileInfo.MmprojSize,
+		Shards:                 len(fileInfo.Shards),
 	}, nil
 }
 
@@ -311,7 +444,86 @@ type manifestLFS struct {
 }
 
 func (p *Puller) fetchManifest(ctx context.Context, repo, quant string) (ggufFileInfo, error) {
-	url := fmt.Sprintf("%s/v2/%s/manifests/%s", p.baseURL, repo, quant)
+	var fi ggufFileInfo
+	err := p.tryEndpoints(func(baseURL string) error {
+		var err error
+		fi, err = p.registry.manifest(ctx, p.client, baseURL, repo, quant)
+		return err
+	})
+	if err != nil {
+		return fi, err
+	}
+
+	// The manifest names only the first shard of a split model, unless the
+	// registry lists them all
+	if _, _, ok := splitShardName(path.Base(fi.Filename)); ok && len(fi.Shards) == 0 {
+		shards, err := p.fetchShards(ctx, repo, fi.Filename)
+		if err != nil {
+			return ggufFileInfo{}, err
+		}
+		fi.Shards = shards
+		fi.Filename = shards[0].Filename
+		fi.SHA256 = shards[0].SHA256
+		fi.Size = 0
+		for _, s := range shards {
+			fi.Size += s.Size
+		}
+	}
+
+	// Some manifests omit the LFS hash (e.g. for files stored with Xet);
+	// the tree API lists it too
+	if len(fi.Shards) == 0 && fi.SHA256 == "" || fi.MmprojFilename != "" && fi.MmprojSHA256 == "" {
+		if err := p.fillTreeHashes(ctx, repo, &fi); err != nil {
+			return ggufFileInfo{}, err
+		}
+	}
+	return fi, nil
+}
+
+// fillTreeHashes looks up the hashes the manifest did not publish in the
+// tree API. A model file listed without a verifiable hash fails here,
+// before it is downloaded; a missing mmproj hash fails only the mmproj
+// download. If the file list is unavailable the hashes stay empty, and the
+// downloads still fail closed.
+func (p *Puller) fillTreeHashes(ctx context.Context, repo string, fi *ggufFileInfo) error {
+	trees := make(map[string]map[string]treeEntry)
+	lookup := func(file string) (treeEntry, bool) {
+		dir := path.Dir(file)
+		if dir == "." {
+			dir = ""
+		}
+		byPath, ok := trees[dir]
+		if !ok {
+			entries, err := p.fetchRepoTree(ctx, repo, dir, false)
+			if err != nil {
+				slog.Debug("file list unavailable for hash lookup", "repo", repo, "error", err)
+			}
+			byPath = filesByPath(entries)
+			trees[dir] = byPath
+		}
+		e, ok := byPath[file]
+		return e, ok
+	}
+
+	if len(fi.Shards) == 0 && fi.SHA256 == "" {
+		if e, ok := lookup(fi.Filename); ok {
+			sum, err := e.sha256()
+			if err != nil {
+				return integrityError(fi.Filename, err)
+			}
+			fi.SHA256 = sum
+		}
+	}
+	if fi.MmprojFilename != "" && fi.MmprojSHA256 == "" {
+		if e, ok := lookup(fi.MmprojOriginalFilename); ok {
+			fi.MmprojSHA256, _ = e.sha256()
+		}
+	}
+	return nil
+}
+
+func (huggingFace) manifest(ctx context.Context, client *http.Client, baseURL, repo, quant string) (ggufFileInfo, error) {
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", baseURL, repo, quant)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return ggufFileInfo{}, fmt.Errorf("create request: %w", err)
@@ -319,9 +531,12 @@ func (p *Puller) fetchManifest(ctx context.Context, repo, quant string) (ggufFil
 	req.Header.Set("User-Agent", "llama-cpp") // Required by HuggingFace v2 manifest API to return GGUF file info
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := p.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		return ggufFileInfo{}, fmt.Errorf("fetch manifest: %w", err)
+		if ctx.Err() != nil {
+			return ggufFileInfo{}, fmt.Errorf("fetch manifest: %w", err)
+		}
+		return ggufFileInfo{}, &endpointUnavailableError{fmt.Errorf("fetch manifest: %w", err)}
 	}
 	defer resp.Body.Close()
 
@@ -331,6 +546,9 @@ func (p *Puller) fetchManifest(ctx context.Context, repo, quant string) (ggufFil
 	if resp.StatusCode == http.StatusBadRequest {
 		return ggufFileInfo{}, fmt.Errorf("invalid quantization '%s' for repository '%s'", quant, repo)
 	}
+	if isUnavailableStatus(resp.StatusCode) {
+		return ggufFileInfo{}, &endpointUnavailableError{fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		return ggufFileInfo{}, fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)
 	}
@@ -366,8 +584,16 @@ func (p *Puller) fetchManifest(ctx context.Context, repo, quant string) (ggufFil
 }
 
 func (p *Puller) downloadFile(ctx context.Context, repo, filename string) (int64, error) {
+	return p.downloadURL(ctx, p.fileURL(repo, filename, ""), filename)
+}
+
+// downloadURL downloads url into filename in the models directory, resuming
+// from an existing .part file when possible. Network and server errors are
+// retried according to the retry policy; failures are *DownloadError.
+func (p *Puller) downloadURL(ctx context.Context, url, filename string) (int64, error) {
 	partFilename := filename + ".part"
 	etagFilename := filename + ".etag"
+	progressFilename := filename + ".progress"
 
 	// Open models directory with OS-level path confinement.
 	// This prevents path traversal attacks even with malicious filenames.
@@ -377,25 +603,29 @@ func (p *Puller) downloadFile(ctx context.Context, repo, filename string) (int64
 	}
 	defer root.Close()
 
-	// Retry loop for 416 responses (max 1 retry)
-	const maxRetries = 1
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		size, retry, err := p.doDownload(ctx, root, repo, filename, partFilename, etagFilename)
-		if err != nil {
-			return 0, err
-		}
-		if !retry {
-			return size, nil
+	return p.withRetry(ctx, filename, func() (int64, error) {
+		// Retry loop for 416 responses (max 1 retry)
+		const maxRetries = 1
+		for attempt := 0; attempt <= maxRetries; attempt++ {
+			size, retry, err := p.doDownload(ctx, root, url, filename, partFilename, etagFilename, progressFilename)
+			if err != nil {
+				return 0, err
+			}
+			if !retry {
+				return size, nil
+			}
+			// retry == true means we got 416, files are cleaned up, try again
 		}
-		// retry == true means we got 416, files are cleaned up, try again
-	}
-
-	return 0, fmt.Errorf("download failed: max retries exceeded")
+		return 0, fmt.Errorf("download failed: max retries exceeded")
+	})
 }
 
 // doDownload performs the actual download. Returns (size, retry, error).
 // retry=true indicates a 416 response was received and files were cleaned up.
-func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename, partFilename, etagFilename string) (int64, bool, error) {
+// A .progress file records the size of the whole file, so a resumed
+// download reports its progress from the start, even before the server
+// answers or when it sends no Content-Length.
+func (p *Puller) doDownload(ctx context.Context, root *os.Root, url, filename, partFilename, etagFilename, progressFilename string) (int64, bool, error) {
 	// Check for existing .part file and .etag
 	var existingSize int64
 	var existingETag string
@@ -407,12 +637,17 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 	// If .part exists but .etag is missing, we cannot safely resume
 	// Delete .part and start from beginning
 	if existingSize > 0 && existingETag == "" {
-		removePartFiles(root, partFilename, etagFilename)
+		removePartFiles(root, partFilename, etagFilename, progressFilename)
 		existingSize = 0
 	}
 
+	// Show the resumed offset while the request is in flight
+	saved := readDownloadState(root, progressFilename)
+	if existingSize > 0 && saved.Total > existingSize {
+		p.reportProgress(existingSize, saved.Total)
+	}
+
 	// Create HTTP request
-	url := fmt.Sprintf("%s/%s/resolve/main/%s", p.baseURL, repo, filename)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return 0, false, fmt.Errorf("create request: %w", err)
@@ -426,7 +661,7 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return 0, false, fmt.Errorf("download: %w", err)
+		return 0, false, networkError(ctx, filename, fmt.Errorf("download: %w", err))
 	}
 	defer resp.Body.Close()
 
@@ -435,24 +670,24 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 	case http.StatusOK:
 		// Server ignored Range, ETag mismatch, or Range not supported
 		// Delete .part + .etag and start from beginning
-		removePartFiles(root, partFilename, etagFilename)
+		removePartFiles(root, partFilename, etagFilename, progressFilename)
 		existingSize = 0
 	case http.StatusPartialContent:
 		// Range request successful, validate Content-Range
 		rangeStart, err := parseContentRangeStart(resp.Header.Get("Content-Range"))
 		if err != nil || rangeStart != existingSize {
 			// Content-Range mismatch, restart from beginning
-			removePartFiles(root, partFilename, etagFilename)
+			removePartFiles(root, partFilename, etagFilename, progressFilename)
 			// Need to re-request without Range header (defer will close resp.Body)
 			return 0, true, nil
 		}
 	case http.StatusRequestedRangeNotSatisfiable:
 		// Range invalid (.part size > server file size)
 		// Delete .part + .etag and signal retry
-		removePartFiles(root, partFilename, etagFilename)
+		removePartFiles(root, partFilename, etagFilename, progressFilename)
 		return 0, true, nil
 	default:
-		return 0, false, fmt.Errorf("download failed: status %d", resp.StatusCode)
+		return 0, false, statusError(filename, resp.StatusCode)
 	}
 
 	// Save ETag for new downloads
@@ -487,6 +722,12 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 	} else {
 		total = resp.ContentLength
 	}
+	if total < 0 && existingSize > 0 && saved.Total > existingSize {
+		total = saved.Total
+	}
+	if total > 0 && total != saved.Total {
+		writeDownloadState(root, progressFilename, downloadState{Total: total})
+	}
 
 	// Copy with progress reporting
 	var written int64
@@ -503,9 +744,7 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 		if nr > 0 {
 			nw, writeErr := out.Write(buf[:nr])
 			written += int64(nw)
-			if p.onProgress != nil {
-				p.onProgress(existingSize+written, total)
-			}
+			p.reportProgress(existingSize+written, total)
 			if writeErr != nil {
 				return 0, false, fmt.Errorf("write file: %w", writeErr)
 			}
@@ -514,7 +753,7 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 			break
 		}
 		if readErr != nil {
-			return 0, false, fmt.Errorf("read response: %w", readErr)
+			return 0, false, networkError(ctx, filename, fmt.Errorf("read response: %w", readErr))
 		}
 	}
 
@@ -527,7 +766,8 @@ func (p *Puller) doDownload(ctx context.Context, root *os.Root, repo, filename,
 	if err := root.Rename(partFilename, filename); err != nil {
 		return 0, false, fmt.Errorf("rename file: %w", err)
 	}
-	root.Remove(etagFilename) // Ignore error, file may not exist
+	root.Remove(etagFilename) // Ignore errors, the files may not exist
+	root.Remove(progressFilename)
 
 	return existingSize + written, false, nil
 }
@@ -547,10 +787,45 @@ func parseContentRangeStart(header string) (int64, error) {
 	return start, nil
 }
 
-// removePartFiles removes .part and .etag files.
-func removePartFiles(root *os.Root, partFilename, etagFilename string) {
+// removePartFiles removes .part, .etag and .progress files.
+func removePartFiles(root *os.Root, partFilename, etagFilename, progressFilename string) {
 	root.Remove(partFilename)
 	root.Remove(etagFilename)
+	root.Remove(progressFilename)
+}
+
+// downloadState is the content of a .progress file: what an interrupted
+// download knows beyond its .part file, whose size is the bytes so far.
+type downloadState struct {
+	Total int64 `json:"total"` // size of the whole file
+}
+
+// readDownloadState reads a .progress file, returning the zero state on
+// any error.
+func readDownloadState(root *os.Root, filename string) downloadState {
+	var state downloadState
+	f, err := root.Open(filename)
+	if err != nil {
+		return state
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&state); err != nil {
+		return downloadState{}
+	}
+	return state
+}
+
+// writeDownloadState writes a .progress file. Errors are ignored: without
+// it a resumed download only reports its progress later.
+func writeDownloadState(root *os.Root, filename string, state downloadState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if f, err := root.Create(filename); err == nil {
+		f.Write(data)
+		f.Close()
+	}
 }
 
 // readETagFile reads the ETag from file, returning empty string on any error.
@@ -567,28 +842,100 @@ func readETagFile(root *os.Root, filename string) string {
 // verifyFileHash computes the SHA256 hash of a downloaded file and compares it
 // against the expected hash from the HuggingFace API.
 func (p *Puller) verifyFileHash(filename, expectedSHA256 string) error {
+	actual, err := p.fileSHA256(filename)
+	if err != nil {
+		return err
+	}
+	if actual != expectedSHA256 {
+		return fmt.Errorf("expected SHA256 %s, got %s", expectedSHA256, actual)
+	}
+	return nil
+}
+
+// hashMatches reports whether filename in the models directory has SHA256
+// sum. A checksum recorded for the file is trusted while its size and
+// modification time are unchanged, unless SetVerify was called; otherwise
+// the file is hashed and the result recorded.
+func (p *Puller) hashMatches(ctx context.Context, filename, sum string) bool {
+	if sum == "" {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(p.modelsDir, filename))
+	if err != nil {
+		return false
+	}
+	if !p.verify && p.metadata.Checksum(filename, info.Size(), info.ModTime()) == sum {
+		return true
+	}
+	if p.verifyFileHash(filename, sum) != nil {
+		return false
+	}
+	p.recordChecksum(ctx, filename, sum)
+	return true
+}
+
+// recordChecksum records that filename, as it is now, has SHA256 sum. The
+// record only saves hashing the file again, so failures are logged and
+// otherwise ignored.
+func (p *Puller) recordChecksum(ctx context.Context, filename, sum string) {
+	info, err := os.Stat(filepath.Join(p.modelsDir, filename))
+	if err != nil {
+		return
+	}
+	c := metadata.FileChecksum{Size: info.Size(), ModTime: info.ModTime(), SHA256: sum}
+	err = p.metadata.Commit(ctx, func(m *metadata.Manager) error {
+		m.SetChecksum(filename, c)
+		return nil
+	})
+	if err != nil {
+		slog.Debug("checksum not recorded", "file", filename, "error", err)
+	}
+}
+
+// fileSHA256 returns the hex-encoded SHA256 of a file in the models directory.
+func (p *Puller) fileSHA256(filename string) (string, error) {
 	root, err := os.OpenRoot(p.modelsDir)
 	if err != nil {
-		return fmt.Errorf("open models dir: %w", err)
+		return "", fmt.Errorf("open models dir: %w", err)
 	}
 	defer root.Close()
 
 	f, err := root.Open(filename)
 	if err != nil {
-		return fmt.Errorf("open file for verification: %w", err)
+		return "", fmt.Errorf("open file for verification: %w", err)
 	}
 	defer f.Close()
 
 	h := sha256.New()
 	if _, err := io.Copy(h, f); err != nil {
-		return fmt.Errorf("compute hash: %w", err)
+		return "", fmt.Errorf("compute hash: %w", err)
 	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
-	actual := hex.EncodeToString(h.Sum(nil))
-	if actual != expectedSHA256 {
-		return fmt.Errorf("expected SHA256 %s, got %s", expectedSHA256, actual)
+// linkStored points filename at content already in the blob store, so it is
+// not downloaded again. Reports whether the content was stored intact; a
+// corrupted blob is removed so the caller downloads it again.
+func (p *Puller) linkStored(filename, sum string) (int64, bool, error) {
+	if sum == "" || !p.blobs.Has(sum) {
+		return 0, false, nil
+	}
+	if err := p.blobs.Link(filename, sum); err != nil {
+		return 0, false, err
+	}
+	if err := p.verifyFileHash(filename, sum); err != nil {
+		slog.Warn("removing corrupted blob", "blob", blob.Name(sum), "error", err)
+		p.removeDownloadedFile(filename)
+		if err := p.blobs.Remove(blob.Name(sum)); err != nil {
+			return 0, false, err
+		}
+		return 0, false, nil
 	}
-	return nil
+	info, err := os.Stat(filepath.Join(p.modelsDir, filename))
+	if err != nil {
+		return 0, false, err
+	}
+	return info.Size(), true, nil
 }
 
 // removeDownloadedFile removes a downloaded file from the models directory.
@@ -0,0 +1,163 @@
+package pull
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// AutoQuant is the quant placeholder (org/repo:auto) that selects the
+// largest quant fitting in the memory budget.
+const AutoQuant = metadata.AutoQuant
+
+// MemoryBudgetFunc returns the bytes a model file may occupy.
+type MemoryBudgetFunc func() (int64, error)
+
+// QuantInfo describes one quantization available in a repository.
+type QuantInfo struct {
+	Quant    string
+	Filename string // repository path; the first shard of a split model
+	Size     int64
+	Files    int // shards of a split model; 1 for a single file
+}
+
+// quantPattern matches the quant suffix of a GGUF filename,
+// e.g. "Qwen3-8B-Q4_K_M.gguf" or "codellama-7b.Q5_K_S.gguf".
+var quantPattern = regexp.MustCompile(`(?i)[-._]((?:I?Q\d(?:_[A-Z0-9]+)*)|BF16|F16|F32)\.gguf$`)
+
+// SetMemoryBudgetFunc sets how the memory budget for :auto is determined.
+// It is only called when an :auto quant is resolved.
+func (p *Puller) SetMemoryBudgetFunc(fn MemoryBudgetFunc) {
+	p.memoryBudget = fn
+}
+
+// ListQuants returns the quants published in a repository, largest first.
+// mmproj files are excluded. The shards of a split model count as one quant
+// whose size is their sum and whose filename is the first shard. Files in
+// subdirectories are included, since large split models are often published
+// in a directory named after the quant.
+func (p *Puller) ListQuants(ctx context.Context, repo string) ([]QuantInfo, error) {
+	entries, err := p.fetchRepoTree(ctx, repo, "", true)
+	if err != nil {
+		return nil, err
+	}
+	return quantsFromFiles(entries), nil
+}
+
+// quantsFromFiles returns the quants in a repository's recursive file list,
+// largest first, as ListQuants describes them.
+func quantsFromFiles(entries []treeEntry) []QuantInfo {
+	var quants []QuantInfo
+	split := make(map[string]int) // shard prefix -> index in quants
+	for _, e := range entries {
+		if e.Type != "file" || strings.Contains(strings.ToLower(e.Path), "mmproj") {
+			continue
+		}
+		name := e.Path
+		prefix, _, isShard := splitShardName(e.Path)
+		if isShard {
+			name = prefix + ".gguf"
+		}
+		m := quantPattern.FindStringSubmatch(name)
+		if m == nil && path.Dir(name) != "." && strings.HasSuffix(strings.ToLower(name), ".gguf") {
+			// e.g. "Q4_K_M/model-00001-of-00002.gguf"
+			m = quantPattern.FindStringSubmatch("-" + path.Base(path.Dir(name)) + ".gguf")
+		}
+		if m == nil {
+			continue
+		}
+		size := e.Size
+		if e.LFS != nil && e.LFS.Size > 0 {
+			size = e.LFS.Size
+		}
+		if isShard {
+			if i, ok := split[prefix]; ok {
+				quants[i].Size += size
+				quants[i].Files++
+				if e.Path < quants[i].Filename {
+					quants[i].Filename = e.Path
+				}
+				continue
+			}
+			split[prefix] = len(quants)
+		}
+		quants = append(quants, QuantInfo{Quant: strings.ToUpper(m[1]), Filename: e.Path, Size: size, Files: 1})
+	}
+	slices.SortFunc(quants, func(a, b QuantInfo) int { return cmp.Compare(b.Size, a.Size) })
+	return quants
+}
+
+// selectQuant returns the largest quant whose file fits in budget.
+// quants must be sorted largest first.
+func selectQuant(quants []QuantInfo, budget int64) (QuantInfo, error) {
+	if len(quants) == 0 {
+		return QuantInfo{}, fmt.Errorf("no GGUF quants found")
+	}
+	for _, q := range quants {
+		if q.Size <= budget {
+			return q, nil
+		}
+	}
+	smallest := quants[len(quants)-1]
+	return QuantInfo{}, fmt.Errorf("no quant fits in available memory (smallest is %s at %d MB, budget %d MB)",
+		smallest.Quant, smallest.Size>>20, max(budget, 0)>>20)
+}
+
+// SelectAutoQuant returns the quant :auto picks from quants, as returned
+// by ListQuants, for the current memory budget.
+func (p *Puller) SelectAutoQuant(quants []QuantInfo) (QuantInfo, error) {
+	if p.memoryBudget == nil {
+		return QuantInfo{}, fmt.Errorf("memory budget unknown")
+	}
+	budget, err := p.memoryBudget()
+	if err != nil {
+		return QuantInfo{}, fmt.Errorf("detect memory: %w", err)
+	}
+	return selectQuant(quants, budget)
+}
+
+// resolveAutoQuant picks the quant to download for repo:auto.
+func (p *Puller) resolveAutoQuant(ctx context.Context, repo string) (string, error) {
+	if p.memoryBudget == nil {
+		return "", fmt.Errorf("cannot select quant for %s:auto: memory budget unknown", repo)
+	}
+	quants, err := p.ListQuants(ctx, repo)
+	if err != nil {
+		return "", err
+	}
+	q, err := p.SelectAutoQuant(quants)
+	if err != nil {
+		return "", fmt.Errorf("%s:auto: %w", repo, err)
+	}
+	return q.Quant, nil
+}
+
+// pullAuto resolves repo:auto, downloads the chosen quant, and records the
+// choice so h:repo:auto keeps referring to it.
+func (p *Puller) pullAuto(ctx context.Context, repo string) (*PullResult, error) {
+	quant, err := p.resolveAutoQuant(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.Pull(ctx, repo, quant)
+	if err != nil {
+		return nil, err
+	}
+	result.Quant = quant
+
+	err = p.metadata.Commit(ctx, func(m *metadata.Manager) error {
+		m.MarkAutoSelected(repo, quant)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save metadata: %w", err)
+	}
+	return result, nil
+}
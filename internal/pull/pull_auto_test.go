@@ -0,0 +1,176 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newAutoQuantTestServer serves a repo tree with Q8_0 (100 bytes), Q4_K_M
+// (50 bytes), and an mmproj file, plus the manifest and download for Q4_K_M.
+func newAutoQuantTestServer(t *testing.T, content []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/tree/main"):
+			json.NewEncoder(w).Encode([]treeEntry{
+				{Type: "file", Path: "README.md", Size: 10},
+				{Type: "file", Path: "model-Q8_0.gguf", LFS: &treeLFSInfo{OID: "a", Size: 100}},
+				{Type: "file", Path: "model.Q4_K_M.gguf", LFS: &treeLFSInfo{OID: "b", Size: 50}},
+				{Type: "file", Path: "mmproj-model-f16.gguf", LFS: &treeLFSInfo{OID: "c", Size: 20}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/manifests/Q4_K_M"):
+			json.NewEncoder(w).Encode(newManifestResponse("model.Q4_K_M.gguf", int64(len(content)), computeSHA256(content)))
+		case strings.Contains(r.URL.Path, "/resolve/main/model.Q4_K_M.gguf"):
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func fixedBudget(n int64) MemoryBudgetFunc {
+	return func() (int64, error) { return n, nil }
+}
+
+func TestListQuants(t *testing.T) {
+	// Arrange
+	srv := newAutoQuantTestServer(t, nil)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	quants, err := puller.ListQuants(context.Background(), "org/model-GGUF")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ListQuants() error = %v", err)
+	}
+	if len(quants) != 2 {
+		t.Fatalf("expected 2 quants (mmproj excluded), got %+v", quants)
+	}
+	if quants[0].Quant != "Q8_0" || quants[1].Quant != "Q4_K_M" {
+		t.Errorf("quants = %+v, want Q8_0 then Q4_K_M", quants)
+	}
+}
+
+func TestListQuants_Subdirectories(t *testing.T) {
+	// Arrange
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		json.NewEncoder(w).Encode([]treeEntry{
+			{Type: "directory", Path: "BF16"},
+			{Type: "file", Path: "BF16/model-BF16-00001-of-00002.gguf", LFS: &treeLFSInfo{OID: "a", Size: 70}},
+			{Type: "file", Path: "BF16/model-BF16-00002-of-00002.gguf", LFS: &treeLFSInfo{OID: "b", Size: 60}},
+			{Type: "directory", Path: "Q8_0"},
+			{Type: "file", Path: "Q8_0/model-00001-of-00002.gguf", LFS: &treeLFSInfo{OID: "c", Size: 40}},
+			{Type: "file", Path: "Q8_0/model-00002-of-00002.gguf", LFS: &treeLFSInfo{OID: "d", Size: 30}},
+			{Type: "file", Path: "Q8_0/README.md", Size: 5},
+			{Type: "file", Path: "model-Q4_K_M.gguf", LFS: &treeLFSInfo{OID: "e", Size: 20}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	quants, err := puller.ListQuants(context.Background(), "org/big-GGUF")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ListQuants() error = %v", err)
+	}
+	if query != "recursive=true" {
+		t.Errorf("tree query = %q, want recursive=true", query)
+	}
+	want := []QuantInfo{
+		{Quant: "BF16", Filename: "BF16/model-BF16-00001-of-00002.gguf", Size: 130, Files: 2},
+		{Quant: "Q8_0", Filename: "Q8_0/model-00001-of-00002.gguf", Size: 70, Files: 2},
+		{Quant: "Q4_K_M", Filename: "model-Q4_K_M.gguf", Size: 20, Files: 1},
+	}
+	if !slices.Equal(quants, want) {
+		t.Errorf("quants = %+v, want %+v", quants, want)
+	}
+}
+
+func TestSelectQuant(t *testing.T) {
+	quants := []QuantInfo{
+		{Quant: "Q8_0", Size: 100},
+		{Quant: "Q4_K_M", Size: 50},
+	}
+	tests := []struct {
+		name    string
+		budget  int64
+		want    string
+		wantErr bool
+	}{
+		{"largest fits", 200, "Q8_0", false},
+		{"exact fit", 100, "Q8_0", false},
+		{"smaller fits", 60, "Q4_K_M", false},
+		{"nothing fits", 10, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectQuant(quants, tt.budget)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectQuant() error = %v", err)
+			}
+			if got.Quant != tt.want {
+				t.Errorf("selectQuant() = %s, want %s", got.Quant, tt.want)
+			}
+		})
+	}
+}
+
+func TestPull_AutoQuant(t *testing.T) {
+	// Arrange
+	content := []byte("q4-model")
+	srv := newAutoQuantTestServer(t, content)
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+	puller.SetMemoryBudgetFunc(fixedBudget(60))
+
+	// Act
+	result, err := puller.Pull(context.Background(), "org/model-GGUF", AutoQuant)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if result.Quant != "Q4_K_M" {
+		t.Errorf("Quant = %q, want Q4_K_M", result.Quant)
+	}
+	entry := puller.metadata.Find("org/model-GGUF", AutoQuant)
+	if entry == nil {
+		t.Fatal("repo:auto should resolve to the chosen entry")
+	}
+	if entry.Quant != "Q4_K_M" || !entry.AutoSelected {
+		t.Errorf("entry = %+v, want auto-selected Q4_K_M", entry)
+	}
+}
+
+func TestPull_AutoQuantWithoutBudget(t *testing.T) {
+	// Arrange
+	srv := newAutoQuantTestServer(t, nil)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.Pull(context.Background(), "org/model-GGUF", AutoQuant)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "memory budget unknown") {
+		t.Fatalf("expected memory budget error, got %v", err)
+	}
+}
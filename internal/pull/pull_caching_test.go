@@ -197,6 +197,80 @@ func TestPull_RedownloadsWhenFileCorrupted(t *testing.T) {
 	}
 }
 
+func TestPull_TrustsRecordedChecksumUnlessVerify(t *testing.T) {
+	tests := []struct {
+		name          string
+		verify        bool
+		wantUpToDate  bool
+		wantDownloads int32
+	}{
+		{name: "recorded checksum", verify: false, wantUpToDate: true, wantDownloads: 1},
+		{name: "verify", verify: true, wantUpToDate: false, wantDownloads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			modelContent := []byte("fake-model-binary-content")
+			modelHash := computeSHA256(modelContent)
+
+			var downloadCount atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.Contains(r.URL.Path, "/manifests/"):
+					resp := newManifestResponse("model-Q4_K_M.gguf", int64(len(modelContent)), modelHash)
+					json.NewEncoder(w).Encode(resp)
+
+				case strings.Contains(r.URL.Path, "/resolve/main/"):
+					downloadCount.Add(1)
+					w.Header().Set("Content-Length", fmt.Sprintf("%d", len(modelContent)))
+					w.WriteHeader(http.StatusOK)
+					w.Write(modelContent)
+
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			t.Cleanup(srv.Close)
+
+			tmpDir := t.TempDir()
+			puller := newTestPuller(tmpDir, srv.URL)
+			if _, err := puller.Pull(context.Background(), "test/model", "Q4_K_M"); err != nil {
+				t.Fatalf("first Pull() error = %v", err)
+			}
+
+			// Change the content but not the size or modification time, which
+			// only a full hash notices
+			path := filepath.Join(tmpDir, "model-Q4_K_M.gguf")
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("FAKE-MODEL-BINARY-CONTENT"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+				t.Fatal(err)
+			}
+			puller.SetVerify(tt.verify)
+
+			// Act
+			result, err := puller.Pull(context.Background(), "test/model", "Q4_K_M")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("second Pull() error = %v", err)
+			}
+			if result.AlreadyUpToDate != tt.wantUpToDate {
+				t.Errorf("AlreadyUpToDate = %v, want %v", result.AlreadyUpToDate, tt.wantUpToDate)
+			}
+			if got := downloadCount.Load(); got != tt.wantDownloads {
+				t.Errorf("download count = %d, want %d", got, tt.wantDownloads)
+			}
+		})
+	}
+}
+
 func TestPull_RegistersMetadataWhenFileExistsButMetadataMissing(t *testing.T) {
 	// Arrange: model file exists on disk with correct hash, but metadata is empty.
 	modelContent := []byte("fake-model-binary-content")
@@ -255,3 +329,53 @@ func TestPull_RegistersMetadataWhenFileExistsButMetadataMissing(t *testing.T) {
 		t.Errorf("metadata Filename = %q, want %q", entry.Filename, "model-Q4_K_M.gguf")
 	}
 }
+
+func TestPull_LinksContentAlreadyInBlobStore(t *testing.T) {
+	// Arrange
+	modelContent := []byte("same-weights-in-two-repos")
+	modelHash := computeSHA256(modelContent)
+
+	var downloadCount atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/first/model/manifests/"):
+			json.NewEncoder(w).Encode(newManifestResponse("first-Q4_K_M.gguf", int64(len(modelContent)), modelHash))
+		case strings.Contains(r.URL.Path, "/mirror/model/manifests/"):
+			json.NewEncoder(w).Encode(newManifestResponse("mirror-Q4_K_M.gguf", int64(len(modelContent)), modelHash))
+		case strings.Contains(r.URL.Path, "/resolve/main/"):
+			downloadCount.Add(1)
+			w.Write(modelContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+	if _, err := puller.Pull(context.Background(), "first/model", "Q4_K_M"); err != nil {
+		t.Fatalf("first Pull() error = %v", err)
+	}
+
+	// Act
+	result, err := puller.Pull(context.Background(), "mirror/model", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second Pull() error = %v", err)
+	}
+	if downloadCount.Load() != 1 {
+		t.Errorf("download count = %d, want 1", downloadCount.Load())
+	}
+	if result.Size != int64(len(modelContent)) {
+		t.Errorf("Size = %d, want %d", result.Size, len(modelContent))
+	}
+	got, err := os.ReadFile(filepath.Join(tmpDir, "mirror-Q4_K_M.gguf"))
+	if err != nil || string(got) != string(modelContent) {
+		t.Errorf("mirror-Q4_K_M.gguf = %q, %v, want linked content", got, err)
+	}
+	blobs, _ := os.ReadDir(filepath.Join(tmpDir, "blobs"))
+	if len(blobs) != 1 {
+		t.Errorf("blobs = %d, want 1", len(blobs))
+	}
+}
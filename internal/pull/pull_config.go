@@ -0,0 +1,90 @@
+package pull
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/pathutil"
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+// NewFromConfig creates a puller for modelsDir with the download settings
+// of cfg: retries, the HTTP transport, the memory headroom for :auto
+// quants, and endpoints.
+// endpoint, when set, overrides HF_ENDPOINT and the configured endpoint;
+// configured mirrors are tried after it.
+func NewFromConfig(modelsDir string, cfg *config.Config, endpoint string) (*Puller, error) {
+	puller := NewPuller(modelsDir)
+	if cfg.DownloadAttempts > 0 {
+		retry := DefaultRetryPolicy
+		retry.Attempts = cfg.DownloadAttempts
+		puller.SetRetryPolicy(retry)
+	}
+	transport, err := transportFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	puller.SetTransport(transport)
+	puller.SetMemoryBudgetFunc(func() (int64, error) {
+		return autoQuantBudget(sysinfo.DetectMemory(), cfg.AutoQuantHeadroom())
+	})
+	if endpoint == "" {
+		endpoint = os.Getenv("HF_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = cfg.HFEndpoint
+	}
+	if endpoint == "" && len(cfg.HFMirrors) == 0 {
+		return puller, nil
+	}
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	if err := puller.SetEndpoints(append([]string{endpoint}, cfg.HFMirrors...)); err != nil {
+		return nil, err
+	}
+	return puller, nil
+}
+
+// transportFromConfig returns the HTTP transport with the connection
+// settings of cfg.
+func transportFromConfig(cfg *config.Config) (*http.Transport, error) {
+	opts := transportOptions{
+		ConnectTimeout: time.Duration(cfg.DownloadConnectTimeout) * time.Second,
+		MaxIdleConns:   cfg.DownloadMaxIdleConns,
+	}
+	if cfg.DownloadTLSMinVersion != "" {
+		v, err := parseTLSVersion(cfg.DownloadTLSMinVersion)
+		if err != nil {
+			return nil, fmt.Errorf("download-tls-min-version: %w", err)
+		}
+		opts.TLSMinVersion = v
+	}
+	if cfg.DownloadCABundle != "" {
+		path, err := pathutil.ResolvePath(cfg.DownloadCABundle, "")
+		if err != nil {
+			return nil, fmt.Errorf("download-ca-bundle: %w", err)
+		}
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("download-ca-bundle: %q must be an absolute path or start with ~/", cfg.DownloadCABundle)
+		}
+		opts.CABundle = path
+	}
+	t, err := newTransport(opts)
+	if err != nil {
+		return nil, fmt.Errorf("download-ca-bundle: %w", err)
+	}
+	return t, nil
+}
+
+// autoQuantBudget returns the file size budget for org/repo:auto quants.
+func autoQuantBudget(mem sysinfo.Memory, headroom int64) (int64, error) {
+	if mem.Budget() == 0 {
+		return 0, fmt.Errorf("could not detect available memory; specify a quant explicitly")
+	}
+	return mem.Budget() - headroom, nil
+}
@@ -0,0 +1,39 @@
+package pull
+
+import (
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/sysinfo"
+)
+
+func TestAutoQuantBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		mem     sysinfo.Memory
+		want    int64
+		wantErr bool
+	}{
+		{"vram minus headroom", sysinfo.Memory{RAM: 64 << 30, VRAM: 24 << 30}, 22 << 30, false},
+		{"ram minus headroom", sysinfo.Memory{RAM: 16 << 30}, 14 << 30, false},
+		{"free memory minus headroom", sysinfo.Memory{RAM: 64 << 30, VRAM: 24 << 30, FreeVRAM: 20 << 30}, 18 << 30, false},
+		{"undetected", sysinfo.Memory{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := autoQuantBudget(tt.mem, 2<<30)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("autoQuantBudget() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("autoQuantBudget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -251,6 +252,68 @@ func TestPull_NoHashAvailable(t *testing.T) {
 	}
 }
 
+func TestPull_HashFromTree(t *testing.T) {
+	modelContent := []byte("fake-model-binary-content")
+
+	tests := []struct {
+		name      string
+		entry     treeEntry
+		wantErr   string
+		wantFetch bool
+	}{
+		{
+			name:      "lfs oid",
+			entry:     treeEntry{Type: "file", Path: "model-Q4_K_M.gguf", LFS: &treeLFSInfo{OID: computeSHA256(modelContent), Size: int64(len(modelContent))}, XetHash: "e1f0"},
+			wantFetch: true,
+		},
+		{
+			name:    "only a xet hash",
+			entry:   treeEntry{Type: "file", Path: "model-Q4_K_M.gguf", Size: int64(len(modelContent)), XetHash: "e1f0"},
+			wantErr: "only a Xet hash is published",
+		},
+		{
+			name:    "no hash",
+			entry:   treeEntry{Type: "file", Path: "model-Q4_K_M.gguf", Size: int64(len(modelContent))},
+			wantErr: "no SHA256 hash available",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			fetched := false
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.Contains(r.URL.Path, "/manifests/"):
+					// Manifest WITHOUT lfs field; the tree API has the hash
+					json.NewEncoder(w).Encode(newManifestResponse("model-Q4_K_M.gguf", int64(len(modelContent)), ""))
+				case strings.HasSuffix(r.URL.Path, "/tree/main"):
+					json.NewEncoder(w).Encode([]treeEntry{tt.entry})
+				case strings.Contains(r.URL.Path, "/resolve/main/"):
+					fetched = true
+					w.Write(modelContent)
+				}
+			}))
+			t.Cleanup(srv.Close)
+			puller := newTestPuller(t.TempDir(), srv.URL)
+
+			// Act
+			_, err := puller.Pull(context.Background(), "test/model", "Q4_K_M")
+
+			// Assert
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Pull() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Pull() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if fetched != tt.wantFetch {
+				t.Errorf("downloaded = %v, want %v", fetched, tt.wantFetch)
+			}
+		})
+	}
+}
+
 func TestPull_ManifestMissingGGUFFile(t *testing.T) {
 	// Arrange
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -484,3 +547,52 @@ func TestGetFileInfo_WithoutMmproj(t *testing.T) {
 		t.Errorf("MmprojSize = %d, want 0", info.MmprojSize)
 	}
 }
+
+func TestPull_ConcurrentClones(t *testing.T) {
+	// Arrange
+	quants := []string{"Q4_K_M", "Q5_K_M", "Q8_0"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			quant := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			content := []byte("model-" + quant)
+			resp := newManifestResponse("model-"+quant+".gguf", int64(len(content)), computeSHA256(content))
+			json.NewEncoder(w).Encode(resp)
+
+		case strings.Contains(r.URL.Path, "/resolve/main/"):
+			name := strings.TrimSuffix(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], ".gguf")
+			w.Write([]byte(name))
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := t.TempDir()
+	base := newTestPuller(tmpDir, srv.URL)
+
+	// Act
+	var wg sync.WaitGroup
+	for _, quant := range quants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := base.Clone().Pull(context.Background(), "test/model", quant); err != nil {
+				t.Errorf("Pull(%s) error = %v", quant, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	reloaded := NewPuller(tmpDir)
+	if err := reloaded.metadata.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, quant := range quants {
+		if reloaded.metadata.Find("test/model", quant) == nil {
+			t.Errorf("metadata entry for %s missing", quant)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// draftSizes lists candidate parameter counts (in billions) for draft models,
+// smallest first. Common small sizes published alongside larger family members.
+var draftSizes = []string{"0.5", "0.6", "1", "1.5", "1.7", "2", "3"}
+
+// sizeTokenPattern matches a parameter-count token such as "8B" or "1.5b"
+// delimited by '-', '_' or the string boundaries.
+var sizeTokenPattern = regexp.MustCompile(`(^|[-_])(\d+(?:\.\d+)?)([bB])([-_]|$)`)
+
+// FindDraftModel looks for a smaller model from the same family that can be
+// used as a draft model for speculative decoding.
+// Candidates are derived by replacing the parameter-count token in the repo
+// name (e.g. Qwen3-8B-GGUF → Qwen3-0.6B-GGUF) and probed via the manifest API
+// with the same quant. Returns the first candidate that exists as h:repo:quant.
+func (p *Puller) FindDraftModel(ctx context.Context, repo, quant string) (string, error) {
+	candidates := draftCandidates(repo)
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("cannot infer model size from repository name '%s'", repo)
+	}
+
+	for _, candidate := range candidates {
+		if _, err := p.fetchManifest(ctx, candidate, quant); err != nil {
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+			continue
+		}
+		return fmt.Sprintf("h:%s:%s", candidate, quant), nil
+	}
+	return "", fmt.Errorf("no draft model found for h:%s:%s", repo, quant)
+}
+
+// draftCandidates returns candidate draft repos for repo, smallest first.
+// Only sizes strictly smaller than the model's own size are returned.
+func draftCandidates(repo string) []string {
+	org, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil
+	}
+
+	loc := sizeTokenPattern.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return nil
+	}
+	sizeStr := name[loc[4]:loc[5]]
+	size, err := strconv.ParseFloat(sizeStr, 64)
+	if err != nil {
+		return nil
+	}
+	unit := name[loc[6]:loc[7]]
+
+	var candidates []string
+	for _, ds := range draftSizes {
+		d, _ := strconv.ParseFloat(ds, 64)
+		if d >= size {
+			break
+		}
+		candidates = append(candidates, org+"/"+name[:loc[4]]+ds+unit+name[loc[7]:])
+	}
+	return candidates
+}
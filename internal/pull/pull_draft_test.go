@@ -0,0 +1,104 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDraftCandidates(t *testing.T) {
+	tests := []struct {
+		name string
+		repo string
+		want []string
+	}{
+		{
+			name: "uppercase size token",
+			repo: "Qwen/Qwen3-8B-GGUF",
+			want: []string{
+				"Qwen/Qwen3-0.5B-GGUF", "Qwen/Qwen3-0.6B-GGUF", "Qwen/Qwen3-1B-GGUF",
+				"Qwen/Qwen3-1.5B-GGUF", "Qwen/Qwen3-1.7B-GGUF", "Qwen/Qwen3-2B-GGUF", "Qwen/Qwen3-3B-GGUF",
+			},
+		},
+		{
+			name: "lowercase size token keeps case",
+			repo: "org/coder-1.5b-instruct",
+			want: []string{"org/coder-0.5b-instruct", "org/coder-0.6b-instruct", "org/coder-1b-instruct"},
+		},
+		{
+			name: "smallest size has no candidates",
+			repo: "org/model-0.5B-GGUF",
+			want: nil,
+		},
+		{
+			name: "no size token",
+			repo: "org/some-model-GGUF",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := draftCandidates(tt.repo)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("draftCandidates(%q) = %v, want %v", tt.repo, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDraftModel(t *testing.T) {
+	// Arrange: only the 1.7B variant exists
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/Qwen/Qwen3-1.7B-GGUF/manifests/Q4_K_M") {
+			json.NewEncoder(w).Encode(newManifestResponse("qwen3-1.7b-q4_k_m.gguf", 100, "abc"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	got, err := puller.FindDraftModel(context.Background(), "Qwen/Qwen3-8B-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("FindDraftModel() error = %v", err)
+	}
+	if got != "h:Qwen/Qwen3-1.7B-GGUF:Q4_K_M" {
+		t.Errorf("FindDraftModel() = %q, want h:Qwen/Qwen3-1.7B-GGUF:Q4_K_M", got)
+	}
+}
+
+func TestFindDraftModel_NotFound(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.FindDraftModel(context.Background(), "Qwen/Qwen3-8B-GGUF", "Q4_K_M")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "no draft model found") {
+		t.Fatalf("expected no draft model error, got %v", err)
+	}
+}
+
+func TestFindDraftModel_NoSizeToken(t *testing.T) {
+	// Arrange
+	puller := newTestPuller(t.TempDir(), "http://unused")
+
+	// Act
+	_, err := puller.FindDraftModel(context.Background(), "org/model-GGUF", "Q4_K_M")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "cannot infer model size") {
+		t.Fatalf("expected size inference error, got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package pull
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// maxExtraFileSize bounds the files PullFiles downloads: they are tokenizer
+// and config files, not weights.
+const maxExtraFileSize = 64 << 20
+
+// ExtraFile is a repository file downloaded next to a model.
+type ExtraFile struct {
+	File            string // path in the repository
+	Path            string // where it is stored
+	Size            int64
+	AlreadyUpToDate bool
+}
+
+// PullFiles downloads the files at the root of repo that match any of
+// patterns (path.Match syntax, e.g. "*.json") next to the downloaded model
+// repo:quant, and records them in its metadata entry. GGUF files never
+// match; they are pulled as models, mmproj files or LoRA adapters.
+// Files are verified like models: by their LFS SHA256, or by their git
+// blob hash for files stored in git itself.
+func (p *Puller) PullFiles(ctx context.Context, repo, quant string, patterns []string) ([]ExtraFile, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+
+	if err := p.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	entry := p.metadata.Find(repo, quant)
+	if entry == nil {
+		return nil, &metadata.NotFoundError{Repo: repo, Quant: quant}
+	}
+
+	tree, err := p.fetchRepoTree(ctx, repo, "", false)
+	if err != nil {
+		return nil, err
+	}
+	matched := matchRepoFiles(tree, patterns)
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no files in repository '%s' match %s", repo, strings.Join(patterns, ", "))
+	}
+	for _, e := range matched {
+		if e.fileSize() > maxExtraFileSize {
+			return nil, fmt.Errorf("file '%s' is too large (%d bytes); only files up to %d MB are pulled alongside a model", e.Path, e.fileSize(), maxExtraFileSize>>20)
+		}
+	}
+
+	var results []ExtraFile
+	var entries []metadata.ExtraFileEntry
+	for i, e := range matched {
+		filename := extraStorageFilename(repo, e.Path)
+		if !filepath.IsLocal(filename) {
+			return nil, fmt.Errorf("invalid filename from API: %s", e.Path)
+		}
+		destPath := filepath.Join(p.modelsDir, filename)
+
+		result := ExtraFile{File: e.Path, Path: destPath, Size: e.fileSize()}
+		if p.verifyRepoFile(filename, e) == nil {
+			result.AlreadyUpToDate = true
+		} else {
+			p.startTransfer(PhaseFile, e.Path)
+			if p.onFileStart != nil {
+				p.onFileStart(e.Path, e.fileSize(), i+1, len(matched))
+			}
+			size, err := p.downloadRepoFile(ctx, repo, e, filename)
+			if err != nil {
+				return nil, err
+			}
+			p.reportDone(size)
+			if p.onFileSaved != nil {
+				p.onFileSaved(destPath)
+			}
+			result.Size = size
+		}
+		results = append(results, result)
+		entries = append(entries, metadata.ExtraFileEntry{File: e.Path, Filename: filename, Size: result.Size})
+	}
+
+	err = p.metadata.Commit(ctx, func(m *metadata.Manager) error {
+		current := m.Find(repo, entry.Quant)
+		if current == nil {
+			return &metadata.NotFoundError{Repo: repo, Quant: entry.Quant}
+		}
+		current.ExtraFiles = mergeExtraFiles(current.ExtraFiles, entries)
+		return m.Add(*current)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save metadata: %w", err)
+	}
+	return results, nil
+}
+
+// matchRepoFiles returns the files of tree whose name matches any of
+// patterns, excluding GGUF files.
+func matchRepoFiles(tree []treeEntry, patterns []string) []treeEntry {
+	var matched []treeEntry
+	for _, e := range tree {
+		if e.Type != "file" || strings.HasSuffix(strings.ToLower(e.Path), ".gguf") {
+			continue
+		}
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			ok, _ := path.Match(pattern, e.Path)
+			return ok
+		}) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// mergeExtraFiles returns existing with the entries of added replacing
+// those with the same filename.
+func mergeExtraFiles(existing, added []metadata.ExtraFileEntry) []metadata.ExtraFileEntry {
+	merged := slices.DeleteFunc(slices.Clone(existing), func(x metadata.ExtraFileEntry) bool {
+		return slices.ContainsFunc(added, func(a metadata.ExtraFileEntry) bool { return a.Filename == x.Filename })
+	})
+	return append(merged, added...)
+}
+
+// extraStorageFilename generates a repo-prefixed storage filename for extra
+// files, following the same scheme as mmproj files.
+func extraStorageFilename(repo, file string) string {
+	return mmprojStorageFilename(repo, file)
+}
+
+// fileSize returns the size of the file's content, which for LFS files is
+// not the size of the pointer stored in git.
+func (e treeEntry) fileSize() int64 {
+	if e.LFS != nil && e.LFS.Size > 0 {
+		return e.LFS.Size
+	}
+	return e.Size
+}
+
+// downloadRepoFile downloads e as filename and verifies it (fail-closed),
+// then moves it into the blob store.
+func (p *Puller) downloadRepoFile(ctx context.Context, repo string, e treeEntry, filename string) (int64, error) {
+	if e.LFS != nil && e.LFS.OID != "" {
+		return p.downloadVerified(ctx, repo, shardFile{Path: e.Path, Filename: filename, SHA256: e.LFS.OID, Size: e.fileSize()})
+	}
+	if e.XetHash != "" {
+		return 0, integrityError(e.Path, errXetOnly)
+	}
+	if e.OID == "" {
+		return 0, integrityError(e.Path, errNoHash)
+	}
+	size, err := p.downloadURL(ctx, p.fileURL(repo, e.Path, ""), filename)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.verifyRepoFile(filename, e); err != nil {
+		p.removeDownloadedFile(filename)
+		return 0, integrityError(e.Path, err)
+	}
+	sum, err := p.fileSHA256(filename)
+	if err != nil {
+		p.removeDownloadedFile(filename)
+		return 0, err
+	}
+	if err := p.blobs.Adopt(filename, sum); err != nil {
+		p.removeDownloadedFile(filename)
+		return 0, err
+	}
+	return size, nil
+}
+
+// verifyRepoFile checks filename in the models directory against the hash
+// the tree API lists for e.
+func (p *Puller) verifyRepoFile(filename string, e treeEntry) error {
+	if e.LFS != nil && e.LFS.OID != "" {
+		return p.verifyFileHash(filename, e.LFS.OID)
+	}
+	if e.XetHash != "" {
+		return errXetOnly
+	}
+	actual, err := p.gitBlobSHA1(filename)
+	if err != nil {
+		return err
+	}
+	if actual != e.OID {
+		return fmt.Errorf("expected git blob %s, got %s", e.OID, actual)
+	}
+	return nil
+}
+
+// gitBlobSHA1 returns the hex-encoded git object ID of a file in the models
+// directory: the SHA-1 of "blob <size>\x00" followed by its content.
+func (p *Puller) gitBlobSHA1(filename string) (string, error) {
+	root, err := os.OpenRoot(p.modelsDir)
+	if err != nil {
+		return "", fmt.Errorf("open models dir: %w", err)
+	}
+	defer root.Close()
+
+	f, err := root.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("open file for verification: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("stat file for verification: %w", err)
+	}
+
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", info.Size())
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("compute hash: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
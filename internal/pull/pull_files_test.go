@@ -0,0 +1,199 @@
+package pull
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// gitBlobID returns the git object ID of content.
+func gitBlobID(content []byte) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// newFilesTestServer serves a repo tree listing entries and, for each
+// path in files, its content. The counter tracks file downloads.
+func newFilesTestServer(t *testing.T, entries []treeEntry, files map[string][]byte) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/tree/main") {
+			json.NewEncoder(w).Encode(entries)
+			return
+		}
+		_, file, ok := strings.Cut(r.URL.Path, "/resolve/main/")
+		content, found := files[file]
+		if !ok || !found {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+		w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &downloads
+}
+
+// newFilesTestPuller returns a puller whose metadata lists org/repo:Q4_K_M.
+func newFilesTestPuller(t *testing.T, baseURL string) (*Puller, string) {
+	t.Helper()
+	dir := t.TempDir()
+	p := newTestPuller(dir, baseURL)
+	p.metadata.Add(metadata.ModelEntry{Repo: "org/repo", Quant: "Q4_K_M", Filename: "model-Q4_K_M.gguf", DownloadedAt: time.Now()})
+	if err := p.metadata.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return p, dir
+}
+
+func TestPullFiles(t *testing.T) {
+	// Arrange
+	config := []byte(`{"bos_token": "<s>"}`)
+	template := []byte("{{ messages }}")
+	srv, _ := newFilesTestServer(t, []treeEntry{
+		{Type: "file", Path: "model-Q4_K_M.gguf", Size: 100, LFS: &treeLFSInfo{OID: "abc", Size: 100}},
+		{Type: "file", Path: "tokenizer_config.json", OID: gitBlobID(config), Size: int64(len(config))},
+		{Type: "file", Path: "chat_template.jinja", Size: 130, LFS: &treeLFSInfo{OID: computeSHA256(template), Size: int64(len(template))}},
+		{Type: "file", Path: "README.md", OID: "0000", Size: 10},
+	}, map[string][]byte{"tokenizer_config.json": config, "chat_template.jinja": template})
+	puller, dir := newFilesTestPuller(t, srv.URL)
+
+	// Act
+	files, err := puller.PullFiles(context.Background(), "org/repo", "Q4_K_M", []string{"*.json", "*.jinja", "*.gguf"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PullFiles() error = %v", err)
+	}
+	if len(files) != 2 || files[0].File != "tokenizer_config.json" || files[1].File != "chat_template.jinja" {
+		t.Fatalf("PullFiles() = %+v, want the config and template only", files)
+	}
+	for _, f := range files {
+		if filepath.Dir(f.Path) != dir || !strings.HasPrefix(filepath.Base(f.Path), "org_repo_") {
+			t.Errorf("Path = %s, want a repo-prefixed file in the models directory", f.Path)
+		}
+	}
+	got, err := os.ReadFile(files[0].Path)
+	if err != nil || string(got) != string(config) {
+		t.Errorf("tokenizer_config.json content = %q, %v", got, err)
+	}
+	entry := puller.metadata.Find("org/repo", "Q4_K_M")
+	want := []metadata.ExtraFileEntry{
+		{File: "tokenizer_config.json", Filename: "org_repo_tokenizer_config.json", Size: int64(len(config))},
+		{File: "chat_template.jinja", Filename: "org_repo_chat_template.jinja", Size: int64(len(template))},
+	}
+	if entry == nil || fmt.Sprint(entry.ExtraFiles) != fmt.Sprint(want) {
+		t.Errorf("ExtraFiles = %+v, want %+v", entry, want)
+	}
+}
+
+func TestPullFiles_AlreadyUpToDate(t *testing.T) {
+	// Arrange
+	config := []byte(`{}`)
+	srv, downloads := newFilesTestServer(t, []treeEntry{
+		{Type: "file", Path: "config.json", OID: gitBlobID(config), Size: int64(len(config))},
+	}, map[string][]byte{"config.json": config})
+	puller, _ := newFilesTestPuller(t, srv.URL)
+	if _, err := puller.PullFiles(context.Background(), "org/repo", "Q4_K_M", []string{"*.json"}); err != nil {
+		t.Fatalf("first PullFiles() error = %v", err)
+	}
+
+	// Act
+	files, err := puller.PullFiles(context.Background(), "org/repo", "Q4_K_M", []string{"*.json"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PullFiles() error = %v", err)
+	}
+	if len(files) != 1 || !files[0].AlreadyUpToDate {
+		t.Errorf("PullFiles() = %+v, want config.json up to date", files)
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("downloads = %d, want 1", n)
+	}
+	if entry := puller.metadata.Find("org/repo", "Q4_K_M"); len(entry.ExtraFiles) != 1 {
+		t.Errorf("ExtraFiles = %+v, want one entry", entry.ExtraFiles)
+	}
+}
+
+func TestPullFiles_Errors(t *testing.T) {
+	config := []byte(`{}`)
+	tests := []struct {
+		name     string
+		entries  []treeEntry
+		quant    string
+		patterns []string
+		wantErr  string
+	}{
+		{
+			name:     "model not pulled",
+			quant:    "Q8_0",
+			patterns: []string{"*.json"},
+			wantErr:  "not found",
+		},
+		{
+			name:     "no match",
+			entries:  []treeEntry{{Type: "file", Path: "model.gguf", LFS: &treeLFSInfo{OID: "abc"}}},
+			patterns: []string{"*.gguf"},
+			wantErr:  "no files in repository 'org/repo' match *.gguf",
+		},
+		{
+			name:     "bad pattern",
+			patterns: []string{"["},
+			wantErr:  "invalid file pattern",
+		},
+		{
+			name:     "too large",
+			entries:  []treeEntry{{Type: "file", Path: "tokenizer.json", Size: 130, LFS: &treeLFSInfo{OID: "abc", Size: maxExtraFileSize + 1}}},
+			patterns: []string{"*.json"},
+			wantErr:  "too large",
+		},
+		{
+			name:     "hash mismatch",
+			entries:  []treeEntry{{Type: "file", Path: "config.json", OID: gitBlobID([]byte("other")), Size: int64(len(config))}},
+			patterns: []string{"*.json"},
+			wantErr:  "integrity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv, _ := newFilesTestServer(t, tt.entries, map[string][]byte{"config.json": config})
+			puller, dir := newFilesTestPuller(t, srv.URL)
+			quant := tt.quant
+			if quant == "" {
+				quant = "Q4_K_M"
+			}
+
+			// Act
+			_, err := puller.PullFiles(context.Background(), "org/repo", quant, tt.patterns)
+
+			// Assert
+			if err == nil || !strings.Contains(strings.ToLower(err.Error()), tt.wantErr) {
+				t.Fatalf("PullFiles() error = %v, want %q", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "org_repo_config.json")); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("unverified file should not be kept, stat error = %v", err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 func newTestPuller(modelsDir, baseURL string) *Puller {
 	p := NewPuller(modelsDir)
 	p.baseURL = baseURL
+	p.retry.BaseDelay, p.retry.MaxDelay = 0, 0 // retry without waiting
 	return p
 }
 
@@ -0,0 +1,259 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// treeEntry represents a file entry from the HuggingFace tree API. Other
+// registries' file lists are converted to it.
+type treeEntry struct {
+	Type    string       `json:"type"` // "file" or "directory"
+	OID     string       `json:"oid"`  // git blob SHA-1
+	Path    string       `json:"path"`
+	Size    int64        `json:"size"`
+	LFS     *treeLFSInfo `json:"lfs"`
+	XetHash string       `json:"xetHash"` // Xet content hash, for files stored with Xet
+}
+
+// treeLFSInfo represents the LFS metadata of a tree entry.
+// The oid of an LFS object is its SHA256 hash.
+type treeLFSInfo struct {
+	OID  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// sha256 returns the SHA256 of the file's content. Files stored with Xet
+// usually list it as their LFS oid too; one listing only its Xet hash
+// cannot be verified.
+func (e treeEntry) sha256() (string, error) {
+	switch {
+	case e.LFS != nil && e.LFS.OID != "":
+		return e.LFS.OID, nil
+	case e.XetHash != "":
+		return "", errXetOnly
+	default:
+		return "", errNoHash
+	}
+}
+
+// filesByPath indexes the files of a tree listing by path.
+func filesByPath(entries []treeEntry) map[string]treeEntry {
+	byPath := make(map[string]treeEntry, len(entries))
+	for _, e := range entries {
+		if e.Type == "file" {
+			byPath[e.Path] = e
+		}
+	}
+	return byPath
+}
+
+// fetchRepoTree lists the files in dir of a repository.
+// An empty dir lists the repository root. Entry paths are relative to the root.
+// recursive also lists the files in subdirectories.
+func (p *Puller) fetchRepoTree(ctx context.Context, repo, dir string, recursive bool) ([]treeEntry, error) {
+	var entries []treeEntry
+	err := p.tryEndpoints(func(baseURL string) error {
+		var err error
+		entries, err = p.registry.listFiles(ctx, p.client, baseURL, repo, dir, recursive)
+		return err
+	})
+	return entries, err
+}
+
+func (huggingFace) listFiles(ctx context.Context, client *http.Client, baseURL, repo, dir string, recursive bool) ([]treeEntry, error) {
+	url := fmt.Sprintf("%s/api/models/%s/tree/main", baseURL, repo)
+	if dir != "" {
+		url += "/" + dir
+	}
+	if recursive {
+		url += "?recursive=true"
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("fetch file list: %w", err)
+		}
+		return nil, &endpointUnavailableError{fmt.Errorf("fetch file list: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("repository not found: %s", repo)
+	}
+	if isUnavailableStatus(resp.StatusCode) {
+		return nil, &endpointUnavailableError{fmt.Errorf("failed to fetch file list: status %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch file list: status %d", resp.StatusCode)
+	}
+
+	var entries []treeEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("parse file list: %w", err)
+	}
+	return entries, nil
+}
+
+// findRepoFile looks up a single file at the root of a repository.
+func (p *Puller) findRepoFile(ctx context.Context, repo, file string) (treeEntry, error) {
+	entries, err := p.fetchRepoTree(ctx, repo, "", false)
+	if err != nil {
+		return treeEntry{}, err
+	}
+	for _, e := range entries {
+		if e.Type == "file" && e.Path == file {
+			return e, nil
+		}
+	}
+	return treeEntry{}, fmt.Errorf("file '%s' not found in repository '%s'", file, repo)
+}
+
+// GetLoraInfo fetches the storage filename and size of a LoRA adapter without downloading.
+func (p *Puller) GetLoraInfo(ctx context.Context, repo, file string) (*FileInfo, error) {
+	info, err := p.findRepoFile(ctx, repo, file)
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size
+	if info.LFS != nil && info.LFS.Size > 0 {
+		size = info.LFS.Size
+	}
+	return &FileInfo{
+		Filename: loraStorageFilename(repo, file),
+		Size:     size,
+	}, nil
+}
+
+// PullLora downloads a LoRA adapter file from HuggingFace.
+// The adapter is stored with a repo-prefixed filename and registered in
+// metadata keyed by repo + file.
+func (p *Puller) PullLora(ctx context.Context, repo, file string) (*PullResult, error) {
+	if strings.Contains(file, "/") || !filepath.IsLocal(file) {
+		return nil, fmt.Errorf("invalid lora filename: %s (must be a file at the repository root)", file)
+	}
+
+	if err := p.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+
+	info, err := p.findRepoFile(ctx, repo, file)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := info.sha256()
+	if err != nil {
+		return nil, integrityError(file, err)
+	}
+	size := info.fileSize()
+
+	storageFilename := loraStorageFilename(repo, file)
+	destPath := filepath.Join(p.modelsDir, storageFilename)
+
+	// Already downloaded and unchanged upstream
+	if existing := p.metadata.FindLora(repo, file); existing != nil && existing.Filename == storageFilename {
+		if st, statErr := os.Stat(destPath); statErr == nil {
+			if p.hashMatches(ctx, storageFilename, sum) {
+				return &PullResult{
+					Path:            destPath,
+					Filename:        storageFilename,
+					Size:            st.Size(),
+					AlreadyUpToDate: true,
+				}, nil
+			}
+		}
+	}
+
+	p.startTransfer(PhaseLora, file)
+	if p.onFileStart != nil {
+		p.onFileStart(file, size, 1, 1)
+	}
+
+	written, stored, err := p.linkStored(storageFilename, sum)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		written, err = p.downloadLora(ctx, repo, file, storageFilename, sum)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.reportDone(written)
+	if p.onFileSaved != nil {
+		p.onFileSaved(destPath)
+	}
+
+	entry := metadata.LoraEntry{
+		Repo:         repo,
+		File:         file,
+		Filename:     storageFilename,
+		Size:         written,
+		DownloadedAt: time.Now().UTC(),
+	}
+	err = p.metadata.Commit(ctx, func(m *metadata.Manager) error {
+		m.AddLora(entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("save metadata: %w", err)
+	}
+
+	return &PullResult{
+		Path:     destPath,
+		Filename: storageFilename,
+		Size:     written,
+	}, nil
+}
+
+// downloadLora downloads an adapter, stores it under its repo-prefixed name,
+// verifies it, and moves it into the blob store.
+func (p *Puller) downloadLora(ctx context.Context, repo, file, storageFilename, sum string) (int64, error) {
+	written, err := p.downloadFile(ctx, repo, file)
+	if err != nil {
+		return 0, err
+	}
+
+	root, err := os.OpenRoot(p.modelsDir)
+	if err != nil {
+		p.removeDownloadedFile(file)
+		return 0, fmt.Errorf("open models dir for lora rename: %w", err)
+	}
+	defer root.Close()
+	if err := root.Rename(file, storageFilename); err != nil {
+		p.removeDownloadedFile(file)
+		return 0, fmt.Errorf("rename lora file: %w", err)
+	}
+
+	if err := p.verifyFileHash(storageFilename, sum); err != nil {
+		p.removeDownloadedFile(storageFilename)
+		return 0, integrityError(file, err)
+	}
+	if err := p.blobs.Adopt(storageFilename, sum); err != nil {
+		p.removeDownloadedFile(storageFilename)
+		return 0, err
+	}
+	p.recordChecksum(ctx, storageFilename, sum)
+	return written, nil
+}
+
+// loraStorageFilename generates a repo-prefixed storage filename for LoRA
+// adapters, following the same scheme as mmproj files.
+func loraStorageFilename(repo, file string) string {
+	return mmprojStorageFilename(repo, file)
+}
@@ -0,0 +1,170 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newLoraTestServer creates a test server that serves a repo tree listing and a single adapter file.
+// The returned counter tracks how many times the adapter file was downloaded.
+func newLoraTestServer(t *testing.T, file string, content []byte, sha string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/tree/main"):
+			json.NewEncoder(w).Encode([]treeEntry{
+				{Type: "file", Path: "README.md", Size: 10},
+				{Type: "file", Path: file, Size: int64(len(content)), LFS: &treeLFSInfo{OID: sha, Size: int64(len(content))}},
+			})
+		case strings.HasSuffix(r.URL.Path, "/resolve/main/"+file):
+			downloads.Add(1)
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+			w.WriteHeader(http.StatusOK)
+			w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &downloads
+}
+
+func TestPullLora_Success(t *testing.T) {
+	// Arrange
+	content := []byte("fake-lora-adapter")
+	srv, _ := newLoraTestServer(t, "adapter.gguf", content, computeSHA256(content))
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+
+	// Act
+	result, err := puller.PullLora(context.Background(), "org/lora-repo", "adapter.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PullLora() error = %v", err)
+	}
+	wantFilename := "org_lora-repo_adapter.gguf"
+	if result.Filename != wantFilename {
+		t.Errorf("Filename = %q, want %q", result.Filename, wantFilename)
+	}
+	got, err := os.ReadFile(filepath.Join(tmpDir, wantFilename))
+	if err != nil {
+		t.Fatalf("read adapter: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Error("adapter content mismatch")
+	}
+	entry := puller.metadata.FindLora("org/lora-repo", "adapter.gguf")
+	if entry == nil {
+		t.Fatal("lora metadata entry not found")
+	}
+	if entry.Filename != wantFilename || entry.Size != int64(len(content)) {
+		t.Errorf("entry = %+v, want filename %q size %d", entry, wantFilename, len(content))
+	}
+}
+
+func TestPullLora_AlreadyUpToDate(t *testing.T) {
+	// Arrange
+	content := []byte("fake-lora-adapter")
+	srv, downloads := newLoraTestServer(t, "adapter.gguf", content, computeSHA256(content))
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+	if _, err := puller.PullLora(context.Background(), "org/lora-repo", "adapter.gguf"); err != nil {
+		t.Fatalf("first PullLora() error = %v", err)
+	}
+
+	// Act
+	result, err := puller.PullLora(context.Background(), "org/lora-repo", "adapter.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second PullLora() error = %v", err)
+	}
+	if !result.AlreadyUpToDate {
+		t.Error("AlreadyUpToDate = false, want true")
+	}
+	if downloads.Load() != 1 {
+		t.Errorf("downloads = %d, want 1", downloads.Load())
+	}
+}
+
+func TestPullLora_HashMismatch(t *testing.T) {
+	// Arrange
+	content := []byte("fake-lora-adapter")
+	srv, _ := newLoraTestServer(t, "adapter.gguf", content, computeSHA256([]byte("other")))
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+
+	// Act
+	_, err := puller.PullLora(context.Background(), "org/lora-repo", "adapter.gguf")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "integrity verification failed") {
+		t.Fatalf("expected integrity error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "org_lora-repo_adapter.gguf")); !os.IsNotExist(statErr) {
+		t.Error("adapter file should be removed after hash mismatch")
+	}
+}
+
+func TestPullLora_FileNotInRepo(t *testing.T) {
+	// Arrange
+	content := []byte("fake-lora-adapter")
+	srv, _ := newLoraTestServer(t, "adapter.gguf", content, computeSHA256(content))
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.PullLora(context.Background(), "org/lora-repo", "missing.gguf")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "not found in repository") {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestPullLora_RejectsSubdirectory(t *testing.T) {
+	// Arrange
+	puller := newTestPuller(t.TempDir(), "http://unused")
+
+	// Act
+	_, err := puller.PullLora(context.Background(), "org/lora-repo", "sub/adapter.gguf")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "repository root") {
+		t.Fatalf("expected repository root error, got %v", err)
+	}
+}
+
+func TestGetLoraInfo(t *testing.T) {
+	// Arrange
+	content := []byte("fake-lora-adapter")
+	srv, downloads := newLoraTestServer(t, "adapter.gguf", content, computeSHA256(content))
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	info, err := puller.GetLoraInfo(context.Background(), "org/lora-repo", "adapter.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("GetLoraInfo() error = %v", err)
+	}
+	if info.Filename != "org_lora-repo_adapter.gguf" {
+		t.Errorf("Filename = %q", info.Filename)
+	}
+	if info.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", info.Size, len(content))
+	}
+	if downloads.Load() != 0 {
+		t.Error("GetLoraInfo should not download the adapter")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/d2verb/alpaca/internal/metadata"
@@ -51,8 +52,21 @@ func (p *Puller) downloadMmproj(ctx context.Context, repo string, fileInfo ggufF
 		return nil, fmt.Errorf("invalid mmproj storage filename: %s", fileInfo.MmprojFilename)
 	}
 
+	// Shared with another model: link the stored copy
+	if size, ok, err := p.linkStored(fileInfo.MmprojFilename, fileInfo.MmprojSHA256); err != nil {
+		return nil, fmt.Errorf("link mmproj: %w", err)
+	} else if ok {
+		return &metadata.MmprojEntry{
+			Filename: fileInfo.MmprojFilename,
+			Size:     size,
+			File:     fileInfo.MmprojOriginalFilename,
+			SHA256:   fileInfo.MmprojSHA256,
+		}, nil
+	}
+
 	// Download mmproj file using the original filename for the URL path
-	size, err := p.downloadFile(ctx, repo, fileInfo.MmprojOriginalFilename)
+	url := p.fileURL(repo, fileInfo.MmprojOriginalFilename, fileInfo.MmprojSHA256)
+	size, err := p.downloadURL(ctx, url, fileInfo.MmprojOriginalFilename)
 	if err != nil {
 		return nil, fmt.Errorf("download mmproj: %w", err)
 	}
@@ -75,16 +89,23 @@ func (p *Puller) downloadMmproj(ctx context.Context, repo string, fileInfo ggufF
 	// Verify SHA256 integrity (fail-closed)
 	if fileInfo.MmprojSHA256 == "" {
 		p.removeDownloadedFile(fileInfo.MmprojFilename)
-		return nil, fmt.Errorf("integrity verification failed for %s: no SHA256 hash available from API", fileInfo.MmprojFilename)
+		return nil, integrityError(fileInfo.MmprojFilename, errNoHash)
 	}
 	if err := p.verifyFileHash(fileInfo.MmprojFilename, fileInfo.MmprojSHA256); err != nil {
 		p.removeDownloadedFile(fileInfo.MmprojFilename)
-		return nil, fmt.Errorf("integrity verification failed for %s: %w", fileInfo.MmprojFilename, err)
+		return nil, integrityError(fileInfo.MmprojFilename, err)
+	}
+	if err := p.blobs.Adopt(fileInfo.MmprojFilename, fileInfo.MmprojSHA256); err != nil {
+		p.removeDownloadedFile(fileInfo.MmprojFilename)
+		return nil, err
 	}
+	p.recordChecksum(ctx, fileInfo.MmprojFilename, fileInfo.MmprojSHA256)
 
 	return &metadata.MmprojEntry{
 		Filename: fileInfo.MmprojFilename,
 		Size:     size,
+		File:     fileInfo.MmprojOriginalFilename,
+		SHA256:   fileInfo.MmprojSHA256,
 	}, nil
 }
 
@@ -96,3 +117,98 @@ func mmprojStorageFilename(repo, originalFilename string) string {
 	prefix := strings.ReplaceAll(repo, "/", "_")
 	return prefix + "_" + originalFilename
 }
+
+// PullMmproj downloads only the mmproj of a model that is already
+// downloaded, e.g. one pulled before its repository had an mmproj or whose
+// mmproj download failed. The result has no MmprojFilename when the
+// manifest lists no mmproj.
+func (p *Puller) PullMmproj(ctx context.Context, repo, quant string) (*PullResult, error) {
+	if err := p.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	existing := p.metadata.Find(repo, quant)
+	if existing == nil {
+		return nil, &metadata.NotFoundError{Repo: repo, Quant: quant}
+	}
+
+	fileInfo, err := p.fetchManifest(ctx, repo, existing.Quant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PullResult{
+		Path:     filepath.Join(p.modelsDir, existing.Filename),
+		Filename: existing.Filename,
+		Size:     existing.Size,
+	}
+	switch {
+	case fileInfo.MmprojFilename == "":
+		// Nothing to fetch; forget a failure for an mmproj that is gone
+		if existing.MmprojFailed {
+			entry := *existing
+			entry.MmprojFailed = false
+			if err := p.commitEntry(ctx, entry); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case existing.Mmproj != nil && existing.Mmproj.Filename == fileInfo.MmprojFilename &&
+		p.hashMatches(ctx, fileInfo.MmprojFilename, fileInfo.MmprojSHA256):
+		result.MmprojFilename = existing.Mmproj.Filename
+		result.MmprojSize = existing.Mmproj.Size
+		result.AlreadyUpToDate = true
+		return result, nil
+	}
+	return p.pullMmprojOnly(ctx, *existing, fileInfo)
+}
+
+// pullMmprojOnly downloads the mmproj in fileInfo for the downloaded model
+// entry and records it. When the download fails, the failure is recorded
+// and the error is returned along with a result that has MmprojFailed set.
+func (p *Puller) pullMmprojOnly(ctx context.Context, entry metadata.ModelEntry, fileInfo ggufFileInfo) (*PullResult, error) {
+	p.cleanupOldMmproj(entry.Repo, entry.Quant, fileInfo.MmprojFilename)
+
+	p.startTransfer(PhaseMmproj, fileInfo.MmprojOriginalFilename)
+	if p.onFileStart != nil {
+		p.onFileStart(fileInfo.MmprojOriginalFilename, fileInfo.MmprojSize, 1, 1)
+	}
+	mmproj, dlErr := p.downloadMmproj(ctx, entry.Repo, fileInfo)
+	if dlErr == nil {
+		p.reportDone(mmproj.Size)
+		if p.onFileSaved != nil {
+			p.onFileSaved(filepath.Join(p.modelsDir, fileInfo.MmprojFilename))
+		}
+	}
+
+	entry.Mmproj = mmproj
+	entry.MmprojFailed = dlErr != nil
+	if err := p.commitEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	result := &PullResult{
+		Path:         filepath.Join(p.modelsDir, entry.Filename),
+		Filename:     entry.Filename,
+		Size:         entry.Size,
+		MmprojFailed: dlErr != nil,
+	}
+	if mmproj != nil {
+		result.MmprojFilename = mmproj.Filename
+		result.MmprojSize = mmproj.Size
+	}
+	return result, dlErr
+}
+
+// modelFilesCurrent reports whether the files of the downloaded model entry
+// are the ones in fileInfo, verified by hash.
+func (p *Puller) modelFilesCurrent(ctx context.Context, entry metadata.ModelEntry, fileInfo ggufFileInfo) bool {
+	if !slices.Equal(entry.Files(), fileInfo.filenames()) {
+		return false
+	}
+	for _, f := range fileInfo.modelFiles() {
+		if !p.hashMatches(ctx, f.Filename, f.SHA256) {
+			return false
+		}
+	}
+	return true
+}
@@ -2,10 +2,14 @@ package pull
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/metadata"
 )
 
 func TestPull_WithMmproj_Success(t *testing.T) {
@@ -121,6 +125,92 @@ func TestPull_WithMmproj_DownloadFailure(t *testing.T) {
 	}
 }
 
+func TestPullMmproj_FetchesOnlyMmproj(t *testing.T) {
+	tests := []struct {
+		name string
+		pull func(p *Puller, repo, quant string) (*PullResult, error)
+	}{
+		{"PullMmproj", func(p *Puller, repo, quant string) (*PullResult, error) {
+			return p.PullMmproj(context.Background(), repo, quant)
+		}},
+		{"Pull", func(p *Puller, repo, quant string) (*PullResult, error) {
+			return p.Pull(context.Background(), repo, quant)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: the model is downloaded but its mmproj failed
+			modelContent := []byte("fake-model-binary-content")
+			mmprojContent := []byte("fake-mmproj-binary-content")
+			repo := "ggml-org/gemma-3-4b-it-GGUF"
+			tmpDir := t.TempDir()
+			failing, _ := newMmprojTestServer(t, modelContent, mmprojContent, http.StatusInternalServerError)
+			if _, err := newTestPuller(tmpDir, failing.URL).Pull(context.Background(), repo, "Q4_K_M"); err != nil {
+				t.Fatalf("Pull() error = %v", err)
+			}
+			modelPath := filepath.Join(tmpDir, "model-Q4_K_M.gguf")
+			old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			if err := os.Chtimes(modelPath, old, old); err != nil {
+				t.Fatal(err)
+			}
+			srv, _ := newMmprojTestServer(t, modelContent, mmprojContent, 0)
+			puller := newTestPuller(tmpDir, srv.URL)
+
+			// Act
+			result, err := tt.pull(puller, repo, "Q4_K_M")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			wantMmproj := "ggml-org_gemma-3-4b-it-GGUF_mmproj-model-f16.gguf"
+			if result.MmprojFilename != wantMmproj || result.MmprojFailed {
+				t.Errorf("result = %+v, want mmproj %s", result, wantMmproj)
+			}
+			entry := puller.metadata.Find(repo, "Q4_K_M")
+			if entry == nil || entry.Mmproj == nil || entry.Mmproj.Filename != wantMmproj || entry.MmprojFailed {
+				t.Errorf("metadata entry = %+v, want mmproj recorded", entry)
+			}
+			if info, err := os.Stat(modelPath); err != nil || !info.ModTime().Equal(old) {
+				t.Error("model file should not be downloaded again")
+			}
+		})
+	}
+}
+
+func TestPull_WithMmproj_DownloadFailureRecorded(t *testing.T) {
+	// Arrange
+	srv, _ := newMmprojTestServer(t, []byte("model"), []byte("mmproj"), http.StatusInternalServerError)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.Pull(context.Background(), "org/vision-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if entry := puller.metadata.Find("org/vision-GGUF", "Q4_K_M"); entry == nil || !entry.MmprojFailed {
+		t.Errorf("metadata entry = %+v, want MmprojFailed", entry)
+	}
+}
+
+func TestPullMmproj_NotDownloaded(t *testing.T) {
+	// Arrange
+	srv, _ := newMmprojTestServer(t, []byte("model"), []byte("mmproj"), 0)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.PullMmproj(context.Background(), "org/vision-GGUF", "Q4_K_M")
+
+	// Assert
+	var notFound *metadata.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("PullMmproj() error = %v, want NotFoundError", err)
+	}
+}
+
 func TestMmprojStorageFilename(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -0,0 +1,116 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+)
+
+const defaultModelScopeBaseURL = "https://modelscope.cn"
+
+// modelScope pulls from ModelScope. It has no manifest API, so quants are
+// found in the repository's file list by file name, as for :auto.
+type modelScope struct{}
+
+func (modelScope) Name() string     { return "ModelScope" }
+func (modelScope) Scheme() string   { return identifier.RegistryModelScope }
+func (modelScope) endpoint() string { return defaultModelScopeBaseURL }
+
+func (modelScope) RepoURL(repo string) string {
+	return defaultModelScopeBaseURL + "/models/" + repo
+}
+
+func (modelScope) fileURL(baseURL, repo, file, _ string) string {
+	return fmt.Sprintf("%s/models/%s/resolve/master/%s", baseURL, repo, file)
+}
+
+func (modelScope) client(base *http.Client) *http.Client { return base }
+
+func (modelScope) repoTerms(context.Context, *http.Client, string, string) (*RepoTerms, error) {
+	return nil, nil
+}
+
+func (m modelScope) manifest(ctx context.Context, client *http.Client, baseURL, repo, quant string) (ggufFileInfo, error) {
+	entries, err := m.listFiles(ctx, client, baseURL, repo, "", true)
+	if err != nil {
+		return ggufFileInfo{}, err
+	}
+	return manifestFromFiles(entries, repo, quant)
+}
+
+// modelScopeFiles represents the ModelScope repository file list response.
+type modelScopeFiles struct {
+	Code int `json:"Code"`
+	Data struct {
+		Files []struct {
+			Path   string `json:"Path"`
+			Type   string `json:"Type"` // "blob" or "tree"
+			Size   int64  `json:"Size"`
+			SHA256 string `json:"Sha256"`
+		} `json:"Files"`
+	} `json:"Data"`
+}
+
+// listFiles lists the whole repository, which the file list API returns in
+// one response, and keeps the entries under dir.
+func (modelScope) listFiles(ctx context.Context, client *http.Client, baseURL, repo, dir string, recursive bool) ([]treeEntry, error) {
+	url := fmt.Sprintf("%s/api/v1/models/%s/repo/files?Revision=master&Recursive=true", baseURL, repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("fetch file list: %w", err)
+		}
+		return nil, &endpointUnavailableError{fmt.Errorf("fetch file list: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("repository not found: %s", repo)
+	}
+	if isUnavailableStatus(resp.StatusCode) {
+		return nil, &endpointUnavailableError{fmt.Errorf("failed to fetch file list: status %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch file list: status %d", resp.StatusCode)
+	}
+
+	var files modelScopeFiles
+	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
+		return nil, fmt.Errorf("parse file list: %w", err)
+	}
+	// Errors are also reported in the body of a 200 response
+	if files.Code != http.StatusOK {
+		return nil, fmt.Errorf("repository not found: %s", repo)
+	}
+
+	var entries []treeEntry
+	for _, f := range files.Data.Files {
+		fileDir := path.Dir(f.Path)
+		if fileDir == "." {
+			fileDir = ""
+		}
+		if fileDir != dir && !(recursive && (dir == "" || strings.HasPrefix(fileDir, dir+"/"))) {
+			continue
+		}
+		e := treeEntry{Type: "directory", Path: f.Path, Size: f.Size}
+		if f.Type == "blob" {
+			e.Type = "file"
+		}
+		if f.SHA256 != "" {
+			e.LFS = &treeLFSInfo{OID: f.SHA256, Size: f.Size}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,178 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// modelScopeFile is a file served by newModelScopeTestServer.
+type modelScopeFile struct {
+	path    string
+	content []byte
+}
+
+// newModelScopeTestServer serves files from org/model-GGUF the way the
+// ModelScope file list API and file downloads do.
+func newModelScopeTestServer(t *testing.T, files ...modelScopeFile) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/models/org/model-GGUF/repo/files":
+			list := []map[string]any{{"Path": "Q8_0", "Type": "tree"}}
+			for _, f := range files {
+				list = append(list, map[string]any{
+					"Path": f.path, "Type": "blob", "Size": len(f.content), "Sha256": computeSHA256(f.content),
+				})
+			}
+			json.NewEncoder(w).Encode(map[string]any{"Code": 200, "Data": map[string]any{"Files": list}})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/models/"):
+			json.NewEncoder(w).Encode(map[string]any{"Code": 10010205001, "Message": "not found"})
+		case strings.HasPrefix(r.URL.Path, "/models/org/model-GGUF/resolve/master/"):
+			name := strings.TrimPrefix(r.URL.Path, "/models/org/model-GGUF/resolve/master/")
+			for _, f := range files {
+				if f.path == name {
+					w.Write(f.content)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newModelScopeTestPuller creates a Puller for ModelScope at baseURL.
+func newModelScopeTestPuller(modelsDir, baseURL string) *Puller {
+	p := newTestPuller(modelsDir, baseURL)
+	p.SetRegistry(ModelScope)
+	p.baseURL = baseURL
+	return p
+}
+
+func TestModelScope_Pull(t *testing.T) {
+	// Arrange
+	model := []byte("q4-model")
+	srv := newModelScopeTestServer(t,
+		modelScopeFile{path: "model-Q8_0.gguf", content: []byte("q8-model-bytes")},
+		modelScopeFile{path: "model-Q4_K_M.gguf", content: model},
+		modelScopeFile{path: "mmproj-BF16.gguf", content: []byte("bf16")},
+		modelScopeFile{path: "mmproj-F16.gguf", content: []byte("f16")},
+	)
+	dir := t.TempDir()
+	puller := newModelScopeTestPuller(dir, srv.URL)
+
+	// Act
+	result, err := puller.Pull(context.Background(), "org/model-GGUF", "q4_k_m")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if result.Filename != "model-Q4_K_M.gguf" {
+		t.Errorf("Filename = %q, want model-Q4_K_M.gguf", result.Filename)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, result.Filename)); string(got) != string(model) {
+		t.Errorf("model content = %q, want %q", got, model)
+	}
+	entry := puller.metadata.Find("org/model-GGUF", "q4_k_m")
+	if entry == nil {
+		t.Fatal("metadata entry not found")
+	}
+	if entry.Registry != "ms" {
+		t.Errorf("Registry = %q, want ms", entry.Registry)
+	}
+	if entry.SHA256 != computeSHA256(model) {
+		t.Errorf("SHA256 = %q, want the listed hash", entry.SHA256)
+	}
+	if entry.Mmproj == nil || entry.Mmproj.File != "mmproj-F16.gguf" {
+		t.Errorf("Mmproj = %+v, want mmproj-F16.gguf", entry.Mmproj)
+	}
+}
+
+func TestModelScope_Errors(t *testing.T) {
+	srv := newModelScopeTestServer(t, modelScopeFile{path: "model-Q4_K_M.gguf", content: []byte("model")})
+
+	tests := []struct {
+		name    string
+		repo    string
+		quant   string
+		wantErr string
+	}{
+		{name: "unknown quant", repo: "org/model-GGUF", quant: "Q2_K", wantErr: "invalid quantization 'Q2_K'"},
+		{name: "unknown repository", repo: "org/missing", quant: "Q4_K_M", wantErr: "repository not found: org/missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			puller := newModelScopeTestPuller(t.TempDir(), srv.URL)
+
+			// Act
+			_, err := puller.Pull(context.Background(), tt.repo, tt.quant)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Pull() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestModelScope_CheckOutdated(t *testing.T) {
+	// Arrange
+	srv := newModelScopeTestServer(t, modelScopeFile{path: "model-Q4_K_M.gguf", content: []byte("model")})
+	dir := t.TempDir()
+	puller := newModelScopeTestPuller(dir, srv.URL)
+	if _, err := puller.Pull(context.Background(), "org/model-GGUF", "Q4_K_M"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	entry := *puller.metadata.Find("org/model-GGUF", "Q4_K_M")
+
+	// Act
+	outdated, err := puller.CheckOutdated(context.Background(), entry)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CheckOutdated() error = %v", err)
+	}
+	if outdated {
+		t.Error("CheckOutdated() = true, want false for an unchanged model")
+	}
+}
+
+func TestRegistryFor(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		want    Registry
+		wantErr bool
+	}{
+		{scheme: "", want: HuggingFace},
+		{scheme: "h", want: HuggingFace},
+		{scheme: "ms", want: ModelScope},
+		{scheme: "s3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			// Act
+			got, err := RegistryFor(tt.scheme)
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RegistryFor(%q) error = %v, wantErr %v", tt.scheme, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RegistryFor(%q) = %v, want %v", tt.scheme, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+)
+
+// Media types of the OCI artifacts alpaca pushes. Pulls accept any layer
+// whose title is a GGUF file name.
+const (
+	ociArtifactType    = "application/vnd.alpaca.model.v1"
+	ociModelLayerType  = "application/vnd.alpaca.model.gguf"
+	ociMmprojLayerType = "application/vnd.alpaca.mmproj.gguf"
+	ociManifestType    = "application/vnd.oci.image.manifest.v1+json"
+	ociEmptyType       = "application/vnd.oci.empty.v1+json"
+	ociTitleAnnotation = "org.opencontainers.image.title"
+)
+
+// ociEmptyConfig is the empty JSON object OCI artifacts without a config
+// refer to as their config blob.
+var ociEmptyConfig = []byte("{}")
+
+// ociManifest is an OCI image manifest.
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// ociDescriptor describes a blob of an OCI artifact.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// title returns the file name the blob was pushed as.
+func (d ociDescriptor) title() string {
+	return d.Annotations[ociTitleAnnotation]
+}
+
+// ociRegistry pulls OCI artifacts from container registries. The
+// repository includes the registry host (ghcr.io/org/model) and the quant
+// is the tag; each GGUF file of the model is a layer.
+type ociRegistry struct{}
+
+func (ociRegistry) Name() string     { return "OCI registry" }
+func (ociRegistry) Scheme() string   { return identifier.RegistryOCI }
+func (ociRegistry) endpoint() string { return "" } // the host is part of the repository
+
+func (ociRegistry) RepoURL(repo string) string {
+	return identifier.RegistryOCI + "://" + repo
+}
+
+// fileURL returns the URL of the blob with SHA256 sum; blobs are addressed
+// by digest, not by file name.
+func (ociRegistry) fileURL(_, repo, _, sum string) string {
+	return ociURL(repo, "blobs/sha256:"+sum)
+}
+
+func (ociRegistry) client(base *http.Client) *http.Client {
+	return newOCIClient(base)
+}
+
+func (ociRegistry) listFiles(context.Context, *http.Client, string, string, string, bool) ([]treeEntry, error) {
+	return nil, fmt.Errorf("OCI registries do not list repository files")
+}
+
+func (ociRegistry) repoTerms(context.Context, *http.Client, string, string) (*RepoTerms, error) {
+	return nil, nil
+}
+
+// ociURL returns the URL of path under the distribution API of repo,
+// e.g. https://ghcr.io/v2/org/model/manifests/q4. Registries on the local
+// machine are reached over plain HTTP, as docker does.
+func ociURL(repo, path string) string {
+	host, name, _ := strings.Cut(repo, "/")
+	scheme := "https"
+	if isLocalHost(host) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s", scheme, host, name, path)
+}
+
+// isLocalHost reports whether host (with an optional port) is the local
+// machine.
+func isLocalHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (ociRegistry) manifest(ctx context.Context, client *http.Client, _, repo, tag string) (ggufFileInfo, error) {
+	m, err := fetchOCIManifest(ctx, client, repo, tag)
+	if err != nil {
+		return ggufFileInfo{}, err
+	}
+	return ociFileInfo(m, repo, tag)
+}
+
+// fetchOCIManifest fetches the manifest tagged tag in repo.
+func fetchOCIManifest(ctx context.Context, client *http.Client, repo, tag string) (*ociManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", ociURL(repo, "manifests/"+tag), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", ociManifestType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("fetch manifest: %w", err)
+		}
+		return nil, &endpointUnavailableError{fmt.Errorf("fetch manifest: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("tag '%s' not found in '%s'", tag, repo)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, ociAccessError(repo)
+	case isUnavailableStatus(resp.StatusCode):
+		return nil, &endpointUnavailableError{fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)}
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)
+	}
+
+	var m ociManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if m.MediaType != "" && m.MediaType != ociManifestType {
+		return nil, fmt.Errorf("'%s:%s' is not a model artifact (media type %s)", repo, tag, m.MediaType)
+	}
+	return &m, nil
+}
+
+// ociAccessError reports that the registry refused access to repo.
+func ociAccessError(repo string) error {
+	host, _, _ := strings.Cut(repo, "/")
+	return fmt.Errorf("access to '%s' denied; log in with: docker login %s", repo, host)
+}
+
+// ociFileInfo describes the model in an artifact's layers: one GGUF file,
+// or the shards of one split model, and optionally an mmproj.
+func ociFileInfo(m *ociManifest, repo, tag string) (ggufFileInfo, error) {
+	var models []shardFile
+	var mmproj *shardFile
+	for _, l := range m.Layers {
+		title := l.title()
+		if !strings.HasSuffix(strings.ToLower(title), ".gguf") {
+			continue
+		}
+		sum, ok := strings.CutPrefix(l.Digest, "sha256:")
+		if !ok {
+			return ggufFileInfo{}, fmt.Errorf("layer %s has unsupported digest %s", title, l.Digest)
+		}
+		if !filepath.IsLocal(title) || path.Base(title) != title {
+			return ggufFileInfo{}, fmt.Errorf("invalid filename from manifest: %s", title)
+		}
+		f := shardFile{Path: title, Filename: title, SHA256: sum, Size: l.Size}
+		if l.MediaType == ociMmprojLayerType || strings.Contains(strings.ToLower(title), "mmproj") {
+			mmproj = &f
+			continue
+		}
+		models = append(models, f)
+	}
+	if len(models) == 0 {
+		return ggufFileInfo{}, fmt.Errorf("no GGUF file found in '%s:%s'", repo, tag)
+	}
+	slices.SortFunc(models, func(a, b shardFile) int { return strings.Compare(a.Filename, b.Filename) })
+
+	fi := ggufFileInfo{Filename: models[0].Filename, SHA256: models[0].SHA256, Size: models[0].Size}
+	if len(models) > 1 {
+		prefix, count, ok := splitShardName(models[0].Filename)
+		for i, s := range models {
+			if !ok || len(models) != count || s.Filename != fmt.Sprintf("%s-%05d-of-%05d.gguf", prefix, i+1, count) {
+				return ggufFileInfo{}, fmt.Errorf("'%s:%s' holds %d GGUF files that are not the shards of one model", repo, tag, len(models))
+			}
+			if i > 0 {
+				fi.Size += s.Size
+			}
+		}
+		fi.Shards = models
+	}
+	if mmproj != nil {
+		fi.MmprojOriginalFilename = mmproj.Filename
+		fi.MmprojFilename = mmprojStorageFilename(repo, mmproj.Filename)
+		fi.MmprojSHA256 = mmproj.SHA256
+		fi.MmprojSize = mmproj.Size
+	}
+	return fi, nil
+}
@@ -0,0 +1,266 @@
+package pull
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// newOCIClient returns a client like base that answers the authentication
+// challenges of container registries with the credentials docker login
+// stored.
+func newOCIClient(base *http.Client) *http.Client {
+	if _, ok := base.Transport.(*ociTransport); ok {
+		return base
+	}
+	c := *base
+	c.Transport = &ociTransport{base: base.Transport, auth: make(map[string]string)}
+	return &c
+}
+
+// ociTransport authenticates requests to container registries the way
+// docker does: a request answered with 401 is retried once with the
+// Authorization its WWW-Authenticate challenge asks for, a bearer token
+// from the registry's token service or basic credentials. Later requests
+// to the same host carry it from the start. Other hosts, such as the
+// storage a blob download is redirected to, never see it.
+type ociTransport struct {
+	base http.RoundTripper // nil for http.DefaultTransport
+
+	mu   sync.Mutex
+	auth map[string]string // host -> Authorization header
+}
+
+func (t *ociTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	host := req.URL.Host
+
+	t.mu.Lock()
+	auth := t.auth[host]
+	t.mu.Unlock()
+	resp, err := base.RoundTrip(withAuthorization(req, auth))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// A request whose body was sent cannot be sent again
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	newAuth, err := authorize(req.Context(), base, host, challenge)
+	if err != nil || newAuth == "" || newAuth == auth {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.mu.Lock()
+	t.auth[host] = newAuth
+	t.mu.Unlock()
+	retry := withAuthorization(req, newAuth)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return base.RoundTrip(retry)
+}
+
+// withAuthorization returns req with its Authorization header set to auth.
+func withAuthorization(req *http.Request, auth string) *http.Request {
+	if auth == "" {
+		return req
+	}
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", auth)
+	return r
+}
+
+// authorize returns the Authorization header that answers a registry's
+// WWW-Authenticate challenge, using the credentials stored for host.
+func authorize(ctx context.Context, rt http.RoundTripper, host, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	user, secret, hasCreds := ociCredentials(host)
+	switch scheme {
+	case "basic":
+		if !hasCreds {
+			return "", nil
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+secret)), nil
+	case "bearer":
+		return fetchBearerToken(ctx, rt, params, user, secret, hasCreds)
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+}
+
+// parseChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="..."`
+// into its lowercase scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+		rest = strings.TrimLeft(rest, ", ")
+	}
+	return strings.ToLower(scheme), params
+}
+
+// fetchBearerToken gets a token from the token service a bearer challenge
+// names, anonymously when there are no stored credentials.
+func fetchBearerToken(ctx context.Context, rt http.RoundTripper, params map[string]string, user, secret string, hasCreds bool) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || (realm.Scheme != "https" && realm.Scheme != "http") {
+		return "", fmt.Errorf("invalid token realm %q", params["realm"])
+	}
+	q := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			q.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if hasCreds {
+		req.SetBasicAuth(user, secret)
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch registry token: status %d", resp.StatusCode)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("parse registry token: %w", err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return "", fmt.Errorf("registry token service returned no token")
+	}
+	return "Bearer " + token, nil
+}
+
+// dockerConfig is the part of docker's config.json that holds credentials.
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth     string `json:"auth"` // base64 of user:secret
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// ociCredentials returns the credentials docker login stored for host:
+// from the credential helper configured for it, or in config.json itself.
+// The config is read from $DOCKER_CONFIG, or ~/.docker.
+func ociCredentials(host string) (user, secret string, ok bool) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", false
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", "", false
+	}
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", "", false
+	}
+
+	if helper := cfg.CredHelpers[host]; helper != "" {
+		return credentialHelper(helper, host)
+	}
+	for key, a := range cfg.Auths {
+		if registryHost(key) != host {
+			continue
+		}
+		if a.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				continue
+			}
+			user, secret, ok = strings.Cut(string(decoded), ":")
+			return user, secret, ok
+		}
+		if a.Username != "" {
+			return a.Username, a.Password, true
+		}
+	}
+	if cfg.CredsStore != "" {
+		return credentialHelper(cfg.CredsStore, host)
+	}
+	return "", "", false
+}
+
+// registryHost returns the host of a config.json auths key, which may be a
+// URL such as https://index.docker.io/v1/.
+func registryHost(key string) string {
+	if u, err := url.Parse(key); err == nil && u.Host != "" {
+		return u.Host
+	}
+	host, _, _ := strings.Cut(key, "/")
+	return host
+}
+
+// credentialHelper asks docker-credential-<helper> for the credentials of
+// host.
+func credentialHelper(helper, host string) (user, secret string, ok bool) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(host)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", "", false
+	}
+	var creds struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(out)).Decode(&creds); err != nil || creds.Secret == "" {
+		return "", "", false
+	}
+	return creds.Username, creds.Secret, true
+}
@@ -0,0 +1,237 @@
+package pull
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// ociBlob is a blob of an artifact being pushed.
+type ociBlob struct {
+	desc     ociDescriptor
+	filename string // file in the models directory; empty for the config
+}
+
+// PushOCI pushes the downloaded model entry to repo (host/name) as an OCI
+// artifact tagged tag and returns the manifest digest. Blobs the registry
+// already has are not uploaded again, so an interrupted push resumes at the
+// first missing file.
+func (p *Puller) PushOCI(ctx context.Context, entry metadata.ModelEntry, repo, tag string) (string, error) {
+	if err := p.metadata.Load(ctx); err != nil {
+		return "", fmt.Errorf("load metadata: %w", err)
+	}
+	client := OCI.client(p.client)
+
+	blobs := []ociBlob{{desc: ociDescriptor{
+		MediaType: ociEmptyType,
+		Digest:    "sha256:" + sha256Hex(ociEmptyConfig),
+		Size:      int64(len(ociEmptyConfig)),
+	}}}
+	for _, f := range entry.Files() {
+		b, err := p.ociLayer(ctx, f, f, ociModelLayerType)
+		if err != nil {
+			return "", err
+		}
+		blobs = append(blobs, b)
+	}
+	if entry.Mmproj != nil {
+		title := entry.Mmproj.File
+		if title == "" {
+			title = entry.Mmproj.Filename
+		}
+		b, err := p.ociLayer(ctx, entry.Mmproj.Filename, filepath.Base(title), ociMmprojLayerType)
+		if err != nil {
+			return "", err
+		}
+		blobs = append(blobs, b)
+	}
+
+	for i, b := range blobs {
+		if err := p.pushBlob(ctx, client, repo, b, i, len(blobs)-1); err != nil {
+			return "", err
+		}
+	}
+
+	m := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestType,
+		ArtifactType:  ociArtifactType,
+		Config:        blobs[0].desc,
+	}
+	for _, b := range blobs[1:] {
+		m.Layers = append(m.Layers, b.desc)
+	}
+	return pushOCIManifest(ctx, client, repo, tag, m)
+}
+
+// ociLayer describes filename in the models directory as a layer titled
+// title, hashing it unless a current checksum is recorded.
+func (p *Puller) ociLayer(ctx context.Context, filename, title, mediaType string) (ociBlob, error) {
+	info, err := os.Stat(filepath.Join(p.modelsDir, filename))
+	if err != nil {
+		return ociBlob{}, fmt.Errorf("model file %s: %w", filename, err)
+	}
+	sum := p.metadata.Checksum(filename, info.Size(), info.ModTime())
+	if sum == "" || p.verify {
+		if sum, err = p.fileSHA256(filename); err != nil {
+			return ociBlob{}, err
+		}
+		p.recordChecksum(ctx, filename, sum)
+	}
+	return ociBlob{
+		desc: ociDescriptor{
+			MediaType:   mediaType,
+			Digest:      "sha256:" + sum,
+			Size:        info.Size(),
+			Annotations: map[string]string{ociTitleAnnotation: title},
+		},
+		filename: filename,
+	}, nil
+}
+
+// pushBlob uploads b, file index of total, to repo unless the registry
+// already has it.
+func (p *Puller) pushBlob(ctx context.Context, client *http.Client, repo string, b ociBlob, index, total int) error {
+	blobURL := ociURL(repo, "blobs/"+b.desc.Digest)
+	req, err := http.NewRequestWithContext(ctx, "HEAD", blobURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("check blob: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	location, err := startOCIUpload(ctx, client, repo)
+	if err != nil {
+		return err
+	}
+	q := location.Query()
+	q.Set("digest", b.desc.Digest)
+	location.RawQuery = q.Encode()
+
+	var body io.Reader = bytes.NewReader(ociEmptyConfig)
+	if b.filename != "" {
+		f, err := os.Open(filepath.Join(p.modelsDir, b.filename))
+		if err != nil {
+			return fmt.Errorf("open %s: %w", b.filename, err)
+		}
+		defer f.Close()
+		if p.onFileStart != nil {
+			p.onFileStart(b.desc.title(), b.desc.Size, index, total)
+		}
+		p.startTransfer(PhaseModel, b.desc.title())
+		body = &progressReader{r: f, total: b.desc.Size, report: p.reportProgress}
+	}
+	req, err = http.NewRequestWithContext(ctx, "PUT", location.String(), body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.ContentLength = b.desc.Size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err = client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", b.desc.title(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return ociPushError(repo, "upload "+b.desc.Digest, resp.StatusCode)
+	}
+	if b.filename != "" {
+		p.reportDone(b.desc.Size)
+	}
+	return nil
+}
+
+// startOCIUpload starts a blob upload session in repo and returns the URL
+// to upload to.
+func startOCIUpload(ctx context.Context, client *http.Client, repo string) (*url.URL, error) {
+	uploadsURL := ociURL(repo, "blobs/uploads/")
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("start upload: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return nil, ociPushError(repo, "start upload", resp.StatusCode)
+	}
+
+	// The location may be relative to the registry
+	base, _ := url.Parse(uploadsURL)
+	location, err := base.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.Header.Get("Location") == "" {
+		return nil, fmt.Errorf("start upload: registry returned no upload location")
+	}
+	return location, nil
+}
+
+// pushOCIManifest tags m as tag in repo and returns its digest.
+func pushOCIManifest(ctx context.Context, client *http.Client, repo, tag string, m ociManifest) (string, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("encode manifest: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", ociURL(repo, "manifests/"+tag), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", ociManifestType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("push manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return "", ociPushError(repo, "push manifest", resp.StatusCode)
+	}
+	return "sha256:" + sha256Hex(data), nil
+}
+
+// ociPushError reports a failed push step.
+func ociPushError(repo, step string, status int) error {
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return ociAccessError(repo)
+	}
+	return fmt.Errorf("%s: status %d", step, status)
+}
+
+// sha256Hex returns the hex-encoded SHA256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// progressReader reports how much of total has been read from r.
+type progressReader struct {
+	r      io.Reader
+	n      int64
+	total  int64
+	report func(done, total int64)
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.n += int64(n)
+	if n > 0 {
+		r.report(r.n, r.total)
+	}
+	return n, err
+}
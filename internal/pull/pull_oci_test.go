@@ -0,0 +1,400 @@
+package pull
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// ociTestRegistry is an in-memory registry for org/model that serves the
+// OCI distribution API behind bearer token authentication, the way
+// ghcr.io and Docker Hub do.
+type ociTestRegistry struct {
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[string][]byte // digest -> content
+	manifests map[string][]byte // tag -> manifest
+	uploads   int
+}
+
+// newOCITestRegistry starts a registry whose token service accepts
+// user:secret, and points DOCKER_CONFIG at a config holding credentials.
+func newOCITestRegistry(t *testing.T, user, secret string) *ociTestRegistry {
+	t.Helper()
+	r := &ociTestRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	r.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/token" {
+			if u, p, ok := req.BasicAuth(); !ok || u != user || p != secret {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"token":"test-token"}`))
+			return
+		}
+		if req.Header.Get("Authorization") != "Bearer test-token" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.srv.URL+`/token",service="test",scope="repository:org/model:pull,push"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.serve(w, req)
+	}))
+	t.Cleanup(r.srv.Close)
+
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	config := `{"auths":{"` + r.host() + `":{"auth":"` + auth + `"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+	return r
+}
+
+func (r *ociTestRegistry) host() string {
+	return strings.TrimPrefix(r.srv.URL, "http://")
+}
+
+func (r *ociTestRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path, ok := strings.CutPrefix(req.URL.Path, "/v2/org/model/")
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	switch {
+	case req.Method == "POST" && path == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/org/model/blobs/uploads/session")
+		w.WriteHeader(http.StatusAccepted)
+	case req.Method == "PUT" && path == "blobs/uploads/session":
+		data, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if digest != "sha256:"+computeSHA256(data) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = data
+		r.uploads++
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "blobs/"):
+		data, ok := r.blobs[strings.TrimPrefix(path, "blobs/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(data)
+	case req.Method == "PUT" && strings.HasPrefix(path, "manifests/"):
+		data, _ := io.ReadAll(req.Body)
+		r.manifests[strings.TrimPrefix(path, "manifests/")] = data
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(path, "manifests/"):
+		data, ok := r.manifests[strings.TrimPrefix(path, "manifests/")]
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", ociManifestType)
+		w.Write(data)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// addTestModel writes a downloaded model with an mmproj to modelsDir.
+func addTestModel(t *testing.T, modelsDir string, model, mmproj []byte) metadata.ModelEntry {
+	t.Helper()
+	entry := metadata.ModelEntry{
+		Repo:     "org/model-GGUF",
+		Quant:    "Q4_K_M",
+		Filename: "model-Q4_K_M.gguf",
+		Size:     int64(len(model)),
+		Mmproj: &metadata.MmprojEntry{
+			Filename: "org_model-GGUF_mmproj-F16.gguf",
+			File:     "mmproj-F16.gguf",
+			Size:     int64(len(mmproj)),
+		},
+	}
+	os.WriteFile(filepath.Join(modelsDir, entry.Filename), model, 0644)
+	os.WriteFile(filepath.Join(modelsDir, entry.Mmproj.Filename), mmproj, 0644)
+	meta := metadata.NewManager(modelsDir)
+	if err := meta.Add(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.Save(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return entry
+}
+
+func TestOCI_PushPull(t *testing.T) {
+	// Arrange
+	reg := newOCITestRegistry(t, "user", "secret")
+	repo := reg.host() + "/org/model"
+	model, mmproj := []byte("model-bytes"), []byte("mmproj-bytes")
+	srcDir := t.TempDir()
+	entry := addTestModel(t, srcDir, model, mmproj)
+	dstDir := t.TempDir()
+
+	// Act
+	digest, pushErr := NewPuller(srcDir).PushOCI(context.Background(), entry, repo, "q4")
+	puller := NewPuller(dstDir)
+	puller.SetRegistry(OCI)
+	result, pullErr := puller.Pull(context.Background(), repo, "q4")
+
+	// Assert
+	if pushErr != nil {
+		t.Fatalf("PushOCI() error = %v", pushErr)
+	}
+	if !strings.HasPrefix(digest, "sha256:") {
+		t.Errorf("digest = %q, want sha256:...", digest)
+	}
+	if reg.uploads != 3 {
+		t.Errorf("uploads = %d, want 3 (config, model, mmproj)", reg.uploads)
+	}
+	if pullErr != nil {
+		t.Fatalf("Pull() error = %v", pullErr)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dstDir, result.Filename)); string(got) != string(model) {
+		t.Errorf("model content = %q, want %q", got, model)
+	}
+	pulled := puller.metadata.Find(repo, "q4")
+	if pulled == nil {
+		t.Fatal("metadata entry not found")
+	}
+	if pulled.Registry != "oci" {
+		t.Errorf("Registry = %q, want oci", pulled.Registry)
+	}
+	if pulled.Mmproj == nil || pulled.Mmproj.File != "mmproj-F16.gguf" {
+		t.Fatalf("Mmproj = %+v, want mmproj-F16.gguf", pulled.Mmproj)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dstDir, pulled.Mmproj.Filename)); string(got) != string(mmproj) {
+		t.Errorf("mmproj content = %q, want %q", got, mmproj)
+	}
+}
+
+func TestOCI_PushSkipsExistingBlobs(t *testing.T) {
+	// Arrange
+	reg := newOCITestRegistry(t, "user", "secret")
+	repo := reg.host() + "/org/model"
+	dir := t.TempDir()
+	entry := addTestModel(t, dir, []byte("model"), []byte("mmproj"))
+	puller := NewPuller(dir)
+	if _, err := puller.PushOCI(context.Background(), entry, repo, "q4"); err != nil {
+		t.Fatalf("PushOCI() error = %v", err)
+	}
+
+	// Act
+	_, err := puller.PushOCI(context.Background(), entry, repo, "latest")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PushOCI() error = %v", err)
+	}
+	if reg.uploads != 3 {
+		t.Errorf("uploads = %d, want 3: the second push has every blob already", reg.uploads)
+	}
+	if _, ok := reg.manifests["latest"]; !ok {
+		t.Error("manifest not tagged latest")
+	}
+}
+
+func TestOCI_CheckOutdated(t *testing.T) {
+	// Arrange
+	reg := newOCITestRegistry(t, "user", "secret")
+	repo := reg.host() + "/org/model"
+	srcDir := t.TempDir()
+	entry := addTestModel(t, srcDir, []byte("model"), []byte("mmproj"))
+	if _, err := NewPuller(srcDir).PushOCI(context.Background(), entry, repo, "q4"); err != nil {
+		t.Fatalf("PushOCI() error = %v", err)
+	}
+	puller := NewPuller(t.TempDir())
+	puller.SetRegistry(OCI)
+	if _, err := puller.Pull(context.Background(), repo, "q4"); err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	pulled := *puller.metadata.Find(repo, "q4")
+	os.WriteFile(filepath.Join(srcDir, entry.Filename), []byte("model v2"), 0644)
+	if _, err := NewPuller(srcDir).PushOCI(context.Background(), entry, repo, "q4"); err != nil {
+		t.Fatalf("PushOCI() error = %v", err)
+	}
+
+	// Act
+	outdated, err := NewPuller(t.TempDir()).CheckOutdated(context.Background(), pulled)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("CheckOutdated() error = %v", err)
+	}
+	if !outdated {
+		t.Error("CheckOutdated() = false, want true after the tag moved")
+	}
+}
+
+func TestOCI_PullErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		tag     string
+		wantErr string
+	}{
+		{name: "unknown tag", secret: "secret", tag: "q8", wantErr: "tag 'q8' not found"},
+		{name: "wrong credentials", secret: "other", tag: "q4", wantErr: "access to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reg := newOCITestRegistry(t, "user", tt.secret)
+			puller := NewPuller(t.TempDir())
+			puller.SetRegistry(OCI)
+
+			// Act
+			_, err := puller.Pull(context.Background(), reg.host()+"/org/model", tt.tag)
+
+			// Assert
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Pull() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOCIFileInfo(t *testing.T) {
+	layer := func(title string) ociDescriptor {
+		return ociDescriptor{
+			MediaType:   ociModelLayerType,
+			Digest:      "sha256:" + computeSHA256([]byte(title)),
+			Size:        10,
+			Annotations: map[string]string{ociTitleAnnotation: title},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		layers     []ociDescriptor
+		wantFile   string
+		wantShards int
+		wantErr    string
+	}{
+		{
+			name:     "single file",
+			layers:   []ociDescriptor{layer("model.gguf"), layer("README.md")},
+			wantFile: "model.gguf",
+		},
+		{
+			name:       "split model",
+			layers:     []ociDescriptor{layer("m-00002-of-00002.gguf"), layer("m-00001-of-00002.gguf")},
+			wantFile:   "m-00001-of-00002.gguf",
+			wantShards: 2,
+		},
+		{
+			name:    "unrelated models",
+			layers:  []ociDescriptor{layer("a.gguf"), layer("b.gguf")},
+			wantErr: "not the shards of one model",
+		},
+		{
+			name:    "no GGUF",
+			layers:  []ociDescriptor{layer("README.md")},
+			wantErr: "no GGUF file found",
+		},
+		{
+			name:    "path in title",
+			layers:  []ociDescriptor{layer("../model.gguf")},
+			wantErr: "invalid filename",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			fi, err := ociFileInfo(&ociManifest{Layers: tt.layers}, "host/org/model", "q4")
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ociFileInfo() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ociFileInfo() error = %v", err)
+			}
+			if fi.Filename != tt.wantFile || len(fi.Shards) != tt.wantShards {
+				t.Errorf("ociFileInfo() = %s with %d shards, want %s with %d", fi.Filename, len(fi.Shards), tt.wantFile, tt.wantShards)
+			}
+		})
+	}
+}
+
+func TestOCICredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		host     string
+		wantUser string
+		wantOK   bool
+	}{
+		{
+			name:     "encoded auth",
+			config:   `{"auths":{"ghcr.io":{"auth":"` + base64.StdEncoding.EncodeToString([]byte("me:pw")) + `"}}}`,
+			host:     "ghcr.io",
+			wantUser: "me",
+			wantOK:   true,
+		},
+		{
+			name:     "username and password under a URL",
+			config:   `{"auths":{"https://registry.example.com/v1/":{"username":"me","password":"pw"}}}`,
+			host:     "registry.example.com",
+			wantUser: "me",
+			wantOK:   true,
+		},
+		{
+			name:   "other host",
+			config: `{"auths":{"ghcr.io":{"username":"me","password":"pw"}}}`,
+			host:   "docker.io",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.config), 0644)
+			t.Setenv("DOCKER_CONFIG", dir)
+
+			// Act
+			user, _, ok := ociCredentials(tt.host)
+
+			// Assert
+			if ok != tt.wantOK || user != tt.wantUser {
+				t.Errorf("ociCredentials(%q) = %q, %v, want %q, %v", tt.host, user, ok, tt.wantUser, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	// Act
+	scheme, params := parseChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:org/model:pull"`)
+
+	// Assert
+	if scheme != "bearer" {
+		t.Errorf("scheme = %q, want bearer", scheme)
+	}
+	want := map[string]string{"realm": "https://ghcr.io/token", "service": "ghcr.io", "scope": "repository:org/model:pull"}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("params[%q] = %q, want %q", k, params[k], v)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// CheckOutdated reports whether a downloaded model differs from the
+// current upstream manifest: a new model hash or a changed mmproj file.
+// Entries without a stored hash (downloaded by older versions) are checked
+// by hashing the local file. The model is checked against the registry it
+// was pulled from.
+func (p *Puller) CheckOutdated(ctx context.Context, entry metadata.ModelEntry) (bool, error) {
+	if entry.Source != "" {
+		return false, fmt.Errorf("not downloaded from a registry")
+	}
+	registry, err := RegistryFor(entry.Registry)
+	if err != nil {
+		return false, err
+	}
+	if registry != p.registry {
+		p = p.Clone()
+		p.SetRegistry(registry)
+	}
+
+	fileInfo, err := p.fetchManifest(ctx, entry.Repo, entry.Quant)
+	if err != nil {
+		return false, err
+	}
+	if fileInfo.SHA256 == "" {
+		return false, errNoHash
+	}
+
+	if !slices.Equal(fileInfo.filenames(), entry.Files()) {
+		return true, nil
+	}
+	var mmprojFilename string
+	if entry.Mmproj != nil {
+		mmprojFilename = entry.Mmproj.Filename
+	}
+	if fileInfo.MmprojFilename != mmprojFilename {
+		return true, nil
+	}
+
+	if entry.SHA256 != "" {
+		return entry.SHA256 != fileInfo.SHA256, nil
+	}
+	return !p.hashMatches(ctx, entry.Filename, fileInfo.SHA256), nil
+}
@@ -0,0 +1,108 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+func newOutdatedTestServer(t *testing.T, manifest manifestResponse) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			json.NewEncoder(w).Encode(manifest)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCheckOutdated(t *testing.T) {
+	local := []byte("local-model")
+	localHash := computeSHA256(local)
+	newHash := computeSHA256([]byte("new-model"))
+
+	tests := []struct {
+		name     string
+		entry    metadata.ModelEntry
+		manifest manifestResponse
+		want     bool
+	}{
+		{
+			name:     "stored hash matches",
+			entry:    metadata.ModelEntry{Repo: "org/m", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: localHash},
+			manifest: newManifestResponse("m.gguf", 11, localHash),
+			want:     false,
+		},
+		{
+			name:     "stored hash differs",
+			entry:    metadata.ModelEntry{Repo: "org/m", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: localHash},
+			manifest: newManifestResponse("m.gguf", 11, newHash),
+			want:     true,
+		},
+		{
+			name:     "no stored hash falls back to local file",
+			entry:    metadata.ModelEntry{Repo: "org/m", Quant: "Q4_K_M", Filename: "m.gguf"},
+			manifest: newManifestResponse("m.gguf", 11, localHash),
+			want:     false,
+		},
+		{
+			name:     "filename changed",
+			entry:    metadata.ModelEntry{Repo: "org/m", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: localHash},
+			manifest: newManifestResponse("m-v2.gguf", 11, localHash),
+			want:     true,
+		},
+		{
+			name:     "mmproj added upstream",
+			entry:    metadata.ModelEntry{Repo: "org/m", Quant: "Q4_K_M", Filename: "m.gguf", SHA256: localHash},
+			manifest: newManifestResponseWithMmproj("m.gguf", 11, localHash, "mmproj.gguf", 5, newHash),
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tmpDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmpDir, "m.gguf"), local, 0644); err != nil {
+				t.Fatal(err)
+			}
+			srv := newOutdatedTestServer(t, tt.manifest)
+			puller := newTestPuller(tmpDir, srv.URL)
+
+			// Act
+			got, err := puller.CheckOutdated(context.Background(), tt.entry)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("CheckOutdated() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CheckOutdated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOutdated_SkipsNonHuggingFace(t *testing.T) {
+	// Arrange
+	puller := NewPuller(t.TempDir())
+	entry := metadata.ModelEntry{Repo: "me/custom", Quant: "Q4_K_M", Filename: "c.gguf", Source: "/tmp/c.gguf"}
+
+	// Act
+	_, err := puller.CheckOutdated(context.Background(), entry)
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error for model added outside HuggingFace")
+	}
+}
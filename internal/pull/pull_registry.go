@@ -0,0 +1,166 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Registry is a service models are pulled from. A registry knows how the
+// service describes a quant, lists a repository's files, and serves a file;
+// downloading, verification, and metadata are the same for every registry.
+// New backends implement it in this package and are selected with an
+// identifier scheme in RegistryFor.
+type Registry interface {
+	// Name is the registry's name in messages, e.g. "HuggingFace".
+	Name() string
+
+	// Scheme is the identifier prefix that selects the registry, without
+	// the colon.
+	Scheme() string
+
+	// RepoURL returns where repo is found, e.g. its web page.
+	RepoURL(repo string) string
+
+	// endpoint is the base URL used when none is configured.
+	endpoint() string
+
+	// manifest describes the model file of quant in repo and the mmproj
+	// published with it. Split models are described by their first shard.
+	manifest(ctx context.Context, client *http.Client, baseURL, repo, quant string) (ggufFileInfo, error)
+
+	// listFiles lists the files in dir of repo. An empty dir lists the
+	// repository root; recursive also lists the files in subdirectories.
+	// Entry paths are relative to the root.
+	listFiles(ctx context.Context, client *http.Client, baseURL, repo, dir string, recursive bool) ([]treeEntry, error)
+
+	// repoTerms returns the license and gating of repo, or nil if the
+	// registry does not publish them.
+	repoTerms(ctx context.Context, client *http.Client, baseURL, repo string) (*RepoTerms, error)
+
+	// fileURL returns the download URL of file, a repository path whose
+	// content has SHA256 sum (empty if unknown).
+	fileURL(baseURL, repo, file, sum string) string
+
+	// client returns the HTTP client for requests to the registry, e.g.
+	// one that authenticates, based on base.
+	client(base *http.Client) *http.Client
+}
+
+// Registries models can be pulled from.
+var (
+	HuggingFace Registry = huggingFace{} // h:org/repo:quant, the default
+	ModelScope  Registry = modelScope{}  // ms:org/repo:quant
+	OCI         Registry = ociRegistry{} // oci://host/repository:tag
+)
+
+// RegistryFor returns the registry an identifier's Registry field names.
+func RegistryFor(scheme string) (Registry, error) {
+	switch scheme {
+	case "", HuggingFace.Scheme():
+		return HuggingFace, nil
+	case ModelScope.Scheme():
+		return ModelScope, nil
+	case OCI.Scheme():
+		return OCI, nil
+	default:
+		return nil, fmt.Errorf("unknown model registry '%s:'", scheme)
+	}
+}
+
+// SetRegistry sets the registry models are pulled from. Endpoints set for
+// another registry are dropped: the puller uses r's own endpoint.
+func (p *Puller) SetRegistry(r Registry) {
+	if p.registry == r {
+		return
+	}
+	p.registry = r
+	p.baseURL = r.endpoint()
+	p.endpoints = nil
+	p.client = r.client(p.client)
+}
+
+// Registry returns the registry models are pulled from.
+func (p *Puller) Registry() Registry {
+	return p.registry
+}
+
+// fileURL returns the download URL of a repository file with SHA256 sum
+// at the active endpoint.
+func (p *Puller) fileURL(repo, file, sum string) string {
+	return p.registry.fileURL(p.baseURL, repo, file, sum)
+}
+
+// huggingFace pulls from HuggingFace and the mirrors that serve its API.
+type huggingFace struct{}
+
+func (huggingFace) Name() string     { return "HuggingFace" }
+func (huggingFace) Scheme() string   { return "h" }
+func (huggingFace) endpoint() string { return defaultHuggingFaceBaseURL }
+
+func (huggingFace) RepoURL(repo string) string {
+	return defaultHuggingFaceBaseURL + "/" + repo
+}
+
+func (huggingFace) fileURL(baseURL, repo, file, _ string) string {
+	return fmt.Sprintf("%s/%s/resolve/main/%s", baseURL, repo, file)
+}
+
+func (huggingFace) client(base *http.Client) *http.Client { return base }
+
+// schemeOf returns the identifier scheme a Registry field of metadata or an
+// identifier holds: empty for HuggingFace.
+func schemeOf(r Registry) string {
+	if r == HuggingFace {
+		return ""
+	}
+	return r.Scheme()
+}
+
+// manifestFromFiles describes quant from a repository's file list, for
+// registries without a manifest API: the quant's file, or the first shard
+// of a split model, and the repository's mmproj, the F16 one when several
+// are published.
+func manifestFromFiles(entries []treeEntry, repo, quant string) (ggufFileInfo, error) {
+	var fi ggufFileInfo
+	for _, q := range quantsFromFiles(entries) {
+		if strings.EqualFold(q.Quant, quant) {
+			fi.Filename = q.Filename
+			break
+		}
+	}
+	if fi.Filename == "" {
+		return ggufFileInfo{}, fmt.Errorf("invalid quantization '%s' for repository '%s'", quant, repo)
+	}
+
+	var mmproj *treeEntry
+	for i, e := range entries {
+		name := strings.ToLower(e.Path)
+		if e.Type != "file" || !strings.Contains(name, "mmproj") || !strings.HasSuffix(name, ".gguf") {
+			continue
+		}
+		if mmproj == nil || isF16(name) && !isF16(strings.ToLower(mmproj.Path)) {
+			mmproj = &entries[i]
+		}
+	}
+
+	for _, e := range entries {
+		if e.Type == "file" && e.Path == fi.Filename {
+			fi.Size = e.fileSize()
+			fi.SHA256, _ = e.sha256()
+		}
+	}
+	if mmproj != nil {
+		fi.MmprojOriginalFilename = mmproj.Path
+		fi.MmprojFilename = mmprojStorageFilename(repo, mmproj.Path)
+		fi.MmprojSize = mmproj.fileSize()
+		fi.MmprojSHA256, _ = mmproj.sha256()
+	}
+	return fi, nil
+}
+
+// isF16 reports whether a lowercase file name is of an F16 file, not BF16.
+func isF16(name string) bool {
+	return strings.Contains(strings.ReplaceAll(name, "bf16", ""), "f16")
+}
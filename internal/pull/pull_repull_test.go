@@ -306,3 +306,49 @@ func TestPull_RePull_SharedMmproj_NotDeleted(t *testing.T) {
 		t.Error("Q8_0 metadata Mmproj should still exist")
 	}
 }
+
+func TestPull_RePull_ModelFilenameChanged(t *testing.T) {
+	// Arrange
+	contentV1 := []byte("model-v1")
+	contentV2 := []byte("model-v2")
+	var version atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			if version.Load() == 0 {
+				json.NewEncoder(w).Encode(newManifestResponse("model-v1-Q4_K_M.gguf", int64(len(contentV1)), computeSHA256(contentV1)))
+			} else {
+				json.NewEncoder(w).Encode(newManifestResponse("model-v2-Q4_K_M.gguf", int64(len(contentV2)), computeSHA256(contentV2)))
+			}
+		case strings.Contains(r.URL.Path, "/resolve/main/model-v1-Q4_K_M.gguf"):
+			w.Write(contentV1)
+		case strings.Contains(r.URL.Path, "/resolve/main/model-v2-Q4_K_M.gguf"):
+			w.Write(contentV2)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+	if _, err := puller.Pull(context.Background(), "org/model", "Q4_K_M"); err != nil {
+		t.Fatalf("first Pull() error = %v", err)
+	}
+
+	// Act
+	version.Store(1)
+	_, err := puller.Pull(context.Background(), "org/model", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("second Pull() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "model-v1-Q4_K_M.gguf")); !os.IsNotExist(err) {
+		t.Error("old model file should be deleted after upstream rename")
+	}
+	entry := puller.metadata.Find("org/model", "Q4_K_M")
+	if entry == nil || entry.Filename != "model-v2-Q4_K_M.gguf" || entry.SHA256 != computeSHA256(contentV2) {
+		t.Errorf("entry = %+v, want v2 filename and hash", entry)
+	}
+}
@@ -0,0 +1,134 @@
+package pull
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrorKind classifies why a download failed.
+type ErrorKind int
+
+const (
+	// ErrorNetwork is a connection that could not be made or dropped
+	// mid-transfer. Retrying later resumes the download.
+	ErrorNetwork ErrorKind = iota + 1
+
+	// ErrorServer is a 5xx or 429 response: the endpoint is having trouble.
+	ErrorServer
+
+	// ErrorRequest is any other 4xx response: the file is missing or
+	// access to it is denied, so retrying does not help.
+	ErrorRequest
+
+	// ErrorIntegrity is downloaded content that does not match its SHA256,
+	// or whose SHA256 is not published.
+	ErrorIntegrity
+)
+
+// DownloadError is a failed download, classified by Kind.
+type DownloadError struct {
+	Kind   ErrorKind
+	File   string
+	Status int // HTTP status for ErrorServer and ErrorRequest; 0 otherwise
+	Err    error
+}
+
+func (e *DownloadError) Error() string { return e.Err.Error() }
+func (e *DownloadError) Unwrap() error { return e.Err }
+
+// Retryable reports whether trying the download again may succeed.
+func (e *DownloadError) Retryable() bool {
+	return e.Kind == ErrorNetwork || e.Kind == ErrorServer
+}
+
+// networkError classifies a failed request or read as a network error,
+// unless ctx ended, in which case the download was canceled.
+func networkError(ctx context.Context, file string, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return &DownloadError{Kind: ErrorNetwork, File: file, Err: err}
+}
+
+// statusError classifies an unexpected HTTP status.
+func statusError(file string, code int) error {
+	kind := ErrorRequest
+	if isUnavailableStatus(code) {
+		kind = ErrorServer
+	}
+	return &DownloadError{Kind: kind, File: file, Status: code, Err: fmt.Errorf("download failed: status %d", code)}
+}
+
+// integrityError reports that file failed verification.
+func integrityError(file string, err error) error {
+	return &DownloadError{Kind: ErrorIntegrity, File: file, Err: fmt.Errorf("integrity verification failed for %s: %w", file, err)}
+}
+
+// errNoHash is the integrity failure of a file whose SHA256 the API did not
+// publish; downloads are fail-closed.
+var errNoHash = errors.New("no SHA256 hash available from API")
+
+// errXetOnly is errNoHash for a file that lists only its Xet hash. Xet
+// hashes (a BLAKE3 Merkle tree over content-defined chunks) are not
+// verified, so such a file is refused like one without any hash.
+var errXetOnly = fmt.Errorf("%w (only a Xet hash is published, and alpaca does not verify Xet hashes)", errNoHash)
+
+// RetryPolicy controls how a download is retried after a network error or
+// a server error. The .part file is kept between tries, so each retry
+// resumes where the last one stopped.
+type RetryPolicy struct {
+	Attempts  int           // tries in total; 1 disables retries
+	BaseDelay time.Duration // wait before the first retry, doubled for each later one
+	MaxDelay  time.Duration // upper bound of the wait
+}
+
+// DefaultRetryPolicy is used unless SetRetryPolicy is called.
+var DefaultRetryPolicy = RetryPolicy{Attempts: 4, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+// SetRetryPolicy sets how downloads are retried. Attempts below 1 are
+// treated as 1.
+func (p *Puller) SetRetryPolicy(r RetryPolicy) {
+	r.Attempts = max(r.Attempts, 1)
+	p.retry = r
+}
+
+// delay returns the wait before retry n (1-based): exponential backoff
+// with jitter, so concurrent downloads do not retry in lockstep.
+func (r RetryPolicy) delay(n int) time.Duration {
+	d := r.BaseDelay
+	for i := 1; i < n && d < r.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, r.MaxDelay)
+	if d <= 0 {
+		return 0
+	}
+	// Between half and all of d
+	return d/2 + rand.N(d/2+1)
+}
+
+// withRetry calls fn until it succeeds, fails with an error that is not
+// retryable, or the policy's attempts are used up.
+func (p *Puller) withRetry(ctx context.Context, file string, fn func() (int64, error)) (int64, error) {
+	for attempt := 1; ; attempt++ {
+		n, err := fn()
+		var dlErr *DownloadError
+		if err == nil || attempt >= p.retry.Attempts || !errors.As(err, &dlErr) || !dlErr.Retryable() {
+			return n, err
+		}
+
+		wait := p.retry.delay(attempt)
+		slog.Warn("download failed, retrying", "file", file, "attempt", attempt, "wait", wait, "error", err)
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return 0, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadFile_RetryResumesAfterDroppedConnection(t *testing.T) {
+	// Arrange
+	const testETag = `"abc123"`
+	const fullContent = "0123456789"
+	var requests atomic.Int32
+	var resumedFrom atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Promise the whole file, send half, then drop the connection
+			w.Header().Set("ETag", testETag)
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(fullContent)))
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(fullContent[:5]))
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		resumedFrom.Store(r.Header.Get("Range"))
+		var start int
+		fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		w.Header().Set("ETag", testETag)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(fullContent)-1, len(fullContent)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(fullContent[start:]))
+	}))
+	t.Cleanup(srv.Close)
+	modelsDir := t.TempDir()
+	puller := newTestPuller(modelsDir, srv.URL)
+
+	// Act
+	size, err := puller.downloadFile(context.Background(), "test/repo", "model.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("downloadFile() error = %v", err)
+	}
+	if size != int64(len(fullContent)) {
+		t.Errorf("size = %d, want %d", size, len(fullContent))
+	}
+	if got := resumedFrom.Load(); got != "bytes=5-" {
+		t.Errorf("retry Range = %v, want bytes=5-", got)
+	}
+	data, err := os.ReadFile(filepath.Join(modelsDir, "model.gguf"))
+	if err != nil || string(data) != fullContent {
+		t.Errorf("file = %q, %v, want %q", data, err, fullContent)
+	}
+}
+
+func TestDownloadFile_RetryByStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // responses before the file is served
+		wantErr      bool
+		wantKind     ErrorKind
+		wantRequests int32
+	}{
+		{"server error then success", []int{503, 500}, false, 0, 3},
+		{"rate limited then success", []int{429}, false, 0, 2},
+		{"server error every time", []int{503, 503, 503, 503}, true, ErrorServer, 4},
+		{"not found is not retried", []int{404}, true, ErrorRequest, 1},
+		{"forbidden is not retried", []int{403}, true, ErrorRequest, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				if n <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[n-1])
+					return
+				}
+				w.Write([]byte("content"))
+			}))
+			t.Cleanup(srv.Close)
+			puller := newTestPuller(t.TempDir(), srv.URL)
+
+			// Act
+			_, err := puller.downloadFile(context.Background(), "test/repo", "model.gguf")
+
+			// Assert
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("downloadFile() error = %v", err)
+				}
+				return
+			}
+			var dlErr *DownloadError
+			if !errors.As(err, &dlErr) {
+				t.Fatalf("downloadFile() error = %v, want *DownloadError", err)
+			}
+			if dlErr.Kind != tt.wantKind || dlErr.Status != tt.statuses[len(tt.statuses)-1] {
+				t.Errorf("error kind = %v, status = %d, want %v, %d", dlErr.Kind, dlErr.Status, tt.wantKind, tt.statuses[len(tt.statuses)-1])
+			}
+		})
+	}
+}
+
+func TestDownloadFile_RetryAttemptsConfigurable(t *testing.T) {
+	// Arrange
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+	puller.SetRetryPolicy(RetryPolicy{Attempts: 1})
+
+	// Act
+	_, err := puller.downloadFile(context.Background(), "test/repo", "model.gguf")
+
+	// Assert
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1 (retries disabled)", got)
+	}
+}
+
+func TestDownloadFile_RetryStopsWhenCanceled(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+	puller.SetRetryPolicy(RetryPolicy{Attempts: 4, BaseDelay: time.Hour, MaxDelay: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, err := puller.downloadFile(ctx, "test/repo", "model.gguf")
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("downloadFile() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestPull_IntegrityErrorKind(t *testing.T) {
+	// Arrange
+	content := []byte("model-content")
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/test/model/manifests/Q4_K_M":
+			json.NewEncoder(w).Encode(newManifestResponse("model-Q4_K_M.gguf", int64(len(content)), computeSHA256([]byte("other"))))
+		default:
+			downloads.Add(1)
+			w.Write(content)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.Pull(context.Background(), "test/model", "Q4_K_M")
+
+	// Assert
+	var dlErr *DownloadError
+	if !errors.As(err, &dlErr) || dlErr.Kind != ErrorIntegrity || dlErr.File != "model-Q4_K_M.gguf" {
+		t.Fatalf("Pull() error = %#v, want integrity DownloadError for model-Q4_K_M.gguf", err)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("downloads = %d, want 1 (integrity failures are not retried)", got)
+	}
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	r := RetryPolicy{Attempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		retry    int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{4, 2500 * time.Millisecond, 5 * time.Second},  // capped
+		{80, 2500 * time.Millisecond, 5 * time.Second}, // many retries
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.retry), func(t *testing.T) {
+			for range 20 {
+				// Act
+				got := r.delay(tt.retry)
+
+				// Assert
+				if got < tt.min || got > tt.max {
+					t.Fatalf("delay(%d) = %v, want between %v and %v", tt.retry, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
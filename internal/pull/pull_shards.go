@@ -0,0 +1,130 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// shardPattern matches the name llama.cpp's gguf-split gives each part of a
+// split model, e.g. "Qwen3-235B-Q4_K_M-00001-of-00003.gguf".
+var shardPattern = regexp.MustCompile(`^(.*)-(\d{5})-of-(\d{5})\.gguf$`)
+
+// shardFile is one file of a model as published in the repository.
+type shardFile struct {
+	Path     string // path in the repository
+	Filename string // storage filename in the models directory
+	SHA256   string // empty if not available from API
+	Size     int64
+}
+
+// splitShardName returns the shared prefix and shard count of a split model
+// file name. ok is false for names that are not a shard of a multi-file model.
+func splitShardName(name string) (prefix string, count int, ok bool) {
+	m := shardPattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", 0, false
+	}
+	count, _ = strconv.Atoi(m[3])
+	if count < 2 {
+		return "", 0, false
+	}
+	return m[1], count, true
+}
+
+// fetchShards lists every shard of the split model whose first shard is
+// first (a repository path). Shards are stored flat in the models directory
+// under their base names, next to each other as llama-server expects.
+func (p *Puller) fetchShards(ctx context.Context, repo, first string) ([]shardFile, error) {
+	prefix, count, _ := splitShardName(first)
+	dir := path.Dir(first)
+	treeDir := dir
+	if treeDir == "." {
+		treeDir = ""
+	}
+	entries, err := p.fetchRepoTree(ctx, repo, treeDir, false)
+	if err != nil {
+		return nil, err
+	}
+	byPath := filesByPath(entries)
+
+	shards := make([]shardFile, 0, count)
+	for i := 1; i <= count; i++ {
+		shardPath := fmt.Sprintf("%s-%05d-of-%05d.gguf", prefix, i, count)
+		e, ok := byPath[shardPath]
+		if !ok {
+			return nil, fmt.Errorf("shard %s not found in repository '%s'", shardPath, repo)
+		}
+		shard := shardFile{Path: shardPath, Filename: path.Base(shardPath), Size: e.fileSize()}
+		if !filepath.IsLocal(shard.Filename) {
+			return nil, fmt.Errorf("invalid filename from API: %s", shardPath)
+		}
+		// Fail before downloading anything: a shard without a hash
+		// could not be verified
+		sum, err := e.sha256()
+		if err != nil {
+			return nil, integrityError(shard.Filename, err)
+		}
+		shard.SHA256 = sum
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
+// downloadShards downloads and verifies every shard, reporting progress
+// across all of them as one file of size total. Shards already on disk with
+// the expected hash (e.g. from an interrupted pull) are not downloaded again.
+func (p *Puller) downloadShards(ctx context.Context, repo string, shards []shardFile, total int64) (int64, error) {
+	t := p.currentTransfer()
+	t.shards, t.total = len(shards), total
+	defer func() { t.offset, t.total = 0, 0 }()
+
+	var done int64
+	for i, s := range shards {
+		if p.hashMatches(ctx, s.Filename, s.SHA256) {
+			done += s.Size
+			t.meter.skip(s.Size)
+			continue
+		}
+		t.shard, t.file, t.offset = i+1, s.Filename, done
+		n, err := p.downloadVerified(ctx, repo, s)
+		if err != nil {
+			return 0, err
+		}
+		done += n
+	}
+	return done, nil
+}
+
+// downloadVerified downloads f and checks its SHA256 (fail-closed: a missing
+// or mismatched hash removes the file), then moves it into the blob store.
+// Content already in the store is linked instead of downloaded.
+func (p *Puller) downloadVerified(ctx context.Context, repo string, f shardFile) (int64, error) {
+	if size, ok, err := p.linkStored(f.Filename, f.SHA256); err != nil || ok {
+		if ok {
+			p.recordChecksum(ctx, f.Filename, f.SHA256)
+		}
+		return size, err
+	}
+	size, err := p.downloadURL(ctx, p.fileURL(repo, f.Path, f.SHA256), f.Filename)
+	if err != nil {
+		return 0, err
+	}
+	if f.SHA256 == "" {
+		p.removeDownloadedFile(f.Filename)
+		return 0, integrityError(f.Filename, errNoHash)
+	}
+	if err := p.verifyFileHash(f.Filename, f.SHA256); err != nil {
+		p.removeDownloadedFile(f.Filename)
+		return 0, integrityError(f.Filename, err)
+	}
+	if err := p.blobs.Adopt(f.Filename, f.SHA256); err != nil {
+		p.removeDownloadedFile(f.Filename)
+		return 0, err
+	}
+	p.recordChecksum(ctx, f.Filename, f.SHA256)
+	return size, nil
+}
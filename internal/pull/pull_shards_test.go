@@ -0,0 +1,267 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newShardTestServer serves a model split into len(shards) files under the
+// Q4_K_M/ directory. downloads counts requests for shard files.
+func newShardTestServer(t *testing.T, shards [][]byte, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	name := func(i int) string {
+		return fmt.Sprintf("Q4_K_M/model-Q4_K_M-%05d-of-%05d.gguf", i+1, len(shards))
+	}
+	var tree []treeEntry
+	for i, data := range shards {
+		tree = append(tree, treeEntry{Type: "file", Path: name(i), LFS: &treeLFSInfo{OID: computeSHA256(data), Size: int64(len(data))}})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			json.NewEncoder(w).Encode(newManifestResponse(name(0), int64(len(shards[0])), computeSHA256(shards[0])))
+		case strings.HasSuffix(r.URL.Path, "/tree/main/Q4_K_M"):
+			json.NewEncoder(w).Encode(tree)
+		case strings.Contains(r.URL.Path, "/resolve/main/"):
+			for i, data := range shards {
+				if strings.HasSuffix(r.URL.Path, "/"+name(i)) {
+					downloads.Add(1)
+					w.Write(data)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSplitShardName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantPrefix string
+		wantCount  int
+		wantOK     bool
+	}{
+		{"model-Q4_K_M-00001-of-00003.gguf", "model-Q4_K_M", 3, true},
+		{"dir/model-00002-of-00002.gguf", "dir/model", 2, true},
+		{"model-00001-of-00001.gguf", "", 0, false},
+		{"model-Q4_K_M.gguf", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, count, ok := splitShardName(tt.name)
+			if prefix != tt.wantPrefix || count != tt.wantCount || ok != tt.wantOK {
+				t.Errorf("splitShardName(%q) = %q, %d, %v, want %q, %d, %v",
+					tt.name, prefix, count, ok, tt.wantPrefix, tt.wantCount, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPull_ShardedModel(t *testing.T) {
+	// Arrange
+	shards := [][]byte{[]byte("shard-one"), []byte("shard-two!"), []byte("three")}
+	var downloads atomic.Int32
+	srv := newShardTestServer(t, shards, &downloads)
+	tmpDir := t.TempDir()
+	puller := newTestPuller(tmpDir, srv.URL)
+
+	var lastDownloaded, lastTotal int64
+	puller.SetProgressFunc(func(downloaded, total int64) {
+		if downloaded < lastDownloaded {
+			t.Errorf("progress went backwards: %d after %d", downloaded, lastDownloaded)
+		}
+		lastDownloaded, lastTotal = downloaded, total
+	})
+	var starts int
+	puller.SetFileStartFunc(func(string, int64, int, int) { starts++ })
+
+	// Act
+	result, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	const total = 24
+	if result.Filename != "model-Q4_K_M-00001-of-00003.gguf" || result.Size != total {
+		t.Errorf("result = %+v, want first shard and total size %d", result, total)
+	}
+	if lastDownloaded != total || lastTotal != total {
+		t.Errorf("final progress = %d/%d, want %d/%d", lastDownloaded, lastTotal, total, total)
+	}
+	if starts != 1 {
+		t.Errorf("file start callbacks = %d, want 1 for the whole model", starts)
+	}
+	for i := range shards {
+		name := fmt.Sprintf("model-Q4_K_M-%05d-of-00003.gguf", i+1)
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("shard %s not saved: %v", name, err)
+		}
+	}
+	entry := puller.metadata.Find("org/big-GGUF", "Q4_K_M")
+	if entry == nil {
+		t.Fatal("metadata entry missing")
+	}
+	wantFiles := []string{
+		"model-Q4_K_M-00001-of-00003.gguf",
+		"model-Q4_K_M-00002-of-00003.gguf",
+		"model-Q4_K_M-00003-of-00003.gguf",
+	}
+	if !slices.Equal(entry.Files(), wantFiles) || entry.Size != total {
+		t.Errorf("entry files = %v, size = %d, want %v, %d", entry.Files(), entry.Size, wantFiles, total)
+	}
+}
+
+func TestPull_ShardedModelResumesAndSkipsVerifiedShards(t *testing.T) {
+	// Arrange
+	shards := [][]byte{[]byte("shard-one"), []byte("shard-two")}
+	var downloads atomic.Int32
+	srv := newShardTestServer(t, shards, &downloads)
+	tmpDir := t.TempDir()
+	// First shard left behind by an interrupted pull
+	if err := os.WriteFile(filepath.Join(tmpDir, "model-Q4_K_M-00001-of-00002.gguf"), shards[0], 0644); err != nil {
+		t.Fatal(err)
+	}
+	puller := newTestPuller(tmpDir, srv.URL)
+
+	// Act
+	_, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("shard downloads = %d, want 1", got)
+	}
+}
+
+func TestPull_ShardedModelAlreadyUpToDate(t *testing.T) {
+	// Arrange
+	shards := [][]byte{[]byte("shard-one"), []byte("shard-two")}
+	var downloads atomic.Int32
+	srv := newShardTestServer(t, shards, &downloads)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+	if _, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M"); err != nil {
+		t.Fatalf("first Pull() error = %v", err)
+	}
+
+	// Act
+	result, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if !result.AlreadyUpToDate || result.Size != 18 {
+		t.Errorf("result = %+v, want up to date with size 18", result)
+	}
+	if got := downloads.Load(); got != 2 {
+		t.Errorf("shard downloads = %d, want 2 (none on re-pull)", got)
+	}
+}
+
+func TestPull_ShardMissingFromRepository(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/manifests/"):
+			json.NewEncoder(w).Encode(newManifestResponse("model-00001-of-00002.gguf", 1, "a"))
+		case strings.Contains(r.URL.Path, "/tree/main"):
+			json.NewEncoder(w).Encode([]treeEntry{
+				{Type: "file", Path: "model-00001-of-00002.gguf", LFS: &treeLFSInfo{OID: "a", Size: 1}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	_, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M")
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "model-00002-of-00002.gguf not found") {
+		t.Fatalf("Pull() error = %v, want missing shard error", err)
+	}
+}
+
+func TestListQuants_CombinesShards(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]treeEntry{
+			{Type: "file", Path: "model-Q8_0-00002-of-00002.gguf", LFS: &treeLFSInfo{OID: "b", Size: 60}},
+			{Type: "file", Path: "model-Q8_0-00001-of-00002.gguf", LFS: &treeLFSInfo{OID: "a", Size: 70}},
+			{Type: "file", Path: "model-Q4_K_M.gguf", LFS: &treeLFSInfo{OID: "c", Size: 80}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	// Act
+	quants, err := puller.ListQuants(context.Background(), "org/big-GGUF")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ListQuants() error = %v", err)
+	}
+	if len(quants) != 2 {
+		t.Fatalf("quants = %+v, want 2", quants)
+	}
+	want := QuantInfo{Quant: "Q8_0", Filename: "model-Q8_0-00001-of-00002.gguf", Size: 130, Files: 2}
+	if quants[0] != want {
+		t.Errorf("quants[0] = %+v, want %+v", quants[0], want)
+	}
+}
+
+func TestPull_ShardedModelReportsShards(t *testing.T) {
+	// Arrange
+	shards := [][]byte{[]byte("shard-one"), []byte("shard-two!"), []byte("three")}
+	var downloads atomic.Int32
+	srv := newShardTestServer(t, shards, &downloads)
+	puller := newTestPuller(t.TempDir(), srv.URL)
+
+	var reports []Progress
+	puller.SetReportFunc(func(pr Progress) { reports = append(reports, pr) })
+
+	// Act
+	_, err := puller.Pull(context.Background(), "org/big-GGUF", "Q4_K_M")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	var seen []int
+	for _, pr := range reports {
+		if pr.Phase != PhaseModel || pr.Shards != 3 || pr.Total != 24 {
+			t.Errorf("report = %+v, want model phase of 3 shards, 24 bytes", pr)
+		}
+		if len(seen) == 0 || seen[len(seen)-1] != pr.Shard {
+			seen = append(seen, pr.Shard)
+		}
+	}
+	if !slices.Equal(seen, []int{1, 2, 3}) {
+		t.Errorf("shards reported = %v, want [1 2 3]", seen)
+	}
+	if last := reports[len(reports)-1]; last.Downloaded != 24 {
+		t.Errorf("final report = %+v, want 24 bytes downloaded", last)
+	}
+}
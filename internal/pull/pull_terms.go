@@ -0,0 +1,134 @@
+package pull
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// RepoTerms is what a registry publishes about the terms a repository's
+// files are distributed under.
+type RepoTerms struct {
+	License     string // license identifier, e.g. "apache-2.0"; empty if none is published
+	LicenseName string // the name of a license published as "other", e.g. "qwen-research"
+	Gated       bool   // the registry requires accepting the terms before downloading
+}
+
+// DisplayLicense returns the license as it is shown to users.
+func (t *RepoTerms) DisplayLicense() string {
+	switch {
+	case t.LicenseName != "" && (t.License == "" || t.License == "other"):
+		return t.LicenseName
+	case t.License != "":
+		return t.License
+	default:
+		return "not specified"
+	}
+}
+
+// NonCommercial reports whether the license forbids commercial use, judged
+// from its identifier: the Creative Commons NC licenses and custom ones
+// named non-commercial or research-only.
+func (t *RepoTerms) NonCommercial() bool {
+	for _, name := range []string{t.License, t.LicenseName} {
+		name = strings.ToLower(name)
+		if slices.Contains(strings.Split(name, "-"), "nc") {
+			return true
+		}
+		for _, word := range []string{"noncommercial", "non-commercial", "research"} {
+			if strings.Contains(name, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetRepoTerms fetches the license and gating of repo. Registries that do
+// not publish them return nil terms.
+func (p *Puller) GetRepoTerms(ctx context.Context, repo string) (*RepoTerms, error) {
+	var terms *RepoTerms
+	err := p.tryEndpoints(func(baseURL string) error {
+		var err error
+		terms, err = p.registry.repoTerms(ctx, p.client, baseURL, repo)
+		return err
+	})
+	return terms, err
+}
+
+// modelInfoResponse represents the parts of the HuggingFace model info API
+// response that describe a repository's terms.
+type modelInfoResponse struct {
+	Gated    any `json:"gated"` // false, or "auto" or "manual" for how access requests are approved
+	CardData struct {
+		License     any    `json:"license"` // a string, or a list for dual-licensed models
+		LicenseName string `json:"license_name"`
+	} `json:"cardData"`
+	Tags []string `json:"tags"`
+}
+
+func (huggingFace) repoTerms(ctx context.Context, client *http.Client, baseURL, repo string) (*RepoTerms, error) {
+	url := fmt.Sprintf("%s/api/models/%s", baseURL, repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("fetch model info: %w", err)
+		}
+		return nil, &endpointUnavailableError{fmt.Errorf("fetch model info: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("repository not found: %s", repo)
+	}
+	if isUnavailableStatus(resp.StatusCode) {
+		return nil, &endpointUnavailableError{fmt.Errorf("failed to fetch model info: status %d", resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch model info: status %d", resp.StatusCode)
+	}
+
+	var info modelInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("parse model info: %w", err)
+	}
+
+	terms := &RepoTerms{LicenseName: info.CardData.LicenseName}
+	switch v := info.Gated.(type) {
+	case bool:
+		terms.Gated = v
+	case string:
+		terms.Gated = v != ""
+	}
+	switch v := info.CardData.License.(type) {
+	case string:
+		terms.License = v
+	case []any:
+		var licenses []string
+		for _, l := range v {
+			if s, ok := l.(string); ok {
+				licenses = append(licenses, s)
+			}
+		}
+		terms.License = strings.Join(licenses, ", ")
+	}
+	// Repositories without a model card still carry the license as a tag
+	if terms.License == "" {
+		for _, tag := range info.Tags {
+			if l, ok := strings.CutPrefix(tag, "license:"); ok {
+				terms.License = l
+				break
+			}
+		}
+	}
+	return terms, nil
+}
@@ -0,0 +1,111 @@
+package pull
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetRepoTerms(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want RepoTerms
+	}{
+		{
+			name: "license from the model card",
+			body: `{"gated": false, "cardData": {"license": "apache-2.0"}, "tags": ["gguf", "license:mit"]}`,
+			want: RepoTerms{License: "apache-2.0"},
+		},
+		{
+			name: "gated with manual approval",
+			body: `{"gated": "manual", "cardData": {"license": "llama3.1"}}`,
+			want: RepoTerms{License: "llama3.1", Gated: true},
+		},
+		{
+			name: "custom license",
+			body: `{"gated": "auto", "cardData": {"license": "other", "license_name": "qwen-research"}}`,
+			want: RepoTerms{License: "other", LicenseName: "qwen-research", Gated: true},
+		},
+		{
+			name: "dual license",
+			body: `{"cardData": {"license": ["apache-2.0", "cc-by-nc-4.0"]}}`,
+			want: RepoTerms{License: "apache-2.0, cc-by-nc-4.0"},
+		},
+		{
+			name: "license from tags without a model card",
+			body: `{"gated": false, "tags": ["gguf", "license:cc-by-nc-sa-4.0"]}`,
+			want: RepoTerms{License: "cc-by-nc-sa-4.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/models/org/model" {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+			puller := newTestPuller(t.TempDir(), srv.URL)
+
+			// Act
+			terms, err := puller.GetRepoTerms(context.Background(), "org/model")
+
+			// Assert
+			if err != nil {
+				t.Fatalf("GetRepoTerms() error = %v", err)
+			}
+			if !reflect.DeepEqual(*terms, tt.want) {
+				t.Errorf("GetRepoTerms() = %+v, want %+v", *terms, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRepoTerms_NotPublished(t *testing.T) {
+	// Arrange
+	puller := newTestPuller(t.TempDir(), "")
+	puller.SetRegistry(ModelScope)
+
+	// Act
+	terms, err := puller.GetRepoTerms(context.Background(), "org/model")
+
+	// Assert
+	if err != nil || terms != nil {
+		t.Errorf("GetRepoTerms() = %+v, %v, want nil terms", terms, err)
+	}
+}
+
+func TestRepoTerms_NonCommercial(t *testing.T) {
+	tests := []struct {
+		terms RepoTerms
+		want  bool
+	}{
+		{RepoTerms{License: "apache-2.0"}, false},
+		{RepoTerms{License: "cc-by-4.0"}, false},
+		{RepoTerms{License: "cc-by-nc-4.0"}, true},
+		{RepoTerms{License: "cc-by-nc-sa-4.0"}, true},
+		{RepoTerms{License: "apache-2.0, cc-by-nc-4.0"}, true},
+		{RepoTerms{License: "other", LicenseName: "qwen-research"}, true},
+		{RepoTerms{License: "other", LicenseName: "Non-Commercial License"}, true},
+		{RepoTerms{License: "llama3.1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.terms.DisplayLicense(), func(t *testing.T) {
+			// Act
+			got := tt.terms.NonCommercial()
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("NonCommercial() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
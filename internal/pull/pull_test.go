@@ -163,6 +163,50 @@ func TestDownloadFile_ResumeSuccess(t *testing.T) {
 	}
 }
 
+func TestDownloadFile_ResumeReportsSavedTotal(t *testing.T) {
+	const testETag = `"abc123"`
+	const fullContent = "0123456789"
+
+	// Arrange: the server streams the rest without a Content-Length
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 5-9/%d", len(fullContent)))
+		w.Header().Set("ETag", testETag)
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte(fullContent[5:]))
+		w.(http.Flusher).Flush()
+	}))
+	defer server.Close()
+
+	modelsDir := t.TempDir()
+	os.WriteFile(filepath.Join(modelsDir, "model.gguf.part"), []byte(fullContent[:5]), 0644)
+	os.WriteFile(filepath.Join(modelsDir, "model.gguf.etag"), []byte(testETag), 0644)
+	os.WriteFile(filepath.Join(modelsDir, "model.gguf.progress"), []byte(`{"total":10}`), 0644)
+
+	puller := NewPuller(modelsDir)
+	puller.baseURL = server.URL
+	var reports []Progress
+	puller.SetReportFunc(func(pr Progress) { reports = append(reports, pr) })
+
+	// Act
+	_, err := puller.downloadFile(context.Background(), "test/repo", "model.gguf")
+
+	// Assert
+	if err != nil {
+		t.Fatalf("downloadFile() error = %v", err)
+	}
+	if len(reports) == 0 || reports[0].Downloaded != 5 || reports[0].Total != 10 {
+		t.Fatalf("first report = %+v, want 5 of 10 bytes before any new data", reports)
+	}
+	for _, pr := range reports {
+		if pr.Total != 10 {
+			t.Errorf("report %+v, want Total 10 from the .progress file", pr)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(modelsDir, "model.gguf.progress")); !os.IsNotExist(err) {
+		t.Error(".progress file should not exist after successful download")
+	}
+}
+
 func TestDownloadFile_ETagMismatch(t *testing.T) {
 	const serverETag = `"newetag"`
 	const oldETag = `"oldetag"`
@@ -324,6 +368,11 @@ func TestDownloadFile_InterruptionKeepsPartFiles(t *testing.T) {
 		t.Error(".etag file should exist after interruption")
 	}
 
+	// Verify .progress records the full size
+	if state, err := os.ReadFile(filepath.Join(modelsDir, "model.gguf.progress")); err != nil || string(state) != `{"total":10}` {
+		t.Errorf(".progress = %q, %v, want the full size after interruption", state, err)
+	}
+
 	// Verify final file does not exist
 	if _, err := os.Stat(filepath.Join(modelsDir, "model.gguf")); !os.IsNotExist(err) {
 		t.Error("final file should not exist after interruption")
@@ -0,0 +1,100 @@
+package pull
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Defaults of the HTTP transport downloads use, as in
+// http.DefaultTransport.
+const (
+	defaultConnectTimeout = 30 * time.Second
+	defaultMaxIdleConns   = 100
+)
+
+// transportOptions tune the HTTP transport downloads use. Zero values
+// leave the defaults.
+type transportOptions struct {
+	ConnectTimeout time.Duration // for the TCP connection to the server or proxy
+	TLSMinVersion  uint16        // e.g. tls.VersionTLS13; defaults to TLS 1.2
+	CABundle       string        // PEM file of CAs trusted in addition to the system's
+	MaxIdleConns   int           // idle connections kept for reuse, per host and in total
+}
+
+// newTransport returns an HTTP transport with opts that sends requests
+// through the proxy HTTP_PROXY, HTTPS_PROXY and NO_PROXY (or their
+// lowercase forms) name.
+func newTransport(opts transportOptions) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+
+	timeout := opts.ConnectTimeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	t.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+
+	idle := opts.MaxIdleConns
+	if idle <= 0 {
+		idle = defaultMaxIdleConns
+	}
+	// Concurrent downloads mostly reach one host
+	t.MaxIdleConns = idle
+	t.MaxIdleConnsPerHost = idle
+
+	t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts.TLSMinVersion != 0 {
+		t.TLSClientConfig.MinVersion = opts.TLSMinVersion
+	}
+	if opts.CABundle != "" {
+		pool, err := certPool(opts.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	return t, nil
+}
+
+// certPool returns the system's certificate pool with the CAs in the PEM
+// file at path added, e.g. the one a TLS-intercepting proxy signs with.
+func certPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// parseTLSVersion returns the TLS version named like "1.2". Versions
+// before 1.2 are refused: they are deprecated (RFC 8996), and accepting
+// them would only weaken the default.
+func parseTLSVersion(s string) (uint16, error) {
+	switch s {
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("TLS %s is not supported (use 1.2 or 1.3)", s)
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q (use 1.2 or 1.3)", s)
+	}
+}
+
+// SetTransport sets the HTTP transport requests are sent through.
+func (p *Puller) SetTransport(rt http.RoundTripper) {
+	p.client = p.registry.client(&http.Client{Transport: rt})
+}
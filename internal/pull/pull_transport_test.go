@@ -0,0 +1,108 @@
+package pull
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+)
+
+func TestTransportFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         config.Config
+		wantTimeout time.Duration
+		wantTLS     uint16
+		wantIdle    int
+		wantErr     string
+	}{
+		{"defaults", config.Config{}, defaultConnectTimeout, tls.VersionTLS12, defaultMaxIdleConns, ""},
+		{
+			"configured",
+			config.Config{DownloadConnectTimeout: 5, DownloadTLSMinVersion: "1.3", DownloadMaxIdleConns: 8},
+			5 * time.Second, tls.VersionTLS13, 8, "",
+		},
+		{"unknown TLS version", config.Config{DownloadTLSMinVersion: "1.4"}, 0, 0, 0, "download-tls-min-version"},
+		{"TLS 1.0", config.Config{DownloadTLSMinVersion: "1.0"}, 0, 0, 0, "TLS 1.0 is not supported"},
+		{"TLS 1.1", config.Config{DownloadTLSMinVersion: "1.1"}, 0, 0, 0, "TLS 1.1 is not supported"},
+		{"relative CA bundle", config.Config{DownloadCABundle: "certs/ca.pem"}, 0, 0, 0, "must be an absolute path"},
+		{"missing CA bundle", config.Config{DownloadCABundle: "/nonexistent/ca.pem"}, 0, 0, 0, "read CA bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			transport, err := transportFromConfig(&tt.cfg)
+
+			// Assert
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("transportFromConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("transportFromConfig() error = %v", err)
+			}
+			if transport.Proxy == nil {
+				t.Error("Proxy is nil, want proxies from the environment")
+			}
+			if got := transport.TLSClientConfig.MinVersion; got != tt.wantTLS {
+				t.Errorf("MinVersion = %#x, want %#x", got, tt.wantTLS)
+			}
+			if transport.MaxIdleConns != tt.wantIdle || transport.MaxIdleConnsPerHost != tt.wantIdle {
+				t.Errorf("idle conns = %d/%d per host, want %d", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, tt.wantIdle)
+			}
+		})
+	}
+}
+
+func TestNewFromConfig_CABundle(t *testing.T) {
+	// Arrange
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cardData": {"license": "mit"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	trusted, err := NewFromConfig(dir, &config.Config{DownloadCABundle: bundle}, srv.URL)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, trustedErr := trusted.GetRepoTerms(context.Background(), "org/model")
+	untrusted, err := NewFromConfig(dir, &config.Config{}, srv.URL)
+	if err != nil {
+		t.Fatalf("NewFromConfig() error = %v", err)
+	}
+	_, untrustedErr := untrusted.GetRepoTerms(context.Background(), "org/model")
+	_, notPEMErr := NewFromConfig(dir, &config.Config{DownloadCABundle: notPEM}, srv.URL)
+
+	// Assert
+	if trustedErr != nil {
+		t.Errorf("request with the CA bundle error = %v", trustedErr)
+	}
+	if untrustedErr == nil {
+		t.Error("request without the CA bundle succeeded, want a certificate error")
+	}
+	if notPEMErr == nil || !strings.Contains(notPEMErr.Error(), "no PEM certificates") {
+		t.Errorf("NewFromConfig() with a file without certificates error = %v", notPEMErr)
+	}
+}
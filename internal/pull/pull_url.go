@@ -0,0 +1,91 @@
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/metadata"
+)
+
+// PullURL downloads a GGUF file from a direct URL and registers it in metadata
+// as repo:quant, so it can be referenced as h:repo:quant.
+// If expectedSHA256 is non-empty the file is verified and removed on mismatch.
+func (p *Puller) PullURL(ctx context.Context, rawURL, repo, quant, expectedSHA256 string) (*PullResult, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: expected http(s)://...", rawURL)
+	}
+	filename := path.Base(u.Path)
+	if !strings.HasSuffix(strings.ToLower(filename), ".gguf") || !filepath.IsLocal(filename) {
+		return nil, fmt.Errorf("URL must point to a .gguf file: %s", rawURL)
+	}
+
+	if err := p.metadata.Load(ctx); err != nil {
+		return nil, fmt.Errorf("load metadata: %w", err)
+	}
+	// A file of the same name is replaced only when re-pulling the model
+	// it belongs to; another model's would be overwritten
+	if _, err := os.Lstat(filepath.Join(p.modelsDir, filename)); err == nil {
+		if e := p.metadata.Find(repo, quant); e == nil || e.Filename != filename {
+			return nil, fmt.Errorf("file already exists in models directory: %s", filename)
+		}
+	}
+
+	p.startTransfer(PhaseModel, filename)
+	if p.onFileStart != nil {
+		p.onFileStart(filename, 0, 1, 1)
+	}
+
+	size, err := p.downloadURL(ctx, rawURL, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	sum, err := p.fileSHA256(filename)
+	if err != nil {
+		p.removeDownloadedFile(filename)
+		return nil, err
+	}
+	if expectedSHA256 != "" && sum != strings.ToLower(expectedSHA256) {
+		p.removeDownloadedFile(filename)
+		return nil, integrityError(filename, fmt.Errorf("expected SHA256 %s, got %s", strings.ToLower(expectedSHA256), sum))
+	}
+	if err := p.blobs.Adopt(filename, sum); err != nil {
+		p.removeDownloadedFile(filename)
+		return nil, err
+	}
+
+	destPath := filepath.Join(p.modelsDir, filename)
+	p.reportDone(size)
+	if p.onFileSaved != nil {
+		p.onFileSaved(destPath)
+	}
+
+	// Never persist credentials embedded in the URL
+	source := *u
+	source.User = nil
+
+	entry := metadata.ModelEntry{
+		Repo:         repo,
+		Quant:        quant,
+		Filename:     filename,
+		Size:         size,
+		Source:       source.String(),
+		DownloadedAt: time.Now().UTC(),
+	}
+	if err := p.commitEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return &PullResult{
+		Path:     destPath,
+		Filename: filename,
+		Size:     size,
+	}, nil
+}
@@ -0,0 +1,144 @@
+package pull
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newURLTestServer(t *testing.T, content []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPullURL_Success(t *testing.T) {
+	// Arrange
+	content := []byte("gguf-from-url")
+	srv := newURLTestServer(t, content)
+	tmpDir := t.TempDir()
+	puller := NewPuller(tmpDir)
+	rawURL := strings.Replace(srv.URL, "http://", "http://user:secret@", 1) + "/files/custom-Q4_K_M.gguf"
+
+	// Act
+	result, err := puller.PullURL(context.Background(), rawURL, "me/custom", "Q4_K_M", computeSHA256(content))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PullURL() error = %v", err)
+	}
+	if result.Filename != "custom-Q4_K_M.gguf" {
+		t.Errorf("Filename = %q, want custom-Q4_K_M.gguf", result.Filename)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "custom-Q4_K_M.gguf")); err != nil {
+		t.Errorf("downloaded file missing: %v", err)
+	}
+	entry := puller.metadata.Find("me/custom", "Q4_K_M")
+	if entry == nil {
+		t.Fatal("metadata entry not found")
+	}
+	if strings.Contains(entry.Source, "secret") {
+		t.Errorf("Source should not contain credentials: %q", entry.Source)
+	}
+	if !strings.HasSuffix(entry.Source, "/files/custom-Q4_K_M.gguf") {
+		t.Errorf("Source = %q", entry.Source)
+	}
+}
+
+func TestPullURL_HashMismatch(t *testing.T) {
+	// Arrange
+	srv := newURLTestServer(t, []byte("gguf-from-url"))
+	tmpDir := t.TempDir()
+	puller := NewPuller(tmpDir)
+
+	// Act
+	_, err := puller.PullURL(context.Background(), srv.URL+"/model.gguf", "me/custom", "Q4_K_M", computeSHA256([]byte("other")))
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "integrity verification failed") {
+		t.Fatalf("expected integrity error, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "model.gguf")); !os.IsNotExist(statErr) {
+		t.Error("file should be removed after hash mismatch")
+	}
+	if puller.metadata.Find("me/custom", "Q4_K_M") != nil {
+		t.Error("metadata should not be registered after hash mismatch")
+	}
+}
+
+func TestPullURL_InvalidURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{"not http", "ftp://example.com/model.gguf", "invalid URL"},
+		{"not gguf", "https://example.com/model.bin", "must point to a .gguf file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puller := NewPuller(t.TempDir())
+
+			_, err := puller.PullURL(context.Background(), tt.url, "me/custom", "Q4_K_M", "")
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("PullURL() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPullURL_ExistingFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		wantErr bool
+	}{
+		{"another model's file", "me/other", true},
+		{"same model re-pulled", "me/custom", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := newURLTestServer(t, []byte("new weights"))
+			tmpDir := t.TempDir()
+			puller := NewPuller(tmpDir)
+			if _, err := NewPuller(tmpDir).PullURL(context.Background(), newURLTestServer(t, []byte("old weights")).URL+"/model.gguf", "me/custom", "Q4_K_M", ""); err != nil {
+				t.Fatal(err)
+			}
+
+			// Act
+			_, err := puller.PullURL(context.Background(), srv.URL+"/model.gguf", tt.repo, "Q4_K_M", "")
+
+			// Assert
+			got, readErr := os.ReadFile(filepath.Join(tmpDir, "model.gguf"))
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "already exists") {
+					t.Fatalf("PullURL() error = %v, want already exists", err)
+				}
+				if string(got) != "old weights" {
+					t.Errorf("existing file = %q, want it kept", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PullURL() error = %v", err)
+			}
+			if string(got) != "new weights" {
+				t.Errorf("file = %q, want the re-pulled content", got)
+			}
+		})
+	}
+}
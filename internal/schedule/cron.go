@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expr is a parsed five-field cron expression: minute, hour, day of month,
+// month, and day of week.
+type Expr struct {
+	minute, hour, dom, month, dow uint64 // bit i set when value i matches
+
+	// domAny and dowAny record a "*" day field. As in cron, when both day
+	// fields are restricted a time matches if either one does.
+	domAny, dowAny bool
+}
+
+// field describes the values one cron field accepts.
+type field struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, ...; nil if the field has none
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// 7 is Sunday as well as 0
+	dowField = field{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// Parse parses a cron expression such as "0 9 * * mon-fri". Each field is
+// "*", a value, a range "a-b", or a comma-separated list of these, each
+// optionally followed by a step "/n". Months and days of the week may be
+// given by their three-letter English names.
+func Parse(s string) (*Expr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields (minute hour day-of-month month day-of-week), got %d", s, len(fields))
+	}
+
+	var e Expr
+	var err error
+	if e.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if e.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if e.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if e.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if e.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1
+	}
+	e.domAny = strings.HasPrefix(fields[2], "*")
+	e.dowAny = strings.HasPrefix(fields[4], "*")
+	return &e, nil
+}
+
+// parse returns the set of values s selects as a bit set.
+func (f field) parse(s string) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step '%s' in %s field", stepStr, f.name)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range '%s' in %s field", rng, f.name)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a single number or name.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s '%s' (want %d-%d)", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+// Matches reports whether t, to the minute, is selected by e.
+func (e *Expr) Matches(t time.Time) bool {
+	return e.minute&(1<<t.Minute()) != 0 && e.hour&(1<<t.Hour()) != 0 &&
+		e.month&(1<<int(t.Month())) != 0 && e.matchesDay(t)
+}
+
+// maxSearch bounds Next: every valid expression matches within four years
+// (February 29).
+const maxSearch = 4 * 366 * 24 * 60
+
+// Next returns the first minute after t selected by e, or the zero time if
+// e never matches (e.g. February 30).
+func (e *Expr) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for range maxSearch {
+		switch {
+		case e.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !e.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case e.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case e.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t is selected by e.
+func (e *Expr) matchesDay(t time.Time) bool {
+	domOK := e.dom&(1<<t.Day()) != 0
+	dowOK := e.dow&(1<<int(t.Weekday())) != 0
+	if e.domAny || e.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "0 9 * *"},
+		{"too many fields", "0 9 * * * *"},
+		{"minute out of range", "60 9 * * *"},
+		{"day of month zero", "0 9 0 * *"},
+		{"unknown name", "0 9 * * funday"},
+		{"reversed range", "0 17-9 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"empty list item", "0,,30 * * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := Parse(tt.expr)
+
+			// Assert
+			if err == nil {
+				t.Errorf("Parse(%q) should fail", tt.expr)
+			}
+		})
+	}
+}
+
+func TestExpr_Matches(t *testing.T) {
+	// 2026-10-19 is a Monday
+	monday9 := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"weekday morning", "0 9 * * mon-fri", monday9, true},
+		{"weekday morning on saturday", "0 9 * * mon-fri", monday9.AddDate(0, 0, 5), false},
+		{"wrong minute", "0 9 * * *", monday9.Add(time.Minute), false},
+		{"step", "*/15 * * * *", monday9.Add(45 * time.Minute), true},
+		{"step off", "*/15 * * * *", monday9.Add(50 * time.Minute), false},
+		{"range with step", "0 8-18/2 * * *", monday9.Add(time.Hour), true},
+		{"list", "0 9,17 * * *", monday9.Add(8 * time.Hour), true},
+		{"sunday as 7", "0 9 * * 7", monday9.AddDate(0, 0, 6), true},
+		{"month name", "0 9 * oct *", monday9, true},
+		{"either day field when both set", "0 9 1 * mon", monday9, true},
+		{"neither day field", "0 9 1 * tue", monday9, false},
+		{"day of month with any weekday", "0 9 19 * *", monday9, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+
+			// Act
+			got := e.Matches(tt.t)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpr_Next(t *testing.T) {
+	// Friday evening
+	from := time.Date(2026, 10, 16, 18, 30, 15, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 9 * * mon-fri", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2026, 10, 16, 18, 31, 0, 0, time.UTC)},
+		{"30 18 * * *", time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			// Arrange
+			e, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+
+			// Act
+			got := e.Next(from)
+
+			// Assert
+			if !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
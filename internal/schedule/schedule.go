@@ -0,0 +1,140 @@
+// Package schedule loads and unloads models at times given by cron
+// expressions, so a preset is ready before it is needed.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// Entry is one scheduled action: at each time Cron selects, the model Load
+// names is loaded, or the running model is unloaded if Unload is set.
+type Entry struct {
+	Cron   string `yaml:"cron"`
+	Load   string `yaml:"load,omitempty"`
+	Unload bool   `yaml:"unload,omitempty"`
+}
+
+// Validate checks that the entry has a valid expression and exactly one
+// action.
+func (e Entry) Validate() error {
+	if _, err := Parse(e.Cron); err != nil {
+		return err
+	}
+	if (e.Load != "") == e.Unload {
+		return fmt.Errorf("schedule entry '%s' must set either load or unload", e.Cron)
+	}
+	if e.Load != "" {
+		if _, err := identifier.Parse(e.Load); err != nil {
+			return fmt.Errorf("schedule entry '%s': %w", e.Cron, err)
+		}
+	}
+	return nil
+}
+
+// Action describes what the entry does, e.g. "load p:coder" or "unload".
+func (e Entry) Action() string {
+	if e.Unload {
+		return "unload"
+	}
+	return "load " + e.Load
+}
+
+// Runner loads and unloads models; *daemon.Daemon implements it.
+type Runner interface {
+	Run(ctx context.Context, input string) error
+	Kill(ctx context.Context) error
+	CurrentPreset() *preset.Preset
+}
+
+// Scheduler runs the entries returned by its entry source on a Runner.
+type Scheduler struct {
+	runner  Runner
+	entries func() ([]Entry, error)
+	logger  *slog.Logger
+
+	// Test hooks
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// New creates a scheduler. entries is called every minute, so changes to
+// the schedule take effect without restarting the daemon.
+func New(runner Runner, entries func() ([]Entry, error), logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		runner:  runner,
+		entries: entries,
+		logger:  logger,
+		now:     time.Now,
+		after:   time.After,
+	}
+}
+
+// Run fires due entries at the start of each minute until ctx is done.
+// Actions run one at a time; a load that takes longer than a minute delays
+// the entries after it rather than overlapping them.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		next := s.now().Truncate(time.Minute).Add(time.Minute)
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.after(next.Sub(s.now())):
+		}
+		s.tick(ctx, next)
+	}
+}
+
+// tick runs the entries due at t, in order, so of a load and an unload due
+// at the same minute the later one wins.
+func (s *Scheduler) tick(ctx context.Context, t time.Time) {
+	entries, err := s.entries()
+	if err != nil {
+		s.logger.Warn("failed to read schedule", "error", err)
+		return
+	}
+	for _, e := range entries {
+		if err := e.Validate(); err != nil {
+			s.logger.Warn("skipping invalid schedule entry", "error", err)
+			continue
+		}
+		if expr, _ := Parse(e.Cron); expr.Matches(t) {
+			s.fire(ctx, e)
+		}
+	}
+}
+
+// fire performs the entry's action. A model that is already running is
+// left alone, so a scheduled load does not restart it under its users.
+func (s *Scheduler) fire(ctx context.Context, e Entry) {
+	current := s.runner.CurrentPreset()
+	if e.Unload {
+		if current == nil {
+			return
+		}
+		s.logger.Info("scheduled unload", "cron", e.Cron)
+		if err := s.runner.Kill(ctx); err != nil {
+			s.logger.Warn("scheduled unload failed", "cron", e.Cron, "error", err)
+		}
+		return
+	}
+
+	if e.Load == "" || isLoaded(current, e.Load) {
+		return
+	}
+	s.logger.Info("scheduled load", "cron", e.Cron, "input", e.Load)
+	if err := s.runner.Run(ctx, e.Load); err != nil {
+		s.logger.Warn("scheduled load failed", "cron", e.Cron, "input", e.Load, "error", err)
+	}
+}
+
+// isLoaded reports whether p is the model input identifies. Presets are
+// named without their p: prefix; other models by their identifier.
+func isLoaded(p *preset.Preset, input string) bool {
+	return p != nil && (p.Name == input || "p:"+p.Name == input)
+}
@@ -0,0 +1,187 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/preset"
+)
+
+// fakeRunner records the calls the scheduler makes.
+type fakeRunner struct {
+	current *preset.Preset
+	calls   []string
+	runErr  error
+}
+
+func (r *fakeRunner) Run(ctx context.Context, input string) error {
+	r.calls = append(r.calls, "run "+input)
+	return r.runErr
+}
+
+func (r *fakeRunner) Kill(ctx context.Context) error {
+	r.calls = append(r.calls, "kill")
+	r.current = nil
+	return nil
+}
+
+func (r *fakeRunner) CurrentPreset() *preset.Preset { return r.current }
+
+func newTestScheduler(r Runner, entries ...Entry) *Scheduler {
+	return New(r, func() ([]Entry, error) { return entries, nil }, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantErr bool
+	}{
+		{"load", Entry{Cron: "0 9 * * mon-fri", Load: "p:coder"}, false},
+		{"unload", Entry{Cron: "0 18 * * *", Unload: true}, false},
+		{"no action", Entry{Cron: "0 9 * * *"}, true},
+		{"both actions", Entry{Cron: "0 9 * * *", Load: "p:coder", Unload: true}, true},
+		{"bad identifier", Entry{Cron: "0 9 * * *", Load: "coder"}, true},
+		{"bad cron", Entry{Cron: "9am", Load: "p:coder"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.entry.Validate()
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	monday9 := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		current *preset.Preset
+		entries []Entry
+		want    []string
+	}{
+		{
+			name:    "loads due preset",
+			entries: []Entry{{Cron: "0 9 * * mon-fri", Load: "p:coder"}},
+			want:    []string{"run p:coder"},
+		},
+		{
+			name:    "skips entries not due",
+			entries: []Entry{{Cron: "0 10 * * *", Load: "p:coder"}, {Cron: "0 9 * * sat", Unload: true}},
+			want:    nil,
+		},
+		{
+			name:    "leaves running preset alone",
+			current: &preset.Preset{Name: "coder"},
+			entries: []Entry{{Cron: "0 9 * * *", Load: "p:coder"}},
+			want:    nil,
+		},
+		{
+			name:    "leaves running model alone",
+			current: &preset.Preset{Name: "h:org/repo:Q4_K_M"},
+			entries: []Entry{{Cron: "0 9 * * *", Load: "h:org/repo:Q4_K_M"}},
+			want:    nil,
+		},
+		{
+			name:    "replaces another preset",
+			current: &preset.Preset{Name: "chat"},
+			entries: []Entry{{Cron: "0 9 * * *", Load: "p:coder"}},
+			want:    []string{"run p:coder"},
+		},
+		{
+			name:    "unloads running model",
+			current: &preset.Preset{Name: "chat"},
+			entries: []Entry{{Cron: "0 9 * * *", Unload: true}},
+			want:    []string{"kill"},
+		},
+		{
+			name:    "unload with nothing running",
+			entries: []Entry{{Cron: "0 9 * * *", Unload: true}},
+			want:    nil,
+		},
+		{
+			name:    "skips invalid entries",
+			entries: []Entry{{Cron: "0 9 * *", Load: "p:bad"}, {Cron: "0 9 * * *", Load: "p:coder"}},
+			want:    []string{"run p:coder"},
+		},
+		{
+			name:    "runs due entries in order",
+			current: &preset.Preset{Name: "chat"},
+			entries: []Entry{{Cron: "0 9 * * *", Unload: true}, {Cron: "0 * * * *", Load: "p:coder"}},
+			want:    []string{"kill", "run p:coder"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			r := &fakeRunner{current: tt.current}
+			s := newTestScheduler(r, tt.entries...)
+
+			// Act
+			s.tick(context.Background(), monday9)
+
+			// Assert
+			if !slices.Equal(r.calls, tt.want) {
+				t.Errorf("calls = %v, want %v", r.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduler_TickLoadFailureContinues(t *testing.T) {
+	// Arrange
+	r := &fakeRunner{runErr: errors.New("preset not found")}
+	s := newTestScheduler(r, Entry{Cron: "* * * * *", Load: "p:missing"}, Entry{Cron: "* * * * *", Load: "p:coder"})
+
+	// Act
+	s.tick(context.Background(), time.Now())
+
+	// Assert
+	if want := []string{"run p:missing", "run p:coder"}; !slices.Equal(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+}
+
+func TestScheduler_Run(t *testing.T) {
+	// Arrange
+	r := &fakeRunner{}
+	s := newTestScheduler(r, Entry{Cron: "0 9 * * *", Load: "p:coder"})
+	now := time.Date(2026, 10, 19, 8, 59, 30, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	s.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		if len(waits) > 1 {
+			cancel()
+			return nil
+		}
+		now = now.Add(d)
+		ch := make(chan time.Time, 1)
+		ch <- now
+		return ch
+	}
+
+	// Act
+	s.Run(ctx)
+
+	// Assert
+	if want := []time.Duration{30 * time.Second, time.Minute}; !slices.Equal(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if want := []string{"run p:coder"}; !slices.Equal(r.calls, want) {
+		t.Errorf("calls = %v, want %v", r.calls, want)
+	}
+}
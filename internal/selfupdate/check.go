@@ -0,0 +1,140 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// checkFile caches the result of the last release check in the cache
+	// directory.
+	checkFile = "update-check.json"
+
+	// checkInterval is how often the latest release is fetched for the
+	// notice, and how often the notice is shown.
+	checkInterval = 24 * time.Hour
+
+	// noticeTimeout bounds the release fetch of a notice, which runs in
+	// front of other output.
+	noticeTimeout = 2 * time.Second
+)
+
+// checkState is the content of the check cache file.
+type checkState struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Latest     string    `json:"latest,omitempty"`
+	NotifiedAt time.Time `json:"notified_at,omitzero"`
+}
+
+// Check fetches the latest release and records it in cacheDir, so the
+// notice does not repeat what the user was just shown. It reports whether
+// the release is newer than the running version.
+func (u *Updater) Check(ctx context.Context, cacheDir string, now time.Time) (*Release, bool, error) {
+	release, err := u.getLatestRelease(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	newer := u.isNewer(release.TagName)
+	state := checkState{CheckedAt: now, Latest: release.TagName}
+	if newer {
+		state.NotifiedAt = now
+	}
+	saveCheckState(cacheDir, state)
+	return release, newer, nil
+}
+
+// Notice returns the latest version when it is newer than the running one
+// and the user has not been told in the last day, or "". The latest
+// release is fetched at most once a day, with a short timeout; failures
+// are silent and retried the next day. Development builds, which have no
+// release version, never check.
+func (u *Updater) Notice(ctx context.Context, cacheDir string, now time.Time) string {
+	if !semver.IsValid(ensureVPrefix(u.currentVersion)) {
+		return ""
+	}
+	state := loadCheckState(cacheDir)
+	if now.Sub(state.CheckedAt) >= checkInterval || now.Before(state.CheckedAt) {
+		ctx, cancel := context.WithTimeout(ctx, noticeTimeout)
+		release, err := u.getLatestRelease(ctx)
+		cancel()
+		state.CheckedAt = now
+		if err == nil {
+			state.Latest = release.TagName
+		}
+		saveCheckState(cacheDir, state)
+	}
+	if !u.isNewer(state.Latest) {
+		return ""
+	}
+	if now.Sub(state.NotifiedAt) < checkInterval && !now.Before(state.NotifiedAt) {
+		return ""
+	}
+	state.NotifiedAt = now
+	saveCheckState(cacheDir, state)
+	return state.Latest
+}
+
+// isNewer reports whether tag is a newer version than the running one.
+func (u *Updater) isNewer(tag string) bool {
+	latest := ensureVPrefix(tag)
+	current := ensureVPrefix(u.currentVersion)
+	return semver.IsValid(latest) && semver.IsValid(current) && semver.Compare(current, latest) < 0
+}
+
+// loadCheckState reads the check cache, or returns the zero state when it
+// is missing or unreadable.
+func loadCheckState(cacheDir string) checkState {
+	var state checkState
+	data, err := os.ReadFile(filepath.Join(cacheDir, checkFile))
+	if err != nil {
+		return checkState{}
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return checkState{}
+	}
+	return state
+}
+
+// saveCheckState writes the check cache. Errors are ignored: the check is
+// best effort and at worst runs again.
+func saveCheckState(cacheDir string, state checkState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(cacheDir, checkFile), data, 0644)
+}
+
+// Summary returns the headings and list items of Markdown release notes,
+// at most max lines of them, and how many more there are. List items keep
+// their "- " marker; headings lose their "#" marks.
+func Summary(notes string, max int) ([]string, int) {
+	var lines []string
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			if heading != "" {
+				lines = append(lines, heading)
+			}
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			indent := line[:len(line)-len(trimmed)]
+			lines = append(lines, indent+"- "+strings.TrimSpace(trimmed[2:]))
+		}
+	}
+	if len(lines) <= max {
+		return lines, 0
+	}
+	return lines[:max], len(lines) - max
+}
@@ -0,0 +1,163 @@
+package selfupdate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newReleaseServer serves tag as the latest release and counts the
+// requests for it.
+func newReleaseServer(t *testing.T, tag string) (*httptest.Server, *int) {
+	t.Helper()
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(Release{TagName: tag, Body: "## Changes\n- Faster pulls"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &fetches
+}
+
+func TestNotice(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		version     string
+		latest      string
+		calls       []time.Duration // offsets from now of successive Notice calls
+		want        []string
+		wantFetches int
+	}{
+		{
+			name:        "newer release shown once a day",
+			version:     "1.0.0",
+			latest:      "v1.1.0",
+			calls:       []time.Duration{0, time.Hour, 25 * time.Hour},
+			want:        []string{"v1.1.0", "", "v1.1.0"},
+			wantFetches: 2,
+		},
+		{
+			name:        "up to date",
+			version:     "v1.1.0",
+			latest:      "v1.1.0",
+			calls:       []time.Duration{0, time.Hour},
+			want:        []string{"", ""},
+			wantFetches: 1,
+		},
+		{
+			name:        "development build never checks",
+			version:     "dev",
+			latest:      "v1.1.0",
+			calls:       []time.Duration{0},
+			want:        []string{""},
+			wantFetches: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv, fetches := newReleaseServer(t, tt.latest)
+			u := newTestUpdater(tt.version, srv.URL)
+			cacheDir := t.TempDir()
+
+			// Act
+			var got []string
+			for _, offset := range tt.calls {
+				got = append(got, u.Notice(context.Background(), cacheDir, now.Add(offset)))
+			}
+
+			// Assert
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Notice() = %q, want %q", got, tt.want)
+			}
+			if *fetches != tt.wantFetches {
+				t.Errorf("fetches = %d, want %d", *fetches, tt.wantFetches)
+			}
+		})
+	}
+}
+
+func TestNotice_FetchFailureIsSilent(t *testing.T) {
+	// Arrange
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	u := newTestUpdater("1.0.0", srv.URL)
+
+	// Act
+	got := u.Notice(context.Background(), t.TempDir(), time.Now())
+
+	// Assert
+	if got != "" {
+		t.Errorf("Notice() = %q, want empty", got)
+	}
+}
+
+func TestCheck_SuppressesNotice(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	srv, fetches := newReleaseServer(t, "v1.1.0")
+	u := newTestUpdater("1.0.0", srv.URL)
+	cacheDir := t.TempDir()
+
+	// Act
+	release, newer, err := u.Check(context.Background(), cacheDir, now)
+	notice := u.Notice(context.Background(), cacheDir, now.Add(time.Hour))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !newer || release.TagName != "v1.1.0" || release.Body == "" {
+		t.Errorf("Check() = %+v, %v, want v1.1.0 with notes, true", release, newer)
+	}
+	if notice != "" {
+		t.Errorf("Notice() = %q, want empty right after Check", notice)
+	}
+	if *fetches != 1 {
+		t.Errorf("fetches = %d, want 1", *fetches)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	notes := "Intro paragraph.\r\n\r\n## Features\r\n* Add OCI pulls\r\n  - with auth\r\n## Fixes\r\n- Fix status\r\n"
+
+	tests := []struct {
+		name     string
+		max      int
+		want     []string
+		wantMore int
+	}{
+		{
+			name: "all lines",
+			max:  10,
+			want: []string{"Features", "- Add OCI pulls", "  - with auth", "Fixes", "- Fix status"},
+		},
+		{
+			name:     "truncated",
+			max:      2,
+			want:     []string{"Features", "- Add OCI pulls"},
+			wantMore: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, more := Summary(notes, tt.max)
+
+			// Assert
+			if !slices.Equal(got, tt.want) || more != tt.wantMore {
+				t.Errorf("Summary() = %q, %d, want %q, %d", got, more, tt.want, tt.wantMore)
+			}
+		})
+	}
+}
@@ -16,8 +16,6 @@ import (
 	"runtime"
 	"strings"
 	"time"
-
-	"golang.org/x/mod/semver"
 )
 
 const (
@@ -42,6 +40,8 @@ type Updater struct {
 // Release represents a GitHub release.
 type Release struct {
 	TagName string  `json:"tag_name"`
+	Body    string  `json:"body"`     // release notes, in Markdown
+	HTMLURL string  `json:"html_url"` // release page
 	Assets  []Asset `json:"assets"`
 }
 
@@ -77,18 +77,7 @@ func (u *Updater) CheckUpdate(ctx context.Context) (string, bool, error) {
 		return "", false, err
 	}
 
-	// Ensure versions have 'v' prefix for semver comparison
-	latestVersion := ensureVPrefix(release.TagName)
-	currentVersion := ensureVPrefix(u.currentVersion)
-
-	// Use proper semver comparison
-	// semver.Compare returns:
-	//   -1 if current < latest
-	//    0 if current == latest
-	//   +1 if current > latest
-	hasUpdate := semver.IsValid(latestVersion) &&
-		semver.IsValid(currentVersion) &&
-		semver.Compare(currentVersion, latestVersion) < 0
+	hasUpdate := u.isNewer(release.TagName)
 
 	return release.TagName, hasUpdate, nil
 }
@@ -0,0 +1,141 @@
+package sysinfo
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// GPU backends reported by DetectGPU.
+const (
+	BackendMetal = "metal"
+	BackendCUDA  = "cuda"
+	BackendROCm  = "rocm"
+)
+
+// GPU describes the detected accelerator. A zero GPU means none was found.
+type GPU struct {
+	Backend string
+	VRAM    int64 // memory available to llama-server in bytes, across all devices
+	Count   int   // number of devices
+}
+
+// metalWorkingSetShare is the share of unified memory macOS lets the GPU
+// use by default (recommendedMaxWorkingSetSize is roughly 3/4 of RAM).
+const metalWorkingSetShare = 0.75
+
+// DetectGPU probes for a GPU: NVIDIA via nvidia-smi, AMD via rocm-smi on
+// Linux, and Metal on Apple Silicon.
+func DetectGPU() GPU {
+	if vram, count := nvidiaGPUs(); vram > 0 {
+		return GPU{Backend: BackendCUDA, VRAM: vram, Count: count}
+	}
+	if vram, count := rocmGPUs(); vram > 0 {
+		return GPU{Backend: BackendROCm, VRAM: vram, Count: count}
+	}
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		if out, err := runCommand("sysctl", "-n", "hw.memsize"); err == nil {
+			ram, _ := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+			if ram > 0 {
+				return GPU{Backend: BackendMetal, VRAM: int64(float64(ram) * metalWorkingSetShare), Count: 1}
+			}
+		}
+	}
+	return GPU{}
+}
+
+func nvidiaGPUs() (int64, int) {
+	out, err := runCommand("nvidia-smi", "--query-gpu=memory.total", "--format=csv,noheader,nounits")
+	if err != nil {
+		return 0, 0
+	}
+	return parseNvidiaSMI(out)
+}
+
+// nvidiaFreeVRAM returns the memory not in use across NVIDIA GPUs.
+func nvidiaFreeVRAM() int64 {
+	out, err := runCommand("nvidia-smi", "--query-gpu=memory.free", "--format=csv,noheader,nounits")
+	if err != nil {
+		return 0
+	}
+	free, _ := parseNvidiaSMI(out)
+	return free
+}
+
+func rocmGPUs() (int64, int) {
+	total, _, count := rocmSMI()
+	return total, count
+}
+
+// rocmSMI returns the total and used memory across AMD GPUs and counts
+// them.
+func rocmSMI() (total, used int64, count int) {
+	if runtime.GOOS != "linux" {
+		return 0, 0, 0
+	}
+	out, err := runCommand("rocm-smi", "--showmeminfo", "vram", "--csv")
+	if err != nil {
+		return 0, 0, 0
+	}
+	return parseROCmSMI(out)
+}
+
+// parseROCmSMI sums the "VRAM Total Memory (B)" and "VRAM Total Used
+// Memory (B)" columns of `rocm-smi --showmeminfo vram --csv` output and
+// counts the GPUs.
+func parseROCmSMI(out []byte) (total, used int64, count int) {
+	totalCol, usedCol := -1, -1
+	for line := range strings.Lines(string(out)) {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if totalCol < 0 {
+			for i, f := range fields {
+				switch {
+				case strings.Contains(f, "Total Used Memory"):
+					usedCol = i
+				case strings.Contains(f, "Total Memory"):
+					totalCol = i
+				}
+			}
+			if totalCol < 0 {
+				return 0, 0, 0
+			}
+			continue
+		}
+		if totalCol >= len(fields) {
+			continue
+		}
+		if b, err := strconv.ParseInt(fields[totalCol], 10, 64); err == nil {
+			total += b
+			count++
+		}
+		if usedCol >= 0 && usedCol < len(fields) {
+			if b, err := strconv.ParseInt(fields[usedCol], 10, 64); err == nil {
+				used += b
+			}
+		}
+	}
+	return total, used, count
+}
+
+// GPULayers returns the --n-gpu-layers value for a model of modelSize bytes
+// with the given number of transformer layers, keeping headroom bytes of
+// VRAM free for the KV cache and compute buffers. When the whole model fits
+// it returns layers+1 so the output layer is offloaded too; without a GPU
+// it returns 0.
+func GPULayers(gpu GPU, modelSize int64, layers int, headroom int64) int {
+	if gpu.VRAM <= 0 || layers <= 0 {
+		return 0
+	}
+	usable := gpu.VRAM - headroom
+	if usable <= 0 {
+		return 0
+	}
+	if modelSize <= usable {
+		return layers + 1
+	}
+	perLayer := modelSize / int64(layers+1)
+	if perLayer <= 0 {
+		return layers + 1
+	}
+	return int(min(usable/perLayer, int64(layers)))
+}
@@ -0,0 +1,69 @@
+package sysinfo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseROCmSMI(t *testing.T) {
+	// Arrange
+	out := []byte("device,VRAM Total Memory (B),VRAM Total Used Memory (B)\n" +
+		"card0,17163091968,1048576\n" +
+		"card1,17163091968,0\n")
+
+	// Act
+	got, used, count := parseROCmSMI(out)
+
+	// Assert
+	if want := int64(2 * 17163091968); got != want || used != 1048576 || count != 2 {
+		t.Errorf("parseROCmSMI() = %d, %d, %d, want %d, 1048576, 2", got, used, count, want)
+	}
+}
+
+func TestDetectGPU_NVIDIA(t *testing.T) {
+	// Arrange
+	origRun := runCommand
+	t.Cleanup(func() { runCommand = origRun })
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		if name == "nvidia-smi" {
+			return []byte("24576\n"), nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	// Act
+	gpu := DetectGPU()
+
+	// Assert
+	want := GPU{Backend: BackendCUDA, VRAM: 24576 << 20, Count: 1}
+	if gpu != want {
+		t.Errorf("DetectGPU() = %+v, want %+v", gpu, want)
+	}
+}
+
+func TestGPULayers(t *testing.T) {
+	const gb = int64(1 << 30)
+	gpu := GPU{Backend: BackendCUDA, VRAM: 8 * gb}
+
+	tests := []struct {
+		name      string
+		gpu       GPU
+		modelSize int64
+		layers    int
+		want      int
+	}{
+		{"no GPU", GPU{}, 4 * gb, 32, 0},
+		{"unknown layer count", gpu, 4 * gb, 0, 0},
+		{"fits entirely", gpu, 4 * gb, 32, 33},
+		{"partial offload", gpu, 11 * gb, 32, 18}, // 6 GB usable / (11 GB / 33 layers)
+		{"headroom exceeds VRAM", GPU{Backend: BackendCUDA, VRAM: gb}, 4 * gb, 32, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GPULayers(tt.gpu, tt.modelSize, tt.layers, 2*gb); got != tt.want {
+				t.Errorf("GPULayers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
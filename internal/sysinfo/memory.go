@@ -0,0 +1,141 @@
+// Package sysinfo detects host hardware used for sizing decisions and the
+// resource usage of running processes.
+package sysinfo
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Memory describes detected memory in bytes. Zero means not detected.
+type Memory struct {
+	RAM      int64 // total system memory
+	VRAM     int64 // total memory across discrete (NVIDIA or AMD) GPUs
+	FreeRAM  int64 // system memory available without swapping
+	FreeVRAM int64 // memory not in use across discrete GPUs
+}
+
+// Budget returns the memory a model should fit in now: the free VRAM when
+// a discrete GPU was detected, otherwise the available system RAM (unified
+// memory on Apple Silicon). Where free memory is not detected, the total
+// is used.
+func (m Memory) Budget() int64 {
+	if m.VRAM > 0 {
+		if m.FreeVRAM > 0 {
+			return m.FreeVRAM
+		}
+		return m.VRAM
+	}
+	if m.FreeRAM > 0 {
+		return m.FreeRAM
+	}
+	return m.RAM
+}
+
+// Replaceable for testing.
+var (
+	readFile   = os.ReadFile
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		return exec.Command(name, args...).Output()
+	}
+)
+
+// DetectMemory detects system RAM and GPU VRAM, in total and free.
+// Detection failures are not errors; the corresponding field is left zero.
+func DetectMemory() Memory {
+	var m Memory
+	switch runtime.GOOS {
+	case "darwin":
+		if out, err := runCommand("sysctl", "-n", "hw.memsize"); err == nil {
+			m.RAM, _ = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		}
+	case "linux":
+		if data, err := readFile("/proc/meminfo"); err == nil {
+			m.RAM = parseMeminfo(data, "MemTotal")
+		}
+	}
+	m.FreeRAM = DetectFreeMemory()
+	if m.VRAM, _ = nvidiaGPUs(); m.VRAM > 0 {
+		m.FreeVRAM = nvidiaFreeVRAM()
+	} else if total, used, _ := rocmSMI(); total > 0 {
+		m.VRAM = total
+		m.FreeVRAM = total - used
+	}
+	return m
+}
+
+// DetectFreeMemory returns the system memory in bytes that can be given to
+// a new process without swapping, or zero when it cannot be detected.
+func DetectFreeMemory() int64 {
+	switch runtime.GOOS {
+	case "darwin":
+		if out, err := runCommand("vm_stat"); err == nil {
+			return parseVMStat(out)
+		}
+	case "linux":
+		if data, err := readFile("/proc/meminfo"); err == nil {
+			return parseMeminfo(data, "MemAvailable")
+		}
+	}
+	return 0
+}
+
+// parseMeminfo returns field, such as MemTotal, from /proc/meminfo in bytes.
+func parseMeminfo(data []byte, field string) int64 {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == field+":" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// parseVMStat returns the free, inactive and speculative pages reported by
+// macOS vm_stat in bytes: memory the kernel hands out without paging.
+func parseVMStat(out []byte) int64 {
+	var pageSize, pages int64
+	for line := range strings.Lines(string(out)) {
+		if _, rest, ok := strings.Cut(line, "page size of "); ok {
+			size, _, _ := strings.Cut(rest, " ")
+			pageSize, _ = strconv.ParseInt(size, 10, 64)
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "Pages free", "Pages inactive", "Pages speculative":
+			n, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), "."), 10, 64)
+			if err == nil {
+				pages += n
+			}
+		}
+	}
+	return pages * pageSize
+}
+
+// parseNvidiaSMI sums per-GPU memory.total values (MiB, one per line) and
+// counts the GPUs.
+func parseNvidiaSMI(out []byte) (total int64, count int) {
+	for line := range strings.Lines(string(out)) {
+		mib, err := strconv.ParseInt(strings.TrimSpace(line), 10, 64)
+		if err != nil {
+			continue
+		}
+		total += mib * 1024 * 1024
+		count++
+	}
+	return total, count
+}
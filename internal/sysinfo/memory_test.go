@@ -0,0 +1,145 @@
+package sysinfo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		field string
+		want  int64
+	}{
+		{"typical", "MemTotal:       16318716 kB\nMemFree:         1234 kB\n", "MemTotal", 16318716 * 1024},
+		{"available", "MemTotal:       16318716 kB\nMemAvailable:    8000000 kB\n", "MemAvailable", 8000000 * 1024},
+		{"missing", "MemFree: 1234 kB\n", "MemTotal", 0},
+		{"malformed", "MemTotal: abc kB\n", "MemTotal", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMeminfo([]byte(tt.data), tt.field); got != tt.want {
+				t.Errorf("parseMeminfo() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVMStat(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want int64
+	}{
+		{
+			name: "typical",
+			out: "Mach Virtual Memory Statistics: (page size of 16384 bytes)\n" +
+				"Pages free:                               10000.\n" +
+				"Pages active:                            500000.\n" +
+				"Pages inactive:                           20000.\n" +
+				"Pages speculative:                         1000.\n",
+			want: 31000 * 16384,
+		},
+		{"no page size", "Pages free: 10000.\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseVMStat([]byte(tt.out)); got != tt.want {
+				t.Errorf("parseVMStat() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	tests := []struct {
+		name      string
+		out       string
+		want      int64
+		wantCount int
+	}{
+		{"single gpu", "24564\n", 24564 << 20, 1},
+		{"two gpus", "24564\n8192\n", (24564 + 8192) << 20, 2},
+		{"garbage", "No devices were found\n", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := parseNvidiaSMI([]byte(tt.out))
+			if got != tt.want || count != tt.wantCount {
+				t.Errorf("parseNvidiaSMI() = %d, %d, want %d, %d", got, count, tt.want, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	tests := []struct {
+		name string
+		mem  Memory
+		want int64
+	}{
+		{"prefers free vram", Memory{RAM: 64, VRAM: 24, FreeRAM: 40, FreeVRAM: 20}, 20},
+		{"total vram when free is unknown", Memory{RAM: 64, VRAM: 24}, 24},
+		{"falls back to available ram", Memory{RAM: 64, FreeRAM: 40}, 40},
+		{"total ram when available is unknown", Memory{RAM: 64}, 64},
+		{"unknown", Memory{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mem.Budget(); got != tt.want {
+				t.Errorf("Budget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMemory_NoGPU(t *testing.T) {
+	// Arrange
+	origRun := runCommand
+	t.Cleanup(func() { runCommand = origRun })
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		if name == "nvidia-smi" || name == "rocm-smi" {
+			return nil, errors.New("not found")
+		}
+		return origRun(name, args...)
+	}
+
+	// Act
+	m := DetectMemory()
+
+	// Assert
+	if m.VRAM != 0 {
+		t.Errorf("VRAM = %d, want 0 without nvidia-smi or rocm-smi", m.VRAM)
+	}
+}
+
+func TestDetectMemory_NVIDIAFree(t *testing.T) {
+	// Arrange
+	origRun := runCommand
+	t.Cleanup(func() { runCommand = origRun })
+	runCommand = func(name string, args ...string) ([]byte, error) {
+		if name != "nvidia-smi" {
+			return origRun(name, args...)
+		}
+		if args[0] == "--query-gpu=memory.free" {
+			return []byte("20000\n"), nil
+		}
+		return []byte("24576\n"), nil
+	}
+
+	// Act
+	m := DetectMemory()
+
+	// Assert
+	if m.VRAM != 24576<<20 || m.FreeVRAM != 20000<<20 {
+		t.Errorf("VRAM = %d, FreeVRAM = %d, want %d, %d", m.VRAM, m.FreeVRAM, int64(24576<<20), int64(20000<<20))
+	}
+	if got := m.Budget(); got != 20000<<20 {
+		t.Errorf("Budget() = %d, want the free VRAM", got)
+	}
+}
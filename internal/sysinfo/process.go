@@ -0,0 +1,170 @@
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProcessUsage is the resource usage of a running process.
+type ProcessUsage struct {
+	RSS int64         // resident memory in bytes
+	CPU time.Duration // user plus system CPU time since the process started
+}
+
+// linuxClockTicks is USER_HZ, the unit of CPU times in /proc/<pid>/stat.
+// It is 100 on every architecture Linux supports today.
+const linuxClockTicks = 100
+
+// DetectProcessUsage reads the resource usage of process pid from /proc on
+// Linux and from ps elsewhere.
+func DetectProcessUsage(pid int) (ProcessUsage, error) {
+	if runtime.GOOS == "linux" {
+		data, err := readFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return ProcessUsage{}, err
+		}
+		return parseProcStat(string(data), int64(os.Getpagesize()))
+	}
+	out, err := runCommand("ps", "-o", "rss=,time=", "-p", strconv.Itoa(pid))
+	if err != nil {
+		return ProcessUsage{}, err
+	}
+	return parsePS(string(out))
+}
+
+// DetectProcessStart returns when process pid started, read from /proc on
+// Linux and from ps elsewhere.
+func DetectProcessStart(pid int) (time.Time, error) {
+	if runtime.GOOS == "linux" {
+		stat, err := readFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil {
+			return time.Time{}, err
+		}
+		system, err := readFile("/proc/stat")
+		if err != nil {
+			return time.Time{}, err
+		}
+		return parseProcStart(string(stat), string(system))
+	}
+	out, err := runCommand("ps", "-o", "etime=", "-p", strconv.Itoa(pid))
+	if err != nil {
+		return time.Time{}, err
+	}
+	elapsed, err := parsePSTime(strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-elapsed).Truncate(time.Second), nil
+}
+
+// statFields returns the fields of /proc/<pid>/stat after the command name,
+// so that fields[0] is field 3 (state). The command name in parentheses may
+// contain spaces, so fields are counted from the closing parenthesis.
+func statFields(data string) ([]string, error) {
+	i := strings.LastIndexByte(data, ')')
+	if i < 0 {
+		return nil, fmt.Errorf("malformed stat")
+	}
+	fields := strings.Fields(data[i+1:])
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("malformed stat: %d fields", len(fields))
+	}
+	return fields, nil
+}
+
+// parseProcStat parses /proc/<pid>/stat: utime is field 14, stime 15 and
+// rss 24.
+func parseProcStat(data string, pageSize int64) (ProcessUsage, error) {
+	fields, err := statFields(data)
+	if err != nil {
+		return ProcessUsage{}, err
+	}
+	utime, err1 := strconv.ParseInt(fields[11], 10, 64)
+	stime, err2 := strconv.ParseInt(fields[12], 10, 64)
+	pages, err3 := strconv.ParseInt(fields[21], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return ProcessUsage{}, fmt.Errorf("malformed stat")
+	}
+	return ProcessUsage{
+		RSS: pages * pageSize,
+		CPU: time.Duration(utime+stime) * time.Second / linuxClockTicks,
+	}, nil
+}
+
+// parseProcStart returns the start time in /proc/<pid>/stat (field 22, in
+// clock ticks since boot) given /proc/stat, whose btime line holds the boot
+// time.
+func parseProcStart(stat, system string) (time.Time, error) {
+	fields, err := statFields(stat)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed stat")
+	}
+	for line := range strings.Lines(system) {
+		if v, ok := strings.CutPrefix(line, "btime "); ok {
+			boot, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("malformed btime %q", strings.TrimSpace(v))
+			}
+			return time.Unix(boot, 0).Add(time.Duration(ticks) * time.Second / linuxClockTicks), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no btime in /proc/stat")
+}
+
+// parsePS parses the output of ps -o rss=,time=: resident memory in KB and
+// CPU time as [dd-][hh:]mm:ss[.ss].
+func parsePS(out string) (ProcessUsage, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return ProcessUsage{}, fmt.Errorf("unexpected ps output %q", strings.TrimSpace(out))
+	}
+	kb, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return ProcessUsage{}, fmt.Errorf("unexpected ps rss %q", fields[0])
+	}
+	cpu, err := parsePSTime(fields[1])
+	if err != nil {
+		return ProcessUsage{}, err
+	}
+	return ProcessUsage{RSS: kb * 1024, CPU: cpu}, nil
+}
+
+func parsePSTime(s string) (time.Duration, error) {
+	var total time.Duration
+	rest := s
+	if days, after, ok := strings.Cut(rest, "-"); ok {
+		d, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected ps time %q", s)
+		}
+		total += time.Duration(d) * 24 * time.Hour
+		rest = after
+	}
+	parts := strings.Split(rest, ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("unexpected ps time %q", s)
+	}
+	secs, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected ps time %q", s)
+	}
+	total += time.Duration(secs * float64(time.Second))
+	unit := time.Minute
+	for i := len(parts) - 2; i >= 0; i-- {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return 0, fmt.Errorf("unexpected ps time %q", s)
+		}
+		total += time.Duration(n) * unit
+		unit *= 60
+	}
+	return total, nil
+}
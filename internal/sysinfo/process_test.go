@@ -0,0 +1,113 @@
+package sysinfo
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseProcStat(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    ProcessUsage
+		wantErr bool
+	}{
+		{
+			name: "typical",
+			data: "4242 (llama-server) S 1 4242 4242 0 -1 4194560 5000 0 0 0 1250 250 0 0 20 0 9 0 123456 9000000000 2048 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0\n",
+			want: ProcessUsage{RSS: 2048 * 4096, CPU: 15 * time.Second},
+		},
+		{
+			name: "command with spaces and parentheses",
+			data: "7 (a (b) c) R 1 7 7 0 -1 0 0 0 0 0 100 0 0 0 20 0 1 0 1 1 10 0\n",
+			want: ProcessUsage{RSS: 10 * 4096, CPU: time.Second},
+		},
+		{name: "truncated", data: "7 (x) R 1 7\n", wantErr: true},
+		{name: "no command", data: "garbage", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProcStat(tt.data, 4096)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProcStat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseProcStat() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProcStart(t *testing.T) {
+	stat := "4242 (llama-server) S 1 4242 4242 0 -1 4194560 5000 0 0 0 1250 250 0 0 20 0 9 0 123456 9000000000 2048 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0\n"
+	tests := []struct {
+		name    string
+		stat    string
+		system  string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:   "typical",
+			stat:   stat,
+			system: "cpu  1 2 3 4\nintr 5\nbtime 1792141200\nprocesses 99\n",
+			want:   time.Unix(1792141200, 0).Add(1234560 * time.Millisecond),
+		},
+		{name: "no btime", stat: stat, system: "cpu  1 2 3 4\n", wantErr: true},
+		{name: "truncated stat", stat: "7 (x) R 1 7\n", system: "btime 1\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProcStart(tt.stat, tt.system)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProcStart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseProcStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectProcessStart(t *testing.T) {
+	// Act
+	started, err := DetectProcessStart(os.Getpid())
+
+	// Assert
+	if err != nil {
+		t.Skipf("process start unavailable: %v", err)
+	}
+	if age := time.Since(started); age < 0 || age > time.Hour {
+		t.Errorf("DetectProcessStart() = %v, want a moment ago", started)
+	}
+}
+
+func TestParsePS(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    ProcessUsage
+		wantErr bool
+	}{
+		{"minutes and seconds", "  51200   3:20.50\n", ProcessUsage{RSS: 50 << 20, CPU: 3*time.Minute + 20500*time.Millisecond}, false},
+		{"hours", "1024 2:03:04\n", ProcessUsage{RSS: 1 << 20, CPU: 2*time.Hour + 3*time.Minute + 4*time.Second}, false},
+		{"days", "1024 1-00:00:01\n", ProcessUsage{RSS: 1 << 20, CPU: 24*time.Hour + time.Second}, false},
+		{"process gone", "", ProcessUsage{}, true},
+		{"garbage time", "1024 soon\n", ProcessUsage{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePS(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parsePS() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
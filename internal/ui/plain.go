@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+)
+
+// plain is set by SetPlain.
+var plain bool
+
+// plainSymbols are the ASCII stand-ins for the symbols the CLI prints.
+var plainSymbols = map[string]string{
+	"✓": "[ok]",
+	"✗": "[error]",
+	"⚠": "[warning]",
+	"ℹ": "[info]",
+	"💡": "Hint:",
+	"●": "*",
+	"◐": "~",
+	"○": "-",
+	"←": "<-",
+	"→": "->",
+	"─": "-",
+	"█": "#",
+	"░": ".",
+}
+
+// SetPlain turns plain output on or off. Plain output has ASCII labels in
+// place of emoji, symbols and box-drawing, and no colors, for CI logs,
+// screen readers and terminals that cannot show them.
+func SetPlain(on bool) {
+	plain = on
+	if on {
+		color.NoColor = true
+	}
+}
+
+// Plain reports whether plain output is on.
+func Plain() bool {
+	return plain
+}
+
+// PlainFromEnv reports whether the environment asks for plain output:
+// NO_COLOR is set (see no-color.org) or TERM is dumb.
+func PlainFromEnv() bool {
+	return os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb"
+}
+
+// Symbol returns s, one of the symbols the CLI prints, or its ASCII
+// stand-in in plain output.
+func Symbol(s string) string {
+	if r, ok := plainSymbols[s]; ok && plain {
+		return r
+	}
+	return s
+}
+
+// withIcon prefixes title with icon and a space, or returns it alone in
+// plain output, where heading icons are dropped.
+func withIcon(icon, title string) string {
+	if plain || icon == "" {
+		return title
+	}
+	return icon + " " + title
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are drawn in turn, one every spinnerInterval.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// Spinner shows that a long operation is running. On a terminal it
+// redraws one line in place: an animated frame, the message, the current
+// phase and the time elapsed. Elsewhere it prints the message once, so
+// logs stay readable.
+type Spinner struct {
+	out     io.Writer
+	live    bool
+	message string
+	start   time.Time
+
+	mu    sync.Mutex
+	phase string
+	frame int
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// StartSpinner shows message on Output until Stop is called. live selects
+// the animated line, for terminals; plain output is never animated.
+func StartSpinner(message string, live bool) *Spinner {
+	s := &Spinner{
+		out:     Output,
+		live:    live && !plain,
+		message: message,
+		start:   time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if !s.live {
+		PrintInfo(message)
+		close(s.done)
+		return s
+	}
+	s.draw()
+	go s.run()
+	return s
+}
+
+func (s *Spinner) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.frame++
+			s.mu.Unlock()
+			s.draw()
+		}
+	}
+}
+
+// SetPhase changes the phase shown after the message, e.g. "starting
+// llama-server". It is not shown when the spinner is not live.
+func (s *Spinner) SetPhase(phase string) {
+	s.mu.Lock()
+	s.phase = phase
+	s.mu.Unlock()
+	if s.live {
+		s.draw()
+	}
+}
+
+// Stop clears the spinner's line and returns how long it ran. Output
+// printed after Stop starts on a line of its own.
+func (s *Spinner) Stop() time.Duration {
+	elapsed := time.Since(s.start)
+	if s.live {
+		close(s.stop)
+		<-s.done
+		fmt.Fprint(s.out, "\r\033[K")
+	}
+	return elapsed
+}
+
+// draw redraws the spinner's line.
+func (s *Spinner) draw() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := fmt.Sprintf("%s %s", Info(spinnerFrames[s.frame%len(spinnerFrames)]), s.message)
+	if s.phase != "" {
+		line += " " + Muted(s.phase)
+	}
+	fmt.Fprintf(s.out, "\r\033[K%s %s", line, Muted(FormatElapsed(time.Since(s.start))))
+}
+
+// FormatElapsed formats the duration of an operation to a tenth of a
+// second, e.g. "12.4s", or in minutes and seconds from a minute on.
+func FormatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
@@ -2,12 +2,14 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"maps"
 	"os"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -41,6 +43,10 @@ var (
 // Defaults to os.Stdout but can be overridden for testing.
 var Output io.Writer = os.Stdout
 
+// Input is the source of answers to prompts.
+// Defaults to os.Stdin but can be overridden for testing.
+var Input = bufio.NewReader(os.Stdin)
+
 // FormatEndpoint formats endpoint as a link.
 func FormatEndpoint(endpoint string) string {
 	return Link(endpoint)
@@ -50,21 +56,31 @@ func FormatEndpoint(endpoint string) string {
 func StatusBadge(state string) string {
 	switch state {
 	case "running":
-		return Success("● Running")
+		return Success(Symbol("●") + " Running")
 	case "loading":
-		return Warning("◐ Loading")
+		return Warning(Symbol("◐") + " Loading")
 	case "idle":
-		return Warning("○ Idle")
+		return Warning(Symbol("○") + " Idle")
 	default:
-		return Error("○ Not Running")
+		return Error(Symbol("○") + " Not Running")
 	}
 }
 
-// PrintStatus prints daemon status in a formatted style.
-func PrintStatus(state, preset, endpoint, logPath, mmproj string) {
-	fmt.Fprintf(Output, "🚀 %s\n", Heading("Status"))
+// stateLabel is the badge for state, followed by detail (e.g. "loading
+// model 43%") when there is one.
+func stateLabel(state, detail string) string {
+	if detail == "" {
+		return StatusBadge(state)
+	}
+	return StatusBadge(state) + " " + Muted("("+detail+")")
+}
 
-	PrintKeyValue("State", StatusBadge(state))
+// PrintStatus prints daemon status in a formatted style. detail is what
+// llama-server reports while loading, if anything.
+func PrintStatus(state, detail, preset, endpoint, logPath, mmproj string) {
+	PrintHeading("🚀", "Status")
+
+	PrintKeyValue("State", stateLabel(state, detail))
 	if preset != "" {
 		label, formatted := formatPresetOrModel(preset)
 		PrintKeyValue(label, formatted)
@@ -78,6 +94,39 @@ func PrintStatus(state, preset, endpoint, logPath, mmproj string) {
 	PrintKeyValue("Logs", logPath)
 }
 
+// ServerMetrics is llama-server load shown by status.
+// Negative values were not reported and are not shown.
+type ServerMetrics struct {
+	KVCacheUsage          float64 // fraction of the KV cache in use
+	SlotsBusy             int
+	SlotsTotal            int
+	PromptTokensPerSec    float64
+	PredictedTokensPerSec float64
+}
+
+// PrintServerMetrics prints llama-server metrics below a status.
+func PrintServerMetrics(m ServerMetrics) {
+	switch {
+	case m.SlotsBusy >= 0 && m.SlotsTotal > 0:
+		PrintKeyValue("Slots", fmt.Sprintf("%d/%d busy", m.SlotsBusy, m.SlotsTotal))
+	case m.SlotsBusy >= 0:
+		PrintKeyValue("Slots", fmt.Sprintf("%d busy", m.SlotsBusy))
+	}
+	if m.KVCacheUsage >= 0 {
+		PrintKeyValue("KV Cache", fmt.Sprintf("%.0f%% used", m.KVCacheUsage*100))
+	}
+	var rates []string
+	if m.PromptTokensPerSec >= 0 {
+		rates = append(rates, fmt.Sprintf("prompt %.1f tok/s", m.PromptTokensPerSec))
+	}
+	if m.PredictedTokensPerSec >= 0 {
+		rates = append(rates, fmt.Sprintf("generation %.1f tok/s", m.PredictedTokensPerSec))
+	}
+	if len(rates) > 0 {
+		PrintKeyValue("Throughput", strings.Join(rates, ", "))
+	}
+}
+
 // formatPresetOrModel formats a preset or model identifier and returns the label and formatted string.
 // It handles h:, p:, and f: prefixes, as well as identifiers without prefixes.
 func formatPresetOrModel(id string) (label, formatted string) {
@@ -131,10 +180,15 @@ func PrintModelList(models []ModelInfo) {
 			Secondary(m.Quant),
 		)
 		// Compact metadata on second line
-		fmt.Fprintf(Output, "    %s · Downloaded %s\n",
-			m.SizeString,
-			m.DownloadedAt,
-		)
+		fmt.Fprintf(Output, "    %s", m.SizeString)
+		if m.Info != "" {
+			fmt.Fprintf(Output, " · %s", m.Info)
+		}
+		fmt.Fprintf(Output, " · Downloaded %s", m.DownloadedAt)
+		if m.Partial != "" {
+			fmt.Fprintf(Output, " · %s", Warning("partial update "+m.Partial))
+		}
+		fmt.Fprintln(Output)
 	}
 }
 
@@ -143,9 +197,131 @@ type ModelInfo struct {
 	Repo         string
 	Quant        string
 	SizeString   string
+	Info         string // architecture, parameters and context length; empty if unknown
+	DownloadedAt string
+	Partial      string // size of an interrupted re-download; empty if none
+}
+
+// LoraInfo represents a downloaded LoRA adapter for display.
+type LoraInfo struct {
+	Repo         string
+	File         string
+	SizeString   string
 	DownloadedAt string
 }
 
+// PrintLoraList prints a list of downloaded LoRA adapters with formatting.
+func PrintLoraList(loras []LoraInfo) {
+	PrintSectionHeader("🧩", "LoRA Adapters")
+	for _, l := range loras {
+		// Display in full h:repo:file format (matches command input)
+		fmt.Fprintf(Output, "  %s%s:%s\n",
+			Primary("h:"),
+			Primary(l.Repo),
+			Secondary(l.File),
+		)
+		fmt.Fprintf(Output, "    %s · Downloaded %s\n",
+			l.SizeString,
+			l.DownloadedAt,
+		)
+	}
+}
+
+// QuantInfo represents a quant available in a HuggingFace repository for display.
+type QuantInfo struct {
+	Repo       string
+	Quant      string
+	SizeString string
+	Filename   string
+	Files      int  // shards of a split model
+	Downloaded bool // already in the models directory
+	Auto       bool // the quant :auto would pick
+}
+
+// PrintQuantList prints the quants available in a repository.
+func PrintQuantList(quants []QuantInfo) {
+	PrintSectionHeader("🧮", "Quants")
+	if len(quants) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(none)"))
+		return
+	}
+
+	for _, q := range quants {
+		line := fmt.Sprintf("  %s%s:%s", Primary("h:"), Primary(q.Repo), Secondary(q.Quant))
+		if q.Downloaded {
+			line += " " + Success(Symbol("✓")+" downloaded")
+		}
+		if q.Auto {
+			line += " " + Info(Symbol("←")+" auto")
+		}
+		fmt.Fprintln(Output, line)
+		file := q.Filename
+		if q.Files > 1 {
+			file = fmt.Sprintf("%s (%d files)", file, q.Files)
+		}
+		fmt.Fprintf(Output, "    %s · %s\n", q.SizeString, Muted(file))
+	}
+}
+
+// LocalModelInfo represents a GGUF file in a directory alpaca does not
+// manage, for display.
+type LocalModelInfo struct {
+	Path       string
+	SizeString string
+	Files      int    // shards of a split model
+	Kind       string // e.g. "LoRA adapter" or "mmproj"; empty for a model
+}
+
+// PrintLocalModelList prints the GGUF files found in a directory, as the
+// f: identifiers they load with.
+func PrintLocalModelList(models []LocalModelInfo) {
+	PrintSectionHeader("📁", "Local Models")
+	if len(models) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(none)"))
+		return
+	}
+
+	for _, m := range models {
+		fmt.Fprintf(Output, "  %s%s\n", Primary("f:"), Primary(m.Path))
+		line := "    " + m.SizeString
+		if m.Files > 1 {
+			line += fmt.Sprintf(" · %d files", m.Files)
+		}
+		if m.Kind != "" {
+			line += " · " + Muted(m.Kind)
+		}
+		fmt.Fprintln(Output, line)
+	}
+}
+
+// ScheduleInfo represents a schedule entry for display.
+type ScheduleInfo struct {
+	Cron    string
+	Action  string // "load p:name" or "unload"
+	Next    string // next run; empty if the entry never runs
+	Problem string // why the entry is invalid; empty if it is valid
+}
+
+// PrintScheduleList prints the schedule entries, numbered from 1 as
+// `alpaca schedule rm` takes them.
+func PrintScheduleList(entries []ScheduleInfo) {
+	PrintSectionHeader("📅", "Schedule")
+	if len(entries) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(none)"))
+		return
+	}
+
+	for i, e := range entries {
+		fmt.Fprintf(Output, "  %d. %s  %s\n", i+1, Primary(e.Cron), Secondary(e.Action))
+		switch {
+		case e.Problem != "":
+			fmt.Fprintf(Output, "     %s\n", Warning(Symbol("⚠")+" "+e.Problem))
+		case e.Next != "":
+			fmt.Fprintf(Output, "     %s\n", Muted("next: "+e.Next))
+		}
+	}
+}
+
 // PrintPresetList prints a list of available presets with formatting.
 func PrintPresetList(presets []string) {
 	PrintSectionHeader("📦", "Presets")
@@ -160,24 +336,154 @@ func PrintPresetList(presets []string) {
 	}
 }
 
+// UsageStatsInfo represents one model's request counters for display.
+type UsageStatsInfo struct {
+	Model            string
+	Requests         int64
+	Errors           int64
+	PromptTokens     int64
+	CompletionTokens int64
+	AvgLatency       time.Duration
+}
+
+// PrintUsageStats prints per-model request statistics.
+func PrintUsageStats(stats []UsageStatsInfo) {
+	PrintSectionHeader("📊", "Usage")
+	if len(stats) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(no requests through the proxy yet)"))
+		return
+	}
+
+	for _, s := range stats {
+		_, formatted := formatPresetOrModel(s.Model)
+		fmt.Fprintf(Output, "  %s\n", formatted)
+		line := fmt.Sprintf("%d requests · %d in / %d out tokens · avg %s",
+			s.Requests, s.PromptTokens, s.CompletionTokens, s.AvgLatency.Round(time.Millisecond))
+		if s.Errors > 0 {
+			line += " · " + Error(fmt.Sprintf("%d errors", s.Errors))
+		}
+		fmt.Fprintf(Output, "    %s\n", line)
+	}
+}
+
+// LlamaProcessInfo represents a llama-server process for display.
+type LlamaProcessInfo struct {
+	PID    int
+	Preset string
+	State  string // daemon state, shown unless "running"
+	Uptime string // empty when unknown
+	RSS    string // empty when unknown
+	Port   int
+	Model  string // model path, or "router" for a router preset
+}
+
+// PrintProcessList prints the llama-server processes the daemon runs.
+func PrintProcessList(procs []LlamaProcessInfo) {
+	PrintSectionHeader("🦙", "Processes")
+	if len(procs) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(none)"))
+		return
+	}
+
+	for _, p := range procs {
+		_, formatted := formatPresetOrModel(p.Preset)
+		fmt.Fprintf(Output, "  %s  %s\n", formatted, Muted(fmt.Sprintf("PID %d", p.PID)))
+		var parts []string
+		if p.State != "" && p.State != "running" {
+			parts = append(parts, StatusBadge(p.State))
+		}
+		if p.Port > 0 {
+			parts = append(parts, fmt.Sprintf("port %d", p.Port))
+		}
+		if p.Uptime != "" {
+			parts = append(parts, "up "+p.Uptime)
+		}
+		if p.RSS != "" {
+			parts = append(parts, p.RSS)
+		}
+		if len(parts) > 0 {
+			fmt.Fprintf(Output, "    %s\n", strings.Join(parts, " · "))
+		}
+		if p.Model != "" {
+			fmt.Fprintf(Output, "    %s\n", Muted(p.Model))
+		}
+	}
+}
+
+// PartialInfo represents an interrupted download for display.
+type PartialInfo struct {
+	Filename string
+	Size     string
+	Age      string
+	Model    string // h:repo:quant whose file it replaces; empty if none
+}
+
+// PrintPendingList prints the partial downloads in the models directory.
+func PrintPendingList(partials []PartialInfo) {
+	PrintSectionHeader("📥", "Partial Downloads")
+	if len(partials) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(none)"))
+		return
+	}
+
+	for _, p := range partials {
+		fmt.Fprintf(Output, "  %s\n", Primary(p.Filename))
+		line := fmt.Sprintf("%s · %s ago", p.Size, p.Age)
+		if p.Model != "" {
+			line += " · update of " + p.Model
+		}
+		fmt.Fprintf(Output, "    %s\n", line)
+	}
+}
+
+// DiskUsageInfo is one row of the disk usage table.
+type DiskUsageInfo struct {
+	Name   string
+	Size   string
+	Detail string // e.g. "2 quants · mmproj 851.0 MB"; empty if none
+}
+
+// PrintDiskUsage prints the disk usage of each repository, then of the
+// other things alpaca keeps, and the total.
+func PrintDiskUsage(repos, other []DiskUsageInfo, total string) {
+	PrintSectionHeader("💾", "Disk Usage")
+	if len(repos) == 0 {
+		fmt.Fprintf(Output, "  %s\n", Muted("(no models)"))
+	}
+	for _, r := range repos {
+		line := fmt.Sprintf("  %10s  %s", r.Size, Primary(r.Name))
+		if r.Detail != "" {
+			line += "  " + Muted(r.Detail)
+		}
+		fmt.Fprintln(Output, line)
+	}
+	if len(other) > 0 {
+		fmt.Fprintln(Output)
+	}
+	for _, o := range other {
+		fmt.Fprintf(Output, "  %10s  %s\n", o.Size, Label(o.Name))
+	}
+	fmt.Fprintf(Output, "  %s  %s\n", Heading(fmt.Sprintf("%10s", total)), Heading("Total"))
+}
+
 // PrintSuccess prints a success message with green checkmark.
 func PrintSuccess(message string) {
-	fmt.Fprintf(Output, "%s %s\n", Success("✓"), message)
+	fmt.Fprintf(Output, "%s %s\n", Success(Symbol("✓")), message)
 }
 
 // PrintError prints an error message with red X.
 func PrintError(message string) {
-	fmt.Fprintf(Output, "%s %s\n", Error("✗"), message)
+	fmt.Fprintf(Output, "%s %s\n", Error(Symbol("✗")), message)
 }
 
 // PrintWarning prints a warning message with yellow exclamation.
 func PrintWarning(message string) {
-	fmt.Fprintf(Output, "%s %s\n", Warning("⚠"), message)
+	fmt.Fprintf(Output, "%s %s\n", Warning(Symbol("⚠")), message)
 }
 
 // PrintInfo prints an info message with info icon.
 func PrintInfo(message string) {
-	fmt.Fprintf(Output, "%s %s\n", Info("ℹ"), message)
+	fmt.Fprintf(Output, "%s %s\n", Info(Symbol("ℹ")), message)
 }
 
 // PrintConfirm prints a confirmation prompt with question mark icon (no newline).
@@ -185,15 +491,33 @@ func PrintConfirm(message string) {
 	fmt.Fprintf(Output, "%s %s (y/N): ", Warning("?"), message)
 }
 
+// Confirm prints a confirmation prompt and reads the answer from Input.
+// Returns true only if the answer is "y" or "Y".
+func Confirm(message string) bool {
+	PrintConfirm(message)
+	input, err := Input.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	input = strings.TrimSpace(input)
+	return input == "y" || input == "Y"
+}
+
 // PresetDetails contains preset information for display.
 type PresetDetails struct {
-	Name       string
-	Model      string
-	DraftModel string
-	Mmproj     string
-	Host       string
-	Port       int
-	Options    map[string]string
+	Name         string
+	Model        string
+	DraftModel   string
+	Mmproj       string
+	Lora         []string
+	Host         string
+	Port         int
+	GPU          string // GPU fields as key=value pairs, empty if none
+	SystemPrompt string
+	Sampling     string // sampling parameters as key=value pairs, empty if none
+	Priority     string // priority fields as key=value pairs, empty if none
+	Health       string // readiness check, empty for the default
+	Options      map[string]string
 }
 
 // ModelDetails contains model metadata for display.
@@ -203,8 +527,17 @@ type ModelDetails struct {
 	Filename     string
 	Path         string
 	Size         string
+	Architecture string // from the GGUF metadata; empty if unknown
+	Parameters   string // formatted parameter count; empty if unknown
+	Context      string // formatted training context length; empty if unknown
 	DownloadedAt string
-	Mmproj       string // formatted mmproj info, empty if none
+	Source       string   // URL or path the model came from
+	SHA256       string   // upstream hash (of the first shard of a split model); empty if unknown
+	Mmproj       string   // formatted mmproj info, empty if none
+	MmprojSHA256 string   // upstream hash of the mmproj; empty if unknown
+	Shards       []string // files of a split model, in order; empty for a single file
+	ExtraFiles   []string // formatted info on files pulled with --with-files
+	Presets      []string // presets that reference the model, as p:name
 }
 
 // PrintPresetDetails prints preset details in a formatted style.
@@ -220,12 +553,36 @@ func PrintPresetDetails(p PresetDetails) {
 	if p.Mmproj != "" {
 		PrintKeyValue("Mmproj", p.Mmproj)
 	}
+	if len(p.Lora) > 0 {
+		PrintKeyValue("LoRA", strings.Join(p.Lora, ", "))
+	}
 	PrintKeyValue("Endpoint", Link(fmt.Sprintf("http://%s:%d", p.Host, p.Port)))
+	if p.GPU != "" {
+		PrintKeyValue("GPU", p.GPU)
+	}
+	printSampling("", p.SystemPrompt, p.Sampling)
+	if p.Priority != "" {
+		PrintKeyValue("Priority", p.Priority)
+	}
+	if p.Health != "" {
+		PrintKeyValue("Health Check", p.Health)
+	}
 	if len(p.Options) > 0 {
 		PrintKeyValue("Options", formatOptions(p.Options))
 	}
 }
 
+// printSampling prints a system prompt, one line per line, and sampling
+// parameters, with keys indented by indent.
+func printSampling(indent, systemPrompt, sampling string) {
+	if systemPrompt != "" {
+		printKeyValues(indent+"System Prompt", strings.Split(strings.TrimRight(systemPrompt, "\n"), "\n"))
+	}
+	if sampling != "" {
+		PrintKeyValue(indent+"Sampling", sampling)
+	}
+}
+
 // PrintModelDetails prints model metadata in a formatted style.
 func PrintModelDetails(m ModelDetails) {
 	// Display in full h:repo:quant format
@@ -237,26 +594,75 @@ func PrintModelDetails(m ModelDetails) {
 	PrintDetailHeader("🤖", "Model", identifier)
 
 	PrintKeyValue("Filename", m.Filename)
+	printKeyValues(fmt.Sprintf("Shards (%d)", len(m.Shards)), m.Shards)
 	PrintKeyValue("Size", m.Size)
+	if m.Architecture != "" {
+		PrintKeyValue("Architecture", m.Architecture)
+	}
+	if m.Parameters != "" {
+		PrintKeyValue("Parameters", m.Parameters)
+	}
+	if m.Context != "" {
+		PrintKeyValue("Context Length", m.Context)
+	}
 	PrintKeyValue("Downloaded", m.DownloadedAt)
+	if m.Source != "" {
+		PrintKeyValue("Source", Link(m.Source))
+	}
+	if m.SHA256 != "" {
+		PrintKeyValue("SHA256", m.SHA256)
+	}
 	PrintKeyValue("Path", Link(m.Path))
 	if m.Mmproj != "" {
 		PrintKeyValue("Mmproj", m.Mmproj)
 	}
-	PrintKeyValue("Status", Success("✓ Ready"))
+	if m.MmprojSHA256 != "" {
+		PrintKeyValue("Mmproj SHA256", m.MmprojSHA256)
+	}
+	printKeyValues("Files", m.ExtraFiles)
+	if len(m.Presets) > 0 {
+		PrintKeyValue("Used by", strings.Join(m.Presets, ", "))
+	} else {
+		PrintKeyValue("Used by", Muted("no presets"))
+	}
+	PrintKeyValue("Status", Success(Symbol("✓")+" Ready"))
+}
+
+// printKeyValues prints values one per line, the first labeled key.
+// Nothing is printed for no values.
+func printKeyValues(key string, values []string) {
+	for i, v := range values {
+		if i > 0 {
+			key = ""
+		}
+		PrintKeyValue(key, v)
+	}
+}
+
+// PrintHeading prints a heading with its icon, which plain output drops.
+func PrintHeading(icon, title string) {
+	fmt.Fprintln(Output, withIcon(icon, Heading(title)))
 }
 
 // PrintSectionHeader prints a section header with divider for list outputs.
 func PrintSectionHeader(icon, title string) {
-	fmt.Fprintf(Output, "%s %s\n", icon, Heading(title))
+	PrintHeading(icon, title)
 	// Divider length: icon (2 chars including icon + 2 chars including space,) + title length
 	dividerLen := len(title) + 4
-	fmt.Fprintln(Output, Muted(strings.Repeat("─", dividerLen)))
+	fmt.Fprintln(Output, Muted(strings.Repeat(Symbol("─"), dividerLen)))
+}
+
+// PrintLines prints a section header followed by indented lines.
+func PrintLines(icon, title string, lines []string) {
+	PrintSectionHeader(icon, title)
+	for _, line := range lines {
+		fmt.Fprintf(Output, "  %s\n", line)
+	}
 }
 
 // PrintDetailHeader prints a header for detail views (no divider).
 func PrintDetailHeader(icon, title, identifier string) {
-	fmt.Fprintf(Output, "%s %s: %s\n", icon, Heading(title), identifier)
+	fmt.Fprintf(Output, "%s: %s\n", withIcon(icon, Heading(title)), identifier)
 }
 
 // PrintKeyValue prints a key-value pair with aligned formatting.
@@ -266,30 +672,34 @@ func PrintKeyValue(key, value string) {
 
 // RouterModelInfo represents a model in router mode status display.
 type RouterModelInfo struct {
-	ID     string
-	Status string
-	Mmproj string // mmproj path, empty if none
+	ID          string
+	Status      string
+	Mmproj      string // mmproj path, empty if none
+	ActiveSlots int    // slots processing a request; negative if not reported
+	Queued      int    // requests waiting for a slot; negative if not reported
 }
 
 // ModelStatusBadge returns a colored badge for a model status.
 func ModelStatusBadge(status string) string {
 	switch status {
 	case "loaded":
-		return Success("●") + " loaded"
+		return Success(Symbol("●")) + " loaded"
 	case "loading":
-		return Warning("◐") + " loading"
+		return Warning(Symbol("◐")) + " loading"
 	case "unloaded":
-		return Muted("○") + " unloaded"
+		return Muted(Symbol("○")) + " unloaded"
 	default:
-		return Error("✗") + " " + status
+		return Error(Symbol("✗")) + " " + status
 	}
 }
 
 // PrintRouterStatus prints router mode daemon status in a formatted style.
-func PrintRouterStatus(state, preset, endpoint, logPath string, models []RouterModelInfo) {
-	fmt.Fprintf(Output, "🚀 %s\n", Heading("Status"))
+// A non-empty modelsErr means llama-server could not be queried for its
+// models; a nil models without it means they were not reported at all.
+func PrintRouterStatus(state, detail, preset, endpoint, logPath string, models []RouterModelInfo, modelsErr string) {
+	PrintHeading("🚀", "Status")
 
-	PrintKeyValue("State", StatusBadge(state))
+	PrintKeyValue("State", stateLabel(state, detail))
 	if preset != "" {
 		label, formatted := formatPresetOrModel(preset)
 		PrintKeyValue(label, formatted)
@@ -299,39 +709,84 @@ func PrintRouterStatus(state, preset, endpoint, logPath string, models []RouterM
 		PrintKeyValue("Endpoint", Link(endpoint))
 	}
 	PrintKeyValue("Logs", logPath)
+	PrintRouterModels(models, modelsErr)
+}
 
-	if len(models) > 0 {
+// PrintRouterModels prints the models of a router preset with their status,
+// or why their status is unavailable. It prints nothing when models is nil
+// and modelsErr is empty.
+func PrintRouterModels(models []RouterModelInfo, modelsErr string) {
+	switch {
+	case modelsErr != "":
+		fmt.Fprintln(Output)
+		fmt.Fprintf(Output, "  %s\n", Heading("Models"))
+		fmt.Fprintf(Output, "  %s\n", Muted(strings.Repeat(Symbol("─"), 10)))
+		fmt.Fprintf(Output, "  %s status unavailable: %s\n", Warning(Symbol("⚠")), modelsErr)
+	case models != nil:
 		fmt.Fprintln(Output)
 		fmt.Fprintf(Output, "  %s\n", Heading(fmt.Sprintf("Models (%d)", len(models))))
-		fmt.Fprintf(Output, "  %s\n", Muted("──────────"))
+		fmt.Fprintf(Output, "  %s\n", Muted(strings.Repeat(Symbol("─"), 10)))
+		if len(models) == 0 {
+			fmt.Fprintf(Output, "  %s\n", Muted("No models reported by llama-server"))
+		}
 		for _, m := range models {
 			suffix := ""
+			if load := routerModelLoad(m); load != "" {
+				suffix = "    " + load
+			}
 			if m.Mmproj != "" {
-				suffix = "    mmproj"
+				suffix += "    mmproj"
 			}
 			fmt.Fprintf(Output, "  %-24s %s%s\n", m.ID, ModelStatusBadge(m.Status), suffix)
 		}
 	}
 }
 
+// routerModelLoad formats the active slots and queued requests of m, with
+// queued requests highlighted: the model cannot keep up. Empty when neither
+// was reported.
+func routerModelLoad(m RouterModelInfo) string {
+	var parts []string
+	if m.ActiveSlots >= 0 {
+		parts = append(parts, fmt.Sprintf("%d active", m.ActiveSlots))
+	}
+	if m.Queued > 0 {
+		parts = append(parts, Warning(fmt.Sprintf("%d queued", m.Queued)))
+	} else if m.Queued == 0 {
+		parts = append(parts, "0 queued")
+	}
+	return strings.Join(parts, " · ")
+}
+
 // RouterPresetDetails contains router preset information for display.
 type RouterPresetDetails struct {
-	Name        string
-	Host        string
-	Port        int
-	MaxModels   int
-	IdleTimeout int
-	Options     map[string]string
-	Models      []RouterModelDetail
+	Name         string
+	Host         string
+	Port         int
+	MaxModels    int
+	IdleTimeout  int
+	GPU          string // GPU fields for every model, empty if none
+	SystemPrompt string // for models without their own
+	Sampling     string // sampling parameters for every model, empty if none
+	Priority     string // priority fields, empty if none
+	Health       string // readiness check, empty for the default
+	Options      map[string]string
+	Models       []RouterModelDetail
 }
 
 // RouterModelDetail contains a single model's details in a router preset.
 type RouterModelDetail struct {
-	Name       string
-	Model      string
-	DraftModel string
-	Mmproj     string
-	Options    map[string]string
+	Name         string
+	Model        string
+	DraftModel   string
+	Mmproj       string
+	Lora         []string
+	Pin          bool
+	TTL          int
+	GPU          string
+	SystemPrompt string
+	Sampling     string
+	Options      map[string]string
 }
 
 // PrintRouterPresetDetails prints router preset details in a formatted style.
@@ -347,6 +802,16 @@ func PrintRouterPresetDetails(p RouterPresetDetails) {
 	if p.IdleTimeout > 0 {
 		PrintKeyValue("Idle Timeout", fmt.Sprintf("%ds", p.IdleTimeout))
 	}
+	if p.GPU != "" {
+		PrintKeyValue("GPU", p.GPU)
+	}
+	printSampling("", p.SystemPrompt, p.Sampling)
+	if p.Priority != "" {
+		PrintKeyValue("Priority", p.Priority)
+	}
+	if p.Health != "" {
+		PrintKeyValue("Health Check", p.Health)
+	}
 	if len(p.Options) > 0 {
 		PrintKeyValue("Options", formatOptions(p.Options))
 	}
@@ -354,7 +819,7 @@ func PrintRouterPresetDetails(p RouterPresetDetails) {
 	if len(p.Models) > 0 {
 		fmt.Fprintln(Output)
 		fmt.Fprintf(Output, "  %s\n", Heading(fmt.Sprintf("Models (%d)", len(p.Models))))
-		fmt.Fprintf(Output, "  %s\n", Muted("──────────"))
+		fmt.Fprintf(Output, "  %s\n", Muted(strings.Repeat(Symbol("─"), 10)))
 		for i, m := range p.Models {
 			fmt.Fprintf(Output, "  %s\n", Primary(m.Name))
 			PrintKeyValue("  Model", Link(m.Model))
@@ -364,6 +829,18 @@ func PrintRouterPresetDetails(p RouterPresetDetails) {
 			if m.Mmproj != "" {
 				PrintKeyValue("  Mmproj", m.Mmproj)
 			}
+			if len(m.Lora) > 0 {
+				PrintKeyValue("  LoRA", strings.Join(m.Lora, ", "))
+			}
+			if m.Pin {
+				PrintKeyValue("  Pinned", "yes")
+			} else if m.TTL > 0 {
+				PrintKeyValue("  TTL", fmt.Sprintf("%ds", m.TTL))
+			}
+			if m.GPU != "" {
+				PrintKeyValue("  GPU", m.GPU)
+			}
+			printSampling("  ", m.SystemPrompt, m.Sampling)
 			if len(m.Options) > 0 {
 				PrintKeyValue("  Options", formatOptions(m.Options))
 			}
@@ -20,10 +20,13 @@ func TestPrintPresetDetails(t *testing.T) {
 	defer func() { Output = os.Stdout }()
 
 	preset := PresetDetails{
-		Name:  "my-preset",
-		Model: "h:org/model:Q4_K_M",
-		Host:  "127.0.0.1",
-		Port:  8080,
+		Name:         "my-preset",
+		Model:        "h:org/model:Q4_K_M",
+		Host:         "127.0.0.1",
+		Port:         8080,
+		GPU:          "gpu-layers=99 tensor-split=3,1",
+		SystemPrompt: "You are a coding assistant.\nAnswer with code.",
+		Sampling:     "temp=0.2 top-p=0.9",
 		Options: map[string]string{
 			"ctx-size":   "4096",
 			"flash-attn": "on",
@@ -35,6 +38,15 @@ func TestPrintPresetDetails(t *testing.T) {
 
 	// Assert
 	output := buf.String()
+	if !strings.Contains(output, "GPU              gpu-layers=99 tensor-split=3,1") {
+		t.Error("Output should contain the GPU fields")
+	}
+	if !strings.Contains(output, "System Prompt    You are a coding assistant.\n                   Answer with code.") {
+		t.Errorf("Output should contain the system prompt, one line per line:\n%s", output)
+	}
+	if !strings.Contains(output, "Sampling         temp=0.2 top-p=0.9") {
+		t.Error("Output should contain the sampling parameters")
+	}
 	if !strings.Contains(output, "📦 Preset: p:my-preset") {
 		t.Error("Output should contain '📦 Preset: p:my-preset' header")
 	}
@@ -125,6 +137,37 @@ func TestPrintPresetDetails_WithMmproj(t *testing.T) {
 	}
 }
 
+func TestPrintPresetDetails_WithLora(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	preset := PresetDetails{
+		Name:  "lora-preset",
+		Model: "h:org/model:Q4_K_M",
+		Lora:  []string{"h:org/lora:adapter.gguf", "f:/path/to/style.gguf"},
+		Host:  "127.0.0.1",
+		Port:  8080,
+	}
+
+	// Act
+	PrintPresetDetails(preset)
+
+	// Assert
+	output := buf.String()
+	if !strings.Contains(output, "LoRA") {
+		t.Error("Output should contain 'LoRA' label")
+	}
+	if !strings.Contains(output, "h:org/lora:adapter.gguf, f:/path/to/style.gguf") {
+		t.Error("Output should contain joined lora values")
+	}
+}
+
 func TestPrintModelDetails(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
@@ -185,6 +228,50 @@ func TestPrintModelDetails(t *testing.T) {
 	if strings.Contains(output, "Mmproj") {
 		t.Error("Output should not contain 'Mmproj' when empty")
 	}
+	if !strings.Contains(output, "no presets") {
+		t.Error("Output should note that no preset uses the model")
+	}
+}
+
+func TestPrintModelDetails_Provenance(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	model := ModelDetails{
+		Repo:         "org/big-model",
+		Quant:        "Q4_K_M",
+		Filename:     "big-Q4_K_M-00001-of-00002.gguf",
+		Path:         "/path/to/big-Q4_K_M-00001-of-00002.gguf",
+		Size:         "80.0 GB",
+		DownloadedAt: "2024-01-15 10:30:00",
+		Source:       "https://huggingface.co/org/big-model",
+		SHA256:       "abc123",
+		Shards:       []string{"big-Q4_K_M-00001-of-00002.gguf", "big-Q4_K_M-00002-of-00002.gguf"},
+		Presets:      []string{"p:big", "p:workspace"},
+	}
+
+	// Act
+	PrintModelDetails(model)
+
+	// Assert
+	output := buf.String()
+	for _, want := range []string{
+		"Shards (2)",
+		"big-Q4_K_M-00002-of-00002.gguf",
+		"Source           https://huggingface.co/org/big-model",
+		"SHA256           abc123",
+		"Used by          p:big, p:workspace",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, output)
+		}
+	}
 }
 
 func TestPrintModelDetails_WithMmproj(t *testing.T) {
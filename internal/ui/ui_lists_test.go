@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fatih/color"
 )
@@ -21,7 +22,7 @@ func TestPrintModelList(t *testing.T) {
 
 	models := []ModelInfo{
 		{Repo: "org/model1", Quant: "Q4_K_M", SizeString: "2.5 GB", DownloadedAt: "2024-01-15"},
-		{Repo: "org/model2", Quant: "Q8_0", SizeString: "5.0 GB", DownloadedAt: "2024-01-16"},
+		{Repo: "org/model2", Quant: "Q8_0", SizeString: "5.0 GB", DownloadedAt: "2024-01-16", Partial: "1.2 GB"},
 	}
 
 	// Act
@@ -44,6 +45,9 @@ func TestPrintModelList(t *testing.T) {
 	if !strings.Contains(output, "h:org/model2:Q8_0") {
 		t.Error("Output should contain second model with h: prefix and quant")
 	}
+	if strings.Count(output, "partial update") != 1 || !strings.Contains(output, "Downloaded 2024-01-16 · partial update 1.2 GB") {
+		t.Errorf("Output should note the second model's partial update only:\n%s", output)
+	}
 }
 
 func TestPrintModelList_Empty(t *testing.T) {
@@ -69,6 +73,66 @@ func TestPrintModelList_Empty(t *testing.T) {
 	}
 }
 
+func TestPrintQuantList(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	quants := []QuantInfo{
+		{Repo: "org/model", Quant: "Q8_0", SizeString: "8.0 GB", Filename: "Q8_0/model-00001-of-00002.gguf", Files: 2},
+		{Repo: "org/model", Quant: "Q4_K_M", SizeString: "4.0 GB", Filename: "model-Q4_K_M.gguf", Files: 1, Downloaded: true, Auto: true},
+	}
+
+	// Act
+	PrintQuantList(quants)
+
+	// Assert
+	want := "🧮 Quants\n" +
+		"──────────\n" +
+		"  h:org/model:Q8_0\n" +
+		"    8.0 GB · Q8_0/model-00001-of-00002.gguf (2 files)\n" +
+		"  h:org/model:Q4_K_M ✓ downloaded ← auto\n" +
+		"    4.0 GB · model-Q4_K_M.gguf\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintQuantList() output =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPrintScheduleList(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	entries := []ScheduleInfo{
+		{Cron: "0 9 * * mon-fri", Action: "load p:coder", Next: "Mon 2026-10-19 09:00"},
+		{Cron: "0 9 * *", Action: "unload", Problem: "cron expression must have 5 fields"},
+	}
+
+	// Act
+	PrintScheduleList(entries)
+
+	// Assert
+	want := "📅 Schedule\n" +
+		"────────────\n" +
+		"  1. 0 9 * * mon-fri  load p:coder\n" +
+		"     next: Mon 2026-10-19 09:00\n" +
+		"  2. 0 9 * *  unload\n" +
+		"     ⚠ cron expression must have 5 fields\n"
+	if got := buf.String(); got != want {
+		t.Errorf("PrintScheduleList() output =\n%s\nwant:\n%s", got, want)
+	}
+}
+
 func TestPrintPresetList(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
@@ -119,3 +183,218 @@ func TestPrintPresetList_Empty(t *testing.T) {
 		t.Error("Output should contain '(none)' when empty")
 	}
 }
+
+func TestPrintLocalModelList(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	models := []LocalModelInfo{
+		{Path: "/models/big-00001-of-00002.gguf", SizeString: "40.0 GB", Files: 2},
+		{Path: "/models/mmproj-f16.gguf", SizeString: "800.0 MB", Files: 1, Kind: "mmproj"},
+	}
+
+	// Act
+	PrintLocalModelList(models)
+
+	// Assert
+	output := buf.String()
+	if !strings.Contains(output, "f:/models/big-00001-of-00002.gguf") {
+		t.Error("Output should contain the model with f: prefix")
+	}
+	if !strings.Contains(output, "40.0 GB · 2 files") {
+		t.Error("Output should contain the size and shard count")
+	}
+	if !strings.Contains(output, "800.0 MB · mmproj") {
+		t.Error("Output should note the mmproj")
+	}
+}
+
+func TestPrintLoraList(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	loras := []LoraInfo{
+		{Repo: "org/lora-repo", File: "adapter.gguf", SizeString: "48.0 MB", DownloadedAt: "2024-01-15"},
+	}
+
+	// Act
+	PrintLoraList(loras)
+
+	// Assert
+	output := buf.String()
+	if !strings.Contains(output, "LoRA Adapters") {
+		t.Error("Output should contain LoRA header")
+	}
+	if !strings.Contains(output, "h:org/lora-repo:adapter.gguf") {
+		t.Error("Output should contain adapter with h: prefix and filename")
+	}
+	if !strings.Contains(output, "48.0 MB") {
+		t.Error("Output should contain adapter size")
+	}
+}
+
+func TestPrintProcessList(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	procs := []LlamaProcessInfo{
+		{PID: 4242, Preset: "qwen3", State: "running", Uptime: "1h2m3s", RSS: "4.0 GB", Port: 8080, Model: "/models/qwen3.gguf"},
+		{PID: 4343, Preset: "multi", State: "loading", Port: 8081, Model: "router"},
+	}
+
+	// Act
+	PrintProcessList(procs)
+
+	// Assert
+	output := buf.String()
+	for _, want := range []string{
+		"🦙 Processes",
+		"p:qwen3  PID 4242",
+		"port 8080 · up 1h2m3s · 4.0 GB",
+		"/models/qwen3.gguf",
+		"p:multi  PID 4343",
+		"Loading · port 8081",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "Running") {
+		t.Errorf("output should not show the running state:\n%s", output)
+	}
+}
+
+func TestPrintProcessList_Empty(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	// Act
+	PrintProcessList(nil)
+
+	// Assert
+	if !strings.Contains(buf.String(), "(none)") {
+		t.Errorf("output should say there are no processes:\n%s", buf.String())
+	}
+}
+
+func TestPrintPendingList(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	partials := []PartialInfo{
+		{Filename: "qwen3-q4_k_m.gguf", Size: "1.2 GB", Age: "3h", Model: "h:org/qwen3:Q4_K_M"},
+		{Filename: "new-model.gguf", Size: "512.0 MB", Age: "2d"},
+	}
+
+	// Act
+	PrintPendingList(partials)
+
+	// Assert
+	output := buf.String()
+	for _, want := range []string{
+		"📥 Partial Downloads",
+		"qwen3-q4_k_m.gguf",
+		"1.2 GB · 3h ago · update of h:org/qwen3:Q4_K_M",
+		"new-model.gguf",
+		"512.0 MB · 2d ago\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintPendingList_Empty(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	// Act
+	PrintPendingList(nil)
+
+	// Assert
+	if !strings.Contains(buf.String(), "(none)") {
+		t.Errorf("output should say there are no partial downloads:\n%s", buf.String())
+	}
+}
+
+func TestPrintUsageStats(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	stats := []UsageStatsInfo{
+		{Model: "qwen3", Requests: 12, PromptTokens: 3400, CompletionTokens: 5600, AvgLatency: 1250 * time.Millisecond},
+		{Model: "h:org/repo:Q4_K_M", Requests: 1, Errors: 1},
+	}
+
+	// Act
+	PrintUsageStats(stats)
+
+	// Assert
+	output := buf.String()
+	for _, want := range []string{
+		"📊 Usage",
+		"p:qwen3",
+		"12 requests · 3400 in / 5600 out tokens · avg 1.25s",
+		"h:org/repo:Q4_K_M",
+		"1 errors",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestPrintUsageStats_Empty(t *testing.T) {
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	// Act
+	PrintUsageStats(nil)
+
+	// Assert
+	if !strings.Contains(buf.String(), "no requests through the proxy yet") {
+		t.Errorf("output should explain why there are no stats:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestPlainOutput(t *testing.T) {
+	tests := []struct {
+		name  string
+		print func()
+		want  string
+	}{
+		{"success", func() { PrintSuccess("Model ready") }, "[ok] Model ready\n"},
+		{"error", func() { PrintError("load failed") }, "[error] load failed\n"},
+		{"warning", func() { PrintWarning("ctx-size too large") }, "[warning] ctx-size too large\n"},
+		{"info", func() { PrintInfo("Loading p:qwen3...") }, "[info] Loading p:qwen3...\n"},
+		{"section header", func() { PrintSectionHeader("🤖", "Models") }, "Models\n----------\n"},
+		{"detail header", func() { PrintDetailHeader("📦", "Preset", "p:qwen3") }, "Preset: p:qwen3\n"},
+		{"status badge", func() { PrintKeyValue("State", StatusBadge("running")) }, "  State            * Running\n"},
+		{"router model badge", func() { PrintKeyValue("qwen3", ModelStatusBadge("loading")) }, "  qwen3            ~ loading\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			defer func() { plain, color.NoColor = false, false }()
+			SetPlain(true)
+			var buf bytes.Buffer
+			Output = &buf
+			defer func() { Output = os.Stdout }()
+
+			// Act
+			tt.print()
+
+			// Assert
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		noColor string
+		term    string
+		want    bool
+	}{
+		{"color terminal", "", "xterm-256color", false},
+		{"NO_COLOR", "1", "xterm-256color", true},
+		{"dumb terminal", "", "dumb", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("TERM", tt.term)
+
+			// Act
+			got := PlainFromEnv()
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("PlainFromEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
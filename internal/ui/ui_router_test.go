@@ -26,7 +26,7 @@ func TestPrintRouterStatus(t *testing.T) {
 	}
 
 	// Act
-	PrintRouterStatus("running", "p:my-workspace", "http://127.0.0.1:8080", "~/.alpaca/logs/llama.log", models)
+	PrintRouterStatus("running", "", "p:my-workspace", "http://127.0.0.1:8080", "~/.alpaca/logs/llama.log", models, "")
 
 	// Assert
 	output := buf.String()
@@ -82,7 +82,7 @@ func TestPrintRouterStatus_NoModels(t *testing.T) {
 	defer func() { Output = os.Stdout }()
 
 	// Act
-	PrintRouterStatus("running", "p:test", "http://127.0.0.1:8080", "/log", nil)
+	PrintRouterStatus("running", "", "p:test", "http://127.0.0.1:8080", "/log", nil, "")
 
 	// Assert
 	output := buf.String()
@@ -94,6 +94,38 @@ func TestPrintRouterStatus_NoModels(t *testing.T) {
 	}
 }
 
+func TestPrintRouterStatus_ModelsSection(t *testing.T) {
+	tests := []struct {
+		name      string
+		models    []RouterModelInfo
+		modelsErr string
+		want      string
+	}{
+		{"no models", []RouterModelInfo{}, "", "No models reported by llama-server"},
+		{"query failed", nil, "connection refused", "⚠ status unavailable: connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			color.NoColor = true
+			defer func() { color.NoColor = false }()
+
+			// Arrange
+			var buf bytes.Buffer
+			Output = &buf
+			defer func() { Output = os.Stdout }()
+
+			// Act
+			PrintRouterStatus("running", "", "p:ws", "http://127.0.0.1:8080", "/log", tt.models, tt.modelsErr)
+
+			// Assert
+			if output := buf.String(); !strings.Contains(output, tt.want) {
+				t.Errorf("output should contain %q:\n%s", tt.want, output)
+			}
+		})
+	}
+}
+
 func TestPrintRouterStatus_WithMmproj(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
@@ -110,7 +142,7 @@ func TestPrintRouterStatus_WithMmproj(t *testing.T) {
 	}
 
 	// Act
-	PrintRouterStatus("running", "p:ws", "http://127.0.0.1:8080", "/log", models)
+	PrintRouterStatus("running", "", "p:ws", "http://127.0.0.1:8080", "/log", models, "")
 
 	// Assert
 	output := buf.String()
@@ -122,6 +154,34 @@ func TestPrintRouterStatus_WithMmproj(t *testing.T) {
 	}
 }
 
+func TestPrintRouterModels_Load(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	models := []RouterModelInfo{
+		{ID: "qwen3", Status: "loaded", ActiveSlots: 4, Queued: 3},
+		{ID: "gemma3", Status: "loaded", ActiveSlots: -1, Queued: -1},
+	}
+
+	// Act
+	PrintRouterModels(models, "")
+
+	// Assert
+	output := buf.String()
+	if !strings.Contains(output, "4 active · 3 queued") {
+		t.Errorf("output = %q, want qwen3's load", output)
+	}
+	if strings.Count(output, "active") != 1 {
+		t.Errorf("output = %q, want no load for gemma3", output)
+	}
+}
+
 func TestPrintRouterPresetDetails(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+func TestSpinner(t *testing.T) {
+	tests := []struct {
+		name     string
+		live     bool
+		want     []string
+		wantNone []string
+	}{
+		{
+			name: "terminal",
+			live: true,
+			want: []string{"Loading p:qwen3...", "starting llama-server", "\r\033[K"},
+		},
+		{
+			name:     "not a terminal",
+			live:     false,
+			want:     []string{"ℹ Loading p:qwen3...\n"},
+			wantNone: []string{"starting llama-server", "\r"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			color.NoColor = true
+			defer func() { color.NoColor = false }()
+
+			// Arrange
+			var buf bytes.Buffer
+			Output = &buf
+			defer func() { Output = os.Stdout }()
+
+			// Act
+			s := StartSpinner("Loading p:qwen3...", tt.live)
+			s.SetPhase("starting llama-server")
+			elapsed := s.Stop()
+
+			// Assert
+			output := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output = %q, want it to contain %q", output, want)
+				}
+			}
+			for _, unwanted := range tt.wantNone {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("output = %q, want no %q", output, unwanted)
+				}
+			}
+			if elapsed <= 0 {
+				t.Errorf("Stop() = %v, want the time the spinner ran", elapsed)
+			}
+		})
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{1234 * time.Millisecond, "1.2s"},
+		{12*time.Second + 449*time.Millisecond, "12.4s"},
+		{90*time.Second + 600*time.Millisecond, "1m31s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			// Act
+			got := FormatElapsed(tt.d)
+
+			// Assert
+			if got != tt.want {
+				t.Errorf("FormatElapsed(%v) = %q, want %q", tt.d, got, tt.want)
+			}
+		})
+	}
+}
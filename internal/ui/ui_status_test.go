@@ -54,7 +54,7 @@ func TestPrintStatus(t *testing.T) {
 			defer func() { Output = os.Stdout }()
 
 			// Act
-			PrintStatus("running", tt.preset, "http://localhost:8080", "/path/to/llama.log", "")
+			PrintStatus("running", "", tt.preset, "http://localhost:8080", "/path/to/llama.log", "")
 
 			// Assert
 			output := buf.String()
@@ -97,7 +97,7 @@ func TestPrintStatus_NoPreset(t *testing.T) {
 	defer func() { Output = os.Stdout }()
 
 	// Act
-	PrintStatus("idle", "", "", "/path/to/llama.log", "")
+	PrintStatus("idle", "", "", "", "/path/to/llama.log", "")
 
 	// Assert
 	output := buf.String()
@@ -118,6 +118,55 @@ func TestPrintStatus_NoPreset(t *testing.T) {
 	}
 }
 
+func TestPrintServerMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics ServerMetrics
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "all values",
+			metrics: ServerMetrics{KVCacheUsage: 0.375, SlotsBusy: 1, SlotsTotal: 4, PromptTokensPerSec: 512.5, PredictedTokensPerSec: 42.25},
+			want:    []string{"1/4 busy", "38% used", "prompt 512.5 tok/s, generation 42.2 tok/s"},
+		},
+		{
+			name:    "unreported values omitted",
+			metrics: ServerMetrics{KVCacheUsage: -1, SlotsBusy: 2, SlotsTotal: -1, PromptTokensPerSec: -1, PredictedTokensPerSec: -1},
+			want:    []string{"2 busy"},
+			notWant: []string{"KV Cache", "Throughput"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			color.NoColor = true
+			defer func() { color.NoColor = false }()
+
+			// Arrange
+			var buf bytes.Buffer
+			Output = &buf
+			defer func() { Output = os.Stdout }()
+
+			// Act
+			PrintServerMetrics(tt.metrics)
+
+			// Assert
+			output := buf.String()
+			for _, s := range tt.want {
+				if !strings.Contains(output, s) {
+					t.Errorf("output should contain %q, got:\n%s", s, output)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(output, s) {
+					t.Errorf("output should not contain %q, got:\n%s", s, output)
+				}
+			}
+		})
+	}
+}
+
 func TestPrintStatus_WithMmproj(t *testing.T) {
 	// Disable color for testing
 	color.NoColor = true
@@ -129,7 +178,7 @@ func TestPrintStatus_WithMmproj(t *testing.T) {
 	defer func() { Output = os.Stdout }()
 
 	// Act
-	PrintStatus("running", "p:vision", "http://localhost:8080", "/path/to/llama.log", "/models/mmproj.gguf")
+	PrintStatus("running", "", "p:vision", "http://localhost:8080", "/path/to/llama.log", "/models/mmproj.gguf")
 
 	// Assert
 	output := buf.String()
@@ -140,3 +189,22 @@ func TestPrintStatus_WithMmproj(t *testing.T) {
 		t.Error("Output should contain mmproj path")
 	}
 }
+
+func TestPrintStatus_Detail(t *testing.T) {
+	// Disable color for testing
+	color.NoColor = true
+	defer func() { color.NoColor = false }()
+
+	// Arrange
+	var buf bytes.Buffer
+	Output = &buf
+	defer func() { Output = os.Stdout }()
+
+	// Act
+	PrintStatus("loading", "loading model 43%", "coder", "", "/path/to/llama.log", "")
+
+	// Assert
+	if !strings.Contains(buf.String(), "Loading (loading model 43%)") {
+		t.Errorf("Output should show the loading detail, got:\n%s", buf.String())
+	}
+}
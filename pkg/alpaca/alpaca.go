@@ -0,0 +1,278 @@
+// Package alpaca controls an alpaca daemon from Go programs: loading and
+// unloading models, reading status, and downloading models, without
+// shelling out to the CLI.
+//
+// Requests and responses are typed; the wire protocol underneath is the
+// one the alpaca CLI speaks (see docs/design/architecture.md). A Client
+// works with daemons of the same protocol version and reports any other as
+// a *VersionMismatchError.
+//
+//	c, err := alpaca.Default()
+//	if err != nil {
+//		return err
+//	}
+//	res, err := c.Load(ctx, "p:coder", alpaca.LoadOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println("ready at", res.Endpoint)
+package alpaca
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/client"
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// ErrUnauthorized is returned when a remote daemon rejects the token.
+var ErrUnauthorized = client.ErrUnauthorized
+
+// ErrPeerRejected is returned when the local daemon refuses connections
+// from the calling user.
+var ErrPeerRejected = client.ErrPeerRejected
+
+// VersionMismatchError is returned when the daemon speaks another protocol
+// version than this package.
+type VersionMismatchError = client.VersionMismatchError
+
+// Error codes set on *Error.
+const (
+	CodePresetNotFound = protocol.ErrCodePresetNotFound
+	CodeModelNotFound  = protocol.ErrCodeModelNotFound
+	CodeServerFailed   = protocol.ErrCodeServerFailed
+	CodeBusy           = protocol.ErrCodeBusy // the daemon is at its request limit; retry
+)
+
+// Error is a request the daemon answered with an error.
+type Error struct {
+	Code    string // one of the Code constants; empty when unclassified
+	Message string
+
+	// Failure describes the llama-server of a failed Load, when the
+	// daemon could tell; set only with CodeServerFailed.
+	Failure *LoadFailure
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Timeouts bound a client's requests.
+type Timeouts struct {
+	Dial    time.Duration // connecting; zero uses 5 seconds
+	Request time.Duration // whole request; zero uses 30 seconds for queries and no limit for Load and Unload
+}
+
+// Client controls one daemon. It holds no connection; each call opens its
+// own, so a Client may be used from several goroutines.
+type Client struct {
+	c      *client.Client
+	remote bool
+	paths  *config.Paths // for Pull; nil unless made by Default
+}
+
+// New creates a client for the daemon listening on the Unix socket at
+// socketPath.
+func New(socketPath string) *Client {
+	return &Client{c: client.New(socketPath)}
+}
+
+// NewRemote creates a client for a daemon started with --listen, at a TCP
+// address (host:port). token is the daemon's remote token. Daemons off the
+// local machine are reached over TLS (see SetTLSConfig).
+func NewRemote(addr, token string) *Client {
+	return &Client{c: client.NewRemote(addr, token), remote: true}
+}
+
+// SetTLSConfig sets the TLS configuration of a remote client, e.g. with
+// the CA of a daemon's self-signed certificate or a client certificate for
+// --tls-client-ca. nil selects plaintext, which only loopback daemons
+// accept.
+func (c *Client) SetTLSConfig(cfg *tls.Config) {
+	c.c.SetTLSConfig(cfg)
+}
+
+// Default creates a client for the local daemon the CLI would use: the
+// profile named by ALPACA_PROFILE and the socket-dir of its config.yaml.
+func Default() (*Client, error) {
+	paths, err := localPaths()
+	if err != nil {
+		return nil, err
+	}
+	return &Client{c: client.New(paths.Socket), paths: paths}, nil
+}
+
+// localPaths returns the paths of the active profile, adjusted by its
+// config.yaml.
+func localPaths() (*config.Paths, error) {
+	paths, err := config.GetPaths()
+	if err != nil {
+		return nil, fmt.Errorf("get paths: %w", err)
+	}
+	cfg, err := config.LoadPathSettings(paths.Config)
+	if err != nil {
+		return nil, err
+	}
+	if err := paths.ApplyConfig(cfg); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// SetTimeouts overrides the default timeouts.
+func (c *Client) SetTimeouts(t Timeouts) {
+	c.c.SetTimeouts(client.Timeouts{Dial: t.Dial, Request: t.Request})
+}
+
+// Version returns the daemon's protocol version. Unlike other calls it
+// succeeds whatever version the daemon speaks.
+func (c *Client) Version(ctx context.Context) (int, error) {
+	return c.c.Hello(ctx)
+}
+
+// Status returns what the daemon is doing.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	var s Status
+	resp, err := c.c.Status(ctx)
+	if err := decode(resp, err, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// LoadOptions holds optional parameters for Load.
+type LoadOptions struct {
+	DraftModel  string // draft model identifier for speculative decoding, or "none"
+	LlamaServer string // llama-server binary for this load
+	Watch       bool   // reload the preset when its file changes, until unloaded
+	Strict      bool   // refuse a ctx-size beyond the model's trained context length
+}
+
+// Load loads a preset or model (p:name, h:org/repo:quant, f:path) and
+// waits until it is ready. Canceling ctx cancels the load on the daemon.
+func (c *Client) Load(ctx context.Context, identifier string, opts LoadOptions) (*LoadResult, error) {
+	var res LoadResult
+	resp, err := c.c.Load(ctx, identifier, client.LoadOptions{DraftModel: opts.DraftModel, LlamaServer: opts.LlamaServer, Watch: opts.Watch, Strict: opts.Strict})
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// UnloadOptions holds optional parameters for Unload.
+type UnloadOptions struct {
+	Force bool // SIGKILL llama-server instead of waiting for it to exit
+}
+
+// Unload stops the running model. It succeeds when nothing is running.
+func (c *Client) Unload(ctx context.Context, opts UnloadOptions) (*UnloadResult, error) {
+	var res UnloadResult
+	resp, err := c.c.Unload(ctx, client.UnloadOptions{Force: opts.Force})
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Cancel aborts the load in progress and reports whether there was one.
+func (c *Client) Cancel(ctx context.Context) (bool, error) {
+	var res struct {
+		Canceled bool `json:"canceled"`
+	}
+	resp, err := c.c.Cancel(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return false, err
+	}
+	return res.Canceled, nil
+}
+
+// ListPresets returns the names of the daemon's presets, without the p:
+// prefix.
+func (c *Client) ListPresets(ctx context.Context) ([]string, error) {
+	var res struct {
+		Presets []string `json:"presets"`
+	}
+	resp, err := c.c.ListPresets(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return res.Presets, nil
+}
+
+// ListModels returns the models downloaded on the daemon's machine.
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	var res struct {
+		Models []Model `json:"models"`
+	}
+	resp, err := c.c.ListModels(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return res.Models, nil
+}
+
+// Completions returns the preset names, downloaded models, aliases and
+// router model names in one request. It answers while a model is loading.
+func (c *Client) Completions(ctx context.Context) (*Completions, error) {
+	var res Completions
+	resp, err := c.c.Complete(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// Stats returns per-model request statistics, collected by a daemon
+// started with --proxy.
+func (c *Client) Stats(ctx context.Context) ([]ModelStats, error) {
+	var res struct {
+		Models []ModelStats `json:"models"`
+	}
+	resp, err := c.c.Stats(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return res.Models, nil
+}
+
+// Processes returns the llama-server processes the daemon runs.
+func (c *Client) Processes(ctx context.Context) ([]LlamaProcess, error) {
+	var res struct {
+		Processes []LlamaProcess `json:"processes"`
+	}
+	resp, err := c.c.Ps(ctx)
+	if err := decode(resp, err, &res); err != nil {
+		return nil, err
+	}
+	return res.Processes, nil
+}
+
+// decode returns err if the request failed, the daemon's error if it
+// answered with one, and otherwise converts the response data into out.
+func decode(resp *protocol.Response, err error, out any) error {
+	if err != nil {
+		return err
+	}
+	if resp.Status == protocol.StatusError {
+		e := &Error{Code: resp.ErrorCode, Message: resp.Error}
+		if e.Code == CodeServerFailed && len(resp.Data) > 0 {
+			var failure LoadFailure
+			if resp.Decode(&failure) == nil {
+				e.Failure = &failure
+			}
+		}
+		return e
+	}
+	return resp.Decode(out)
+}
+
+// IsNotFound reports whether err is a load of a preset or model that does
+// not exist.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && (e.Code == CodePresetNotFound || e.Code == CodeModelNotFound)
+}
@@ -0,0 +1,318 @@
+package alpaca
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/protocol"
+)
+
+// testDaemon serves handler on a Unix socket and returns a client for it.
+func testDaemon(t *testing.T, handler func(req *protocol.Request) *protocol.Response) *Client {
+	t.Helper()
+
+	// Use /tmp directly to avoid long path issues on macOS
+	socketPath := filepath.Join("/tmp", "alpaca-sdk-"+filepath.Base(t.TempDir())+".sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadBytes('\n')
+				if err != nil {
+					return
+				}
+				var req protocol.Request
+				if err := json.Unmarshal(line, &req); err != nil {
+					return
+				}
+				data, _ := json.Marshal(handler(&req))
+				conn.Write(append(data, '\n'))
+			}()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		os.Remove(socketPath)
+	})
+	return New(socketPath)
+}
+
+func TestClient_Status(t *testing.T) {
+	// Arrange
+	busy, usage := 1, 0.25
+	rss, cpu := int64(1<<20), 1.5
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		return protocol.NewOKResponse(protocol.StatusData{
+			State:    "running",
+			Since:    time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC),
+			Preset:   "coder",
+			Endpoint: "http://127.0.0.1:8080",
+			Mode:     "router",
+			Models:   []protocol.RouterModelData{{ID: "qwen", Status: "loaded"}},
+			Metrics:  &protocol.MetricsData{SlotsBusy: &busy, KVCacheUsage: &usage},
+			Daemon: &protocol.ProcessData{
+				PID: 100, Version: "1.2.3", StartedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC), RSS: &rss, CPUSeconds: &cpu,
+			},
+			LlamaServer: &protocol.ProcessData{PID: 200},
+		})
+	})
+
+	// Act
+	s, err := c.Status(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if s.State != StateRunning || s.Preset != "coder" || s.Endpoint != "http://127.0.0.1:8080" || s.Mode != "router" {
+		t.Errorf("Status() = %+v", s)
+	}
+	if !s.Since.Equal(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Since = %v", s.Since)
+	}
+	if len(s.Models) != 1 || s.Models[0] != (RouterModel{ID: "qwen", Status: "loaded"}) {
+		t.Errorf("Models = %+v", s.Models)
+	}
+	if s.Metrics == nil || *s.Metrics.SlotsBusy != 1 || *s.Metrics.KVCacheUsage != 0.25 || s.Metrics.SlotsTotal != nil {
+		t.Errorf("Metrics = %+v", s.Metrics)
+	}
+	if s.Daemon.PID != 100 || s.Daemon.Version != "1.2.3" || s.Daemon.RSS != 1<<20 || s.Daemon.CPUSeconds != 1.5 {
+		t.Errorf("Daemon = %+v", s.Daemon)
+	}
+	if s.LlamaServer == nil || s.LlamaServer.PID != 200 {
+		t.Errorf("LlamaServer = %+v", s.LlamaServer)
+	}
+}
+
+func TestClient_Load(t *testing.T) {
+	// Arrange
+	var got *protocol.Request
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		got = req
+		return protocol.NewOKResponse(protocol.LoadData{
+			Endpoint:      "http://127.0.0.1:8080",
+			Warnings:      []string{"unknown option --foo"},
+			Models:        []protocol.RouterModelData{{ID: "qwen", Status: "loading"}},
+			MissingModels: []string{"gemma"},
+		})
+	})
+
+	// Act
+	res, err := c.Load(context.Background(), "p:coder", LoadOptions{DraftModel: "none"})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if res.Endpoint != "http://127.0.0.1:8080" || !slices.Equal(res.Warnings, []string{"unknown option --foo"}) {
+		t.Errorf("Load() = %+v", res)
+	}
+	if len(res.Models) != 1 || res.Models[0] != (RouterModel{ID: "qwen", Status: "loading"}) || !slices.Equal(res.MissingModels, []string{"gemma"}) {
+		t.Errorf("Load() models = %+v, missing = %v", res.Models, res.MissingModels)
+	}
+	if got.Command != protocol.CmdLoad || got.Args["identifier"] != "p:coder" || got.Args["draft_model"] != "none" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestClient_LoadError(t *testing.T) {
+	// Arrange
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		return protocol.NewErrorResponseWithCode(protocol.ErrCodePresetNotFound, "preset 'x' not found")
+	})
+
+	// Act
+	_, err := c.Load(context.Background(), "p:x", LoadOptions{})
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodePresetNotFound || apiErr.Message != "preset 'x' not found" {
+		t.Fatalf("Load() error = %#v, want preset not found", err)
+	}
+	if !IsNotFound(err) {
+		t.Error("IsNotFound() = false, want true")
+	}
+}
+
+func TestClient_LoadServerFailure(t *testing.T) {
+	// Arrange
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		return protocol.NewErrorResponseWithData(protocol.ErrCodeServerFailed, "wait llama-server: exited",
+			protocol.LoadFailureData{LogTail: []string{"error: couldn't bind"}, Addr: "127.0.0.1:8080", PortInUse: true})
+	})
+
+	// Act
+	_, err := c.Load(context.Background(), "p:x", LoadOptions{})
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != CodeServerFailed || apiErr.Failure == nil {
+		t.Fatalf("Load() error = %#v, want a server failure with diagnostics", err)
+	}
+	if f := apiErr.Failure; !f.PortInUse || f.Addr != "127.0.0.1:8080" || len(f.LogTail) != 1 {
+		t.Errorf("failure = %+v", f)
+	}
+}
+
+func TestClient_UnloadAndLists(t *testing.T) {
+	// Arrange
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		switch req.Command {
+		case protocol.CmdUnload:
+			return protocol.NewOKResponse(protocol.UnloadData{Graceful: req.Args["force"] != true})
+		case protocol.CmdListModels:
+			return protocol.NewOKResponse(protocol.ListModelsData{Models: []protocol.ModelData{{Repo: "org/repo", Quant: "Q4_K_M", Size: 42}}})
+		case protocol.CmdListPresets:
+			return protocol.NewOKResponse(protocol.ListPresetsData{Presets: []string{"chat", "coder"}})
+		case protocol.CmdCancel:
+			return protocol.NewOKResponse(protocol.CancelData{Canceled: true})
+		case protocol.CmdPs:
+			return protocol.NewOKResponse(protocol.PsData{Processes: []protocol.LlamaProcessData{{PID: 4242, Preset: "coder", State: "running", Port: 8080}}})
+		case protocol.CmdComplete:
+			return protocol.NewOKResponse(protocol.CompleteData{Presets: []string{"fleet"}, RouterModels: map[string][]string{"fleet": {"qwen"}}})
+		}
+		return protocol.NewErrorResponse("unexpected command " + req.Command)
+	})
+	ctx := context.Background()
+
+	// Act
+	unloaded, errUnload := c.Unload(ctx, UnloadOptions{Force: true})
+	models, errModels := c.ListModels(ctx)
+	presets, errPresets := c.ListPresets(ctx)
+	canceled, errCancel := c.Cancel(ctx)
+	procs, errProcs := c.Processes(ctx)
+	completions, errComplete := c.Completions(ctx)
+
+	// Assert
+	for _, err := range []error{errUnload, errModels, errPresets, errCancel, errProcs, errComplete} {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if unloaded.Graceful {
+		t.Error("Unload(Force) Graceful = true, want false")
+	}
+	if want := []Model{{Repo: "org/repo", Quant: "Q4_K_M", Size: 42}}; !slices.Equal(models, want) {
+		t.Errorf("ListModels() = %+v, want %+v", models, want)
+	}
+	if want := []string{"chat", "coder"}; !slices.Equal(presets, want) {
+		t.Errorf("ListPresets() = %v, want %v", presets, want)
+	}
+	if !canceled {
+		t.Error("Cancel() = false, want true")
+	}
+	if want := []LlamaProcess{{PID: 4242, Preset: "coder", State: StateRunning, Port: 8080}}; !slices.Equal(procs, want) {
+		t.Errorf("Processes() = %+v, want %+v", procs, want)
+	}
+	if got := completions.RouterModels["fleet"]; !slices.Equal(completions.Presets, []string{"fleet"}) || !slices.Equal(got, []string{"qwen"}) {
+		t.Errorf("Completions() = %+v, want fleet with model qwen", completions)
+	}
+}
+
+func TestClient_VersionMismatch(t *testing.T) {
+	// Arrange
+	c := testDaemon(t, func(req *protocol.Request) *protocol.Response {
+		resp := protocol.NewOKResponse(protocol.StatusData{State: "idle"})
+		resp.Version = protocol.Version + 1
+		return resp
+	})
+
+	// Act
+	_, err := c.Status(context.Background())
+
+	// Assert
+	var mismatch *VersionMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Daemon != protocol.Version+1 {
+		t.Fatalf("Status() error = %v, want *VersionMismatchError", err)
+	}
+}
+
+func TestClient_Pull(t *testing.T) {
+	// Arrange
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ALPACA_PROFILE", "")
+	content := []byte("model-content")
+	sum := sha256.Sum256(content)
+	hf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/org/model/manifests/Q4_K_M" {
+			fmt.Fprintf(w, `{"ggufFile":{"rfilename":"model-Q4_K_M.gguf","size":%d,"lfs":{"sha256":"%s"}}}`, len(content), hex.EncodeToString(sum[:]))
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(hf.Close)
+	var reports []PullProgress
+	c, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	res, err := c.Pull(context.Background(), "org/model:Q4_K_M", PullOptions{
+		Endpoint: hf.URL,
+		Progress: func(p PullProgress) { reports = append(reports, p) },
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if res.Quant != "Q4_K_M" || res.Size != int64(len(content)) || res.UpToDate {
+		t.Errorf("Pull() = %+v", res)
+	}
+	if filepath.Dir(res.Path) != filepath.Join(home, ".alpaca", "models") {
+		t.Errorf("Path = %s, want in the default models directory", res.Path)
+	}
+	if len(reports) == 0 || reports[len(reports)-1].Downloaded != int64(len(content)) {
+		t.Errorf("progress reports = %+v, want a final report of the whole file", reports)
+	}
+}
+
+func TestClient_PullErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *Client
+		model  string
+	}{
+		{"remote daemon", NewRemote("127.0.0.1:7070", "token"), "h:org/model:Q4_K_M"},
+		{"missing quant", New("/tmp/unused.sock"), "h:org/model"},
+		{"preset", New("/tmp/unused.sock"), "p:coder"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("HOME", t.TempDir())
+
+			// Act
+			_, err := tt.client.Pull(context.Background(), tt.model, PullOptions{})
+
+			// Assert
+			if err == nil {
+				t.Error("Pull() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package alpaca
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/d2verb/alpaca/internal/config"
+	"github.com/d2verb/alpaca/internal/identifier"
+	"github.com/d2verb/alpaca/internal/pull"
+)
+
+// ErrRemotePull is returned by Pull on a client of a remote daemon: models
+// are downloaded on the machine Pull runs on.
+var ErrRemotePull = errors.New("pull downloads to the local models directory; it is not available for remote daemons")
+
+// PullOptions holds optional parameters for Pull.
+type PullOptions struct {
+	// Endpoint is the HuggingFace endpoint or mirror to download from.
+	// Defaults to HF_ENDPOINT, then the hf-endpoint of config.yaml. It does
+	// only applies to HuggingFace models.
+	Endpoint string
+
+	// Progress, if set, is called as files download.
+	Progress func(PullProgress)
+
+	// Verify hashes files already downloaded in full to check they are up
+	// to date, instead of trusting the checksums recorded for them.
+	Verify bool
+}
+
+// PullProgress reports a file download.
+type PullProgress struct {
+	Phase      string // "model" or "mmproj"
+	File       string
+	Shard      int // 1-based shard of a split model; 0 for a single file
+	Shards     int
+	Downloaded int64         // bytes so far, across all shards of a split model
+	Total      int64         // -1 when unknown
+	Rate       float64       // bytes per second; 0 until measured
+	ETA        time.Duration // 0 when unknown
+}
+
+// PullResult is the outcome of a successful Pull.
+type PullResult struct {
+	Quant        string // quant downloaded; the one chosen for :auto
+	Path         string
+	Size         int64
+	Mmproj       string // file name of the vision projector, if the model has one
+	MmprojFailed bool   // the model downloaded but its mmproj did not; pull again to retry
+	UpToDate     bool   // already downloaded and unchanged upstream
+}
+
+// Pull downloads a HuggingFace model (h:org/repo:quant), a ModelScope
+// model (ms:org/repo:quant), or an OCI artifact
+// (oci://registry/repository:tag) into the models directory of the local
+// profile, with the download settings of its config.yaml. The daemon is
+// not involved; a model it is running can be pulled, and loads see the
+// model as soon as Pull returns.
+func (c *Client) Pull(ctx context.Context, model string, opts PullOptions) (*PullResult, error) {
+	if c.remote {
+		return nil, ErrRemotePull
+	}
+	if !identifier.HasPrefix(model) {
+		model = "h:" + model
+	}
+	id, err := identifier.Parse(model)
+	if err != nil {
+		return nil, err
+	}
+	if id.Type != identifier.TypeHuggingFace || id.Quant == "" || id.IsRepoFile() {
+		return nil, fmt.Errorf("pull needs a model as h:org/repo:quant, ms:org/repo:quant or oci://registry/repository:tag, got '%s'", model)
+	}
+
+	paths := c.paths
+	if paths == nil {
+		if paths, err = localPaths(); err != nil {
+			return nil, err
+		}
+	}
+	if err := paths.EnsureDirectories(); err != nil {
+		return nil, fmt.Errorf("create directories: %w", err)
+	}
+	cfg, err := config.Load(paths.Config)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := pull.RegistryFor(id.Registry)
+	if err != nil {
+		return nil, err
+	}
+	if registry != pull.HuggingFace && opts.Endpoint != "" {
+		return nil, fmt.Errorf("endpoint only applies to HuggingFace models, not %s", registry.Name())
+	}
+	puller, err := pull.NewFromConfig(paths.Models, cfg, opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	puller.SetRegistry(registry)
+	puller.SetVerify(opts.Verify)
+	if opts.Progress != nil {
+		puller.SetReportFunc(func(p pull.Progress) {
+			opts.Progress(PullProgress{
+				Phase:      string(p.Phase),
+				File:       p.File,
+				Shard:      p.Shard,
+				Shards:     p.Shards,
+				Downloaded: p.Downloaded,
+				Total:      p.Total,
+				Rate:       p.Rate,
+				ETA:        p.ETA,
+			})
+		})
+	}
+
+	res, err := puller.Pull(ctx, id.Repo, id.Quant)
+	if err != nil {
+		return nil, err
+	}
+	quant := res.Quant
+	if quant == "" {
+		quant = id.Quant
+	}
+	return &PullResult{
+		Quant:        quant,
+		Path:         res.Path,
+		Size:         res.Size,
+		Mmproj:       res.MmprojFilename,
+		MmprojFailed: res.MmprojFailed,
+		UpToDate:     res.AlreadyUpToDate,
+	}, nil
+}
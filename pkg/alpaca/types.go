@@ -0,0 +1,135 @@
+package alpaca
+
+import "time"
+
+// State is what the daemon is doing.
+type State string
+
+const (
+	StateIdle    State = "idle"
+	StateLoading State = "loading"
+	StateRunning State = "running"
+)
+
+// Status describes the daemon and the model it runs. Fields about the
+// model are empty while the daemon is idle.
+type Status struct {
+	State State     `json:"state"`
+	Since time.Time `json:"since"` // when State was entered
+	// Detail is what llama-server reports while loading, e.g.
+	// "loading model 43%".
+	Detail string `json:"detail,omitempty"`
+
+	Preset    string   `json:"preset,omitempty"` // preset name, or the identifier of a model loaded directly
+	Endpoint  string   `json:"endpoint,omitempty"`
+	Mode      string   `json:"mode,omitempty"` // "router" for router presets; empty otherwise
+	Files     []string `json:"files,omitempty"`
+	Mmproj    string   `json:"mmproj,omitempty"`
+	Command   []string `json:"command,omitempty"` // llama-server command line
+	ConfigINI string   `json:"config_ini,omitempty"`
+
+	// Models lists a router preset's models. ModelsError says why they
+	// could not be read from llama-server.
+	Models      []RouterModel `json:"models,omitempty"`
+	ModelsError string        `json:"models_error,omitempty"`
+
+	Metrics     *Metrics `json:"metrics,omitempty"`
+	Daemon      Process  `json:"daemon"`
+	LlamaServer *Process `json:"llama_server,omitempty"`
+}
+
+// RouterModel is a model of a router preset.
+type RouterModel struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"` // as reported by llama-server, e.g. "loaded" or "unloaded"
+	Mmproj      string `json:"mmproj,omitempty"`
+	ActiveSlots *int   `json:"active_slots,omitempty"` // slots processing a request; nil if not reported
+	Queued      *int   `json:"queued,omitempty"`       // requests waiting for a slot; nil if not reported
+}
+
+// Metrics are llama-server's performance counters. Values llama-server
+// does not report are nil.
+type Metrics struct {
+	KVCacheUsage          *float64 `json:"kv_cache_usage,omitempty"` // fraction in use
+	SlotsBusy             *int     `json:"slots_busy,omitempty"`
+	SlotsTotal            *int     `json:"slots_total,omitempty"`
+	PromptTokensPerSec    *float64 `json:"prompt_tokens_per_sec,omitempty"`
+	PredictedTokensPerSec *float64 `json:"predicted_tokens_per_sec,omitempty"`
+}
+
+// Process describes the daemon or llama-server process. RSS and
+// CPUSeconds are zero when they could not be read.
+type Process struct {
+	PID        int       `json:"pid"`
+	Version    string    `json:"version,omitempty"`   // daemon only
+	StartedAt  time.Time `json:"started_at,omitzero"` // daemon only
+	RSS        int64     `json:"rss,omitempty"`       // resident memory in bytes
+	CPUSeconds float64   `json:"cpu_seconds,omitempty"`
+}
+
+// LoadResult is the outcome of a successful Load.
+type LoadResult struct {
+	Endpoint string   `json:"endpoint"`
+	Warnings []string `json:"warnings,omitempty"` // llama-server options that look wrong
+
+	// For router presets: the models llama-server registered, or why they
+	// could not be read, and the preset's models it did not register.
+	Models        []RouterModel `json:"models,omitempty"`
+	ModelsError   string        `json:"models_error,omitempty"`
+	MissingModels []string      `json:"missing_models,omitempty"`
+}
+
+// LoadFailure describes a llama-server that ran but did not become ready,
+// as the daemon saw it when the load failed.
+type LoadFailure struct {
+	Command    []string `json:"command,omitempty"`     // llama-server and its arguments
+	ExitCode   *int     `json:"exit_code,omitempty"`   // nil when llama-server did not exit on its own
+	LogTail    []string `json:"log_tail,omitempty"`    // last lines of llama-server output
+	Addr       string   `json:"addr,omitempty"`        // host:port it was to listen on
+	PortInUse  bool     `json:"port_in_use"`           // another process holds Addr
+	FreeMemory int64    `json:"free_memory,omitempty"` // bytes available; 0 if unknown
+}
+
+// UnloadResult is the outcome of a successful Unload.
+type UnloadResult struct {
+	// Graceful is false when llama-server had to be killed.
+	Graceful bool `json:"graceful"`
+}
+
+// Model is a downloaded model.
+type Model struct {
+	Repo  string `json:"repo"`
+	Quant string `json:"quant"`
+	Size  int64  `json:"size"` // bytes
+}
+
+// Completions are what identifiers and model names can be completed to.
+type Completions struct {
+	Presets      []string            `json:"presets"`       // preset names, without p:
+	Models       []string            `json:"models"`        // downloaded models as identifiers, e.g. h:org/repo:Q4_K_M
+	Aliases      []string            `json:"aliases"`       // model names presets give llama-server with the alias option
+	RouterModels map[string][]string `json:"router_models"` // router preset name to its model names
+	Warning      string              `json:"warning,omitempty"`
+}
+
+// LlamaProcess is a llama-server process run by the daemon.
+type LlamaProcess struct {
+	PID       int       `json:"pid"`
+	Preset    string    `json:"preset"`
+	State     State     `json:"state"`
+	StartedAt time.Time `json:"started_at,omitzero"` // zero when it could not be read
+	RSS       int64     `json:"rss,omitempty"`       // resident memory in bytes; 0 when it could not be read
+	Port      int       `json:"port,omitempty"`
+	Model     string    `json:"model,omitempty"` // model path; empty for a router preset
+	Mode      string    `json:"mode,omitempty"`  // "router" for router presets
+}
+
+// ModelStats are the request statistics of one model.
+type ModelStats struct {
+	Model            string `json:"model"`
+	Requests         int64  `json:"requests"`
+	Errors           int64  `json:"errors"`
+	PromptTokens     int64  `json:"prompt_tokens"`
+	CompletionTokens int64  `json:"completion_tokens"`
+	AvgLatencyMS     int64  `json:"avg_latency_ms"`
+}